@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptScan     string
+	adoptBase     string
+	adoptTemplate string
+	adoptLabels   []string
+	adoptMove     bool
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [path]",
+	Short: "Manage worktrees created outside giwo",
+	Long: `Record worktrees created outside giwo, such as with 'git worktree add' in
+a place of your own, in giwo's metadata, as if giwo had created them: their
+base, the template override they are set up with and labels are kept, and
+'giwo clean --older-than' and the like treat them as any other worktree.
+
+  giwo adopt ../hotfix --base main --label review
+  giwo adopt --scan ~/src/scratch --move
+
+The worktree must be one of this repository. With --scan, all worktrees of the
+repository in or below a directory that giwo does not manage yet are adopted.
+With --move, adopted worktrees are moved into the worktree directory as well,
+under the name of their branch, as 'giwo mv' does.
+
+Worktrees giwo manages already are adopted again, which records the options
+given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (adoptScan == "") == (len(args) == 0) {
+			return fmt.Errorf("pass either a worktree path or --scan <dir>")
+		}
+
+		ctx := cmd.Context()
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		if adoptTemplate != "" {
+			cfg, err := loadConfig(manager)
+			if err != nil {
+				return err
+			}
+			// Only check that the override exists, before anything is recorded
+			if _, err := cfg.ResolveTemplate("", adoptTemplate, worktree.ConfigFiles); err != nil {
+				return err
+			}
+		}
+
+		paths := args
+		if adoptScan != "" {
+			dir, err := filepath.Abs(adoptScan)
+			if err != nil {
+				return err
+			}
+			if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+				dir = resolved
+			}
+			unmanaged, err := manager.Unmanaged(ctx, dir)
+			if err != nil {
+				return err
+			}
+			if len(unmanaged) == 0 {
+				fmt.Printf("✨ No worktrees to adopt in %s\n", displayPath(dir))
+				return nil
+			}
+			paths = nil
+			for _, wt := range unmanaged {
+				paths = append(paths, wt.Path)
+			}
+		}
+
+		failed := 0
+		for _, path := range paths {
+			if err := adoptWorktree(ctx, manager, path); err != nil {
+				if len(paths) == 1 {
+					return err
+				}
+				fmt.Printf("❌ %s: %v\n", displayPath(path), err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d worktrees could not be adopted", failed, len(paths))
+		}
+		return nil
+	},
+}
+
+// adoptWorktree adopts the worktree at path with the options of the flags and,
+// with --move, moves it into the worktree directory.
+func adoptWorktree(ctx context.Context, manager *worktree.Manager, path string) error {
+	wt, adopted, err := manager.Adopt(ctx, worktree.AdoptOptions{
+		Path:     path,
+		Base:     adoptBase,
+		Template: adoptTemplate,
+		Labels:   adoptLabels,
+	})
+	if err != nil {
+		return err
+	}
+	label := wt.Branch
+	if wt.Detached {
+		label = filepath.Base(wt.Path) + " (detached)"
+	}
+	if adopted {
+		fmt.Printf("🏠 Adopted '%s' at %s\n", label, displayPath(wt.Path))
+	} else {
+		fmt.Printf("✅ '%s' at %s is managed already; recorded the options given\n", label, displayPath(wt.Path))
+	}
+
+	if !adoptMove {
+		return nil
+	}
+	dest := manager.AdoptDestination(wt)
+	if dest == "" {
+		return nil
+	}
+	dest, err = manager.MoveDestination(ctx, dest)
+	if err != nil {
+		return fmt.Errorf("cannot move it into the worktree directory: %w", err)
+	}
+	if err := manager.Move(ctx, worktree.MoveOptions{Path: wt.Path, Dest: dest}); err != nil {
+		if errors.Is(err, giwoerrors.ErrWorktreeLocked) {
+			return fmt.Errorf("refusing to move worktree: %w\n💡 Use 'giwo mv --force' to move it anyway", err)
+		}
+		return err
+	}
+	fmt.Printf("📦 Moved it to %s\n", displayPath(dest))
+	return nil
+}
+
+func init() {
+	adoptCmd.Flags().StringVar(&adoptScan, "scan", "", "Adopt all unmanaged worktrees of the repository in or below this directory")
+	adoptCmd.Flags().StringVar(&adoptBase, "base", "", "Record the branch the worktree's branch started from")
+	adoptCmd.Flags().StringVar(&adoptTemplate, "template", "", "Record the override with this key from the overrides setting as the worktree's template")
+	adoptCmd.Flags().StringSliceVar(&adoptLabels, "label", nil, "Labels to attach, e.g. --label review,urgent")
+	adoptCmd.Flags().BoolVar(&adoptMove, "move", false, "Move adopted worktrees into the worktree directory, named after their branch")
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyFile     string
+	applyPrefetch bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <spec>",
+	Short: "Create the worktrees described by a spec file",
+	Long: `Create the worktrees listed in a spec file, for example to reconstruct a
+layout on a CI runner that needs several branches checked out side by side:
+
+  worktrees:
+    - branch: main
+    - branch: release/1.x
+      env: RELEASE_DIR
+    - branch: contract-compat
+      base: main
+
+Branches are checked out from the local branch or from origin; a branch missing
+from both is started from its base, if one is given. Branches already checked
+out in a worktree are left alone, so applying a spec twice is harmless.
+
+apply never prompts. CI clones are usually shallow or single-branch, so use
+--prefetch to fetch every branch of the spec in one go first. Afterwards,
+'giwo export-shellenv -f <spec>' exports the worktree paths to later steps.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := worktree.LoadSpec(applyFile)
+		if err != nil {
+			return err
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		if applyPrefetch {
+			fmt.Printf("📡 Fetching %d branches...\n", len(spec.Worktrees))
+		}
+
+		results, err := manager.Apply(cmd.Context(), spec, worktree.ApplyOptions{Prefetch: applyPrefetch})
+		for _, result := range results {
+			if result.Created {
+				fmt.Printf("✅ Created worktree '%s' at %s\n", result.Branch, result.Path)
+			} else {
+				fmt.Printf("✔️  Worktree '%s' already checked out at %s\n", result.Branch, result.Path)
+			}
+		}
+		return err
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "Spec file describing the worktrees")
+	applyCmd.Flags().BoolVar(&applyPrefetch, "prefetch", false, "Fetch all branches of the spec from origin first")
+	_ = applyCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var restoreForce bool
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive [filter]",
+	Short: "Snapshot a worktree and remove it",
+	Long: `Snapshot a worktree and remove it to free disk space, keeping its branch.
+
+The snapshot is a commit under refs/giwo/archive/<name> holding the commit the
+worktree was on and all its files that are not ignored, including uncommitted
+and untracked ones. Ignored files such as build output or node_modules are not
+kept. Use 'giwo restore <name>' to bring the worktree back.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		filter := ""
+		if len(args) > 0 {
+			filter = args[0]
+		}
+
+		ctx := cmd.Context()
+		selected, err := resolveWorktree(ctx, manager, filter)
+		if err != nil {
+			return err
+		}
+		if selected == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+		if selected.IsMain {
+			return fmt.Errorf("cannot archive the main worktree")
+		}
+
+		name := worktreeName(manager, selected)
+		fmt.Printf("📦 Archiving worktree '%s'...\n", name)
+
+		archive, err := manager.Archive(ctx, name)
+		if err != nil {
+			if errors.Is(err, giwoerrors.ErrWorktreeLocked) {
+				return fmt.Errorf("refusing to archive worktree: %w\n💡 Run 'giwo unlock %s' first", err, utils.ShellQuote(name))
+			}
+			return fmt.Errorf("failed to archive worktree: %w", err)
+		}
+
+		fmt.Printf("✅ Archived '%s' as %s and removed it\n", name, archive.Snapshot[:12])
+		fmt.Printf("💡 Run 'giwo restore %s' to bring it back\n", utils.ShellQuote(name))
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [name]",
+	Short: "Recreate an archived worktree",
+	Long: `Recreate a worktree archived with 'giwo archive' at its original place,
+with its files as they were when it was archived, as uncommitted changes.
+The branch is recreated if it was deleted in the meantime. If it has new
+commits, restoring is refused unless --force is given, as the snapshot would
+undo them in the working tree.
+
+Without a name, the archives are listed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		ctx := cmd.Context()
+		if len(args) == 0 {
+			archives, err := manager.Archives(ctx)
+			if err != nil {
+				return err
+			}
+			if len(archives) == 0 {
+				fmt.Println("No archived worktrees found")
+				return nil
+			}
+
+			w := ui.NewTable(os.Stdout)
+			defer w.Flush()
+			fmt.Fprintln(w, "NAME\tBRANCH\tARCHIVED")
+			for _, archive := range archives {
+				branch := archive.Branch
+				if branch == "" {
+					branch = "(detached)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", archive.Name, branch, archive.Time.Format("2006-01-02 15:04"))
+			}
+			return nil
+		}
+
+		path, err := manager.Restore(ctx, args[0], restoreForce)
+		if err != nil {
+			return fmt.Errorf("failed to restore worktree: %w", err)
+		}
+
+		fmt.Printf("✅ Restored '%s' at %s\n", args[0], path)
+		return nil
+	},
+}
+
+func init() {
+	restoreCmd.Flags().BoolVar(&restoreForce, "force", false, "Restore even if the branch has moved on since it was archived")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var assistJSON bool
+
+var assistCmd = &cobra.Command{
+	Use:   "assist",
+	Short: "Suggest what to do next with your worktrees",
+	Long: `Inspect the worktrees of the repository and suggest giwo commands to run,
+most urgent first, each with the reason for it:
+
+  high    remove worktrees of merged branches, rebase diverged branches
+  medium  push unpushed commits, finish old uncommitted work
+  low     fast-forward branches that are behind, archive abandoned worktrees,
+          prune worktrees whose directory is gone
+
+Worktrees count as abandoned once their last commit is older than the 'stale'
+age threshold in the configuration (30 days by default). Upstream state is that
+of the last fetch. The main worktree and locked worktrees are left alone.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+		ages, err := ageThresholds(cfg)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+		// Without a main branch on origin, there is just nothing to suggest about merged branches
+		merged, _ := manager.GetMergedBranches(ctx)
+
+		suggestions := worktree.Suggest(worktrees, merged, worktree.SuggestOptions{
+			Now:        time.Now(),
+			StaleAfter: ages.Stale,
+			Name:       func(wt *worktree.Worktree) string { return worktreeName(manager, wt) },
+		})
+
+		if assistJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if suggestions == nil {
+				suggestions = []worktree.Suggestion{}
+			}
+			return encoder.Encode(suggestions)
+		}
+
+		if len(suggestions) == 0 {
+			fmt.Println("✨ Nothing to do: your worktrees are in good shape")
+			return nil
+		}
+
+		fmt.Println("🧭 Suggested next steps")
+		for i, s := range suggestions {
+			fmt.Printf("\n%2d. %s %s\n", i+1, priorityMarker(s.Priority), s.Command)
+			fmt.Printf("    %s\n", s.Reason)
+		}
+		return nil
+	},
+}
+
+// priorityMarker returns the marker shown in front of suggestions of priority p.
+func priorityMarker(p worktree.Priority) string {
+	switch p {
+	case worktree.PriorityHigh:
+		return "🔴"
+	case worktree.PriorityMedium:
+		return "🟡"
+	default:
+		return "🔵"
+	}
+}
+
+func init() {
+	assistCmd.Flags().BoolVar(&assistJSON, "json", false, "Print the suggestions as JSON")
+}
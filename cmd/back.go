@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/knwoop/giwo/internal/config"
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/registry"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+// backListSize is the number of visited worktrees 'giwo back --list' shows.
+const backListSize = 10
+
+var backList bool
+
+var backCmd = &cobra.Command{
+	Use:   "back [n]",
+	Short: "Switch back to the previously visited worktree",
+	Long: `Switch back to the worktree you were in before, like 'cd -', across all
+repositories. 'giwo switch -' does the same.
+
+With n, go back n worktrees instead: 'giwo back 2' goes to the one visited before
+the previous one. Worktrees visited more than once count once, at their last
+visit, and worktrees that no longer exist are skipped. Use --list to see the
+last visited worktrees with their numbers.
+
+Visits are taken from the history (see 'giwo history'): the worktrees you
+switched to or created, and the ones you switched away from. With the shell
+integration loaded, the shell changes into the worktree.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return fmt.Errorf("invalid count %q: must be a positive number", args[0])
+			}
+		}
+		if backList {
+			return listVisited()
+		}
+		return switchBack(n)
+	},
+}
+
+// visitedWorktrees returns the worktrees visited before the current one, most
+// recent first, leaving out those that no longer exist.
+func visitedWorktrees() ([]history.Entry, error) {
+	entries, err := history.Load(config.StateDir())
+	if err != nil {
+		return nil, err
+	}
+	var visited []history.Entry
+	for _, e := range history.Visited(entries, currentWorktreeRoot()) {
+		if _, err := os.Stat(e.Path); err == nil {
+			visited = append(visited, e)
+		}
+	}
+	return visited, nil
+}
+
+// currentWorktreeRoot returns the root of the worktree the command runs in,
+// or "" outside of a repository.
+func currentWorktreeRoot() string {
+	manager, err := worktree.New()
+	if err != nil {
+		return ""
+	}
+	return manager.RepoRoot()
+}
+
+// switchBack switches to the nth worktree visited before the current one.
+func switchBack(n int) error {
+	visited, err := visitedWorktrees()
+	if err != nil {
+		return err
+	}
+	if len(visited) == 0 {
+		return fmt.Errorf("%w: no previously visited worktree\n💡 Worktrees are remembered as you switch to them with 'giwo switch'", giwoerrors.ErrWorktreeNotFound)
+	}
+	if n > len(visited) {
+		return fmt.Errorf("%w: only %d previously visited worktrees", giwoerrors.ErrWorktreeNotFound, len(visited))
+	}
+	e := visited[n-1]
+	return switchTo(nil, &worktree.Worktree{Path: e.Path, Branch: e.Branch})
+}
+
+// listVisited prints the last visited worktrees with the number to pass to
+// 'giwo back' for each.
+func listVisited() error {
+	visited, err := visitedWorktrees()
+	if err != nil {
+		return err
+	}
+	if len(visited) == 0 {
+		fmt.Println("No previously visited worktrees.")
+		return nil
+	}
+	w := ui.NewTable(os.Stdout)
+	fmt.Fprintln(w, "N\tWHEN\tREPO\tBRANCH\tPATH")
+	for i, e := range visited[:min(len(visited), backListSize)] {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, utils.TimeAgo(e.Time), registry.Name(e.Repo), e.Branch, e.Path)
+	}
+	return w.Flush()
+}
+
+// recordDeparture records a visit to the worktree the command runs in when
+// switching away from it to target, so that 'giwo back' can return to it even
+// if it was never switched to, such as the repository root.
+func recordDeparture(manager *worktree.Manager, target string) {
+	if manager == nil {
+		var err error
+		if manager, err = worktree.New(); err != nil {
+			return
+		}
+	}
+	root := manager.RepoRoot()
+	if root == target {
+		return
+	}
+	entries, err := history.Load(config.StateDir())
+	if err != nil {
+		return
+	}
+	if visited := history.Visited(entries, ""); len(visited) > 0 && visited[0].Path == root {
+		return
+	}
+	branch, _ := manager.GetCurrentBranch(context.Background())
+	if branch == "HEAD" {
+		branch = ""
+	}
+	recordHistory(manager, history.Switched, root, branch)
+}
+
+func init() {
+	backCmd.Flags().BoolVar(&backList, "list", false, "List the last visited worktrees instead of switching")
+	backCmd.Flags().BoolVarP(&switchPrint, "print", "p", false, "Print the worktree path instead of switching")
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var batchInput string
+
+var batchCmd = &cobra.Command{
+	Use:   "batch --json-in <file|->",
+	Short: "Run a JSON list of create, remove and exec operations",
+	Long: `Run a list of operations given as a JSON array, read from a file or from
+standard input with '-', and print a JSON document with the outcome of each:
+
+  [
+    {"id": "a", "op": "create", "branch": "feature-a", "base": "main"},
+    {"op": "exec", "branch": "feature-a", "command": ["make", "test"]},
+    {"op": "remove", "branch": "old-feature", "keep_branch": true, "depends_on": ["a"]}
+  ]
+
+create takes base, from and force as the create command does, and starts from
+the default branch without them. remove takes force and keep_branch; without
+force it fails for worktrees with uncommitted changes. exec runs the command
+in the worktree that has the branch checked out, capturing its output and
+exit code.
+
+Operations run one at a time, each after those in its depends_on and after the
+earlier ones on the same branch, and otherwise in the order given. One is
+skipped if an operation in its depends_on, or the creation of its worktree, did
+not succeed; the others run anyway, so a worktree is removed even if a command
+run in it failed.
+
+The document goes to standard output and everything else to standard error.
+The command exits with 1 unless every operation succeeded. It never prompts.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ops, err := readBatch(batchInput)
+		if err != nil {
+			return err
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		// Keep standard output for the document; what the operations print goes to stderr
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		results, err := manager.RunBatch(cmd.Context(), ops)
+		os.Stdout = stdout
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, result := range results {
+			switch {
+			case result.Status != worktree.BatchOK:
+				failed++
+			case result.Op == worktree.BatchCreate:
+				recordHistory(manager, history.Created, result.Path, result.Branch)
+			case result.Op == worktree.BatchRemove:
+				recordHistory(manager, history.Removed, result.Path, result.Branch)
+			}
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(struct {
+			OK      bool                   `json:"ok"`
+			Results []worktree.BatchResult `json:"results"`
+		}{failed == 0, results}); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d operations did not succeed", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// readBatch reads the operations of a batch from path, or from standard input for "-".
+func readBatch(path string) ([]worktree.BatchOp, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read batch: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return worktree.DecodeBatch(r)
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&batchInput, "json-in", "", "File with the JSON array of operations, or - for standard input")
+	_ = batchCmd.MarkFlagRequired("json-in")
+}
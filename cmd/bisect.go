@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var bisectKeep bool
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect",
+	Short: "Bisect in a worktree of its own",
+	Long: `Find the commit that broke something with git bisect, in a worktree of its
+own with a detached HEAD, so the worktree you work in stays untouched.
+
+'giwo bisect start <bad> <good>' creates the worktree and starts the session.
+Test the commit checked out there and tell git how it went with 'giwo bisect
+good' or 'giwo bisect bad', from any worktree, or let 'giwo bisect run' test
+each commit with a command. Once the first bad commit is found, the worktree
+is removed, unless --keep is given; 'giwo bisect reset' ends a session early.
+
+  giwo bisect start HEAD v1.4.0
+  giwo bisect run -- go test ./pkg/parser`,
+}
+
+var bisectStartCmd = &cobra.Command{
+	Use:   "start <bad> <good>...",
+	Short: "Start a bisect session in a new worktree",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		fmt.Printf("🔎 Bisecting between '%s' (bad) and %s (good)...\n", args[0], quoteRevs(args[1:]))
+		state, err := manager.StartBisect(cmd.Context(), os.Stdout, args[0], args[1:]...)
+		if err != nil {
+			return err
+		}
+		return reportBisect(cmd, manager, state)
+	},
+}
+
+var bisectResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "End the bisect session and remove its worktree",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		wt, err := manager.Bisection(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if err := manager.EndBisect(cmd.Context()); err != nil {
+			return err
+		}
+		fmt.Println("🧹 Ended the bisect session and removed its worktree")
+		warnRemovedCwd(wt.Path)
+		return nil
+	},
+}
+
+// newBisectStepCmd returns the command passing the verdict on the commit under
+// test, such as good, on to git.
+func newBisectStepCmd(verdict, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   verdict + " [rev...]",
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBisectStep(cmd, append([]string{verdict}, args...))
+		},
+	}
+}
+
+var bisectRunCmd = &cobra.Command{
+	Use:   "run <command> [args...]",
+	Short: "Test each commit by running a command",
+	Long: `Let git test each commit by running a command in the bisect worktree: exit
+code 0 marks it good, 125 skips it and any other code up to 127 marks it bad.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBisectStep(cmd, append([]string{"run"}, args...))
+	},
+}
+
+// runBisectStep runs 'git bisect' with args in the running session, and ends
+// the session once it found the first bad commit, unless --keep is set.
+func runBisectStep(cmd *cobra.Command, args []string) error {
+	manager, err := newManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize manager: %w", err)
+	}
+
+	state, err := manager.Bisect(cmd.Context(), os.Stdout, args...)
+	if err != nil {
+		return err
+	}
+	return reportBisect(cmd, manager, state)
+}
+
+// reportBisect tells where the bisect session stands, ending it if it is done.
+func reportBisect(cmd *cobra.Command, manager *worktree.Manager, state *worktree.BisectState) error {
+	if state.FirstBad == "" {
+		fmt.Printf("💡 Test it in %s, then run 'giwo bisect good' or 'giwo bisect bad'\n", utils.ShellQuote(displayPath(state.Path)))
+		return nil
+	}
+
+	fmt.Printf("\n🎯 Found the first bad commit: %s\n", state.FirstBad)
+	if bisectKeep {
+		fmt.Printf("💡 The bisect worktree is kept at %s; remove it with 'giwo bisect reset'\n", utils.ShellQuote(displayPath(state.Path)))
+		return nil
+	}
+	if err := manager.EndBisect(cmd.Context()); err != nil {
+		return fmt.Errorf("failed to remove the bisect worktree: %w", err)
+	}
+	fmt.Println("🧹 Removed the bisect worktree")
+	warnRemovedCwd(state.Path)
+	return nil
+}
+
+// warnRemovedCwd tells the user to leave the current directory if it was in the
+// removed worktree at path.
+func warnRemovedCwd(path string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	if wd == path || strings.HasPrefix(wd, path+string(filepath.Separator)) {
+		fmt.Println("💡 Your shell was in the removed worktree; go back with 'giwo back' or 'giwo main'")
+	}
+}
+
+// quoteRevs lists revisions for messages, such as 'v1.4.0', 'v1.3.2'.
+func quoteRevs(revs []string) string {
+	quoted := make([]string, len(revs))
+	for i, rev := range revs {
+		quoted[i] = "'" + rev + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func init() {
+	bisectCmd.PersistentFlags().BoolVar(&bisectKeep, "keep", false, "Keep the bisect worktree once the first bad commit is found")
+	// Flags after the command belong to it
+	bisectRunCmd.Flags().SetInterspersed(false)
+
+	bisectCmd.AddCommand(bisectStartCmd)
+	bisectCmd.AddCommand(newBisectStepCmd("good", "Mark the commit under test, or the given ones, as good"))
+	bisectCmd.AddCommand(newBisectStepCmd("bad", "Mark the commit under test, or the given ones, as bad"))
+	bisectCmd.AddCommand(newBisectStepCmd("skip", "Skip the commit under test, or the given ones, as untestable"))
+	bisectCmd.AddCommand(bisectRunCmd)
+	bisectCmd.AddCommand(bisectResetCmd)
+}
@@ -2,31 +2,60 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/utils"
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cleanDryRun bool
-	cleanForce  bool
+	cleanDryRun             bool
+	cleanForce              bool
+	cleanEphemeral          bool
+	cleanIncludeMaintenance bool
+	cleanOlderThan          string
 )
 
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Remove worktrees for merged branches",
 	Long: `Batch remove worktrees for branches that have been merged into the main branch.
-This excludes main/master/develop branches by default.`,
+This excludes main/master/develop branches and maintenance worktrees
+(created with 'giwo create --from-tag') by default.
+
+With --ephemeral, remove worktrees created with 'giwo create --ephemeral'
+instead, regardless of whether they have been merged.
+
+With --older-than, remove worktrees nobody has worked in for the given time,
+e.g. 30d or 6w, instead. Activity is the latest commit, branch switch, staging
+or edit of a changed file. Worktrees with uncommitted changes are kept.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
+		defer reportProgress(manager, "auto")()
 
 		ctx := cmd.Context()
+		if cleanEphemeral {
+			return cleanEphemeralWorktrees(cmd, manager)
+		}
+		if cleanOlderThan != "" {
+			age, err := config.ParseAge(cleanOlderThan)
+			if err != nil {
+				return err
+			}
+			return cleanInactiveWorktrees(cmd, manager, age)
+		}
+
 		mergedBranches, err := manager.GetMergedBranches(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get merged branches: %w", err)
@@ -37,21 +66,26 @@ This excludes main/master/develop branches by default.`,
 			return nil
 		}
 
-		worktrees, err := manager.List(ctx)
+		worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
 		if err != nil {
 			return fmt.Errorf("failed to list worktrees: %w", err)
 		}
 
-		worktreeMap := make(map[string]*worktree.Worktree)
-		for _, wt := range worktrees {
-			worktreeMap[wt.Branch] = wt
-		}
-
-		var toRemove []string
-		for _, branch := range mergedBranches {
-			if _, exists := worktreeMap[branch]; exists {
-				toRemove = append(toRemove, branch)
+		var toRemove []*worktree.Worktree
+		for _, wt := range mergedWorktrees(worktrees, mergedBranches) {
+			name := worktreeName(manager, wt)
+			if wt.Locked {
+				fmt.Printf("🔒 Skipping locked worktree '%s' (%s)\n", name, formatLockReason(wt))
+				continue
+			}
+			if wt.Kind == worktree.KindMaintenance && !cleanIncludeMaintenance {
+				fmt.Printf("🔧 Skipping maintenance worktree '%s' (use --include-maintenance)\n", name)
+				continue
 			}
+			if hasNestedChanges(ctx, manager, name, wt.Path) {
+				continue
+			}
+			toRemove = append(toRemove, wt)
 		}
 
 		if len(toRemove) == 0 {
@@ -60,13 +94,12 @@ This excludes main/master/develop branches by default.`,
 		}
 
 		fmt.Printf("🧹 Found %d worktree(s) for merged branches:\n", len(toRemove))
-		for _, branch := range toRemove {
-			wt := worktreeMap[branch]
+		for _, wt := range toRemove {
 			status := "clean"
 			if !wt.IsClean {
 				status = "⚠️  dirty"
 			}
-			fmt.Printf("  - %s (%s)\n", branch, status)
+			fmt.Printf("  - %s (%s)\n", worktreeName(manager, wt), status)
 		}
 
 		if cleanDryRun {
@@ -84,13 +117,17 @@ This excludes main/master/develop branches by default.`,
 			}
 		}
 
+		warnJJ(manager, "the jj bookmarks of the deleted branches are deleted too once jj imports from git")
 		removed := 0
-		for _, branch := range toRemove {
-			fmt.Printf("🗑️  Removing worktree '%s'...\n", branch)
-			if err := manager.Remove(ctx, branch, true, false); err != nil {
-				fmt.Printf("⚠️  Failed to remove '%s': %v\n", branch, err)
+		for _, wt := range toRemove {
+			// The directory is named after the branch, but not always the same, e.g. for namespaced branches
+			name := worktreeName(manager, wt)
+			fmt.Printf("🗑️  Removing worktree '%s'...\n", name)
+			if err := manager.Remove(ctx, worktree.RemoveOptions{Name: name, Force: true}); err != nil {
+				fmt.Printf("⚠️  Failed to remove '%s': %v\n", name, err)
 				continue
 			}
+			recordHistory(manager, history.Removed, wt.Path, wt.Branch)
 			removed++
 		}
 
@@ -99,7 +136,198 @@ This excludes main/master/develop branches by default.`,
 	},
 }
 
+// cleanEphemeralWorktrees removes all worktrees registered as ephemeral.
+func cleanEphemeralWorktrees(cmd *cobra.Command, manager *worktree.Manager) error {
+	ctx := cmd.Context()
+	worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	var toRemove []*worktree.Worktree
+	for _, wt := range worktrees {
+		if !wt.Ephemeral || wt.IsMain {
+			continue
+		}
+		if wt.Locked {
+			fmt.Printf("🔒 Skipping locked worktree '%s' (%s)\n", worktreeName(manager, wt), formatLockReason(wt))
+			continue
+		}
+		if hasNestedChanges(ctx, manager, worktreeName(manager, wt), wt.Path) {
+			continue
+		}
+		toRemove = append(toRemove, wt)
+	}
+
+	if len(toRemove) == 0 {
+		fmt.Println("🧹 No ephemeral worktrees found to clean up")
+		return nil
+	}
+
+	fmt.Printf("🧹 Found %d ephemeral worktree(s):\n", len(toRemove))
+	for _, wt := range toRemove {
+		status := "clean"
+		if !wt.IsClean {
+			status = "⚠️  dirty"
+		}
+		fmt.Printf("  - %s (%s)\n", worktreeName(manager, wt), status)
+	}
+
+	if cleanDryRun {
+		fmt.Printf("\n💡 Run without --dry-run to actually remove these worktrees\n")
+		return nil
+	}
+
+	if !cleanForce {
+		fmt.Printf("\nRemove %d worktree(s)? [y/N]: ", len(toRemove))
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(response)) != "y" {
+			fmt.Println("Operation cancelled")
+			return nil
+		}
+	}
+
+	warnJJ(manager, "the jj bookmarks of the deleted branches are deleted too once jj imports from git")
+	removed := 0
+	for _, wt := range toRemove {
+		name := worktreeName(manager, wt)
+		fmt.Printf("🗑️  Removing worktree '%s'...\n", name)
+		if err := manager.Remove(ctx, worktree.RemoveOptions{Name: name, Force: true}); err != nil {
+			fmt.Printf("⚠️  Failed to remove '%s': %v\n", name, err)
+			continue
+		}
+		recordHistory(manager, history.Removed, wt.Path, wt.Branch)
+		removed++
+	}
+
+	fmt.Printf("✅ Successfully removed %d worktree(s)\n", removed)
+	return nil
+}
+
+// cleanInactiveWorktrees removes clean worktrees without activity for at least age.
+func cleanInactiveWorktrees(cmd *cobra.Command, manager *worktree.Manager, age time.Duration) error {
+	ctx := cmd.Context()
+	worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	var toRemove []*worktree.Worktree
+	for _, wt := range worktree.Inactive(worktrees, age, time.Now()) {
+		name := worktreeName(manager, wt)
+		if wt.IsMain {
+			continue
+		}
+		if wt.Locked {
+			fmt.Printf("🔒 Skipping locked worktree '%s' (%s)\n", name, formatLockReason(wt))
+			continue
+		}
+		if wt.Kind == worktree.KindMaintenance && !cleanIncludeMaintenance {
+			fmt.Printf("🔧 Skipping maintenance worktree '%s' (use --include-maintenance)\n", name)
+			continue
+		}
+		if !wt.IsClean {
+			fmt.Printf("⚠️  Skipping worktree '%s' with uncommitted changes (review with 'giwo remove %s')\n", name, utils.ShellQuote(name))
+			continue
+		}
+		if hasNestedChanges(ctx, manager, name, wt.Path) {
+			continue
+		}
+		toRemove = append(toRemove, wt)
+	}
+
+	if len(toRemove) == 0 {
+		fmt.Printf("🧹 No worktrees inactive for %s found to clean up\n", cleanOlderThan)
+		return nil
+	}
+
+	fmt.Printf("🧹 Found %d worktree(s) inactive for %s:\n", len(toRemove), cleanOlderThan)
+	for _, wt := range toRemove {
+		fmt.Printf("  - %s (last active %s)\n", worktreeName(manager, wt), wt.ActivityAge)
+	}
+
+	if cleanDryRun {
+		fmt.Printf("\n💡 Run without --dry-run to actually remove these worktrees\n")
+		return nil
+	}
+
+	if !cleanForce {
+		fmt.Printf("\nRemove %d worktree(s)? [y/N]: ", len(toRemove))
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(response)) != "y" {
+			fmt.Println("Operation cancelled")
+			return nil
+		}
+	}
+
+	warnJJ(manager, "the jj bookmarks of the deleted branches are deleted too once jj imports from git")
+	removed := 0
+	for _, wt := range toRemove {
+		name := worktreeName(manager, wt)
+		fmt.Printf("🗑️  Removing worktree '%s'...\n", name)
+		if err := manager.Remove(ctx, worktree.RemoveOptions{Name: name, Force: true}); err != nil {
+			fmt.Printf("⚠️  Failed to remove '%s': %v\n", name, err)
+			continue
+		}
+		recordHistory(manager, history.Removed, wt.Path, wt.Branch)
+		removed++
+	}
+
+	fmt.Printf("✅ Successfully removed %d worktree(s)\n", removed)
+	return nil
+}
+
+// hasNestedChanges reports whether repositories nested in the worktree called
+// name at path have work that removing it would lose, which clean never does.
+func hasNestedChanges(ctx context.Context, manager *worktree.Manager, name, path string) bool {
+	nested, err := manager.NestedChanges(ctx, path)
+	if err != nil || len(nested) == 0 {
+		return false
+	}
+	paths := make([]string, len(nested))
+	for i, change := range nested {
+		paths[i] = change.Path
+	}
+	fmt.Printf("🧩 Skipping worktree '%s' with unsaved work in %s (review with 'giwo remove %s')\n", name, strings.Join(paths, ", "), utils.ShellQuote(name))
+	return true
+}
+
+// worktreeName returns the name of a worktree relative to the worktree directory,
+// falling back to its branch for worktrees living elsewhere.
+func worktreeName(manager *worktree.Manager, wt *worktree.Worktree) string {
+	rel, err := filepath.Rel(manager.WorktreeDir(), wt.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return wt.Branch
+	}
+	return rel
+}
+
+// mergedWorktrees returns the linked worktrees that have one of the merged
+// branches checked out, in the order of the branches.
+func mergedWorktrees(worktrees []*worktree.Worktree, merged []string) []*worktree.Worktree {
+	byBranch := make(map[string]*worktree.Worktree, len(worktrees))
+	for _, wt := range worktrees {
+		if !wt.Detached && !wt.IsMain {
+			byBranch[wt.Branch] = wt
+		}
+	}
+
+	var found []*worktree.Worktree
+	for _, branch := range merged {
+		if wt, ok := byBranch[branch]; ok {
+			found = append(found, wt)
+		}
+	}
+	return found
+}
+
 func init() {
 	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "Show what would be removed without actually removing")
 	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "Force removal without confirmation")
+	cleanCmd.Flags().BoolVar(&cleanIncludeMaintenance, "include-maintenance", false, "Also remove merged maintenance worktrees")
+	cleanCmd.Flags().BoolVar(&cleanEphemeral, "ephemeral", false, "Remove worktrees created with --ephemeral instead of merged ones")
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "", "Remove clean worktrees inactive for this long, e.g. 30d, instead of merged ones")
+	cleanCmd.MarkFlagsMutuallyExclusive("ephemeral", "older-than")
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestMergedWorktreeNames(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Parallel()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	manager, err := worktree.NewAt(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wts := filepath.Join(dir, ".worktree")
+	manager.SetWorktreeDir(wts)
+
+	worktrees := []*worktree.Worktree{
+		{Path: dir, Branch: "main", IsMain: true},
+		{Path: filepath.Join(wts, "feature-x"), Branch: "me/feature-x"},
+		{Path: filepath.Join(wts, "fix", "login"), Branch: "fix/login"},
+		{Path: filepath.Join(wts, "wip"), Branch: "wip"},
+		{Path: filepath.Join(wts, "detached"), Branch: "HEAD", Detached: true},
+		{Path: filepath.Join(dir, "..", "elsewhere"), Branch: "elsewhere"},
+	}
+
+	for name, tt := range map[string]struct {
+		merged []string
+		want   []string
+	}{
+		"namespaced branch": {
+			merged: []string{"me/feature-x"},
+			want:   []string{"feature-x"},
+		},
+		"branch with a slash": {
+			merged: []string{"fix/login"},
+			want:   []string{filepath.Join("fix", "login")},
+		},
+		"worktree outside the worktree directory": {
+			merged: []string{"elsewhere"},
+			want:   []string{"elsewhere"},
+		},
+		"main and detached worktrees are kept": {
+			merged: []string{"main", "HEAD"},
+		},
+		"branch without a worktree": {
+			merged: []string{"gone", "wip"},
+			want:   []string{"wip"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, wt := range mergedWorktrees(worktrees, tt.merged) {
+				got = append(got, worktreeName(manager, wt))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("merged worktree names mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
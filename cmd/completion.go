@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+// completeWorktrees returns a completion function suggesting worktrees with a short status
+// description. Completion must be fast, so it uses the status recorded by the last list
+// instead of recomputing it. If byName is set, worktrees are completed by their directory
+// name (as expected by remove) instead of their branch, and the main worktree is omitted.
+func completeWorktrees(byName bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		worktrees, err := manager.ListFast(cmd.Context())
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var completions []string
+		for _, wt := range worktrees {
+			name := wt.Branch
+			if byName {
+				if wt.IsMain {
+					continue
+				}
+				name = worktreeName(manager, wt)
+			}
+			if !strings.HasPrefix(name, toComplete) {
+				continue
+			}
+			completions = append(completions, name+"\t"+completionDescription(wt))
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionDescription summarizes the status of a worktree for shell completion.
+func completionDescription(wt *worktree.Worktree) string {
+	var parts []string
+
+	if wt.IsMain {
+		parts = append(parts, "main")
+	}
+	if wt.Locked {
+		parts = append(parts, "locked")
+	}
+	if wt.Detached {
+		parts = append(parts, "detached")
+	}
+
+	// Status fields are zero when the worktree has not been listed yet
+	if wt.CommitAge != "" {
+		if wt.IsClean {
+			parts = append(parts, "clean")
+		} else {
+			parts = append(parts, "dirty")
+		}
+		if wt.Ahead > 0 || wt.Behind > 0 {
+			parts = append(parts, fmt.Sprintf("+%d/-%d", wt.Ahead, wt.Behind))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	switchCmd.ValidArgsFunction = completeWorktrees(false)
+	removeCmd.ValidArgsFunction = completeWorktrees(true)
+	lockCmd.ValidArgsFunction = completeWorktrees(false)
+	unlockCmd.ValidArgsFunction = completeWorktrees(false)
+	showCmd.ValidArgsFunction = completeWorktrees(false)
+	whereCmd.ValidArgsFunction = completeWorktrees(false)
+	landCmd.ValidArgsFunction = completeWorktrees(false)
+}
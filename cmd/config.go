@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// newManager creates a worktree manager configured from the user and repository config files.
+func newManager() (*worktree.Manager, error) {
+	return newManagerAt("")
+}
+
+// loadConfig loads the configuration that applies to the manager's repository,
+// including the profile selected with --profile or matched by the origin remote.
+func loadConfig(manager *worktree.Manager) (*config.Config, error) {
+	return config.Load(manager.RepoRoot(), profileName, manager.RemoteURL(context.Background()))
+}
+
+// newManagerAt creates a configured worktree manager for the repository containing dir.
+func newManagerAt(dir string) (*worktree.Manager, error) {
+	manager, err := worktree.NewAt(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configureManager(manager, cfg); err != nil {
+		return nil, err
+	}
+	if noCache {
+		manager.DisableCache()
+	}
+	manager.SetOffline(offline)
+	logger, err := newLogger()
+	if err != nil {
+		return nil, err
+	}
+	manager.SetLogger(logger)
+	managers = append(managers, manager)
+
+	return manager, nil
+}
+
+// configureManager applies the settings of cfg to manager. It is also used to
+// apply a reloaded configuration to the manager of a long-running command.
+func configureManager(manager *worktree.Manager, cfg *config.Config) error {
+	if dir := cfg.ResolveWorktreeDir(manager.BaseDir()); dir != "" {
+		manager.SetWorktreeDir(dir)
+	}
+	backend, err := worktree.NewGitBackend(cfg.GitBackend)
+	if err != nil {
+		return err
+	}
+	manager.SetGitBackend(backend)
+	manager.SetTemplateFunc(templateFunc(cfg, ""))
+	manager.SetCheckoutOptions(worktree.CheckoutOptions{
+		Submodules: cfg.Checkout.SubmodulesEnabled(),
+		LFS:        cfg.Checkout.LFSEnabled(),
+		Hooks:      cfg.Checkout.HooksEnabled(),
+	})
+	manager.SetPortOptions(worktree.PortOptions{
+		Step:  cfg.Ports.Step,
+		Slots: cfg.Ports.Slots,
+		Fixed: cfg.Ports.Offsets,
+	})
+	manager.SetPathOptions(worktree.PathOptions{
+		MaxLength:   cfg.Paths.MaxLength,
+		Replacement: cfg.Paths.Replacement,
+	})
+	return nil
+}
+
+// loadUIConfig loads the configuration for the interactive UIs: that of the
+// manager's repository, or the user's in cross-repo mode where manager is nil.
+func loadUIConfig(manager *worktree.Manager) (*config.Config, error) {
+	if manager == nil {
+		return config.Load("", profileName, "")
+	}
+	return loadConfig(manager)
+}
+
+// newFuzzyFinder creates a fuzzy finder over worktrees using the configured key
+// bindings, listing the worktrees by frecency.
+func newFuzzyFinder(manager *worktree.Manager, worktrees []*worktree.Worktree) (*ui.FuzzyFinder, error) {
+	cfg, err := loadUIConfig(manager)
+	if err != nil {
+		return nil, err
+	}
+	finder := ui.NewFuzzyFinder(rankWorktrees(worktrees))
+	if err := configureFinder(finder, cfg); err != nil {
+		return nil, err
+	}
+	return finder, nil
+}
+
+// configureFinder applies the key bindings, age thresholds, auto-selection and theme of cfg to finder.
+// Nothing is changed if they are invalid.
+func configureFinder(finder *ui.FuzzyFinder, cfg *config.Config) error {
+	keymap, err := ui.DefaultFinderKeymap().WithOverrides(cfg.Keymap.Finder)
+	if err != nil {
+		return fmt.Errorf("invalid keymap.finder: %w", err)
+	}
+	ages, err := ageThresholds(cfg)
+	if err != nil {
+		return err
+	}
+	theme, err := uiTheme(cfg)
+	if err != nil {
+		return err
+	}
+	finder.WithKeymap(keymap).WithAgeThresholds(ages).WithAutoSelect(cfg.AutoSelectEnabled()).WithTheme(theme)
+	return nil
+}
+
+// uiTheme returns the theme selected by --theme or theme.name, with the colors
+// of theme.colors, drawing without colors when NO_COLOR is set.
+func uiTheme(cfg *config.Config) (ui.Theme, error) {
+	name := cfg.Theme.Name
+	if themeName != "" {
+		name = themeName
+	}
+	theme, err := ui.LookupTheme(name)
+	if err != nil {
+		return theme, fmt.Errorf("invalid theme: %w", err)
+	}
+	theme, err = theme.WithColors(cfg.Theme.Colors)
+	if err != nil {
+		return theme, fmt.Errorf("invalid theme.colors: %w", err)
+	}
+	if ui.NoColorRequested() {
+		theme = theme.WithoutColor()
+	}
+	return theme, nil
+}
+
+// newChooser creates a chooser over items using the configured finder key
+// bindings and theme.
+func newChooser(cfg *config.Config, header string, items []string) (*ui.Chooser, error) {
+	keymap, err := ui.DefaultFinderKeymap().WithOverrides(cfg.Keymap.Finder)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keymap.finder: %w", err)
+	}
+	theme, err := uiTheme(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return ui.NewChooser(header, items).WithKeymap(keymap).WithTheme(theme), nil
+}
+
+// watchConfig reloads the settings of finder, and of manager unless it is nil
+// in cross-repo mode, when the configuration files change, until ctx is done.
+// They are applied on the finder's goroutine, which also runs the manager's
+// operations, so that neither is changed while in use. An invalid configuration
+// is reported in the finder and the previous settings are kept.
+func watchConfig(ctx context.Context, manager *worktree.Manager, finder *ui.FuzzyFinder) {
+	go newConfigWatcher(ctx, manager).Watch(ctx, func(cfg *config.Config, err error) {
+		finder.Reconfigure(func(f *ui.FuzzyFinder) {
+			if err == nil {
+				err = configureFinder(f, cfg)
+			}
+			if err == nil && manager != nil {
+				if err = configureManager(manager, cfg); err == nil {
+					manager.NotifyConfigReloaded()
+				}
+			}
+			if err != nil {
+				f.SetMessage(fmt.Sprintf("⚠️  Configuration not reloaded: %v", err))
+				return
+			}
+			f.SetMessage("🔄 Configuration reloaded")
+		})
+	})
+}
+
+// newConfigWatcher creates a watcher loading the configuration of manager's
+// repository, or the user's in cross-repo mode where manager is nil. The
+// remote is looked up once, since the manager must not be used from the
+// watcher's goroutine.
+func newConfigWatcher(ctx context.Context, manager *worktree.Manager) *config.Watcher {
+	var repoRoot, remoteURL string
+	if manager != nil {
+		repoRoot = manager.RepoRoot()
+		remoteURL = manager.RemoteURL(ctx)
+	}
+	return config.NewWatcher(config.Paths(repoRoot), func() (*config.Config, error) {
+		return config.Load(repoRoot, profileName, remoteURL)
+	})
+}
+
+// newSelector creates a numbered selector over worktrees using the configured key bindings
+// and theme.
+func newSelector(manager *worktree.Manager, worktrees []*worktree.Worktree) (*ui.Selector, error) {
+	cfg, err := loadUIConfig(manager)
+	if err != nil {
+		return nil, err
+	}
+	keymap, err := ui.DefaultSelectorKeymap().WithOverrides(cfg.Keymap.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keymap.selector: %w", err)
+	}
+	ages, err := ageThresholds(cfg)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := uiTheme(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return ui.NewSelector(worktrees).WithKeymap(keymap).WithAgeThresholds(ages).WithAutoSelect(cfg.AutoSelectEnabled()).WithTheme(theme), nil
+}
+
+// ageThresholds returns the configured commit ages at which worktrees are marked
+// as aging, with defaults for unset ones.
+func ageThresholds(cfg *config.Config) (ui.AgeThresholds, error) {
+	ages := ui.DefaultAgeThresholds()
+	for _, threshold := range []struct {
+		value string
+		dst   *time.Duration
+	}{
+		{cfg.Age.Recent, &ages.Recent},
+		{cfg.Age.Aging, &ages.Aging},
+		{cfg.Age.Stale, &ages.Stale},
+	} {
+		if threshold.value == "" {
+			continue
+		}
+		d, err := config.ParseAge(threshold.value)
+		if err != nil {
+			return ages, err
+		}
+		*threshold.dst = d
+	}
+
+	if ages.Recent > ages.Aging || ages.Aging > ages.Stale {
+		return ages, fmt.Errorf("invalid age thresholds: recent (%s), aging (%s) and stale (%s) must increase", ages.Recent, ages.Aging, ages.Stale)
+	}
+	return ages, nil
+}
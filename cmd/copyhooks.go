@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var copyHooksCmd = &cobra.Command{
+	Use:   "copy-hooks [filter]",
+	Short: "Make the repository's git hooks run in every worktree",
+	Long: `Make the git hooks of the repository run in worktrees created before they
+were set up, as 'giwo create' does for new worktrees.
+
+Linked worktrees share the hooks in the git directory, but a relative
+core.hooksPath, as set by husky or for a .githooks directory, is looked up in
+each worktree. Where a worktree does not have that directory, because it is
+generated on install rather than committed, it is symlinked from the current
+worktree or the main worktree, or copied where symbolic links are not
+available. The link is added to .git/info/exclude so that git status does not
+show it.
+
+Without a filter, all worktrees are handled. Set 'checkout.hooks: false' in the
+config file, or pass --no-hooks to 'giwo create', to leave new worktrees alone.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktrees(false),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		var worktrees []*worktree.Worktree
+		if len(args) > 0 {
+			selected, err := resolveWorktree(ctx, manager, args[0])
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+			worktrees = []*worktree.Worktree{selected}
+		} else {
+			worktrees, err = manager.ListFast(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+		}
+
+		missing := 0
+		for _, wt := range worktrees {
+			status, err := manager.SyncHooks(ctx, wt.Path)
+			if err != nil {
+				return fmt.Errorf("failed to set up the hooks of %s: %w", wt.Path, err)
+			}
+			switch status.State {
+			case worktree.HooksShared:
+				fmt.Printf("✅ %s: shared hooks in %s\n", wt.Path, status.Dir)
+			case worktree.HooksPresent:
+				fmt.Printf("✅ %s: has %s\n", wt.Path, status.HooksPath)
+			case worktree.HooksLinked, worktree.HooksCopied:
+				fmt.Printf("🪝 %s: %s %s from %s\n", wt.Path, status.State, status.HooksPath, status.Source)
+			case worktree.HooksMissing:
+				missing++
+				fmt.Printf("❌ %s: %s does not exist\n", wt.Path, status.HooksPath)
+			}
+		}
+
+		if missing > 0 {
+			fmt.Println("💡 Generate the hooks directory in one worktree, e.g. with 'npm install' for husky, and run 'giwo copy-hooks' again")
+			return fmt.Errorf("%d worktree(s) without hooks", missing)
+		}
+		return nil
+	},
+}
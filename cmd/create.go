@@ -1,68 +1,633 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/knwoop/giwo/internal/config"
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/ui"
 	"github.com/knwoop/giwo/internal/utils"
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
 var (
-	createForce bool
-	createBase  string
+	createForce      bool
+	createBase       string
+	createFrom       string
+	createDetach     bool
+	createEphemeral  bool
+	createFromTag    string
+	createPush       bool
+	createTrack      bool
+	createSwitch     bool
+	createOpen       bool
+	createTmux       bool
+	createProgress   string
+	createIssue      string
+	createTemplate   string
+	createUpdateBase bool
+	createNoSubs     bool
+	createNoLFS      bool
+	createNoHooks    bool
+	createSparse     string
+	createBatch      string
+	createKeepFailed bool
+	createShared     bool
 )
 
 var createCmd = &cobra.Command{
-	Use:   "create <branch-name>",
+	Use:   "create [branch-name]",
 	Short: "Create a new worktree",
 	Long: `Create a new worktree based on the current branch.
 The worktree will be placed in .worktree/<branch-name> directory and
 automatically create and switch to the new branch.
 
 By default, the new worktree will be created from the current branch.
-Use --base to specify a different base branch.`,
-	Args: cobra.ExactArgs(1),
+Use --base to specify a different base branch.
+
+Use --from to start from an arbitrary commit, tag or stash@{n} instead, and
+--detach to skip creating a branch altogether (handy for bisects and builds).
+Without --from, the new branch starts from the base freshly fetched from origin.
+The local branch it starts from, the base or one given to --from, is checked
+against its upstream first: if it is behind, you are offered to fast-forward it,
+which --update-base does without asking.
+Worktrees created with --ephemeral are removed by 'giwo clean --ephemeral'.
+
+If another worktree has the branch checked out, which git does not allow, you
+are offered to switch to that worktree, to create a new branch off it, or to
+check it out in the new worktree as well, which --shared does without asking.
+
+Use --from-tag to start a maintenance branch from a release tag. Maintenance
+worktrees are skipped by 'giwo clean' and kept by 'giwo prune'. Combine with
+--push and --track to publish the branch and set its upstream right away.
+
+Use --issue to name the branch after an issue instead: its title is fetched from
+GitHub, or from Jira for keys like PROJ-123 when issues.jira_url is configured,
+and turned into a name like 1234-fix-login-crash (see issues.branch_template).
+
+The new worktree is set up with the template for its branch: configured files
+are copied, shared paths linked and setup commands run. Use --template to apply
+an extra override; 'giwo template explain <branch>' shows how it is composed.
+
+To enter the new worktree right away, use --switch (changes directory with the
+shell integration loaded, otherwise opens a new shell), --open (opens it in your
+editor) or --tmux (opens it in a new tmux window).
+
+Fetch and checkout progress is shown on standard error when it is a terminal.
+Use --progress=json to emit one JSON progress event per line instead, for
+wrapping tools, or --progress=none to silence it.
+
+Run without a branch name in a terminal to be guided instead: pick the base
+among local and remote branches with fuzzy search, type the branch name (an
+invalid one gets a cleaned-up suggestion), choose a template and confirm the
+worktree path.
+
+Creation is all or nothing: if it is interrupted with Ctrl-C or a setup command
+fails, the new worktree and branch are removed again. Use --keep-on-failure to
+keep them as they are to look into the failure.
+
+Use --batch <file|-> to create several worktrees at once from a list of
+branches, as 'giwo import' does.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runCreateCommand,
 }
 
 func runCreateCommand(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
-	branchName := args[0]
 
-	if err := utils.ValidateBranchName(branchName); err != nil {
-		return fmt.Errorf("invalid branch name: %w", err)
+	if createBatch != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("pass either a branch name or --batch, not both")
+		}
+		return runImport(ctx, createBatch)
 	}
 
-	manager, err := worktree.New()
+	// Without a name, interactive users are walked through the choices
+	wizard := len(args) == 0 && createIssue == "" && isInteractive()
+
+	var branchName string
+	switch {
+	case len(args) == 1 && createIssue != "":
+		return fmt.Errorf("pass either a branch name or --issue, not both")
+	case len(args) == 1:
+		branchName = args[0]
+		if err := utils.ValidateBranchName(branchName); err != nil {
+			return fmt.Errorf("invalid branch name: %w", err)
+		}
+	case createIssue == "" && !wizard:
+		return fmt.Errorf("a branch name or --issue is required")
+	}
+
+	if createDetach && createFrom == "" {
+		return fmt.Errorf("--detach requires --from <commit>")
+	}
+	if createFrom != "" && createBase != "" {
+		return fmt.Errorf("--from and --base cannot be used together")
+	}
+	if createFromTag != "" && (createFrom != "" || createBase != "") {
+		return fmt.Errorf("--from-tag cannot be combined with --from or --base")
+	}
+	if createDetach && (createPush || createTrack) {
+		return fmt.Errorf("--push and --track cannot be used with --detach")
+	}
+	if countTrue(createSwitch, createOpen, createTmux) > 1 {
+		return fmt.Errorf("only one of --switch, --open and --tmux can be used")
+	}
+	switch createProgress {
+	case "auto", "json", "none":
+	default:
+		return fmt.Errorf("invalid --progress %q: must be auto, json or none", createProgress)
+	}
+
+	manager, err := newManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize manager: %w", err)
 	}
+	defer reportProgress(manager, createProgress)()
+	manager.SetRollback(!createKeepFailed)
+
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return err
+	}
+	if wizard {
+		branchName, err = runCreateWizard(ctx, manager, cfg)
+		if err != nil {
+			return err
+		}
+		if branchName == "" {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+	}
+	if createIssue != "" {
+		branchName, err = issueBranchName(ctx, manager, cfg, createIssue)
+		if err != nil {
+			return err
+		}
+	}
+	checkout, err := createCheckoutOptions(cfg)
+	if err != nil {
+		return err
+	}
+	manager.SetCheckoutOptions(checkout)
+	if createTemplate != "" {
+		// Fail before creating anything if the override does not resolve
+		if _, err := cfg.ResolveTemplate(branchName, createTemplate, worktree.ConfigFiles); err != nil {
+			return err
+		}
+		manager.SetTemplateFunc(templateFunc(cfg, createTemplate))
+	}
 
-	baseBranch := createBase
-	if baseBranch == "" {
+	if !createDetach {
+		handled, err := resolveCheckedOut(ctx, manager, cfg, branchName, isInteractive())
+		if handled || err != nil {
+			return err
+		}
+	}
+
+	if createFromTag != "" {
+		fmt.Printf("🔧 Creating maintenance worktree '%s' from tag '%s'...\n", branchName, createFromTag)
+
+		if err := interruptible(ctx, func(ctx context.Context) error {
+			return manager.CreateFromTag(ctx, branchName, createFromTag, createForce)
+		}); err != nil {
+			return err
+		}
+
+		return finishCreateCommand(ctx, manager, branchName, branchName)
+	}
+
+	if createFrom != "" {
+		if err := checkBase(ctx, manager, createFrom, createUpdateBase, isInteractive()); err != nil {
+			return err
+		}
+		if err := checkDirtyBase(ctx, manager, cfg, createFrom); err != nil {
+			return err
+		}
+
+		if createDetach {
+			fmt.Printf("🌱 Creating detached worktree '%s' at '%s'...\n", branchName, createFrom)
+		} else {
+			fmt.Printf("🌱 Creating worktree '%s' from '%s'...\n", branchName, createFrom)
+		}
+
+		if err := interruptible(ctx, func(ctx context.Context) error {
+			return manager.CreateAt(ctx, branchName, createFrom, createDetach, createForce)
+		}); err != nil {
+			return err
+		}
+
+		return finishCreateCommand(ctx, manager, branchName, branchName)
+	}
+
+	branch, err := createFromBase(ctx, manager, cfg, branchName, createBase, createForce, createUpdateBase, isInteractive())
+	if err != nil {
+		return err
+	}
+	if branch == "" {
+		fmt.Println("Operation cancelled.")
+		return nil
+	}
+	return finishCreateCommand(ctx, manager, branchName, branch)
+}
+
+// createFromBase creates the worktree called name on a new branch off base, or
+// off the pristine default branch or the current branch if base is empty, as
+// 'giwo create' does by default: a base behind its upstream is fast-forwarded
+// as checkBase does with update, a dirty base is reported or refused as
+// main.on_dirty says, and a branch name taken by someone else is replaced as
+// branches.on_collision says. Unless interactive, it fails where the user would
+// be asked. It returns the branch created, or "" if the user cancels.
+func createFromBase(ctx context.Context, manager *worktree.Manager, cfg *config.Config, name, base string, force, update, interactive bool) (string, error) {
+	if base == "" {
+		base = pristineBase(ctx, manager)
+	}
+	if base == "" {
 		// Use current branch as default
-		baseBranch, err = manager.GetCurrentBranch(ctx)
+		current, err := manager.GetCurrentBranch(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get current branch: %w", err)
+		}
+		base = current
+	}
+	if err := checkBase(ctx, manager, base, update, interactive); err != nil {
+		return "", err
+	}
+	if err := checkDirtyBase(ctx, manager, cfg, base); err != nil {
+		return "", err
+	}
+
+	branch, err := resolveBranchCollision(ctx, manager, cfg, name, interactive)
+	if err != nil || branch == "" {
+		return "", err
+	}
+	opts := worktree.CreateOptions{Branch: branch, Base: base, Force: force}
+	if branch != name {
+		opts.Name = name
+		fmt.Printf("🌱 Creating worktree '%s' on branch '%s' based on '%s'...\n", name, branch, base)
+	} else {
+		fmt.Printf("🌱 Creating worktree '%s' based on '%s'...\n", name, base)
+	}
+
+	if err := interruptible(ctx, func(ctx context.Context) error {
+		return manager.Create(ctx, opts)
+	}); err != nil {
+		return "", err
+	}
+	return branch, nil
+}
+
+// Choices offered when the branch to create a worktree for is checked out in
+// another worktree.
+const (
+	checkedOutSwitch = "Switch to the worktree that has it"
+	checkedOutBranch = "Create a new branch off it"
+	checkedOutShared = "Check it out here as well"
+)
+
+// resolveCheckedOut handles creating a worktree for branch when another
+// worktree has it checked out, which git refuses: with --shared the branch is
+// checked out in the new worktree as well, and otherwise the user is asked
+// whether to go to the other worktree, branch off it or check it out anyway.
+// Unless interactive, it fails instead of asking. It reports whether it handled
+// the command; if branch is not checked out, creation goes on as usual.
+func resolveCheckedOut(ctx context.Context, manager *worktree.Manager, cfg *config.Config, branch string, interactive bool) (bool, error) {
+	wt, err := manager.CheckedOut(ctx, branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check where '%s' is checked out: %w", branch, err)
+	}
+	if wt == nil {
+		return false, nil
+	}
+
+	fmt.Printf("⚠️  Branch '%s' is already checked out in %s\n", branch, wt.Path)
+	choice := checkedOutShared
+	if !createShared {
+		if !interactive {
+			return true, fmt.Errorf("%w: '%s' in %s\n💡 Use 'giwo switch %s' to go there, or --shared to check it out in the new worktree as well",
+				giwoerrors.ErrBranchCheckedOut, branch, wt.Path, utils.ShellQuote(branch))
+		}
+		header := fmt.Sprintf("'%s' is checked out in %s", branch, wt.Path)
+		chooser, err := newChooser(cfg, header, []string{checkedOutSwitch, checkedOutBranch, checkedOutShared})
+		if err != nil {
+			return true, err
+		}
+		var ok bool
+		choice, ok, err = chooser.Choose()
+		if err != nil {
+			return true, fmt.Errorf("selection failed: %w", err)
+		}
+		if !ok {
+			fmt.Println("Operation cancelled.")
+			return true, nil
+		}
+	}
+
+	switch choice {
+	case checkedOutSwitch:
+		return true, switchTo(manager, wt)
+	case checkedOutBranch:
+		name, err := askBranchName()
+		if err != nil || name == "" {
+			fmt.Println("Operation cancelled.")
+			return true, err
+		}
+		if err := checkDirtyBase(ctx, manager, cfg, branch); err != nil {
+			return true, err
+		}
+		fmt.Printf("🌱 Creating worktree '%s' from '%s'...\n", name, branch)
+		if err := interruptible(ctx, func(ctx context.Context) error {
+			return manager.CreateAt(ctx, name, branch, false, createForce)
+		}); err != nil {
+			return true, err
+		}
+		return true, finishCreateCommand(ctx, manager, name, name)
+	default:
+		fmt.Printf("🌱 Checking out '%s' in a new worktree as well...\n", branch)
+		fmt.Println("⚠️  Commits made in one worktree change the files of the other; keep it for reading or building")
+		if err := interruptible(ctx, func(ctx context.Context) error {
+			return manager.CreateShared(ctx, branch, createForce)
+		}); err != nil {
+			return true, err
+		}
+		return true, finishCreateCommand(ctx, manager, branch, branch)
+	}
+}
+
+// resolveBranchCollision returns the branch to create for a worktree called name:
+// name itself if no branch has it or the branch is the user's own, and otherwise
+// a namespaced alternative picked as branches.on_collision says. Unless
+// interactive, it fails where the user would pick one. It returns "" if the
+// user cancels.
+func resolveBranchCollision(ctx context.Context, manager *worktree.Manager, cfg *config.Config, name string, interactive bool) (string, error) {
+	collision, err := manager.CheckBranchCollision(ctx, name)
+	if err != nil {
+		fmt.Printf("⚠️  Could not check whether '%s' is taken: %v\n", name, err)
+		return name, nil
+	}
+	email := cfg.User.Email
+	if email == "" {
+		email = manager.UserEmail(ctx)
+	}
+	if collision == nil || collision.OwnedBy(email) {
+		return name, nil
+	}
+
+	where := "locally"
+	if collision.Remote {
+		where = "on origin"
+	}
+	fmt.Printf("⚠️  Branch '%s' already exists %s, last committed to by %s\n", name, where, collision.Owner)
+
+	taken := fmt.Errorf("%w: %s", giwoerrors.ErrBranchExists, name)
+	policy := cfg.Branches.OnCollision
+	if policy == config.CollisionFail {
+		return "", taken
+	}
+	namespace := cfg.Branches.Namespace
+	if namespace == "" {
+		namespace = worktree.NamespaceFromEmail(email)
+	}
+	alternatives, err := manager.BranchAlternatives(ctx, name, namespace)
+	if err != nil {
+		return "", err
+	}
+	if len(alternatives) == 0 {
+		return "", taken
+	}
+
+	if policy == config.CollisionNamespace {
+		fmt.Printf("🔀 Using branch '%s' instead\n", alternatives[0])
+		return alternatives[0], nil
+	}
+	if !interactive {
+		return "", fmt.Errorf("%w\n💡 Pass another name, such as '%s', or set branches.on_collision to namespace", taken, alternatives[0])
+	}
+
+	header := fmt.Sprintf("Branch to create instead of '%s' (the worktree keeps its name)", name)
+	chooser, err := newChooser(cfg, header, alternatives)
+	if err != nil {
+		return "", err
+	}
+	choice, ok, err := chooser.Choose()
+	if err != nil || !ok {
+		return "", err
+	}
+	return choice, nil
+}
+
+// checkBase makes sure a new worktree does not silently start from an outdated
+// local branch: if base is behind its upstream, it is fast-forwarded with
+// update, or when the user agrees if interactive, and a warning is printed otherwise.
+func checkBase(ctx context.Context, manager *worktree.Manager, base string, update, interactive bool) error {
+	status, err := manager.CheckBase(ctx, base)
+	if err != nil {
+		// Being offline should not prevent creating worktrees
+		fmt.Printf("⚠️  Could not check whether '%s' is up to date: %v\n", base, err)
+		return nil
+	}
+	if status == nil || status.Behind == 0 {
+		return nil
+	}
+
+	if !status.CanFastForward() {
+		fmt.Printf("⚠️  '%s' has diverged from %s (%d ahead, %d behind); leaving it as it is\n",
+			base, status.Upstream, status.Ahead, status.Behind)
+		return nil
+	}
+
+	if !update && interactive {
+		update = confirm(fmt.Sprintf("⚠️  '%s' is %d commit(s) behind %s. Fast-forward it first?", base, status.Behind, status.Upstream), true)
+	}
+	if !update {
+		fmt.Printf("⚠️  '%s' is %d commit(s) behind %s; leaving it as it is (use --update-base to fast-forward it)\n",
+			base, status.Behind, status.Upstream)
+		return nil
+	}
+
+	if err := manager.UpdateBase(ctx, status); err != nil {
+		return err
+	}
+	fmt.Printf("⏩ Fast-forwarded '%s' by %d commit(s) to %s\n", base, status.Behind, status.Upstream)
+	return nil
+}
+
+// checkDirtyBase tells the user when base is checked out with uncommitted
+// changes, which the new worktree does not get, or refuses to go on, as
+// main.on_dirty says.
+func checkDirtyBase(ctx context.Context, manager *worktree.Manager, cfg *config.Config, base string) error {
+	if cfg.Main.OnDirty == config.DirtyIgnore {
+		return nil
+	}
+	dirty, err := manager.DirtyCheckout(ctx, base)
+	if err != nil {
+		fmt.Printf("⚠️  Could not check '%s' for uncommitted changes: %v\n", base, err)
+		return nil
+	}
+	if dirty == nil {
+		return nil
+	}
+
+	if cfg.Main.OnDirty == config.DirtyRefuse {
+		return fmt.Errorf("%w: '%s' in %s, which the new worktree would not get\n💡 Commit or stash them first, or start from a clean checkout with 'giwo main'",
+			giwoerrors.ErrWorktreeDirty, base, dirty.Path)
+	}
+	fmt.Printf("⚠️  '%s' has uncommitted changes in %s; the new worktree starts without them\n", base, dirty.Path)
+	return nil
+}
+
+// interruptible runs create with a context that Ctrl-C cancels, so that an
+// interrupted creation can be rolled back instead of giwo being killed midway.
+func interruptible(ctx context.Context, create func(ctx context.Context) error) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := create(ctx)
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", giwoerrors.ErrOperationCancelled, err)
+	}
+	return err
+}
+
+// createCheckoutOptions returns the checkout options of the config file with
+// the --no-submodules, --no-lfs, --no-hooks and --sparse flags applied.
+func createCheckoutOptions(cfg *config.Config) (worktree.CheckoutOptions, error) {
+	checkout := worktree.CheckoutOptions{
+		Submodules: cfg.Checkout.SubmodulesEnabled() && !createNoSubs,
+		LFS:        cfg.Checkout.LFSEnabled() && !createNoLFS,
+		Hooks:      cfg.Checkout.HooksEnabled() && !createNoHooks,
+	}
+	if createSparse != "" {
+		dirs, err := cfg.ResolveSparse(createSparse)
 		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
+			return checkout, fmt.Errorf("invalid --sparse: %w", err)
 		}
+		checkout.Sparse = dirs
+	}
+	return checkout, nil
+}
+
+// finishCreateCommand handles the steps shared by all create modes once the worktree
+// called name exists with the given branch.
+func finishCreateCommand(ctx context.Context, manager *worktree.Manager, name, branch string) error {
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return err
+	}
+	if err := manager.SetIdentity(ctx, cfg.User.Name, cfg.User.Email); err != nil {
+		fmt.Printf("⚠️  Warning: failed to set git identity: %v\n", err)
 	}
 
-	fmt.Printf("🌱 Creating worktree '%s' based on '%s'...\n", branchName, baseBranch)
+	if createPush {
+		fmt.Printf("📤 Pushing '%s' to origin...\n", branch)
+		if err := manager.Push(ctx, branch, createTrack); err != nil {
+			return err
+		}
+	} else if createTrack {
+		if err := manager.SetUpstream(ctx, branch); err != nil {
+			return err
+		}
+	}
 
-	if err := manager.Create(ctx, branchName, baseBranch, createForce); err != nil {
-		return fmt.Errorf("failed to create worktree: %w", err)
+	if createEphemeral {
+		if err := manager.MarkEphemeral(name); err != nil {
+			return fmt.Errorf("failed to mark worktree as ephemeral: %w", err)
+		}
 	}
 
-	worktreePath := fmt.Sprintf("%s/%s", manager.WorktreeDir(), branchName)
+	worktreePath := manager.WorktreePath(name)
+	if createTemplate != "" {
+		if err := manager.RecordTemplate(worktreePath, createTemplate); err != nil {
+			fmt.Printf("⚠️  Warning: failed to record the worktree template: %v\n", err)
+		}
+	}
+	recordHistory(manager, history.Created, worktreePath, branch)
 	fmt.Printf("✅ Worktree created successfully at: %s\n", worktreePath)
-	fmt.Printf("💡 Run 'cd %s' to switch to the new worktree\n", worktreePath)
+	warnJJNested(manager, worktreePath)
+	if createEphemeral {
+		fmt.Printf("🧹 Marked as ephemeral; 'giwo clean --ephemeral' will remove it\n")
+	}
 
+	switch {
+	case createSwitch:
+		return changeDirectory(worktreePath)
+	case createOpen:
+		return openInEditor(cfg, worktreePath)
+	case createTmux:
+		return openInTmux(name, worktreePath)
+	}
+
+	fmt.Printf("💡 Switch to the new worktree with: cd %s\n", utils.ShellQuote(worktreePath))
 	return nil
 }
 
+// reportProgress sets up progress reporting for the manager's long operations in the
+// given mode and returns a function that ends the output. In auto mode their steps
+// are shown on stderr unless --quiet is set, with git's progress on a terminal.
+func reportProgress(manager *worktree.Manager, mode string) func() {
+	switch {
+	case mode == "json":
+		encoder := json.NewEncoder(os.Stderr)
+		manager.SetProgress(func(event worktree.ProgressEvent) {
+			_ = encoder.Encode(event)
+		})
+	case mode == "auto" && !quiet:
+		tty := ui.IsStderrTerminal()
+		reporter := ui.NewStepReporter(os.Stderr, tty)
+		if cfg, err := loadConfig(manager); err == nil {
+			if theme, err := uiTheme(cfg); err == nil {
+				reporter.WithTheme(theme)
+			}
+		}
+		manager.SetSteps(reporter.Start)
+		if tty {
+			manager.SetProgress(reporter.Progress)
+		}
+		return reporter.Stop
+	}
+	return func() {}
+}
+
+// countTrue returns the number of true values.
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
 
 func init() {
-	createCmd.Flags().BoolVar(&createForce, "force", false, "Force creation even if directory exists")
+	createCmd.Flags().BoolVar(&createForce, "force", false, "Force creation even if the directory exists")
 	createCmd.Flags().StringVar(&createBase, "base", "", "Base branch to create worktree from (default: current branch)")
+	createCmd.Flags().StringVar(&createFrom, "from", "", "Commit, tag or stash@{n} to create the worktree at")
+	createCmd.Flags().BoolVar(&createDetach, "detach", false, "Create a detached HEAD worktree without a branch (requires --from)")
+	createCmd.Flags().BoolVar(&createUpdateBase, "update-base", false, "Fast-forward the base or --from branch if it is behind its upstream without asking")
+	createCmd.Flags().StringVar(&createFromTag, "from-tag", "", "Release tag to create a maintenance branch from")
+	createCmd.Flags().BoolVar(&createPush, "push", false, "Push the new branch to origin")
+	createCmd.Flags().BoolVar(&createTrack, "track", false, "Set origin/<branch-name> as the upstream of the new branch")
+	createCmd.Flags().BoolVar(&createSwitch, "switch", false, "Change into the new worktree after creating it")
+	createCmd.Flags().BoolVar(&createOpen, "open", false, "Open the new worktree in your editor")
+	createCmd.Flags().BoolVar(&createTmux, "tmux", false, "Open the new worktree in a new tmux window")
+	createCmd.Flags().StringVar(&createProgress, "progress", "auto", "Progress output: auto, json or none")
+	createCmd.Flags().StringVar(&createIssue, "issue", "", "Name the branch after a GitHub issue number or Jira key, e.g. 1234 or PROJ-123")
+	createCmd.Flags().StringVar(&createTemplate, "template", "", "Apply the override with this key from the overrides setting")
+	createCmd.Flags().BoolVar(&createNoSubs, "no-submodules", false, "Do not check out the submodules of the new worktree")
+	createCmd.Flags().BoolVar(&createNoLFS, "no-lfs", false, "Do not download the Git LFS objects of the new worktree")
+	createCmd.Flags().BoolVar(&createNoHooks, "no-hooks", false, "Do not link the git hooks directory into the new worktree")
+	createCmd.Flags().StringVar(&createSparse, "sparse", "", "Check out only these comma-separated directories, or those of a sparse profile from the config file")
+	createCmd.Flags().StringVar(&createBatch, "batch", "", "Create the worktrees listed in this file, or - for standard input (see 'giwo import')")
+	createCmd.Flags().BoolVar(&createEphemeral, "ephemeral", false, "Register the worktree for removal by 'giwo clean --ephemeral'")
+	createCmd.Flags().BoolVar(&createShared, "shared", false, "Check the branch out in the new worktree as well if another worktree has it checked out")
+	createCmd.Flags().BoolVar(&createKeepFailed, "keep-on-failure", false, "Keep the worktree and branch when creation is interrupted or a setup command fails")
 }
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// gitIn runs git in dir with a fixed identity and without the user's
+// configuration, failing the test if it fails.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=giwo", "-c", "user.email=giwo@example.com", "-c", "init.defaultBranch=main"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL="+os.DevNull, "GIT_CONFIG_NOSYSTEM=1")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// TestCreateFromBaseChecksBase checks that a worktree created off --base has
+// its base compared with the upstream like one created with --from.
+func TestCreateFromBaseChecksBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	for name, tt := range map[string]struct {
+		upstream     string // the branch of origin main tracks
+		update       bool
+		fastForwards bool // whether the local main ends up at origin/main
+	}{
+		"stale upstream": {
+			upstream: "main",
+		},
+		"stale upstream with --update-base": {
+			upstream:     "main",
+			update:       true,
+			fastForwards: true,
+		},
+		"missing upstream": {
+			upstream: "gone",
+			update:   true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			origin := filepath.Join(root, "origin.git")
+			repo := filepath.Join(root, "repo")
+			gitIn(t, root, "init", "--quiet", "--bare", origin)
+			gitIn(t, root, "init", "--quiet", repo)
+			gitIn(t, repo, "commit", "--quiet", "--allow-empty", "-m", "first")
+			gitIn(t, repo, "commit", "--quiet", "--allow-empty", "-m", "second")
+			gitIn(t, repo, "remote", "add", "origin", origin)
+			gitIn(t, repo, "push", "--quiet", "origin", "main")
+			gitIn(t, repo, "branch", "--set-upstream-to", "origin/main")
+			if tt.upstream != "main" {
+				gitIn(t, repo, "config", "branch.main.merge", "refs/heads/"+tt.upstream)
+			}
+			// A week-old main, behind what origin has
+			gitIn(t, repo, "reset", "--quiet", "--hard", "HEAD~1")
+			stale := gitIn(t, repo, "rev-parse", "main")
+			latest := gitIn(t, repo, "rev-parse", "origin/main")
+
+			manager, err := worktree.NewAt(repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			manager.SetWorktreeDir(filepath.Join(root, "trees"))
+
+			branch, err := createFromBase(context.Background(), manager, &config.Config{}, "feature", "main", false, tt.update, false)
+			if err != nil {
+				t.Fatalf("createFromBase() error = %v", err)
+			}
+			if diff := cmp.Diff("feature", branch); diff != "" {
+				t.Errorf("branch mismatch (-want +got):\n%s", diff)
+			}
+
+			expected := stale
+			if tt.fastForwards {
+				expected = latest
+			}
+			if diff := cmp.Diff(expected, gitIn(t, repo, "rev-parse", "main")); diff != "" {
+				t.Errorf("main mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(latest, gitIn(t, filepath.Join(root, "trees", "feature"), "rev-parse", "HEAD")); diff != "" {
+				t.Errorf("feature mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+// diffToolDefault is the --tool value meaning "the difftool configured in git".
+const diffToolDefault = "default"
+
+var (
+	diffStat        bool
+	diffFilesOnly   bool
+	diffTool        string
+	diffWorkingTree bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [a] [b]",
+	Short: "Compare two worktrees",
+	Long: `Show the diff between two worktrees. Worktrees not given as arguments are
+selected with the fuzzy finder.
+
+By default the committed state of both branches is compared. With --working-tree,
+uncommitted changes to tracked files are included on both sides.
+
+Use --stat for a diffstat, --files-only for the changed paths, or --tool to open
+git's configured difftool (or --tool=<name> for a specific one) on both trees.`,
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeWorktrees(false),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffStat && diffFilesOnly {
+			return fmt.Errorf("--stat and --files-only cannot be used together")
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		a, err := selectDiffWorktree(ctx, manager, worktrees, args, 0, "Select first worktree")
+		if err != nil || a == nil {
+			return err
+		}
+
+		var others []*worktree.Worktree
+		for _, wt := range worktrees {
+			if wt.Path != a.Path {
+				others = append(others, wt)
+			}
+		}
+		b, err := selectDiffWorktree(ctx, manager, others, args, 1, fmt.Sprintf("Compare '%s' with", ui.DisplayName(a)))
+		if err != nil || b == nil {
+			return err
+		}
+
+		revA, err := manager.ComparableRevision(ctx, a, diffWorkingTree)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", a.Path, err)
+		}
+		revB, err := manager.ComparableRevision(ctx, b, diffWorkingTree)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", b.Path, err)
+		}
+
+		gitArgs := []string{"diff"}
+		switch {
+		case diffTool != "":
+			gitArgs = []string{"difftool", "--dir-diff", "--no-prompt"}
+			if diffTool != diffToolDefault {
+				gitArgs = append(gitArgs, "--tool="+diffTool)
+			}
+		case diffStat:
+			gitArgs = append(gitArgs, "--stat")
+		case diffFilesOnly:
+			gitArgs = append(gitArgs, "--name-status")
+		}
+		gitArgs = append(gitArgs, revA, revB)
+
+		fmt.Fprintf(os.Stderr, "🔍 Comparing '%s' with '%s'\n", ui.DisplayName(a), ui.DisplayName(b))
+
+		gitCmd := exec.CommandContext(ctx, "git", gitArgs...)
+		gitCmd.Dir = manager.RepoRoot()
+		gitCmd.Stdin = os.Stdin
+		gitCmd.Stdout = os.Stdout
+		gitCmd.Stderr = os.Stderr
+		return gitCmd.Run()
+	},
+}
+
+// selectDiffWorktree returns the worktree named by args[i], or lets the user pick one
+// from candidates. It returns nil without an error if the user cancels.
+func selectDiffWorktree(ctx context.Context, manager *worktree.Manager, candidates []*worktree.Worktree, args []string, i int, header string) (*worktree.Worktree, error) {
+	if i < len(args) {
+		return resolveWorktree(ctx, manager, args[i])
+	}
+
+	if !isInteractive() {
+		return nil, fmt.Errorf("two worktrees are required in non-interactive mode")
+	}
+
+	finder, err := newFuzzyFinder(manager, candidates)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := finder.WithHeader(header).Search()
+	if err != nil {
+		return nil, fmt.Errorf("selection failed: %w", err)
+	}
+	if selected == nil {
+		fmt.Println("Operation cancelled.")
+	}
+	return selected, nil
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false, "Show a diffstat instead of the full diff")
+	diffCmd.Flags().BoolVar(&diffFilesOnly, "files-only", false, "Only list the changed files")
+	diffCmd.Flags().BoolVar(&diffWorkingTree, "working-tree", false, "Include uncommitted changes to tracked files")
+	diffCmd.Flags().StringVar(&diffTool, "tool", "", "Open the trees in an external diff tool (git's difftool, or the named tool)")
+	diffCmd.Flags().Lookup("tool").NoOptDefVal = diffToolDefault
+}
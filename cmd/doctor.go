@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+// doctorFixPermissions repairs ownership and permission problems instead of only reporting them.
+var doctorFixPermissions bool
+
+// doctorCheck is the outcome of a single diagnostic.
+type doctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+	Fix    string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment and repository",
+	Long: `Check that giwo can work reliably in the current environment and repository:
+git version compatibility, shell integration, configuration files, orphaned
+worktree metadata, permissions of the worktree directory, and ownership of the
+worktrees and the git directory, which breaks git when several users share a clone.
+Each problem is reported together with a suggested fix.
+
+With --fix-permissions, ownership problems are repaired as far as possible: worktrees
+of other users are added to git's safe.directory, and write access is restored to
+your own files. Run as root, everything is handed over to the owner of the git
+directory. Problems that need another user are printed with the command to run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		checks := []doctorCheck{
+			checkGitVersion(ctx),
+			checkShellIntegration(),
+		}
+
+		// The remaining checks need a repository, but should still run with a broken config
+		manager, err := worktree.New()
+		if err != nil {
+			checks = append(checks, doctorCheck{
+				Name:   "repository",
+				Detail: err.Error(),
+				Fix:    "Run 'giwo doctor' inside a git repository to check it as well",
+			})
+		} else {
+			cfg, configChecks := checkConfigFiles(manager.RepoRoot())
+			checks = append(checks, configChecks...)
+			if dir := cfg.ResolveWorktreeDir(manager.BaseDir()); dir != "" {
+				manager.SetWorktreeDir(dir)
+			}
+
+			checks = append(checks,
+				checkProfile(ctx, manager),
+				checkOrphanedWorktrees(ctx, manager),
+				checkWorktreeDirPermissions(manager.WorktreeDir()),
+				checkOwnership(ctx, manager, doctorFixPermissions),
+			)
+		}
+
+		problems := 0
+		fmt.Println("🩺 giwo doctor")
+		for _, check := range checks {
+			if check.OK {
+				fmt.Printf("  ✅ %s: %s\n", check.Name, check.Detail)
+				continue
+			}
+			problems++
+			fmt.Printf("  ❌ %s: %s\n", check.Name, check.Detail)
+			if check.Fix != "" {
+				fmt.Printf("     💡 %s\n", check.Fix)
+			}
+		}
+
+		if problems > 0 {
+			return fmt.Errorf("doctor found %d problem(s)", problems)
+		}
+
+		fmt.Println("\n✅ Everything looks good")
+		return nil
+	},
+}
+
+// checkGitVersion verifies that the installed git supports all worktree subcommands giwo uses.
+func checkGitVersion(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "git version"}
+
+	version, err := worktree.GitVersion(ctx)
+	if err != nil {
+		check.Detail = err.Error()
+		check.Fix = "Install git and make sure it is on your PATH"
+		return check
+	}
+
+	minimum := fmt.Sprintf("%d.%d", worktree.MinimumGitVersion[0], worktree.MinimumGitVersion[1])
+	if !worktree.IsSupportedGitVersion(version) {
+		check.Detail = fmt.Sprintf("git %s is older than the required %s", version, minimum)
+		check.Fix = fmt.Sprintf("Upgrade git to %s or newer", minimum)
+		return check
+	}
+
+	check.OK = true
+	check.Detail = fmt.Sprintf("git %s (>= %s)", version, minimum)
+	return check
+}
+
+// checkShellIntegration verifies that the shell wrapper from scripts/giwo-switch.sh,
+// or scripts/giwo-switch.ps1 in PowerShell, is loaded.
+func checkShellIntegration() doctorCheck {
+	check := doctorCheck{Name: "shell integration"}
+
+	if os.Getenv("GIWO_SHELL_INTEGRATION") == "" {
+		check.Detail = "not loaded in this shell, so 'giwo switch' cannot change directory"
+		check.Fix = "Add 'source /path/to/giwo/scripts/giwo-switch.sh' to your .bashrc or .zshrc"
+		if utils.UserShell().Kind == utils.ShellPowerShell {
+			check.Fix = "Add '. C:\\path\\to\\giwo\\scripts\\giwo-switch.ps1' to your PowerShell $PROFILE"
+		}
+		return check
+	}
+
+	check.OK = true
+	check.Detail = "loaded"
+	return check
+}
+
+// checkProfile reports which configuration profile applies to the repository.
+func checkProfile(ctx context.Context, manager *worktree.Manager) doctorCheck {
+	check := doctorCheck{Name: "profile"}
+
+	cfg, err := config.Load(manager.RepoRoot(), profileName, manager.RemoteURL(ctx))
+	if err != nil {
+		check.Detail = err.Error()
+		check.Fix = "Pass a profile defined under 'profiles' in your config"
+		return check
+	}
+
+	check.OK = true
+	switch {
+	case cfg.ActiveProfile != "":
+		check.Detail = fmt.Sprintf("using %q", cfg.ActiveProfile)
+	case len(cfg.Profiles) > 0:
+		check.Detail = "no profile matches the origin remote"
+	default:
+		check.Detail = "no profiles configured"
+	}
+	return check
+}
+
+// checkConfigFiles validates every configuration file that exists for the repository.
+// It returns the merged configuration of the valid files.
+func checkConfigFiles(repoRoot string) (*config.Config, []doctorCheck) {
+	merged := &config.Config{}
+	var checks []doctorCheck
+
+	found := false
+	for _, path := range config.Paths(repoRoot) {
+		cfg, err := config.LoadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		found = true
+
+		if err != nil {
+			checks = append(checks, doctorCheck{
+				Name:   "config",
+				Detail: err.Error(),
+				Fix:    fmt.Sprintf("Fix or remove %s", path),
+			})
+			continue
+		}
+
+		merged.Merge(cfg)
+		checks = append(checks, doctorCheck{Name: "config", OK: true, Detail: path + " is valid"})
+	}
+
+	if !found {
+		checks = append(checks, doctorCheck{Name: "config", OK: true, Detail: "no config file, using defaults"})
+	}
+
+	return merged, checks
+}
+
+// checkOrphanedWorktrees detects worktrees whose directory is gone and stale giwo metadata.
+func checkOrphanedWorktrees(ctx context.Context, manager *worktree.Manager) doctorCheck {
+	check := doctorCheck{Name: "worktree metadata"}
+
+	worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	var prunable []string
+	for _, wt := range worktrees {
+		if wt.Prunable {
+			prunable = append(prunable, wt.Path)
+		}
+	}
+
+	stale, err := manager.StaleMetadata(ctx)
+	if err != nil {
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Remove the corrupt state in %s", manager.StateDir())
+		return check
+	}
+
+	if len(prunable) == 0 && len(stale) == 0 {
+		check.OK = true
+		check.Detail = "no orphaned worktrees"
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("%d orphaned worktree(s) and %d stale metadata entries", len(prunable), len(stale))
+	for _, path := range append(prunable, stale...) {
+		check.Detail += "\n       - " + path
+	}
+	check.Fix = "Run 'giwo prune' to clean them up"
+	return check
+}
+
+// checkWorktreeDirPermissions verifies that new worktrees can be created in the worktree directory.
+func checkWorktreeDirPermissions(dir string) doctorCheck {
+	check := doctorCheck{Name: "worktree directory"}
+
+	// Walk up to the closest existing directory, which is where creation would happen
+	target := dir
+	for {
+		info, err := os.Stat(target)
+		if err == nil {
+			if !info.IsDir() {
+				check.Detail = fmt.Sprintf("%s is not a directory", target)
+				check.Fix = fmt.Sprintf("Move %s out of the way or configure a different worktree_dir", target)
+				return check
+			}
+			break
+		}
+		parent := filepath.Dir(target)
+		if parent == target {
+			break
+		}
+		target = parent
+	}
+
+	probe, err := os.CreateTemp(target, ".giwo-doctor-*")
+	if err != nil {
+		check.Detail = fmt.Sprintf("%s is not writable: %v", target, err)
+		check.Fix = fmt.Sprintf("Fix the permissions of %s (e.g. 'chmod u+w %s') or configure a different worktree_dir", target, target)
+		return check
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	check.OK = true
+	check.Detail = dir + " is writable"
+	return check
+}
+
+// checkOwnership detects worktrees and git directories that another user owns or
+// that cannot be written, repairing them if fix is set.
+func checkOwnership(ctx context.Context, manager *worktree.Manager, fix bool) doctorCheck {
+	check := doctorCheck{Name: "ownership"}
+
+	problems, err := manager.PermissionProblems(ctx)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	if len(problems) == 0 {
+		check.OK = true
+		check.Detail = "worktrees and git directory are usable"
+		return check
+	}
+
+	if !fix {
+		check.Detail = fmt.Sprintf("%d path(s) with ownership or permission problems", len(problems))
+		for _, problem := range problems {
+			check.Detail += fmt.Sprintf("\n       - %s (%s)", problem.Path, problem.Describe())
+		}
+		check.Fix = "Run 'giwo doctor --fix-permissions' to repair them"
+		return check
+	}
+
+	failed := 0
+	var results string
+	for _, problem := range problems {
+		done, err := manager.FixPermission(ctx, problem)
+		if err != nil {
+			failed++
+			if done != "" {
+				err = fmt.Errorf("%s; %w", done, err)
+			}
+			results += fmt.Sprintf("\n       - %s: %v", problem.Path, err)
+			continue
+		}
+		results += fmt.Sprintf("\n       - %s: %s", problem.Path, done)
+	}
+	check.Detail = fmt.Sprintf("repaired %d of %d path(s)", len(problems)-failed, len(problems)) + results
+	if failed > 0 {
+		check.Fix = "Run the suggested commands, then 'giwo doctor' again"
+		return check
+	}
+	check.OK = true
+	return check
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFixPermissions, "fix-permissions", false, "Repair ownership and permission problems of worktrees and the git directory")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+// duOffenderShare is the share of the total above which a worktree is highlighted.
+const duOffenderShare = 0.25
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show the disk usage of worktrees",
+	Long: `Show how much disk space each worktree uses, largest first, to decide what
+to clean up. Everything in a worktree is counted, including ignored files such
+as build artifacts and dependencies, but not worktrees nested in it or symlinked
+shared assets. Worktrees using more than a quarter of the total are marked.
+
+The repository's .git directory holds the objects of all worktrees and is
+reported separately, as removing worktrees does not shrink it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		worktrees, err := manager.ListFast(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		if err := worktree.MeasureDiskUsage(ctx, worktrees); err != nil {
+			return fmt.Errorf("failed to measure disk usage: %w", err)
+		}
+		gitDirSize, err := manager.GitDirSize(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to measure disk usage: %w", err)
+		}
+
+		sort.SliceStable(worktrees, func(i, j int) bool { return worktrees[i].DiskUsage > worktrees[j].DiskUsage })
+
+		var total int64
+		for _, wt := range worktrees {
+			total += wt.DiskUsage
+		}
+
+		w := ui.NewTable(os.Stdout)
+		fmt.Fprintln(w, "SIZE\tBRANCH\tPATH")
+		for _, wt := range worktrees {
+			marker := ""
+			if len(worktrees) > 1 && float64(wt.DiskUsage) > duOffenderShare*float64(total) {
+				marker = "🔥"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ui.FormatSize(wt.DiskUsage), wt.Branch, wt.Path, marker)
+		}
+		w.Flush()
+
+		fmt.Printf("\n💾 Total: %s in %d worktree(s), plus %s in the shared .git directory\n",
+			ui.FormatSize(total), len(worktrees), ui.FormatSize(gitDirSize))
+		return nil
+	},
+}
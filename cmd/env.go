@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var envFormat string
+
+var envCmd = &cobra.Command{
+	Use:   "env [filter]",
+	Short: "Print environment variables describing a worktree",
+	Long: `Print environment variables describing the current worktree, or the one
+matching the filter, for direnv, Makefiles and scripts:
+
+  eval "$(giwo env)"                       # in .envrc or a shell
+  giwo env --format dotenv > .env          # for tools reading .env files
+
+GIWO_WORKTREE_PATH, GIWO_BRANCH, GIWO_REPO (the repository name),
+GIWO_REPO_ROOT (the main worktree) and GIWO_PORT_OFFSET are always printed.
+More variables are configured under 'env' in the config file, as Go templates
+over the fields .Path, .Name, .Branch, .Repo, .RepoRoot and .PortOffset:
+
+  env:
+    COMPOSE_PROJECT_NAME: "{{.Repo}}-{{.Name}}"
+    DATABASE_URL: "postgres://localhost/{{.Repo}}_{{.Name}}"
+    PORT: "{{.Port 3000}}"     # 3000 plus the port offset
+
+The port offset is 0 for the main worktree. Other worktrees get a multiple of
+'ports.step' (default 10) derived from their name, unique among the worktrees
+of the repository and kept for their lifetime. 'ports.offsets' pins the
+offset of a branch.
+
+--format sh (default) prints export statements; --format dotenv prints
+NAME=value lines.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateEnvFormat(envFormat); err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+
+		worktrees, err := manager.ListFast(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+		if len(worktrees) == 0 {
+			return fmt.Errorf("no worktrees found")
+		}
+
+		var selected *worktree.Worktree
+		if len(args) == 0 {
+			selected, err = currentWorktree(manager, worktrees)
+			if err != nil {
+				return err
+			}
+		} else {
+			selected, err = resolveWorktree(ctx, manager, args[0])
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				// stdout is usually eval'd
+				fmt.Fprintln(os.Stderr, "Operation cancelled.")
+				return nil
+			}
+		}
+
+		vars, err := worktreeEnv(ctx, manager, cfg.Env, worktrees, selected)
+		if err != nil {
+			return err
+		}
+
+		printEnv(vars, envFormat)
+		return nil
+	},
+}
+
+// currentWorktree returns the worktree the command runs in, which is the manager's repository root.
+func currentWorktree(manager *worktree.Manager, worktrees []*worktree.Worktree) (*worktree.Worktree, error) {
+	for _, wt := range worktrees {
+		if wt.Path == manager.RepoRoot() {
+			return wt, nil
+		}
+	}
+	return nil, fmt.Errorf("current directory is not in a worktree of this repository")
+}
+
+// worktreeEnv returns the environment variables describing selected, one of worktrees,
+// followed by vars, user-defined templates such as the env setting; see worktree.Env.
+func worktreeEnv(ctx context.Context, manager *worktree.Manager, vars map[string]string, worktrees []*worktree.Worktree, selected *worktree.Worktree) ([][2]string, error) {
+	offset, err := manager.PortOffset(ctx, selected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate port offset: %w", err)
+	}
+
+	// git lists the main worktree first
+	return worktree.Env(worktree.NewEnvData(selected, worktrees[0].Path, offset), vars)
+}
+
+func init() {
+	envCmd.Flags().StringVar(&envFormat, "format", "sh", "Output format: sh or dotenv")
+	envCmd.ValidArgsFunction = completeWorktrees(false)
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/runner"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	execAll  bool
+	execHost string
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [filter] -- <command> [args...]",
+	Short: "Run a command inside worktrees",
+	Long: `Run a command inside the selected worktree, or in every worktree with --all.
+When several worktrees are selected, each output line is prefixed with the worktree name.
+
+With --host, the command runs over SSH on a remote machine that shares the
+repository layout. Hosts and their path mappings are configured in the config file:
+
+  hosts:
+    buildbox:
+      ssh: me@buildbox.internal
+      path_map:
+        /Users/me/src: /home/me/src`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 || dash == len(args) {
+			return fmt.Errorf("missing command; use 'giwo exec [filter] -- <command>'")
+		}
+		if dash > 1 {
+			return fmt.Errorf("expected at most one filter before '--'")
+		}
+		command := args[dash:]
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+
+		run, err := execRunner(cfg, execHost)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		var targets []*worktree.Worktree
+		if execAll {
+			targets, err = manager.List(ctx, worktree.ListOptions{WithStatus: true})
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+		} else {
+			filter := ""
+			if dash == 1 {
+				filter = args[0]
+			}
+			selected, err := resolveWorktree(ctx, manager, filter)
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+			targets = []*worktree.Worktree{selected}
+		}
+
+		var failed []string
+		for _, wt := range targets {
+			if len(targets) == 1 {
+				if err := run.Run(ctx, wt.Path, command, os.Stdout, os.Stderr); err != nil {
+					return fmt.Errorf("command failed in '%s': %w", wt.Branch, err)
+				}
+				break
+			}
+
+			prefix := fmt.Sprintf("[%s] ", wt.Branch)
+			stdout := runner.NewPrefixWriter(os.Stdout, prefix)
+			stderr := runner.NewPrefixWriter(os.Stderr, prefix)
+			if err := run.Run(ctx, wt.Path, command, stdout, stderr); err != nil {
+				fmt.Fprintf(os.Stderr, "%s⚠️  %v\n", prefix, err)
+				failed = append(failed, wt.Branch)
+			}
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("command failed in %d worktree(s): %s", len(failed), strings.Join(failed, ", "))
+		}
+		return nil
+	},
+}
+
+// execRunner returns the runner for the given host, or a local runner if host is empty.
+func execRunner(cfg *config.Config, host string) (runner.Runner, error) {
+	if host == "" {
+		return runner.Local{}, nil
+	}
+
+	hostCfg, ok := cfg.Hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("unknown host %q; configure it under 'hosts' in the config file", host)
+	}
+
+	target := hostCfg.SSH
+	if target == "" {
+		target = host
+	}
+
+	pathMap := make(map[string]string, len(hostCfg.PathMap))
+	for local, remote := range hostCfg.PathMap {
+		pathMap[config.ExpandHome(local)] = remote
+	}
+
+	return runner.SSH{Target: target, PathMap: pathMap}, nil
+}
+
+func init() {
+	execCmd.Flags().BoolVar(&execAll, "all", false, "Run the command in every worktree")
+	execCmd.Flags().StringVar(&execHost, "host", "", "Run the command over SSH on a configured host")
+	execCmd.ValidArgsFunction = completeWorktrees(false)
+}
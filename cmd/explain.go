@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/config"
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var explainJSON bool
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <command> [args...]",
+	Short: "Show the git commands and file operations a command would run",
+	Long: `Print the git commands and file operations a giwo command would run for the
+given arguments, in order, without running any of them. Flags after the command
+are the command's own:
+
+  giwo explain create feature-x --base main --push
+  giwo explain remove feature-x --keep-branch
+  giwo explain rename feature-x feature-y
+  giwo explain mv feature-x archive/feature-x
+
+Git commands are printed with -C and the directory they run in, so that they can
+be run by hand. Changes giwo makes to its own state files, such as recording the
+base branch of a new worktree, are listed by what they record. The plan reflects
+the repository as it is now: template files and shared links are listed only if
+they exist, and the command fails here where it would fail before changing
+anything, such as for a locked worktree. Reading the repository, as with
+'git worktree list' and 'git status', is not listed.
+
+The commands explained are create, remove, rename and mv.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExplainCommand,
+}
+
+func runExplainCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	target, rest, err := rootCmd.Find(args)
+	if err != nil || target == rootCmd {
+		return fmt.Errorf("unknown command %q for 'giwo explain'", args[0])
+	}
+	if err := target.ParseFlags(rest); err != nil {
+		return fmt.Errorf("invalid arguments for '%s': %w", target.Name(), err)
+	}
+	positional := target.Flags().Args()
+	if err := target.ValidateArgs(positional); err != nil {
+		return fmt.Errorf("invalid arguments for '%s': %w", target.Name(), err)
+	}
+
+	manager, err := newManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize manager: %w", err)
+	}
+
+	var (
+		plan  *worktree.Plan
+		notes []string
+	)
+	switch target {
+	case createCmd:
+		plan, notes, err = explainCreate(ctx, manager, positional)
+	case removeCmd:
+		plan, notes, err = explainRemove(ctx, manager, positional[0])
+	case renameCmd:
+		plan, err = explainRename(ctx, manager, positional[0], positional[1])
+	case mvCmd:
+		plan, err = explainMove(ctx, manager, positional)
+	default:
+		return fmt.Errorf("'giwo explain' does not know the %s command; it explains create, remove, rename and mv", target.Name())
+	}
+	if err != nil {
+		return fmt.Errorf("giwo %s would fail: %w", target.Name(), err)
+	}
+
+	if explainJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Command string `json:"command"`
+			*worktree.Plan
+			Notes []string `json:"notes,omitempty"`
+		}{utils.ShellJoin(append([]string{"giwo"}, args...)), plan, notes})
+	}
+
+	fmt.Printf("📋 %s would run:\n", utils.ShellJoin(append([]string{"giwo"}, args...)))
+	for _, note := range notes {
+		fmt.Printf("💡 %s\n", note)
+	}
+	fmt.Println()
+	width := len(fmt.Sprint(len(plan.Steps)))
+	for i, step := range plan.Steps {
+		indent := strings.Repeat(" ", width+4)
+		if line := step.String(); line != "" {
+			fmt.Printf("  %*d. %s\n", width, i+1, line)
+			fmt.Printf("%s# %s\n", indent, step.Note)
+		} else {
+			fmt.Printf("  %*d. # %s\n", width, i+1, step.Note)
+		}
+	}
+	return nil
+}
+
+// explainCreate plans 'giwo create' with its flags as parsed, following
+// runCreateCommand and finishCreateCommand.
+func explainCreate(ctx context.Context, manager *worktree.Manager, args []string) (*worktree.Plan, []string, error) {
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("'giwo explain create' needs a branch name")
+	}
+	branch := args[0]
+	if err := utils.ValidateBranchName(branch); err != nil {
+		return nil, nil, fmt.Errorf("invalid branch name: %w", err)
+	}
+	if createDetach && createFrom == "" {
+		return nil, nil, fmt.Errorf("--detach requires --from <commit>")
+	}
+	if createFrom != "" && createBase != "" {
+		return nil, nil, fmt.Errorf("--from and --base cannot be used together")
+	}
+	if createFromTag != "" && (createFrom != "" || createBase != "") {
+		return nil, nil, fmt.Errorf("--from-tag cannot be combined with --from or --base")
+	}
+
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return nil, nil, err
+	}
+	checkout, err := createCheckoutOptions(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	manager.SetCheckoutOptions(checkout)
+	if createTemplate != "" {
+		if _, err := cfg.ResolveTemplate(branch, createTemplate, worktree.ConfigFiles); err != nil {
+			return nil, nil, err
+		}
+		manager.SetTemplateFunc(templateFunc(cfg, createTemplate))
+	}
+
+	var (
+		plan  *worktree.Plan
+		notes []string
+	)
+	switch {
+	case createFromTag != "":
+		plan, err = manager.PlanCreateFromTag(ctx, branch, createFromTag, createForce)
+	case createFrom != "":
+		if notes, err = dirtyBaseNotes(ctx, manager, cfg, createFrom); err != nil {
+			return nil, nil, err
+		}
+		plan, err = manager.PlanCreateAt(ctx, branch, createFrom, createDetach, createForce)
+	default:
+		base := createBase
+		if base == "" {
+			base = pristineBase(ctx, manager)
+		}
+		if base == "" {
+			if base, err = manager.GetCurrentBranch(ctx); err != nil {
+				return nil, nil, fmt.Errorf("failed to get current branch: %w", err)
+			}
+		}
+		if notes, err = dirtyBaseNotes(ctx, manager, cfg, base); err != nil {
+			return nil, nil, err
+		}
+		email := cfg.User.Email
+		if email == "" {
+			email = manager.UserEmail(ctx)
+		}
+		if collision, err := manager.CheckBranchCollision(ctx, branch); err == nil && collision != nil && !collision.OwnedBy(email) {
+			notes = append(notes, fmt.Sprintf("Branch '%s' is taken by %s, so another name may be used as branches.on_collision says", branch, collision.Owner))
+		}
+		plan, err = manager.PlanCreate(ctx, worktree.CreateOptions{Branch: branch, Base: base, Force: createForce})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manager.PlanIdentity(plan, cfg.User.Name, cfg.User.Email)
+	if createPush {
+		manager.PlanPush(plan, branch, createTrack)
+	} else if createTrack {
+		manager.PlanSetUpstream(plan, branch)
+	}
+	if createEphemeral {
+		manager.PlanMarkEphemeral(plan)
+	}
+	plan.File("record the creation in " + history.Path(config.StateDir()))
+
+	worktreePath := manager.WorktreePath(branch)
+	switch {
+	case createSwitch:
+		notes = append(notes, "Then switches to "+worktreePath)
+	case createOpen:
+		notes = append(notes, "Then opens "+worktreePath+" in the editor")
+	case createTmux:
+		notes = append(notes, "Then opens "+worktreePath+" in tmux")
+	}
+	return plan, notes, nil
+}
+
+// dirtyBaseNotes returns what checkDirtyBase would say about base, or its
+// error if it would refuse to go on.
+func dirtyBaseNotes(ctx context.Context, manager *worktree.Manager, cfg *config.Config, base string) ([]string, error) {
+	if cfg.Main.OnDirty == config.DirtyIgnore {
+		return nil, nil
+	}
+	dirty, err := manager.DirtyCheckout(ctx, base)
+	if err != nil || dirty == nil {
+		return nil, nil
+	}
+	if cfg.Main.OnDirty == config.DirtyRefuse {
+		return nil, fmt.Errorf("%w: '%s' in %s (main.on_dirty is refuse)", giwoerrors.ErrWorktreeDirty, base, dirty.Path)
+	}
+	return []string{fmt.Sprintf("'%s' has uncommitted changes in %s, which the new worktree does not get", base, dirty.Path)}, nil
+}
+
+// explainRemove plans 'giwo remove', which removes the worktree called name
+// even with uncommitted changes once the user confirms.
+func explainRemove(ctx context.Context, manager *worktree.Manager, name string) (*worktree.Plan, []string, error) {
+	plan, err := manager.PlanRemove(ctx, worktree.RemoveOptions{Name: name, Force: true, KeepBranch: removeKeepBranch})
+	if err != nil {
+		return nil, nil, err
+	}
+	plan.File("record the removal in " + history.Path(config.StateDir()))
+
+	var notes []string
+	if !removeForce {
+		notes = append(notes, "Asks for confirmation first")
+	}
+	if nested, err := manager.NestedChanges(ctx, manager.WorktreePath(name)); err == nil {
+		for _, change := range nested {
+			notes = append(notes, "Loses work in the nested repository "+formatNestedChange(change))
+		}
+	}
+	return plan, notes, nil
+}
+
+// explainRename plans 'giwo rename'.
+func explainRename(ctx context.Context, manager *worktree.Manager, oldName, newName string) (*worktree.Plan, error) {
+	if err := utils.ValidateBranchName(newName); err != nil {
+		return nil, fmt.Errorf("invalid branch name: %w", err)
+	}
+	return manager.PlanRename(ctx, oldName, newName)
+}
+
+// explainMove plans 'giwo mv' with the filter, if any, and the destination in args.
+func explainMove(ctx context.Context, manager *worktree.Manager, args []string) (*worktree.Plan, error) {
+	filter, dest := "", args[len(args)-1]
+	if len(args) == 2 {
+		filter = args[0]
+	}
+
+	selected, err := resolveWorktree(ctx, manager, filter)
+	if err != nil {
+		return nil, err
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("no worktree selected")
+	}
+	if selected.IsMain {
+		return nil, fmt.Errorf("the main worktree cannot be moved")
+	}
+	destPath, err := manager.MoveDestination(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	return manager.PlanMove(ctx, worktree.MoveOptions{Path: selected.Path, Dest: destPath, Force: mvForce})
+}
+
+func init() {
+	// Flags after the command belong to it
+	explainCmd.Flags().SetInterspersed(false)
+	explainCmd.Flags().BoolVar(&explainJSON, "json", false, "Print the plan as JSON")
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/stats"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcArchiveRetention string
+	gcMaintenance      bool
+	gcDryRun           bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Clean up giwo's state and run git maintenance",
+	Long: `Clean up what giwo and git keep about worktrees that are gone:
+
+  - worktrees whose directory was deleted are pruned, as with 'giwo prune'
+  - their metadata, labels, cached status and usage stats are dropped
+  - forge information older than a month is dropped
+  - the history is trimmed to its most recent entries
+
+Archives older than gc.archive_retention (or --archive-retention) are deleted;
+without a retention they are kept until restored. With gc.maintenance (or
+--maintenance), 'git maintenance run' runs once for the object store all
+worktrees share, rather than in each of them.
+
+Use --dry-run to see what would be cleaned up.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+
+		opts := worktree.GCOptions{Maintenance: gcMaintenance || cfg.GC.Maintenance, DryRun: gcDryRun}
+		retention := cfg.GC.ArchiveRetention
+		if cmd.Flags().Changed("archive-retention") {
+			retention = gcArchiveRetention
+		}
+		if retention != "" {
+			if opts.ArchiveRetention, err = config.ParseAge(retention); err != nil {
+				return fmt.Errorf("invalid archive retention: %w", err)
+			}
+		}
+
+		stop := reportProgress(manager, "auto")
+		report, err := manager.GC(cmd.Context(), opts)
+		stop()
+		if err != nil {
+			return err
+		}
+
+		// action phrases what was done, or what would be in a dry run
+		action := func(done, would string) string {
+			if gcDryRun {
+				return "Would " + would
+			}
+			return done
+		}
+
+		cleaned := false
+		for _, path := range report.Pruned {
+			fmt.Printf("🧹 %s worktree %s, its directory is gone\n", action("Pruned", "prune"), displayPath(path))
+			cleaned = true
+		}
+		if n := report.Metadata + report.Labels + report.Cache; n > 0 {
+			fmt.Printf("🧹 %s %d state entries of removed worktrees (%d metadata, %d labels, %d cached status)\n",
+				action("Dropped", "drop"), n, report.Metadata, report.Labels, report.Cache)
+			cleaned = true
+		}
+		if report.Remote > 0 {
+			fmt.Printf("🧹 %s forge information of %d branch(es) older than a month\n", action("Dropped", "drop"), report.Remote)
+			cleaned = true
+		}
+		for _, archive := range report.Archives {
+			fmt.Printf("📦 %s archive %s from %s\n", action("Deleted", "delete"), archive.Name, utils.TimeAgo(archive.Time))
+			cleaned = true
+		}
+
+		if n, err := gcUserState(); err != nil {
+			fmt.Printf("⚠️  Warning: failed to clean up the history and stats: %v\n", err)
+		} else if n > 0 {
+			fmt.Printf("🧹 %s %d history and stats entries\n", action("Dropped", "drop"), n)
+			cleaned = true
+		}
+
+		switch {
+		case report.Maintenance:
+			fmt.Println("🔧 Ran git maintenance for the shared object store")
+		case opts.Maintenance && gcDryRun:
+			fmt.Println("🔧 Would run git maintenance for the shared object store")
+		}
+
+		if !cleaned {
+			fmt.Println("✅ Nothing to clean up")
+		} else if gcDryRun {
+			fmt.Println("\n💡 Run without --dry-run to clean up")
+		}
+		return nil
+	},
+}
+
+// gcUserState drops the usage stats of worktrees whose directory is gone and
+// trims the history, both kept across repositories, and returns the number of
+// entries dropped. In a dry run, only the stats are counted.
+func gcUserState() (int, error) {
+	stateDir := config.StateDir()
+	s, err := stats.Load(stateDir)
+	if err != nil {
+		return 0, err
+	}
+	pruned := s.Prune(func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	if gcDryRun {
+		return len(pruned), nil
+	}
+	if len(pruned) > 0 {
+		if err := s.Save(stateDir); err != nil {
+			return 0, err
+		}
+	}
+
+	dropped, err := history.Compact(stateDir)
+	if err != nil {
+		return 0, err
+	}
+	return len(pruned) + dropped, nil
+}
+
+func init() {
+	gcCmd.Flags().StringVar(&gcArchiveRetention, "archive-retention", "", "Delete archives older than this, such as 90d (default: gc.archive_retention)")
+	gcCmd.Flags().BoolVar(&gcMaintenance, "maintenance", false, "Run 'git maintenance run' for the shared object store (default: gc.maintenance)")
+	gcCmd.Flags().BoolVarP(&gcDryRun, "dry-run", "n", false, "Only show what would be cleaned up")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// errorHints suggest how to resolve errors, checked in order with errors.Is.
+var errorHints = []struct {
+	err  error
+	hint string
+}{
+	{giwoerrors.ErrNotGitRepository, "Run giwo inside a git repository"},
+	{giwoerrors.ErrWorktreeExists, "Use 'giwo switch' to go there, or --force to reuse the directory"},
+	{giwoerrors.ErrWorktreeNotFound, "Run 'giwo list' to see the worktrees"},
+	{giwoerrors.ErrWorktreeAmbiguous, "Pass more of the branch name, or the path of the worktree"},
+	{giwoerrors.ErrWorktreeLocked, "Unlock it first with 'giwo unlock'"},
+	{giwoerrors.ErrWorktreeDirty, "Commit or stash the changes first, or pass --force to discard them"},
+	{giwoerrors.ErrBranchExists, "Pick another name, or use 'giwo switch' if the branch has a worktree"},
+	{giwoerrors.ErrBranchNotFound, "Check the name with 'git branch -a', or run 'git fetch' if it is new on the remote"},
+	{giwoerrors.ErrInvalidBranchName, "Branch names cannot contain spaces, '..', '~', '^', ':', '?', '*' or '['"},
+	{giwoerrors.ErrDetachedHead, "Check out a branch first, e.g. with 'git switch -c <name>'"},
+	{giwoerrors.ErrArchiveNotFound, "Run 'giwo restore' without a name to pick an archive"},
+	{giwoerrors.ErrCreateRolledBack, "Pass --keep-on-failure to keep a worktree whose creation fails, to look into it"},
+	{giwoerrors.ErrNoBisect, "Start one with 'giwo bisect start <bad> <good>'"},
+	{giwoerrors.ErrSnapshotNotFound, "Run 'giwo snapshot' to list the saved snapshots, or pass the path of a snapshot file"},
+}
+
+// errorHint returns advice on how to resolve err, or an empty string if there
+// is none or the error message gives some already.
+func errorHint(err error) string {
+	if strings.Contains(err.Error(), "💡") {
+		return ""
+	}
+
+	var checkedOut *giwoerrors.BranchCheckedOutError
+	if errors.As(err, &checkedOut) {
+		return fmt.Sprintf("Use 'giwo switch %s' to go to the worktree at %s", utils.ShellQuote(checkedOut.Branch), displayPath(checkedOut.Path))
+	}
+
+	for _, h := range errorHints {
+		if errors.Is(err, h.err) {
+			return h.hint
+		}
+	}
+	return ""
+}
+
+// displayPath returns path relative to the current directory if that is shorter.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || len(rel) >= len(path) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/knwoop/giwo/internal/config"
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/registry"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyAction string
+	historyRepo   bool
+	historySince  string
+	historyLimit  int
+	historyPage   int
+	historyList   bool
+	historyJSON   bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [filter]",
+	Short: "Browse and revisit the worktrees you created, switched to and removed",
+	Long: `Show the worktrees you created, switched to and removed, most recent first,
+across all repositories.
+
+In a terminal, the history opens in the fuzzy finder with the filter as its
+query; selecting an entry switches to that worktree again. Worktrees that have
+been removed since cannot be switched to, but the branch usually still exists.
+
+With --list, without a terminal, or with --json, the entries whose branch or
+path contains the filter are printed instead, --limit per page. Use --page to
+see older entries.
+
+Entries can be narrowed down to one action with --action, to the current
+repository with --repo, and to recent ones with --since, e.g. 2h, 3d or 2w.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHistoryCommand,
+}
+
+func runHistoryCommand(cmd *cobra.Command, args []string) error {
+	query := history.Query{Action: history.Action(historyAction)}
+	switch query.Action {
+	case "", history.Created, history.Switched, history.Removed:
+	default:
+		return fmt.Errorf("invalid action %q: must be one of %s, %s or %s", historyAction, history.Created, history.Switched, history.Removed)
+	}
+	if historySince != "" {
+		d, err := config.ParseAge(historySince)
+		if err != nil {
+			return err
+		}
+		query.Since = time.Now().Add(-d)
+	}
+
+	var manager *worktree.Manager
+	if historyRepo {
+		var err error
+		manager, err = newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		query.Repo = manager.MainRoot()
+	}
+
+	entries, err := history.Load(config.StateDir())
+	if err != nil {
+		return err
+	}
+
+	filter := ""
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	if isInteractive() && !historyList && !historyJSON {
+		return chooseFromHistory(manager, history.Filter(entries, query), filter)
+	}
+
+	query.Text = filter
+	page, pages := history.Page(history.Filter(entries, query), historyPage, historyLimit)
+
+	if historyJSON {
+		if page == nil {
+			page = []history.Entry{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(page)
+	}
+
+	if len(page) == 0 {
+		if historyPage > 1 {
+			fmt.Printf("No entries on page %d (%d page(s) in total)\n", historyPage, pages)
+		} else {
+			fmt.Println("No history yet")
+		}
+		return nil
+	}
+
+	w := ui.NewTable(os.Stdout)
+	fmt.Fprintln(w, "WHEN\tACTION\tREPO\tBRANCH\tPATH")
+	for _, e := range page {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", utils.TimeAgo(e.Time), e.Action, registry.Name(e.Repo), e.Branch, e.Path)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if historyPage < pages {
+		fmt.Printf("\nPage %d of %d; use --page %d for older entries\n", historyPage, pages, historyPage+1)
+	}
+	return nil
+}
+
+// chooseFromHistory lets the user pick a history entry in the fuzzy finder and
+// switches to its worktree.
+func chooseFromHistory(manager *worktree.Manager, entries []history.Entry, query string) error {
+	if len(entries) == 0 {
+		fmt.Println("No history yet")
+		return nil
+	}
+
+	// Identical lines lead to the same worktree, so the first one is enough
+	items := make([]string, 0, len(entries))
+	byItem := make(map[string]history.Entry, len(entries))
+	for _, e := range entries {
+		item := fmt.Sprintf("%-9s %-7s %s  %s  %s", utils.TimeAgo(e.Time), e.Action, registry.Name(e.Repo), e.Branch, e.Path)
+		if _, ok := byItem[item]; ok {
+			continue
+		}
+		items = append(items, item)
+		byItem[item] = e
+	}
+
+	cfg, err := config.Load("", profileName, "")
+	if err != nil {
+		return err
+	}
+	chooser, err := newChooser(cfg, "Worktree to switch to", items)
+	if err != nil {
+		return err
+	}
+	choice, ok, err := chooser.WithQuery(query).Choose()
+	if err != nil {
+		return fmt.Errorf("selection failed: %w", err)
+	}
+	if !ok {
+		fmt.Println("Operation cancelled.")
+		return nil
+	}
+
+	e := byItem[choice]
+	if _, err := os.Stat(e.Path); err != nil {
+		if e.Branch == "" {
+			return fmt.Errorf("%w: %s no longer exists", giwoerrors.ErrWorktreeNotFound, e.Path)
+		}
+		return fmt.Errorf("%w: %s no longer exists\n💡 Run 'giwo create %s' to recreate it", giwoerrors.ErrWorktreeNotFound, e.Path, utils.ShellQuote(e.Branch))
+	}
+	if manager != nil && e.Repo != manager.MainRoot() {
+		// The worktree belongs to another repository than the one filtered on
+		manager = nil
+	}
+	return switchTo(manager, &worktree.Worktree{Path: e.Path, Branch: e.Branch})
+}
+
+// recordHistory records that the user acted on the worktree at path. manager
+// may be nil, in which case the repository is looked up from path. Failures are
+// ignored, since the history is only a convenience.
+func recordHistory(manager *worktree.Manager, action history.Action, path, branch string) {
+	if manager == nil {
+		var err error
+		if manager, err = worktree.NewAt(path); err != nil {
+			return
+		}
+	}
+	_ = history.Record(config.StateDir(), history.Entry{
+		Time:   time.Now(),
+		Action: action,
+		Repo:   manager.MainRoot(),
+		Path:   path,
+		Branch: branch,
+	})
+	recordStats(action, manager.MainRoot(), path, branch)
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyAction, "action", "", "Only show entries of this action: create, switch or remove")
+	historyCmd.Flags().BoolVar(&historyRepo, "repo", false, "Only show entries of the current repository")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only show entries newer than this age, e.g. 2h, 3d or 2w")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "Number of entries per page with --list or --json")
+	historyCmd.Flags().IntVar(&historyPage, "page", 1, "Page to show with --list or --json, 1 being the most recent")
+	historyCmd.Flags().BoolVar(&historyList, "list", false, "Print the entries instead of opening the fuzzy finder")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Print the entries as JSON")
+}
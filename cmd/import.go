@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var importJobs int
+
+var importCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Create the worktrees listed in a file",
+	Long: `Create a worktree for each branch listed in a file, or on standard input
+with '-', one per line with an optional base branch and template override:
+
+  # streams of the 2.0 release
+  release-2.0
+  feature-billing   release-2.0
+  feature-search    release-2.0   backend
+
+Branches start from the default branch on origin without a base. Blank lines
+and lines starting with # are skipped.
+
+Origin is fetched once, then the worktrees are created at the same time, or at
+most --jobs at once, and a summary tells which ones were created. The command
+exits with 1 unless all of them were. 'giwo create --batch <file|->' does the
+same.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if importJobs < 0 {
+			return fmt.Errorf("invalid --jobs %d: must not be negative", importJobs)
+		}
+		return runImport(cmd.Context(), args[0])
+	},
+}
+
+// runImport creates the worktrees listed in the file at path, or on standard
+// input for "-", and reports which were created.
+func runImport(ctx context.Context, path string) error {
+	entries, err := readImport(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No worktrees to create.")
+		return nil
+	}
+
+	manager, err := newManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize manager: %w", err)
+	}
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return err
+	}
+	checkout, err := createCheckoutOptions(cfg)
+	if err != nil {
+		return err
+	}
+	manager.SetCheckoutOptions(checkout)
+
+	// Fail before creating anything if an override does not resolve
+	overrides := make(map[string]string)
+	for _, entry := range entries {
+		if entry.Template == "" {
+			continue
+		}
+		if _, err := cfg.ResolveTemplate(entry.Branch, entry.Template, worktree.ConfigFiles); err != nil {
+			return fmt.Errorf("%s: %w", entry.Branch, err)
+		}
+		overrides[entry.Branch] = entry.Template
+	}
+	manager.SetTemplateFunc(func(branch string) (worktree.Template, error) {
+		return templateFunc(cfg, overrides[branch])(branch)
+	})
+
+	defaultBranch := manager.DefaultBranch(ctx)
+	opts := make([]worktree.CreateOptions, len(entries))
+	for i, entry := range entries {
+		opts[i] = worktree.CreateOptions{Branch: entry.Branch, Base: entry.Base}
+		if opts[i].Base == "" {
+			opts[i].Base = defaultBranch
+		}
+	}
+
+	fmt.Printf("🌱 Creating %d worktrees...\n", len(opts))
+	errs := manager.CreateAll(ctx, opts, importJobs)
+
+	fmt.Println()
+	failed := 0
+	for i, o := range opts {
+		if errs[i] != nil {
+			fmt.Printf("❌ %s: %v\n", o.Branch, errs[i])
+			failed++
+			continue
+		}
+		path := manager.WorktreePath(o.Branch)
+		recordHistory(manager, history.Created, path, o.Branch)
+		fmt.Printf("✅ %s from %s at %s\n", o.Branch, o.Base, displayPath(path))
+	}
+
+	if failed < len(opts) {
+		if err := manager.SetIdentity(ctx, cfg.User.Name, cfg.User.Email); err != nil {
+			fmt.Printf("⚠️  Warning: failed to set git identity: %v\n", err)
+		}
+	}
+
+	fmt.Printf("\n📊 Created %d of %d worktrees\n", len(opts)-failed, len(opts))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d worktrees could not be created", failed, len(opts))
+	}
+	return nil
+}
+
+// readImport reads the worktrees to create from path, or from standard input for "-".
+func readImport(path string) ([]worktree.ImportEntry, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read worktrees: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return worktree.ParseImport(r)
+}
+
+func init() {
+	importCmd.Flags().IntVarP(&importJobs, "jobs", "j", 0, "Number of worktrees created at once (default: all)")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/github"
+	"github.com/knwoop/giwo/pkg/jira"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// jiraKeyPattern matches Jira issue keys such as PROJ-123.
+var jiraKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// issueBranchName looks up the issue id in the configured tracker and names a branch after it.
+func issueBranchName(ctx context.Context, manager *worktree.Manager, cfg *config.Config, id string) (string, error) {
+	if manager.Offline() {
+		return "", fmt.Errorf("cannot look up issue %s offline\n💡 Name the branch yourself instead: giwo create %s-<title>", id, strings.TrimPrefix(id, "#"))
+	}
+
+	provider := cfg.Issues.Provider
+	if provider == "" {
+		provider = "github"
+		if jiraKeyPattern.MatchString(id) {
+			provider = "jira"
+		}
+	}
+
+	var title string
+	switch provider {
+	case "jira":
+		if cfg.Issues.JiraURL == "" {
+			return "", fmt.Errorf("set issues.jira_url in the config to look up Jira issues")
+		}
+		summary, err := jira.New(cfg.Issues.JiraURL).IssueSummary(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		title = summary
+	default:
+		number, err := strconv.Atoi(strings.TrimPrefix(id, "#"))
+		if err != nil || number <= 0 {
+			return "", fmt.Errorf("invalid GitHub issue number %q", id)
+		}
+		owner, repo, err := manager.GetRepoInfo()
+		if err != nil {
+			return "", fmt.Errorf("--issue requires a GitHub origin: %w", err)
+		}
+		title, err = github.NewWithToken(cfg.GitHubToken).IssueTitle(ctx, owner, repo, number)
+		if err != nil {
+			return "", err
+		}
+		id = strconv.Itoa(number)
+	}
+
+	fmt.Printf("🎫 %s: %s\n", id, title)
+	return utils.IssueBranchName(cfg.Issues.BranchTemplate, id, title)
+}
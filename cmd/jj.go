@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// warnJJ prints a warning about an operation jj sees differently than git when
+// the repository is colocated with jj.
+func warnJJ(manager *worktree.Manager, format string, args ...any) {
+	if manager.JJColocated() {
+		fmt.Printf("⚠️  jj: "+format+"\n", args...)
+	}
+}
+
+// warnJJNested warns that jj commands run in a worktree nested in jj's workspace
+// act on that workspace, since jj finds it by looking for .jj in parent directories
+// and knows nothing about git worktrees.
+func warnJJNested(manager *worktree.Manager, path string) {
+	if !manager.JJColocated() {
+		return
+	}
+	workspace := manager.JJWorkspace()
+	if rel, err := filepath.Rel(workspace, path); err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+	warnJJ(manager, "jj commands run in %s act on the jj workspace at %s; use git there, or 'jj workspace add' for a worktree jj manages", path, workspace)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/github"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+// landPollInterval is how often 'giwo land' checks whether the pull request
+// was merged.
+const landPollInterval = 15 * time.Second
+
+var (
+	landPR      bool
+	landMerge   bool
+	landMethod  string
+	landTimeout time.Duration
+	landYes     bool
+)
+
+var landCmd = &cobra.Command{
+	Use:   "land [filter]",
+	Short: "Push a worktree's branch, wait for its pull request to merge and clean up",
+	Long: `Land the branch of a worktree: push it to origin, wait until its pull request
+is merged, then remove the worktree and delete the local branch.
+
+Use --pr to open the pull request with the gh CLI if the branch has none yet,
+titled and described after its commits, and --merge to have GitHub merge it as
+soon as its checks and reviews allow, with the --method of your choice. Without
+--merge, someone else merges it while giwo waits.
+
+The steps are listed and confirmed before anything is done; --yes skips the
+confirmation, which is required without a terminal. The worktree must have no
+uncommitted changes. If the pull request is not merged within --timeout, or is
+closed, the worktree is kept; run the command again to resume waiting.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch landMethod {
+		case github.MergeMethodMerge, github.MergeMethodSquash, github.MergeMethodRebase:
+		default:
+			return fmt.Errorf("invalid --method %q: must be merge, squash or rebase", landMethod)
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+
+		filter := ""
+		if len(args) > 0 {
+			filter = args[0]
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		wt, err := resolveWorktree(ctx, manager, filter)
+		if err != nil {
+			return err
+		}
+		if wt == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+		switch {
+		case wt.IsMain:
+			return fmt.Errorf("cannot land the main worktree")
+		case wt.Detached:
+			return fmt.Errorf("%w: %s has no branch to land", giwoerrors.ErrDetachedHead, wt.Path)
+		case !wt.IsClean:
+			return fmt.Errorf("%w: %s\n💡 Commit or stash the changes before landing", giwoerrors.ErrWorktreeDirty, wt.Path)
+		}
+
+		owner, repo, err := manager.GetRepoInfo()
+		if err != nil {
+			return fmt.Errorf("land requires a GitHub origin: %w", err)
+		}
+		head := wt.PullRequestHead()
+
+		fmt.Printf("🛬 Landing '%s':\n", wt.Branch)
+		fmt.Printf("   - push it to origin\n")
+		if landPR {
+			fmt.Printf("   - open a pull request unless it has one\n")
+		}
+		if landMerge {
+			fmt.Printf("   - merge the pull request (%s) once checks and reviews allow\n", landMethod)
+		}
+		fmt.Printf("   - wait for the pull request to be merged, for up to %s\n", landTimeout)
+		fmt.Printf("   - remove the worktree at %s and delete the branch\n", displayPath(wt.Path))
+		if !landYes {
+			if !isInteractive() {
+				return fmt.Errorf("confirmation required: pass --yes to land without a terminal")
+			}
+			if !confirm("Go ahead?", true) {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+		}
+
+		fmt.Printf("📤 Pushing '%s' to origin...\n", wt.Branch)
+		if err := manager.Push(ctx, wt.Branch, true); err != nil {
+			return err
+		}
+
+		client := github.NewWithToken(cfg.GitHubToken)
+		pr, err := client.PullRequestStatus(ctx, owner, repo, head)
+		if err != nil {
+			return fmt.Errorf("failed to look up the pull request: %w", err)
+		}
+		if pr == nil {
+			if !landPR {
+				return fmt.Errorf("no pull request for '%s'\n💡 Use --pr to open one", head)
+			}
+			url, err := github.CreatePullRequest(ctx, owner, repo, head, landBase(ctx, manager, wt))
+			if err != nil {
+				return fmt.Errorf("failed to open a pull request: %w", err)
+			}
+			fmt.Printf("🔀 Opened %s\n", url)
+			if pr, err = client.PullRequestStatus(ctx, owner, repo, head); err != nil {
+				return fmt.Errorf("failed to look up the new pull request: %w", err)
+			}
+			if pr == nil {
+				return fmt.Errorf("the new pull request of '%s' was not found", head)
+			}
+		}
+
+		if landMerge && pr.State != github.PRStateMerged && pr.State != github.PRStateClosed {
+			if err := github.MergePullRequest(ctx, owner, repo, head, landMethod); err != nil {
+				return fmt.Errorf("failed to merge #%d: %w", pr.Number, err)
+			}
+			fmt.Printf("🤖 Asked GitHub to merge #%d (%s) once checks and reviews allow\n", pr.Number, landMethod)
+		}
+
+		if pr, err = waitForMerge(ctx, client, owner, repo, head, pr); err != nil {
+			return fmt.Errorf("%w\n💡 The worktree is kept; run 'giwo land %s' again to resume", err, utils.ShellQuote(wt.Branch))
+		}
+
+		if err := manager.Remove(ctx, worktree.RemoveOptions{Name: worktreeName(manager, wt)}); err != nil {
+			return fmt.Errorf("#%d was merged, but the worktree could not be removed: %w", pr.Number, err)
+		}
+		recordHistory(manager, history.Removed, wt.Path, wt.Branch)
+		fmt.Printf("✅ Landed '%s' in #%d; removed its worktree and branch\n", wt.Branch, pr.Number)
+		return nil
+	},
+}
+
+// waitForMerge polls the pull request of head until it is merged, and fails if
+// it is closed without, if --timeout passes or if the user interrupts. pr is
+// its current status.
+func waitForMerge(ctx context.Context, client *github.Client, owner, repo, head string, pr *github.PullRequestStatus) (*github.PullRequestStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, landTimeout)
+	defer cancel()
+
+	announced := false
+	for {
+		switch pr.State {
+		case github.PRStateMerged:
+			fmt.Printf("🎉 #%d was merged\n", pr.Number)
+			return pr, nil
+		case github.PRStateClosed:
+			return nil, fmt.Errorf("#%d was closed without being merged", pr.Number)
+		}
+		if !announced {
+			fmt.Printf("⏳ Waiting for #%d to be merged (%s, ctrl-c to stop)...\n", pr.Number, pr.URL)
+			announced = true
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("#%d was not merged within %s", pr.Number, landTimeout)
+			}
+			return nil, fmt.Errorf("stopped waiting for #%d", pr.Number)
+		case <-time.After(landPollInterval):
+		}
+
+		next, err := client.PullRequestStatus(ctx, owner, repo, head)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			fmt.Printf("⚠️  Warning: failed to check #%d: %v\n", pr.Number, err)
+		case next != nil:
+			pr = next
+		}
+	}
+}
+
+// landBase returns the branch the pull request of wt goes into: the one the
+// worktree was created from if it still exists and is not the default branch,
+// and otherwise the empty string, for the default branch.
+func landBase(ctx context.Context, manager *worktree.Manager, wt *worktree.Worktree) string {
+	if wt.Base == "" || wt.Base == manager.DefaultBranch(ctx) {
+		return ""
+	}
+	if collision, err := manager.CheckBranchCollision(ctx, wt.Base); err != nil || collision == nil {
+		return ""
+	}
+	return wt.Base
+}
+
+func init() {
+	landCmd.Flags().BoolVar(&landPR, "pr", false, "Open a pull request with the gh CLI if the branch has none")
+	landCmd.Flags().BoolVar(&landMerge, "merge", false, "Have GitHub merge the pull request once checks and reviews allow")
+	landCmd.Flags().StringVar(&landMethod, "method", github.MergeMethodSquash, "Merge method with --merge: merge, squash or rebase")
+	landCmd.Flags().DurationVar(&landTimeout, "timeout", time.Hour, "How long to wait for the pull request to be merged")
+	landCmd.Flags().BoolVarP(&landYes, "yes", "y", false, "Do not ask for confirmation")
+}
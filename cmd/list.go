@@ -4,30 +4,67 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"text/tabwriter"
+	"strings"
+	"text/template"
+	"time"
 
+	"github.com/knwoop/giwo/internal/ui"
 	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/mattn/go-runewidth"
 	"github.com/spf13/cobra"
 )
 
 var (
-	listVerbose bool
-	listFormat  string
+	listVerbose    bool
+	listFormat     string
+	listCheckLinks bool
+	listRemote     bool
+	listDiskUsage  bool
+	listTag        string
+	listSort       string
 )
 
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all worktrees",
-	Long:    `Display a list of all worktrees with their status information.`,
+	Long: `Display a list of all worktrees with their status information.
+
+--format also takes a Go template, executed for each worktree like the format of
+'git for-each-ref', to shape the output for scripts:
+
+  giwo list --format '{{.Branch}}\t{{.Path}}\t{{.Ahead}}'
+  giwo list --format '{{.Name}} {{short .Head}} {{join .Labels ","}}'
+  giwo list --remote --format '{{.Branch}}{{with .Remote}} #{{.PRNumber}}{{end}}'
+
+Templates see the fields of the JSON output under their Go names, such as
+.Branch, .Path, .Head, .IsClean, .Ahead, .Behind, .Labels, .CommitAge and
+.ActivityAge, and .Name, the worktree's directory. \t and \n are expanded, and
+the functions join, short and json are available.
+
+The AGE column shows the last activity in a worktree: the latest commit, branch
+switch, staging or edit of a changed file. --sort activity lists the most
+recently active worktrees first.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		if listSort != "" && listSort != "activity" {
+			return fmt.Errorf("invalid sort order %q: must be activity", listSort)
+		}
+
+		var custom *template.Template
+		if worktree.IsCustomFormat(listFormat) {
+			var err error
+			if custom, err = worktree.ParseFormat(listFormat); err != nil {
+				return err
+			}
+		}
+
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
 
 		ctx := cmd.Context()
-		worktrees, err := manager.List(ctx)
+		worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
 		if err != nil {
 			return fmt.Errorf("failed to list worktrees: %w", err)
 		}
@@ -36,6 +73,39 @@ var listCmd = &cobra.Command{
 			fmt.Println("No worktrees found")
 			return nil
 		}
+		if listTag != "" {
+			if worktrees = worktree.FilterByLabel(worktrees, listTag); len(worktrees) == 0 {
+				fmt.Printf("No worktrees labeled '%s'\n", listTag)
+				return nil
+			}
+		}
+		if listSort == "activity" {
+			worktree.SortByActivity(worktrees)
+		}
+
+		if listCheckLinks {
+			if err := countBrokenLinks(manager, worktrees); err != nil {
+				return err
+			}
+		}
+		if listDiskUsage {
+			if err := worktree.MeasureDiskUsage(ctx, worktrees); err != nil {
+				return fmt.Errorf("failed to measure disk usage: %w", err)
+			}
+		}
+		if listRemote {
+			if err := fetchRemoteStatus(ctx, manager, worktrees); err != nil {
+				return err
+			}
+		}
+
+		if custom != nil {
+			data := make([]worktree.FormatData, len(worktrees))
+			for i, wt := range worktrees {
+				data[i] = worktree.FormatData{Worktree: wt, Name: worktreeName(manager, wt)}
+			}
+			return worktree.WriteFormatted(os.Stdout, custom, data)
+		}
 
 		format := worktree.OutputFormat(listFormat)
 		switch format {
@@ -44,23 +114,93 @@ var listCmd = &cobra.Command{
 		case worktree.OutputFormatSimple:
 			return printSimple(worktrees)
 		default:
-			return printTable(worktrees, listVerbose)
+			cfg, err := loadConfig(manager)
+			if err != nil {
+				return err
+			}
+			ages, err := ageThresholds(cfg)
+			if err != nil {
+				return err
+			}
+			theme, err := uiTheme(cfg)
+			if err != nil {
+				return err
+			}
+			return printTable(worktrees, ages, theme, listVerbose, listRemote, listDiskUsage)
 		}
 	},
 }
 
-func printTable(worktrees []*worktree.Worktree, verbose bool) error {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+func printTable(worktrees []*worktree.Worktree, ages ui.AgeThresholds, theme ui.Theme, verbose, remote, diskUsage bool) error {
+	w := ui.NewTable(os.Stdout)
 	defer w.Flush()
 
+	// sizeColumn returns the disk usage column when --du is given
+	sizeColumn := func(wt *worktree.Worktree) string {
+		if !diskUsage {
+			return ""
+		}
+		return "\t" + ui.FormatSize(wt.DiskUsage)
+	}
+
+	// age returns the age of the last activity, or else of the last commit,
+	// marked by how old it is
+	now := time.Now()
+	age := func(wt *worktree.Worktree) string {
+		label := wt.ActivityAge
+		if label == "" {
+			label = wt.CommitAge
+		}
+		level := ages.Level(wt.ActiveAt(), now)
+		if level == ui.AgeUnknown {
+			return label
+		}
+		return ui.Mark(theme.AgeIcon(level), label)
+	}
+
+	// remoteColumn returns the PR/CI column when --remote is given
+	remoteColumn := func(wt *worktree.Worktree) string {
+		if !remote {
+			return ""
+		}
+		return "\t" + formatRemoteStatus(wt)
+	}
+
+	// extraHeader names the optional columns
+	extraHeader := ""
+	if remote {
+		extraHeader = "\tPR/CI"
+	}
+	if diskUsage {
+		extraHeader = "\tSIZE" + extraHeader
+	}
+
 	if verbose {
-		fmt.Fprintf(w, "BRANCH\tPATH\tSTATUS\tAHEAD/BEHIND\tCHANGES\tLAST COMMIT\tAGE\n")
+		fmt.Fprintf(w, "BRANCH\tPATH\tSTATUS\tAHEAD/BEHIND\tCHANGES\tLAST COMMIT\tAGE%s\n", extraHeader)
 		for _, wt := range worktrees {
-			status := "🌱"
+			status := theme.Icons.Feature
 			if wt.IsMain {
-				status = "🏠"
+				status = theme.Icons.Main
 			} else if !wt.IsClean {
-				status = "⚠️"
+				status = strings.TrimSpace(theme.Icons.Dirty)
+			}
+			if wt.Kind == worktree.KindMaintenance {
+				status += " 🔧"
+			}
+			if wt.Kind == worktree.KindPristine {
+				status += " 🧊"
+			}
+			if wt.Locked {
+				status += " " + theme.Icons.Locked
+			}
+			if wt.BrokenLinks > 0 {
+				status += " 🔗"
+			}
+			if len(wt.Submodules) > 0 {
+				status += " 🧩"
+			}
+			if len(wt.Labels) > 0 {
+				status += " 🏷️ " + strings.Join(wt.Labels, ",")
 			}
 
 			changes := fmt.Sprintf("M:%d A:%d D:%d", wt.Modified, wt.Added, wt.Deleted)
@@ -75,24 +215,51 @@ func printTable(worktrees []*worktree.Worktree, verbose bool) error {
 				aheadBehind = "up-to-date"
 			}
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-				wt.Branch, wt.Path, status, aheadBehind, changes,
-				truncateString(wt.LastCommit, 50), wt.CommitAge)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s%s\n",
+				ui.BranchLabel(wt), wt.Path, status, aheadBehind, changes,
+				truncateString(wt.LastCommit, 50), age(wt), sizeColumn(wt)+remoteColumn(wt))
 		}
 	} else {
-		fmt.Fprintf(w, "BRANCH\tPATH\tSTATUS\n")
+		fmt.Fprintf(w, "BRANCH\tPATH\tAGE\tSTATUS%s\n", extraHeader)
 		for _, wt := range worktrees {
-			status := "🌱"
+			var status string
 			if wt.IsMain {
-				status = "🏠 main"
+				status = ui.Mark(theme.Icons.Main, "main")
 			} else if !wt.IsClean {
-				status = "⚠️  dirty"
+				status = ui.Mark(theme.Icons.Dirty, "dirty")
 			} else {
-				status = "✅ clean"
+				status = ui.Mark(theme.Icons.Clean, "clean")
 			}
+			if wt.Kind == worktree.KindMaintenance {
+				status += " 🔧 maintenance"
+			}
+			if wt.Kind == worktree.KindPristine {
+				status += " 🧊 pristine"
+			}
+			if wt.Locked {
+				status += " " + ui.Mark(theme.Icons.Locked, formatLockReason(wt))
+			}
+			if wt.BrokenLinks > 0 {
+				status += fmt.Sprintf(" 🔗 %d broken link(s)", wt.BrokenLinks)
+			}
+			if len(wt.Submodules) > 0 {
+				status += " 🧩 " + strings.Join(wt.Submodules, ", ")
+			}
+			if len(wt.Labels) > 0 {
+				status += " 🏷️  " + strings.Join(wt.Labels, ", ")
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s%s\n", ui.BranchLabel(wt), wt.Path, age(wt), status, sizeColumn(wt)+remoteColumn(wt))
+		}
+	}
 
-			fmt.Fprintf(w, "%s\t%s\t%s\n", wt.Branch, wt.Path, status)
+	if diskUsage {
+		var total int64
+		for _, wt := range worktrees {
+			total += wt.DiskUsage
 		}
+		w.Flush()
+		fmt.Printf("\n💾 Total: %s\n", ui.FormatSize(total))
 	}
 
 	return nil
@@ -111,14 +278,27 @@ func printSimple(worktrees []*worktree.Worktree) error {
 	return nil
 }
 
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// formatLockReason returns the lock reason of a worktree, or "locked" if none was given.
+func formatLockReason(wt *worktree.Worktree) string {
+	if wt.LockReason == "" {
+		return "locked"
 	}
-	return s[:maxLen-3] + "..."
+	return wt.LockReason
+}
+
+// truncateString cuts s to at most maxLen terminal columns, ending it with "..."
+// if it was cut. Wide characters such as CJK count as two columns.
+func truncateString(s string, maxLen int) string {
+	return runewidth.Truncate(s, maxLen, "...")
 }
 
 func init() {
 	listCmd.Flags().BoolVarP(&listVerbose, "verbose", "v", false, "Show detailed information")
-	listCmd.Flags().StringVar(&listFormat, "format", "table", "Output format (table, json, simple)")
+	listCmd.Flags().StringVar(&listFormat, "format", "table", "Output format (table, json, simple) or a Go template such as '{{.Branch}}\t{{.Path}}'")
+	listCmd.Flags().BoolVar(&listRemote, "remote", false, "Show pull request and CI status from GitHub")
+	listCmd.Flags().DurationVar(&remoteTimeout, "remote-timeout", 10*time.Second, "Time limit for fetching remote status")
+	listCmd.Flags().BoolVar(&listDiskUsage, "du", false, "Show the disk usage of each worktree, including build artifacts")
+	listCmd.Flags().BoolVar(&listCheckLinks, "check-links", false, "Flag worktrees with broken shared-asset symlinks")
+	listCmd.Flags().StringVar(&listTag, "tag", "", "Only show worktrees carrying this label")
+	listCmd.Flags().StringVar(&listSort, "sort", "", "Sort order of the worktrees (activity)")
 }
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var lockReason string
+
+var lockCmd = &cobra.Command{
+	Use:   "lock [filter]",
+	Short: "Lock a worktree",
+	Long: `Lock a worktree so that it cannot be pruned, moved or removed.
+This is a wrapper around 'git worktree lock'. The optional reason is shown
+by 'giwo list', the switch UI, and when removal is refused.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		filter := ""
+		if len(args) > 0 {
+			filter = args[0]
+		}
+
+		ctx := cmd.Context()
+		selected, err := resolveWorktree(ctx, manager, filter)
+		if err != nil {
+			return err
+		}
+		if selected == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+
+		if selected.Locked {
+			fmt.Printf("🔒 Worktree '%s' is already locked\n", selected.Branch)
+			return nil
+		}
+
+		if err := manager.Lock(ctx, selected.Path, lockReason); err != nil {
+			return err
+		}
+
+		fmt.Printf("🔒 Worktree '%s' locked\n", selected.Branch)
+		return nil
+	},
+}
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock [filter]",
+	Short: "Unlock a worktree",
+	Long:  `Unlock a worktree previously locked with 'giwo lock'. This is a wrapper around 'git worktree unlock'.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		filter := ""
+		if len(args) > 0 {
+			filter = args[0]
+		}
+
+		ctx := cmd.Context()
+		selected, err := resolveWorktree(ctx, manager, filter)
+		if err != nil {
+			return err
+		}
+		if selected == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+
+		if !selected.Locked {
+			fmt.Printf("🔓 Worktree '%s' is not locked\n", selected.Branch)
+			return nil
+		}
+
+		if err := manager.Unlock(ctx, selected.Path); err != nil {
+			return err
+		}
+
+		fmt.Printf("🔓 Worktree '%s' unlocked\n", selected.Branch)
+		return nil
+	},
+}
+
+func init() {
+	lockCmd.Flags().StringVar(&lockReason, "reason", "", "Reason for locking the worktree")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var (
+	// verbose logs every git command giwo runs.
+	verbose bool
+
+	// debug additionally logs the finer steps of giwo's work.
+	debug bool
+)
+
+// logLevel returns the level to log at, from --debug and --verbose or else
+// $GIWO_LOG, and whether logging is enabled at all.
+func logLevel() (slog.Level, bool, error) {
+	switch {
+	case debug:
+		return slog.LevelDebug, true, nil
+	case verbose:
+		return slog.LevelInfo, true, nil
+	}
+
+	value := strings.TrimSpace(os.Getenv("GIWO_LOG"))
+	if value == "" || value == "off" {
+		return 0, false, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return 0, false, fmt.Errorf("invalid GIWO_LOG %q: use debug, info, warn, error or off", value)
+	}
+	return level, true, nil
+}
+
+// newLogger returns the logger giwo traces its work to on stderr, as text or,
+// with GIWO_LOG_FORMAT=json, as JSON lines. It discards everything unless
+// logging is enabled.
+func newLogger() (*slog.Logger, error) {
+	level, enabled, err := logLevel()
+	if err != nil || !enabled {
+		return slog.New(slog.DiscardHandler), err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if os.Getenv("GIWO_LOG_FORMAT") == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, options)), nil
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, options)), nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var mainNoUpdate bool
+
+var mainCmd = &cobra.Command{
+	Use:   "main",
+	Short: "Switch to the worktree of the default branch",
+	Long: `Switch to the worktree of the default branch, such as main: the one that has
+the branch checked out, usually the repository root.
+
+With 'main.dedicated: true' in the config file, giwo keeps a worktree of its own
+for the default branch instead, created in the worktree directory on first use
+and fast-forwarded to origin each time you switch to it. Nobody works in it, so
+it is always a safe place to review, rebase onto and start new branches from.
+If the branch is checked out elsewhere, as in the repository root, it has the
+branch's commit on origin checked out with a detached HEAD. Use --no-update to
+switch without fetching.
+
+'giwo create' warns when the branch a new worktree starts from is checked out
+with uncommitted changes, since the new worktree does not get them. Set
+'main.on_dirty' to refuse to stop it instead, or to ignore to stay quiet.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+		branch := manager.DefaultBranch(ctx)
+
+		if !cfg.Main.Dedicated {
+			worktrees, err := manager.ListFast(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			for _, wt := range worktrees {
+				if wt.Branch == branch {
+					return switchTo(manager, wt)
+				}
+			}
+			return fmt.Errorf("%w: '%s' is not checked out in any worktree\n💡 Set main.dedicated to true in the config file to have giwo keep a worktree of it",
+				giwoerrors.ErrWorktreeNotFound, branch)
+		}
+
+		pristine, created, err := manager.EnsurePristine(ctx, branch)
+		if err != nil {
+			return fmt.Errorf("failed to set up the worktree of '%s': %w", branch, err)
+		}
+		if created {
+			fmt.Printf("✅ Created the worktree of '%s' at %s\n", branch, pristine.Path)
+			recordHistory(manager, history.Created, pristine.Path, pristine.Branch)
+		} else if !mainNoUpdate {
+			err := manager.SyncPristine(ctx, pristine)
+			switch {
+			case errors.Is(err, giwoerrors.ErrWorktreeDirty):
+				fmt.Printf("⚠️  The worktree of '%s' has uncommitted changes and was not updated\n", branch)
+				fmt.Printf("💡 Stash or discard them to let it follow origin again: git -C %s stash\n", utils.ShellQuote(pristine.Path))
+			case err != nil:
+				fmt.Printf("⚠️  Could not update the worktree of '%s': %v\n", branch, err)
+			}
+		}
+
+		return switchTo(manager, pristine)
+	},
+}
+
+// pristineBase returns the branch followed by the pristine worktree if the
+// command runs in it, or "" otherwise. Its HEAD is usually detached, so the
+// current branch cannot tell.
+func pristineBase(ctx context.Context, manager *worktree.Manager) string {
+	pristine, err := manager.Pristine(ctx)
+	if err != nil || pristine == nil || pristine.Path != manager.RepoRoot() {
+		return ""
+	}
+	return pristine.Base
+}
+
+func init() {
+	mainCmd.Flags().BoolVar(&mainNoUpdate, "no-update", false, "Switch to the dedicated worktree without fetching and fast-forwarding it")
+	mainCmd.Flags().BoolVar(&switchPrint, "print", false, "Print the worktree path instead of switching")
+}
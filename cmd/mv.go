@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var mvForce bool
+
+var mvCmd = &cobra.Command{
+	Use:   "mv [filter] <new-path>",
+	Short: "Move a worktree to another directory",
+	Long: `Move a worktree to another directory, keeping its branch.
+This is a wrapper around 'git worktree move' that also carries giwo's metadata
+and labels over to the new path. Relative paths are taken relative to the
+worktree directory, which the new path must be inside of.
+
+A locked worktree is moved after confirmation, or with --force, and stays locked.
+
+Inside tmux, shells sitting in the worktree change to the new directory, and
+windows and sessions named after it (as 'giwo create --tmux' names them) are
+renamed. Use 'giwo rename' to rename the branch along with the directory.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeWorktrees(true),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, dest := "", args[len(args)-1]
+		if len(args) == 2 {
+			filter = args[0]
+		}
+
+		ctx := cmd.Context()
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		selected, err := resolveWorktree(ctx, manager, filter)
+		if err != nil {
+			return err
+		}
+		if selected == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+
+		if selected.IsMain {
+			return fmt.Errorf("the main worktree cannot be moved")
+		}
+
+		destPath, err := manager.MoveDestination(ctx, dest)
+		if errors.Is(err, giwoerrors.ErrWorktreeExists) {
+			return fmt.Errorf("%w\n💡 Pick a path that does not exist yet", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		name := worktreeName(manager, selected)
+		force := mvForce
+		if selected.Locked && !force && isInteractive() {
+			reason := ""
+			if selected.LockReason != "" {
+				reason = fmt.Sprintf(" (%s)", selected.LockReason)
+			}
+			if !confirm(fmt.Sprintf("🔒 '%s' is locked%s. Move it anyway? It stays locked", name, reason), false) {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+			force = true
+		}
+
+		var panes []tmuxPane
+		if !serverSafe {
+			panes = tmuxPanesIn(selected.Path)
+		}
+
+		fmt.Printf("📦 Moving worktree '%s' to %s...\n", name, destPath)
+		err = manager.Move(ctx, worktree.MoveOptions{Path: selected.Path, Dest: destPath, Force: force})
+		if err != nil {
+			if errors.Is(err, giwoerrors.ErrWorktreeLocked) {
+				return fmt.Errorf("refusing to move worktree: %w\n💡 Pass --force to move it anyway; it stays locked", err)
+			}
+			return err
+		}
+		fmt.Printf("✅ Worktree moved to %s\n", destPath)
+
+		if !serverSafe {
+			newName := worktreeName(manager, &worktree.Worktree{Path: destPath})
+			followInTmux(panes, selected.Path, destPath, name, newName)
+		}
+
+		// The shell of a user inside the old directory is left in a path that no longer exists
+		if cwd, err := os.Getwd(); err != nil || strings.HasPrefix(cwd, selected.Path) {
+			fmt.Printf("💡 Follow the worktree with: cd %s\n", utils.ShellQuote(destPath))
+		}
+		return nil
+	},
+}
+
+// tmuxPane is a tmux pane working inside a worktree.
+type tmuxPane struct {
+	ID      string
+	Command string
+	Path    string
+}
+
+// tmuxPanesIn returns the tmux panes whose working directory is path or below
+// it, or nil if tmux is not running.
+func tmuxPanesIn(path string) []tmuxPane {
+	// tmux escapes tabs in formats; the path, which may contain spaces, comes last
+	output, err := exec.Command("tmux", "list-panes", "-a", "-F", "#{pane_id} #{pane_current_command} #{pane_current_path}").Output()
+	if err != nil {
+		return nil
+	}
+
+	var panes []tmuxPane
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[2] == path || strings.HasPrefix(fields[2], path+string(filepath.Separator)) {
+			panes = append(panes, tmuxPane{ID: fields[0], Command: fields[1], Path: fields[2]})
+		}
+	}
+	return panes
+}
+
+// tmuxShells are the commands of panes that are idle at a shell prompt.
+var tmuxShells = map[string]bool{"bash": true, "zsh": true, "fish": true, "sh": true, "dash": true, "ksh": true}
+
+// followInTmux points tmux at a worktree moved from oldPath to newPath: shells
+// idle in panes inside it change to the new directory, and windows and sessions
+// named after oldName are renamed after newName. Panes running other programs
+// are only reported, since typing into them could do anything.
+func followInTmux(panes []tmuxPane, oldPath, newPath, oldName, newName string) {
+	for _, pane := range panes {
+		dir := newPath + strings.TrimPrefix(pane.Path, oldPath)
+		if !tmuxShells[pane.Command] {
+			fmt.Printf("💡 tmux pane %s still runs %s in the old directory\n", pane.ID, pane.Command)
+			continue
+		}
+		if err := exec.Command("tmux", "send-keys", "-t", pane.ID, "cd "+utils.ShellQuote(dir), "Enter").Run(); err != nil {
+			fmt.Printf("⚠️  Warning: failed to move tmux pane %s: %v\n", pane.ID, err)
+		}
+	}
+
+	from, to := tmuxName(oldName), tmuxName(newName)
+	if from == to {
+		return
+	}
+	if output, err := exec.Command("tmux", "list-windows", "-a", "-F", "#{window_id} #{window_name}").Output(); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			if id, name, _ := strings.Cut(line, " "); name == from {
+				_ = exec.Command("tmux", "rename-window", "-t", id, to).Run()
+			}
+		}
+	}
+	if exec.Command("tmux", "has-session", "-t", "="+from).Run() == nil {
+		_ = exec.Command("tmux", "rename-session", "-t", "="+from, to).Run()
+	}
+}
+
+func init() {
+	mvCmd.Flags().BoolVar(&mvForce, "force", false, "Move a locked worktree without asking; it stays locked")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// changeDirectory moves the user into path. With the shell integration loaded, the
+// path is handed to the wrapper through $GIWO_CD_FILE so that the calling shell
+// changes directory; otherwise a new shell is opened in path when running interactively.
+func changeDirectory(path string) error {
+	if cdFile := os.Getenv("GIWO_CD_FILE"); cdFile != "" {
+		return os.WriteFile(cdFile, []byte(path), 0o600)
+	}
+
+	fmt.Printf("💡 Run: cd %s\n", utils.ShellQuote(path))
+	if !isInteractive() {
+		return nil
+	}
+	return openShellInDirectory(path)
+}
+
+// openInEditor opens path in the configured editor, falling back to $VISUAL and $EDITOR.
+func openInEditor(cfg *config.Config, path string) error {
+	if err := checkServerSafe("opening an editor"); err != nil {
+		return err
+	}
+
+	editor := cfg.Editor
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return fmt.Errorf("no editor configured; set 'editor' in the config file or $EDITOR")
+	}
+
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Dir = path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	fmt.Printf("📝 Opening %s in %s\n", path, fields[0])
+	return cmd.Run()
+}
+
+// openInTmux opens path in a new tmux window named name, or a new session when
+// not running inside tmux.
+func openInTmux(name, path string) error {
+	if err := checkServerSafe("opening tmux"); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return fmt.Errorf("tmux not found in PATH")
+	}
+
+	name = tmuxName(name)
+
+	var cmd *exec.Cmd
+	if os.Getenv("TMUX") != "" {
+		cmd = exec.Command("tmux", "new-window", "-n", name, "-c", path)
+	} else {
+		cmd = exec.Command("tmux", "new-session", "-A", "-s", name, "-c", path)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	fmt.Printf("🪟 Opening %s in tmux\n", path)
+	return cmd.Run()
+}
+
+// tmuxName returns the tmux session and window name for a worktree name;
+// tmux does not allow dots and colons in them.
+func tmuxName(name string) string {
+	return strings.NewReplacer(".", "-", ":", "-").Replace(name)
+}
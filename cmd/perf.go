@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	perfJSON    bool
+	perfSlowest int
+	perfReset   bool
+)
+
+// managers are the managers created by the running command, whose timings are
+// saved when it ends.
+var managers []*worktree.Manager
+
+var perfCmd = &cobra.Command{
+	Use:   "perf",
+	Short: "Show the latency of the git commands giwo runs",
+	Long: `Show how long the git commands giwo runs in this repository take, such as
+fetch, status and worktree add, with their median (p50) and 95th percentile
+(p95) latencies and the slowest runs. Filesystem steps like copying templates
+and whole giwo commands are timed too, and the time of giwo commands is broken
+down into git, the filesystem and giwo itself, to tell where slowness comes from.
+
+The latest 200 runs of each operation are kept in the repository's giwo state
+directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		if perfReset {
+			if err := manager.ResetTimings(); err != nil {
+				return err
+			}
+			fmt.Println("🧹 Timings reset")
+			return nil
+		}
+
+		timings, err := manager.Timings()
+		if err != nil {
+			return err
+		}
+		stats := worktree.SummarizeTimings(timings)
+		slowest := worktree.SlowestTimings(timings, perfSlowest)
+		breakdown := worktree.BreakDownTimings(timings)
+
+		if perfJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(struct {
+				Operations []worktree.TimingStats `json:"operations"`
+				Slowest    []worktree.Timing      `json:"slowest"`
+				Breakdown  worktree.TimeBreakdown `json:"breakdown"`
+			}{stats, slowest, breakdown})
+		}
+
+		if len(timings) == 0 {
+			fmt.Println("No timings recorded yet. They are collected as you use giwo in this repository.")
+			return nil
+		}
+
+		fmt.Printf("📊 %d timed runs since %s\n\n", len(timings), timings[0].Time.Local().Format("2006-01-02 15:04"))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "OPERATION\tRUNS\tFAILED\tP50\tP95\tMAX")
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n", s.Op, s.Count, s.Failed,
+				formatLatency(s.P50), formatLatency(s.P95), formatLatency(s.Max))
+		}
+		w.Flush()
+
+		if breakdown.Total > 0 {
+			share := func(d time.Duration) float64 { return 100 * float64(d) / float64(breakdown.Total) }
+			fmt.Printf("\n⏱️  %d giwo command(s) took %s: %.0f%% in git, %.0f%% in the filesystem, %.0f%% in giwo\n",
+				breakdown.Commands, formatLatency(breakdown.Total),
+				share(breakdown.Git), share(breakdown.FS), share(breakdown.Giwo()))
+		}
+
+		if len(slowest) > 0 {
+			fmt.Println("\n🐢 Slowest runs:")
+			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, t := range slowest {
+				op := t.Op
+				if t.Failed {
+					op += " ❌"
+				}
+				fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", formatLatency(t.Duration), op,
+					t.Time.Local().Format("2006-01-02 15:04"), t.Dir)
+			}
+			w.Flush()
+		}
+		return nil
+	},
+}
+
+// formatLatency formats a duration for the perf tables, e.g. "420µs", "42ms" or "1.25s".
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// saveTimings records how long cmd took and saves the timings its managers
+// measured. Timings are only statistics, so failing to save them is not reported.
+func saveTimings(cmd *cobra.Command, start time.Time, err error) {
+	if len(managers) == 0 || cmd == nil || cmd == perfCmd {
+		return
+	}
+	managers[0].RecordTiming(worktree.Timing{
+		Op:       cmd.CommandPath(),
+		Time:     start,
+		Duration: time.Since(start),
+		Failed:   err != nil,
+	})
+	for _, manager := range managers {
+		_ = manager.SaveTimings()
+	}
+}
+
+func init() {
+	perfCmd.Flags().BoolVar(&perfJSON, "json", false, "Output as JSON")
+	perfCmd.Flags().IntVar(&perfSlowest, "slowest", 10, "Number of slowest runs to show")
+	perfCmd.Flags().BoolVar(&perfReset, "reset", false, "Delete the recorded timings")
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/plugin"
+	"github.com/spf13/cobra"
+)
+
+// pluginExitError carries the exit code of a plugin that failed. The plugin
+// reports its own errors, so giwo only passes the code on.
+type pluginExitError struct {
+	code int
+}
+
+func (e *pluginExitError) Error() string {
+	return fmt.Sprintf("plugin exited with code %d", e.code)
+}
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Manage plugins providing extra subcommands",
+	Long: `Plugins are executables named giwo-<name> on your PATH. 'giwo <name>' runs
+giwo-<name> with the remaining arguments when <name> is not a built-in command,
+like git does for git-<name>. Inside a repository the plugin gets the variables
+of 'giwo env' for the current worktree, plus GIWO_BIN (the giwo executable) and
+GIWO_PLUGIN (its own name), so it can call back into giwo.`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found on PATH",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plugins := plugin.List(os.Getenv("PATH"))
+		if len(plugins) == 0 {
+			fmt.Println("No plugins found. Put an executable named giwo-<name> on your PATH to add 'giwo <name>'.")
+			return nil
+		}
+
+		for _, p := range plugins {
+			switch {
+			case isBuiltinCommand(p.Name):
+				fmt.Printf("⚠️  %s\t%s (hidden by the built-in command)\n", p.Name, p.Path)
+			case p.Shadowed:
+				fmt.Printf("⚠️  %s\t%s (shadowed by an earlier one on PATH)\n", p.Name, p.Path)
+			default:
+				fmt.Printf("🔌 %s\t%s\n", p.Name, p.Path)
+			}
+		}
+		return nil
+	},
+}
+
+// isBuiltinCommand reports whether name is a giwo command or one of its aliases.
+func isBuiltinCommand(name string) bool {
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginInvocation returns the plugin to run for the command line args, if its
+// first argument names no built-in command but a plugin on PATH.
+func pluginInvocation(args []string) (string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || isBuiltinCommand(args[0]) {
+		return "", false
+	}
+	if strings.HasPrefix(args[0], cobra.ShellCompRequestCmd) {
+		return "", false
+	}
+	path, err := plugin.Lookup(args[0])
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// runPlugin runs the plugin at path with args, passing on the terminal and the
+// context of the worktree giwo runs in.
+func runPlugin(ctx context.Context, name, path string, args []string) error {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "GIWO_PLUGIN="+name)
+	if exe, err := os.Executable(); err == nil {
+		cmd.Env = append(cmd.Env, "GIWO_BIN="+exe)
+	}
+	cmd.Env = append(cmd.Env, pluginWorktreeEnv(ctx)...)
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &pluginExitError{code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", name, err)
+	}
+	return nil
+}
+
+// pluginWorktreeEnv returns the variables of 'giwo env' for the current worktree.
+// Plugins may run outside repositories, so nothing is returned on errors.
+func pluginWorktreeEnv(ctx context.Context) []string {
+	manager, err := newManager()
+	if err != nil {
+		return nil
+	}
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return nil
+	}
+	worktrees, err := manager.ListFast(ctx)
+	if err != nil {
+		return nil
+	}
+	selected, err := currentWorktree(manager, worktrees)
+	if err != nil {
+		return nil
+	}
+	vars, err := worktreeEnv(ctx, manager, cfg.Env, worktrees, selected)
+	if err != nil {
+		return nil
+	}
+
+	env := make([]string, 0, len(vars))
+	for _, v := range vars {
+		env = append(env, v[0]+"="+v[1])
+	}
+	return env
+}
+
+func init() {
+	pluginsCmd.AddCommand(pluginsListCmd)
+}
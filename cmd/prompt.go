@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readLine prints prompt and returns the line the user types, without surrounding
+// whitespace. It fails with io.EOF when standard input is closed.
+func readLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// confirm asks a yes/no question and returns the answer, which is defaultYes
+// when the user just presses enter.
+func confirm(prompt string, defaultYes bool) bool {
+	if defaultYes {
+		prompt += " [Y/n]: "
+	} else {
+		prompt += " [y/N]: "
+	}
+	answer, err := readLine(prompt)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(answer) {
+	case "":
+		return defaultYes
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/template"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var promptFormat string
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a segment for shell prompts",
+	Long: `Print a compact segment describing the current worktree for shell prompts:
+its name, * if it has uncommitted changes, and the commits it is ahead of and
+behind its upstream, such as "feature-auth* ⇡2⇣1".
+
+The status comes from the status cache while nothing changed since it was
+computed, so the command usually runs no git command besides finding the
+repository. Outside a repository it prints nothing and succeeds, so it is safe
+to call from every prompt:
+
+  PS1='$(giwo prompt 2>/dev/null) '$PS1        # bash, zsh with PROMPT_SUBST
+
+  # starship.toml
+  [custom.giwo]
+  command = "giwo prompt"
+  when = "git rev-parse --is-inside-work-tree"
+
+--format takes a Go template as 'giwo list --format' does, e.g.
+'{{.Name}}{{if .Ahead}} +{{.Ahead}}{{end}}'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var custom *template.Template
+		if promptFormat != "" {
+			var err error
+			if custom, err = worktree.ParseFormat(promptFormat); err != nil {
+				return err
+			}
+		}
+
+		// A prompt must not fail, so not being in a worktree just prints nothing
+		manager, err := newManager()
+		if err != nil {
+			return nil
+		}
+		wt, err := manager.PromptStatus(cmd.Context())
+		if err != nil {
+			return nil
+		}
+
+		name := worktreeName(manager, wt)
+		if custom != nil {
+			return worktree.WriteFormatted(os.Stdout, custom, []worktree.FormatData{{Worktree: wt, Name: name}})
+		}
+		fmt.Println(worktree.PromptSegment(wt, name))
+		return nil
+	},
+}
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormat, "format", "", "Go template for the segment, such as '{{.Name}}'")
+}
@@ -1,29 +1,64 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
-	"os/exec"
+	"os"
+	"strings"
 
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
+var (
+	pruneIncludeMaintenance bool
+	pruneOrphans            bool
+	pruneDelete             bool
+	pruneAdopt              bool
+	pruneDryRun             bool
+)
+
 var pruneCmd = &cobra.Command{
 	Use:   "prune",
 	Short: "Remove administrative files for orphaned worktrees",
-	Long:  `Remove administrative files for orphaned worktrees. This is a wrapper around 'git worktree prune'.`,
+	Long: `Remove administrative files for orphaned worktrees. This is a wrapper around 'git worktree prune'.
+
+Maintenance worktrees (created with 'giwo create --from-tag') are kept even when
+their directory is missing, unless --include-maintenance is given.
+
+With --orphans, the worktree directory is first scanned for the opposite case:
+directories that look like former worktrees but that git no longer knows about,
+e.g. after a crash or a manual deletion of git's files. For each one, giwo asks
+whether to delete it or to adopt it again as a worktree of the branch it is
+named after, keeping its files. --delete or --adopt answer for all of them, and
+--dry-run only lists them.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		if pruneDelete && pruneAdopt {
+			return fmt.Errorf("--delete and --adopt are mutually exclusive")
+		}
+		if (pruneDelete || pruneAdopt || pruneDryRun) && !pruneOrphans {
+			return fmt.Errorf("--delete, --adopt and --dry-run require --orphans")
+		}
+
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
+		defer reportProgress(manager, "auto")()
 
-		fmt.Println("🧹 Pruning orphaned worktree administrative files...")
+		// Orphans come first, as pruning would drop the files needed to repair them
+		if pruneOrphans {
+			if err := pruneOrphanDirs(cmd, manager); err != nil {
+				return err
+			}
+			if pruneDryRun {
+				return nil
+			}
+		}
 
-		gitCmd := exec.Command("git", "worktree", "prune", "-v")
-		gitCmd.Dir = manager.RepoRoot()
+		fmt.Println("🧹 Pruning orphaned worktree administrative files...")
 
-		output, err := gitCmd.CombinedOutput()
+		output, kept, err := manager.Prune(cmd.Context(), pruneIncludeMaintenance)
 		if err != nil {
 			return fmt.Errorf("failed to prune worktrees: %w", err)
 		}
@@ -34,6 +69,96 @@ var pruneCmd = &cobra.Command{
 			fmt.Println("✅ No orphaned administrative files found")
 		}
 
+		for _, path := range kept {
+			fmt.Printf("🔧 Kept maintenance worktree %s (use --include-maintenance to prune it)\n", path)
+		}
+
 		return nil
 	},
 }
+
+// pruneOrphanDirs deletes or adopts the orphaned worktree directories, as
+// chosen by flag or asked for each one.
+func pruneOrphanDirs(cmd *cobra.Command, manager *worktree.Manager) error {
+	ctx := cmd.Context()
+	orphans, err := manager.Orphans(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("✅ No orphaned worktree directories found")
+		return nil
+	}
+
+	fmt.Printf("👻 Found %d orphaned worktree directory(ies):\n", len(orphans))
+	for _, orphan := range orphans {
+		fmt.Printf("  - %s\n", orphan.Path)
+	}
+
+	if pruneDryRun {
+		fmt.Printf("\n💡 Run without --dry-run to delete or adopt them\n")
+		return nil
+	}
+	if !pruneDelete && !pruneAdopt && !isInteractive() {
+		fmt.Printf("\n💡 Use --delete or --adopt to handle them without prompting\n")
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, orphan := range orphans {
+		adopt := pruneAdopt
+		if !pruneDelete && !pruneAdopt {
+			if orphan.Repairable {
+				fmt.Printf("\n%s: [d]elete, [a]dopt (%s), or [s]kip? [s]: ", orphan.Path, adoptDescription(orphan))
+			} else {
+				fmt.Printf("\n%s: [d]elete, or [s]kip (git's files for it are gone, so it cannot be adopted)? [s]: ", orphan.Path)
+			}
+			response, _ := reader.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(response)) {
+			case "d":
+			case "a":
+				if !orphan.Repairable {
+					continue
+				}
+				adopt = true
+			default:
+				continue
+			}
+		}
+
+		if adopt {
+			if err := manager.AdoptOrphan(ctx, orphan); err != nil {
+				fmt.Printf("⚠️  Failed to adopt '%s': %v\n", orphan.Path, err)
+				continue
+			}
+			fmt.Printf("✅ Adopted %s (%s)\n", orphan.Path, adoptDescription(orphan))
+			continue
+		}
+
+		if err := manager.RemoveOrphan(orphan); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+			continue
+		}
+		fmt.Printf("🗑️  Deleted %s\n", orphan.Path)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// adoptDescription says how an orphan is adopted.
+func adoptDescription(orphan worktree.Orphan) string {
+	if orphan.Branch == "" {
+		return "repair in place"
+	}
+	return fmt.Sprintf("repair in place as worktree of '%s'", orphan.Branch)
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneIncludeMaintenance, "include-maintenance", false, "Also prune missing maintenance worktrees")
+	pruneCmd.Flags().BoolVar(&pruneOrphans, "orphans", false, "Also handle worktree directories git no longer knows about")
+	pruneCmd.Flags().BoolVar(&pruneDelete, "delete", false, "Delete orphaned directories without prompting")
+	pruneCmd.Flags().BoolVar(&pruneAdopt, "adopt", false, "Adopt orphaned directories as worktrees without prompting")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "List orphaned directories without changing anything")
+}
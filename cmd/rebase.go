@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rebaseAll       bool
+	rebaseOnto      string
+	rebaseKeepGoing bool
+	rebaseJSON      bool
+)
+
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase [filter]",
+	Short: "Rebase worktrees onto the updated default branch",
+	Long: `Rebase the branch of the selected worktree, or of every feature worktree with
+--all, onto the default branch freshly fetched from origin (or --onto another
+branch).
+
+Uncommitted changes are stashed for the rebase and restored afterwards. A
+rebase that conflicts is aborted, leaving the worktree as it was, and the
+conflicting files are reported; the other worktrees are rebased all the same.
+With --keep-going, conflicted rebases are left in progress instead, to be
+resolved with 'git rebase --continue' in the worktree.
+
+Worktrees with a detached HEAD or a rebase or merge in progress are skipped.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rebaseAll && len(args) > 0 {
+			return fmt.Errorf("pass either a filter or --all")
+		}
+
+		ctx := cmd.Context()
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		var targets []*worktree.Worktree
+		if rebaseAll {
+			worktrees, err := manager.ListFast(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			targets = slices.DeleteFunc(worktrees, func(wt *worktree.Worktree) bool { return wt.IsMain })
+		} else {
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+			selected, err := resolveWorktree(ctx, manager, filter)
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+			targets = []*worktree.Worktree{selected}
+		}
+
+		mode := "auto"
+		if rebaseJSON {
+			mode = "none"
+		}
+		stop := reportProgress(manager, mode)
+		onto := rebaseOnto
+		if onto == "" {
+			onto = manager.DefaultBranch(ctx)
+		}
+		target, err := manager.RebaseTarget(ctx, onto)
+		if err != nil {
+			stop()
+			return err
+		}
+
+		var results []*worktree.RebaseResult
+		for _, wt := range targets {
+			result, err := manager.Rebase(ctx, wt, target, worktree.RebaseOptions{KeepConflicts: rebaseKeepGoing})
+			if err != nil {
+				stop()
+				return err
+			}
+			results = append(results, result)
+		}
+		stop()
+
+		if rebaseJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(results); err != nil {
+				return err
+			}
+		} else {
+			for _, result := range results {
+				printRebaseResult(result, target)
+			}
+		}
+
+		var conflicted []string
+		for _, result := range results {
+			if result.Status == worktree.RebaseConflict || result.StashKept {
+				conflicted = append(conflicted, result.Branch)
+			}
+		}
+		if len(conflicted) > 0 {
+			return fmt.Errorf("rebase needs attention in %d worktree(s): %s", len(conflicted), strings.Join(conflicted, ", "))
+		}
+		return nil
+	},
+}
+
+// printRebaseResult reports how rebasing a worktree onto target went.
+func printRebaseResult(result *worktree.RebaseResult, target string) {
+	switch result.Status {
+	case worktree.RebaseDone:
+		fmt.Printf("✅ %s rebased onto %s\n", result.Branch, target)
+	case worktree.RebaseUpToDate:
+		fmt.Printf("👌 %s is up to date with %s\n", result.Branch, target)
+	case worktree.RebaseSkipped:
+		name := result.Branch
+		if name == "" {
+			name = displayPath(result.Path)
+		}
+		fmt.Printf("⏭️  %s skipped: %s\n", name, result.Reason)
+	case worktree.RebaseConflict:
+		if result.InProgress {
+			fmt.Printf("❌ %s conflicts with %s; the rebase is left in progress in %s\n", result.Branch, target, result.Path)
+		} else {
+			fmt.Printf("❌ %s conflicts with %s; the rebase was aborted\n", result.Branch, target)
+		}
+	}
+	for _, file := range result.Conflicts {
+		fmt.Printf("   - %s\n", file)
+	}
+
+	switch {
+	case result.InProgress:
+		fmt.Printf("💡 Resolve the conflicts, run 'git rebase --continue' in %s", result.Path)
+		if result.StashKept {
+			fmt.Printf(", then 'git stash pop'")
+		}
+		fmt.Println()
+	case result.StashKept:
+		fmt.Printf("⚠️  The uncommitted changes of %s could not be restored and are kept in the stash ('git stash list')\n", result.Branch)
+	case result.Stashed:
+		fmt.Printf("📦 Restored the uncommitted changes of %s\n", result.Branch)
+	}
+}
+
+func init() {
+	rebaseCmd.Flags().BoolVar(&rebaseAll, "all", false, "Rebase every feature worktree")
+	rebaseCmd.Flags().StringVar(&rebaseOnto, "onto", "", "Branch to rebase onto (default: the default branch)")
+	rebaseCmd.Flags().BoolVar(&rebaseKeepGoing, "keep-going", false, "Leave conflicted rebases in progress to resolve them instead of aborting them")
+	rebaseCmd.Flags().BoolVar(&rebaseJSON, "json", false, "Print the results as JSON")
+	rebaseCmd.ValidArgsFunction = completeWorktrees(false)
+}
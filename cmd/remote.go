@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/github"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// remoteConcurrency limits the number of forge requests in flight.
+const remoteConcurrency = 8
+
+// remoteTimeout bounds the time spent fetching forge status for --remote.
+var remoteTimeout time.Duration
+
+// remoteOffline is the error recorded for worktrees whose forge status was not
+// looked up because giwo is offline.
+const remoteOffline = "offline"
+
+// fetchRemoteStatus fills in the pull request and CI status of each branch worktree,
+// querying the forge concurrently. Worktrees whose lookup fails or times out get
+// the status last fetched for their branch, or an error recorded if there is none,
+// so a slow or unreachable forge never hides the local information. Offline, the
+// forge is not queried at all.
+func fetchRemoteStatus(ctx context.Context, manager *worktree.Manager, worktrees []*worktree.Worktree) error {
+	if manager.Offline() {
+		for _, wt := range worktrees {
+			if !wt.IsMain && !wt.Detached && wt.Branch != "" {
+				wt.Remote = &worktree.RemoteStatus{Error: remoteOffline}
+			}
+		}
+		return manager.CachedRemoteStatus(worktrees)
+	}
+
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return err
+	}
+
+	owner, repo, err := manager.GetRepoInfo()
+	if err != nil {
+		return fmt.Errorf("--remote requires a GitHub origin: %w", err)
+	}
+
+	client := github.NewWithToken(cfg.GitHubToken)
+
+	ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+	defer cancel()
+
+	sem := make(chan struct{}, remoteConcurrency)
+	var wg sync.WaitGroup
+	for _, wt := range worktrees {
+		if wt.IsMain || wt.Detached || wt.Branch == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(wt *worktree.Worktree) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			wt.Remote = &worktree.RemoteStatus{}
+			pr, err := client.PullRequestStatus(ctx, owner, repo, wt.PullRequestHead())
+			switch {
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				wt.Remote.Error = fmt.Sprintf("timed out after %s", remoteTimeout)
+			case err != nil:
+				wt.Remote.Error = err.Error()
+			case pr != nil:
+				wt.Remote.PRNumber = pr.Number
+				wt.Remote.PRState = pr.State
+				wt.Remote.PRURL = pr.URL
+				wt.Remote.Checks = pr.Checks
+			}
+		}(wt)
+	}
+	wg.Wait()
+
+	if err := manager.SaveRemoteStatus(worktrees); err != nil {
+		fmt.Printf("⚠️  Warning: %v\n", err)
+	}
+	return manager.CachedRemoteStatus(worktrees)
+}
+
+// formatRemoteStatus summarizes the pull request and CI status of a worktree, e.g. "#42 open ✅".
+func formatRemoteStatus(wt *worktree.Worktree) string {
+	remote := wt.Remote
+	switch {
+	case remote == nil:
+		return "-"
+	case remote.Error == remoteOffline:
+		return "📴 offline"
+	case remote.Error != "":
+		return "⚠️  unavailable"
+	}
+
+	status := "no PR"
+	if remote.PRNumber != 0 {
+		status = fmt.Sprintf("#%d %s", remote.PRNumber, remote.PRState)
+	}
+	switch remote.Checks {
+	case github.ChecksSuccess:
+		status += " ✅"
+	case github.ChecksFailure:
+		status += " ❌"
+	case github.ChecksPending:
+		status += " ⏳"
+	}
+	if !remote.CachedAt.IsZero() {
+		status += " 🕒 " + utils.TimeAgo(remote.CachedAt)
+	}
+	return status
+}
@@ -1,8 +1,16 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/utils"
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
@@ -17,33 +25,134 @@ var removeCmd = &cobra.Command{
 	Aliases: []string{"rm", "delete"},
 	Short:   "Remove a worktree",
 	Long: `Remove the specified worktree and optionally delete the associated local branch.
-By default, the local branch will be deleted unless --keep-branch is specified.`,
+By default, the local branch will be deleted unless --keep-branch is specified.
+
+Without a terminal, or with --non-interactive, --force is required and the
+argument may also be the worktree's path. The command exits with 1 if nothing
+matches and with 2 if the argument is ambiguous.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		branchName := args[0]
 
-		manager, err := worktree.New()
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
+		defer reportProgress(manager, "auto")()
+
+		ctx := cmd.Context()
+		if !isInteractive() {
+			// Scripts may name the worktree by path, and cannot answer the confirmation
+			if !removeForce {
+				return fmt.Errorf("refusing to prompt for confirmation in non-interactive mode; pass --force")
+			}
+
+			worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			selected, err := matchWorktree(worktrees, branchName)
+			if err != nil {
+				return err
+			}
+			branchName = worktreeName(manager, selected)
+		}
+		// The worktree's name may differ from its branch, e.g. for a namespaced branch
+		branch := removedBranch(ctx, manager, manager.WorktreePath(branchName), branchName)
 
+		if !removeKeepBranch {
+			warnJJ(manager, "deleting branch '%s' also deletes its jj bookmark once jj imports from git; use --keep-branch to keep it", branch)
+		}
 		fmt.Printf("🗑️  Removing worktree '%s'...\n", branchName)
+		warnNestedChanges(ctx, manager, manager.WorktreePath(branchName))
 
-		ctx := cmd.Context()
-		if err := manager.Remove(ctx, branchName, removeForce, removeKeepBranch); err != nil {
+		// Confirming the removal also confirms losing uncommitted changes
+		opts := worktree.RemoveOptions{Name: branchName, Force: true, KeepBranch: removeKeepBranch}
+		if !removeForce {
+			opts.Confirm = confirmRemoval
+		}
+		if err := manager.Remove(ctx, opts); err != nil {
+			if errors.Is(err, giwoerrors.ErrWorktreeLocked) {
+				return fmt.Errorf("refusing to remove worktree: %w\n💡 Run 'giwo unlock %s' first", err, utils.ShellQuote(branchName))
+			}
 			return fmt.Errorf("failed to remove worktree: %w", err)
 		}
+		recordHistory(manager, history.Removed, manager.WorktreePath(branchName), branch)
 
 		if removeKeepBranch {
 			fmt.Printf("✅ Worktree removed successfully (branch kept)\n")
 		} else {
-			fmt.Printf("✅ Worktree and branch removed successfully\n")
+			fmt.Printf("✅ Worktree and branch '%s' removed successfully\n", branch)
 		}
 
 		return nil
 	},
 }
 
+// removedBranch returns the branch checked out in the worktree at path, which
+// Remove deletes, or name if it cannot tell.
+func removedBranch(ctx context.Context, manager *worktree.Manager, path, name string) string {
+	worktrees, err := manager.ListFast(ctx)
+	if err != nil {
+		return name
+	}
+	for _, wt := range worktrees {
+		if wt.Path == path && !wt.Detached {
+			return wt.Branch
+		}
+	}
+	return name
+}
+
+// warnNestedChanges lists the repositories nested in the worktree at path,
+// such as submodules, whose work removing it would lose, and reports whether
+// there are any. The worktree's own status does not show all of it.
+func warnNestedChanges(ctx context.Context, manager *worktree.Manager, path string) bool {
+	nested, err := manager.NestedChanges(ctx, path)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: failed to check nested repositories: %v\n", err)
+		return false
+	}
+	if len(nested) == 0 {
+		return false
+	}
+	fmt.Printf("⚠️  Removing the worktree loses work in nested repositories:\n")
+	for _, change := range nested {
+		fmt.Printf("   - %s\n", formatNestedChange(change))
+	}
+	return true
+}
+
+// formatNestedChange describes what removing a worktree would lose in a
+// nested repository, e.g. "lib/core (submodule): uncommitted changes, 2 unpushed commits".
+func formatNestedChange(change worktree.NestedChange) string {
+	name := change.Path
+	if change.Submodule {
+		name += " (submodule)"
+	}
+	var lost []string
+	if change.Dirty {
+		lost = append(lost, "uncommitted changes")
+	}
+	switch {
+	case change.Unpushed > 0 && change.NoRemote:
+		lost = append(lost, fmt.Sprintf("%d commits and no remote", change.Unpushed))
+	case change.Unpushed == 1:
+		lost = append(lost, "1 unpushed commit")
+	case change.Unpushed > 1:
+		lost = append(lost, fmt.Sprintf("%d unpushed commits", change.Unpushed))
+	}
+	return name + ": " + strings.Join(lost, ", ")
+}
+
+// confirmRemoval asks the user whether to remove the worktree called name at path.
+func confirmRemoval(name, path string) bool {
+	fmt.Printf("Remove worktree '%s' at %s? [y/N]: ", name, path)
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(response)) == "y"
+}
+
 func init() {
 	removeCmd.Flags().BoolVar(&removeForce, "force", false, "Force removal without confirmation")
 	removeCmd.Flags().BoolVar(&removeKeepBranch, "keep-branch", false, "Keep the local branch after removing worktree")
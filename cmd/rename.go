@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var renameCmd = &cobra.Command{
+	Use:     "rename <old> <new>",
+	Aliases: []string{"mv-branch"},
+	Short:   "Rename a worktree's branch and directory together",
+	Long: `Rename the branch of a worktree and move its directory to match the new name.
+The worktree's gitdir links are updated by 'git worktree move', and an upstream
+tracking the old branch name is pointed at the new name so that the next push
+publishes it. The remote branch itself is not renamed.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeWorktrees(true),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName, newName := args[0], args[1]
+
+		if err := utils.ValidateBranchName(newName); err != nil {
+			return fmt.Errorf("invalid branch name: %w", err)
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		warnJJ(manager, "jj imports the rename as bookmark '%s' being deleted and '%s' created", oldName, newName)
+		fmt.Printf("✏️  Renaming worktree '%s' to '%s'...\n", oldName, newName)
+
+		if err := manager.Rename(cmd.Context(), oldName, newName); err != nil {
+			if errors.Is(err, giwoerrors.ErrWorktreeLocked) {
+				return fmt.Errorf("refusing to rename worktree: %w\n💡 Run 'giwo unlock %s' first", err, utils.ShellQuote(oldName))
+			}
+			return fmt.Errorf("failed to rename worktree: %w", err)
+		}
+
+		newPath := manager.WorktreePath(newName)
+		fmt.Printf("✅ Worktree renamed to '%s' at %s\n", newName, newPath)
+
+		// The shell of a user inside the old directory is left in a path that no longer exists
+		if cwd, err := os.Getwd(); err != nil || strings.HasPrefix(cwd, manager.WorktreePath(oldName)) {
+			fmt.Printf("💡 Follow the worktree with: cd %s\n", utils.ShellQuote(newPath))
+		}
+
+		return nil
+	},
+}
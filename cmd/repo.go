@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/registry"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage repositories known to cross-repo mode",
+	Long: `Manage the registry of repositories used by cross-repo mode ('giwo switch --global').
+Repositories are either added explicitly or discovered below the directories
+listed in the repo_roots configuration setting.`,
+}
+
+var repoAddCmd = &cobra.Command{
+	Use:   "add [path]",
+	Short: "Register a repository",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := ""
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		manager, err := worktree.NewAt(dir)
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		reg, err := registry.Load(config.StateDir())
+		if err != nil {
+			return err
+		}
+
+		if !reg.Add(manager.RepoRoot()) {
+			fmt.Printf("📚 Repository '%s' is already registered\n", manager.RepoRoot())
+			return nil
+		}
+		if err := reg.Save(); err != nil {
+			return fmt.Errorf("failed to save repository registry: %w", err)
+		}
+
+		fmt.Printf("✅ Registered repository '%s'\n", manager.RepoRoot())
+		return nil
+	},
+}
+
+var repoRemoveCmd = &cobra.Command{
+	Use:     "remove <path|name>",
+	Aliases: []string{"rm"},
+	Short:   "Unregister a repository",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := registry.Load(config.StateDir())
+		if err != nil {
+			return err
+		}
+
+		repo := args[0]
+		removed := reg.Remove(repo)
+		if !removed {
+			if abs, err := filepath.Abs(repo); err == nil {
+				removed = reg.Remove(abs)
+			}
+		}
+		if !removed {
+			return fmt.Errorf("repository '%s' is not registered", repo)
+		}
+		if err := reg.Save(); err != nil {
+			return fmt.Errorf("failed to save repository registry: %w", err)
+		}
+
+		fmt.Printf("✅ Unregistered repository '%s'\n", repo)
+		return nil
+	},
+}
+
+var repoListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List registered and discovered repositories",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repos, err := knownRepositories()
+		if err != nil {
+			return err
+		}
+
+		if len(repos) == 0 {
+			fmt.Println("No repositories found. Use 'giwo repo add' or configure repo_roots.")
+			return nil
+		}
+
+		for _, repo := range repos {
+			fmt.Printf("%s\t%s\n", registry.Name(repo), repo)
+		}
+		return nil
+	},
+}
+
+// knownRepositories returns the registered repositories and those discovered under the configured roots.
+func knownRepositories() ([]string, error) {
+	cfg, err := config.Load("", profileName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := registry.Load(config.StateDir())
+	if err != nil {
+		return nil, err
+	}
+
+	return reg.All(cfg.ResolveRepoRoots()), nil
+}
+
+// listGlobalWorktrees lists the worktrees of all known repositories concurrently,
+// tagging each with the name of its repository. Repositories that fail to list are skipped.
+func listGlobalWorktrees(ctx context.Context) ([]*worktree.Worktree, error) {
+	repos, err := knownRepositories()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]*worktree.Worktree, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			manager, err := newManagerAt(repo)
+			if err != nil {
+				return
+			}
+			worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+			if err != nil {
+				return
+			}
+			for _, wt := range worktrees {
+				wt.Repo = registry.Name(repo)
+			}
+			results[i] = worktrees
+		}()
+	}
+	wg.Wait()
+
+	var all []*worktree.Worktree
+	for _, worktrees := range results {
+		all = append(all, worktrees...)
+	}
+	return all, nil
+}
+
+func init() {
+	repoCmd.AddCommand(repoAddCmd)
+	repoCmd.AddCommand(repoRemoveCmd)
+	repoCmd.AddCommand(repoListCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// resolveWorktree finds the worktree matching filter by branch name.
+// An exact match wins; otherwise the fuzzy finder is shown over the candidates.
+// It returns nil without an error if the user cancels the selection.
+// In non-interactive mode the finder is never shown; see worktree.Match.
+func resolveWorktree(ctx context.Context, manager *worktree.Manager, filter string) (*worktree.Worktree, error) {
+	worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	if !isInteractive() {
+		return matchWorktree(worktrees, filter)
+	}
+
+	candidates := worktree.FilterByBranch(worktrees, filter)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: no worktree matches %q", errors.ErrWorktreeNotFound, filter)
+	}
+
+	for _, wt := range candidates {
+		if wt.Branch == filter {
+			return wt, nil
+		}
+	}
+
+	finder, err := newFuzzyFinder(manager, candidates)
+	if err != nil {
+		return nil, err
+	}
+	return finder.Search()
+}
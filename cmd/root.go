@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -13,21 +18,149 @@ var rootCmd = &cobra.Command{
 	Long: `giwo is a CLI tool for efficiently managing Git worktrees.
 It supports parallel work across multiple branches and manages 
 the entire lifecycle of worktrees.`,
+	// Errors are reported by Execute; usage is only useful for flag errors
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if serverSafe {
+			// Pagers wait for a key press, so output goes straight to stdout
+			os.Setenv("PAGER", "cat")
+			os.Setenv("GIT_PAGER", "cat")
+		}
+		showFirstRunHint(cmd)
+	},
 }
 
+var (
+	// noCache disables the worktree status cache for commands that list worktrees.
+	noCache bool
+
+	// profileName selects a configuration profile instead of matching it by remote.
+	profileName string
+
+	// quiet hides the progress of long operations.
+	quiet bool
+
+	// nonInteractive disables prompts and the fuzzy finder, as if there were no terminal.
+	nonInteractive bool
+
+	// offline makes giwo work from what was last fetched instead of contacting remotes.
+	offline bool
+
+	// themeName selects the UI theme instead of theme.name of the configuration.
+	themeName string
+)
+
+// Exit codes returned when a worktree argument cannot be resolved.
+const (
+	exitError     = 1
+	exitAmbiguous = 2
+)
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	start := time.Now()
+	var err error
+	if path, ok := pluginInvocation(os.Args[1:]); ok {
+		err = runPlugin(context.Background(), os.Args[1], path, os.Args[2:])
+	} else {
+		var cmd *cobra.Command
+		cmd, err = rootCmd.ExecuteC()
+		saveTimings(cmd, start, err)
+		if err == nil {
+			noticeNewRelease(cmd)
+		}
+	}
+	if serverSafe {
+		if logErr := logOperation(start, err); logErr != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: %v\n", logErr)
+		}
+	}
+	var pluginErr *pluginExitError
+	if errors.As(err, &pluginErr) {
+		os.Exit(pluginErr.code)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "💡 %s\n", hint)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
+// exitCode returns the process exit code for err. Not finding a worktree is an
+// ordinary failure, while an ambiguous filter gets its own code so scripts can tell them apart.
+func exitCode(err error) int {
+	if errors.Is(err, giwoerrors.ErrWorktreeAmbiguous) {
+		return exitAmbiguous
+	}
+	return exitError
+}
+
+// isInteractive reports whether giwo may prompt or show the fuzzy finder.
+func isInteractive() bool {
+	return !nonInteractive && !serverSafe && ui.IsInteractive()
+}
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", os.Getenv("GIWO_PROFILE"), "Configuration profile to use (default: matched by the origin remote, or $GIWO_PROFILE)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt or show the fuzzy finder (implied without a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&serverSafe, "server-safe", envBool("GIWO_SERVER_SAFE"), "Disable interactive features, require worktree paths and log every operation (default: $GIWO_SERVER_SAFE)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Hide the progress of long operations")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log every git command giwo runs to stderr (also: GIWO_LOG=info)")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Log git commands and the steps in between to stderr (also: GIWO_LOG=debug)")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", envBool("GIWO_OFFLINE"), "Work from what was last fetched without contacting remotes or the forge (default: $GIWO_OFFLINE)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", os.Getenv("GIWO_THEME"), "UI theme: emoji, nerd, ascii or auto (default: theme.name, or $GIWO_THEME)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Recompute worktree status instead of using the status cache")
+
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(removeCmd)
+	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(mvCmd)
+	rootCmd.AddCommand(adoptCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(gcCmd)
 	rootCmd.AddCommand(switchCmd)
+	rootCmd.AddCommand(backCmd)
+	rootCmd.AddCommand(whereCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(mainCmd)
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(unlockCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(rebaseCmd)
+	rootCmd.AddCommand(bisectCmd)
+	rootCmd.AddCommand(landCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(repoCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(shellenvCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(duCmd)
+	rootCmd.AddCommand(verifyLinksCmd)
+	rootCmd.AddCommand(copyHooksCmd)
+	rootCmd.AddCommand(tutorialCmd)
+	rootCmd.AddCommand(pluginsCmd)
+	rootCmd.AddCommand(perfCmd)
+	rootCmd.AddCommand(templateCmd)
+	rootCmd.AddCommand(assistCmd)
+	rootCmd.AddCommand(taskCmd)
+	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(subCmd)
+	rootCmd.AddCommand(upgradeCmd)
+	rootCmd.AddCommand(serveCmd)
 }
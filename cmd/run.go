@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/runner"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runAll  bool
+	runJobs int
+	runList bool
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [task] [filter]",
+	Short: "Run a named task in worktrees",
+	Long: `Run a task defined under 'run' in .giwo.yaml or the config file, in the
+current worktree, the one matching the filter, or every worktree with --all:
+
+  run:
+    test:
+      run: go test ./...
+      description: Run the tests
+    dev:
+      run: npm run dev
+      env:
+        PORT: "{{.Port 3000}}"
+
+The task runs with the shell in the worktree's directory. Its environment has the
+variables printed by 'giwo env' and the task's own env, templates over the same
+fields, so that dev servers of several worktrees can listen on separate ports.
+
+With --all, the task runs in all worktrees at the same time, or at most --jobs
+at once, and each output line is prefixed with the worktree's branch.
+
+Without a task, or with --list, the defined tasks are listed.`,
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeRunTasks,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if runAll && len(args) > 1 {
+			return fmt.Errorf("cannot combine a filter with --all")
+		}
+		if runJobs < 0 {
+			return fmt.Errorf("invalid --jobs %d: must not be negative", runJobs)
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 || runList {
+			printRunTasks(cfg.Run)
+			return nil
+		}
+
+		name := args[0]
+		task, ok := cfg.Run[name]
+		if !ok {
+			return fmt.Errorf("no task named %q\n💡 Run 'giwo run --list' to see the tasks defined under 'run' in %s",
+				name, config.RepoFileName)
+		}
+
+		ctx := cmd.Context()
+		worktrees, err := manager.ListFast(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		var targets []*worktree.Worktree
+		switch {
+		case runAll:
+			targets = worktrees
+		case len(args) == 2:
+			selected, err := resolveWorktree(ctx, manager, args[1])
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+			targets = []*worktree.Worktree{selected}
+		default:
+			selected, err := currentWorktree(manager, worktrees)
+			if err != nil {
+				return err
+			}
+			targets = []*worktree.Worktree{selected}
+		}
+
+		// The variables are resolved up front, since allocating port offsets
+		// records them and must not run concurrently
+		vars := maps.Clone(cfg.Env)
+		if vars == nil {
+			vars = make(map[string]string)
+		}
+		maps.Copy(vars, task.Env)
+		envs := make([][]string, len(targets))
+		for i, wt := range targets {
+			env, err := worktreeEnv(ctx, manager, vars, worktrees, wt)
+			if err != nil {
+				return err
+			}
+			for _, v := range env {
+				envs[i] = append(envs[i], v[0]+"="+v[1])
+			}
+		}
+
+		shell := utils.ScriptShell()
+		command := append([]string{shell.Path}, shell.ScriptArgs(task.Run)...)
+
+		if len(targets) == 1 {
+			run := runner.Local{Env: envs[0], Stdin: os.Stdin}
+			if err := run.Run(ctx, targets[0].Path, command, os.Stdout, os.Stderr); err != nil {
+				return fmt.Errorf("task %s failed in '%s': %w", name, runLabel(targets[0]), err)
+			}
+			return nil
+		}
+
+		failed := runInParallel(ctx, targets, envs, command)
+		if len(failed) > 0 {
+			return fmt.Errorf("task %s failed in %d worktree(s): %s", name, len(failed), strings.Join(failed, ", "))
+		}
+		return nil
+	},
+}
+
+// runInParallel runs command in targets, at most --jobs at once, prefixing the
+// output lines with the worktrees' branches. envs holds the environment of each
+// target. It returns the branches of the worktrees where the command failed.
+func runInParallel(ctx context.Context, targets []*worktree.Worktree, envs [][]string, command []string) []string {
+	jobs := runJobs
+	if jobs == 0 {
+		jobs = len(targets)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		out    sync.Mutex
+		failed = make([]bool, len(targets))
+		slots  = make(chan struct{}, jobs)
+	)
+	for i, wt := range targets {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			prefix := fmt.Sprintf("[%s] ", runLabel(wt))
+			stdout := runner.NewLinePrefixWriter(os.Stdout, prefix, &out)
+			stderr := runner.NewLinePrefixWriter(os.Stderr, prefix, &out)
+			err := runner.Local{Env: envs[i]}.Run(ctx, wt.Path, command, stdout, stderr)
+			_ = stdout.Flush()
+			_ = stderr.Flush()
+			if err != nil {
+				failed[i] = true
+				out.Lock()
+				fmt.Fprintf(os.Stderr, "%s⚠️  %v\n", prefix, err)
+				out.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	var branches []string
+	for i, wt := range targets {
+		if failed[i] {
+			branches = append(branches, runLabel(wt))
+		}
+	}
+	return branches
+}
+
+// runLabel names a worktree in the output of 'giwo run': its branch, or its
+// directory name if its HEAD is detached.
+func runLabel(wt *worktree.Worktree) string {
+	if wt.Branch == "" {
+		return filepath.Base(wt.Path)
+	}
+	return wt.Branch
+}
+
+// printRunTasks lists the tasks with their descriptions and commands.
+func printRunTasks(tasks map[string]config.RunTask) {
+	if len(tasks) == 0 {
+		fmt.Printf("No tasks defined; add them under 'run' in %s\n", config.RepoFileName)
+		return
+	}
+
+	w := ui.NewTable(os.Stdout)
+	defer w.Flush()
+	fmt.Fprintln(w, "TASK\tDESCRIPTION\tCOMMAND")
+	for _, name := range slices.Sorted(maps.Keys(tasks)) {
+		task := tasks[name]
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, task.Description, truncateString(task.Run, 60))
+	}
+}
+
+// completeRunTasks completes the task names, then the worktree filter.
+func completeRunTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 1 {
+		return completeWorktrees(false)(cmd, nil, toComplete)
+	}
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manager, err := newManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, name := range slices.Sorted(maps.Keys(cfg.Run)) {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name+"\t"+cfg.Run[name].Description)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	runCmd.Flags().BoolVar(&runAll, "all", false, "Run the task in every worktree")
+	runCmd.Flags().IntVarP(&runJobs, "jobs", "j", 0, "Number of worktrees the task runs in at once with --all (default: all)")
+	runCmd.Flags().BoolVar(&runList, "list", false, "List the defined tasks")
+}
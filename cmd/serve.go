@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/server"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var serveSocket string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local API for editor and IDE plugins",
+	Long: `Serve a small JSON API over HTTP on a unix socket, so that editor and IDE
+plugins can list, create, remove and switch worktrees without starting giwo for
+every keystroke of a picker. Only the current user can connect to the socket.
+
+  GET  /v1/version              the version of giwo
+  GET  /v1/worktrees[?fast=1]   the worktrees; fast uses their cached status
+  POST /v1/create               {"branch": "x", "base": "main", "force": false},
+                                created as 'giwo create' does without a terminal
+  POST /v1/remove               {"name": "x", "force": false, "keep_branch": false}
+  GET  /v1/switch-path?filter=x the worktree to switch to, recorded as a visit
+  GET  /v1/events               a stream of events, one JSON object per line:
+                                worktree_created, worktree_removed and
+                                config_reloaded
+
+For example:
+
+  curl --unix-socket "$(git rev-parse --git-common-dir)/giwo/serve.sock" http://giwo/v1/worktrees
+
+Changes to the configuration files are applied between requests without
+restarting, and announced with a config_reloaded event. The server runs until
+interrupted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		path := serveSocket
+		if path == "" {
+			if err := os.MkdirAll(manager.StateDir(), 0o755); err != nil {
+				return fmt.Errorf("failed to create state directory: %w", err)
+			}
+			path = filepath.Join(manager.StateDir(), "serve.sock")
+		}
+		listener, err := server.Listen(path)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		srv := server.New(&serveBackend{manager: manager}, giwoVersion())
+		go newConfigWatcher(ctx, manager).Watch(ctx, func(cfg *config.Config, err error) {
+			srv.Reconfigure(func() {
+				if err == nil {
+					err = configureManager(manager, cfg)
+				}
+				if err != nil {
+					fmt.Printf("⚠️  Configuration not reloaded: %v\n", err)
+					return
+				}
+				manager.NotifyConfigReloaded()
+				fmt.Println("🔄 Configuration reloaded")
+			})
+		})
+
+		fmt.Printf("🔌 Serving worktrees of %s on %s (ctrl-c to stop)\n", manager.MainRoot(), path)
+		return srv.Serve(ctx, listener)
+	},
+}
+
+// serveBackend performs the operations of 'giwo serve' as the commands do.
+type serveBackend struct {
+	manager *worktree.Manager
+}
+
+// List implements server.Backend.
+func (b *serveBackend) List(ctx context.Context, fast bool) ([]*worktree.Worktree, error) {
+	if fast {
+		return b.manager.ListFast(ctx)
+	}
+	return b.manager.List(ctx, worktree.ListOptions{WithStatus: true})
+}
+
+// Create implements server.Backend. It creates the worktree as 'giwo create'
+// does without a terminal, failing where the command would ask.
+func (b *serveBackend) Create(ctx context.Context, req server.CreateRequest) (*server.PathResponse, error) {
+	if err := utils.ValidateBranchName(req.Branch); err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(b.manager)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := resolveCheckedOut(ctx, b.manager, cfg, req.Branch, false); err != nil {
+		return nil, err
+	}
+	branch, err := createFromBase(ctx, b.manager, cfg, req.Branch, req.Base, req.Force, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.manager.SetIdentity(ctx, cfg.User.Name, cfg.User.Email); err != nil {
+		fmt.Printf("⚠️  Warning: failed to set git identity: %v\n", err)
+	}
+
+	path := b.manager.WorktreePath(req.Branch)
+	recordHistory(b.manager, history.Created, path, branch)
+	return &server.PathResponse{Path: path, Branch: branch}, nil
+}
+
+// Remove implements server.Backend.
+func (b *serveBackend) Remove(ctx context.Context, req server.RemoveRequest) error {
+	opts := worktree.RemoveOptions{Name: req.Name, Force: req.Force, KeepBranch: req.KeepBranch}
+	if err := b.manager.Remove(ctx, opts); err != nil {
+		return err
+	}
+	recordHistory(b.manager, history.Removed, b.manager.WorktreePath(req.Name), req.Name)
+	return nil
+}
+
+// SwitchPath implements server.Backend.
+func (b *serveBackend) SwitchPath(ctx context.Context, filter string) (*server.PathResponse, error) {
+	worktrees, err := b.manager.ListFast(ctx)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := matchWorktree(worktrees, filter)
+	if err != nil {
+		return nil, err
+	}
+	recordHistory(b.manager, history.Switched, selected.Path, selected.Branch)
+	return &server.PathResponse{Path: selected.Path, Branch: selected.Branch}, nil
+}
+
+// Subscribe implements server.Backend.
+func (b *serveBackend) Subscribe(ctx context.Context, fn func(worktree.Event)) {
+	b.manager.Subscribe(ctx, fn)
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveSocket, "socket", "", "Path of the unix socket (default: serve.sock in giwo's state directory of the repository)")
+}
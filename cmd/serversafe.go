@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// serverSafe restricts giwo for unattended use on shared servers and bare clones:
+// nothing interactive is started, worktrees must be named by path and every
+// invocation is appended to the operations log.
+var serverSafe bool
+
+// operation is an entry of the server-safe operations log.
+type operation struct {
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	User     string    `json:"user,omitempty"`
+	PID      int       `json:"pid"`
+	Dir      string    `json:"dir,omitempty"`
+	Args     []string  `json:"args"`
+	ExitCode int       `json:"exit_code"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// envBool reports whether the environment variable name is set to a true value.
+func envBool(name string) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && value
+}
+
+// checkServerSafe fails if feature, which needs a user at the terminal, may not run.
+func checkServerSafe(feature string) error {
+	if serverSafe {
+		return fmt.Errorf("%s is disabled in server-safe mode", feature)
+	}
+	return nil
+}
+
+// matchWorktree resolves a worktree argument without prompting; see worktree.Match.
+// In server-safe mode only an exact path is accepted.
+func matchWorktree(worktrees []*worktree.Worktree, filter string) (*worktree.Worktree, error) {
+	if serverSafe {
+		return worktree.MatchPath(worktrees, filter)
+	}
+	return worktree.Match(worktrees, filter)
+}
+
+// operationsLogPath returns the path of the operations log, which can be moved
+// with $GIWO_OPERATIONS_LOG.
+func operationsLogPath() string {
+	if path := os.Getenv("GIWO_OPERATIONS_LOG"); path != "" {
+		return path
+	}
+	return filepath.Join(config.StateDir(), "operations.log")
+}
+
+// logOperation appends the invocation that started at start and ended with err
+// to the operations log as a line of JSON.
+func logOperation(start time.Time, err error) error {
+	entry := operation{
+		Time:     start.UTC(),
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+		PID:      os.Getpid(),
+		Args:     os.Args[1:],
+	}
+	if u, userErr := user.Current(); userErr == nil {
+		entry.User = u.Username
+	}
+	if dir, dirErr := os.Getwd(); dirErr == nil {
+		entry.Dir = dir
+	}
+	if err != nil {
+		entry.ExitCode = exitCode(err)
+		entry.Error = err.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path := operationsLogPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open operations log: %w", err)
+	}
+	defer f.Close()
+
+	// A single write keeps concurrent invocations from interleaving their entries
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write operations log: %w", err)
+	}
+	return nil
+}
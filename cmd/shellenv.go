@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shellenvFile   string
+	shellenvFormat string
+)
+
+var shellenvCmd = &cobra.Command{
+	Use:   "export-shellenv",
+	Short: "Print worktree paths as environment variables",
+	Long: `Print the repository root and the path of every worktree as environment
+variables, for CI jobs and scripts that need the worktree layout:
+
+  eval "$(giwo export-shellenv)"
+  giwo export-shellenv -f worktrees.yaml --format dotenv >> "$GITHUB_ENV"
+
+GIWO_REPO_ROOT holds the repository root and GIWO_WORKTREE_<BRANCH> each
+worktree's path, with the branch upper-cased and other characters replaced
+by underscores. With -f, only the worktrees of the spec are exported, under
+the names given by their 'env' keys; it is an error if one does not exist.
+
+--format sh (default) prints export statements; --format dotenv prints
+NAME=value lines.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateEnvFormat(shellenvFormat); err != nil {
+			return err
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		worktrees, err := manager.ListFast(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		vars := [][2]string{{"GIWO_REPO_ROOT", manager.RepoRoot()}}
+		if shellenvFile == "" {
+			for _, wt := range worktrees {
+				if wt.Branch != "" {
+					vars = append(vars, [2]string{worktree.EnvName(wt.Branch), wt.Path})
+				}
+			}
+		} else {
+			spec, err := worktree.LoadSpec(shellenvFile)
+			if err != nil {
+				return err
+			}
+
+			paths := make(map[string]string, len(worktrees))
+			for _, wt := range worktrees {
+				paths[wt.Branch] = wt.Path
+			}
+			for _, entry := range spec.Worktrees {
+				path, ok := paths[entry.Branch]
+				if !ok {
+					return fmt.Errorf("no worktree for branch %s; run 'giwo apply -f %s' first", entry.Branch, utils.ShellQuote(shellenvFile))
+				}
+				vars = append(vars, [2]string{entry.EnvName(), path})
+			}
+		}
+
+		printEnv(vars, shellenvFormat)
+		return nil
+	},
+}
+
+// validateEnvFormat checks a --format flag of the commands printing environment variables.
+func validateEnvFormat(format string) error {
+	if format != "sh" && format != "dotenv" {
+		return fmt.Errorf("invalid --format %q: must be sh or dotenv", format)
+	}
+	return nil
+}
+
+// printEnv prints vars as export statements, or as NAME=value lines for the dotenv format.
+func printEnv(vars [][2]string, format string) {
+	for _, v := range vars {
+		if format == "dotenv" {
+			fmt.Printf("%s=%s\n", v[0], v[1])
+		} else {
+			fmt.Printf("export %s=%s\n", v[0], utils.ShellQuote(v[1]))
+		}
+	}
+}
+
+func init() {
+	shellenvCmd.Flags().StringVarP(&shellenvFile, "file", "f", "", "Only export the worktrees of this spec file")
+	shellenvCmd.Flags().StringVar(&shellenvFormat, "format", "sh", "Output format: sh or dotenv")
+}
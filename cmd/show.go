@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/codeowners"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var showDiff bool
+
+var showCmd = &cobra.Command{
+	Use:   "show [filter]",
+	Short: "Show a worktree's changes against its base",
+	Long: `Show the commit list and diffstat of a worktree versus the base it was created from.
+Worktrees without a recorded base are compared against the default branch.
+
+In a terminal, worktrees are picked in the fuzzy finder: enter opens a scrollable
+detail pane with the changes of the highlighted worktree, ctrl-v shows its full
+diff in $PAGER, and esc goes back. Without a terminal the summary is printed.
+Use --diff to open the full diff in git's pager instead.
+
+If the repository has a CODEOWNERS file, the owners of the changed paths are listed too.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		filter := ""
+		if len(args) > 0 {
+			filter = args[0]
+		}
+
+		ctx := cmd.Context()
+		if !showDiff && isInteractive() {
+			return browseChanges(ctx, manager, filter)
+		}
+
+		selected, err := resolveWorktree(ctx, manager, filter)
+		if err != nil {
+			return err
+		}
+		if selected == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+
+		if showDiff {
+			return openFullDiff(ctx, manager, selected)
+		}
+
+		summary, err := manager.DiffSummary(ctx, selected)
+		if err != nil {
+			return fmt.Errorf("failed to summarize changes: %w", err)
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Branch: %s\n", selected.Branch)
+		fmt.Fprintf(&b, "Path: %s\n\n", selected.Path)
+		b.WriteString(ui.FormatDiffSummary(summary))
+
+		owners, err := codeowners.Load(selected.Path)
+		if err != nil {
+			fmt.Printf("⚠️  Warning: failed to read CODEOWNERS: %v\n", err)
+		}
+		if owners != nil {
+			b.WriteString("\n")
+			b.WriteString(ui.FormatOwners(codeowners.Affected(owners, summary.Files)))
+		}
+
+		b.WriteString("\n\n💡 Run 'giwo show")
+		if filter != "" {
+			b.WriteString(" " + filter)
+		}
+		b.WriteString(" --diff' to open the full diff\n")
+
+		return ui.Page(b.String())
+	},
+}
+
+// browseChanges lets the user review the changes of worktrees in the detail
+// pane of the fuzzy finder, which enter opens, until they cancel. A filter
+// naming a single worktree opens its pane right away.
+func browseChanges(ctx context.Context, manager *worktree.Manager, filter string) error {
+	worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	finder, err := newFuzzyFinder(manager, worktrees)
+	if err != nil {
+		return err
+	}
+	finder.WithHeader("Review Changes").WithQuery(filter).WithAutoSelect(false).WithDetailsOnAccept().
+		WithDetails(diffDetails(ctx, manager)).WithFullDiff(fullDiff(ctx, manager))
+	if filter != "" && len(ui.FilterWorktrees(worktrees, filter)) == 1 {
+		finder.OpenDetails()
+	}
+
+	_, _, err = finder.Pick()
+	return err
+}
+
+// openFullDiff shows the full diff of a worktree against its base using git's pager.
+func openFullDiff(ctx context.Context, manager *worktree.Manager, wt *worktree.Worktree) error {
+	base, err := manager.ResolveBase(ctx, wt)
+	if err != nil {
+		return err
+	}
+
+	gitCmd := exec.CommandContext(ctx, "git", "diff", base+"...HEAD")
+	gitCmd.Dir = wt.Path
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = os.Stdout
+	gitCmd.Stderr = os.Stderr
+	return gitCmd.Run()
+}
+
+// diffDetails returns a function rendering a worktree's diff summary, and the
+// code owners of the changed paths, for the fuzzy finder's preview and detail pane.
+func diffDetails(ctx context.Context, manager *worktree.Manager) func(*worktree.Worktree) string {
+	return func(wt *worktree.Worktree) string {
+		if wt.IsMain {
+			return ""
+		}
+		summary, err := manager.DiffSummary(ctx, wt)
+		if err != nil {
+			return ""
+		}
+		details := ui.FormatDiffSummary(summary)
+		if owners, err := codeowners.Load(wt.Path); err == nil && owners != nil {
+			details += "\n" + ui.FormatOwners(codeowners.Affected(owners, summary.Files))
+		}
+		return details
+	}
+}
+
+// fullDiff returns a function producing a worktree's full diff against its
+// base, which the fuzzy finder shows in the pager.
+func fullDiff(ctx context.Context, manager *worktree.Manager) func(*worktree.Worktree) (string, error) {
+	return func(wt *worktree.Worktree) (string, error) {
+		base, err := manager.ResolveBase(ctx, wt)
+		if err != nil {
+			return "", err
+		}
+
+		gitCmd := exec.CommandContext(ctx, "git", "diff", base+"...HEAD")
+		gitCmd.Dir = wt.Path
+		output, err := gitCmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to diff against %s: %w", base, err)
+		}
+		return string(output), nil
+	}
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showDiff, "diff", false, "Open the full diff against the base in git's pager instead of the fuzzy finder")
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var snapshotOutput string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore the set of worktrees",
+	Long: `Save the set of worktrees of the repository and recreate it later.
+
+'giwo snapshot save <name>' records the branch, commit, base and labels of each
+worktree besides the main one. 'giwo snapshot restore <name>' recreates those
+that do not exist, after a cleanup or on another machine. Snapshots written
+to a file with --output can be shared, and restored from the file:
+
+  giwo snapshot save release-2.0 --output release-2.0.json
+  giwo snapshot restore release-2.0.json
+
+Without a subcommand, the saved snapshots are listed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		snapshots, err := manager.Snapshots()
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("No snapshots found. Use 'giwo snapshot save <name>' to save one.")
+			return nil
+		}
+
+		w := ui.NewTable(os.Stdout)
+		defer w.Flush()
+		fmt.Fprintln(w, "NAME\tWORKTREES\tSAVED")
+		for _, snapshot := range snapshots {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", snapshot.Name, len(snapshot.Worktrees), snapshot.CreatedAt.Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Record the current worktrees",
+	Long: `Record the branch, commit, base and labels of each worktree besides the
+main one under a name, replacing an earlier snapshot of that name.
+
+With --output, the snapshot is written to a file instead, or to standard output
+with '-', to share it or keep it outside the repository.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+			return fmt.Errorf("invalid snapshot name %q: must not be empty, start with '.' or contain slashes", name)
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		snapshot, err := manager.TakeSnapshot(cmd.Context(), name)
+		if err != nil {
+			return fmt.Errorf("failed to take snapshot: %w", err)
+		}
+
+		switch snapshotOutput {
+		case "":
+			if err := manager.SaveSnapshot(snapshot); err != nil {
+				return fmt.Errorf("failed to save snapshot: %w", err)
+			}
+		case "-":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(snapshot)
+		default:
+			data, err := json.MarshalIndent(snapshot, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode snapshot: %w", err)
+			}
+			if err := os.WriteFile(snapshotOutput, append(data, '\n'), 0o644); err != nil {
+				return fmt.Errorf("failed to write snapshot: %w", err)
+			}
+		}
+
+		fmt.Printf("📸 Saved snapshot '%s' of %d worktree(s)\n", name, len(snapshot.Worktrees))
+		fmt.Printf("💡 Run 'giwo snapshot restore %s' to recreate them\n", utils.ShellQuote(snapshotSource(name)))
+		return nil
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <name|file|->",
+	Short: "Recreate the worktrees of a snapshot",
+	Long: `Recreate the worktrees of a snapshot that do not exist, at the same place
+relative to the worktree directory and with their labels. Worktrees already
+there are left alone.
+
+Origin is fetched first. A branch is checked out if it exists locally, continued
+from origin if only origin has it, and otherwise recreated at the recorded
+commit, or started from its base on origin if the commit is not available
+either. Detached worktrees need their commit.
+
+The snapshot is read from a file if the argument names one, from standard input
+with '-', and else from those saved with 'giwo snapshot save'. The command exits
+with 1 unless all missing worktrees were recreated.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		snapshot, err := readSnapshot(manager, args[0])
+		if err != nil {
+			return err
+		}
+		if len(snapshot.Worktrees) == 0 {
+			fmt.Println("No worktrees in the snapshot.")
+			return nil
+		}
+
+		ctx := cmd.Context()
+		fmt.Printf("📸 Restoring %d worktree(s) of snapshot '%s'...\n", len(snapshot.Worktrees), snapshot.Name)
+		results, err := manager.RestoreSnapshot(ctx, snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to restore snapshot: %w", err)
+		}
+
+		fmt.Println()
+		created, failed := 0, 0
+		for _, result := range results {
+			label := result.Worktree.Branch
+			if label == "" {
+				label = result.Worktree.Name + " (detached)"
+			}
+			switch result.Status {
+			case worktree.SnapshotCreated:
+				recordHistory(manager, history.Created, result.Path, result.Worktree.Branch)
+				fmt.Printf("✅ %s at %s\n", label, displayPath(result.Path))
+				created++
+			case worktree.SnapshotExists:
+				fmt.Printf("⏭️  %s already exists at %s\n", label, displayPath(result.Path))
+			default:
+				fmt.Printf("❌ %s: %v\n", label, result.Err)
+				failed++
+			}
+		}
+
+		if created+failed == 0 {
+			fmt.Println("\n📊 All worktrees of the snapshot exist already")
+			return nil
+		}
+		fmt.Printf("\n📊 Created %d of %d missing worktrees\n", created, created+failed)
+		if failed > 0 {
+			return fmt.Errorf("%d of %d missing worktrees could not be created", failed, created+failed)
+		}
+		return nil
+	},
+}
+
+// readSnapshot reads the snapshot named by arg: a file, standard input for "-",
+// or else a snapshot saved in the repository.
+func readSnapshot(manager *worktree.Manager, arg string) (*worktree.Snapshot, error) {
+	if arg == "-" {
+		return worktree.ReadSnapshot(os.Stdin)
+	}
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		f, err := os.Open(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		defer f.Close()
+		return worktree.ReadSnapshot(f)
+	}
+	return manager.LoadSnapshot(arg)
+}
+
+// snapshotSource returns what 'giwo snapshot restore' takes to restore the snapshot just saved.
+func snapshotSource(name string) string {
+	if snapshotOutput != "" {
+		return snapshotOutput
+	}
+	return name
+}
+
+func init() {
+	snapshotSaveCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "", "Write the snapshot to a file, or '-' for standard output, instead of saving it")
+
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+}
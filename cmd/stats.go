@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/history"
+	"github.com/knwoop/giwo/internal/registry"
+	"github.com/knwoop/giwo/internal/stats"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsRepo  bool
+	statsLimit int
+	statsJSON  bool
+	statsReset bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show which worktrees you spend your time in",
+	Long: `Show how often you switched to each worktree and how long you stayed there,
+across all repositories, the worktrees you spent most time in first.
+
+A stay lasts from switching to (or creating) a worktree with giwo until
+switching to another one. Since giwo cannot tell when you step away, a single
+stay counts for at most 4 hours.
+
+The metrics are kept in stats.json in giwo's state directory and never leave
+this machine. They also rank the worktrees in the fuzzy finder, putting those
+you switch to often and recently first. Use --reset to start over.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stateDir := config.StateDir()
+		if statsReset {
+			if err := stats.Reset(stateDir); err != nil {
+				return err
+			}
+			fmt.Println("🧹 Stats reset")
+			return nil
+		}
+
+		s, err := stats.Load(stateDir)
+		if err != nil {
+			return err
+		}
+		list := s.List(time.Now())
+		if statsRepo {
+			manager, err := newManager()
+			if err != nil {
+				return fmt.Errorf("failed to initialize manager: %w", err)
+			}
+			list = slices.DeleteFunc(list, func(w stats.Worktree) bool { return w.Repo != manager.MainRoot() })
+		}
+		if statsLimit > 0 && len(list) > statsLimit {
+			list = list[:statsLimit]
+		}
+
+		if statsJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(list)
+		}
+
+		if len(list) == 0 {
+			fmt.Println("No stats yet. They are collected as you switch between worktrees with giwo.")
+			return nil
+		}
+
+		w := ui.NewTable(os.Stdout)
+		fmt.Fprintln(w, "TIME\tSWITCHES\tLAST VISIT\tREPO\tBRANCH\tPATH")
+		for _, e := range list {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", formatStay(e.Time), e.Switches, utils.TimeAgo(e.LastVisit), registry.Name(e.Repo), e.Branch, e.Path)
+		}
+		return w.Flush()
+	},
+}
+
+// formatStay formats time spent in a worktree to the minute.
+func formatStay(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// recordStats updates the usage metrics for an action recorded in the history.
+// Like the history, metrics are not worth failing a command over.
+func recordStats(action history.Action, repo, path, branch string) {
+	stateDir := config.StateDir()
+	s, err := stats.Load(stateDir)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	switch action {
+	case history.Created, history.Switched:
+		s.Visit(repo, path, branch, now)
+	case history.Removed:
+		s.Leave(path, now)
+	}
+	_ = s.Save(stateDir)
+}
+
+// rankWorktrees returns worktrees ordered by frecency, those switched to often
+// and recently first. Worktrees never switched to keep their order, after the
+// others.
+func rankWorktrees(worktrees []*worktree.Worktree) []*worktree.Worktree {
+	s, err := stats.Load(config.StateDir())
+	if err != nil || len(s.Worktrees) == 0 {
+		return worktrees
+	}
+	now := time.Now()
+	scores := make(map[string]float64, len(worktrees))
+	for _, wt := range worktrees {
+		scores[wt.Path] = s.Score(wt.Path, now)
+	}
+	ranked := slices.Clone(worktrees)
+	slices.SortStableFunc(ranked, func(a, b *worktree.Worktree) int {
+		switch sa, sb := scores[a.Path], scores[b.Path]; {
+		case sa > sb:
+			return -1
+		case sa < sb:
+			return 1
+		}
+		return 0
+	})
+	return ranked
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsRepo, "repo", false, "Only show worktrees of the current repository")
+	statsCmd.Flags().IntVar(&statsLimit, "limit", 20, "Number of worktrees to show, 0 for all")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the stats as JSON")
+	statsCmd.Flags().BoolVar(&statsReset, "reset", false, "Delete all stats")
+}
@@ -1,27 +1,81 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"time"
 
+	"github.com/knwoop/giwo/internal/config"
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
 
+var (
+	statusRemote   bool
+	statusWatch    bool
+	statusInterval time.Duration
+	statusFormat   string
+)
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show worktree statistics",
-	Long:  `Display statistics about worktrees and provide recommended actions.`,
+	Long: `Display statistics about worktrees and provide recommended actions.
+With --remote, the ahead/behind counts and the pull request and CI status of
+each branch are shown as well. GitHub is queried with the github_token setting,
+$GITHUB_TOKEN, or the gh CLI.
+
+With --watch, the worktrees are shown with their changes and ahead/behind
+counts, and the display is refreshed whenever they change, such as after an
+edit, a commit or a fetch in any worktree, until interrupted with ctrl-c.
+Changes are noticed through file notifications, and only the worktrees that
+changed are checked again; all of them are checked every --interval as well,
+for what notifications miss. Changes to the configuration files, such as the
+theme or age thresholds, are applied without restarting.
+
+With --format, counts of worktrees by state are printed for machines instead:
+in total, dirty, ahead of and behind their upstream, locked, prunable and
+stale, that is without activity for the 'stale' age threshold, with the disk
+space stale worktrees use. 'prom' prints them in the Prometheus text format,
+e.g. for node_exporter's textfile collector, and 'env' as shell variables:
+
+  eval "$(giwo status --format env)"
+  [ "$GIWO_STALE" -lt 10 ] || echo "$GIWO_STALE stale worktrees"`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		manager, err := worktree.New()
+		if statusWatch && statusRemote {
+			return fmt.Errorf("--watch cannot be combined with --remote")
+		}
+		if statusFormat != "" && (statusWatch || statusRemote) {
+			return fmt.Errorf("--format cannot be combined with --watch or --remote")
+		}
+		switch statusFormat {
+		case "", "prom", "env":
+		default:
+			return fmt.Errorf("invalid --format %q: must be prom or env", statusFormat)
+		}
+		if statusInterval <= 0 {
+			return fmt.Errorf("invalid interval %s: must be positive", statusInterval)
+		}
+
+		manager, err := newManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize manager: %w", err)
 		}
 
 		ctx := cmd.Context()
-		worktrees, err := manager.List(ctx)
+		if statusWatch {
+			return watchStatus(ctx, manager)
+		}
+
+		worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
 		if err != nil {
 			return fmt.Errorf("failed to list worktrees: %w", err)
 		}
+		if statusFormat != "" {
+			return printMetrics(ctx, manager, worktrees)
+		}
 
 		stats := calculateStats(worktrees)
 
@@ -29,12 +83,21 @@ var statusCmd = &cobra.Command{
 		fmt.Printf("  Total worktrees: %d\n", stats.Total)
 		fmt.Printf("  Active worktrees: %d\n", stats.Active)
 		fmt.Printf("  Dirty worktrees: %d\n", stats.Dirty)
-		fmt.Printf("  Main worktree: %s\n", formatBool(stats.MainExists))
+		if !manager.Bare() {
+			fmt.Printf("  Main worktree: %s\n", formatBool(stats.MainExists))
+		}
 
 		if stats.Dirty > 0 {
 			fmt.Printf("\n⚠️  %d worktree(s) have uncommitted changes\n", stats.Dirty)
 		}
 
+		if statusRemote {
+			if err := fetchRemoteStatus(ctx, manager, worktrees); err != nil {
+				return err
+			}
+			printRemoteStatus(worktrees)
+		}
+
 		mergedBranches, err := manager.GetMergedBranches(ctx)
 		if err == nil && len(mergedBranches) > 0 {
 			fmt.Printf("\n🧹 %d merged branch(es) can be cleaned up:\n", len(mergedBranches))
@@ -52,6 +115,122 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// watchStatus redraws the worktree table whenever the worktrees change. On a
+// terminal the screen is cleared first; otherwise each update is appended, so
+// that the output can be logged. When the configuration files change, watching
+// stops while the new settings are applied and starts over with them.
+func watchStatus(ctx context.Context, manager *worktree.Manager) error {
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return err
+	}
+	ages, err := ageThresholds(cfg)
+	if err != nil {
+		return err
+	}
+	theme, err := uiTheme(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	type reload struct {
+		cfg *config.Config
+		err error
+	}
+	reloads := make(chan reload)
+	go newConfigWatcher(ctx, manager).Watch(ctx, func(cfg *config.Config, err error) {
+		select {
+		case reloads <- reload{cfg, err}:
+		case <-ctx.Done():
+		}
+	})
+
+	interactive := isInteractive()
+	var notice string
+	for {
+		watchCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			manager.Watch(watchCtx, statusInterval, func(worktrees []*worktree.Worktree, err error) {
+				if interactive {
+					// Move home and clear the screen
+					fmt.Print("\033[H\033[2J")
+				} else {
+					fmt.Println()
+				}
+				fmt.Printf("👀 Watching worktrees (ctrl-c to stop), updated %s\n", time.Now().Format("15:04:05"))
+				if notice != "" {
+					fmt.Println(notice)
+				}
+				fmt.Println()
+				if err != nil {
+					fmt.Printf("⚠️  Failed to list worktrees: %v\n", err)
+					return
+				}
+
+				stats := calculateStats(worktrees)
+				_ = printTable(worktrees, ages, theme, true, false, false)
+				fmt.Printf("\n📊 %d worktree(s), %d dirty\n", stats.Total, stats.Dirty)
+			})
+		}()
+
+		var r reload
+		select {
+		case <-ctx.Done():
+		case r = <-reloads:
+		}
+		cancel()
+		<-done
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Nothing uses the manager or the settings until watching starts over
+		newAges, newTheme, err := ages, theme, r.err
+		if err == nil {
+			newAges, err = ageThresholds(r.cfg)
+		}
+		if err == nil {
+			newTheme, err = uiTheme(r.cfg)
+		}
+		if err == nil {
+			err = configureManager(manager, r.cfg)
+		}
+		if err != nil {
+			notice = fmt.Sprintf("⚠️  Configuration not reloaded: %v", err)
+			continue
+		}
+		ages, theme = newAges, newTheme
+		manager.NotifyConfigReloaded()
+		notice = "🔄 Configuration reloaded at " + time.Now().Format("15:04:05")
+	}
+}
+
+// printMetrics prints the counts of worktrees by state in the --format.
+func printMetrics(ctx context.Context, manager *worktree.Manager, worktrees []*worktree.Worktree) error {
+	cfg, err := loadConfig(manager)
+	if err != nil {
+		return err
+	}
+	ages, err := ageThresholds(cfg)
+	if err != nil {
+		return err
+	}
+
+	metrics, err := worktree.CollectMetrics(ctx, worktrees, ages.Stale, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to measure disk usage: %w", err)
+	}
+	if statusFormat == "env" {
+		return worktree.WriteMetricsEnv(os.Stdout, metrics)
+	}
+	return worktree.WriteMetrics(os.Stdout, metrics, manager.MainRoot())
+}
+
 func calculateStats(worktrees []*worktree.Worktree) worktree.Stats {
 	stats := worktree.Stats{
 		Total: len(worktrees),
@@ -78,3 +257,30 @@ func formatBool(b bool) string {
 	}
 	return "❌ missing"
 }
+
+// printRemoteStatus prints the upstream and pull request status of each branch worktree.
+func printRemoteStatus(worktrees []*worktree.Worktree) {
+	fmt.Println("\n🔀 Branches")
+	for _, wt := range worktrees {
+		if wt.Remote == nil {
+			continue
+		}
+
+		sync := "no upstream"
+		if wt.Upstream != "" {
+			sync = fmt.Sprintf("+%d/-%d vs %s", wt.Ahead, wt.Behind, wt.Upstream)
+		}
+		fmt.Printf("  - %s: %s, %s\n", wt.Branch, sync, formatRemoteStatus(wt))
+		if wt.Remote.Error != "" {
+			fmt.Printf("    ⚠️  %s\n", wt.Remote.Error)
+		}
+	}
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusRemote, "remote", false, "Show ahead/behind, pull request and CI status from GitHub")
+	statusCmd.Flags().BoolVarP(&statusWatch, "watch", "w", false, "Keep showing the worktrees, refreshing them as they change")
+	statusCmd.Flags().DurationVar(&statusInterval, "interval", worktree.DefaultWatchInterval, "How often to check all worktrees with --watch, besides whenever their files change")
+	statusCmd.Flags().StringVar(&statusFormat, "format", "", "Print counts of worktrees by state instead, as prom (Prometheus) or env (shell variables)")
+	statusCmd.Flags().DurationVar(&remoteTimeout, "remote-timeout", 10*time.Second, "Time limit for fetching remote status")
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	subBase  string
+	subForce bool
+	subJSON  bool
+)
+
+var subCmd = &cobra.Command{
+	Use:   "sub",
+	Short: "Develop a submodule together with the superproject",
+	Long: `Develop a submodule together with the superproject in paired worktrees.
+
+'giwo sub create' creates a worktree of the superproject and checks out the
+submodule in it as a worktree of the submodule's own repository, on a branch of
+the same name. Commits made in the submodule land on that branch, and the
+superproject worktree sees them as a changed submodule, ready to be committed.
+
+'giwo remove' takes the submodule worktree down along with the superproject's.`,
+}
+
+var subCreateCmd = &cobra.Command{
+	Use:   "create <submodule> <branch-name>",
+	Short: "Create paired worktrees of the superproject and a submodule",
+	Long: `Create a worktree of the superproject on a new branch, as 'giwo create' does,
+and check out the submodule at its path as a worktree of the submodule's
+repository. The submodule branch of the same name is continued if it exists,
+and otherwise started from the commit the superproject records.
+
+The submodule must be initialized in the main worktree ('git submodule update --init').`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		submodule, branchName := args[0], args[1]
+		if err := utils.ValidateBranchName(branchName); err != nil {
+			return fmt.Errorf("invalid branch name: %w", err)
+		}
+
+		ctx := cmd.Context()
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		base := subBase
+		if base == "" {
+			if base, err = manager.GetCurrentBranch(ctx); err != nil {
+				return fmt.Errorf("failed to get current branch: %w", err)
+			}
+		}
+
+		fmt.Printf("🌱 Creating worktree '%s' with submodule '%s' based on '%s'...\n", branchName, submodule, base)
+		pair, err := manager.CreateWithSubmodule(ctx, worktree.SubmoduleOptions{
+			Submodule: submodule,
+			Branch:    branchName,
+			Base:      base,
+			Force:     subForce,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Worktree created successfully at: %s\n", pair.Path)
+		fmt.Printf("🧩 Submodule '%s' is on branch '%s' at: %s\n", pair.Submodule, pair.Branch, pair.SubmodulePath)
+		return nil
+	},
+}
+
+var subListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List paired worktrees",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		pairs, err := manager.SubmodulePairs(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if subJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if pairs == nil {
+				pairs = []worktree.SubmodulePair{}
+			}
+			return encoder.Encode(pairs)
+		}
+
+		if len(pairs) == 0 {
+			fmt.Println("No paired worktrees found. Use 'giwo sub create <submodule> <branch-name>' to create one.")
+			return nil
+		}
+
+		w := ui.NewTable(os.Stdout)
+		defer w.Flush()
+		fmt.Fprintln(w, "BRANCH\tPATH\tSUBMODULE\tSUBMODULE PATH")
+		for _, pair := range pairs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pair.Branch, pair.Path, pair.Submodule, pair.SubmodulePath)
+		}
+		return nil
+	},
+}
+
+func init() {
+	subCreateCmd.Flags().StringVar(&subBase, "base", "", "Superproject branch to start from (default: current branch)")
+	subCreateCmd.Flags().BoolVar(&subForce, "force", false, "Force creation even if the directory exists")
+	subListCmd.Flags().BoolVar(&subJSON, "json", false, "Output in JSON format")
+
+	subCmd.AddCommand(subCreateCmd)
+	subCmd.AddCommand(subListCmd)
+}
@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/history"
 	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
 	"github.com/knwoop/giwo/pkg/worktree"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +21,9 @@ var (
 	switchFilter   string
 	switchPrint    bool
 	switchSelector bool
+	switchGlobal   bool
+	switchTag      string
+	switchOnDirty  string
 )
 
 var switchCmd = &cobra.Command{
@@ -22,60 +32,261 @@ var switchCmd = &cobra.Command{
 	Short:   "Switch to a worktree interactively",
 	Long: `Switch to a worktree using an interactive fuzzy search interface.
 By default, shows all worktrees with real-time incremental filtering.
-Use --selector for the classic numbered list interface instead.`,
+Use --selector for the classic numbered list interface instead.
+
+Keys act on the highlighted worktree without leaving the picker: ctrl-x removes
+it, ctrl-o opens it in the editor, ctrl-t in tmux, ctrl-y copies its path and
+ctrl-s shows its status. In the selector, type d, e, t, y or s before the number.
+Bindings can be changed under 'keymap' in the config file. Changes to the
+config files are applied to the open fuzzy finder without restarting it.
+
+The filter works the same in both interfaces: a branch name or path picks that
+worktree, and anything else is fuzzy matched against the worktree names, then
+their labels, paths and last commit subjects. When it matches a single worktree,
+it is switched to right away; set 'auto_select: false' in the config file to
+always show the picker. Otherwise the fuzzy finder opens with the filter as its
+query, and the selector lists the matches.
+
+Without a terminal, or with --non-interactive, the filter must name a single
+worktree by branch or path. The command exits with 1 if nothing matches and
+with 2 if the filter is ambiguous.
+
+When the current worktree has uncommitted changes to tracked files, you are
+asked whether to leave them there, stash them or carry them to the worktree you
+switch to. Use --on-dirty to decide without being asked: leave, stash (as an
+entry named after the branch and time, see 'git stash list'), carry, or fail.
+Without a terminal, the changes are left where they are.
+
+With --tag, only worktrees carrying the label (see 'giwo tag') are offered.
+
+With --global, worktrees of all known repositories (see 'giwo repo') are shown,
+prefixed by their repository name.
+
+'giwo switch -' switches back to the previously visited worktree, like 'cd -'
+(see 'giwo back').`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runSwitchCommand,
 }
 
 func runSwitchCommand(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
-
-	manager, err := worktree.New()
-	if err != nil {
-		return fmt.Errorf("failed to initialize manager: %w", err)
+	if len(args) > 0 && args[0] == "-" {
+		// Like 'cd -'
+		return switchBack(1)
 	}
 
-	worktrees, err := manager.List(ctx)
+	var (
+		manager   *worktree.Manager
+		worktrees []*worktree.Worktree
+		err       error
+	)
+	if switchGlobal {
+		worktrees, err = listGlobalWorktrees(ctx)
+	} else {
+		manager, err = newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		// Status is loaded while the finder is open, as only it shows the status
+		worktrees, err = manager.List(ctx, worktree.ListOptions{})
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
+	if switchTag != "" {
+		if worktrees = worktree.FilterByLabel(worktrees, switchTag); len(worktrees) == 0 {
+			return fmt.Errorf("%w: no worktree is labeled '%s'", giwoerrors.ErrWorktreeNotFound, switchTag)
+		}
+	}
+
+	filter := switchFilter
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	if !isInteractive() {
+		selected, err := matchWorktree(worktrees, filter)
+		if err != nil {
+			return err
+		}
+		return switchTo(manager, selected)
+	}
 
 	if len(worktrees) == 0 {
 		fmt.Println("No worktrees found. Use 'giwo create <branch-name>' to create one.")
 		return nil
 	}
 
-	var selected *worktree.Worktree
+	// Removal needs the worktree's own repository, so it is not offered across repositories
+	actions := []ui.Action{ui.ActionOpenEditor, ui.ActionOpenTmux, ui.ActionCopyPath, ui.ActionStatus}
+	if manager != nil {
+		actions = append(actions, ui.ActionDelete)
+	}
+
+	var picker ui.Picker
 
 	// Use classic selector if requested, otherwise default to fuzzy search
 	if switchSelector {
-		// Get filter from args or flag
-		filter := switchFilter
-		if len(args) > 0 {
-			filter = args[0]
+		if manager != nil {
+			manager.LoadStatus(ctx, worktrees, 0, func(i int, status *worktree.Worktree) {
+				*worktrees[i] = *status
+			})
+		}
+		selector, err := newSelector(manager, worktrees)
+		if err != nil {
+			return err
 		}
-
-		selector := ui.NewSelector(worktrees)
 
 		if filter != "" {
-			selected, err = selector.SelectWithFilter(filter)
-		} else {
-			selected, err = selector.Select()
+			selected, err := selector.SelectWithFilter(filter)
+			if err != nil {
+				return fmt.Errorf("selection failed: %w", err)
+			}
+			if selected == nil {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+			return switchTo(manager, selected)
 		}
+		picker = selector.WithActions(actions...)
 	} else {
-		// Default to fuzzy search
-		fuzzyFinder := ui.NewFuzzyFinder(worktrees)
-		selected, err = fuzzyFinder.Search()
+		// Default to fuzzy search, starting from the filter as its query
+		fuzzyFinder, err := newFuzzyFinder(manager, worktrees)
+		if err != nil {
+			return err
+		}
+		fuzzyFinder.WithQuery(filter)
+		if manager != nil {
+			fuzzyFinder.WithDetails(diffDetails(ctx, manager)).WithFullDiff(fullDiff(ctx, manager))
+		}
+
+		watchCtx, stopWatching := context.WithCancel(ctx)
+		defer stopWatching()
+		watchConfig(watchCtx, manager, fuzzyFinder)
+		if manager != nil {
+			go loadFinderStatus(watchCtx, manager, fuzzyFinder, worktrees)
+		}
+		picker = fuzzyFinder.WithActions(actions...)
 	}
 
-	if err != nil {
-		return fmt.Errorf("selection failed: %w", err)
+	// Inline actions return to the picker, which is left only by switching or cancelling
+	for {
+		selected, action, err := picker.Pick()
+		if err != nil {
+			return fmt.Errorf("selection failed: %w", err)
+		}
+
+		if selected == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+
+		if action == ui.ActionAccept {
+			return switchTo(manager, selected)
+		}
+
+		message, err := runInlineAction(ctx, manager, selected, action)
+		if err != nil {
+			message = fmt.Sprintf("❌ %v", err)
+		}
+		picker.SetMessage(message)
+
+		if action == ui.ActionDelete {
+			if worktrees, err = manager.List(ctx, worktree.ListOptions{WithStatus: true}); err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			if !switchSelector {
+				worktrees = rankWorktrees(worktrees)
+			}
+			picker.SetWorktrees(worktrees)
+		}
 	}
+}
 
-	if selected == nil {
-		fmt.Println("Operation cancelled.")
-		return nil
+// loadFinderStatus loads the status of worktrees, which the open finder shows,
+// and updates each in the finder as soon as it is known.
+func loadFinderStatus(ctx context.Context, manager *worktree.Manager, finder *ui.FuzzyFinder, worktrees []*worktree.Worktree) {
+	manager.LoadStatus(ctx, worktrees, 0, func(i int, status *worktree.Worktree) {
+		finder.Reconfigure(func(*ui.FuzzyFinder) {
+			*worktrees[i] = *status
+		})
+	})
+}
+
+// runInlineAction performs an action chosen in the switch UI on the selected worktree
+// and returns a message describing the outcome. manager is nil in cross-repo mode.
+func runInlineAction(ctx context.Context, manager *worktree.Manager, selected *worktree.Worktree, action ui.Action) (string, error) {
+	name := ui.DisplayName(selected)
+
+	switch action {
+	case ui.ActionDelete:
+		return removeSelected(ctx, manager, selected)
+	case ui.ActionOpenEditor:
+		cfg, err := loadUIConfig(manager)
+		if err != nil {
+			return "", err
+		}
+		if err := openInEditor(cfg, selected.Path); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("📝 Opened '%s' in the editor", name), nil
+	case ui.ActionOpenTmux:
+		if err := openInTmux(selected.Branch, selected.Path); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("🪟 Opened '%s' in tmux", name), nil
+	case ui.ActionCopyPath:
+		if err := checkServerSafe("copying to the clipboard"); err != nil {
+			return "", err
+		}
+		if err := ui.CopyToClipboard(selected.Path); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("📋 Copied %s", selected.Path), nil
+	case ui.ActionStatus:
+		fmt.Printf("📊 Status of '%s'\n\n", name)
+		status := exec.CommandContext(ctx, "git", "status")
+		status.Dir = selected.Path
+		status.Stdout = os.Stdout
+		status.Stderr = os.Stderr
+		if err := status.Run(); err != nil {
+			return "", fmt.Errorf("failed to get status: %w", err)
+		}
+		waitForEnter()
+		return "", nil
+	}
+
+	return "", fmt.Errorf("unsupported action %q", action)
+}
+
+// removeSelected removes a worktree picked in the switch UI, after confirmation.
+func removeSelected(ctx context.Context, manager *worktree.Manager, selected *worktree.Worktree) (string, error) {
+	if selected.IsMain {
+		return "", fmt.Errorf("cannot remove the main worktree")
+	}
+
+	name := worktreeName(manager, selected)
+	warnNestedChanges(ctx, manager, selected.Path)
+	if err := manager.Remove(ctx, worktree.RemoveOptions{Name: name, Force: true, Confirm: confirmRemoval}); err != nil {
+		if errors.Is(err, giwoerrors.ErrOperationCancelled) {
+			return "Removal cancelled", nil
+		}
+		return "", fmt.Errorf("failed to remove worktree: %w", err)
 	}
+	recordHistory(manager, history.Removed, selected.Path, selected.Branch)
+
+	return fmt.Sprintf("🗑️  Removed '%s'", ui.DisplayName(selected)), nil
+}
+
+// switchTo changes into the selected worktree, or prints its path with --print,
+// and records the switch in the history. manager is nil with --global.
+func switchTo(manager *worktree.Manager, selected *worktree.Worktree) error {
+	if proceed, err := guardDirty(manager, selected); err != nil || !proceed {
+		return err
+	}
+
+	recordDeparture(manager, selected.Path)
+	recordHistory(manager, history.Switched, selected.Path, selected.Branch)
 
 	// If --print flag is set, just print the path
 	if switchPrint {
@@ -90,37 +301,135 @@ func runSwitchCommand(cmd *cobra.Command, args []string) error {
 	}
 
 	if currentDir == selected.Path {
-		fmt.Printf("Already in worktree '%s'\n", selected.Branch)
+		fmt.Printf("Already in worktree '%s'\n", ui.DisplayName(selected))
 		return nil
 	}
 
 	// Try to change directory using a subshell
-	fmt.Printf("🔄 Switching to worktree '%s' at %s\n", selected.Branch, selected.Path)
-
-	// Since we can't change the parent shell's directory from a child process,
-	// we'll provide instructions to the user
-	fmt.Printf("💡 Run: cd %s\n", selected.Path)
+	fmt.Printf("🔄 Switching to worktree '%s' at %s\n", ui.DisplayName(selected), selected.Path)
 
-	// Optionally, try to open a new shell in the directory
-	if err := openShellInDirectory(selected.Path); err != nil {
+	// We can't change the parent shell's directory from a child process, so either
+	// hand the path to the shell integration or open a new shell in the directory
+	if err := changeDirectory(selected.Path); err != nil {
 		// If opening a new shell fails, that's okay - we've already given instructions
 		fmt.Printf("⚠️  Could not open new shell: %v\n", err)
-		fmt.Printf("📝 You can also copy and run: cd %s\n", selected.Path)
+		fmt.Printf("📝 You can also copy and run: cd %s\n", utils.ShellQuote(selected.Path))
 	}
 
 	return nil
 }
 
+// Ways of dealing with uncommitted changes when switching away from them, as
+// given to --on-dirty.
+const (
+	onDirtyLeave = "leave"
+	onDirtyStash = "stash"
+	onDirtyCarry = "carry"
+	onDirtyFail  = "fail"
+)
+
+// guardDirty deals with uncommitted changes in the current worktree before
+// switching to selected, as chosen with --on-dirty or by the user, and reports
+// whether to go on switching. manager is nil with --global, in which case
+// changes can only be carried within the current repository. Messages go to
+// stderr so that they do not mix with the path printed by --print.
+func guardDirty(manager *worktree.Manager, selected *worktree.Worktree) (bool, error) {
+	mode := switchOnDirty
+	switch mode {
+	case "", onDirtyLeave, onDirtyStash, onDirtyCarry, onDirtyFail:
+	default:
+		return false, fmt.Errorf("invalid --on-dirty value '%s': must be leave, stash, carry or fail", mode)
+	}
+	if mode == onDirtyLeave || (mode == "" && !isInteractive()) {
+		return true, nil
+	}
+
+	if manager == nil {
+		var err error
+		if manager, err = newManager(); err != nil {
+			// Outside a repository there is nothing to leave behind
+			return true, nil
+		}
+	}
+	ctx := context.Background()
+	current := manager.RepoRoot()
+	if current == selected.Path {
+		return true, nil
+	}
+	dirty, err := manager.HasTrackedChanges(ctx, current)
+	if err != nil || !dirty {
+		return true, err
+	}
+
+	branch, err := manager.GetCurrentBranch(ctx)
+	if err != nil {
+		branch = filepath.Base(current)
+	}
+	name := ui.DisplayName(selected)
+	if mode == "" {
+		leave := fmt.Sprintf("Leave them in '%s'", branch)
+		stash := "Stash them"
+		carry := fmt.Sprintf("Carry them to '%s'", name)
+		cfg, err := loadUIConfig(manager)
+		if err != nil {
+			return false, err
+		}
+		chooser, err := newChooser(cfg, fmt.Sprintf("'%s' has uncommitted changes", branch), []string{leave, stash, carry})
+		if err != nil {
+			return false, err
+		}
+		choice, ok, err := chooser.Choose()
+		if err != nil {
+			return false, fmt.Errorf("selection failed: %w", err)
+		}
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Operation cancelled.")
+			return false, nil
+		}
+		mode = map[string]string{leave: onDirtyLeave, stash: onDirtyStash, carry: onDirtyCarry}[choice]
+	}
+
+	message := worktree.StashMessage(branch, time.Now())
+	switch mode {
+	case onDirtyFail:
+		return false, fmt.Errorf("%w: %s\n💡 Commit or stash them first, or use --on-dirty leave, stash or carry",
+			giwoerrors.ErrWorktreeDirty, current)
+	case onDirtyStash:
+		if err := manager.Stash(ctx, current, message); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(os.Stderr, "📦 Stashed the changes in '%s' as '%s'\n", branch, message)
+	case onDirtyCarry:
+		if !sameRepository(manager, selected.Path) {
+			return false, fmt.Errorf("cannot carry changes to '%s', it belongs to another repository", name)
+		}
+		if kept, err := manager.CarryChanges(ctx, current, selected.Path, message); err != nil {
+			if !kept {
+				return false, err
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n💡 Resolve the conflicts in '%s', then run 'git stash drop'\n", err, name)
+			return true, nil
+		}
+		fmt.Fprintf(os.Stderr, "🚚 Carried the changes in '%s' to '%s'\n", branch, name)
+	}
+	return true, nil
+}
+
+// sameRepository reports whether the worktree at path belongs to the
+// repository of manager.
+func sameRepository(manager *worktree.Manager, path string) bool {
+	other, err := worktree.NewAt(path)
+	return err == nil && other.MainRoot() == manager.MainRoot()
+}
+
 // openShellInDirectory attempts to open a new shell in the specified directory.
 func openShellInDirectory(path string) error {
-	// Try to determine the user's shell
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "/bin/sh"
+	if err := checkServerSafe("opening a shell"); err != nil {
+		return err
 	}
 
 	// Try to open a new shell session
-	cmd := exec.Command(shell)
+	cmd := exec.Command(utils.UserShell().Path)
 	cmd.Dir = path
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -131,7 +440,10 @@ func openShellInDirectory(path string) error {
 }
 
 func init() {
-	switchCmd.Flags().StringVarP(&switchFilter, "filter", "f", "", "Filter worktrees by branch name (only used with --selector)")
+	switchCmd.Flags().StringVarP(&switchFilter, "filter", "f", "", "Filter worktrees by branch name or path (same as the positional argument)")
 	switchCmd.Flags().BoolVarP(&switchPrint, "print", "p", false, "Print the selected worktree path instead of switching")
+	switchCmd.Flags().BoolVar(&switchGlobal, "global", false, "Show worktrees of all known repositories")
+	switchCmd.Flags().StringVar(&switchTag, "tag", "", "Only offer worktrees carrying this label")
+	switchCmd.Flags().StringVar(&switchOnDirty, "on-dirty", "", "What to do with uncommitted changes in the current worktree: leave, stash, carry or fail (default: ask)")
 	switchCmd.Flags().BoolVar(&switchSelector, "selector", false, "Use classic numbered selector instead of fuzzy search")
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagRemove []string
+	tagClear  bool
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <filter> [+label...]",
+	Short: "Attach labels to a worktree",
+	Long: `Attach labels to a worktree to keep track of what it is for, e.g. which
+worktrees are up for review and which are in active development:
+
+  giwo tag feat-login +review +urgent
+  giwo tag feat-login --remove urgent
+  giwo tag feat-login              # show its labels
+
+Labels are shown by 'giwo list' and the switch UIs, where they can be searched,
+and 'giwo switch --tag review' and 'giwo list --tag review' only show the
+worktrees carrying a label. They are kept in the giwo state directory of the
+repository and dropped when the worktree is removed.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var add []string
+		for _, arg := range args[1:] {
+			label, ok := strings.CutPrefix(arg, "+")
+			if !ok {
+				return fmt.Errorf("labels to attach start with +, e.g. +%s; use --remove to detach one", arg)
+			}
+			add = append(add, label)
+		}
+
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		selected, err := resolveWorktree(ctx, manager, args[0])
+		if err != nil {
+			return err
+		}
+		if selected == nil {
+			fmt.Println("Operation cancelled.")
+			return nil
+		}
+
+		remove := tagRemove
+		if tagClear {
+			remove = selected.Labels
+		}
+		labels := selected.Labels
+		if len(add) > 0 || len(remove) > 0 {
+			if labels, err = manager.Label(selected.Path, add, remove); err != nil {
+				return err
+			}
+		}
+
+		name := worktreeName(manager, selected)
+		if len(labels) == 0 {
+			fmt.Printf("🏷️  Worktree '%s' has no labels\n", name)
+			return nil
+		}
+		fmt.Printf("🏷️  Worktree '%s': %s\n", name, strings.Join(labels, ", "))
+		return nil
+	},
+}
+
+func init() {
+	tagCmd.Flags().StringSliceVarP(&tagRemove, "remove", "r", nil, "Labels to detach")
+	tagCmd.Flags().BoolVar(&tagClear, "clear", false, "Detach all labels")
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/knwoop/giwo/internal/config"
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	taskBase     string
+	taskOwner    string
+	taskTTL      string
+	taskLand     bool
+	taskExpired  bool
+	taskListJSON bool
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Run automated coding tasks in isolated worktrees",
+	Long: `Run automated coding tasks, such as those of AI agents, in isolated worktrees.
+
+'giwo task start' creates a worktree on a generated branch, records the task's
+description, owner and expiry with it, allocates a port offset and prints a JSON
+handle for the agent. 'giwo task finish' lands or archives it.
+
+The 'tasks' section of the config file sets the branch prefix (default task/),
+how long tasks may run (default 24h) and a template override isolating task
+worktrees, e.g. one skipping slow setup steps:
+
+  tasks:
+    branch_prefix: agent/
+    ttl: 8h
+    template: sandbox`,
+}
+
+var taskStartCmd = &cobra.Command{
+	Use:   "start <description>",
+	Short: "Create a worktree for a task and print its JSON handle",
+	Long: `Create a worktree for a task on a branch named after its description, such
+as task/fix-the-parser-3fa2c1, freshly started from the base on origin.
+
+The JSON handle printed on standard output holds the task's id, description,
+owner, expiry, worktree name, branch, path and port offset (see 'giwo env').
+Progress is shown on standard error.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+		ttl, err := taskTTLFor(cfg)
+		if err != nil {
+			return err
+		}
+		if cfg.Tasks.Template != "" {
+			manager.SetTemplateFunc(templateFunc(cfg, cfg.Tasks.Template))
+		}
+
+		base := taskBase
+		if base == "" {
+			if base, err = manager.GetCurrentBranch(ctx); err != nil {
+				return fmt.Errorf("failed to get current branch: %w", err)
+			}
+		}
+		owner := taskOwner
+		if owner == "" {
+			owner = os.Getenv("USER")
+		}
+
+		task, err := manager.StartTask(ctx, worktree.TaskOptions{
+			Description:  args[0],
+			Owner:        owner,
+			Base:         base,
+			BranchPrefix: cfg.Tasks.BranchPrefix,
+			TTL:          ttl,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(task)
+	},
+}
+
+var taskFinishCmd = &cobra.Command{
+	Use:   "finish [id]",
+	Short: "Land or archive a task's worktree",
+	Long: `Finish a task, given by its id or worktree name, and remove its worktree.
+
+By default the worktree is archived with all its files, uncommitted ones
+included, and 'giwo restore <name>' brings it back. With --land, the task's
+work is published instead: its worktree must have no uncommitted changes, and
+the branch is pushed to origin with its upstream set. The branch is kept either way.
+
+With --expired, all tasks that ran past their expiry are finished.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if taskExpired == (len(args) == 1) {
+			return fmt.Errorf("pass either a task id or --expired")
+		}
+
+		ctx := cmd.Context()
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		defer reportProgress(manager, "auto")()
+
+		var tasks []worktree.Task
+		if taskExpired {
+			all, err := manager.Tasks(ctx)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			for _, task := range all {
+				if task.Expired(now) {
+					tasks = append(tasks, task)
+				}
+			}
+			if len(tasks) == 0 {
+				fmt.Println("✨ No expired tasks")
+				return nil
+			}
+		} else {
+			task, err := manager.Task(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, *task)
+		}
+
+		for _, task := range tasks {
+			if err := manager.FinishTask(ctx, &task, taskLand); err != nil {
+				if errors.Is(err, giwoerrors.ErrWorktreeDirty) {
+					return fmt.Errorf("cannot land task %s: %w\n💡 Commit its work first, or finish it without --land to archive it", task.ID, err)
+				}
+				return err
+			}
+			if taskLand {
+				fmt.Printf("🚀 Landed task %s: pushed '%s' to origin and removed its worktree\n", task.ID, task.Branch)
+			} else {
+				fmt.Printf("📦 Archived task %s; run 'giwo restore %s' to bring it back\n", task.ID, utils.ShellQuote(task.Name))
+			}
+		}
+		return nil
+	},
+}
+
+var taskListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List running tasks",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		tasks, err := manager.Tasks(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if taskListJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if tasks == nil {
+				tasks = []worktree.Task{}
+			}
+			return encoder.Encode(tasks)
+		}
+
+		if len(tasks) == 0 {
+			fmt.Println("No tasks found. Use 'giwo task start <description>' to start one.")
+			return nil
+		}
+
+		now := time.Now()
+		w := ui.NewTable(os.Stdout)
+		defer w.Flush()
+		fmt.Fprintln(w, "ID\tNAME\tOWNER\tEXPIRES\tDESCRIPTION")
+		for _, task := range tasks {
+			expires := task.ExpiresAt.Format("2006-01-02 15:04")
+			if task.Expired(now) {
+				expires = "⏰ expired"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", task.ID, task.Name, task.Owner, expires, task.Description)
+		}
+		return nil
+	},
+}
+
+// taskTTLFor returns how long a new task may run: --ttl, tasks.ttl or the default.
+func taskTTLFor(cfg *config.Config) (time.Duration, error) {
+	ttl := taskTTL
+	if ttl == "" {
+		ttl = cfg.Tasks.TTL
+	}
+	if ttl == "" {
+		return worktree.DefaultTaskTTL, nil
+	}
+	d, err := config.ParseAge(ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --ttl: %w", err)
+	}
+	if d == 0 {
+		return 0, fmt.Errorf("invalid --ttl: must be positive")
+	}
+	return d, nil
+}
+
+func init() {
+	taskStartCmd.Flags().StringVar(&taskBase, "base", "", "Branch to start from (default: current branch)")
+	taskStartCmd.Flags().StringVar(&taskOwner, "owner", "", "Person or agent running the task (default: $USER)")
+	taskStartCmd.Flags().StringVar(&taskTTL, "ttl", "", "How long the task may run, e.g. 8h or 2d (default: tasks.ttl or 24h)")
+	taskFinishCmd.Flags().BoolVar(&taskLand, "land", false, "Push the branch to origin instead of archiving the worktree")
+	taskFinishCmd.Flags().BoolVar(&taskExpired, "expired", false, "Finish all tasks past their expiry")
+	taskListCmd.Flags().BoolVar(&taskListJSON, "json", false, "Output in JSON format")
+
+	taskCmd.AddCommand(taskStartCmd)
+	taskCmd.AddCommand(taskFinishCmd)
+	taskCmd.AddCommand(taskListCmd)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var templateExplainName string
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Inspect how new worktrees are set up",
+	Long: `New worktrees are set up with a template: the files listed in templates are
+copied from the repository root, the paths in links are symlinked and the
+commands in setup are run. Entries of overrides, keyed by a branch pattern like
+"release/*", add to or skip parts of it for matching branches.
+
+Templates are composed in order of increasing precedence:
+
+  1. the base: templates, links and setup
+  2. matching overrides, least specific pattern first (fewest literal characters)
+  3. the override selected with 'giwo create --template <key>'
+
+An override with extends is applied right after the override it names.`,
+}
+
+var templateExplainCmd = &cobra.Command{
+	Use:   "explain <branch>",
+	Short: "Show how the template for a branch is composed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		cfg, err := loadConfig(manager)
+		if err != nil {
+			return err
+		}
+
+		t, err := cfg.ResolveTemplate(args[0], templateExplainName, worktree.ConfigFiles)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("🧩 Template for '%s':\n", args[0])
+		for i, layer := range t.Layers {
+			fmt.Printf("\n%d. %s (%s)\n", i+1, layer.Source, layer.Reason)
+			if len(layer.Changes) == 0 {
+				fmt.Println("   no changes")
+			}
+			for _, change := range layer.Changes {
+				fmt.Printf("   %s\n", change)
+			}
+		}
+
+		fmt.Println("\n📋 Result:")
+		fmt.Printf("   templates: %s\n", listOrNone(t.Templates))
+		fmt.Printf("   links:     %s\n", listOrNone(t.Links))
+		if len(t.Setup) == 0 {
+			fmt.Println("   setup:     (none)")
+		}
+		for i, step := range t.Setup {
+			fmt.Printf("   setup %d:   %s: %s\n", i+1, step.Name, step.Run)
+		}
+		return nil
+	},
+}
+
+// templateFunc returns the function resolving the template of new worktrees
+// from cfg, applying the override name if it is set.
+func templateFunc(cfg *config.Config, name string) worktree.TemplateFunc {
+	return func(branch string) (worktree.Template, error) {
+		t, err := cfg.ResolveTemplate(branch, name, worktree.ConfigFiles)
+		if err != nil {
+			return worktree.Template{}, err
+		}
+		steps := make([]worktree.SetupStep, len(t.Setup))
+		for i, step := range t.Setup {
+			steps[i] = worktree.SetupStep{Name: step.Name, Run: step.Run}
+		}
+		return worktree.Template{Files: t.Templates, Links: t.Links, Setup: steps}, nil
+	}
+}
+
+// listOrNone joins items with commas, or returns "(none)" if there are none.
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
+
+func init() {
+	templateExplainCmd.Flags().StringVar(&templateExplainName, "template", "", "Override to apply as with 'giwo create --template'")
+	templateCmd.AddCommand(templateExplainCmd)
+}
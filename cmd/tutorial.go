@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// onboardedMarker is the file in the user state directory recording that the first-run hint was shown.
+const onboardedMarker = "onboarded"
+
+var tutorialKeep bool
+
+// tutorialStep is one stage of the tutorial: an explanation followed by giwo commands to run.
+type tutorialStep struct {
+	Title       string
+	Explanation string
+	Commands    [][]string
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		Title: "Create a worktree",
+		Explanation: `A worktree is a second checkout of the same repository, on its own branch,
+in its own directory. 'giwo create' fetches, creates the branch and places the
+worktree under .worktree/ (or the configured worktree_dir).`,
+		Commands: [][]string{{"create", "feature-demo", "--base", "main"}},
+	},
+	{
+		Title: "List worktrees",
+		Explanation: `'giwo list' shows every worktree with its status. Add --verbose for
+ahead/behind counts and the last commit.`,
+		Commands: [][]string{{"list"}, {"list", "--verbose"}},
+	},
+	{
+		Title: "Switch to a worktree",
+		Explanation: `'giwo switch' opens a fuzzy finder over your worktrees and changes into the
+selected one. With the shell integration loaded your shell changes directory;
+here we only print the path the finder would switch to.`,
+		Commands: [][]string{{"switch", "--print", "--non-interactive", "feature-demo"}},
+	},
+	{
+		Title: "Remove and prune",
+		Explanation: `When you are done, 'giwo remove' deletes the worktree and its branch, and
+'giwo prune' cleans up worktrees whose directories were deleted by hand.`,
+		Commands: [][]string{{"remove", "--force", "feature-demo"}, {"prune"}},
+	},
+}
+
+var tutorialCmd = &cobra.Command{
+	Use:   "tutorial",
+	Short: "Walk through giwo in a throwaway demo repository",
+	Long: `Walk through creating, listing, switching to, and removing a worktree in a
+demo repository created in a temporary directory. Your own repositories and
+configuration are not touched. The shell integration is checked first, since
+'giwo switch' needs it to change directory.
+
+The demo repository is deleted afterwards unless --keep is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkServerSafe("the tutorial"); err != nil {
+			return err
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate giwo: %w", err)
+		}
+
+		root, err := os.MkdirTemp("", "giwo-tutorial-")
+		if err != nil {
+			return fmt.Errorf("failed to create demo directory: %w", err)
+		}
+		if tutorialKeep {
+			defer fmt.Printf("\n📁 Demo repository kept at %s\n", filepath.Join(root, "demo"))
+		} else {
+			defer os.RemoveAll(root)
+		}
+
+		fmt.Println("🎓 giwo tutorial")
+		fmt.Println()
+
+		check := checkShellIntegration()
+		if check.OK {
+			fmt.Println("✅ Shell integration is loaded, so 'giwo switch' can change directory.")
+		} else {
+			fmt.Printf("⚠️  Shell integration: %s\n", check.Detail)
+			fmt.Printf("   💡 %s\n", check.Fix)
+		}
+
+		repo, err := createDemoRepository(root)
+		if err != nil {
+			return fmt.Errorf("failed to create demo repository: %w", err)
+		}
+		fmt.Printf("📁 Created a demo repository at %s\n", repo)
+
+		env := tutorialEnv(root)
+		for i, step := range tutorialSteps {
+			waitForEnter()
+			fmt.Printf("\n── Step %d/%d: %s ──\n%s\n", i+1, len(tutorialSteps), step.Title, step.Explanation)
+
+			for _, args := range step.Commands {
+				fmt.Printf("\n$ giwo %s\n", strings.Join(args, " "))
+				run := exec.Command(exe, args...)
+				run.Dir = repo
+				run.Env = env
+				run.Stdout = os.Stdout
+				run.Stderr = os.Stderr
+				if err := run.Run(); err != nil {
+					return fmt.Errorf("tutorial step %q failed: %w", step.Title, err)
+				}
+			}
+		}
+
+		fmt.Println("\n🎉 That's it! Run 'giwo doctor' to check your setup and 'giwo --help' for all commands.")
+		return nil
+	},
+}
+
+// createDemoRepository creates a demo repository with an origin in root and returns its path.
+func createDemoRepository(root string) (string, error) {
+	origin := filepath.Join(root, "origin.git")
+	repo := filepath.Join(root, "demo")
+
+	steps := [][]string{
+		{"init", "--quiet", "--bare", "--initial-branch=main", origin},
+		{"clone", "--quiet", origin, repo},
+	}
+	for _, args := range steps {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			return "", fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# giwo demo\n"), 0o644); err != nil {
+		return "", err
+	}
+
+	steps = [][]string{
+		{"checkout", "--quiet", "-b", "main"},
+		{"add", "README.md"},
+		{"-c", "user.name=giwo", "-c", "user.email=tutorial@giwo.invalid", "commit", "--quiet", "-m", "Initial commit"},
+		{"push", "--quiet", "-u", "origin", "main"},
+	}
+	for _, args := range steps {
+		git := exec.Command("git", args...)
+		git.Dir = repo
+		if output, err := git.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	return repo, nil
+}
+
+// tutorialEnv isolates the demo commands from the user's configuration and state.
+func tutorialEnv(root string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		switch name {
+		case "XDG_CONFIG_HOME", "XDG_STATE_HOME", "GIWO_CD_FILE", "GIWO_PROFILE":
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env,
+		"XDG_CONFIG_HOME="+filepath.Join(root, "config"),
+		"XDG_STATE_HOME="+filepath.Join(root, "state"),
+	)
+}
+
+// waitForEnter pauses the tutorial until the user presses Enter, when running interactively.
+func waitForEnter() {
+	if !isInteractive() {
+		return
+	}
+	fmt.Print("\n⏎  Press Enter to continue...")
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// showFirstRunHint points new users at the tutorial, once per user.
+func showFirstRunHint(cmd *cobra.Command) {
+	switch cmd.Name() {
+	case "tutorial", "completion", "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return
+	}
+	if !isInteractive() {
+		return
+	}
+
+	dir := config.StateDir()
+	if dir == "" {
+		return
+	}
+	marker := filepath.Join(dir, onboardedMarker)
+	if _, err := os.Stat(marker); err == nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	if err := os.WriteFile(marker, nil, 0o644); err != nil {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "👋 New to giwo? Run 'giwo tutorial' for a short walkthrough in a demo repository.")
+}
+
+func init() {
+	tutorialCmd.Flags().BoolVar(&tutorialKeep, "keep", false, "Keep the demo repository afterwards")
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/upgrade"
+	"github.com/knwoop/giwo/pkg/github"
+	"github.com/spf13/cobra"
+)
+
+// The repository giwo is released from.
+const (
+	releaseOwner = "knwoop"
+	releaseRepo  = "giwo"
+)
+
+// updateCheckTimeout bounds the passive check for a new release, which runs
+// after the command the user asked for.
+const updateCheckTimeout = 2 * time.Second
+
+var (
+	upgradeChannel  string
+	upgradeCheck    bool
+	upgradeForce    bool
+	upgradeInsecure bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade giwo to the latest release",
+	Long: `Download the latest release of giwo from GitHub and replace the running
+binary with it.
+
+The archive for this platform is checked against the SHA-256 sums published with
+the release, and the sums against their signature with the release signing key
+built into giwo; nothing is installed if either does not match. Builds without
+the key, such as those made with 'go install', cannot verify the signature and
+refuse to upgrade: upgrade them the way they were installed, or pass --insecure
+to trust the sums alone, which come from the same place as the download.
+
+--channel prerelease includes prereleases; the default is the 'updates.channel'
+setting, or stable. Use --check to only see whether a newer release exists, and
+--force to reinstall the latest release even if it is not newer.
+
+Interactive commands also look for a new release at most once a day and mention
+it. Set 'updates.check: false' in the config file to turn that off.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if offline {
+			return fmt.Errorf("giwo cannot be upgraded offline")
+		}
+		cfg, err := config.Load("", profileName, "")
+		if err != nil {
+			return err
+		}
+		channel := upgradeChannel
+		if channel == "" {
+			channel = cfg.Updates.ResolvedChannel()
+		}
+		if channel != config.ChannelStable && channel != config.ChannelPrerelease {
+			return fmt.Errorf("invalid --channel %q: must be stable or prerelease", channel)
+		}
+
+		ctx := cmd.Context()
+		client := github.New()
+		release, err := latestRelease(ctx, client, channel)
+		if err != nil {
+			return err
+		}
+
+		current := giwoVersion()
+		newer := !upgrade.IsRelease(current) || upgrade.Compare(release.TagName, current) > 0
+		if upgradeCheck {
+			if newer {
+				fmt.Printf("🆕 giwo %s is available (you have %s): %s\n", release.TagName, current, release.HTMLURL)
+				fmt.Println("💡 Run 'giwo upgrade' to install it")
+			} else {
+				fmt.Printf("✅ giwo %s is the latest %s release\n", current, channel)
+			}
+			return nil
+		}
+		if !newer && !upgradeForce {
+			fmt.Printf("✅ giwo %s is the latest %s release\n", current, channel)
+			return nil
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the running executable: %w", err)
+		}
+		if exe, err = filepath.EvalSymlinks(exe); err != nil {
+			return fmt.Errorf("failed to find the running executable: %w", err)
+		}
+
+		binary, err := downloadRelease(ctx, client, release)
+		if err != nil {
+			return err
+		}
+		if err := upgrade.Replace(exe, binary); err != nil {
+			return fmt.Errorf("%w\n💡 Install it by hand from %s", err, release.HTMLURL)
+		}
+		fmt.Printf("✅ Upgraded giwo from %s to %s at %s\n", current, release.TagName, exe)
+		return nil
+	},
+}
+
+// latestRelease returns the latest release of giwo on channel and records it
+// for the notice of new releases.
+func latestRelease(ctx context.Context, client *github.Client, channel string) (*github.Release, error) {
+	releases, err := client.Releases(ctx, releaseOwner, releaseRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up releases: %w", err)
+	}
+	release := github.LatestRelease(releases, channel == config.ChannelPrerelease)
+	if release == nil {
+		return nil, fmt.Errorf("no %s release of giwo found", channel)
+	}
+
+	if state, err := upgrade.LoadCheck(config.StateDir()); err == nil {
+		state.CheckedAt, state.Latest, state.Channel = time.Now(), release.TagName, channel
+		_ = state.Save(config.StateDir())
+	}
+	return release, nil
+}
+
+// downloadRelease downloads the archive of release for this platform, verifies
+// it and returns the giwo binary in it. Without a release signing key, only
+// --insecure lets it go ahead.
+func downloadRelease(ctx context.Context, client *github.Client, release *github.Release) ([]byte, error) {
+	if releaseKey == "" && !upgradeInsecure {
+		return nil, fmt.Errorf("this build of giwo has no release signing key to verify the download with\n💡 Upgrade it the way it was installed, e.g. 'go install github.com/knwoop/giwo@latest', or pass --insecure to trust the checksums alone")
+	}
+	name := upgrade.ArchiveName(release.TagName, runtime.GOOS, runtime.GOARCH)
+	archive := release.Asset(name)
+	if archive == nil {
+		return nil, fmt.Errorf("release %s has no build for %s/%s (%s)", release.TagName, runtime.GOOS, runtime.GOARCH, name)
+	}
+	checksums := release.Asset(upgrade.ChecksumsAsset)
+	if checksums == nil {
+		return nil, fmt.Errorf("release %s has no %s to verify the download with", release.TagName, upgrade.ChecksumsAsset)
+	}
+
+	fmt.Printf("⬇️  Downloading %s...\n", name)
+	sums, err := client.Download(ctx, checksums)
+	if err != nil {
+		return nil, err
+	}
+	if signature := release.Asset(upgrade.SignatureAsset); releaseKey != "" {
+		if signature == nil {
+			return nil, fmt.Errorf("release %s has no %s", release.TagName, upgrade.SignatureAsset)
+		}
+		sig, err := client.Download(ctx, signature)
+		if err != nil {
+			return nil, err
+		}
+		if err := upgrade.VerifySignature(sums, sig, releaseKey); err != nil {
+			return nil, err
+		}
+	}
+	data, err := client.Download(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+	if err := upgrade.VerifyChecksum(sums, name, data); err != nil {
+		return nil, err
+	}
+	if releaseKey != "" {
+		fmt.Println("🔏 Verified the checksum and its signature")
+	} else {
+		fmt.Println("⚠️  Verified the checksum only; its signature cannot be checked without a release signing key")
+	}
+
+	binary := "giwo"
+	if runtime.GOOS == "windows" {
+		binary = "giwo.exe"
+	}
+	return upgrade.ExtractBinary(name, data, binary)
+}
+
+// noticeNewRelease mentions a newer release of giwo on standard error, looking
+// releases up first if the last check is a day old. It is quiet for scripts,
+// local builds, offline and with 'updates.check: false', and gives up quickly
+// when GitHub is slow, since the user is waiting.
+func noticeNewRelease(cmd *cobra.Command) {
+	current := giwoVersion()
+	if cmd == upgradeCmd || offline || !isInteractive() || !upgrade.IsRelease(current) {
+		return
+	}
+	cfg, err := config.Load("", profileName, "")
+	if err != nil || !cfg.Updates.CheckEnabled() {
+		return
+	}
+	stateDir := config.StateDir()
+	state, err := upgrade.LoadCheck(stateDir)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	channel := cfg.Updates.ResolvedChannel()
+	if state.Due(channel, now) {
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		state.CheckedAt, state.Channel = now, channel
+		if releases, err := github.New().Releases(ctx, releaseOwner, releaseRepo); err == nil {
+			if release := github.LatestRelease(releases, channel == config.ChannelPrerelease); release != nil {
+				state.Latest = release.TagName
+			}
+		}
+	}
+
+	if latest := state.Notice(current, now); latest != "" {
+		fmt.Fprintf(os.Stderr, "\n🆕 giwo %s is available (you have %s); run 'giwo upgrade' to install it\n", latest, current)
+		state.NotifiedAt = now
+	}
+	_ = state.Save(stateDir)
+}
+
+func init() {
+	upgradeCmd.Flags().StringVar(&upgradeChannel, "channel", "", "Release channel: stable or prerelease (default: updates.channel, or stable)")
+	upgradeCmd.Flags().BoolVar(&upgradeCheck, "check", false, "Only report whether a newer release is available")
+	upgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "Reinstall the latest release even if it is not newer")
+	upgradeCmd.Flags().BoolVar(&upgradeInsecure, "insecure", false, "Install without verifying the signature of the checksums if this build has no release signing key")
+}
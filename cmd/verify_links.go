@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var verifyLinksRepair bool
+
+var verifyLinksCmd = &cobra.Command{
+	Use:   "verify-links [filter]",
+	Short: "Check the symlinks giwo created in worktrees",
+	Long: `Check the symlinks created for the 'links' setting, which share assets such as
+node_modules between worktrees. A link is broken if it was removed, if its target
+no longer exists, or if it points somewhere other than the same path in the
+repository root (for example after the repository was moved).
+
+Without a filter, all worktrees are checked. Use --repair to re-point broken links.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktrees(false),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+
+		ctx := cmd.Context()
+		var worktrees []*worktree.Worktree
+		if len(args) > 0 {
+			selected, err := resolveWorktree(ctx, manager, args[0])
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				fmt.Println("Operation cancelled.")
+				return nil
+			}
+			worktrees = []*worktree.Worktree{selected}
+		} else {
+			worktrees, err = manager.List(ctx, worktree.ListOptions{WithStatus: true})
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+		}
+
+		checked, broken := 0, 0
+		for _, wt := range worktrees {
+			statuses, err := manager.VerifyLinks(wt)
+			if err != nil {
+				return fmt.Errorf("failed to verify links: %w", err)
+			}
+
+			for _, status := range statuses {
+				checked++
+				if status.State == worktree.LinkOK {
+					fmt.Printf("✅ %s -> %s\n", status.Path, status.Target)
+					continue
+				}
+
+				if verifyLinksRepair {
+					if err := manager.RepairLink(wt, status); err != nil {
+						broken++
+						fmt.Printf("❌ %s: %v\n", status.Path, err)
+						continue
+					}
+					fmt.Printf("🔧 %s: repaired (%s) -> %s\n", status.Path, status.State, status.Expected)
+					continue
+				}
+
+				broken++
+				fmt.Printf("❌ %s\n", describeBrokenLink(status))
+			}
+		}
+
+		if checked == 0 {
+			fmt.Println("No links to verify. Configure shared paths with the 'links' setting.")
+			return nil
+		}
+		if broken > 0 {
+			if !verifyLinksRepair {
+				fmt.Println("💡 Run 'giwo verify-links --repair' to re-point broken links")
+			}
+			return fmt.Errorf("%d broken link(s)", broken)
+		}
+		return nil
+	},
+}
+
+// describeBrokenLink explains what is wrong with a link.
+func describeBrokenLink(status worktree.LinkStatus) string {
+	switch status.State {
+	case worktree.LinkMissing:
+		return fmt.Sprintf("%s: missing (expected -> %s)", status.Path, status.Expected)
+	case worktree.LinkDangling:
+		return fmt.Sprintf("%s: target %s does not exist", status.Path, status.Target)
+	default:
+		return fmt.Sprintf("%s: points to %s instead of %s", status.Path, status.Target, status.Expected)
+	}
+}
+
+// countBrokenLinks records the number of broken giwo-created links on each worktree.
+func countBrokenLinks(manager *worktree.Manager, worktrees []*worktree.Worktree) error {
+	for _, wt := range worktrees {
+		statuses, err := manager.VerifyLinks(wt)
+		if err != nil {
+			return fmt.Errorf("failed to verify links: %w", err)
+		}
+		for _, status := range statuses {
+			if status.State != worktree.LinkOK {
+				wt.BrokenLinks++
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	verifyLinksCmd.Flags().BoolVar(&verifyLinksRepair, "repair", false, "Re-point broken links at their expected source")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import rtdebug "runtime/debug"
+
+// version is the version of giwo, set when building a release with
+// -ldflags "-X github.com/knwoop/giwo/cmd.version=v1.2.0".
+var version string
+
+// releaseKey is the base64-encoded Ed25519 public key the checksums of
+// releases are signed with, set at build time like version from RELEASE_KEY
+// in the Makefile. Without it, 'giwo upgrade' cannot tell a genuine release
+// from a tampered one and refuses to install unless --insecure is given.
+var releaseKey string
+
+// giwoVersion returns the version of the running giwo: the one set at build
+// time, that of the module for 'go install', or "dev" for a local build.
+func giwoVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := rtdebug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+func init() {
+	rootCmd.Version = giwoVersion()
+}
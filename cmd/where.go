@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/ui"
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+var whereCmd = &cobra.Command{
+	Use:   "where <filter>",
+	Short: "Print the path of the worktree matching a filter",
+	Long: `Print the path of the single worktree matching the filter, and nothing else,
+for Makefiles and shell functions:
+
+  cd "$(giwo where api)"
+  make -C "$(giwo where release)" dist
+
+The filter is matched as with 'giwo switch': a branch name or path picks that
+worktree, and anything else must be contained in the branch of exactly one. It
+never prompts. The command exits with 1 if nothing matches, and with 2 if the
+filter is ambiguous, listing the candidates on standard error.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize manager: %w", err)
+		}
+		worktrees, err := manager.ListFast(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		selected, err := matchWorktree(worktrees, args[0])
+		if errors.Is(err, giwoerrors.ErrWorktreeAmbiguous) {
+			for _, wt := range worktree.Candidates(worktrees, args[0]) {
+				fmt.Fprintf(os.Stderr, "  %s\t%s\n", ui.DisplayName(wt), wt.Path)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(selected.Path)
+		return nil
+	},
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/config"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// noTemplate is the template choice that applies only the overrides matching the branch.
+const noTemplate = "(none)"
+
+// runCreateWizard asks for what 'giwo create' needs when run without arguments:
+// the base, the branch name and an extra template. It sets the matching flags
+// and returns the branch name once the user confirmed the worktree path, or ""
+// if the user cancelled.
+func runCreateWizard(ctx context.Context, manager *worktree.Manager, cfg *config.Config) (string, error) {
+	if createBase == "" && createFrom == "" && createFromTag == "" {
+		ok, err := chooseBase(ctx, manager, cfg)
+		if err != nil || !ok {
+			return "", err
+		}
+	}
+
+	branchName, err := askBranchName()
+	if err != nil || branchName == "" {
+		return "", err
+	}
+
+	if createTemplate == "" && len(cfg.Overrides) > 0 {
+		items := []string{noTemplate}
+		for name := range cfg.Overrides {
+			items = append(items, name)
+		}
+		sort.Strings(items[1:])
+
+		chooser, err := newChooser(cfg, "Extra template (matching overrides apply anyway)", items)
+		if err != nil {
+			return "", err
+		}
+		choice, ok, err := chooser.Choose()
+		if err != nil || !ok {
+			return "", err
+		}
+		if choice != noTemplate {
+			createTemplate = choice
+		}
+	}
+
+	base := createBase
+	switch {
+	case createFromTag != "":
+		base = "tag " + createFromTag
+	case createFrom != "":
+		base = createFrom
+	}
+	template := createTemplate
+	if template == "" {
+		template = noTemplate
+	}
+	fmt.Printf("📋 New worktree\n")
+	fmt.Printf("   Branch:   %s\n", branchName)
+	fmt.Printf("   Base:     %s\n", base)
+	fmt.Printf("   Template: %s\n", template)
+	fmt.Printf("   Path:     %s\n", manager.WorktreePath(branchName))
+	if !confirm("Create it?", true) {
+		return "", nil
+	}
+	return branchName, nil
+}
+
+// chooseBase lets the user pick the branch the new worktree starts from, the
+// current branch first. Local branches are used as they are, after checking
+// them against their upstream; branches of origin are fetched like --base.
+func chooseBase(ctx context.Context, manager *worktree.Manager, cfg *config.Config) (bool, error) {
+	branches, err := manager.Branches(ctx)
+	if err != nil {
+		return false, err
+	}
+	if current, err := manager.GetCurrentBranch(ctx); err == nil {
+		if i := slices.Index(branches, current); i > 0 {
+			branches = slices.Insert(slices.Delete(branches, i, i+1), 0, current)
+		}
+	}
+
+	chooser, err := newChooser(cfg, "Base branch for the new worktree", branches)
+	if err != nil {
+		return false, err
+	}
+	choice, ok, err := chooser.Choose()
+	if err != nil || !ok {
+		return false, err
+	}
+	if name, found := strings.CutPrefix(choice, "origin/"); found {
+		createBase = name
+	} else {
+		createFrom = choice
+	}
+	return true, nil
+}
+
+// askBranchName prompts for the name of the new branch until it is valid,
+// offering a cleaned-up name for invalid ones such as issue titles. It returns
+// "" if standard input is closed.
+func askBranchName() (string, error) {
+	for {
+		name, err := readLine("🌿 New branch name: ")
+		if err != nil {
+			return "", nil
+		}
+		if name == "" {
+			continue
+		}
+		if err := utils.ValidateBranchName(name); err == nil {
+			return name, nil
+		} else if suggestion := suggestBranchName(name); suggestion != "" {
+			if confirm(fmt.Sprintf("⚠️  %v. Use '%s' instead?", err, suggestion), true) {
+				return suggestion, nil
+			}
+		} else {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+}
+
+// suggestBranchName turns name into a valid branch name by slugifying each
+// slash-separated part, so that "Feature/Fix login crash" becomes
+// "feature/fix-login-crash". It returns "" if nothing valid is left.
+func suggestBranchName(name string) string {
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		if slug := utils.Slugify(part, 50); slug != "" {
+			parts = append(parts, slug)
+		}
+	}
+	suggestion := strings.Join(parts, "/")
+	if suggestion == "" || utils.ValidateBranchName(suggestion) != nil {
+		return ""
+	}
+	return suggestion
+}
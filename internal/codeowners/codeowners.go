@@ -0,0 +1,186 @@
+// Package codeowners parses CODEOWNERS files and maps changed paths to their owners.
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Locations are the places a CODEOWNERS file is looked up, relative to the repository root,
+// in the order GitHub uses.
+var Locations = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// Matcher reports the owners of a path relative to the repository root.
+// Ruleset implements it for CODEOWNERS files; other ownership sources can be plugged in.
+type Matcher interface {
+	Owners(path string) []string
+}
+
+// Ruleset is a parsed CODEOWNERS file.
+type Ruleset struct {
+	rules []rule
+}
+
+// rule is one line of a CODEOWNERS file.
+type rule struct {
+	pattern string
+	re      *regexp.Regexp
+	owners  []string
+}
+
+// Load reads the first CODEOWNERS file found in root.
+// It returns nil without an error if the repository has none.
+func Load(root string) (*Ruleset, error) {
+	for _, location := range Locations {
+		f, err := os.Open(filepath.Join(root, location))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		rules, err := Parse(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", location, err)
+		}
+		return rules, nil
+	}
+	return nil, nil
+}
+
+// Parse reads CODEOWNERS rules. Blank lines and comments are ignored.
+// A pattern without owners is kept, since it removes ownership from matching paths.
+func Parse(r io.Reader) (*Ruleset, error) {
+	rules := &Ruleset{}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		re, err := compilePattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		rules.rules = append(rules.rules, rule{pattern: fields[0], re: re, owners: fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Owners returns the owners of path according to the last matching rule, as in GitHub.
+func (r *Ruleset) Owners(path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		if r.rules[i].re.MatchString(path) {
+			return r.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// compilePattern translates a gitignore-style CODEOWNERS pattern into a regular expression.
+// Patterns with a leading or inner slash are anchored at the repository root; others match
+// at any depth. A pattern matching a directory also matches everything below it.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("invalid pattern %q", pattern)
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
+
+// OwnedFiles lists the changed files belonging to one owner.
+type OwnedFiles struct {
+	Owner string   `json:"owner"`
+	Files []string `json:"files"`
+}
+
+// Affected groups files by owner, sorted by owner. Files without an owner are omitted.
+func Affected(m Matcher, files []string) []OwnedFiles {
+	byOwner := make(map[string][]string)
+	for _, file := range files {
+		for _, owner := range m.Owners(file) {
+			byOwner[owner] = append(byOwner[owner], file)
+		}
+	}
+
+	affected := make([]OwnedFiles, 0, len(byOwner))
+	for owner, files := range byOwner {
+		affected = append(affected, OwnedFiles{Owner: owner, Files: files})
+	}
+	sort.Slice(affected, func(i, j int) bool { return affected[i].Owner < affected[j].Owner })
+
+	return affected
+}
+
+// Unapproved returns the owners in affected for which approved reports false,
+// for example because no member of an owning team approved the change.
+func Unapproved(affected []OwnedFiles, approved func(owner string) bool) []OwnedFiles {
+	var missing []OwnedFiles
+	for _, owned := range affected {
+		if !approved(owned.Owner) {
+			missing = append(missing, owned)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,72 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testCodeowners = `# Default owners
+*                 @acme/core
+
+*.md              @acme/docs
+/build/           @acme/infra
+apps/web/         @acme/frontend
+docs              @acme/docs   # any docs directory
+**/migrations/*.sql @acme/dba
+/apps/web/vendor/
+`
+
+func TestOwners(t *testing.T) {
+	rules, err := Parse(strings.NewReader(testCodeowners))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	for name, tt := range map[string]struct {
+		path     string
+		expected []string
+	}{
+		"fallback":                  {"main.go", []string{"@acme/core"}},
+		"extension at any depth":    {"pkg/worktree/README.md", []string{"@acme/docs"}},
+		"anchored directory":        {"build/ci/deploy.sh", []string{"@acme/infra"}},
+		"anchored directory nested": {"tools/build/x.sh", []string{"@acme/core"}},
+		"inner slash is anchored":   {"apps/web/src/app.tsx", []string{"@acme/frontend"}},
+		"directory at any depth":    {"services/api/docs/intro.txt", []string{"@acme/docs"}},
+		"double star":               {"services/api/db/migrations/001.sql", []string{"@acme/dba"}},
+		"single star stays in dir":  {"migrations/sub/001.sql", []string{"@acme/core"}},
+		"unowned by later rule":     {"apps/web/vendor/lib.js", []string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.expected, rules.Owners(tt.path)); diff != "" {
+				t.Errorf("Owners(%q) mismatch (-want +got):\n%s", tt.path, diff)
+			}
+		})
+	}
+}
+
+func TestAffectedAndUnapproved(t *testing.T) {
+	t.Parallel()
+
+	rules, err := Parse(strings.NewReader("*.go @alice\n/docs/ @acme/docs @bob\n/vendor/\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	affected := Affected(rules, []string{"main.go", "docs/a.md", "vendor/x.go", "cmd/root.go"})
+	expected := []OwnedFiles{
+		{Owner: "@acme/docs", Files: []string{"docs/a.md"}},
+		{Owner: "@alice", Files: []string{"main.go", "cmd/root.go"}},
+		{Owner: "@bob", Files: []string{"docs/a.md"}},
+	}
+	if diff := cmp.Diff(expected, affected); diff != "" {
+		t.Fatalf("Affected mismatch (-want +got):\n%s", diff)
+	}
+
+	missing := Unapproved(affected, func(owner string) bool { return owner == "@alice" })
+	if diff := cmp.Diff([]OwnedFiles{expected[0], expected[2]}, missing); diff != "" {
+		t.Errorf("Unapproved mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,922 @@
+// Package config loads and validates giwo configuration files.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/knwoop/giwo/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoFileName is the name of the per-repository configuration file, placed in the repository root.
+const RepoFileName = ".giwo.yaml"
+
+// Config holds user-configurable settings.
+// Repository settings take precedence over user settings.
+type Config struct {
+	// WorktreeDir is the directory new worktrees are created in.
+	// Relative paths are resolved against the repository root.
+	WorktreeDir string `yaml:"worktree_dir"`
+
+	// GitBackend selects how giwo talks to git: "exec" (the default) runs the
+	// git executable, "go-git" lists worktrees and resolves revisions with go-git,
+	// which is faster where starting processes is slow, such as on Windows.
+	GitBackend string `yaml:"git_backend"`
+
+	// Editor is the command used to open worktrees. Defaults to $VISUAL or $EDITOR.
+	Editor string `yaml:"editor"`
+
+	// RepoRoots are directories scanned for repositories in cross-repo mode.
+	RepoRoots []string `yaml:"repo_roots"`
+
+	// Hosts are remote machines 'giwo exec --host' can run commands on.
+	Hosts map[string]Host `yaml:"hosts"`
+
+	// User is the git identity recorded in repositories when worktrees are created.
+	User Identity `yaml:"user"`
+
+	// GitHubToken authenticates GitHub API requests. Defaults to $GITHUB_TOKEN.
+	GitHubToken string `yaml:"github_token"`
+
+	// Templates are files copied from the repository root into new worktrees.
+	// Defaults to common editor and environment files.
+	Templates []string `yaml:"templates"`
+
+	// Links are paths in the repository root symlinked into new worktrees instead of copied,
+	// for large shared assets such as node_modules or data directories.
+	Links []string `yaml:"links"`
+
+	// Setup are commands run in new worktrees, in order, such as installing dependencies.
+	Setup []SetupStep `yaml:"setup"`
+
+	// Overrides adjust templates, links and setup for branches matching their
+	// key, a pattern like "release/*"; see ResolveTemplate.
+	Overrides map[string]Override `yaml:"overrides"`
+
+	// Env are extra variables printed by 'giwo env'. Values are Go templates
+	// over the worktree, e.g. "{{.Repo}}-{{.Name}}".
+	Env map[string]string `yaml:"env"`
+
+	// Ports controls the port offsets allocated to worktrees.
+	Ports Ports `yaml:"ports"`
+
+	// Keymap remaps keys in the interactive UIs.
+	Keymap Keymap `yaml:"keymap"`
+
+	// Theme sets the icons and colors of the interactive UIs.
+	Theme Theme `yaml:"theme"`
+
+	// Age sets the commit ages at which worktrees are marked as aging in listings.
+	Age AgeThresholds `yaml:"age"`
+
+	// Issues configures how 'giwo create --issue' looks up issues and names branches.
+	Issues Issues `yaml:"issues"`
+
+	// Tasks configures the isolated worktrees of 'giwo task start'.
+	Tasks Tasks `yaml:"tasks"`
+
+	// Branches configures how 'giwo create' handles branch names taken by someone else.
+	Branches Branches `yaml:"branches"`
+
+	// Main configures the worktree of the default branch that 'giwo main' switches to.
+	Main Main `yaml:"main"`
+
+	// Checkout configures what new worktrees get besides the files of their commit.
+	Checkout Checkout `yaml:"checkout"`
+
+	// Run are named tasks such as test or dev that 'giwo run' executes in worktrees.
+	Run map[string]RunTask `yaml:"run"`
+
+	// AutoSelect makes 'giwo switch <filter>' switch right away when the filter
+	// matches a single worktree, instead of showing the picker. Defaults to true.
+	AutoSelect *bool `yaml:"auto_select"`
+
+	// Updates configures 'giwo upgrade' and the notice of new releases.
+	Updates Updates `yaml:"updates"`
+
+	// GC configures the housekeeping of 'giwo gc'.
+	GC GC `yaml:"gc"`
+
+	// Paths configures how branch names are turned into worktree directory names.
+	Paths PathNames `yaml:"paths"`
+
+	// Profiles are named sets of settings for separate contexts, such as work and personal.
+	Profiles map[string]Profile `yaml:"profiles"`
+
+	// ActiveProfile is the name of the profile applied by Load, if any.
+	ActiveProfile string `yaml:"-"`
+}
+
+// Identity is a git author identity.
+type Identity struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+}
+
+// Ports controls the port offsets allocated to worktrees, so that dev servers of
+// several worktrees can run side by side. Zero values keep the defaults.
+type Ports struct {
+	// Step is the distance between offsets. Defaults to 10.
+	Step int `yaml:"step"`
+
+	// Slots is the number of offsets, including the main worktree's 0. Defaults to 100.
+	Slots int `yaml:"slots"`
+
+	// Offsets pins the offsets of branches.
+	Offsets map[string]int `yaml:"offsets"`
+}
+
+// Issues configures the issue tracker used by 'giwo create --issue'.
+type Issues struct {
+	// Provider is "github" or "jira". By default keys like PROJ-123 are looked
+	// up in Jira and numbers in GitHub.
+	Provider string `yaml:"provider"`
+
+	// JiraURL is the base URL of the Jira site, e.g. https://acme.atlassian.net.
+	JiraURL string `yaml:"jira_url"`
+
+	// BranchTemplate is a Go template over .ID, .Title and .Slug naming the
+	// branch. Defaults to "{{.ID}}-{{.Slug}}".
+	BranchTemplate string `yaml:"branch_template"`
+}
+
+// Tasks configures the worktrees 'giwo task start' creates for automated
+// coding tasks, such as runs of AI agents.
+type Tasks struct {
+	// BranchPrefix starts the generated branch names. Defaults to "task/".
+	BranchPrefix string `yaml:"branch_prefix"`
+
+	// TTL is how long a task may run before it counts as expired, such as
+	// "8h" or "2d". Defaults to 24h.
+	TTL string `yaml:"ttl"`
+
+	// Template is the override applied to task worktrees, as with
+	// 'giwo create --template', e.g. to skip slow setup steps.
+	Template string `yaml:"template"`
+}
+
+// RunTask is a named command that 'giwo run' executes in worktrees, usually
+// defined in the repository's .giwo.yaml.
+type RunTask struct {
+	// Run is the shell command, run in the worktree's directory.
+	Run string `yaml:"run"`
+
+	// Description is shown when listing the tasks.
+	Description string `yaml:"description"`
+
+	// Env are extra variables for the command, Go templates like the values of
+	// the env setting, e.g. PORT: "{{.Port 3000}}".
+	Env map[string]string `yaml:"env"`
+}
+
+// Collision policies of Branches.
+const (
+	CollisionAsk       = "ask"
+	CollisionNamespace = "namespace"
+	CollisionFail      = "fail"
+)
+
+// Branches configures what 'giwo create' does when the new branch name is
+// already taken, locally or on origin, by a branch someone else committed to.
+type Branches struct {
+	// Namespace prefixes the alternative names offered, as in "knwoop/feature-x".
+	// Defaults to the user name in the git email address.
+	Namespace string `yaml:"namespace"`
+
+	// OnCollision is "ask" to offer the alternatives (the default; non-interactive
+	// runs fail), "namespace" to take <namespace>/<branch> without asking, or
+	// "fail" to refuse.
+	OnCollision string `yaml:"on_collision"`
+}
+
+// Dirty checkout policies of Main.
+const (
+	DirtyWarn   = "warn"
+	DirtyRefuse = "refuse"
+	DirtyIgnore = "ignore"
+)
+
+// Release channels of Updates.
+const (
+	ChannelStable     = "stable"
+	ChannelPrerelease = "prerelease"
+)
+
+// Updates configures how giwo looks for new releases of itself.
+type Updates struct {
+	// Check makes interactive commands look for a new release at most once a
+	// day and mention it. Defaults to true.
+	Check *bool `yaml:"check"`
+
+	// Channel is "stable" (the default) for releases only, or "prerelease" to
+	// include prereleases.
+	Channel string `yaml:"channel"`
+}
+
+// CheckEnabled reports whether giwo looks for new releases on its own.
+func (u Updates) CheckEnabled() bool {
+	return u.Check == nil || *u.Check
+}
+
+// ResolvedChannel returns the release channel, stable unless configured.
+func (u Updates) ResolvedChannel() string {
+	if u.Channel == "" {
+		return ChannelStable
+	}
+	return u.Channel
+}
+
+// GC configures what 'giwo gc' cleans up besides giwo's own state.
+type GC struct {
+	// ArchiveRetention is how long archives are kept, such as "90d". Archives
+	// are kept until restored if empty.
+	ArchiveRetention string `yaml:"archive_retention"`
+
+	// Maintenance makes 'giwo gc' run 'git maintenance run' on the object
+	// store the worktrees share.
+	Maintenance bool `yaml:"maintenance"`
+}
+
+// PathNames configures how branch names are turned into worktree directory names.
+// Names that are not valid file names on every system, or are too long, are
+// altered, and given a hash suffix if another branch already took the result.
+type PathNames struct {
+	// MaxLength caps the length in bytes of each directory name. Defaults to 80.
+	MaxLength int `yaml:"max_length"`
+
+	// Replacement is the character that replaces those invalid in file names,
+	// such as ':' or '?'. Defaults to "-".
+	Replacement string `yaml:"replacement"`
+}
+
+// minPathLength leaves room in directory names for a few characters of the
+// name besides the hash suffix.
+const minPathLength = 16
+
+// Main configures the worktree of the default branch and how 'giwo create'
+// treats uncommitted changes in the checkout of the branch it starts from.
+type Main struct {
+	// Dedicated makes 'giwo main' keep a worktree of its own for the default
+	// branch, which nobody works in and which is fast-forwarded to origin each
+	// time it is switched to, instead of switching to where the branch is
+	// checked out, usually the repository root.
+	Dedicated bool `yaml:"dedicated"`
+
+	// OnDirty is what 'giwo create' does when the branch it starts from is
+	// checked out with uncommitted changes, which the new worktree does not
+	// get: "warn" (the default), "refuse" or "ignore".
+	OnDirty string `yaml:"on_dirty"`
+}
+
+// Checkout configures whether 'giwo create' checks out the submodules and Git
+// LFS objects of new worktrees, which git worktree add leaves out, and links
+// their git hooks. All default to true and only affect repositories that use them.
+type Checkout struct {
+	Submodules *bool `yaml:"submodules"`
+	LFS        *bool `yaml:"lfs"`
+
+	// Hooks links the git hooks directory of a relative core.hooksPath, such
+	// as husky's, into new worktrees that do not have it.
+	Hooks *bool `yaml:"hooks"`
+
+	// Sparse are named lists of directories that 'giwo create --sparse <name>'
+	// limits a new worktree to, as a cone-mode sparse checkout.
+	Sparse map[string][]string `yaml:"sparse"`
+}
+
+// SubmodulesEnabled reports whether new worktrees get their submodules checked out.
+func (c Checkout) SubmodulesEnabled() bool {
+	return c.Submodules == nil || *c.Submodules
+}
+
+// LFSEnabled reports whether new worktrees get their LFS objects downloaded.
+func (c Checkout) LFSEnabled() bool {
+	return c.LFS == nil || *c.LFS
+}
+
+// HooksEnabled reports whether new worktrees get the hooks directory linked.
+func (c Checkout) HooksEnabled() bool {
+	return c.Hooks == nil || *c.Hooks
+}
+
+// AgeThresholds are the commit ages from which a worktree counts as recent,
+// aging and stale, such as "36h", "3d" or "2w". Unset thresholds keep their defaults.
+type AgeThresholds struct {
+	Recent string `yaml:"recent"`
+	Aging  string `yaml:"aging"`
+	Stale  string `yaml:"stale"`
+}
+
+// ParseAge parses a duration as accepted by time.ParseDuration, or a whole
+// number of days or weeks such as "3d" or "2w".
+func ParseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// Keymap maps key names to UI actions, e.g. "ctrl-d: delete", separately for
+// the fuzzy finder and the numbered selector. Keys not listed keep their default.
+type Keymap struct {
+	Finder   map[string]string `yaml:"finder"`
+	Selector map[string]string `yaml:"selector"`
+}
+
+// Theme picks the icons and colors of the interactive UIs and progress lines.
+// Name is a built-in theme, such as emoji, nerd or ascii, and Colors replace
+// its colors by role, e.g. "match: '#ff8800'". Both are checked when used.
+type Theme struct {
+	Name   string            `yaml:"name"`
+	Colors map[string]string `yaml:"colors"`
+}
+
+// Profile is a named set of settings layered over the user configuration.
+// It is selected with --profile, or automatically when the repository's
+// origin remote matches one of its patterns.
+type Profile struct {
+	// Match lists remote hosts, optionally followed by an owner, e.g. "github.com/acme".
+	Match []string `yaml:"match"`
+
+	Config `yaml:",inline"`
+}
+
+// Host describes a remote machine sharing the repository layout.
+type Host struct {
+	// SSH is the ssh destination, e.g. "me@buildbox". Defaults to the host name.
+	SSH string `yaml:"ssh"`
+
+	// PathMap maps local path prefixes to their remote counterparts.
+	PathMap map[string]string `yaml:"path_map"`
+}
+
+// UserPath returns the path of the user configuration file.
+// It honors $XDG_CONFIG_HOME and defaults to ~/.config/giwo/config.yaml.
+func UserPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "giwo", "config.yaml")
+}
+
+// StateDir returns the directory for user-level state shared across repositories.
+// It honors $XDG_STATE_HOME and defaults to ~/.local/state/giwo.
+func StateDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "giwo")
+}
+
+// RepoPath returns the path of the repository configuration file.
+func RepoPath(repoRoot string) string {
+	return filepath.Join(repoRoot, RepoFileName)
+}
+
+// Paths returns the configuration files consulted for a repository, in increasing precedence.
+func Paths(repoRoot string) []string {
+	var paths []string
+	if user := UserPath(); user != "" {
+		paths = append(paths, user)
+	}
+	if repoRoot != "" {
+		paths = append(paths, RepoPath(repoRoot))
+	}
+	return paths
+}
+
+// Load reads the user and repository configuration files and merges them.
+// Missing files are not an error.
+//
+// The profile named by profile is layered between the two; if profile is empty,
+// the first profile matching remoteURL is used. Settings of other profiles never apply.
+func Load(repoRoot, profile, remoteURL string) (*Config, error) {
+	user, err := loadOptional(UserPath())
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &Config{}
+	if repoRoot != "" {
+		if repo, err = loadOptional(RepoPath(repoRoot)); err != nil {
+			return nil, err
+		}
+	}
+
+	profiles := make(map[string]Profile)
+	for _, fileCfg := range []*Config{user, repo} {
+		for name, p := range fileCfg.Profiles {
+			profiles[name] = p
+		}
+	}
+
+	name, err := SelectProfile(profiles, profile, remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	cfg.Merge(user)
+	if name != "" {
+		p := profiles[name]
+		cfg.Merge(&p.Config)
+	}
+	cfg.Merge(repo)
+	cfg.Profiles = profiles
+	cfg.ActiveProfile = name
+
+	return cfg, nil
+}
+
+// loadOptional reads a configuration file, returning an empty configuration if it does not exist.
+func loadOptional(path string) (*Config, error) {
+	if path == "" {
+		return &Config{}, nil
+	}
+
+	cfg, err := LoadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	return cfg, err
+}
+
+// SelectProfile returns the profile to apply: the one called name if it is set,
+// otherwise the first profile, in name order, matching remoteURL.
+// It returns an empty name if no profile applies.
+func SelectProfile(profiles map[string]Profile, name, remoteURL string) (string, error) {
+	if name != "" {
+		if _, ok := profiles[name]; !ok {
+			return "", fmt.Errorf("unknown profile %q", name)
+		}
+		return name, nil
+	}
+
+	remote := normalizeRemote(remoteURL)
+	if remote == "" {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, pattern := range profiles[name].Match {
+			pattern = strings.Trim(strings.ToLower(pattern), "/")
+			if remote == pattern || strings.HasPrefix(remote, pattern+"/") {
+				return name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// normalizeRemote reduces a git remote URL to "host/path" without a user, port or .git suffix,
+// so that https, ssh and scp-like URLs of the same repository compare equal.
+func normalizeRemote(remoteURL string) string {
+	remote := strings.TrimSpace(remoteURL)
+	if remote == "" {
+		return ""
+	}
+
+	if i := strings.Index(remote, "://"); i >= 0 {
+		remote = remote[i+3:]
+		if at := strings.Index(remote, "@"); at >= 0 && at < strings.Index(remote+"/", "/") {
+			remote = remote[at+1:]
+		}
+		host, path, _ := strings.Cut(remote, "/")
+		host, _, _ = strings.Cut(host, ":")
+		remote = host + "/" + path
+	} else {
+		// scp-like syntax: [user@]host:path
+		host, path, ok := strings.Cut(remote, ":")
+		if !ok {
+			return ""
+		}
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		remote = host + "/" + path
+	}
+
+	remote = strings.TrimSuffix(strings.Trim(remote, "/"), ".git")
+	return strings.ToLower(remote)
+}
+
+// LoadFile reads and validates a single configuration file.
+// Unknown keys are rejected so that typos do not go unnoticed.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the configuration for values that cannot work.
+func (c *Config) Validate() error {
+	if c.WorktreeDir != "" && filepath.Clean(c.WorktreeDir) == "." {
+		return fmt.Errorf("worktree_dir must not be the repository root")
+	}
+	for name, p := range c.Profiles {
+		if len(p.Profiles) > 0 {
+			return fmt.Errorf("profiles.%s: profiles cannot be nested", name)
+		}
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("profiles.%s: %w", name, err)
+		}
+	}
+	if c.Ports.Step < 0 || c.Ports.Slots < 0 || c.Ports.Slots == 1 {
+		return fmt.Errorf("ports: step must not be negative and slots must be at least 2")
+	}
+	for branch, offset := range c.Ports.Offsets {
+		if offset < 0 {
+			return fmt.Errorf("ports.offsets: %s has a negative offset", branch)
+		}
+	}
+	for name, age := range map[string]string{"recent": c.Age.Recent, "aging": c.Age.Aging, "stale": c.Age.Stale} {
+		if age == "" {
+			continue
+		}
+		if _, err := ParseAge(age); err != nil {
+			return fmt.Errorf("age.%s: %w", name, err)
+		}
+	}
+	if c.Tasks.TTL != "" {
+		if _, err := ParseAge(c.Tasks.TTL); err != nil {
+			return fmt.Errorf("tasks.ttl: %w", err)
+		}
+	}
+	if c.GC.ArchiveRetention != "" {
+		if _, err := ParseAge(c.GC.ArchiveRetention); err != nil {
+			return fmt.Errorf("gc.archive_retention: %w", err)
+		}
+	}
+	if c.Paths.MaxLength != 0 && c.Paths.MaxLength < minPathLength {
+		return fmt.Errorf("paths.max_length: must be at least %d, got %d", minPathLength, c.Paths.MaxLength)
+	}
+	if r := c.Paths.Replacement; r != "" && (utf8.RuneCountInString(r) != 1 || strings.ContainsAny(r, "/. <>:\"\\|?*") || r[0] < 0x20) {
+		return fmt.Errorf("paths.replacement: must be a single character valid in file names, got %q", r)
+	}
+	if c.Tasks.BranchPrefix != "" {
+		if err := utils.ValidateBranchName(c.Tasks.BranchPrefix + "x"); err != nil {
+			return fmt.Errorf("tasks.branch_prefix: %w", err)
+		}
+	}
+	if c.Branches.Namespace != "" {
+		if err := utils.ValidateBranchName(c.Branches.Namespace + "/x"); err != nil {
+			return fmt.Errorf("branches.namespace: %w", err)
+		}
+	}
+	switch c.Branches.OnCollision {
+	case "", CollisionAsk, CollisionNamespace, CollisionFail:
+	default:
+		return fmt.Errorf("branches.on_collision: must be ask, namespace or fail, got %q", c.Branches.OnCollision)
+	}
+	switch c.Main.OnDirty {
+	case "", DirtyWarn, DirtyRefuse, DirtyIgnore:
+	default:
+		return fmt.Errorf("main.on_dirty: must be warn, refuse or ignore, got %q", c.Main.OnDirty)
+	}
+	for name, dirs := range c.Checkout.Sparse {
+		if len(dirs) == 0 {
+			return fmt.Errorf("checkout.sparse.%s: no directories", name)
+		}
+		for _, dir := range dirs {
+			if err := validateSparseDir(dir); err != nil {
+				return fmt.Errorf("checkout.sparse.%s: %w", name, err)
+			}
+		}
+	}
+	switch c.Updates.Channel {
+	case "", ChannelStable, ChannelPrerelease:
+	default:
+		return fmt.Errorf("updates.channel: must be stable or prerelease, got %q", c.Updates.Channel)
+	}
+	switch c.GitBackend {
+	case "", "exec", "go-git":
+	default:
+		return fmt.Errorf("git_backend: must be exec or go-git, got %q", c.GitBackend)
+	}
+	switch c.Issues.Provider {
+	case "", "github", "jira":
+	default:
+		return fmt.Errorf("issues.provider: must be github or jira, got %q", c.Issues.Provider)
+	}
+	if _, err := utils.ParseIssueBranchTemplate(c.Issues.BranchTemplate); err != nil {
+		return fmt.Errorf("issues.branch_template: %w", err)
+	}
+	if err := validateSetup(c.Setup); err != nil {
+		return fmt.Errorf("setup: %w", err)
+	}
+	for pattern, o := range c.Overrides {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("overrides: invalid branch pattern %q", pattern)
+		}
+		if err := validateSetup(o.Setup); err != nil {
+			return fmt.Errorf("overrides.%s.setup: %w", pattern, err)
+		}
+	}
+	for name := range c.Env {
+		if !isEnvName(name) {
+			return fmt.Errorf("env: %q is not a valid variable name", name)
+		}
+	}
+	for name, task := range c.Run {
+		if name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+			return fmt.Errorf("run: %q is not a valid task name", name)
+		}
+		if task.Run == "" {
+			return fmt.Errorf("run.%s: needs a run command", name)
+		}
+		for env := range task.Env {
+			if !isEnvName(env) {
+				return fmt.Errorf("run.%s.env: %q is not a valid variable name", name, env)
+			}
+		}
+	}
+	for name, host := range c.Hosts {
+		for local, remote := range host.PathMap {
+			if !filepath.IsAbs(ExpandHome(local)) || !strings.HasPrefix(remote, "/") {
+				return fmt.Errorf("hosts.%s.path_map: %q -> %q must map absolute paths", name, local, remote)
+			}
+		}
+	}
+	return nil
+}
+
+// isEnvName reports whether name can be used as a shell variable name.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !(r >= 'A' && r <= 'Z') && !(r >= 'a' && r <= 'z') && (i == 0 || !(r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveWorktreeDir returns the absolute worktree directory for a repository,
+// with symlinks resolved as git does for worktree paths, or an empty string if
+// none is configured.
+func (c *Config) ResolveWorktreeDir(repoRoot string) string {
+	dir := c.WorktreeDir
+	if dir == "" {
+		return ""
+	}
+
+	dir = ExpandHome(dir)
+	switch {
+	case filepath.IsAbs(dir):
+	case filepath.VolumeName(dir) == "" && (strings.HasPrefix(dir, "/") || strings.HasPrefix(dir, `\`)):
+		// Rooted without a drive on Windows, such as \worktrees: on the repository's drive
+		dir = filepath.VolumeName(repoRoot) + dir
+	default:
+		dir = filepath.Join(repoRoot, dir)
+	}
+	return utils.ResolvePath(dir)
+}
+
+// AutoSelectEnabled reports whether a filter matching a single worktree skips the picker.
+func (c *Config) AutoSelectEnabled() bool {
+	return c.AutoSelect == nil || *c.AutoSelect
+}
+
+// ResolveRepoRoots returns the configured repository roots with ~ expanded.
+func (c *Config) ResolveRepoRoots() []string {
+	roots := make([]string, 0, len(c.RepoRoots))
+	for _, root := range c.RepoRoots {
+		roots = append(roots, filepath.Clean(ExpandHome(root)))
+	}
+	return roots
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// Merge overrides the settings of c with those set in other.
+// Profiles are not merged; Load resolves them separately.
+func (c *Config) Merge(other *Config) {
+	if other.WorktreeDir != "" {
+		c.WorktreeDir = other.WorktreeDir
+	}
+	if other.GitBackend != "" {
+		c.GitBackend = other.GitBackend
+	}
+	if other.Editor != "" {
+		c.Editor = other.Editor
+	}
+	if len(other.RepoRoots) > 0 {
+		c.RepoRoots = other.RepoRoots
+	}
+	for name, host := range other.Hosts {
+		if c.Hosts == nil {
+			c.Hosts = make(map[string]Host)
+		}
+		c.Hosts[name] = host
+	}
+	if other.User.Name != "" {
+		c.User.Name = other.User.Name
+	}
+	if other.User.Email != "" {
+		c.User.Email = other.User.Email
+	}
+	if other.GitHubToken != "" {
+		c.GitHubToken = other.GitHubToken
+	}
+	if len(other.Templates) > 0 {
+		c.Templates = other.Templates
+	}
+	if len(other.Links) > 0 {
+		c.Links = other.Links
+	}
+	if len(other.Setup) > 0 {
+		c.Setup = other.Setup
+	}
+	for pattern, o := range other.Overrides {
+		if c.Overrides == nil {
+			c.Overrides = make(map[string]Override)
+		}
+		c.Overrides[pattern] = o
+	}
+	for name, value := range other.Env {
+		if c.Env == nil {
+			c.Env = make(map[string]string)
+		}
+		c.Env[name] = value
+	}
+	if other.Ports.Step != 0 {
+		c.Ports.Step = other.Ports.Step
+	}
+	if other.Ports.Slots != 0 {
+		c.Ports.Slots = other.Ports.Slots
+	}
+	for branch, offset := range other.Ports.Offsets {
+		if c.Ports.Offsets == nil {
+			c.Ports.Offsets = make(map[string]int)
+		}
+		c.Ports.Offsets[branch] = offset
+	}
+	if other.Issues.Provider != "" {
+		c.Issues.Provider = other.Issues.Provider
+	}
+	if other.Issues.JiraURL != "" {
+		c.Issues.JiraURL = other.Issues.JiraURL
+	}
+	if other.Issues.BranchTemplate != "" {
+		c.Issues.BranchTemplate = other.Issues.BranchTemplate
+	}
+	if other.Age.Recent != "" {
+		c.Age.Recent = other.Age.Recent
+	}
+	if other.Age.Aging != "" {
+		c.Age.Aging = other.Age.Aging
+	}
+	if other.Age.Stale != "" {
+		c.Age.Stale = other.Age.Stale
+	}
+	if other.Tasks.BranchPrefix != "" {
+		c.Tasks.BranchPrefix = other.Tasks.BranchPrefix
+	}
+	if other.Tasks.TTL != "" {
+		c.Tasks.TTL = other.Tasks.TTL
+	}
+	if other.Tasks.Template != "" {
+		c.Tasks.Template = other.Tasks.Template
+	}
+	if other.Branches.Namespace != "" {
+		c.Branches.Namespace = other.Branches.Namespace
+	}
+	if other.Branches.OnCollision != "" {
+		c.Branches.OnCollision = other.Branches.OnCollision
+	}
+	if other.Main.Dedicated {
+		c.Main.Dedicated = true
+	}
+	if other.Main.OnDirty != "" {
+		c.Main.OnDirty = other.Main.OnDirty
+	}
+	if other.Checkout.Submodules != nil {
+		c.Checkout.Submodules = other.Checkout.Submodules
+	}
+	if other.Checkout.LFS != nil {
+		c.Checkout.LFS = other.Checkout.LFS
+	}
+	if other.Checkout.Hooks != nil {
+		c.Checkout.Hooks = other.Checkout.Hooks
+	}
+	for name, dirs := range other.Checkout.Sparse {
+		if c.Checkout.Sparse == nil {
+			c.Checkout.Sparse = make(map[string][]string)
+		}
+		c.Checkout.Sparse[name] = dirs
+	}
+	for name, task := range other.Run {
+		if c.Run == nil {
+			c.Run = make(map[string]RunTask)
+		}
+		c.Run[name] = task
+	}
+	if other.AutoSelect != nil {
+		c.AutoSelect = other.AutoSelect
+	}
+	if other.Updates.Check != nil {
+		c.Updates.Check = other.Updates.Check
+	}
+	if other.Updates.Channel != "" {
+		c.Updates.Channel = other.Updates.Channel
+	}
+	if other.GC.ArchiveRetention != "" {
+		c.GC.ArchiveRetention = other.GC.ArchiveRetention
+	}
+	if other.GC.Maintenance {
+		c.GC.Maintenance = true
+	}
+	if other.Paths.MaxLength != 0 {
+		c.Paths.MaxLength = other.Paths.MaxLength
+	}
+	if other.Paths.Replacement != "" {
+		c.Paths.Replacement = other.Paths.Replacement
+	}
+	c.Keymap.Finder = mergeBindings(c.Keymap.Finder, other.Keymap.Finder)
+	c.Keymap.Selector = mergeBindings(c.Keymap.Selector, other.Keymap.Selector)
+	if other.Theme.Name != "" {
+		c.Theme.Name = other.Theme.Name
+	}
+	c.Theme.Colors = mergeBindings(c.Theme.Colors, other.Theme.Colors)
+}
+
+// mergeBindings returns bindings with the keys in overrides rebound.
+func mergeBindings(bindings, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return bindings
+	}
+	merged := make(map[string]string, len(bindings)+len(overrides))
+	for key, action := range bindings {
+		merged[key] = action
+	}
+	for key, action := range overrides {
+		merged[key] = action
+	}
+	return merged
+}
@@ -0,0 +1,521 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadFile(t *testing.T) {
+	for name, tt := range map[string]struct {
+		content   string
+		expected  *Config
+		wantError bool
+	}{
+		"empty file": {
+			content:  "",
+			expected: &Config{},
+		},
+		"worktree dir": {
+			content:  "worktree_dir: ../worktrees\n",
+			expected: &Config{WorktreeDir: "../worktrees"},
+		},
+		"host": {
+			content: "hosts:\n  buildbox:\n    ssh: me@buildbox\n    path_map:\n      /Users/me/src: /home/me/src\n",
+			expected: &Config{Hosts: map[string]Host{
+				"buildbox": {SSH: "me@buildbox", PathMap: map[string]string{"/Users/me/src": "/home/me/src"}},
+			}},
+		},
+		"host with relative path map": {
+			content:   "hosts:\n  buildbox:\n    path_map:\n      src: /home/me/src\n",
+			wantError: true,
+		},
+		"profile": {
+			content: "profiles:\n  work:\n    match: [github.com/acme]\n    user:\n      email: me@acme.com\n    worktree_dir: ~/work/trees\n",
+			expected: &Config{Profiles: map[string]Profile{
+				"work": {
+					Match:  []string{"github.com/acme"},
+					Config: Config{User: Identity{Email: "me@acme.com"}, WorktreeDir: "~/work/trees"},
+				},
+			}},
+		},
+		"keymap": {
+			content: "keymap:\n  finder:\n    ctrl-d: delete\n    ctrl-o: open-editor\n  selector:\n    d: delete\n",
+			expected: &Config{Keymap: Keymap{
+				Finder:   map[string]string{"ctrl-d": "delete", "ctrl-o": "open-editor"},
+				Selector: map[string]string{"d": "delete"},
+			}},
+		},
+		"theme": {
+			content:  "theme:\n  name: ascii\n  colors:\n    match: \"#ff8800\"\n",
+			expected: &Config{Theme: Theme{Name: "ascii", Colors: map[string]string{"match": "#ff8800"}}},
+		},
+		"gc": {
+			content:  "gc:\n  archive_retention: 90d\n  maintenance: true\n",
+			expected: &Config{GC: GC{ArchiveRetention: "90d", Maintenance: true}},
+		},
+		"paths": {
+			content:  "paths:\n  max_length: 40\n  replacement: _\n",
+			expected: &Config{Paths: PathNames{MaxLength: 40, Replacement: "_"}},
+		},
+		"too short max path length": {
+			content:   "paths:\n  max_length: 8\n",
+			wantError: true,
+		},
+		"invalid path replacement": {
+			content:   "paths:\n  replacement: ':'\n",
+			wantError: true,
+		},
+		"invalid archive retention": {
+			content:   "gc:\n  archive_retention: a while\n",
+			wantError: true,
+		},
+		"updates": {
+			content:  "updates:\n  check: false\n  channel: prerelease\n",
+			expected: &Config{Updates: Updates{Check: ptr(false), Channel: ChannelPrerelease}},
+		},
+		"invalid update channel": {
+			content:   "updates:\n  channel: nightly\n",
+			wantError: true,
+		},
+		"setup and overrides": {
+			content: "setup:\n  - name: deps\n    run: npm ci\noverrides:\n  docs/*:\n    skip: [deps]\n",
+			expected: &Config{
+				Setup:     []SetupStep{{Name: "deps", Run: "npm ci"}},
+				Overrides: map[string]Override{"docs/*": {Skip: []string{"deps"}}},
+			},
+		},
+		"setup step without command": {
+			content:   "setup:\n  - name: deps\n",
+			wantError: true,
+		},
+		"invalid override pattern": {
+			content:   "overrides:\n  \"release/[\":\n    skip: [deps]\n",
+			wantError: true,
+		},
+		"git backend": {
+			content:  "git_backend: go-git\n",
+			expected: &Config{GitBackend: "go-git"},
+		},
+		"unknown git backend": {
+			content:   "git_backend: libgit2\n",
+			wantError: true,
+		},
+		"auto select": {
+			content:  "auto_select: false\n",
+			expected: &Config{AutoSelect: ptr(false)},
+		},
+		"env": {
+			content:  "env:\n  COMPOSE_PROJECT_NAME: \"{{.Repo}}-{{.Name}}\"\n",
+			expected: &Config{Env: map[string]string{"COMPOSE_PROJECT_NAME": "{{.Repo}}-{{.Name}}"}},
+		},
+		"invalid env name": {
+			content:   "env:\n  1PORT: \"3000\"\n",
+			wantError: true,
+		},
+		"ports": {
+			content:  "ports:\n  step: 100\n  offsets:\n    main: 0\n    demo: 900\n",
+			expected: &Config{Ports: Ports{Step: 100, Offsets: map[string]int{"main": 0, "demo": 900}}},
+		},
+		"single port slot": {
+			content:   "ports:\n  slots: 1\n",
+			wantError: true,
+		},
+		"age": {
+			content:  "age:\n  recent: 36h\n  stale: 2w\n",
+			expected: &Config{Age: AgeThresholds{Recent: "36h", Stale: "2w"}},
+		},
+		"invalid age": {
+			content:   "age:\n  stale: a month\n",
+			wantError: true,
+		},
+		"issues": {
+			content:  "issues:\n  provider: jira\n  jira_url: https://acme.atlassian.net\n  branch_template: \"{{.ID}}/{{.Slug}}\"\n",
+			expected: &Config{Issues: Issues{Provider: "jira", JiraURL: "https://acme.atlassian.net", BranchTemplate: "{{.ID}}/{{.Slug}}"}},
+		},
+		"unknown issue provider": {
+			content:   "issues:\n  provider: linear\n",
+			wantError: true,
+		},
+		"tasks": {
+			content:  "tasks:\n  branch_prefix: agent/\n  ttl: 8h\n  template: sandbox\n",
+			expected: &Config{Tasks: Tasks{BranchPrefix: "agent/", TTL: "8h", Template: "sandbox"}},
+		},
+		"invalid task ttl": {
+			content:   "tasks:\n  ttl: forever\n",
+			wantError: true,
+		},
+		"invalid task branch prefix": {
+			content:   "tasks:\n  branch_prefix: \"agent task/\"\n",
+			wantError: true,
+		},
+		"branches": {
+			content:  "branches:\n  namespace: knwoop\n  on_collision: namespace\n",
+			expected: &Config{Branches: Branches{Namespace: "knwoop", OnCollision: CollisionNamespace}},
+		},
+		"invalid branch namespace": {
+			content:   "branches:\n  namespace: \"kn woop\"\n",
+			wantError: true,
+		},
+		"unknown collision policy": {
+			content:   "branches:\n  on_collision: rename\n",
+			wantError: true,
+		},
+		"main": {
+			content:  "main:\n  dedicated: true\n  on_dirty: refuse\n",
+			expected: &Config{Main: Main{Dedicated: true, OnDirty: DirtyRefuse}},
+		},
+		"checkout": {
+			content:  "checkout:\n  submodules: false\n  lfs: true\n",
+			expected: &Config{Checkout: Checkout{Submodules: ptr(false), LFS: ptr(true)}},
+		},
+		"sparse profiles": {
+			content:  "checkout:\n  sparse:\n    api: [services/api, libs/common]\n",
+			expected: &Config{Checkout: Checkout{Sparse: map[string][]string{"api": {"services/api", "libs/common"}}}},
+		},
+		"sparse profile outside the repository": {
+			content:   "checkout:\n  sparse:\n    api: [../api]\n",
+			wantError: true,
+		},
+		"unknown dirty policy": {
+			content:   "main:\n  on_dirty: stash\n",
+			wantError: true,
+		},
+		"run tasks": {
+			content: "run:\n  test:\n    run: go test ./...\n    description: Run the tests\n  dev:\n    run: npm run dev\n    env:\n      PORT: \"{{.Port 3000}}\"\n",
+			expected: &Config{Run: map[string]RunTask{
+				"test": {Run: "go test ./...", Description: "Run the tests"},
+				"dev":  {Run: "npm run dev", Env: map[string]string{"PORT": "{{.Port 3000}}"}},
+			}},
+		},
+		"run task without command": {
+			content:   "run:\n  test:\n    description: Run the tests\n",
+			wantError: true,
+		},
+		"run task with invalid env name": {
+			content:   "run:\n  dev:\n    run: npm run dev\n    env:\n      DEV-PORT: \"3000\"\n",
+			wantError: true,
+		},
+		"nested profiles": {
+			content:   "profiles:\n  work:\n    profiles:\n      inner: {}\n",
+			wantError: true,
+		},
+		"unknown key": {
+			content:   "worktree_directory: ../worktrees\n",
+			wantError: true,
+		},
+		"repository root as worktree dir": {
+			content:   "worktree_dir: .\n",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadFile(path)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("LoadFile expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFile unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, cfg); diff != "" {
+				t.Errorf("LoadFile mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveWorktreeDir(t *testing.T) {
+	for name, tt := range map[string]struct {
+		worktreeDir string
+		expected    string
+	}{
+		"not configured": {"", ""},
+		"relative":       {"../worktrees", "/src/worktrees"},
+		"absolute":       {"/var/worktrees", "/var/worktrees"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{WorktreeDir: tt.worktreeDir}
+			if diff := cmp.Diff(tt.expected, cfg.ResolveWorktreeDir("/src/repo")); diff != "" {
+				t.Errorf("ResolveWorktreeDir mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectProfile(t *testing.T) {
+	profiles := map[string]Profile{
+		"personal": {Match: []string{"github.com/me"}},
+		"work":     {Match: []string{"github.com/acme", "git.acme.internal"}},
+	}
+
+	for name, tt := range map[string]struct {
+		profile   string
+		remoteURL string
+		expected  string
+		wantError bool
+	}{
+		"explicit":           {profile: "personal", remoteURL: "git@github.com:acme/api.git", expected: "personal"},
+		"unknown explicit":   {profile: "school", wantError: true},
+		"scp-like remote":    {remoteURL: "git@github.com:acme/api.git", expected: "work"},
+		"https remote":       {remoteURL: "https://github.com/me/dotfiles", expected: "personal"},
+		"ssh remote on host": {remoteURL: "ssh://git@git.acme.internal:2222/platform/infra.git", expected: "work"},
+		"owner prefix only":  {remoteURL: "https://github.com/acme-labs/tool.git", expected: ""},
+		"no match":           {remoteURL: "https://gitlab.com/acme/api.git", expected: ""},
+		"no remote":          {expected: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := SelectProfile(profiles, tt.profile, tt.remoteURL)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("SelectProfile expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectProfile unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("SelectProfile mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	configHome := t.TempDir()
+	repoRoot := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	user := `editor: vim
+worktree_dir: ../trees
+profiles:
+  work:
+    match: [github.com/acme]
+    editor: code
+    github_token: work-token
+    user:
+      email: me@acme.com
+  personal:
+    match: [github.com/me]
+    github_token: personal-token
+`
+	if err := os.MkdirAll(filepath.Join(configHome, "giwo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configHome, "giwo", "config.yaml"), []byte(user), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(RepoPath(repoRoot), []byte("editor: nvim\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(repoRoot, "", "git@github.com:acme/api.git")
+	if err != nil {
+		t.Fatalf("Load unexpected error: %v", err)
+	}
+
+	got := Config{
+		WorktreeDir:   cfg.WorktreeDir,
+		Editor:        cfg.Editor,
+		User:          cfg.User,
+		GitHubToken:   cfg.GitHubToken,
+		ActiveProfile: cfg.ActiveProfile,
+	}
+	expected := Config{
+		WorktreeDir:   "../trees",
+		Editor:        "nvim",
+		User:          Identity{Email: "me@acme.com"},
+		GitHubToken:   "work-token",
+		ActiveProfile: "work",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Load mismatch (-want +got):\n%s", diff)
+	}
+
+	cfg, err = Load(repoRoot, "", "https://gitlab.com/oss/tool.git")
+	if err != nil {
+		t.Fatalf("Load unexpected error: %v", err)
+	}
+	if cfg.GitHubToken != "" || cfg.User.Email != "" || cfg.ActiveProfile != "" {
+		t.Errorf("expected no profile settings without a match, got %+v", cfg)
+	}
+}
+
+func TestMergeKeymap(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{}
+	cfg.Merge(&Config{Keymap: Keymap{Finder: map[string]string{"ctrl-d": "delete", "ctrl-o": "open-editor"}}})
+	cfg.Merge(&Config{Keymap: Keymap{Finder: map[string]string{"ctrl-d": "abort"}}})
+
+	expected := Keymap{Finder: map[string]string{"ctrl-d": "abort", "ctrl-o": "open-editor"}}
+	if diff := cmp.Diff(expected, cfg.Keymap); diff != "" {
+		t.Errorf("Merge keymap mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAutoSelectEnabled(t *testing.T) {
+	for name, tt := range map[string]struct {
+		configs  []*Config
+		expected bool
+	}{
+		"default": {
+			expected: true,
+		},
+		"disabled": {
+			configs:  []*Config{{AutoSelect: ptr(false)}},
+			expected: false,
+		},
+		"disabled by user, unset in repository": {
+			configs:  []*Config{{AutoSelect: ptr(false)}, {}},
+			expected: false,
+		},
+		"re-enabled in repository": {
+			configs:  []*Config{{AutoSelect: ptr(false)}, {AutoSelect: ptr(true)}},
+			expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{}
+			for _, other := range tt.configs {
+				cfg.Merge(other)
+			}
+			if diff := cmp.Diff(tt.expected, cfg.AutoSelectEnabled()); diff != "" {
+				t.Errorf("AutoSelectEnabled mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckoutEnabled(t *testing.T) {
+	for name, tt := range map[string]struct {
+		configs        []*Config
+		wantSubmodules bool
+		wantLFS        bool
+		wantHooks      bool
+	}{
+		"default": {
+			wantSubmodules: true,
+			wantLFS:        true,
+			wantHooks:      true,
+		},
+		"submodules disabled": {
+			configs:        []*Config{{Checkout: Checkout{Submodules: ptr(false)}}},
+			wantSubmodules: false,
+			wantLFS:        true,
+			wantHooks:      true,
+		},
+		"lfs disabled by user, unset in repository": {
+			configs:        []*Config{{Checkout: Checkout{LFS: ptr(false)}}, {}},
+			wantSubmodules: true,
+			wantLFS:        false,
+			wantHooks:      true,
+		},
+		"lfs re-enabled in repository": {
+			configs:        []*Config{{Checkout: Checkout{LFS: ptr(false)}}, {Checkout: Checkout{LFS: ptr(true)}}},
+			wantSubmodules: true,
+			wantLFS:        true,
+			wantHooks:      true,
+		},
+		"hooks disabled": {
+			configs:        []*Config{{Checkout: Checkout{Hooks: ptr(false)}}},
+			wantSubmodules: true,
+			wantLFS:        true,
+			wantHooks:      false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{}
+			for _, other := range tt.configs {
+				cfg.Merge(other)
+			}
+			if diff := cmp.Diff(tt.wantSubmodules, cfg.Checkout.SubmodulesEnabled()); diff != "" {
+				t.Errorf("SubmodulesEnabled mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantLFS, cfg.Checkout.LFSEnabled()); diff != "" {
+				t.Errorf("LFSEnabled mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantHooks, cfg.Checkout.HooksEnabled()); diff != "" {
+				t.Errorf("HooksEnabled mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestParseAge(t *testing.T) {
+	for name, tt := range map[string]struct {
+		age       string
+		expected  time.Duration
+		wantError bool
+	}{
+		"go duration": {
+			age:      "36h",
+			expected: 36 * time.Hour,
+		},
+		"days": {
+			age:      "3d",
+			expected: 3 * 24 * time.Hour,
+		},
+		"weeks": {
+			age:      "2w",
+			expected: 14 * 24 * time.Hour,
+		},
+		"fractional days": {
+			age:       "1.5d",
+			wantError: true,
+		},
+		"negative": {
+			age:       "-1h",
+			wantError: true,
+		},
+		"empty": {
+			age:       "",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			age, err := ParseAge(tt.age)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("ParseAge expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAge unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, age); diff != "" {
+				t.Errorf("ParseAge mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveSparse returns the directories a new worktree is limited to for the
+// --sparse value of 'giwo create': the directories of the sparse profile of
+// that name, or else the value itself as a comma-separated list, such as
+// "services/api,libs/common". Directories are relative to the repository root.
+func (c *Config) ResolveSparse(value string) ([]string, error) {
+	dirs, ok := c.Checkout.Sparse[value]
+	if !ok {
+		for _, dir := range strings.Split(value, ",") {
+			if dir = strings.TrimSpace(dir); dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no sparse directories given")
+	}
+
+	resolved := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if err := validateSparseDir(dir); err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, path.Clean(filepath.ToSlash(dir)))
+	}
+	return resolved, nil
+}
+
+// validateSparseDir checks that dir can be given to a cone-mode sparse
+// checkout: a directory within the repository, without patterns.
+func validateSparseDir(dir string) error {
+	slashed := filepath.ToSlash(dir)
+	clean := path.Clean(slashed)
+	switch {
+	case strings.TrimSpace(dir) == "":
+		return fmt.Errorf("empty sparse directory")
+	case path.IsAbs(slashed) || filepath.IsAbs(dir) || filepath.VolumeName(dir) != "":
+		return fmt.Errorf("sparse directory %q must be relative to the repository root", dir)
+	case clean == "." || clean == ".." || strings.HasPrefix(clean, "../"):
+		return fmt.Errorf("sparse directory %q must be inside the repository", dir)
+	case strings.ContainsAny(slashed, "*?[!\\"):
+		return fmt.Errorf("sparse directory %q must be a directory, not a pattern", dir)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveSparse(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{Checkout: Checkout{Sparse: map[string][]string{
+		"api": {"services/api", "libs/common/"},
+	}}}
+
+	for name, tt := range map[string]struct {
+		value     string
+		want      []string
+		wantError bool
+	}{
+		"profile": {
+			value: "api",
+			want:  []string{"services/api", "libs/common"},
+		},
+		"directory list": {
+			value: "services/web, libs/ui/",
+			want:  []string{"services/web", "libs/ui"},
+		},
+		"single directory": {
+			value: "docs",
+			want:  []string{"docs"},
+		},
+		"empty": {
+			value:     " , ",
+			wantError: true,
+		},
+		"absolute": {
+			value:     "/services/api",
+			wantError: true,
+		},
+		"outside the repository": {
+			value:     "services/../../other",
+			wantError: true,
+		},
+		"repository root": {
+			value:     "services/..",
+			wantError: true,
+		},
+		"pattern": {
+			value:     "services/*",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cfg.ResolveSparse(tt.value)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ResolveSparse() error = %v, wantError %v", err, tt.wantError)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ResolveSparse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SetupStep is a shell command run in new worktrees, such as installing dependencies.
+// Its name lets overrides replace or skip it.
+type SetupStep struct {
+	Name string `yaml:"name"`
+	Run  string `yaml:"run"`
+}
+
+// Override adjusts what new worktrees of matching branches are set up with.
+// It is keyed by a branch pattern like "release/*" in the overrides setting.
+type Override struct {
+	// Extends names another override that is applied first, whether or not its pattern matches.
+	Extends string `yaml:"extends"`
+
+	// Templates and Links are added to the inherited files and links.
+	Templates []string `yaml:"templates"`
+	Links     []string `yaml:"links"`
+
+	// Setup steps are added after the inherited ones, replacing inherited steps of the same name.
+	Setup []SetupStep `yaml:"setup"`
+
+	// Skip removes inherited setup steps by name, and templates and links by path.
+	Skip []string `yaml:"skip"`
+}
+
+// Template is what a new worktree is set up with, resolved for its branch.
+type Template struct {
+	Templates []string
+	Links     []string
+	Setup     []SetupStep
+
+	// Layers explains how the template was composed, in the order they were applied.
+	Layers []TemplateLayer
+}
+
+// TemplateLayer is one of the layers a Template was composed of.
+type TemplateLayer struct {
+	// Source is "base" or the key of the override.
+	Source string
+
+	// Reason says why the layer applies.
+	Reason string
+
+	// Changes lists what the layer changed, e.g. "+setup deps" or "-template .env".
+	Changes []string
+}
+
+// ResolveTemplate composes the template for new worktrees of branch, in order of
+// increasing precedence:
+//
+//  1. the base: templates (defaultTemplates if unset), links and setup
+//  2. the overrides whose pattern matches branch, least specific first, i.e. the
+//     ones with fewer literal characters; ties are applied in pattern order
+//  3. the override named by name, if it is set
+//
+// An override extending another is applied right after the one it extends.
+func (c *Config) ResolveTemplate(branch, name string, defaultTemplates []string) (*Template, error) {
+	t := &Template{}
+
+	base := Override{Templates: c.Templates, Links: c.Links, Setup: c.Setup}
+	if c.Templates == nil {
+		base.Templates = defaultTemplates
+	}
+	t.apply("base", "always applies", base)
+
+	var patterns []string
+	for pattern := range c.Overrides {
+		if ok, _ := path.Match(pattern, branch); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if a, b := literalLength(patterns[i]), literalLength(patterns[j]); a != b {
+			return a < b
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if err := c.applyOverride(t, pattern, "matches "+branch, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if name != "" {
+		if _, ok := c.Overrides[name]; !ok {
+			return nil, fmt.Errorf("unknown template %q", name)
+		}
+		if err := c.applyOverride(t, name, "selected with --template", nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// applyOverride applies the override with key to t after the overrides it extends.
+// chain holds the overrides being applied, to detect cycles.
+func (c *Config) applyOverride(t *Template, key, reason string, chain []string) error {
+	if slices.Contains(chain, key) {
+		return fmt.Errorf("overrides: %s extend each other", strings.Join(append(chain, key), " -> "))
+	}
+	o, ok := c.Overrides[key]
+	if !ok {
+		return fmt.Errorf("overrides.%s: extends unknown override %q", chain[len(chain)-1], key)
+	}
+	if o.Extends != "" {
+		if err := c.applyOverride(t, o.Extends, "extended by "+key, append(chain, key)); err != nil {
+			return err
+		}
+	}
+	t.apply(key, reason, o)
+	return nil
+}
+
+// apply layers o over t.
+func (t *Template) apply(source, reason string, o Override) {
+	layer := TemplateLayer{Source: source, Reason: reason}
+
+	for _, skip := range o.Skip {
+		if i := slices.IndexFunc(t.Setup, func(s SetupStep) bool { return s.Name == skip }); i >= 0 {
+			t.Setup = slices.Delete(t.Setup, i, i+1)
+			layer.Changes = append(layer.Changes, "-setup "+skip)
+		}
+		if i := slices.Index(t.Templates, skip); i >= 0 {
+			t.Templates = slices.Delete(t.Templates, i, i+1)
+			layer.Changes = append(layer.Changes, "-template "+skip)
+		}
+		if i := slices.Index(t.Links, skip); i >= 0 {
+			t.Links = slices.Delete(t.Links, i, i+1)
+			layer.Changes = append(layer.Changes, "-link "+skip)
+		}
+	}
+	for _, file := range o.Templates {
+		if !slices.Contains(t.Templates, file) {
+			t.Templates = append(t.Templates, file)
+			layer.Changes = append(layer.Changes, "+template "+file)
+		}
+	}
+	for _, link := range o.Links {
+		if !slices.Contains(t.Links, link) {
+			t.Links = append(t.Links, link)
+			layer.Changes = append(layer.Changes, "+link "+link)
+		}
+	}
+	for _, step := range o.Setup {
+		if i := slices.IndexFunc(t.Setup, func(s SetupStep) bool { return s.Name == step.Name }); i >= 0 {
+			t.Setup[i] = step
+			layer.Changes = append(layer.Changes, "~setup "+step.Name)
+			continue
+		}
+		t.Setup = append(t.Setup, step)
+		layer.Changes = append(layer.Changes, "+setup "+step.Name)
+	}
+
+	t.Layers = append(t.Layers, layer)
+}
+
+// literalLength returns the number of characters of a branch pattern that are
+// not wildcards, which measures how specific it is.
+func literalLength(pattern string) int {
+	n := 0
+	for _, r := range pattern {
+		if !strings.ContainsRune("*?[]", r) {
+			n++
+		}
+	}
+	return n
+}
+
+// validateSetup checks that every step of a setup list has a unique name and a command.
+func validateSetup(steps []SetupStep) error {
+	seen := make(map[string]bool, len(steps))
+	for i, step := range steps {
+		if step.Name == "" || step.Run == "" {
+			return fmt.Errorf("step %d needs a name and a run command", i+1)
+		}
+		if seen[step.Name] {
+			return fmt.Errorf("step %q is defined twice", step.Name)
+		}
+		seen[step.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveTemplate(t *testing.T) {
+	cfg := &Config{
+		Links: []string{"node_modules"},
+		Setup: []SetupStep{{Name: "deps", Run: "npm ci"}, {Name: "db", Run: "make db"}},
+		Overrides: map[string]Override{
+			"release/*":   {Setup: []SetupStep{{Name: "changelog", Run: "make changelog"}}},
+			"release/1.*": {Setup: []SetupStep{{Name: "deps", Run: "npm ci --legacy"}}},
+			"docs/*":      {Extends: "light", Templates: []string{"mkdocs.yml"}},
+			"light":       {Skip: []string{"deps", "db", "node_modules"}},
+			"loop-a":      {Extends: "loop-b"},
+			"loop-b":      {Extends: "loop-a"},
+			"broken":      {Extends: "missing"},
+		},
+	}
+
+	for name, tt := range map[string]struct {
+		branch    string
+		template  string
+		expected  *Template
+		wantError bool
+	}{
+		"base only": {
+			branch: "feature",
+			expected: &Template{
+				Templates: []string{".env"},
+				Links:     []string{"node_modules"},
+				Setup:     []SetupStep{{Name: "deps", Run: "npm ci"}, {Name: "db", Run: "make db"}},
+			},
+		},
+		"more specific pattern wins": {
+			branch: "release/1.2",
+			expected: &Template{
+				Templates: []string{".env"},
+				Links:     []string{"node_modules"},
+				Setup: []SetupStep{
+					{Name: "deps", Run: "npm ci --legacy"},
+					{Name: "db", Run: "make db"},
+					{Name: "changelog", Run: "make changelog"},
+				},
+			},
+		},
+		"extends and skip": {
+			branch: "docs/readme",
+			expected: &Template{
+				Templates: []string{".env", "mkdocs.yml"},
+				Links:     []string{},
+				Setup:     []SetupStep{},
+			},
+		},
+		"selected template applies last": {
+			branch:   "release/2.0",
+			template: "light",
+			expected: &Template{
+				Templates: []string{".env"},
+				Links:     []string{},
+				Setup:     []SetupStep{{Name: "changelog", Run: "make changelog"}},
+			},
+		},
+		"unknown template": {
+			branch:    "feature",
+			template:  "missing",
+			wantError: true,
+		},
+		"cycle": {
+			branch:    "feature",
+			template:  "loop-a",
+			wantError: true,
+		},
+		"unknown extends": {
+			branch:    "feature",
+			template:  "broken",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cfg.ResolveTemplate(tt.branch, tt.template, []string{".env"})
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTemplate failed: %v", err)
+			}
+
+			got.Layers = nil
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("ResolveTemplate mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveTemplateLayers(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Templates: []string{".env"},
+		Overrides: map[string]Override{
+			"docs/*": {Extends: "light", Templates: []string{"mkdocs.yml"}},
+			"light":  {Skip: []string{".env"}},
+		},
+	}
+
+	got, err := cfg.ResolveTemplate("docs/readme", "", nil)
+	if err != nil {
+		t.Fatalf("ResolveTemplate failed: %v", err)
+	}
+
+	expected := []TemplateLayer{
+		{Source: "base", Reason: "always applies", Changes: []string{"+template .env"}},
+		{Source: "light", Reason: "extended by docs/*", Changes: []string{"-template .env"}},
+		{Source: "docs/*", Reason: "matches docs/readme", Changes: []string{"+template mkdocs.yml"}},
+	}
+	if diff := cmp.Diff(expected, got.Layers); diff != "" {
+		t.Errorf("layers mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettleTime is how long a Watcher waits for changes to settle before
+// reloading, so that an editor saving a file in several steps reloads once.
+const watchSettleTime = 100 * time.Millisecond
+
+// Watcher reloads the configuration when one of its files changes, so that
+// long-running commands apply new settings without being restarted.
+// Changes are noticed through file notifications on the directories of the
+// files, which keep working when editors replace a file instead of writing it.
+type Watcher struct {
+	paths  []string
+	load   func() (*Config, error)
+	stamps []fileStamp
+}
+
+// fileStamp identifies a version of a file; the zero value stands for a missing file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher creates a watcher calling load when one of paths, usually those
+// returned by Paths, is created, changed or removed.
+func NewWatcher(paths []string, load func() (*Config, error)) *Watcher {
+	w := &Watcher{paths: make([]string, len(paths)), load: load}
+	for i, path := range paths {
+		w.paths[i] = filepath.Clean(path)
+	}
+	w.stamps = w.stat()
+	return w
+}
+
+// Watch calls fn with the reloaded configuration after each change until ctx is done.
+// If the files do not make a valid configuration, for example while one is only
+// half written, fn gets the error instead, and callers should keep the settings
+// they have until the next change fixes it. Without file notifications, such as
+// when the system is out of them, Watch returns right away and nothing is reloaded.
+func (w *Watcher) Watch(ctx context.Context, fn func(*Config, error)) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+	defer notify.Close()
+	w.watchDirs(notify)
+
+	// Changes made since NewWatcher, before the directories were watched
+	settle := time.After(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-notify.Events:
+			if w.concerns(event.Name) {
+				// Directories of the files may have come or gone
+				w.watchDirs(notify)
+				settle = time.After(watchSettleTime)
+			}
+			continue
+		case <-notify.Errors:
+			// Notifications were dropped, so any file may have changed
+			w.watchDirs(notify)
+		case <-settle:
+		}
+		settle = nil
+
+		if !w.changed() {
+			continue
+		}
+		fn(w.load())
+	}
+}
+
+// watchDirs watches the directory of each file, or the closest of its parents
+// that exists, to notice the directory being created.
+func (w *Watcher) watchDirs(notify *fsnotify.Watcher) {
+	for _, path := range w.paths {
+		dir := filepath.Dir(path)
+		for notify.Add(dir) != nil {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+}
+
+// concerns reports whether a notification about name may concern one of the
+// files: it is one of them or one of their parent directories.
+func (w *Watcher) concerns(name string) bool {
+	name = filepath.Clean(name)
+	for _, path := range w.paths {
+		if path == name || strings.HasPrefix(path, name+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// changed reports whether a file changed since the last check.
+func (w *Watcher) changed() bool {
+	stamps := w.stat()
+	changed := false
+	for i := range stamps {
+		if !stamps[i].modTime.Equal(w.stamps[i].modTime) || stamps[i].size != w.stamps[i].size {
+			changed = true
+		}
+	}
+	w.stamps = stamps
+	return changed
+}
+
+// stat returns the current stamps of the files.
+func (w *Watcher) stat() []fileStamp {
+	stamps := make([]fileStamp, len(w.paths))
+	for i, path := range w.paths {
+		if info, err := os.Stat(path); err == nil {
+			stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWatcher(t *testing.T) {
+	for name, tt := range map[string]struct {
+		dir string // of the file, below a temporary directory
+	}{
+		"existing directory":           {dir: "."},
+		"directory created afterwards": {dir: "giwo/nested"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			path := filepath.Join(root, tt.dir, "config.yaml")
+			write := func(content string) {
+				t.Helper()
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			watcher := NewWatcher([]string{path}, func() (*Config, error) { return loadOptional(path) })
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			type reload struct {
+				editor string
+				failed bool
+			}
+			reloads := make(chan reload)
+			go watcher.Watch(ctx, func(cfg *Config, err error) {
+				if err != nil {
+					reloads <- reload{failed: true}
+					return
+				}
+				reloads <- reload{editor: cfg.Editor}
+			})
+
+			next := func() reload {
+				t.Helper()
+				select {
+				case r := <-reloads:
+					return r
+				case <-time.After(5 * time.Second):
+					t.Fatal("configuration was not reloaded")
+					return reload{}
+				}
+			}
+
+			// Created, broken, fixed and removed files are all picked up
+			for _, step := range []struct {
+				content  string
+				expected reload
+			}{
+				{content: "editor: vim\n", expected: reload{editor: "vim"}},
+				{content: "editor: [vim\n", expected: reload{failed: true}},
+				{content: "editor: nvim\n", expected: reload{editor: "nvim"}},
+				{expected: reload{}},
+			} {
+				if step.content == "" {
+					if err := os.Remove(path); err != nil {
+						t.Fatal(err)
+					}
+				} else {
+					write(step.content)
+				}
+				if diff := cmp.Diff(step.expected, next(), cmp.AllowUnexported(reload{})); diff != "" {
+					t.Errorf("reload mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
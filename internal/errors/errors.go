@@ -11,10 +11,21 @@ var (
 	ErrNotGitRepository     = errors.New("not in a git repository")
 	ErrWorktreeExists       = errors.New("worktree already exists")
 	ErrWorktreeNotFound     = errors.New("worktree not found")
+	ErrWorktreeLocked       = errors.New("worktree is locked")
+	ErrWorktreeDirty        = errors.New("worktree has uncommitted changes")
+	ErrWorktreeAmbiguous    = errors.New("ambiguous worktree")
 	ErrBranchNotFound       = errors.New("branch not found")
+	ErrBranchExists         = errors.New("branch already exists")
+	ErrBranchCheckedOut     = errors.New("branch already checked out")
+	ErrDetachedHead         = errors.New("detached HEAD")
+	ErrArchiveNotFound      = errors.New("archive not found")
+	ErrArchiveExists        = errors.New("archive already exists")
+	ErrSnapshotNotFound     = errors.New("snapshot not found")
+	ErrNoBisect             = errors.New("no bisect session running")
 	ErrInvalidBranchName    = errors.New("invalid branch name")
 	ErrGitHubAPIUnavailable = errors.New("github API unavailable")
 	ErrOperationCancelled   = errors.New("operation cancelled by user")
+	ErrCreateRolledBack     = errors.New("worktree creation rolled back")
 )
 
 // ValidationError represents a validation error with details.
@@ -51,6 +62,28 @@ func (e *GitError) Unwrap() error {
 	return e.Err
 }
 
+// BranchCheckedOutError reports that a branch cannot be checked out because
+// another worktree has it checked out already.
+type BranchCheckedOutError struct {
+	Branch string
+
+	// Path is the worktree the branch is checked out in.
+	Path string
+
+	// Err is the underlying git failure.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *BranchCheckedOutError) Error() string {
+	return fmt.Sprintf("branch %s is already checked out in %s", e.Branch, e.Path)
+}
+
+// Unwrap returns ErrBranchCheckedOut and the underlying error.
+func (e *BranchCheckedOutError) Unwrap() []error {
+	return []error{ErrBranchCheckedOut, e.Err}
+}
+
 // NewValidationError creates a new validation error.
 func NewValidationError(field, value string, err error) *ValidationError {
 	return &ValidationError{
@@ -78,3 +78,20 @@ func TestGitError(t *testing.T) {
 		})
 	}
 }
+
+func TestBranchCheckedOutError(t *testing.T) {
+	t.Parallel()
+
+	gitErr := errors.New("exit status 128")
+	err := error(&BranchCheckedOutError{Branch: "main", Path: "/repo", Err: gitErr})
+
+	if diff := cmp.Diff("branch main is already checked out in /repo", err.Error()); diff != "" {
+		t.Errorf("Error() mismatch (-want +got):\n%s", diff)
+	}
+	if !errors.Is(err, ErrBranchCheckedOut) {
+		t.Errorf("Expected error to wrap %v", ErrBranchCheckedOut)
+	}
+	if !errors.Is(err, gitErr) {
+		t.Errorf("Expected error to wrap %v", gitErr)
+	}
+}
@@ -0,0 +1,236 @@
+// Package history records the worktrees the user created, switched to and
+// removed, across repositories, so that they can be found and visited again.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileName is the name of the history file inside the state directory.
+const fileName = "history.jsonl"
+
+// maxEntries is the number of entries kept once the history is trimmed.
+const maxEntries = 1000
+
+// trimSize is the file size from which Record trims the history, well above
+// maxEntries entries of typical size so that trimming is rare.
+const trimSize = 2 * maxEntries * 200
+
+// Action is what the user did with a worktree.
+type Action string
+
+// Actions recorded in the history.
+const (
+	Created  Action = "create"
+	Switched Action = "switch"
+	Removed  Action = "remove"
+)
+
+// Entry is something the user did with a worktree.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+
+	// Repo is the root of the repository's main worktree.
+	Repo string `json:"repo"`
+
+	Path   string `json:"path"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// Path returns the history file in stateDir.
+func Path(stateDir string) string {
+	return filepath.Join(stateDir, fileName)
+}
+
+// Record appends e to the history in stateDir. Once the file has grown large,
+// it is trimmed to the most recent entries.
+func Record(stateDir string, e Entry) error {
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	path := Path(stateDir)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() > trimSize {
+		return trim(path)
+	}
+	return nil
+}
+
+// trim rewrites the history at path with its most recent maxEntries entries.
+func trim(path string) error {
+	entries, err := read(path)
+	if err != nil {
+		return err
+	}
+	if len(entries) <= maxEntries {
+		return nil
+	}
+	return write(path, entries[len(entries)-maxEntries:])
+}
+
+// Compact rewrites the history in stateDir with its most recent maxEntries
+// entries, dropping lines that cannot be parsed, and returns the number of
+// lines dropped.
+func Compact(stateDir string) (int, error) {
+	path := Path(stateDir)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read history: %w", err)
+	}
+	entries, err := read(path)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := entries[max(len(entries)-maxEntries, 0):]
+	dropped := bytes.Count(data, []byte("\n")) - len(kept)
+	if dropped <= 0 {
+		return 0, nil
+	}
+	return dropped, write(path, kept)
+}
+
+// write replaces the history at path with entries.
+func write(path string, entries []Entry) error {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to trim history: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load returns the history in stateDir, oldest entry first. It returns no
+// entries if there is no history yet.
+func Load(stateDir string) ([]Entry, error) {
+	entries, err := read(Path(stateDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// read parses the history file at path. Lines that cannot be parsed, such as
+// one cut short by a crash, are skipped.
+func read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// Query selects history entries. Zero values select everything.
+type Query struct {
+	// Action keeps only entries of this action.
+	Action Action
+
+	// Repo keeps only entries of this repository.
+	Repo string
+
+	// Since keeps only entries recorded at or after this time.
+	Since time.Time
+
+	// Text keeps only entries whose branch or path contains it, ignoring case.
+	Text string
+}
+
+// Filter returns the entries matching q, most recent first.
+func Filter(entries []Entry, q Query) []Entry {
+	text := strings.ToLower(q.Text)
+
+	var matched []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		switch {
+		case q.Action != "" && e.Action != q.Action,
+			q.Repo != "" && e.Repo != q.Repo,
+			!q.Since.IsZero() && e.Time.Before(q.Since),
+			text != "" && !strings.Contains(strings.ToLower(e.Branch), text) && !strings.Contains(strings.ToLower(e.Path), text):
+			continue
+		}
+		matched = append(matched, e)
+	}
+	return matched
+}
+
+// Page returns the entries of the given page, counting from 1, with size
+// entries per page, and the number of pages.
+func Page(entries []Entry, page, size int) ([]Entry, int) {
+	if size <= 0 {
+		return entries, 1
+	}
+	pages := max((len(entries)+size-1)/size, 1)
+	start := (page - 1) * size
+	if page < 1 || start >= len(entries) {
+		return nil, pages
+	}
+	return entries[start:min(start+size, len(entries))], pages
+}
+
+// Visited returns the worktrees the user created or switched to, as their
+// most recent entry, most recent first. The worktree at current is left out,
+// so the first entry is the one to go back to, as with 'cd -'.
+func Visited(entries []Entry, current string) []Entry {
+	seen := map[string]bool{current: true}
+	var visited []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if (e.Action != Created && e.Action != Switched) || seen[e.Path] {
+			continue
+		}
+		seen[e.Path] = true
+		visited = append(visited, e)
+	}
+	return visited
+}
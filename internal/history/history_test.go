@@ -0,0 +1,221 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecordLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "state")
+	entries, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff(0, len(entries)); diff != "" {
+		t.Errorf("entries before recording mismatch (-want +got):\n%s", diff)
+	}
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expected := []Entry{
+		{Time: now, Action: Created, Repo: "/src/api", Path: "/src/api/.worktree/feat", Branch: "feat"},
+		{Time: now.Add(time.Minute), Action: Switched, Repo: "/src/api", Path: "/src/api/.worktree/feat", Branch: "feat"},
+	}
+	for _, e := range expected {
+		if err := Record(dir, e); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	// A line cut short by a crash does not spoil the rest
+	f, err := os.OpenFile(filepath.Join(dir, fileName), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"time":"2025-06`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	entries, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff(expected, entries); diff != "" {
+		t.Errorf("Load mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := range maxEntries + 10 {
+		if err := Record(dir, Entry{Time: start.Add(time.Duration(i) * time.Minute), Action: Switched, Path: "/p"}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if err := trim(filepath.Join(dir, fileName)); err != nil {
+		t.Fatalf("trim failed: %v", err)
+	}
+
+	entries, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff(maxEntries, len(entries)); diff != "" {
+		t.Errorf("entry count mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(start.Add(10*time.Minute), entries[0].Time); diff != "" {
+		t.Errorf("oldest kept entry mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		if err := Record(dir, Entry{Time: start.Add(time.Duration(i) * time.Minute), Action: Switched, Path: "/p"}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	f, err := os.OpenFile(filepath.Join(dir, fileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{\"time\": \"2025-06\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	dropped, err := Compact(dir)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if diff := cmp.Diff(1, dropped); diff != "" {
+		t.Errorf("dropped lines mismatch (-want +got):\n%s", diff)
+	}
+	entries, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff(3, len(entries)); diff != "" {
+		t.Errorf("entry count mismatch (-want +got):\n%s", diff)
+	}
+
+	if dropped, err := Compact(dir); err != nil || dropped != 0 {
+		t.Errorf("Compact of a compact history = %d, %v; want 0, nil", dropped, err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: now.Add(-48 * time.Hour), Action: Created, Repo: "/src/api", Path: "/src/api/.worktree/feat", Branch: "feat"},
+		{Time: now.Add(-2 * time.Hour), Action: Switched, Repo: "/src/web", Path: "/src/web/.worktree/Login-Fix", Branch: "Login-Fix"},
+		{Time: now.Add(-time.Hour), Action: Switched, Repo: "/src/api", Path: "/src/api/.worktree/feat", Branch: "feat"},
+		{Time: now, Action: Removed, Repo: "/src/api", Path: "/src/api/.worktree/feat", Branch: "feat"},
+	}
+
+	for name, tt := range map[string]struct {
+		query    Query
+		expected []int
+	}{
+		"everything, most recent first": {expected: []int{3, 2, 1, 0}},
+		"action":                        {query: Query{Action: Switched}, expected: []int{2, 1}},
+		"repository":                    {query: Query{Repo: "/src/web"}, expected: []int{1}},
+		"since":                         {query: Query{Since: now.Add(-3 * time.Hour)}, expected: []int{3, 2, 1}},
+		"text ignores case":             {query: Query{Text: "login"}, expected: []int{1}},
+		"text matches path":             {query: Query{Text: "src/api"}, expected: []int{3, 2, 0}},
+		"combined":                      {query: Query{Action: Switched, Repo: "/src/api"}, expected: []int{2}},
+		"nothing":                       {query: Query{Text: "nope"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var expected []Entry
+			for _, i := range tt.expected {
+				expected = append(expected, entries[i])
+			}
+			if diff := cmp.Diff(expected, Filter(entries, tt.query)); diff != "" {
+				t.Errorf("Filter mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVisited(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: now.Add(-4 * time.Hour), Action: Created, Repo: "/src/api", Path: "/src/api/.worktree/feat", Branch: "feat"},
+		{Time: now.Add(-3 * time.Hour), Action: Switched, Repo: "/src/api", Path: "/src/api", Branch: "main"},
+		{Time: now.Add(-2 * time.Hour), Action: Switched, Repo: "/src/web", Path: "/src/web/.worktree/fix", Branch: "fix"},
+		{Time: now.Add(-time.Hour), Action: Switched, Repo: "/src/api", Path: "/src/api/.worktree/feat", Branch: "feat"},
+		{Time: now, Action: Removed, Repo: "/src/web", Path: "/src/web/.worktree/fix", Branch: "fix"},
+	}
+
+	for name, tt := range map[string]struct {
+		current  string
+		expected []int
+	}{
+		"outside any worktree":         {expected: []int{3, 2, 1}},
+		"in the last visited worktree": {current: "/src/api/.worktree/feat", expected: []int{2, 1}},
+		"in an older visited worktree": {current: "/src/api", expected: []int{3, 2}},
+		"removals are not visits":      {current: "/src/web/.worktree/fix", expected: []int{3, 1}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var expected []Entry
+			for _, i := range tt.expected {
+				expected = append(expected, entries[i])
+			}
+			if diff := cmp.Diff(expected, Visited(entries, tt.current)); diff != "" {
+				t.Errorf("Visited mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	entries := make([]Entry, 5)
+	for i := range entries {
+		entries[i].Branch = string(rune('a' + i))
+	}
+
+	for name, tt := range map[string]struct {
+		page, size    int
+		expected      string
+		expectedPages int
+	}{
+		"first page":       {page: 1, size: 2, expected: "ab", expectedPages: 3},
+		"last page":        {page: 3, size: 2, expected: "e", expectedPages: 3},
+		"past the end":     {page: 4, size: 2, expectedPages: 3},
+		"before the start": {page: 0, size: 2, expectedPages: 3},
+		"no paging":        {page: 1, size: 0, expected: "abcde", expectedPages: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, pages := Page(entries, tt.page, tt.size)
+			var branches string
+			for _, e := range got {
+				branches += e.Branch
+			}
+			if diff := cmp.Diff(tt.expected, branches); diff != "" {
+				t.Errorf("Page(%d, %d) entries mismatch (-want +got):\n%s", tt.page, tt.size, diff)
+			}
+			if diff := cmp.Diff(tt.expectedPages, pages); diff != "" {
+				t.Errorf("Page(%d, %d) pages mismatch (-want +got):\n%s", tt.page, tt.size, diff)
+			}
+		})
+	}
+}
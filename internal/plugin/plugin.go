@@ -0,0 +1,84 @@
+// Package plugin finds external giwo subcommands: executables named giwo-<name>
+// on PATH, run for 'giwo <name>' the way git runs git-<name>.
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix starts the file name of every plugin executable.
+const Prefix = "giwo-"
+
+// Plugin is a plugin executable found on PATH.
+type Plugin struct {
+	// Name is the subcommand the plugin provides, its file name without Prefix.
+	Name string
+	Path string
+
+	// Shadowed is set when a plugin of the same name comes earlier on PATH and is run instead.
+	Shadowed bool
+}
+
+// Lookup returns the path of the plugin providing the subcommand name.
+func Lookup(name string) (string, error) {
+	return exec.LookPath(Prefix + name)
+}
+
+// List returns the plugins in the directories of pathList, a PATH-style list,
+// sorted by name and then in PATH order.
+func List(pathList string) []Plugin {
+	var plugins []Plugin
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			plugins = append(plugins, Plugin{Name: name, Path: path, Shadowed: seen[name]})
+			seen[name] = true
+		}
+	}
+
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// pluginName returns the subcommand a file provides if it is a plugin.
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	name, ok := strings.CutPrefix(file, Prefix)
+	return name, ok && name != ""
+}
+
+// isExecutable reports whether path is a regular file that can be executed.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// exec.LookPath decides by extension, honoring PATHEXT
+		_, err := exec.LookPath(path)
+		return err == nil
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
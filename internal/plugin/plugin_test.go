@@ -0,0 +1,44 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are told apart by extension on Windows")
+	}
+	t.Parallel()
+
+	first, second := t.TempDir(), t.TempDir()
+	for path, mode := range map[string]os.FileMode{
+		filepath.Join(first, "giwo-lint"):    0o755,
+		filepath.Join(first, "giwo-notes"):   0o644,
+		filepath.Join(first, "git-giwo"):     0o755,
+		filepath.Join(first, "giwo-"):        0o755,
+		filepath.Join(second, "giwo-lint"):   0o755,
+		filepath.Join(second, "giwo-deploy"): 0o755,
+	} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(second, "giwo-dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Plugin{
+		{Name: "deploy", Path: filepath.Join(second, "giwo-deploy")},
+		{Name: "lint", Path: filepath.Join(first, "giwo-lint")},
+		{Name: "lint", Path: filepath.Join(second, "giwo-lint"), Shadowed: true},
+	}
+	got := List(first + string(os.PathListSeparator) + filepath.Join(first, "missing") + string(os.PathListSeparator) + second)
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("List mismatch (-want +got):\n%s", diff)
+	}
+}
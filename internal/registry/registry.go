@@ -0,0 +1,132 @@
+// Package registry keeps track of the repositories giwo manages in cross-repo mode.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fileName is the name of the registry file inside the state directory.
+const fileName = "repos.json"
+
+// maxDiscoveryDepth limits how deep Discover descends below a root.
+const maxDiscoveryDepth = 3
+
+// Registry is the set of repositories explicitly added with 'giwo repo add'.
+type Registry struct {
+	path  string
+	Repos []string `json:"repos"`
+}
+
+// Load reads the registry from stateDir, returning an empty registry if none exists yet.
+func Load(stateDir string) (*Registry, error) {
+	r := &Registry{path: filepath.Join(stateDir, fileName)}
+
+	data, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository registry: %w", err)
+	}
+
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse repository registry: %w", err)
+	}
+	return r, nil
+}
+
+// Save writes the registry to disk.
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode repository registry: %w", err)
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+// Add registers a repository root. It reports whether the repository was newly added.
+func (r *Registry) Add(repoRoot string) bool {
+	for _, repo := range r.Repos {
+		if repo == repoRoot {
+			return false
+		}
+	}
+	r.Repos = append(r.Repos, repoRoot)
+	sort.Strings(r.Repos)
+	return true
+}
+
+// Remove unregisters a repository given its root or name. It reports whether one was removed.
+func (r *Registry) Remove(repo string) bool {
+	for i, existing := range r.Repos {
+		if existing == repo || Name(existing) == repo {
+			r.Repos = append(r.Repos[:i], r.Repos[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// All returns the registered repositories together with those discovered under roots,
+// without duplicates and in a stable order.
+func (r *Registry) All(roots []string) []string {
+	seen := make(map[string]bool)
+	var repos []string
+	for _, repo := range append(append([]string{}, r.Repos...), Discover(roots)...) {
+		if !seen[repo] {
+			seen[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// Name returns the short name of a repository used to prefix its worktrees.
+func Name(repoRoot string) string {
+	return filepath.Base(repoRoot)
+}
+
+// Discover finds repositories below roots. Directories named .worktree and
+// nested worktrees are skipped, and discovery stops at each repository found.
+func Discover(roots []string) []string {
+	var repos []string
+	for _, root := range roots {
+		repos = append(repos, discover(root, 0)...)
+	}
+	return repos
+}
+
+// discover recursively looks for repositories in dir.
+func discover(dir string, depth int) []string {
+	// Only a .git directory marks a main repository; linked worktrees have a .git file
+	if info, err := os.Stat(filepath.Join(dir, ".git")); err == nil && info.IsDir() {
+		return []string{dir}
+	}
+	if depth >= maxDiscoveryDepth {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var repos []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == "node_modules" {
+			continue
+		}
+		repos = append(repos, discover(filepath.Join(dir, entry.Name()), depth+1)...)
+	}
+	return repos
+}
@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, dir := range []string{
+		"api/.git",
+		"team/web/.git",
+		"team/web/.worktree/feature",
+		"notes",
+		"a/b/c/deep/.git",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		filepath.Join(root, "api"),
+		filepath.Join(root, "team/web"),
+	}
+	if diff := cmp.Diff(expected, Discover([]string{root})); diff != "" {
+		t.Errorf("Discover mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	t.Parallel()
+
+	r, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !r.Add("/src/api") {
+		t.Error("expected /src/api to be added")
+	}
+	if r.Add("/src/api") {
+		t.Error("expected duplicate add to be ignored")
+	}
+	r.Add("/src/web")
+
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if !r.Remove("web") {
+		t.Error("expected web to be removed by name")
+	}
+	if diff := cmp.Diff([]string{"/src/api"}, r.Repos); diff != "" {
+		t.Errorf("Repos mismatch (-want +got):\n%s", diff)
+	}
+}
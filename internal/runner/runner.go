@@ -0,0 +1,169 @@
+// Package runner executes commands inside worktrees, locally or on a remote host.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// Runner runs a command in a worktree directory, streaming its output.
+type Runner interface {
+	Run(ctx context.Context, dir string, command []string, stdout, stderr io.Writer) error
+}
+
+// Local runs commands on this machine.
+type Local struct {
+	// Env are variables, as "NAME=value", added to the environment of giwo.
+	Env []string
+
+	// Stdin is the command's input. Commands get no input by default.
+	Stdin io.Reader
+}
+
+// Run implements Runner.
+func (l Local) Run(ctx context.Context, dir string, command []string, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = l.Stdin
+	if len(l.Env) > 0 {
+		cmd.Env = append(os.Environ(), l.Env...)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// SSH runs commands on a remote host sharing the repository layout.
+// Local worktree paths are translated to remote ones through PathMap.
+type SSH struct {
+	// Target is the ssh destination, e.g. "me@buildbox".
+	Target string
+
+	// PathMap maps local path prefixes to their remote counterparts.
+	PathMap map[string]string
+}
+
+// Run implements Runner.
+func (s SSH) Run(ctx context.Context, dir string, command []string, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, "ssh", s.Args(dir, command)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// Args returns the ssh arguments running command in the remote counterpart of dir.
+func (s SSH) Args(dir string, command []string) []string {
+	remote := "cd " + utils.ShellQuote(s.MapPath(dir)) + " && " + utils.ShellJoin(command)
+	return []string{"-T", s.Target, "--", remote}
+}
+
+// MapPath translates a local path to the remote one using the longest matching prefix.
+// Paths without a matching prefix are used unchanged.
+func (s SSH) MapPath(path string) string {
+	prefixes := make([]string, 0, len(s.PathMap))
+	for prefix := range s.PathMap {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		clean := filepath.Clean(prefix)
+		if path == clean || strings.HasPrefix(path, clean+string(filepath.Separator)) {
+			return s.PathMap[prefix] + strings.TrimPrefix(path, clean)
+		}
+	}
+	return path
+}
+
+// PrefixWriter prefixes every line written to it, so that the output of commands
+// running in several worktrees can be told apart.
+type PrefixWriter struct {
+	w         io.Writer
+	prefix    string
+	lineStart bool
+
+	// mu is set for writers shared by concurrent commands, which write whole
+	// lines only, holding back a partial line in pending.
+	mu      *sync.Mutex
+	pending []byte
+}
+
+// NewPrefixWriter returns a writer prefixing each line written to w with prefix.
+func NewPrefixWriter(w io.Writer, prefix string) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: prefix, lineStart: true}
+}
+
+// NewLinePrefixWriter returns a writer prefixing each line written to w with
+// prefix, for commands running concurrently. It writes whole lines to w while
+// holding mu, shared by the writers of all commands, so that lines of their
+// output are never mixed up. A last line without newline is written by Flush.
+func NewLinePrefixWriter(w io.Writer, prefix string, mu *sync.Mutex) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: prefix, lineStart: true, mu: mu}
+}
+
+// Write implements io.Writer.
+func (p *PrefixWriter) Write(b []byte) (int, error) {
+	if p.mu == nil {
+		return p.write(b)
+	}
+
+	p.pending = append(p.pending, b...)
+	end := bytes.LastIndexByte(p.pending, '\n')
+	if end < 0 {
+		return len(b), nil
+	}
+	lines := p.pending[:end+1]
+	p.pending = append([]byte(nil), p.pending[end+1:]...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.write(lines); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Flush writes a partial last line held back by a writer from
+// NewLinePrefixWriter, ending it with a newline.
+func (p *PrefixWriter) Flush() error {
+	if p.mu == nil || len(p.pending) == 0 {
+		return nil
+	}
+	line := append(p.pending, '\n')
+	p.pending = nil
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.write(line)
+	return err
+}
+
+// write writes b to w, prefixing each line.
+func (p *PrefixWriter) write(b []byte) (int, error) {
+	var out strings.Builder
+	for _, c := range string(b) {
+		if p.lineStart {
+			out.WriteString(p.prefix)
+			p.lineStart = false
+		}
+		out.WriteRune(c)
+		if c == '\n' {
+			p.lineStart = true
+		}
+	}
+
+	if _, err := io.WriteString(p.w, out.String()); err != nil {
+		return 0, fmt.Errorf("failed to write output: %w", err)
+	}
+	return len(b), nil
+}
@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSSHMapPath(t *testing.T) {
+	ssh := SSH{
+		Target: "buildbox",
+		PathMap: map[string]string{
+			"/Users/me/src":     "/home/me/src",
+			"/Users/me/src/big": "/data/big",
+		},
+	}
+
+	for name, tt := range map[string]struct {
+		path     string
+		expected string
+	}{
+		"mapped prefix":         {"/Users/me/src/api/.worktree/feat", "/home/me/src/api/.worktree/feat"},
+		"longest prefix wins":   {"/Users/me/src/big/.worktree/x", "/data/big/.worktree/x"},
+		"exact match":           {"/Users/me/src", "/home/me/src"},
+		"partial name no match": {"/Users/me/srcs/api", "/Users/me/srcs/api"},
+		"unmapped":              {"/opt/repo", "/opt/repo"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.expected, ssh.MapPath(tt.path)); diff != "" {
+				t.Errorf("MapPath(%q) mismatch (-want +got):\n%s", tt.path, diff)
+			}
+		})
+	}
+}
+
+func TestSSHArgs(t *testing.T) {
+	t.Parallel()
+
+	ssh := SSH{Target: "me@buildbox", PathMap: map[string]string{"/local": "/remote"}}
+	args := ssh.Args("/local/my repo", []string{"make", "-j"})
+
+	expected := []string{"-T", "me@buildbox", "--", "cd '/remote/my repo' && make -j"}
+	if diff := cmp.Diff(expected, args); diff != "" {
+		t.Errorf("Args mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	w := NewPrefixWriter(&b, "[api] ")
+	for _, chunk := range []string{"building", "...\ndone\n", "ok"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff("[api] building...\n[api] done\n[api] ok", b.String()); diff != "" {
+		t.Errorf("PrefixWriter mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLinePrefixWriter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		b  strings.Builder
+		mu sync.Mutex
+	)
+	api := NewLinePrefixWriter(&b, "[api] ", &mu)
+	web := NewLinePrefixWriter(&b, "[web] ", &mu)
+	for _, write := range []struct {
+		w     *PrefixWriter
+		chunk string
+	}{
+		{api, "building"},
+		{web, "starting\n"},
+		{api, "...\ndone\n"},
+		{web, "listening on"},
+		{api, "ok"},
+	} {
+		if _, err := write.w.Write([]byte(write.chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, w := range []*PrefixWriter{api, web} {
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := "[web] starting\n[api] building...\n[api] done\n[api] ok\n[web] listening on\n"
+	if diff := cmp.Diff(expected, b.String()); diff != "" {
+		t.Errorf("LinePrefixWriter mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,346 @@
+// Package server exposes worktree operations to editor and IDE plugins as a
+// small JSON API over HTTP on a unix socket, so that a picker can query giwo
+// on every keystroke without starting a process each time.
+//
+// The API is versioned under /v1:
+//
+//	GET  /v1/version              the version of giwo
+//	GET  /v1/worktrees[?fast=1]   the worktrees; fast uses their cached status
+//	POST /v1/create               create a worktree: {"branch", "base", "force"}
+//	POST /v1/remove               remove a worktree: {"name", "force", "keep_branch"}
+//	GET  /v1/switch-path?filter=  the worktree to switch to, recorded as a visit
+//	GET  /v1/events               a stream of events, one JSON object per line
+//
+// Errors are returned as {"error": "...", "code": "..."} with a matching status.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// CreateRequest is the body of POST /v1/create.
+type CreateRequest struct {
+	Branch string `json:"branch"`
+
+	// Base is the branch the new one starts from. If empty, it is chosen as
+	// 'giwo create' does: the default branch of a pristine main worktree, or
+	// the current branch.
+	Base string `json:"base,omitempty"`
+
+	Force bool `json:"force,omitempty"`
+}
+
+// RemoveRequest is the body of POST /v1/remove.
+type RemoveRequest struct {
+	// Name is the worktree's directory in the worktree directory.
+	Name string `json:"name"`
+
+	// Force removes the worktree even with uncommitted changes, which are lost.
+	Force bool `json:"force,omitempty"`
+
+	KeepBranch bool `json:"keep_branch,omitempty"`
+}
+
+// PathResponse answers POST /v1/create and GET /v1/switch-path.
+type PathResponse struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// Event is a line of GET /v1/events, telling clients to refresh what they show.
+type Event struct {
+	// Type is "worktree_created", "worktree_removed" or "config_reloaded".
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	Path   string `json:"path,omitempty"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// newEvent converts an event of the manager to the API, reporting false for
+// those clients are not told about.
+func newEvent(ev worktree.Event) (Event, bool) {
+	switch ev := ev.(type) {
+	case worktree.WorktreeCreated:
+		return Event{Type: "worktree_created", Time: ev.Time, Path: ev.Path, Branch: ev.Branch}, true
+	case worktree.WorktreeRemoved:
+		return Event{Type: "worktree_removed", Time: ev.Time, Path: ev.Path, Branch: ev.Branch}, true
+	case worktree.ConfigReloaded:
+		return Event{Type: "config_reloaded", Time: ev.Time}, true
+	}
+	return Event{}, false
+}
+
+// ErrorResponse is the body of failed requests.
+type ErrorResponse struct {
+	Error string `json:"error"`
+
+	// Code tells errors apart without parsing messages, e.g. "not_found".
+	Code string `json:"code"`
+}
+
+// Backend performs the operations the server exposes.
+type Backend interface {
+	List(ctx context.Context, fast bool) ([]*worktree.Worktree, error)
+	Create(ctx context.Context, req CreateRequest) (*PathResponse, error)
+	Remove(ctx context.Context, req RemoveRequest) error
+	SwitchPath(ctx context.Context, filter string) (*PathResponse, error)
+
+	// Subscribe calls fn with the events of the manager until ctx is done, as
+	// worktree.Manager.Subscribe does.
+	Subscribe(ctx context.Context, fn func(worktree.Event))
+}
+
+// Server serves the API of a Backend. Requests are handled one at a time,
+// since the operations share the state of the repository.
+type Server struct {
+	backend Backend
+	version string
+	mu      sync.Mutex
+
+	// closing is closed when the server shuts down, which ends event streams.
+	closing chan struct{}
+}
+
+// New creates a server for backend, reporting version.
+func New(backend Backend, version string) *Server {
+	return &Server{backend: backend, version: version, closing: make(chan struct{})}
+}
+
+// Reconfigure calls fn while no request is handled, so that fn can change the
+// settings of the backend, such as after the configuration was reloaded.
+func (s *Server) Reconfigure(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"version": s.version})
+	})
+	mux.HandleFunc("GET /v1/worktrees", s.serialized(func(w http.ResponseWriter, r *http.Request) {
+		worktrees, err := s.backend.List(r.Context(), r.URL.Query().Get("fast") != "")
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if worktrees == nil {
+			worktrees = []*worktree.Worktree{}
+		}
+		writeJSON(w, http.StatusOK, worktrees)
+	}))
+	mux.HandleFunc("POST /v1/create", s.serialized(func(w http.ResponseWriter, r *http.Request) {
+		var req CreateRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.Branch == "" {
+			writeError(w, errors.NewValidationError("branch", "", fmt.Errorf("required")))
+			return
+		}
+		created, err := s.backend.Create(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	}))
+	mux.HandleFunc("POST /v1/remove", s.serialized(func(w http.ResponseWriter, r *http.Request) {
+		var req RemoveRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.Name == "" {
+			writeError(w, errors.NewValidationError("name", "", fmt.Errorf("required")))
+			return
+		}
+		if err := s.backend.Remove(r.Context(), req); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("GET /v1/switch-path", s.serialized(func(w http.ResponseWriter, r *http.Request) {
+		target, err := s.backend.SwitchPath(r.Context(), r.URL.Query().Get("filter"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, target)
+	}))
+	mux.HandleFunc("GET /v1/events", s.streamEvents)
+	return mux
+}
+
+// streamEvents writes events as they happen, one per line, until the client
+// goes away or the server shuts down. It does not hold up other requests.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	events := make(chan Event)
+	s.backend.Subscribe(ctx, func(ev worktree.Event) {
+		if event, ok := newEvent(ev); ok {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+		}
+	})
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			if err := encoder.Encode(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// serialized wraps handler to run while no other request is handled.
+func (s *Server) serialized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		handler(w, r)
+	}
+}
+
+// Serve answers requests on listener until ctx is done, then ends event
+// streams and lets the other requests in flight finish.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		close(s.closing)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(listener); !stderrors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Listen listens on the unix socket at path, which only the user may connect
+// to. A socket left behind by a server that is gone is replaced; one that a
+// server still answers on is not. The caller removes the socket when done.
+func Listen(path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a server is already listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	// The socket is created in a directory only the user can enter, so that
+	// nobody can connect before its permissions are restricted, and then moved
+	// into place
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".giwo-sock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer os.RemoveAll(dir)
+
+	private := filepath.Join(dir, "s")
+	listener, err := net.Listen("unix", private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	// Closing the listener would remove the socket at its old path
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(private, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict access to %s: %w", path, err)
+	}
+	if err := os.Rename(private, path); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// readJSON decodes the body of r into v, answering with an error if it cannot.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid request body: %v", err), Code: "invalid"})
+		return false
+	}
+	return true
+}
+
+// writeJSON answers with status and v as JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with err and the status and code matching it.
+func writeError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+	writeJSON(w, status, ErrorResponse{Error: err.Error(), Code: code})
+}
+
+// errorStatus returns the HTTP status and error code of err.
+func errorStatus(err error) (int, string) {
+	var validation *errors.ValidationError
+	switch {
+	case stderrors.As(err, &validation), stderrors.Is(err, errors.ErrInvalidBranchName):
+		return http.StatusBadRequest, "invalid"
+	case stderrors.Is(err, errors.ErrWorktreeNotFound), stderrors.Is(err, errors.ErrBranchNotFound):
+		return http.StatusNotFound, "not_found"
+	case stderrors.Is(err, errors.ErrWorktreeAmbiguous):
+		return http.StatusConflict, "ambiguous"
+	case stderrors.Is(err, errors.ErrWorktreeExists), stderrors.Is(err, errors.ErrBranchExists), stderrors.Is(err, errors.ErrBranchCheckedOut):
+		return http.StatusConflict, "exists"
+	case stderrors.Is(err, errors.ErrWorktreeDirty):
+		return http.StatusConflict, "dirty"
+	case stderrors.Is(err, errors.ErrWorktreeLocked):
+		return http.StatusLocked, "locked"
+	}
+	return http.StatusInternalServerError, "internal"
+}
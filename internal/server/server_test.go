@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// fakeBackend serves a fixed set of worktrees and events.
+type fakeBackend struct {
+	worktrees []*worktree.Worktree
+	removed   []RemoveRequest
+	events    []worktree.Event
+}
+
+func (b *fakeBackend) List(ctx context.Context, fast bool) ([]*worktree.Worktree, error) {
+	return b.worktrees, nil
+}
+
+func (b *fakeBackend) Create(ctx context.Context, req CreateRequest) (*PathResponse, error) {
+	for _, wt := range b.worktrees {
+		if wt.Branch == req.Branch {
+			return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, wt.Path)
+		}
+	}
+	return &PathResponse{Path: "/repo/.worktree/" + req.Branch, Branch: req.Branch}, nil
+}
+
+func (b *fakeBackend) Remove(ctx context.Context, req RemoveRequest) error {
+	b.removed = append(b.removed, req)
+	return nil
+}
+
+func (b *fakeBackend) SwitchPath(ctx context.Context, filter string) (*PathResponse, error) {
+	wt, err := worktree.Match(b.worktrees, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &PathResponse{Path: wt.Path, Branch: wt.Branch}, nil
+}
+
+func (b *fakeBackend) Subscribe(ctx context.Context, fn func(worktree.Event)) {
+	go func() {
+		for _, ev := range b.events {
+			fn(ev)
+		}
+	}()
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		"version": {
+			method: http.MethodGet, target: "/v1/version",
+			wantStatus: http.StatusOK, wantBody: `{"version":"1.2.3"}`,
+		},
+		"switch path": {
+			method: http.MethodGet, target: "/v1/switch-path?filter=auth",
+			wantStatus: http.StatusOK, wantBody: `{"path":"/repo/.worktree/feature-auth","branch":"feature-auth"}`,
+		},
+		"switch path not found": {
+			method: http.MethodGet, target: "/v1/switch-path?filter=nothing",
+			wantStatus: http.StatusNotFound, wantBody: `"code":"not_found"`,
+		},
+		"switch path ambiguous": {
+			method: http.MethodGet, target: "/v1/switch-path?filter=feature",
+			wantStatus: http.StatusConflict, wantBody: `"code":"ambiguous"`,
+		},
+		"create": {
+			method: http.MethodPost, target: "/v1/create", body: `{"branch":"fix-login","base":"main"}`,
+			wantStatus: http.StatusCreated, wantBody: `{"path":"/repo/.worktree/fix-login","branch":"fix-login"}`,
+		},
+		"create existing": {
+			method: http.MethodPost, target: "/v1/create", body: `{"branch":"feature-auth"}`,
+			wantStatus: http.StatusConflict, wantBody: `"code":"exists"`,
+		},
+		"create without branch": {
+			method: http.MethodPost, target: "/v1/create", body: `{}`,
+			wantStatus: http.StatusBadRequest, wantBody: `"code":"invalid"`,
+		},
+		"create with unknown field": {
+			method: http.MethodPost, target: "/v1/create", body: `{"branch":"x","colour":"red"}`,
+			wantStatus: http.StatusBadRequest, wantBody: `"code":"invalid"`,
+		},
+		"remove": {
+			method: http.MethodPost, target: "/v1/remove", body: `{"name":"feature-auth","keep_branch":true}`,
+			wantStatus: http.StatusNoContent,
+		},
+		"wrong method": {
+			method: http.MethodGet, target: "/v1/create",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			backend := &fakeBackend{worktrees: []*worktree.Worktree{
+				{Path: "/repo", Branch: "main", IsMain: true},
+				{Path: "/repo/.worktree/feature-auth", Branch: "feature-auth"},
+				{Path: "/repo/.worktree/feature-login", Branch: "feature-login"},
+			}}
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			New(backend, "1.2.3").Handler().ServeHTTP(rec, req)
+
+			if diff := cmp.Diff(tt.wantStatus, rec.Code); diff != "" {
+				t.Errorf("status mismatch (-want +got):\n%s", diff)
+			}
+			body, _ := io.ReadAll(rec.Body)
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("body %q does not contain %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandlerListsWorktrees(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	New(&fakeBackend{}, "dev").Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/worktrees?fast=1", nil))
+
+	if diff := cmp.Diff("[]\n", rec.Body.String()); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	backend := &fakeBackend{events: []worktree.Event{
+		worktree.WorktreeCreated{Time: at, Path: "/repo/.worktree/fix-login", Branch: "fix-login", Base: "main"},
+		worktree.HookRan{Time: at, Hook: "setup", Path: "/repo/.worktree/fix-login"},
+		worktree.ConfigReloaded{Time: at},
+		worktree.WorktreeRemoved{Time: at, Path: "/repo/.worktree/fix-login", Branch: "fix-login", BranchDeleted: true},
+	}}
+	srv := httptest.NewServer(New(backend, "dev").Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /v1/events failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var got []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(got) < 3 && scanner.Scan() {
+		got = append(got, scanner.Text())
+	}
+	want := []string{
+		`{"type":"worktree_created","time":"2026-10-16T09:30:00Z","path":"/repo/.worktree/fix-login","branch":"fix-login"}`,
+		`{"type":"config_reloaded","time":"2026-10-16T09:30:00Z"}`,
+		`{"type":"worktree_removed","time":"2026-10-16T09:30:00Z","path":"/repo/.worktree/fix-login","branch":"fix-login"}`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestListen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "giwo.sock")
+	listener, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("socket not in place: %v", err)
+	}
+	if diff := cmp.Diff(os.FileMode(0o600), info.Mode().Perm()); diff != "" {
+		t.Errorf("socket permissions mismatch (-want +got):\n%s", diff)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(1, len(entries)); diff != "" {
+		t.Errorf("Listen left files behind (-want +got):\n%s", diff)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- New(&fakeBackend{}, "dev").Serve(ctx, listener) }()
+
+	if _, err := Listen(path); err == nil {
+		t.Error("Listen on a socket in use succeeded")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve failed: %v", err)
+	}
+}
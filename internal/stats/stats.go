@@ -0,0 +1,203 @@
+// Package stats keeps usage metrics of worktrees on the local machine: how
+// often the user switches to each worktree and how long they stay there.
+// Nothing is ever sent anywhere; the metrics only power 'giwo stats' and the
+// ranking of the fuzzy finder.
+package stats
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// fileName is the name of the metrics file inside the state directory.
+const fileName = "stats.json"
+
+// MaxStay is the longest a single stay in a worktree counts for. Leaving a
+// worktree is only noticed when switching to another one, possibly the next
+// morning, so longer stays are taken to include time away.
+const MaxStay = 4 * time.Hour
+
+// Worktree holds the metrics of one worktree.
+type Worktree struct {
+	// Repo is the root of the repository's main worktree.
+	Repo string `json:"repo"`
+
+	Path string `json:"path"`
+
+	// Branch is the branch the worktree had at the last visit.
+	Branch string `json:"branch,omitempty"`
+
+	// Switches is the number of times the user switched to the worktree.
+	Switches int `json:"switches"`
+
+	// Time is the time spent in the worktree, in stays of at most MaxStay.
+	Time time.Duration `json:"time"`
+
+	LastVisit time.Time `json:"last_visit"`
+}
+
+// Stats holds the metrics of all worktrees, keyed by path, and the stay in
+// progress.
+type Stats struct {
+	Worktrees map[string]*Worktree `json:"worktrees"`
+
+	// Current is the worktree the user is staying in since Since, if any.
+	Current string    `json:"current,omitempty"`
+	Since   time.Time `json:"since,omitzero"`
+}
+
+// Path returns the metrics file in stateDir.
+func Path(stateDir string) string {
+	return filepath.Join(stateDir, fileName)
+}
+
+// Load reads the metrics from stateDir. Without any yet, it returns empty ones.
+func Load(stateDir string) (*Stats, error) {
+	s := &Stats{Worktrees: make(map[string]*Worktree)}
+	data, err := os.ReadFile(Path(stateDir))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		// Metrics are not worth failing over; a corrupt file starts them over
+		return &Stats{Worktrees: make(map[string]*Worktree)}, nil
+	}
+	if s.Worktrees == nil {
+		s.Worktrees = make(map[string]*Worktree)
+	}
+	return s, nil
+}
+
+// Save writes the metrics to stateDir, replacing the file at once so that a
+// concurrent Load never sees half of it.
+func (s *Stats) Save(stateDir string) error {
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+	path := Path(stateDir)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save stats: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Reset removes the metrics in stateDir.
+func Reset(stateDir string) error {
+	if err := os.Remove(Path(stateDir)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reset stats: %w", err)
+	}
+	return nil
+}
+
+// Visit records that the user went to the worktree at path at now, ending the
+// stay in the previous one. Visiting the worktree the user is already in
+// carries on the stay.
+func (s *Stats) Visit(repo, path, branch string, now time.Time) {
+	if w := s.Worktrees[path]; w != nil && s.Current == path {
+		w.LastVisit = now
+		return
+	}
+	s.Leave(s.Current, now)
+
+	w := s.Worktrees[path]
+	if w == nil {
+		w = &Worktree{Path: path}
+		s.Worktrees[path] = w
+	}
+	w.Repo, w.LastVisit = repo, now
+	if branch != "" {
+		w.Branch = branch
+	}
+	w.Switches++
+	s.Current, s.Since = path, now
+}
+
+// Leave ends the stay in the worktree at path at now, if the user is in it, as
+// when it is removed.
+func (s *Stats) Leave(path string, now time.Time) {
+	if path == "" || s.Current != path {
+		return
+	}
+	if w := s.Worktrees[path]; w != nil {
+		w.Time += stay(s.Since, now)
+	}
+	s.Current, s.Since = "", time.Time{}
+}
+
+// Prune drops the metrics of worktrees for which exists returns false, such
+// as removed ones, and returns their paths, sorted.
+func (s *Stats) Prune(exists func(path string) bool) []string {
+	var pruned []string
+	for path := range s.Worktrees {
+		if exists(path) {
+			continue
+		}
+		delete(s.Worktrees, path)
+		if s.Current == path {
+			s.Current, s.Since = "", time.Time{}
+		}
+		pruned = append(pruned, path)
+	}
+	slices.Sort(pruned)
+	return pruned
+}
+
+// List returns the metrics of all worktrees at now, the stay in progress
+// included, those the user spent most time in first.
+func (s *Stats) List(now time.Time) []Worktree {
+	list := make([]Worktree, 0, len(s.Worktrees))
+	for _, w := range s.Worktrees {
+		entry := *w
+		if entry.Path == s.Current {
+			entry.Time += stay(s.Since, now)
+		}
+		list = append(list, entry)
+	}
+	slices.SortFunc(list, func(a, b Worktree) int {
+		return cmp.Or(
+			cmp.Compare(b.Time, a.Time),
+			cmp.Compare(b.Switches, a.Switches),
+			cmp.Compare(a.Path, b.Path),
+		)
+	})
+	return list
+}
+
+// Score returns the frecency of the worktree at path at now: the number of
+// switches to it, weighted by how recently it was last visited, so that both
+// worktrees used often and those used just now rank high. Worktrees never
+// visited score 0.
+func (s *Stats) Score(path string, now time.Time) float64 {
+	w := s.Worktrees[path]
+	if w == nil {
+		return 0
+	}
+	weight := 0.25
+	switch age := now.Sub(w.LastVisit); {
+	case age < time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 0.5
+	}
+	return float64(w.Switches) * weight
+}
+
+// stay returns the duration of a stay from since to now, capped at MaxStay.
+func stay(since, now time.Time) time.Duration {
+	return max(min(now.Sub(since), MaxStay), 0)
+}
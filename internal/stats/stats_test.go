@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVisit(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	s := &Stats{Worktrees: make(map[string]*Worktree)}
+	s.Visit("/repo", "/repo/.worktree/a", "a", start)
+	s.Visit("/repo", "/repo/.worktree/a", "a", start.Add(10*time.Minute))
+	s.Visit("/repo", "/repo/.worktree/b", "b", start.Add(30*time.Minute))
+	s.Visit("/repo", "/repo/.worktree/a", "a", start.Add(45*time.Minute))
+	// Overnight, the stay is capped
+	s.Visit("/repo", "/repo/.worktree/b", "b", start.Add(24*time.Hour))
+	s.Leave("/repo/.worktree/a", start.Add(25*time.Hour))
+
+	expected := []Worktree{
+		{Repo: "/repo", Path: "/repo/.worktree/a", Branch: "a", Switches: 2, Time: 30*time.Minute + MaxStay, LastVisit: start.Add(45 * time.Minute)},
+		{Repo: "/repo", Path: "/repo/.worktree/b", Branch: "b", Switches: 2, Time: 15*time.Minute + 2*time.Hour, LastVisit: start.Add(24 * time.Hour)},
+	}
+	if diff := cmp.Diff(expected, s.List(start.Add(26*time.Hour))); diff != "" {
+		t.Errorf("List mismatch (-want +got):\n%s", diff)
+	}
+
+	s.Leave("/repo/.worktree/b", start.Add(26*time.Hour))
+	if diff := cmp.Diff("", s.Current); diff != "" {
+		t.Errorf("Current after Leave mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := &Stats{Worktrees: make(map[string]*Worktree)}
+	s.Visit("/repo", "/repo/.worktree/kept", "kept", now)
+	s.Visit("/repo", "/repo/.worktree/b", "b", now)
+	s.Visit("/repo", "/repo/.worktree/a", "a", now)
+
+	pruned := s.Prune(func(path string) bool { return path == "/repo/.worktree/kept" })
+	if diff := cmp.Diff([]string{"/repo/.worktree/a", "/repo/.worktree/b"}, pruned); diff != "" {
+		t.Errorf("Prune mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"/repo/.worktree/kept"}, slices.Collect(maps.Keys(s.Worktrees))); diff != "" {
+		t.Errorf("remaining worktrees mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("", s.Current); diff != "" {
+		t.Errorf("Current after Prune mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestScore(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := &Stats{Worktrees: map[string]*Worktree{
+		"/recent": {Path: "/recent", Switches: 3, LastVisit: now.Add(-10 * time.Minute)},
+		"/today":  {Path: "/today", Switches: 3, LastVisit: now.Add(-5 * time.Hour)},
+		"/week":   {Path: "/week", Switches: 3, LastVisit: now.Add(-3 * 24 * time.Hour)},
+		"/old":    {Path: "/old", Switches: 3, LastVisit: now.Add(-60 * 24 * time.Hour)},
+	}}
+
+	for path, expected := range map[string]float64{
+		"/recent": 12,
+		"/today":  6,
+		"/week":   1.5,
+		"/old":    0.75,
+		"/never":  0,
+	} {
+		t.Run(path, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(expected, s.Score(path, now)); diff != "" {
+				t.Errorf("Score mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "state")
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.Visit("/repo", "/repo/.worktree/a", "a", now)
+	if err := s.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff(s, got); diff != "" {
+		t.Errorf("Load mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := Reset(dir); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	got, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if diff := cmp.Diff(0, len(got.Worktrees)); diff != "" {
+		t.Errorf("worktrees after Reset mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// AgeLevel buckets how long ago the last commit of a worktree was made,
+// so that neglected worktrees stand out in listings.
+type AgeLevel int
+
+const (
+	// AgeUnknown is used for worktrees without commit information.
+	AgeUnknown AgeLevel = iota
+	AgeFresh
+	AgeRecent
+	AgeAging
+	AgeStale
+)
+
+// AgeThresholds are the commit ages from which a worktree counts as recent,
+// aging and stale. Younger worktrees are fresh.
+type AgeThresholds struct {
+	Recent time.Duration
+	Aging  time.Duration
+	Stale  time.Duration
+}
+
+// DefaultAgeThresholds returns the default thresholds: a day, a week and a month.
+func DefaultAgeThresholds() AgeThresholds {
+	return AgeThresholds{
+		Recent: 24 * time.Hour,
+		Aging:  7 * 24 * time.Hour,
+		Stale:  30 * 24 * time.Hour,
+	}
+}
+
+// Level returns the age level of a worktree whose last commit was made at commitTime.
+func (t AgeThresholds) Level(commitTime, now time.Time) AgeLevel {
+	if commitTime.IsZero() {
+		return AgeUnknown
+	}
+
+	switch age := now.Sub(commitTime); {
+	case age >= t.Stale:
+		return AgeStale
+	case age >= t.Aging:
+		return AgeAging
+	case age >= t.Recent:
+		return AgeRecent
+	default:
+		return AgeFresh
+	}
+}
+
+// Icon returns a square colored by the age level, from green for fresh to red
+// for stale, as in the default theme.
+func (l AgeLevel) Icon() string {
+	return themeIcons[ThemeEmoji].Ages[l]
+}
+
+// Color returns the terminal color of the age level in the default theme.
+func (l AgeLevel) Color() tcell.Color {
+	return defaultColors[ageColor(l)]
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAgeThresholdsLevel(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, tt := range map[string]struct {
+		commitTime time.Time
+		expected   AgeLevel
+	}{
+		"no commit information": {
+			expected: AgeUnknown,
+		},
+		"an hour ago": {
+			commitTime: now.Add(-time.Hour),
+			expected:   AgeFresh,
+		},
+		"exactly a day ago": {
+			commitTime: now.Add(-24 * time.Hour),
+			expected:   AgeRecent,
+		},
+		"two weeks ago": {
+			commitTime: now.Add(-14 * 24 * time.Hour),
+			expected:   AgeAging,
+		},
+		"a year ago": {
+			commitTime: now.AddDate(-1, 0, 0),
+			expected:   AgeStale,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			level := DefaultAgeThresholds().Level(tt.commitTime, now)
+			if diff := cmp.Diff(tt.expected, level); diff != "" {
+				t.Errorf("Level mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
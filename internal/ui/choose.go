@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Chooser lets the user pick one of a list of strings with fuzzy search, such
+// as the branch a new worktree starts from. It shares the key bindings of the
+// FuzzyFinder, of which only moving, editing, accepting and aborting apply.
+type Chooser struct {
+	header string
+	items  []string
+	query  []rune
+	keymap Keymap
+	theme  Theme
+
+	// newScreen opens the terminal; tests replace it with a simulation screen.
+	newScreen func() (tcell.Screen, error)
+}
+
+// NewChooser creates a chooser over items, described by header.
+func NewChooser(header string, items []string) *Chooser {
+	return &Chooser{
+		header:    header,
+		items:     items,
+		keymap:    DefaultFinderKeymap(),
+		theme:     DefaultTheme(),
+		newScreen: tcell.NewScreen,
+	}
+}
+
+// WithQuery pre-populates the query.
+func (c *Chooser) WithQuery(query string) *Chooser {
+	c.query = []rune(query)
+	return c
+}
+
+// WithKeymap replaces the default key bindings.
+func (c *Chooser) WithKeymap(keymap Keymap) *Chooser {
+	c.keymap = keymap
+	return c
+}
+
+// WithTheme sets the colors of the chooser.
+func (c *Chooser) WithTheme(theme Theme) *Chooser {
+	c.theme = theme
+	return c
+}
+
+// Choose lets the user pick an item. It returns false if the user cancels.
+func (c *Chooser) Choose() (string, bool, error) {
+	if len(c.items) == 0 {
+		return "", false, fmt.Errorf("nothing to choose from")
+	}
+
+	screen, err := c.newScreen()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open terminal: %w", err)
+	}
+	if err := screen.Init(); err != nil {
+		return "", false, fmt.Errorf("failed to initialize terminal: %w", err)
+	}
+	defer screen.Fini()
+
+	idx := c.run(screen)
+	if idx < 0 {
+		return "", false, nil
+	}
+	return c.items[idx], true, nil
+}
+
+// run handles key events until the user ends the selection. It returns the index
+// of the chosen item, or -1 if the user cancelled.
+func (c *Chooser) run(screen tcell.Screen) int {
+	state := &finderState{items: c.items, fields: [][]string{c.items}, query: c.query}
+	state.filter()
+
+	for {
+		c.draw(screen, state)
+
+		ev, ok := screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			// Redraw on resize and other events
+			continue
+		}
+
+		_, height := screen.Size()
+		action := c.keymap.Lookup(keyName(ev))
+		switch {
+		case state.edit(action, ev, listHeight(height)):
+		case action == ActionAbort:
+			return -1
+		case action == ActionAccept && len(state.matched) > 0:
+			return state.matched[state.cursor].Idx
+		}
+		state.scroll(listHeight(height))
+	}
+}
+
+// draw renders the prompt and the matched items.
+func (c *Chooser) draw(screen tcell.Screen, state *finderState) {
+	screen.Clear()
+	width, height := screen.Size()
+
+	theme := c.theme
+	x := drawText(screen, 0, 0, width, "> ", theme.fg(tcell.StyleDefault, ColorPrompt))
+	x = drawText(screen, x, 0, width, string(state.query), tcell.StyleDefault.Bold(true))
+	screen.ShowCursor(x, 0)
+
+	status := fmt.Sprintf("%d/%d", len(state.matched), len(state.items))
+	x = drawText(screen, 2, 1, width, status, theme.fg(tcell.StyleDefault, ColorCount))
+	drawText(screen, x+2, 1, width, c.header, theme.fg(tcell.StyleDefault, ColorHeader))
+
+	for row := 0; row < listHeight(height) && state.offset+row < len(state.matched); row++ {
+		i := state.offset + row
+		style := tcell.StyleDefault
+		if i == state.cursor {
+			style = theme.cursorStyle()
+			drawText(screen, 0, row+2, width, "> ", theme.fg(style, ColorMarker))
+		}
+		state.drawMatch(screen, 2, row+2, width, i, style, theme)
+	}
+
+	screen.Show()
+}
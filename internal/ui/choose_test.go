@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChooserRun(t *testing.T) {
+	items := []string{"main", "origin/main", "origin/release-1.2", "feature-auth"}
+
+	for name, tt := range map[string]struct {
+		query    string
+		keys     []*tcell.EventKey
+		expected int
+	}{
+		"accept first": {
+			keys:     []*tcell.EventKey{key(tcell.KeyEnter)},
+			expected: 0,
+		},
+		"filter by query": {
+			keys:     append(runes("rel"), key(tcell.KeyEnter)),
+			expected: 2,
+		},
+		"initial query and move": {
+			query:    "main",
+			keys:     []*tcell.EventKey{key(tcell.KeyDown), key(tcell.KeyEnter)},
+			expected: 1,
+		},
+		"delete action is ignored": {
+			keys:     []*tcell.EventKey{key(tcell.KeyCtrlX), key(tcell.KeyEsc)},
+			expected: -1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			screen := tcell.NewSimulationScreen("UTF-8")
+			if err := screen.Init(); err != nil {
+				t.Fatal(err)
+			}
+			defer screen.Fini()
+			screen.SetSize(80, 24)
+			for _, ev := range tt.keys {
+				screen.InjectKey(ev.Key(), ev.Rune(), ev.Modifiers())
+			}
+
+			idx := NewChooser("Base branch", items).WithQuery(tt.query).run(screen)
+			if diff := cmp.Diff(tt.expected, idx); diff != "" {
+				t.Errorf("run index mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
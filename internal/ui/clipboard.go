@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands are the clipboard tools tried in order, covering macOS,
+// Wayland, X11 and WSL.
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// CopyToClipboard copies text to the system clipboard using the first available
+// clipboard tool. Without one, for example over SSH, it asks the terminal to set
+// the clipboard with an OSC 52 escape sequence, which most modern terminals support.
+func CopyToClipboard(text string) error {
+	if command := clipboardCommand(exec.LookPath); command != nil {
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run %s: %w", command[0], err)
+		}
+		return nil
+	}
+
+	if !IsStderrTerminal() {
+		return fmt.Errorf("no clipboard tool found (tried pbcopy, wl-copy, xclip, xsel, clip.exe)")
+	}
+	_, err := fmt.Fprint(os.Stderr, osc52(text))
+	return err
+}
+
+// clipboardCommand returns the first clipboard command found by lookPath, or nil.
+func clipboardCommand(lookPath func(string) (string, error)) []string {
+	for _, command := range clipboardCommands {
+		if _, err := lookPath(command[0]); err == nil {
+			return command
+		}
+	}
+	return nil
+}
+
+// osc52 returns the escape sequence asking the terminal to put text on the clipboard.
+func osc52(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
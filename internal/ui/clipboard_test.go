@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClipboardCommand(t *testing.T) {
+	for name, tt := range map[string]struct {
+		available []string
+		expected  []string
+	}{
+		"macOS": {
+			available: []string{"pbcopy"},
+			expected:  []string{"pbcopy"},
+		},
+		"X11": {
+			available: []string{"xsel", "xclip"},
+			expected:  []string{"xclip", "-selection", "clipboard"},
+		},
+		"none": {
+			expected: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookPath := func(file string) (string, error) {
+				for _, name := range tt.available {
+					if name == file {
+						return "/usr/bin/" + file, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+
+			if diff := cmp.Diff(tt.expected, clipboardCommand(lookPath)); diff != "" {
+				t.Errorf("clipboardCommand mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOSC52(t *testing.T) {
+	t.Parallel()
+
+	if diff := cmp.Diff("\x1b]52;c;L3JlcG8=\a", osc52("/repo")); diff != "" {
+		t.Errorf("osc52 mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/codeowners"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// FormatDiffSummary renders a diff summary as a commit list followed by the diffstat.
+func FormatDiffSummary(summary *worktree.DiffSummary) string {
+	var lines []string
+
+	lines = append(lines, fmt.Sprintf("Changes vs %s:", summary.Base))
+	if len(summary.Commits) == 0 && summary.Stat == "" {
+		lines = append(lines, "  No changes")
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, fmt.Sprintf("Commits (%d):", len(summary.Commits)))
+	for _, commit := range summary.Commits {
+		lines = append(lines, "  "+commit)
+	}
+
+	if summary.Stat != "" {
+		lines = append(lines, "Diffstat:")
+		for _, line := range strings.Split(summary.Stat, "\n") {
+			lines = append(lines, " "+line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatOwners renders the code owners affected by a change, with the number of files each owns.
+func FormatOwners(affected []codeowners.OwnedFiles) string {
+	if len(affected) == 0 {
+		return "Owners:\n  No owned paths changed"
+	}
+
+	lines := []string{fmt.Sprintf("Owners (%d):", len(affected))}
+	for _, owned := range affected {
+		noun := "files"
+		if len(owned.Files) == 1 {
+			noun = "file"
+		}
+		lines = append(lines, fmt.Sprintf("  %s (%d %s)", owned.Owner, len(owned.Files), noun))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/giwo/internal/codeowners"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestFormatDiffSummary(t *testing.T) {
+	for name, tt := range map[string]struct {
+		summary  *worktree.DiffSummary
+		expected string
+	}{
+		"no changes": {
+			summary:  &worktree.DiffSummary{Base: "origin/main"},
+			expected: "Changes vs origin/main:\n  No changes",
+		},
+		"commits and stat": {
+			summary: &worktree.DiffSummary{
+				Base:    "origin/main",
+				Stat:    " auth.go | 4 ++--\n 1 file changed, 2 insertions(+), 2 deletions(-)",
+				Commits: []string{"abc1234 Fix login", "def5678 Add auth"},
+			},
+			expected: "Changes vs origin/main:\n" +
+				"Commits (2):\n  abc1234 Fix login\n  def5678 Add auth\n" +
+				"Diffstat:\n  auth.go | 4 ++--\n  1 file changed, 2 insertions(+), 2 deletions(-)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result := FormatDiffSummary(tt.summary)
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("FormatDiffSummary mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatOwners(t *testing.T) {
+	for name, tt := range map[string]struct {
+		affected []codeowners.OwnedFiles
+		expected string
+	}{
+		"none": {
+			affected: nil,
+			expected: "Owners:\n  No owned paths changed",
+		},
+		"several": {
+			affected: []codeowners.OwnedFiles{
+				{Owner: "@acme/docs", Files: []string{"README.md"}},
+				{Owner: "@alice", Files: []string{"main.go", "cmd/root.go"}},
+			},
+			expected: "Owners (2):\n  @acme/docs (1 file)\n  @alice (2 files)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, FormatOwners(tt.affected)); diff != "" {
+				t.Errorf("FormatOwners mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
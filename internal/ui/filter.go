@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"github.com/ktr0731/go-fuzzyfinder/matching"
+)
+
+// Fields of a worktree searched by the finders, as returned by worktreeFields.
+const (
+	fieldName = iota
+	fieldLabels
+	fieldPath
+	fieldSubject
+)
+
+// fieldMatch is an item matching a query in one of its fields.
+type fieldMatch struct {
+	matching.Matched
+
+	// Field is the index of the field the query matched in.
+	Field int
+}
+
+// FilterWorktrees returns the worktrees matching filter, the way both the
+// FuzzyFinder and the Selector narrow their list down. A filter naming a single
+// worktree by branch or path, as worktree.Match does, returns just that one;
+// otherwise the filter is fuzzy matched against the displayed names, then the
+// labels, the paths and the last commit subjects, best matches first. An empty filter
+// returns all worktrees.
+func FilterWorktrees(worktrees []*worktree.Worktree, filter string) []*worktree.Worktree {
+	if filter == "" {
+		return worktrees
+	}
+	if wt, err := worktree.Match(worktrees, filter); err == nil {
+		return []*worktree.Worktree{wt}
+	}
+
+	var filtered []*worktree.Worktree
+	for _, m := range matchFields(filter, worktreeFields(worktrees)) {
+		filtered = append(filtered, worktrees[m.Idx])
+	}
+	return filtered
+}
+
+// worktreeFields returns the text searched for each worktree, indexed by field
+// and then by worktree: the displayed names, the labels, the paths and the last
+// commit subjects.
+func worktreeFields(worktrees []*worktree.Worktree) [][]string {
+	fields := [][]string{
+		fieldName:    make([]string, len(worktrees)),
+		fieldLabels:  make([]string, len(worktrees)),
+		fieldPath:    make([]string, len(worktrees)),
+		fieldSubject: make([]string, len(worktrees)),
+	}
+	for i, wt := range worktrees {
+		fields[fieldName][i] = DisplayName(wt)
+		fields[fieldLabels][i] = FormatLabels(wt.Labels)
+		fields[fieldPath][i] = wt.Path
+		fields[fieldSubject][i] = wt.LastCommit
+	}
+	return fields
+}
+
+// FormatLabels formats labels for the switch UIs, as in "#review #urgent".
+func FormatLabels(labels []string) string {
+	var b strings.Builder
+	for i, label := range labels {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString("#" + label)
+	}
+	return b.String()
+}
+
+// matchFields fuzzy matches query against the items described by fields, where
+// fields[f][i] is field f of item i. Each item is returned once, for the first
+// field it matches in, so that earlier fields rank higher; within a field the
+// best matches come first.
+func matchFields(query string, fields [][]string) []fieldMatch {
+	if len(fields) == 0 {
+		return nil
+	}
+	seen := make([]bool, len(fields[0]))
+	var matched []fieldMatch
+	for field, texts := range fields {
+		for _, m := range matching.FindAll(query, texts) {
+			if !seen[m.Idx] {
+				seen[m.Idx] = true
+				matched = append(matched, fieldMatch{Matched: m, Field: field})
+			}
+		}
+	}
+	return matched
+}
+
+// matchedRunes reports for each rune of s whether it is one of the query runes
+// found by the matcher within pos, the inclusive range of runes it matched,
+// so that they can be highlighted.
+func matchedRunes(s string, query []rune, pos [2]int) []bool {
+	runes := []rune(s)
+	marks := make([]bool, len(runes))
+	if pos[0] < 0 {
+		return marks
+	}
+	q := 0
+	for i := pos[0]; i <= pos[1] && i < len(runes) && q < len(query); i++ {
+		if unicode.ToLower(runes[i]) == unicode.ToLower(query[q]) {
+			marks[i] = true
+			q++
+		}
+	}
+	return marks
+}
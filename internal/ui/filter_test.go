@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/giwo/internal/utils"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestFilterWorktrees(t *testing.T) {
+	worktrees := []*worktree.Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+		{Branch: "feature-login", Path: "/repo/.worktree/feature-login", LastCommit: "Fix the parser"},
+		{Branch: "main-backport", Path: "/repo/.worktree/main-backport", Labels: []string{"review"}},
+		{Branch: "hotfix", Path: "/repo/.worktree/urgent", LastCommit: "Handle empty config files"},
+	}
+
+	for name, tt := range map[string]struct {
+		filter   string
+		expected []string
+	}{
+		"empty filter": {
+			filter:   "",
+			expected: []string{"main", "feature-auth", "feature-login", "main-backport", "hotfix"},
+		},
+		"exact branch wins over longer names": {
+			filter:   "main",
+			expected: []string{"main"},
+		},
+		"exact path": {
+			filter:   "/repo/.worktree/feature-login",
+			expected: []string{"feature-login"},
+		},
+		"unique substring": {
+			filter:   "auth",
+			expected: []string{"feature-auth"},
+		},
+		"fuzzy": {
+			filter:   "ftlgn",
+			expected: []string{"feature-login"},
+		},
+		"path": {
+			filter:   "urgnt",
+			expected: []string{"hotfix"},
+		},
+		"label": {
+			filter:   "#review",
+			expected: []string{"main-backport"},
+		},
+		"commit subject": {
+			filter:   "parser",
+			expected: []string{"feature-login"},
+		},
+		"several matches": {
+			filter:   "feat",
+			expected: []string{"feature-auth", "feature-login"},
+		},
+		"no match": {
+			filter:   "xyz",
+			expected: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, wt := range FilterWorktrees(worktrees, tt.filter) {
+				got = append(got, wt.Branch)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("FilterWorktrees mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterWorktreesProperties(t *testing.T) {
+	t.Parallel()
+
+	// Any valid branch name, however unusual, picks its worktree and is
+	// fully highlighted when typed into the finder
+	findsBranch := func(s string) bool {
+		branch := utils.SanitizeBranchName(s)
+		wt := &worktree.Worktree{Path: "/repo/.worktree/" + branch, Branch: branch}
+		worktrees := []*worktree.Worktree{{Path: "/repo", Branch: "main", IsMain: true}, wt}
+
+		filtered := FilterWorktrees(worktrees, branch)
+		if len(filtered) == 0 || filtered[0] != wt {
+			return false
+		}
+
+		matches := matchFields(branch, worktreeFields([]*worktree.Worktree{wt}))
+		if len(matches) == 0 || matches[0].Field != fieldName {
+			return false
+		}
+		marked := 0
+		for _, m := range matchedRunes(branch, []rune(branch), matches[0].Pos) {
+			if m {
+				marked++
+			}
+		}
+		return marked == utf8.RuneCountInString(branch)
+	}
+	if err := quick.Check(findsBranch, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Errorf("branch not found by its name: %v", err)
+	}
+}
+
+func TestMatchFields(t *testing.T) {
+	t.Parallel()
+
+	fields := [][]string{
+		{"hotfix", "feature-login", "docs"},
+		{"", "", "#fixme"},
+		{"/repo/hotfix", "/repo/feature-login", "/repo/docs"},
+		{"Handle empty files", "Fix the parser", "Fix typos"},
+	}
+
+	type match struct {
+		Idx, Field int
+	}
+	var got []match
+	for _, m := range matchFields("fix", fields) {
+		got = append(got, match{Idx: m.Idx, Field: m.Field})
+	}
+	// Names rank above labels and subjects, and each item is listed once
+	expected := []match{{Idx: 0, Field: 0}, {Idx: 2, Field: 1}, {Idx: 1, Field: 3}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("matchFields mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMatchedRunes(t *testing.T) {
+	for name, tt := range map[string]struct {
+		text     string
+		query    string
+		pos      [2]int
+		expected string
+	}{
+		"contiguous": {
+			text:     "feature-login",
+			query:    "login",
+			pos:      [2]int{8, 12},
+			expected: "        ^^^^^",
+		},
+		"scattered and case insensitive": {
+			text:     "Fix the parser",
+			query:    "ftp",
+			pos:      [2]int{0, 8},
+			expected: "^   ^   ^     ",
+		},
+		"no match": {
+			text:     "main",
+			query:    "",
+			pos:      [2]int{-1, -1},
+			expected: "    ",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got strings.Builder
+			for _, marked := range matchedRunes(tt.text, []rune(tt.query), tt.pos) {
+				if marked {
+					got.WriteByte('^')
+				} else {
+					got.WriteByte(' ')
+				}
+			}
+			if diff := cmp.Diff(tt.expected, got.String()); diff != "" {
+				t.Errorf("matchedRunes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,58 +3,631 @@ package ui
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/knwoop/giwo/pkg/worktree"
-	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
+	"github.com/ktr0731/go-fuzzyfinder/matching"
+	"github.com/mattn/go-runewidth"
 )
 
-// FuzzyFinder provides fuzzy search functionality for worktrees using go-fuzzyfinder.
+// FuzzyFinder provides fuzzy search functionality for worktrees.
+// Keys are dispatched through a Keymap, so bindings can be changed in the configuration.
 type FuzzyFinder struct {
 	worktrees []*worktree.Worktree
+
+	header  string
+	keymap  Keymap
+	actions map[Action]bool
+	ages    AgeThresholds
+	theme   Theme
+
+	// query, highlighted and message carry over from one Pick to the next,
+	// so that the finder reopens where it was left after an inline action.
+	query       []rune
+	highlighted string
+	message     string
+
+	// autoSelect makes the first Pick return the only worktree matching the query without asking.
+	autoSelect bool
+
+	details      func(*worktree.Worktree) string
+	detailsMu    sync.Mutex
+	detailsCache map[int]string
+
+	// fullDiff and pager show the full diff of a worktree on ActionPager.
+	fullDiff func(*worktree.Worktree) (string, error)
+	pager    func(string) error
+
+	// detailsOnAccept makes accepting a worktree open its detail pane instead of
+	// ending the selection, and openDetails opens it when the finder is next opened.
+	detailsOnAccept bool
+	openDetails     bool
+
+	// newScreen opens the terminal; tests replace it with a simulation screen.
+	newScreen func() (tcell.Screen, error)
+
+	// screen is the screen of the running finder, woken up to apply the
+	// changes queued in pending by Reconfigure.
+	screenMu sync.Mutex
+	screen   tcell.Screen
+	pending  []func(*FuzzyFinder)
 }
 
 // NewFuzzyFinder creates a new fuzzy finder.
 func NewFuzzyFinder(worktrees []*worktree.Worktree) *FuzzyFinder {
 	return &FuzzyFinder{
-		worktrees: worktrees,
+		worktrees:    worktrees,
+		header:       "Select Worktree",
+		keymap:       DefaultFinderKeymap(),
+		actions:      map[Action]bool{ActionAccept: true},
+		ages:         DefaultAgeThresholds(),
+		theme:        DefaultTheme(),
+		autoSelect:   true,
+		detailsCache: make(map[int]string),
+		pager:        pageInTerminal,
+		newScreen:    tcell.NewScreen,
+	}
+}
+
+// WithHeader replaces the header shown above the list, e.g. to say what the selection is for.
+func (f *FuzzyFinder) WithHeader(header string) *FuzzyFinder {
+	f.header = header
+	return f
+}
+
+// WithQuery pre-populates the query, e.g. with a filter given on the command line.
+func (f *FuzzyFinder) WithQuery(query string) *FuzzyFinder {
+	f.query = []rune(query)
+	return f
+}
+
+// WithAutoSelect sets whether Pick returns the only worktree matching the query
+// given to WithQuery without asking, as FilterWorktrees matches it.
+func (f *FuzzyFinder) WithAutoSelect(autoSelect bool) *FuzzyFinder {
+	f.autoSelect = autoSelect
+	return f
+}
+
+// WithAgeThresholds sets the commit ages at which the age marker of a worktree changes color.
+func (f *FuzzyFinder) WithAgeThresholds(ages AgeThresholds) *FuzzyFinder {
+	f.ages = ages
+	return f
+}
+
+// WithTheme sets the colors and icons of the finder.
+func (f *FuzzyFinder) WithTheme(theme Theme) *FuzzyFinder {
+	f.theme = theme
+	return f
+}
+
+// WithKeymap replaces the default key bindings.
+func (f *FuzzyFinder) WithKeymap(keymap Keymap) *FuzzyFinder {
+	f.keymap = keymap
+	return f
+}
+
+// WithActions enables actions on the highlighted worktree besides accepting it,
+// such as ActionDelete. Keys bound to actions that are not enabled are ignored.
+func (f *FuzzyFinder) WithActions(actions ...Action) *FuzzyFinder {
+	for _, action := range actions {
+		f.actions[action] = true
+	}
+	return f
+}
+
+// SetWorktrees replaces the worktrees offered, e.g. after one was removed by an inline action.
+func (f *FuzzyFinder) SetWorktrees(worktrees []*worktree.Worktree) {
+	f.worktrees = worktrees
+	f.detailsMu.Lock()
+	f.detailsCache = make(map[int]string)
+	f.detailsMu.Unlock()
+}
+
+// SetMessage shows message in the status line when the finder is next opened,
+// until a key is pressed.
+func (f *FuzzyFinder) SetMessage(message string) {
+	f.message = message
+}
+
+// WithDetails sets a function providing extra details shown below the preview
+// and in the detail pane, such as the diff against the worktree's base. Results
+// are cached per worktree since the preview is redrawn on every cursor move.
+func (f *FuzzyFinder) WithDetails(fn func(*worktree.Worktree) string) *FuzzyFinder {
+	f.details = fn
+	return f
+}
+
+// WithFullDiff sets a function providing the full diff of a worktree, which
+// the key bound to ActionPager shows in $PAGER while the screen is suspended.
+func (f *FuzzyFinder) WithFullDiff(fn func(*worktree.Worktree) (string, error)) *FuzzyFinder {
+	f.fullDiff = fn
+	return f
+}
+
+// WithDetailsOnAccept makes accepting a worktree open its detail pane rather
+// than return it, for commands that only show worktrees. Pick then returns
+// only when the user cancels, or chooses an action enabled with WithActions.
+func (f *FuzzyFinder) WithDetailsOnAccept() *FuzzyFinder {
+	f.detailsOnAccept = true
+	return f
+}
+
+// OpenDetails opens the detail pane of the highlighted worktree, the first
+// matching the query unless the finder was left elsewhere, when the finder is
+// next opened.
+func (f *FuzzyFinder) OpenDetails() {
+	f.openDetails = true
+}
+
+// Reconfigure changes the finder with fn, such as with WithKeymap after the
+// configuration was reloaded. It is safe to call from any goroutine: fn runs on
+// the goroutine of the finder, right away if it is open, redrawing it, or when
+// it is next opened otherwise.
+func (f *FuzzyFinder) Reconfigure(fn func(*FuzzyFinder)) {
+	f.screenMu.Lock()
+	defer f.screenMu.Unlock()
+
+	f.pending = append(f.pending, fn)
+	if f.screen != nil {
+		f.screen.PostEvent(tcell.NewEventInterrupt(nil))
+	}
+}
+
+// attach makes screen the one woken up by Reconfigure, or none if it is nil,
+// and applies the changes queued until then.
+func (f *FuzzyFinder) attach(screen tcell.Screen) {
+	f.screenMu.Lock()
+	f.screen = screen
+	f.screenMu.Unlock()
+
+	f.applyPending()
+}
+
+// applyPending applies the changes queued by Reconfigure.
+func (f *FuzzyFinder) applyPending() {
+	f.screenMu.Lock()
+	pending := f.pending
+	f.pending = nil
+	f.screenMu.Unlock()
+
+	for _, fn := range pending {
+		fn(f)
 	}
 }
 
-// Search performs fuzzy search using go-fuzzyfinder.
+// Search lets the user pick a worktree. It returns nil if the user cancels.
 func (f *FuzzyFinder) Search() (*worktree.Worktree, error) {
+	selected, _, err := f.Pick()
+	return selected, err
+}
+
+// Pick lets the user pick a worktree and returns it with the action chosen for it:
+// ActionAccept, or one of the actions enabled with WithActions.
+// It returns nil and ActionAbort if the user cancels.
+func (f *FuzzyFinder) Pick() (*worktree.Worktree, Action, error) {
 	if len(f.worktrees) == 0 {
-		return nil, fmt.Errorf("no worktrees available")
+		return nil, ActionAbort, fmt.Errorf("no worktrees available")
 	}
 
-	// If only one worktree, return it directly
-	if len(f.worktrees) == 1 {
-		return f.worktrees[0], nil
+	// A query naming a single worktree needs no picker, but only the first time:
+	// later picks follow inline actions, whose worktree must not be switched to
+	autoSelect := f.autoSelect && len(f.query) > 0
+	f.autoSelect = false
+	if autoSelect {
+		if matched := FilterWorktrees(f.worktrees, string(f.query)); len(matched) == 1 {
+			return matched[0], ActionAccept, nil
+		}
 	}
 
-	// Use go-fuzzyfinder to search
-	idx, err := fuzzyfinder.Find(
-		f.worktrees,
-		func(i int) string {
-			return f.worktrees[i].Branch
-		},
-		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
-			if i == -1 {
-				return ""
-			}
-			return f.formatWorktreePreview(f.worktrees[i])
-		}),
-		fuzzyfinder.WithHeader("Select Worktree"),
-	)
+	// If only one worktree and nothing else to do with it, return it directly
+	if len(f.worktrees) == 1 && len(f.actions) == 1 && !f.detailsOnAccept {
+		return f.worktrees[0], ActionAccept, nil
+	}
+
+	screen, err := f.newScreen()
 	if err != nil {
-		// go-fuzzyfinder returns specific error for user cancellation
-		if err == fuzzyfinder.ErrAbort {
-			return nil, nil
+		return nil, ActionAbort, fmt.Errorf("failed to open terminal: %w", err)
+	}
+	if err := screen.Init(); err != nil {
+		return nil, ActionAbort, fmt.Errorf("failed to initialize terminal: %w", err)
+	}
+	defer screen.Fini()
+
+	idx, action := f.run(screen)
+	if idx < 0 {
+		return nil, ActionAbort, nil
+	}
+	return f.worktrees[idx], action, nil
+}
+
+// finderState is the query and cursor of a running fuzzy finder.
+type finderState struct {
+	// items are the displayed names, and fields the text searched for them,
+	// starting with the names themselves. The fields in shown are displayed
+	// after every name, the others only when the query matched in them.
+	items  []string
+	fields [][]string
+	shown  []int
+
+	query   []rune
+	matched []fieldMatch
+	cursor  int
+	offset  int
+
+	// detail reports whether the detail pane of the highlighted worktree is
+	// open instead of the list, scrolled down by detailOffset lines.
+	detail       bool
+	detailOffset int
+}
+
+// run handles key events until the user ends the selection. It returns the index
+// of the highlighted worktree and the action, or -1 if the user cancelled.
+func (f *FuzzyFinder) run(screen tcell.Screen) (int, Action) {
+	f.attach(screen)
+	defer f.attach(nil)
+
+	fields := worktreeFields(f.worktrees)
+	state := &finderState{items: fields[fieldName], fields: fields, shown: []int{fieldLabels}, query: f.query}
+	state.filter()
+	for i, m := range state.matched {
+		if f.worktrees[m.Idx].Path == f.highlighted {
+			state.cursor = i
+		}
+	}
+	_, height := screen.Size()
+	state.scroll(listHeight(height))
+	state.detail = f.openDetails && len(state.matched) > 0
+	f.openDetails = false
+
+	defer func() {
+		f.query = state.query
+		f.highlighted = ""
+		if len(state.matched) > 0 {
+			f.highlighted = f.worktrees[state.matched[state.cursor].Idx].Path
+		}
+	}()
+
+	for {
+		f.draw(screen, state)
+
+		var ev *tcell.EventKey
+		switch e := screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			ev = e
+		case *tcell.EventInterrupt:
+			f.applyPending()
+			continue
+		default:
+			// Redraw on resize and other events
+			continue
 		}
-		return nil, fmt.Errorf("fuzzy search failed: %w", err)
+		f.message = ""
+
+		_, height = screen.Size()
+		action := f.keymap.Lookup(keyName(ev))
+		openDetails := action == ActionDetails || (action == ActionAccept && f.detailsOnAccept)
+		switch {
+		case state.detail && f.actions[action] && !openDetails:
+			return state.matched[state.cursor].Idx, action
+		case state.detail:
+			f.detailKey(screen, state, action, listHeight(height))
+		case state.edit(action, ev, listHeight(height)):
+		case action == ActionAbort:
+			return -1, ActionAbort
+		case len(state.matched) == 0:
+		case openDetails:
+			state.detail = true
+			state.detailOffset = 0
+		case action == ActionPager:
+			f.pageDiff(screen, state)
+		case f.actions[action]:
+			return state.matched[state.cursor].Idx, action
+		}
+		state.scroll(listHeight(height))
+	}
+}
+
+// detailKey performs action in the detail pane, which scrolls by rows lines a
+// page: scrolling it, showing the full diff or closing it.
+func (f *FuzzyFinder) detailKey(screen tcell.Screen, state *finderState, action Action, rows int) {
+	switch action {
+	case ActionUp:
+		state.detailOffset--
+	case ActionDown:
+		state.detailOffset++
+	case ActionPageUp:
+		state.detailOffset -= rows
+	case ActionPageDown:
+		state.detailOffset += rows
+	case ActionPager:
+		f.pageDiff(screen, state)
+	case ActionAbort, ActionDetails, ActionAccept:
+		state.detail = false
+		return
 	}
 
-	return f.worktrees[idx], nil
+	lines := strings.Count(f.previewFor(state.matched[state.cursor].Idx), "\n") + 1
+	state.detailOffset = max(0, min(state.detailOffset, lines-rows))
+}
+
+// pageDiff suspends the screen to show the full diff of the highlighted
+// worktree in the pager, reporting failures in the status line.
+func (f *FuzzyFinder) pageDiff(screen tcell.Screen, state *finderState) {
+	if f.fullDiff == nil {
+		return
+	}
+
+	diff, err := f.fullDiff(f.worktrees[state.matched[state.cursor].Idx])
+	switch {
+	case err != nil:
+		f.message = fmt.Sprintf("❌ %v", err)
+		return
+	case diff == "":
+		f.message = "No changes to show"
+		return
+	}
+
+	if err := screen.Suspend(); err != nil {
+		f.message = fmt.Sprintf("❌ failed to suspend the screen: %v", err)
+		return
+	}
+	err = f.pager(diff)
+	if resumeErr := screen.Resume(); resumeErr != nil && err == nil {
+		err = resumeErr
+	}
+	if err != nil {
+		f.message = fmt.Sprintf("❌ pager failed: %v", err)
+	}
+}
+
+// edit performs action if it moves the cursor or edits the query, typing the
+// rune of ev for keys without an action, and reports whether it did.
+func (s *finderState) edit(action Action, ev *tcell.EventKey, height int) bool {
+	switch action {
+	case ActionUp:
+		s.move(-1)
+	case ActionDown:
+		s.move(1)
+	case ActionPageUp:
+		s.move(-height)
+	case ActionPageDown:
+		s.move(height)
+	case ActionBackwardDeleteChar:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.filter()
+		}
+	case ActionBackwardKillWord:
+		query := strings.TrimRight(string(s.query), " ")
+		s.query = []rune(query[:strings.LastIndex(query, " ")+1])
+		s.filter()
+	case ActionClearQuery:
+		s.query = nil
+		s.filter()
+	case ActionNone:
+		if ev.Key() == tcell.KeyRune {
+			s.query = append(s.query, ev.Rune())
+			s.filter()
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// filter matches the items against the query, keeping the original order for an empty query.
+func (s *finderState) filter() {
+	if len(s.query) == 0 {
+		s.matched = make([]fieldMatch, len(s.items))
+		for i := range s.items {
+			s.matched[i] = fieldMatch{Matched: matching.Matched{Idx: i, Pos: [2]int{-1, -1}}}
+		}
+	} else {
+		s.matched = matchFields(string(s.query), s.fields)
+	}
+	s.cursor = 0
+	s.offset = 0
+}
+
+// move moves the cursor by delta items, stopping at either end of the list.
+func (s *finderState) move(delta int) {
+	s.cursor = max(0, min(s.cursor+delta, len(s.matched)-1))
+}
+
+// scroll keeps the cursor within the visible part of the list.
+func (s *finderState) scroll(height int) {
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if height > 0 && s.cursor >= s.offset+height {
+		s.offset = s.cursor - height + 1
+	}
+}
+
+// listHeight returns the number of list rows on a screen of the given height,
+// below the prompt and status lines.
+func listHeight(height int) int {
+	return max(height-2, 1)
+}
+
+// draw renders the prompt, the matched worktrees and the preview of the highlighted one.
+func (f *FuzzyFinder) draw(screen tcell.Screen, state *finderState) {
+	screen.Clear()
+	if state.detail {
+		f.drawDetail(screen, state)
+		return
+	}
+	width, height := screen.Size()
+	listWidth := width / 2
+
+	theme := f.theme
+	x := drawText(screen, 0, 0, width, "> ", theme.fg(tcell.StyleDefault, ColorPrompt))
+	x = drawText(screen, x, 0, width, string(state.query), tcell.StyleDefault.Bold(true))
+	screen.ShowCursor(x, 0)
+
+	status := fmt.Sprintf("%d/%d", len(state.matched), len(state.items))
+	x = drawText(screen, 2, 1, listWidth, status, theme.fg(tcell.StyleDefault, ColorCount))
+	if f.message != "" {
+		drawText(screen, x+2, 1, width, f.message, tcell.StyleDefault.Bold(true))
+	} else {
+		x = drawText(screen, x+2, 1, listWidth, f.header, theme.fg(tcell.StyleDefault, ColorHeader))
+		drawText(screen, x+2, 1, width, actionHints(f.keymap, f.hinted()), theme.fg(tcell.StyleDefault, ColorHint))
+	}
+
+	now := time.Now()
+	for row := 0; row < listHeight(height) && state.offset+row < len(state.matched); row++ {
+		i := state.offset + row
+		idx := state.matched[i].Idx
+		style := tcell.StyleDefault
+		if i == state.cursor {
+			style = theme.cursorStyle()
+			drawText(screen, 0, row+2, listWidth, "> ", theme.fg(style, ColorMarker))
+		}
+		age := f.ages.Level(f.worktrees[idx].ActiveAt(), now)
+		x := drawText(screen, 2, row+2, listWidth, theme.Icons.Marker+" ", theme.fg(style, ageColor(age)))
+		state.drawMatch(screen, x, row+2, listWidth, i, style, theme)
+	}
+
+	if len(state.matched) > 0 {
+		preview := f.previewFor(state.matched[state.cursor].Idx)
+		border := theme.fg(tcell.StyleDefault, ColorBorder)
+		for y := 1; y < height; y++ {
+			screen.SetContent(listWidth, y, theme.Icons.Border, nil, border)
+		}
+		for y, line := range strings.Split(preview, "\n") {
+			if y+1 >= height {
+				break
+			}
+			drawText(screen, listWidth+2, y+1, width, line, tcell.StyleDefault)
+		}
+	}
+
+	screen.Show()
+}
+
+// drawDetail renders the detail pane of the highlighted worktree: its preview
+// across the whole screen, below its name and the keys of the pane.
+func (f *FuzzyFinder) drawDetail(screen tcell.Screen, state *finderState) {
+	width, height := screen.Size()
+	theme := f.theme
+	idx := state.matched[state.cursor].Idx
+
+	screen.HideCursor()
+	x := drawText(screen, 0, 0, width, "> ", theme.fg(tcell.StyleDefault, ColorPrompt))
+	drawText(screen, x, 0, width, DisplayName(f.worktrees[idx]), tcell.StyleDefault.Bold(true))
+
+	lines := strings.Split(f.previewFor(idx), "\n")
+	rows := listHeight(height)
+	position := fmt.Sprintf("%d-%d/%d", state.detailOffset+1, min(state.detailOffset+rows, len(lines)), len(lines))
+	x = drawText(screen, 2, 1, width, position, theme.fg(tcell.StyleDefault, ColorCount))
+	if f.message != "" {
+		drawText(screen, x+2, 1, width, f.message, tcell.StyleDefault.Bold(true))
+	} else {
+		hints := map[Action]bool{ActionDetails: true, ActionPager: f.fullDiff != nil}
+		drawText(screen, x+2, 1, width, actionHints(f.keymap, hints), theme.fg(tcell.StyleDefault, ColorHint))
+	}
+
+	for row := 0; row < rows && state.detailOffset+row < len(lines); row++ {
+		drawText(screen, 2, row+2, width, lines[state.detailOffset+row], tcell.StyleDefault)
+	}
+
+	screen.Show()
+}
+
+// hinted returns the actions whose keys the status line of the list shows.
+func (f *FuzzyFinder) hinted() map[Action]bool {
+	hinted := maps.Clone(f.actions)
+	hinted[ActionDetails] = true
+	hinted[ActionPager] = f.fullDiff != nil
+	return hinted
+}
+
+// drawMatch draws the name of the i-th matched item at (x, y), clipped at column
+// maxX, followed by its shown fields and the field the query matched in, such as
+// its commit subject. The runes matching the query are highlighted.
+func (s *finderState) drawMatch(screen tcell.Screen, x, y, maxX, i int, style tcell.Style, theme Theme) {
+	m := s.matched[i]
+	x = s.drawField(screen, x, y, maxX, m, 0, style, theme)
+	for field := 1; field < len(s.fields); field++ {
+		if s.fields[field][m.Idx] == "" || (field != m.Field && !slices.Contains(s.shown, field)) {
+			continue
+		}
+		x = drawText(screen, x, y, maxX, "  ", style)
+		x = s.drawField(screen, x, y, maxX, m, field, theme.fg(style, ColorHint), theme)
+	}
+}
+
+// drawField draws a field of the matched item like drawText, highlighting the
+// runes matching the query if it matched in that field.
+func (s *finderState) drawField(screen tcell.Screen, x, y, maxX int, m fieldMatch, field int, style tcell.Style, theme Theme) int {
+	text := s.fields[field][m.Idx]
+	var highlights []bool
+	if field == m.Field {
+		highlights = matchedRunes(text, s.query, m.Pos)
+	}
+	return drawHighlighted(screen, x, y, maxX, text, highlights, style, theme)
+}
+
+// drawHighlighted draws s like drawText, in the match color of theme for the
+// runes marked in highlights.
+func drawHighlighted(screen tcell.Screen, x, y, maxX int, s string, highlights []bool, style tcell.Style, theme Theme) int {
+	for i, r := range []rune(s) {
+		w := runewidth.RuneWidth(r)
+		if x+w > maxX {
+			break
+		}
+		runeStyle := style
+		if i < len(highlights) && highlights[i] {
+			runeStyle = theme.matchStyle(style)
+		}
+		screen.SetContent(x, y, r, nil, runeStyle)
+		x += w
+	}
+	return x
+}
+
+// drawText draws s at (x, y), clipped at column maxX, and returns the column after it.
+func drawText(screen tcell.Screen, x, y, maxX int, s string, style tcell.Style) int {
+	for _, r := range s {
+		w := runewidth.RuneWidth(r)
+		if x+w > maxX {
+			break
+		}
+		screen.SetContent(x, y, r, nil, style)
+		x += w
+	}
+	return x
+}
+
+// previewFor returns the preview of the worktree at index i, followed by its details.
+func (f *FuzzyFinder) previewFor(i int) string {
+	preview := f.formatWorktreePreview(f.worktrees[i])
+	if details := f.detailsFor(i); details != "" {
+		preview += "\n\n" + details
+	}
+	return preview
+}
+
+// detailsFor returns the cached details for the worktree at index i.
+func (f *FuzzyFinder) detailsFor(i int) string {
+	if f.details == nil {
+		return ""
+	}
+
+	f.detailsMu.Lock()
+	defer f.detailsMu.Unlock()
+
+	if details, ok := f.detailsCache[i]; ok {
+		return details
+	}
+	details := f.details(f.worktrees[i])
+	f.detailsCache[i] = details
+	return details
 }
 
 // formatWorktreePreview formats a worktree for the preview window.
@@ -62,22 +635,42 @@ func (f *FuzzyFinder) formatWorktreePreview(wt *worktree.Worktree) string {
 	var lines []string
 
 	// Branch and path info
+	if wt.Repo != "" {
+		lines = append(lines, fmt.Sprintf("Repository: %s", wt.Repo))
+	}
 	lines = append(lines, fmt.Sprintf("Branch: %s", wt.Branch))
 	lines = append(lines, fmt.Sprintf("Path: %s", wt.Path))
+	if len(wt.Labels) > 0 {
+		lines = append(lines, fmt.Sprintf("Labels: %s", strings.Join(wt.Labels, ", ")))
+	}
 
 	// Status info
+	icons := f.theme.Icons
 	if wt.IsMain {
-		lines = append(lines, "Type: Main worktree 🏠")
+		lines = append(lines, "Type: Main worktree "+icons.Main)
 	} else {
-		lines = append(lines, "Type: Feature worktree 🌱")
+		lines = append(lines, "Type: Feature worktree "+icons.Feature)
+	}
+
+	// Lock status
+	if wt.Locked {
+		if wt.LockReason != "" {
+			lines = append(lines, fmt.Sprintf("Locked: %s %s", icons.Locked, wt.LockReason))
+		} else {
+			lines = append(lines, "Locked: "+icons.Locked)
+		}
 	}
 
-	// Clean status
+	// Clean status, which is filled in once it is loaded
+	if wt.StatusPending {
+		lines = append(lines, "Status: loading...")
+		return strings.Join(lines, "\n")
+	}
 	if wt.IsClean {
-		lines = append(lines, "Status: Clean ✅")
+		lines = append(lines, "Status: Clean "+icons.Clean)
 	} else {
 		changes := wt.Added + wt.Modified + wt.Deleted
-		lines = append(lines, fmt.Sprintf("Status: %d changes ⚠️", changes))
+		lines = append(lines, fmt.Sprintf("Status: %d changes %s", changes, strings.TrimSpace(icons.Dirty)))
 		if wt.Added > 0 {
 			lines = append(lines, fmt.Sprintf("  Added: %d files", wt.Added))
 		}
@@ -91,15 +684,26 @@ func (f *FuzzyFinder) formatWorktreePreview(wt *worktree.Worktree) string {
 
 	// Remote sync status
 	if wt.Ahead > 0 || wt.Behind > 0 {
-		lines = append(lines, fmt.Sprintf("Sync: +%d/-%d commits 📡", wt.Ahead, wt.Behind))
+		lines = append(lines, fmt.Sprintf("Sync: +%d/-%d commits %s", wt.Ahead, wt.Behind, icons.Sync))
 	}
 
 	// Last commit info
 	if wt.LastCommit != "" {
 		lines = append(lines, fmt.Sprintf("Last commit: %s", wt.LastCommit))
 		if wt.CommitAge != "" {
-			lines = append(lines, fmt.Sprintf("Commit age: %s", wt.CommitAge))
+			commitAge := wt.CommitAge
+			if age := f.ages.Level(wt.CommitTime, time.Now()); age != AgeUnknown {
+				commitAge = f.theme.AgeIcon(age) + " " + commitAge
+			}
+			lines = append(lines, fmt.Sprintf("Commit age: %s", commitAge))
+		}
+	}
+	if wt.ActivityAge != "" {
+		activity := wt.ActivityAge
+		if age := f.ages.Level(wt.LastActivity, time.Now()); age != AgeUnknown {
+			activity = f.theme.AgeIcon(age) + " " + activity
 		}
+		lines = append(lines, fmt.Sprintf("Last activity: %s", activity))
 	}
 
 	return strings.Join(lines, "\n")
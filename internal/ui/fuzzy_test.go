@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/go-cmp/cmp"
 	"github.com/knwoop/giwo/pkg/worktree"
 )
 
@@ -98,3 +101,373 @@ func TestFormatWorktreePreview(t *testing.T) {
 		})
 	}
 }
+
+func TestFuzzyFinderRun(t *testing.T) {
+	worktrees := []*worktree.Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+		{Branch: "fix-parser", Path: "/repo/.worktree/fix-parser"},
+	}
+
+	for name, tt := range map[string]struct {
+		keymap         map[string]string
+		actions        []Action
+		query          string
+		keys           []*tcell.EventKey
+		expectedIndex  int
+		expectedAction Action
+	}{
+		"accept first": {
+			keys:           []*tcell.EventKey{key(tcell.KeyEnter)},
+			expectedIndex:  0,
+			expectedAction: ActionAccept,
+		},
+		"move down": {
+			keys:           []*tcell.EventKey{key(tcell.KeyCtrlJ), key(tcell.KeyDown), key(tcell.KeyEnter)},
+			expectedIndex:  2,
+			expectedAction: ActionAccept,
+		},
+		"filter by query": {
+			keys:           append(runes("parser"), key(tcell.KeyEnter)),
+			expectedIndex:  2,
+			expectedAction: ActionAccept,
+		},
+		"initial query": {
+			query:          "feat",
+			keys:           []*tcell.EventKey{key(tcell.KeyEnter)},
+			expectedIndex:  1,
+			expectedAction: ActionAccept,
+		},
+		"abort": {
+			keys:           []*tcell.EventKey{key(tcell.KeyEsc)},
+			expectedIndex:  -1,
+			expectedAction: ActionAbort,
+		},
+		"remapped delete": {
+			keymap:         map[string]string{"ctrl-d": "delete"},
+			actions:        []Action{ActionDelete},
+			keys:           []*tcell.EventKey{key(tcell.KeyCtrlN), key(tcell.KeyCtrlD)},
+			expectedIndex:  1,
+			expectedAction: ActionDelete,
+		},
+		"disabled action is ignored": {
+			keymap:         map[string]string{"ctrl-o": "open-editor"},
+			keys:           []*tcell.EventKey{key(tcell.KeyCtrlO), key(tcell.KeyEnter)},
+			expectedIndex:  0,
+			expectedAction: ActionAccept,
+		},
+		"rune bound to an action": {
+			keymap:         map[string]string{"j": "down"},
+			keys:           append(runes("j"), key(tcell.KeyEnter)),
+			expectedIndex:  1,
+			expectedAction: ActionAccept,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keymap, err := DefaultFinderKeymap().WithOverrides(tt.keymap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			finder := NewFuzzyFinder(worktrees).WithKeymap(keymap).WithActions(tt.actions...).WithQuery(tt.query)
+
+			screen := tcell.NewSimulationScreen("UTF-8")
+			if err := screen.Init(); err != nil {
+				t.Fatal(err)
+			}
+			defer screen.Fini()
+			screen.SetSize(80, 24)
+			for _, ev := range tt.keys {
+				screen.InjectKey(ev.Key(), ev.Rune(), ev.Modifiers())
+			}
+
+			idx, action := finder.run(screen)
+			if diff := cmp.Diff(tt.expectedIndex, idx); diff != "" {
+				t.Errorf("run index mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedAction, action); diff != "" {
+				t.Errorf("run action mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// key returns a key event for k.
+func key(k tcell.Key) *tcell.EventKey {
+	return tcell.NewEventKey(k, 0, tcell.ModNone)
+}
+
+// runes returns the key events for typing s.
+func runes(s string) []*tcell.EventKey {
+	var events []*tcell.EventKey
+	for _, r := range s {
+		events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	return events
+}
+
+func TestFuzzyFinderReopen(t *testing.T) {
+	t.Parallel()
+
+	worktrees := []*worktree.Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+		{Branch: "feature-login", Path: "/repo/.worktree/feature-login"},
+	}
+	finder := NewFuzzyFinder(worktrees).WithActions(ActionCopyPath)
+
+	pick := func(keys ...*tcell.EventKey) (int, Action) {
+		screen := tcell.NewSimulationScreen("UTF-8")
+		if err := screen.Init(); err != nil {
+			t.Fatal(err)
+		}
+		defer screen.Fini()
+		for _, ev := range keys {
+			screen.InjectKey(ev.Key(), ev.Rune(), ev.Modifiers())
+		}
+		return finder.run(screen)
+	}
+
+	// Filter, highlight the second match and copy its path
+	idx, action := pick(append(runes("feat"), key(tcell.KeyDown), key(tcell.KeyCtrlY))...)
+	if idx < 0 || action != ActionCopyPath {
+		t.Fatalf("expected copy-path, got index %d and action %q", idx, action)
+	}
+	first := worktrees[idx].Branch
+
+	// The query and the highlighted worktree are kept when the finder is reopened
+	idx, action = pick(key(tcell.KeyEnter))
+	if diff := cmp.Diff(ActionAccept, action); diff != "" {
+		t.Errorf("action mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(first, worktrees[idx].Branch); diff != "" {
+		t.Errorf("highlighted worktree mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("feat", string(finder.query)); diff != "" {
+		t.Errorf("query mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFuzzyFinderReconfigure(t *testing.T) {
+	t.Parallel()
+
+	worktrees := []*worktree.Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+	}
+	finder := NewFuzzyFinder(worktrees)
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+
+	// Bind j to down while the finder is open; the key is typed after the change
+	started := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		finder.Reconfigure(func(*FuzzyFinder) { close(started) })
+		idx, _ := finder.run(screen)
+		done <- idx
+	}()
+	<-started
+	finder.Reconfigure(func(f *FuzzyFinder) {
+		keymap, err := DefaultFinderKeymap().WithOverrides(map[string]string{"j": "down"})
+		if err != nil {
+			t.Error(err)
+		}
+		f.WithKeymap(keymap).SetMessage("reloaded")
+	})
+	for _, ev := range append(runes("j"), key(tcell.KeyEnter)) {
+		screen.InjectKey(ev.Key(), ev.Rune(), ev.Modifiers())
+	}
+
+	if diff := cmp.Diff(1, <-done); diff != "" {
+		t.Errorf("run index mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFuzzyFinderAutoSelect(t *testing.T) {
+	t.Parallel()
+
+	worktrees := []*worktree.Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+		{Branch: "feature-login", Path: "/repo/.worktree/feature-login"},
+	}
+	errNoTerminal := errors.New("no terminal")
+	noScreen := func() (tcell.Screen, error) { return nil, errNoTerminal }
+
+	for name, tt := range map[string]struct {
+		query      string
+		autoSelect bool
+		expected   string // branch returned without opening the terminal, if any
+	}{
+		"unique match":    {query: "login", autoSelect: true, expected: "feature-login"},
+		"several matches": {query: "feat", autoSelect: true},
+		"auto-select off": {query: "login", autoSelect: false},
+		"no query":        {query: "", autoSelect: true},
+		"exact branch":    {query: "main", autoSelect: true, expected: "main"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			finder := NewFuzzyFinder(worktrees).WithQuery(tt.query).WithAutoSelect(tt.autoSelect)
+			finder.newScreen = noScreen
+
+			selected, action, err := finder.Pick()
+			if tt.expected == "" {
+				if !errors.Is(err, errNoTerminal) {
+					t.Fatalf("expected the finder to open, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Pick unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, selected.Branch); diff != "" {
+				t.Errorf("selected mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(ActionAccept, action); diff != "" {
+				t.Errorf("action mismatch (-want +got):\n%s", diff)
+			}
+
+			// Reopening after an inline action shows the finder
+			if _, _, err := finder.Pick(); !errors.Is(err, errNoTerminal) {
+				t.Errorf("expected the finder to open on the second pick, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFuzzyFinderDetails(t *testing.T) {
+	worktrees := []*worktree.Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+	}
+
+	for name, tt := range map[string]struct {
+		detailsOnAccept bool
+		openDetails     bool
+		actions         []Action
+		keys            []*tcell.EventKey
+		expectedIndex   int
+		expectedAction  Action
+		expectedPaged   []string
+	}{
+		"accept from the pane": {
+			keys:           []*tcell.EventKey{key(tcell.KeyDown), key(tcell.KeyTab), key(tcell.KeyDown), key(tcell.KeyEnter)},
+			expectedIndex:  1,
+			expectedAction: ActionAccept,
+		},
+		"close the pane": {
+			keys:           []*tcell.EventKey{key(tcell.KeyTab), key(tcell.KeyEsc), key(tcell.KeyDown), key(tcell.KeyEnter)},
+			expectedIndex:  1,
+			expectedAction: ActionAccept,
+		},
+		"inline action from the pane": {
+			actions:        []Action{ActionCopyPath},
+			keys:           []*tcell.EventKey{key(tcell.KeyTab), key(tcell.KeyCtrlY)},
+			expectedIndex:  0,
+			expectedAction: ActionCopyPath,
+		},
+		"enter opens the pane": {
+			detailsOnAccept: true,
+			keys:            []*tcell.EventKey{key(tcell.KeyDown), key(tcell.KeyEnter), key(tcell.KeyEnter), key(tcell.KeyEsc)},
+			expectedIndex:   -1,
+			expectedAction:  ActionAbort,
+		},
+		"pager from the pane": {
+			detailsOnAccept: true,
+			keys:            []*tcell.EventKey{key(tcell.KeyDown), key(tcell.KeyEnter), key(tcell.KeyCtrlV), key(tcell.KeyEsc), key(tcell.KeyEsc)},
+			expectedIndex:   -1,
+			expectedAction:  ActionAbort,
+			expectedPaged:   []string{"diff of feature-auth"},
+		},
+		"pager from the list": {
+			keys:           []*tcell.EventKey{key(tcell.KeyCtrlV), key(tcell.KeyEnter)},
+			expectedIndex:  0,
+			expectedAction: ActionAccept,
+			expectedPaged:  []string{"diff of main"},
+		},
+		"opened with the pane": {
+			openDetails:    true,
+			keys:           []*tcell.EventKey{key(tcell.KeyEsc), key(tcell.KeyEsc)},
+			expectedIndex:  -1,
+			expectedAction: ActionAbort,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			finder := NewFuzzyFinder(worktrees).WithActions(tt.actions...).
+				WithDetails(func(wt *worktree.Worktree) string { return "details of " + wt.Branch }).
+				WithFullDiff(func(wt *worktree.Worktree) (string, error) { return "diff of " + wt.Branch, nil })
+			if tt.detailsOnAccept {
+				finder.WithDetailsOnAccept()
+			}
+			if tt.openDetails {
+				finder.OpenDetails()
+			}
+			var paged []string
+			finder.pager = func(content string) error {
+				paged = append(paged, content)
+				return nil
+			}
+
+			screen := tcell.NewSimulationScreen("UTF-8")
+			if err := screen.Init(); err != nil {
+				t.Fatal(err)
+			}
+			defer screen.Fini()
+			screen.SetSize(80, 24)
+			for _, ev := range tt.keys {
+				screen.InjectKey(ev.Key(), ev.Rune(), ev.Modifiers())
+			}
+
+			idx, action := finder.run(screen)
+			if diff := cmp.Diff(tt.expectedIndex, idx); diff != "" {
+				t.Errorf("run index mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedAction, action); diff != "" {
+				t.Errorf("run action mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedPaged, paged); diff != "" {
+				t.Errorf("paged mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFuzzyFinderDetailScroll(t *testing.T) {
+	for name, tt := range map[string]struct {
+		actions  []Action
+		expected int
+	}{
+		"down":             {actions: []Action{ActionDown, ActionDown}, expected: 2},
+		"up at the top":    {actions: []Action{ActionUp}, expected: 0},
+		"page down":        {actions: []Action{ActionPageDown}, expected: 5},
+		"past the bottom":  {actions: []Action{ActionPageDown, ActionPageDown, ActionPageDown}, expected: 10},
+		"page up and down": {actions: []Action{ActionPageDown, ActionDown, ActionPageUp}, expected: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// The preview of the worktree takes 4 lines, followed by a blank line and 10 lines of details
+			worktrees := []*worktree.Worktree{{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth", IsClean: true}}
+			finder := NewFuzzyFinder(worktrees).WithDetails(func(*worktree.Worktree) string {
+				return strings.Repeat("commit\n", 9) + "commit"
+			})
+			state := &finderState{matched: []fieldMatch{{}}, detail: true}
+
+			for _, action := range tt.actions {
+				finder.detailKey(nil, state, action, 5)
+			}
+			if diff := cmp.Diff(tt.expected, state.detailOffset); diff != "" {
+				t.Errorf("detail offset mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Action is something the interactive UIs do in response to a key.
+type Action string
+
+const (
+	// ActionNone unbinds a key, so that it is ignored or typed into the query.
+	ActionNone Action = "none"
+
+	ActionUp                 Action = "up"
+	ActionDown               Action = "down"
+	ActionPageUp             Action = "page-up"
+	ActionPageDown           Action = "page-down"
+	ActionAccept             Action = "accept"
+	ActionAbort              Action = "abort"
+	ActionBackwardDeleteChar Action = "backward-delete-char"
+	ActionBackwardKillWord   Action = "backward-kill-word"
+	ActionClearQuery         Action = "clear-query"
+
+	// ActionDetails opens the detail pane of the highlighted worktree in the
+	// fuzzy finder, or closes it, and ActionPager shows its full diff in the
+	// pager, from either the list or the pane.
+	ActionDetails Action = "details"
+	ActionPager   Action = "pager"
+
+	// The inline actions end the selection like ActionAccept, asking the command
+	// to act on the worktree instead; commands may then reopen the finder.
+	ActionDelete     Action = "delete"
+	ActionOpenEditor Action = "open-editor"
+	ActionOpenTmux   Action = "open-tmux"
+	ActionCopyPath   Action = "copy-path"
+	ActionStatus     Action = "status"
+)
+
+// actions lists the valid actions and whether they act on the highlighted worktree.
+var actions = map[Action]bool{
+	ActionNone:               false,
+	ActionUp:                 false,
+	ActionDown:               false,
+	ActionPageUp:             false,
+	ActionPageDown:           false,
+	ActionAccept:             true,
+	ActionAbort:              false,
+	ActionBackwardDeleteChar: false,
+	ActionBackwardKillWord:   false,
+	ActionClearQuery:         false,
+	ActionDetails:            false,
+	ActionPager:              false,
+	ActionDelete:             true,
+	ActionOpenEditor:         true,
+	ActionOpenTmux:           true,
+	ActionCopyPath:           true,
+	ActionStatus:             true,
+}
+
+// Keymap maps key names such as "ctrl-j", "enter" or "d" to actions.
+type Keymap map[string]Action
+
+// DefaultFinderKeymap returns the fuzzy finder's default bindings, matching those of fzf.
+func DefaultFinderKeymap() Keymap {
+	return Keymap{
+		"up":        ActionUp,
+		"ctrl-k":    ActionUp,
+		"ctrl-p":    ActionUp,
+		"down":      ActionDown,
+		"ctrl-j":    ActionDown,
+		"ctrl-n":    ActionDown,
+		"pgup":      ActionPageUp,
+		"pgdn":      ActionPageDown,
+		"enter":     ActionAccept,
+		"esc":       ActionAbort,
+		"ctrl-c":    ActionAbort,
+		"ctrl-d":    ActionAbort,
+		"backspace": ActionBackwardDeleteChar,
+		"ctrl-h":    ActionBackwardDeleteChar,
+		"ctrl-w":    ActionBackwardKillWord,
+		"ctrl-u":    ActionClearQuery,
+		"tab":       ActionDetails,
+		"ctrl-v":    ActionPager,
+		"ctrl-x":    ActionDelete,
+		"ctrl-o":    ActionOpenEditor,
+		"ctrl-t":    ActionOpenTmux,
+		"ctrl-y":    ActionCopyPath,
+		"ctrl-s":    ActionStatus,
+	}
+}
+
+// DefaultSelectorKeymap returns the numbered selector's default bindings.
+// The selector reads whole lines, so its keys are single characters typed
+// before the number, e.g. "d2" to run the action bound to "d" on entry 2.
+func DefaultSelectorKeymap() Keymap {
+	return Keymap{
+		"q": ActionAbort,
+		"d": ActionDelete,
+		"e": ActionOpenEditor,
+		"t": ActionOpenTmux,
+		"y": ActionCopyPath,
+		"s": ActionStatus,
+	}
+}
+
+// WithOverrides returns a copy of k with the bindings in overrides applied,
+// as read from the keymap section of the configuration file.
+func (k Keymap) WithOverrides(overrides map[string]string) (Keymap, error) {
+	merged := make(Keymap, len(k)+len(overrides))
+	for key, action := range k {
+		merged[key] = action
+	}
+
+	for key, name := range overrides {
+		normalized, err := normalizeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		action := Action(name)
+		if _, ok := actions[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q for key %q (valid actions: %s)", name, key, strings.Join(actionNames(), ", "))
+		}
+		merged[normalized] = action
+	}
+
+	return merged, nil
+}
+
+// Lookup returns the action bound to key, or ActionNone.
+func (k Keymap) Lookup(key string) Action {
+	if action, ok := k[key]; ok {
+		return action
+	}
+	return ActionNone
+}
+
+// normalizeKey validates a key name from the configuration and returns its canonical form.
+// Special keys are case-insensitive and "ctrl+x" is accepted for "ctrl-x".
+func normalizeKey(key string) (string, error) {
+	name := strings.TrimSpace(key)
+	if utf8.RuneCountInString(name) == 1 {
+		return name, nil
+	}
+
+	name = strings.ToLower(name)
+	if letter, ok := strings.CutPrefix(name, "ctrl+"); ok {
+		name = "ctrl-" + letter
+	}
+	if _, ok := namedKeys[name]; ok {
+		return name, nil
+	}
+	if letter, ok := strings.CutPrefix(name, "ctrl-"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return name, nil
+	}
+	return "", fmt.Errorf("unknown key %q", key)
+}
+
+// namedKeys maps the names of special keys to their tcell keys.
+var namedKeys = map[string]tcell.Key{
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+	"pgup":      tcell.KeyPgUp,
+	"pgdn":      tcell.KeyPgDn,
+	"home":      tcell.KeyHome,
+	"end":       tcell.KeyEnd,
+	"enter":     tcell.KeyEnter,
+	"esc":       tcell.KeyEsc,
+	"tab":       tcell.KeyTab,
+	"backspace": tcell.KeyBackspace2,
+	"delete":    tcell.KeyDelete,
+	"f1":        tcell.KeyF1,
+	"f2":        tcell.KeyF2,
+	"f3":        tcell.KeyF3,
+	"f4":        tcell.KeyF4,
+	"f5":        tcell.KeyF5,
+	"f6":        tcell.KeyF6,
+	"f7":        tcell.KeyF7,
+	"f8":        tcell.KeyF8,
+	"f9":        tcell.KeyF9,
+	"f10":       tcell.KeyF10,
+	"f11":       tcell.KeyF11,
+	"f12":       tcell.KeyF12,
+}
+
+// keyName returns the name of the key pressed in ev, as used in keymaps.
+func keyName(ev *tcell.EventKey) string {
+	switch key := ev.Key(); {
+	case key == tcell.KeyRune:
+		return string(ev.Rune())
+	case key >= tcell.KeyCtrlA && key <= tcell.KeyCtrlZ:
+		// Terminals send enter, tab and backspace as control characters
+		switch key {
+		case tcell.KeyEnter:
+			return "enter"
+		case tcell.KeyTab:
+			return "tab"
+		}
+		return "ctrl-" + string(rune('a'+key-tcell.KeyCtrlA))
+	}
+
+	for name, key := range namedKeys {
+		if key == ev.Key() {
+			return name
+		}
+	}
+	return ""
+}
+
+// actionNames returns the names of all actions, sorted.
+func actionNames() []string {
+	names := make([]string, 0, len(actions))
+	for action := range actions {
+		names = append(names, string(action))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// actionHints lists the keys bound to the enabled inline actions, e.g. "ctrl-x delete".
+func actionHints(keymap Keymap, enabled map[Action]bool) string {
+	var hints []string
+	for key, action := range keymap {
+		if action != ActionAccept && enabled[action] {
+			hints = append(hints, key+" "+string(action))
+		}
+	}
+	sort.Strings(hints)
+	return strings.Join(hints, "  ")
+}
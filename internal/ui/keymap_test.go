@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKeymapWithOverrides(t *testing.T) {
+	for name, tt := range map[string]struct {
+		overrides map[string]string
+		key       string
+		expected  Action
+		wantError bool
+	}{
+		"default binding": {
+			key:      "ctrl-j",
+			expected: ActionDown,
+		},
+		"rebound key": {
+			overrides: map[string]string{"ctrl-d": "delete"},
+			key:       "ctrl-d",
+			expected:  ActionDelete,
+		},
+		"plus syntax and case": {
+			overrides: map[string]string{"Ctrl+O": "open-editor"},
+			key:       "ctrl-o",
+			expected:  ActionOpenEditor,
+		},
+		"unbound key": {
+			overrides: map[string]string{"ctrl-u": "none"},
+			key:       "ctrl-u",
+			expected:  ActionNone,
+		},
+		"single character": {
+			overrides: map[string]string{"D": "delete"},
+			key:       "D",
+			expected:  ActionDelete,
+		},
+		"unknown action": {
+			overrides: map[string]string{"ctrl-d": "explode"},
+			wantError: true,
+		},
+		"unknown key": {
+			overrides: map[string]string{"hyper-x": "delete"},
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keymap, err := DefaultFinderKeymap().WithOverrides(tt.overrides)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("WithOverrides expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WithOverrides unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, keymap.Lookup(tt.key)); diff != "" {
+				t.Errorf("Lookup(%q) mismatch (-want +got):\n%s", tt.key, diff)
+			}
+		})
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		event    *tcell.EventKey
+		expected string
+	}{
+		"rune": {
+			event:    tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			expected: "x",
+		},
+		"control key": {
+			event:    tcell.NewEventKey(tcell.KeyCtrlJ, 0, tcell.ModCtrl),
+			expected: "ctrl-j",
+		},
+		"enter": {
+			event:    tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+			expected: "enter",
+		},
+		"backspace": {
+			event:    tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone),
+			expected: "backspace",
+		},
+		"special key": {
+			event:    tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModNone),
+			expected: "pgdn",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, keyName(tt.event)); diff != "" {
+				t.Errorf("keyName mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestActionHints(t *testing.T) {
+	t.Parallel()
+
+	keymap := Keymap{"enter": ActionAccept, "ctrl-x": ActionDelete, "ctrl-y": ActionCopyPath, "ctrl-o": ActionOpenEditor}
+	enabled := map[Action]bool{ActionAccept: true, ActionDelete: true, ActionCopyPath: true}
+
+	if diff := cmp.Diff("ctrl-x delete  ctrl-y copy-path", actionHints(keymap, enabled)); diff != "" {
+		t.Errorf("actionHints mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether standard output is connected to a terminal.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// IsInteractive reports whether the user can answer prompts and drive the fuzzy finder,
+// which requires both standard input and standard error to be terminals.
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// Page shows content through the user's pager so that long output can be scrolled.
+// It honors $PAGER, defaults to less, and prints directly when not attached to a terminal.
+func Page(content string) error {
+	if !IsTerminal() {
+		_, err := fmt.Print(content)
+		return err
+	}
+
+	cmd := pagerCommand("less -FRX")
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		// Fall back to plain output if the pager is unavailable
+		_, printErr := fmt.Print(content)
+		return printErr
+	}
+	return nil
+}
+
+// pageInTerminal shows content through the user's pager for the fuzzy finder,
+// whose screen is suspended meanwhile. The pager writes to standard error, the
+// terminal even when the shell integration captures standard output, and less
+// does not quit on short content, which the finder's screen would cover again.
+func pageInTerminal(content string) error {
+	cmd := pagerCommand("less -R")
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// pagerCommand returns the command of $PAGER, or of fallback if it is not set.
+func pagerCommand(fallback string) *exec.Cmd {
+	pager := strings.TrimSpace(os.Getenv("PAGER"))
+	if pager == "" {
+		pager = fallback
+	}
+	fields := strings.Fields(pager)
+	return exec.Command(fields[0], fields[1:]...)
+}
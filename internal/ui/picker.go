@@ -0,0 +1,17 @@
+package ui
+
+import "github.com/knwoop/giwo/pkg/worktree"
+
+// Picker lets the user pick a worktree and an action for it.
+// FuzzyFinder and Selector implement it, so commands can dispatch actions
+// the same way whichever UI is in use.
+type Picker interface {
+	// Pick returns the chosen worktree and action, or nil and ActionAbort if the user cancels.
+	Pick() (*worktree.Worktree, Action, error)
+
+	// SetWorktrees replaces the worktrees offered by the next Pick.
+	SetWorktrees(worktrees []*worktree.Worktree)
+
+	// SetMessage shows a message, such as the outcome of an action, in the next Pick.
+	SetMessage(message string)
+}
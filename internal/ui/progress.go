@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"golang.org/x/term"
+)
+
+// progressBarWidth is the number of cells in a progress bar drawn by FormatProgress.
+const progressBarWidth = 24
+
+// IsStderrTerminal reports whether standard error is connected to a terminal.
+func IsStderrTerminal() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// FormatProgress renders a progress event as one line, e.g.
+// "Receiving objects  [██████░░░░]  45% (450/1000)  2.10 MiB/s  ETA 12s".
+func FormatProgress(event worktree.ProgressEvent) string {
+	return formatProgress(event, DefaultTheme().Icons)
+}
+
+// formatProgress is FormatProgress drawing the bar with the given icons.
+func formatProgress(event worktree.ProgressEvent, icons Icons) string {
+	if event.Total == 0 {
+		line := fmt.Sprintf("%-18s %d", event.Phase, event.Current)
+		if event.Done {
+			line += ", done"
+		}
+		return line
+	}
+
+	filled := progressBarWidth * event.Percent / 100
+	filled = min(max(filled, 0), progressBarWidth)
+	bar := strings.Repeat(icons.BarFull, filled) + strings.Repeat(icons.BarEmpty, progressBarWidth-filled)
+
+	parts := []string{
+		fmt.Sprintf("%-18s [%s] %3d%% (%d/%d)", event.Phase, bar, event.Percent, event.Current, event.Total),
+	}
+	if event.Throughput != "" {
+		parts = append(parts, event.Throughput)
+	}
+	if event.ETA > 0 && !event.Done {
+		parts = append(parts, "ETA "+event.ETA.Round(time.Second).String())
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func TestFormatProgress(t *testing.T) {
+	for name, tt := range map[string]struct {
+		event    worktree.ProgressEvent
+		expected string
+	}{
+		"with throughput and eta": {
+			event:    worktree.ProgressEvent{Phase: "Receiving objects", Current: 500, Total: 1000, Percent: 50, Throughput: "2.10 MiB/s", ETA: 12 * time.Second},
+			expected: "Receiving objects  [████████████░░░░░░░░░░░░]  50% (500/1000)  2.10 MiB/s  ETA 12s",
+		},
+		"done hides eta": {
+			event:    worktree.ProgressEvent{Phase: "Updating files", Current: 10, Total: 10, Percent: 100, ETA: time.Second, Done: true},
+			expected: "Updating files     [████████████████████████] 100% (10/10)",
+		},
+		"without total": {
+			event:    worktree.ProgressEvent{Phase: "Enumerating objects", Current: 1234, Done: true},
+			expected: "Enumerating objects 1234, done",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, FormatProgress(tt.event)); diff != "" {
+				t.Errorf("FormatProgress mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/knwoop/giwo/pkg/worktree"
 )
@@ -14,64 +18,165 @@ import (
 // Selector provides interactive selection functionality for worktrees.
 type Selector struct {
 	worktrees []*worktree.Worktree
+	keymap    Keymap
+	actions   map[Action]bool
+	ages      AgeThresholds
+	theme     Theme
+	message   string
+
+	// autoSelect makes SelectWithFilter return a single match without asking.
+	autoSelect bool
 }
 
 // NewSelector creates a new selector with the given worktrees.
 func NewSelector(worktrees []*worktree.Worktree) *Selector {
 	return &Selector{
-		worktrees: worktrees,
+		worktrees:  worktrees,
+		keymap:     DefaultSelectorKeymap(),
+		actions:    map[Action]bool{ActionAccept: true},
+		ages:       DefaultAgeThresholds(),
+		theme:      DefaultTheme(),
+		autoSelect: true,
+	}
+}
+
+// WithAutoSelect sets whether SelectWithFilter returns a single match without asking.
+func (s *Selector) WithAutoSelect(autoSelect bool) *Selector {
+	s.autoSelect = autoSelect
+	return s
+}
+
+// WithAgeThresholds sets the commit ages at which the age marker of a worktree changes color.
+func (s *Selector) WithAgeThresholds(ages AgeThresholds) *Selector {
+	s.ages = ages
+	return s
+}
+
+// WithTheme sets the icons of the selector.
+func (s *Selector) WithTheme(theme Theme) *Selector {
+	s.theme = theme
+	return s
+}
+
+// WithKeymap replaces the default key bindings.
+func (s *Selector) WithKeymap(keymap Keymap) *Selector {
+	s.keymap = keymap
+	return s
+}
+
+// WithActions enables actions on the selected worktree besides accepting it,
+// chosen by typing the bound key before the number.
+func (s *Selector) WithActions(actions ...Action) *Selector {
+	for _, action := range actions {
+		s.actions[action] = true
 	}
+	return s
+}
+
+// SetWorktrees replaces the worktrees offered.
+func (s *Selector) SetWorktrees(worktrees []*worktree.Worktree) {
+	s.worktrees = worktrees
+}
+
+// SetMessage prints message above the list when the selector is next shown.
+func (s *Selector) SetMessage(message string) {
+	s.message = message
 }
 
 // Select allows the user to interactively select a worktree.
 // It returns the selected worktree or nil if cancelled.
 func (s *Selector) Select() (*worktree.Worktree, error) {
+	selected, _, err := s.Pick()
+	return selected, err
+}
+
+// Pick allows the user to select a worktree and an action for it, as for FuzzyFinder.Pick.
+func (s *Selector) Pick() (*worktree.Worktree, Action, error) {
 	if len(s.worktrees) == 0 {
-		return nil, fmt.Errorf("no worktrees available")
+		return nil, ActionAbort, fmt.Errorf("no worktrees available")
 	}
 
-	// If only one worktree (main), return it directly
-	if len(s.worktrees) == 1 {
-		return s.worktrees[0], nil
+	// If only one worktree (main) and nothing else to do with it, return it directly
+	if len(s.worktrees) == 1 && len(s.actions) == 1 {
+		return s.worktrees[0], ActionAccept, nil
 	}
 
-	fmt.Println("📂 Available worktrees:")
+	if s.message != "" {
+		fmt.Println(s.message)
+		fmt.Println()
+		s.message = ""
+	}
+
+	fmt.Println(Mark(s.theme.Icons.List, "Available worktrees:"))
 	fmt.Println()
 
 	// Display numbered list of worktrees
 	for i, wt := range s.worktrees {
 		status := s.formatWorktreeStatus(wt)
-		fmt.Printf("  %d) %s %s\n", i+1, wt.Branch, status)
+		fmt.Printf("  %d) %s %s\n", i+1, DisplayName(wt), status)
 	}
 
 	fmt.Println()
-	fmt.Printf("Select worktree (1-%d, q to quit): ", len(s.worktrees))
+	fmt.Printf("Select worktree (1-%d%s): ", len(s.worktrees), s.keyHelp())
 
 	// Read user input
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
-		return nil, fmt.Errorf("failed to read input: %w", err)
+		return nil, ActionAbort, fmt.Errorf("failed to read input: %w", err)
 	}
 
-	input = strings.TrimSpace(input)
+	return s.parseInput(strings.TrimSpace(input))
+}
 
-	// Handle quit
-	if strings.ToLower(input) == "q" || input == "" {
-		return nil, nil
+// parseInput interprets a line typed at the selector prompt: a number, optionally
+// preceded by the key of an enabled action, or a key bound to ActionAbort.
+func (s *Selector) parseInput(input string) (*worktree.Worktree, Action, error) {
+	if input == "" {
+		return nil, ActionAbort, nil
+	}
+
+	action := ActionAccept
+	if key, size := utf8.DecodeRuneInString(input); !unicode.IsDigit(key) {
+		action = s.keymap.Lookup(string(key))
+		if action == ActionAbort {
+			return nil, ActionAbort, nil
+		}
+		if !s.actions[action] {
+			return nil, ActionAbort, fmt.Errorf("invalid selection: %s", input)
+		}
+		input = strings.TrimSpace(input[size:])
 	}
 
 	// Parse selection
 	selection, err := strconv.Atoi(input)
 	if err != nil {
-		return nil, fmt.Errorf("invalid selection: %s", input)
+		return nil, ActionAbort, fmt.Errorf("invalid selection: %s", input)
 	}
 
 	if selection < 1 || selection > len(s.worktrees) {
-		return nil, fmt.Errorf("selection out of range: %d", selection)
+		return nil, ActionAbort, fmt.Errorf("selection out of range: %d", selection)
 	}
 
-	return s.worktrees[selection-1], nil
+	return s.worktrees[selection-1], action, nil
+}
+
+// keyHelp describes the keys bound to abort and to enabled actions, for the prompt.
+func (s *Selector) keyHelp() string {
+	var help []string
+	for key, action := range s.keymap {
+		switch {
+		case action == ActionAbort:
+			help = append(help, fmt.Sprintf("%s to quit", key))
+		case action != ActionAccept && s.actions[action]:
+			help = append(help, fmt.Sprintf("%s<n> to %s", key, action))
+		}
+	}
+	if len(help) == 0 {
+		return ""
+	}
+	sort.Strings(help)
+	return ", " + strings.Join(help, ", ")
 }
 
 // SelectWithFilter allows the user to filter and select worktrees.
@@ -80,53 +185,80 @@ func (s *Selector) SelectWithFilter(filter string) (*worktree.Worktree, error) {
 		return s.Select()
 	}
 
-	// Filter worktrees based on branch name
-	var filtered []*worktree.Worktree
-	filter = strings.ToLower(filter)
-
-	for _, wt := range s.worktrees {
-		if strings.Contains(strings.ToLower(wt.Branch), filter) {
-			filtered = append(filtered, wt)
-		}
-	}
-
+	filtered := FilterWorktrees(s.worktrees, filter)
 	if len(filtered) == 0 {
 		return nil, fmt.Errorf("no worktrees match filter: %s", filter)
 	}
 
 	// If only one match, return it directly
-	if len(filtered) == 1 {
+	if len(filtered) == 1 && s.autoSelect {
 		return filtered[0], nil
 	}
 
 	// Create new selector with filtered results
-	filteredSelector := NewSelector(filtered)
-	fmt.Printf("🔍 Filtered worktrees (matching '%s'):\n", filter)
+	filteredSelector := NewSelector(filtered).WithKeymap(s.keymap).WithAgeThresholds(s.ages).WithTheme(s.theme)
+	fmt.Println(Mark(s.theme.Icons.Filter, fmt.Sprintf("Filtered worktrees (matching '%s'):", filter)))
 	fmt.Println()
 
 	return filteredSelector.Select()
 }
 
+// DisplayName returns the name a worktree is shown and matched by in the UIs:
+// its branch, prefixed by the repository name in cross-repo mode.
+func DisplayName(wt *worktree.Worktree) string {
+	if wt.Repo != "" {
+		return wt.Repo + "/" + wt.Branch
+	}
+	return wt.Branch
+}
+
+// BranchLabel returns the branch of a worktree for listings. The worktree jj
+// manages in a colocated repository is named by its jj bookmark instead of its
+// detached HEAD.
+func BranchLabel(wt *worktree.Worktree) string {
+	if wt.Bookmark != "" {
+		return wt.Bookmark + " (jj)"
+	}
+	return wt.Branch
+}
+
 // formatWorktreeStatus returns a formatted status string for a worktree.
 func (s *Selector) formatWorktreeStatus(wt *worktree.Worktree) string {
 	var parts []string
+	icons := s.theme.Icons
+
+	if age := s.ages.Level(wt.ActiveAt(), time.Now()); age != AgeUnknown {
+		if wt.ActivityAge != "" {
+			parts = append(parts, Mark(s.theme.AgeIcon(age), wt.ActivityAge))
+		} else {
+			parts = append(parts, s.theme.AgeIcon(age))
+		}
+	}
 
 	if wt.IsMain {
-		parts = append(parts, "🏠 main")
+		parts = append(parts, Mark(icons.Main, "main"))
 	} else {
-		parts = append(parts, "🌱")
+		parts = append(parts, icons.Feature)
+	}
+
+	if wt.Locked {
+		parts = append(parts, icons.Locked)
+	}
+
+	if len(wt.Labels) > 0 {
+		parts = append(parts, Mark(icons.Labels, FormatLabels(wt.Labels)))
 	}
 
 	if !wt.IsClean {
 		changes := wt.Added + wt.Modified + wt.Deleted
-		parts = append(parts, fmt.Sprintf("⚠️  %d changes", changes))
+		parts = append(parts, Mark(icons.Dirty, fmt.Sprintf("%d changes", changes)))
 	}
 
 	if wt.Ahead > 0 || wt.Behind > 0 {
-		parts = append(parts, fmt.Sprintf("📡 +%d/-%d", wt.Ahead, wt.Behind))
+		parts = append(parts, Mark(icons.Sync, fmt.Sprintf("+%d/-%d", wt.Ahead, wt.Behind)))
 	}
 
-	parts = append(parts, fmt.Sprintf("📁 %s", wt.Path))
+	parts = append(parts, Mark(icons.Path, wt.Path))
 
 	return strings.Join(parts, " ")
 }
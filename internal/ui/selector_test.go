@@ -2,6 +2,7 @@ package ui
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/knwoop/giwo/pkg/worktree"
@@ -57,6 +58,35 @@ func TestFormatWorktreeStatus(t *testing.T) {
 			},
 			expected: "🌱 📡 +2/-1 📁 /repo/.worktree/feature",
 		},
+		"labeled worktree": {
+			worktree: &worktree.Worktree{
+				Branch:  "feature",
+				Path:    "/repo/.worktree/feature",
+				IsClean: true,
+				Labels:  []string{"review", "urgent"},
+			},
+			expected: "🌱 🏷️ #review #urgent 📁 /repo/.worktree/feature",
+		},
+		"stale worktree": {
+			worktree: &worktree.Worktree{
+				Branch:     "old",
+				Path:       "/repo/.worktree/old",
+				IsClean:    true,
+				CommitTime: time.Now().Add(-90 * 24 * time.Hour),
+			},
+			expected: "🟥 🌱 📁 /repo/.worktree/old",
+		},
+		"recently active worktree": {
+			worktree: &worktree.Worktree{
+				Branch:       "old",
+				Path:         "/repo/.worktree/old",
+				IsClean:      true,
+				CommitTime:   time.Now().Add(-90 * 24 * time.Hour),
+				LastActivity: time.Now().Add(-2 * time.Hour),
+				ActivityAge:  "2h ago",
+			},
+			expected: "🟩 2h ago 🌱 📁 /repo/.worktree/old",
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
@@ -70,3 +100,129 @@ func TestFormatWorktreeStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestDisplayName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		worktree *worktree.Worktree
+		expected string
+	}{
+		"single repository": {
+			worktree: &worktree.Worktree{Branch: "feature"},
+			expected: "feature",
+		},
+		"cross-repo mode": {
+			worktree: &worktree.Worktree{Branch: "feature", Repo: "api"},
+			expected: "api/feature",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, DisplayName(tt.worktree)); diff != "" {
+				t.Errorf("DisplayName mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBranchLabel(t *testing.T) {
+	for name, tt := range map[string]struct {
+		worktree *worktree.Worktree
+		expected string
+	}{
+		"branch": {
+			worktree: &worktree.Worktree{Branch: "feature"},
+			expected: "feature",
+		},
+		"jj bookmark": {
+			worktree: &worktree.Worktree{Branch: "HEAD", Detached: true, Bookmark: "main"},
+			expected: "main (jj)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, BranchLabel(tt.worktree)); diff != "" {
+				t.Errorf("BranchLabel mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectorParseInput(t *testing.T) {
+	worktrees := []*worktree.Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature", Path: "/repo/.worktree/feature"},
+	}
+
+	for name, tt := range map[string]struct {
+		input          string
+		expectedBranch string
+		expectedAction Action
+		wantError      bool
+	}{
+		"number": {
+			input:          "2",
+			expectedBranch: "feature",
+			expectedAction: ActionAccept,
+		},
+		"empty input": {
+			input:          "",
+			expectedAction: ActionAbort,
+		},
+		"quit": {
+			input:          "q",
+			expectedAction: ActionAbort,
+		},
+		"action key": {
+			input:          "d2",
+			expectedBranch: "feature",
+			expectedAction: ActionDelete,
+		},
+		"action key with space": {
+			input:          "d 1",
+			expectedBranch: "main",
+			expectedAction: ActionDelete,
+		},
+		"disabled action": {
+			input:     "o2",
+			wantError: true,
+		},
+		"out of range": {
+			input:     "3",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keymap, err := DefaultSelectorKeymap().WithOverrides(map[string]string{"d": "delete", "o": "open-editor"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			selector := NewSelector(worktrees).WithKeymap(keymap).WithActions(ActionDelete)
+
+			selected, action, err := selector.parseInput(tt.input)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("parseInput expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseInput unexpected error: %v", err)
+			}
+
+			branch := ""
+			if selected != nil {
+				branch = selected.Branch
+			}
+			if diff := cmp.Diff(tt.expectedBranch, branch); diff != "" {
+				t.Errorf("parseInput worktree mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedAction, action); diff != "" {
+				t.Errorf("parseInput action mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
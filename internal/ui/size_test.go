@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatSize(t *testing.T) {
+	for name, tt := range map[string]struct {
+		bytes    int64
+		expected string
+	}{
+		"bytes": {
+			bytes:    512,
+			expected: "512 B",
+		},
+		"kilobytes": {
+			bytes:    1536,
+			expected: "1.5 KB",
+		},
+		"gigabytes": {
+			bytes:    3 << 30,
+			expected: "3.0 GB",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, FormatSize(tt.bytes)); diff != "" {
+				t.Errorf("FormatSize mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+	"golang.org/x/term"
+)
+
+// spinnerInterval is how often running steps are redrawn on a terminal.
+const spinnerInterval = 100 * time.Millisecond
+
+// StepReporter shows the steps of long operations as status lines.
+// On a terminal every running step gets a line with a spinner, its elapsed time
+// and the latest git progress, redrawn in place, which turns into a check mark
+// once the step ends. Otherwise a plain line is written when a step starts and ends,
+// which suits logs. It is safe for concurrent use.
+type StepReporter struct {
+	w     io.Writer
+	tty   bool
+	width int
+	now   func() time.Time
+	theme Theme
+
+	mu      sync.Mutex
+	active  []*step
+	drawn   int
+	frame   int
+	ticking bool
+	stop    chan struct{}
+}
+
+// step is a running step.
+type step struct {
+	name    string
+	detail  string
+	started time.Time
+}
+
+// NewStepReporter creates a reporter writing to w. With tty set, w must be a terminal.
+func NewStepReporter(w io.Writer, tty bool) *StepReporter {
+	width := 80
+	if f, ok := w.(*os.File); ok && tty {
+		if cols, _, err := term.GetSize(int(f.Fd())); err == nil && cols > 0 {
+			width = cols
+		}
+	}
+	return &StepReporter{w: w, tty: tty, width: width, now: time.Now, theme: DefaultTheme()}
+}
+
+// WithTheme sets the icons of the status lines and the spinner.
+func (r *StepReporter) WithTheme(theme Theme) *StepReporter {
+	r.theme = theme
+	return r
+}
+
+// Start reports that the named step started and returns the function ending it
+// with the step's result. It matches worktree.StepFunc.
+func (r *StepReporter) Start(name string) func(error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &step{name: name, started: r.now()}
+	r.active = append(r.active, s)
+
+	if !r.tty {
+		fmt.Fprintln(r.w, Mark(r.theme.Icons.Running, name+"..."))
+	} else {
+		r.redraw()
+		if !r.ticking {
+			r.ticking = true
+			r.stop = make(chan struct{})
+			go r.tick(r.stop)
+		}
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { r.finish(s, err) })
+	}
+}
+
+// Progress shows a git progress event on the line of the most recent running step.
+func (r *StepReporter) Progress(event worktree.ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.tty || len(r.active) == 0 {
+		return
+	}
+	r.active[len(r.active)-1].detail = formatProgress(event, r.theme.Icons)
+	r.redraw()
+}
+
+// Stop ends the output, erasing the lines of steps that never ended.
+func (r *StepReporter) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ticking {
+		r.ticking = false
+		close(r.stop)
+	}
+	r.clear()
+	r.active = nil
+}
+
+// finish removes s from the running steps and writes its outcome.
+func (r *StepReporter) finish(s *step, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, active := range r.active {
+		if active == s {
+			r.active = append(r.active[:i], r.active[i+1:]...)
+			break
+		}
+	}
+
+	elapsed := formatElapsed(r.now().Sub(s.started))
+	line := Mark(r.theme.Icons.Done, fmt.Sprintf("%s (%s)", s.name, elapsed))
+	if err != nil {
+		line = Mark(r.theme.Icons.Failed, fmt.Sprintf("%s failed (%s)", s.name, elapsed))
+	}
+
+	r.clear()
+	fmt.Fprintln(r.w, line)
+	if r.tty {
+		r.draw()
+	}
+}
+
+// tick redraws the running steps until stop is closed.
+func (r *StepReporter) tick(stop chan struct{}) {
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			r.frame++
+			r.redraw()
+			r.mu.Unlock()
+		}
+	}
+}
+
+// redraw replaces the drawn lines with the current state of the running steps.
+func (r *StepReporter) redraw() {
+	r.clear()
+	r.draw()
+}
+
+// clear erases the lines drawn for running steps, leaving the cursor where the first was.
+func (r *StepReporter) clear() {
+	if r.drawn > 0 {
+		fmt.Fprintf(r.w, "\x1b[%dA\x1b[J", r.drawn)
+		r.drawn = 0
+	}
+}
+
+// draw writes a line for each running step. Lines are cut to the terminal width
+// since wrapped lines could not be erased reliably.
+func (r *StepReporter) draw() {
+	spinner := r.theme.Icons.Spinner
+	frame := spinner[r.frame%len(spinner)]
+	for _, s := range r.active {
+		line := fmt.Sprintf("%s %s (%s)", frame, s.name, formatElapsed(r.now().Sub(s.started)))
+		if s.detail != "" {
+			line += "  " + s.detail
+		}
+		fmt.Fprintln(r.w, truncateRunes(line, r.width-1))
+	}
+	r.drawn = len(r.active)
+}
+
+// formatElapsed formats the duration of a step, e.g. "0.4s" or "1m12s".
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// truncateRunes cuts s to at most n runes.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	return strings.TrimRight(string(runes[:n]), " ")
+}
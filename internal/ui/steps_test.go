@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+// fakeClock returns times that advance by step on every call.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestStepReporterPlain(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	reporter := NewStepReporter(&out, false)
+	reporter.now = fakeClock(time.Second)
+
+	fetch := reporter.Start("Fetching origin")
+	reporter.Progress(worktree.ProgressEvent{Phase: "Receiving objects", Current: 1})
+	checkout := reporter.Start("Checking out feature")
+	fetch(nil)
+	checkout(errors.New("boom"))
+	checkout(nil)
+	reporter.Stop()
+
+	expected := "⏳ Fetching origin...\n" +
+		"⏳ Checking out feature...\n" +
+		"✅ Fetching origin (2.0s)\n" +
+		"❌ Checking out feature failed (2.0s)\n"
+	if diff := cmp.Diff(expected, out.String()); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStepReporterTerminal(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	// Marked as ticking so that no spinner goroutine redraws behind the test's back
+	reporter := &StepReporter{w: &out, tty: true, width: 40, now: fakeClock(time.Second), theme: DefaultTheme(), ticking: true, stop: make(chan struct{})}
+
+	done := reporter.Start("Fetching origin")
+	reporter.Progress(worktree.ProgressEvent{Phase: "Receiving objects", Current: 5, Total: 10, Percent: 50})
+	done(nil)
+	reporter.Stop()
+
+	expected := "⠋ Fetching origin (1.0s)\n" +
+		"\x1b[1A\x1b[J⠋ Fetching origin (2.0s)  Receiving obj\n" +
+		"\x1b[1A\x1b[J✅ Fetching origin (3.0s)\n"
+	if diff := cmp.Diff(expected, out.String()); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	for name, tt := range map[string]struct {
+		d        time.Duration
+		expected string
+	}{
+		"subsecond": {d: 420 * time.Millisecond, expected: "0.4s"},
+		"seconds":   {d: 12300 * time.Millisecond, expected: "12.3s"},
+		"minutes":   {d: 72*time.Second + 400*time.Millisecond, expected: "1m12s"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, formatElapsed(tt.d)); diff != "" {
+				t.Errorf("formatElapsed mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// tablePadding is the number of spaces between columns.
+const tablePadding = 2
+
+// Table aligns tab-terminated cells into columns like text/tabwriter, but
+// measures cells by their width on the terminal, so that branch names in wide
+// scripts such as "feature/ブックマーク追加" do not push the following columns
+// out of line. Output is buffered until Flush.
+type Table struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewTable returns a Table writing to w.
+func NewTable(w io.Writer) *Table {
+	return &Table{w: w}
+}
+
+// Write buffers p. Cells are terminated by tabs and lines by newlines.
+func (t *Table) Write(p []byte) (int, error) {
+	return t.buf.Write(p)
+}
+
+// Flush writes the buffered lines with their cells padded to the widest cell
+// of each column. The last cell of a line is not padded.
+func (t *Table) Flush() error {
+	text := t.buf.String()
+	t.buf.Reset()
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	rows := make([][]string, len(lines))
+	var widths []int
+	for i, line := range lines {
+		rows[i] = strings.Split(line, "\t")
+		for col, cell := range rows[i][:len(rows[i])-1] {
+			if col == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[col] = max(widths[col], runewidth.StringWidth(cell))
+		}
+	}
+
+	var out strings.Builder
+	for _, cells := range rows {
+		last := len(cells) - 1
+		for col, cell := range cells[:last] {
+			out.WriteString(cell)
+			out.WriteString(strings.Repeat(" ", widths[col]-runewidth.StringWidth(cell)+tablePadding))
+		}
+		out.WriteString(cells[last])
+	}
+	_, err := io.WriteString(t.w, out.String())
+	return err
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTable(t *testing.T) {
+	for name, tt := range map[string]struct {
+		input    string
+		expected string
+	}{
+		"ascii": {
+			input:    "BRANCH\tPATH\nmain\t/repo\nfeature-x\t/repo/.worktree/feature-x\n",
+			expected: "BRANCH     PATH\nmain       /repo\nfeature-x  /repo/.worktree/feature-x\n",
+		},
+		"wide characters": {
+			input:    "BRANCH\tAGE\nmain\t1d\nfeature/追加\t2h\n",
+			expected: "BRANCH        AGE\nmain          1d\nfeature/追加  2h\n",
+		},
+		"ragged rows": {
+			input:    "a\tb\tc\nlonger\n",
+			expected: "a  b  c\nlonger\n",
+		},
+		"no trailing newline": {
+			input:    "a\tb",
+			expected: "a  b",
+		},
+		"empty": {
+			input:    "",
+			expected: "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var out strings.Builder
+			table := NewTable(&out)
+			fmt.Fprint(table, tt.input)
+			if err := table.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, out.String()); diff != "" {
+				t.Errorf("Table mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Names of the built-in themes.
+const (
+	// ThemeEmoji marks states with emoji, as giwo always has.
+	ThemeEmoji = "emoji"
+
+	// ThemeNerd uses the glyphs of nerd fonts, which line up better than
+	// emoji in terminals that have such a font.
+	ThemeNerd = "nerd"
+
+	// ThemeASCII uses plain ASCII only, for minimal terminals and CI logs.
+	ThemeASCII = "ascii"
+
+	// ThemeAuto picks ThemeASCII for dumb terminals and ThemeEmoji otherwise.
+	ThemeAuto = "auto"
+)
+
+// ColorRole is what a color of a theme is used for.
+type ColorRole string
+
+// Color roles of the fuzzy finder and the chooser.
+const (
+	ColorPrompt ColorRole = "prompt" // the "> " before the query
+	ColorCount  ColorRole = "count"  // the number of matches
+	ColorHeader ColorRole = "header" // what the selection is for
+	ColorHint   ColorRole = "hint"   // the key hints and secondary fields
+	ColorCursor ColorRole = "cursor" // the highlighted line
+	ColorMarker ColorRole = "marker" // the "> " in front of the highlighted line
+	ColorMatch  ColorRole = "match"  // the runes matching the query
+	ColorBorder ColorRole = "border" // the border of the preview
+
+	// Colors of the commit age markers, see AgeLevel.
+	ColorFresh   ColorRole = "fresh"
+	ColorRecent  ColorRole = "recent"
+	ColorAging   ColorRole = "aging"
+	ColorStale   ColorRole = "stale"
+	ColorUnknown ColorRole = "unknown"
+)
+
+// Icons are the glyphs the UI components mark states with.
+type Icons struct {
+	Main     string // the main worktree
+	Feature  string // other worktrees
+	Locked   string
+	Labels   string
+	Dirty    string // uncommitted changes
+	Clean    string
+	Sync     string // commits ahead of and behind the upstream
+	Path     string
+	List     string // heads the selector's list
+	Filter   string // heads the selector's list when filtered
+	Running  string // a step that started, in logs
+	Done     string // a step that succeeded
+	Failed   string // a step that failed
+	Marker   string // the age marker in the fuzzy finder, colored by age
+	Border   rune   // the border of the fuzzy finder's preview
+	BarFull  string // the done part of progress bars
+	BarEmpty string // the rest of progress bars
+
+	// Ages mark the commit age of worktrees in the selector and 'giwo list'.
+	Ages map[AgeLevel]string
+
+	// Spinner is drawn in turn in front of running steps on a terminal.
+	Spinner []string
+}
+
+// Theme decides how the UI components look: the colors of the fuzzy finder
+// and the chooser, and the icons marking the states of worktrees and steps.
+type Theme struct {
+	Name   string
+	Icons  Icons
+	Colors map[ColorRole]tcell.Color
+
+	// noColor draws everything in the terminal's default colors, telling the
+	// highlighted line and matches apart by attributes only.
+	noColor bool
+}
+
+// defaultColors are the colors of all built-in themes.
+var defaultColors = map[ColorRole]tcell.Color{
+	ColorPrompt:  tcell.ColorBlue,
+	ColorCount:   tcell.ColorYellow,
+	ColorHeader:  tcell.ColorGreen,
+	ColorHint:    tcell.ColorGray,
+	ColorCursor:  tcell.ColorYellow,
+	ColorMarker:  tcell.ColorRed,
+	ColorMatch:   tcell.ColorGreen,
+	ColorBorder:  tcell.ColorGray,
+	ColorFresh:   tcell.ColorGreen,
+	ColorRecent:  tcell.ColorYellow,
+	ColorAging:   tcell.ColorOrange,
+	ColorStale:   tcell.ColorRed,
+	ColorUnknown: tcell.ColorGray,
+}
+
+// themeIcons are the icons of the built-in themes.
+var themeIcons = map[string]Icons{
+	// The warning sign is drawn one cell wide by many terminals, so it brings
+	// a space of its own
+	ThemeEmoji: {
+		Main: "🏠", Feature: "🌱", Locked: "🔒", Labels: "🏷️", Dirty: "⚠️ ", Clean: "✅", Sync: "📡", Path: "📁",
+		List: "📂", Filter: "🔍", Running: "⏳", Done: "✅", Failed: "❌",
+		Marker: "■", Border: '│', BarFull: "█", BarEmpty: "░",
+		Ages:    map[AgeLevel]string{AgeFresh: "🟩", AgeRecent: "🟨", AgeAging: "🟧", AgeStale: "🟥", AgeUnknown: "⬜"},
+		Spinner: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	},
+	ThemeNerd: {
+		Main: "\uf015", Feature: "\ue725", Locked: "\uf023", Labels: "\uf02b", Dirty: "\uf071", Clean: "\uf00c", Sync: "\uf021", Path: "\uf07b",
+		List: "\uf07c", Filter: "\uf002", Running: "\uf254", Done: "\uf00c", Failed: "\uf00d",
+		Marker: "\uf0c8", Border: '│', BarFull: "█", BarEmpty: "░",
+		Ages:    map[AgeLevel]string{AgeFresh: "\uf111", AgeRecent: "\uf111", AgeAging: "\uf111", AgeStale: "\uf111", AgeUnknown: "\uf10c"},
+		Spinner: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	},
+	ThemeASCII: {
+		Main: "^", Feature: "*", Locked: "[locked]", Labels: "labels:", Dirty: "!", Clean: "ok", Sync: "sync", Path: "@",
+		List: "==", Filter: "==", Running: "..", Done: "ok", Failed: "!!",
+		Marker: "#", Border: '|', BarFull: "#", BarEmpty: "-",
+		Ages:    map[AgeLevel]string{AgeFresh: "+", AgeRecent: "~", AgeAging: "-", AgeStale: "!", AgeUnknown: "?"},
+		Spinner: []string{"|", "/", "-", "\\"},
+	},
+}
+
+// DefaultTheme returns the emoji theme.
+func DefaultTheme() Theme {
+	theme, _ := LookupTheme(ThemeEmoji)
+	return theme
+}
+
+// ThemeNames returns the names LookupTheme accepts.
+func ThemeNames() []string {
+	return append(slices.Sorted(maps.Keys(themeIcons)), ThemeAuto)
+}
+
+// LookupTheme returns the built-in theme called name. ThemeAuto and "" pick
+// the theme for the terminal in $TERM.
+func LookupTheme(name string) (Theme, error) {
+	if name == "" || name == ThemeAuto {
+		name = ThemeEmoji
+		if term := os.Getenv("TERM"); term == "dumb" {
+			name = ThemeASCII
+		}
+	}
+	icons, ok := themeIcons[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q: must be one of %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	return Theme{Name: name, Icons: icons, Colors: maps.Clone(defaultColors)}, nil
+}
+
+// WithColors returns the theme with the colors of the given roles replaced.
+// Colors are names such as "yellow" or "darkcyan", or hex values such as
+// "#ff8800"; "default" is the terminal's color.
+func (t Theme) WithColors(colors map[string]string) (Theme, error) {
+	if len(colors) == 0 {
+		return t, nil
+	}
+	t.Colors = maps.Clone(t.Colors)
+	for role, value := range colors {
+		if _, ok := defaultColors[ColorRole(role)]; !ok {
+			roles := slices.Sorted(maps.Keys(defaultColors))
+			names := make([]string, len(roles))
+			for i, r := range roles {
+				names[i] = string(r)
+			}
+			return t, fmt.Errorf("unknown color %q: must be one of %s", role, strings.Join(names, ", "))
+		}
+		color := tcell.GetColor(strings.ToLower(value))
+		if color == tcell.ColorDefault && value != "default" {
+			return t, fmt.Errorf("invalid color %q for %s: use a name such as yellow or a hex value such as #ff8800", value, role)
+		}
+		t.Colors[ColorRole(role)] = color
+	}
+	return t, nil
+}
+
+// WithoutColor returns the theme drawing in the terminal's default colors,
+// as asked for by NO_COLOR.
+func (t Theme) WithoutColor() Theme {
+	t.noColor = true
+	return t
+}
+
+// NoColorRequested reports whether the user asked for output without colors
+// by setting $NO_COLOR, see https://no-color.org.
+func NoColorRequested() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// fg returns style in the color of role.
+func (t Theme) fg(style tcell.Style, role ColorRole) tcell.Style {
+	if t.noColor {
+		return style
+	}
+	color, ok := t.Colors[role]
+	if !ok {
+		return style
+	}
+	return style.Foreground(color)
+}
+
+// cursorStyle returns the style of the highlighted line.
+func (t Theme) cursorStyle() tcell.Style {
+	style := tcell.StyleDefault.Bold(true)
+	if t.noColor {
+		return style.Reverse(true)
+	}
+	return t.fg(style, ColorCursor).Background(tcell.ColorBlack)
+}
+
+// matchStyle returns style for the runes matching the query.
+func (t Theme) matchStyle(style tcell.Style) tcell.Style {
+	if t.noColor {
+		return style.Bold(true).Underline(true)
+	}
+	return t.fg(style, ColorMatch).Bold(true)
+}
+
+// ageColor returns the color role of an age level.
+func ageColor(level AgeLevel) ColorRole {
+	switch level {
+	case AgeFresh:
+		return ColorFresh
+	case AgeRecent:
+		return ColorRecent
+	case AgeAging:
+		return ColorAging
+	case AgeStale:
+		return ColorStale
+	default:
+		return ColorUnknown
+	}
+}
+
+// AgeIcon returns the icon marking a commit age of the given level.
+func (t Theme) AgeIcon(level AgeLevel) string {
+	return t.Icons.Ages[level]
+}
+
+// Mark prefixes text with icon, unless the theme has none.
+func Mark(icon, text string) string {
+	if icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLookupTheme(t *testing.T) {
+	for name, tt := range map[string]struct {
+		name      string
+		term      string
+		expected  string
+		wantError bool
+	}{
+		"emoji": {
+			name:     ThemeEmoji,
+			expected: ThemeEmoji,
+		},
+		"nerd": {
+			name:     ThemeNerd,
+			expected: ThemeNerd,
+		},
+		"auto on a color terminal": {
+			name:     ThemeAuto,
+			term:     "xterm-256color",
+			expected: ThemeEmoji,
+		},
+		"auto on a dumb terminal": {
+			name:     ThemeAuto,
+			term:     "dumb",
+			expected: ThemeASCII,
+		},
+		"unset on a dumb terminal": {
+			term:     "dumb",
+			expected: ThemeASCII,
+		},
+		"unknown": {
+			name:      "solarized",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("TERM", tt.term)
+
+			theme, err := LookupTheme(tt.name)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupTheme() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, theme.Name); diff != "" {
+				t.Errorf("Name mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestThemeWithColors(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		colors    map[string]string
+		role      ColorRole
+		expected  tcell.Color
+		wantError bool
+	}{
+		"name": {
+			colors:   map[string]string{"match": "DarkCyan"},
+			role:     ColorMatch,
+			expected: tcell.ColorDarkCyan,
+		},
+		"hex value": {
+			colors:   map[string]string{"cursor": "#ff8800"},
+			role:     ColorCursor,
+			expected: tcell.NewHexColor(0xff8800),
+		},
+		"terminal default": {
+			colors:   map[string]string{"prompt": "default"},
+			role:     ColorPrompt,
+			expected: tcell.ColorDefault,
+		},
+		"others kept": {
+			colors:   map[string]string{"match": "red"},
+			role:     ColorHeader,
+			expected: tcell.ColorGreen,
+		},
+		"unknown role": {
+			colors:    map[string]string{"background": "red"},
+			wantError: true,
+		},
+		"invalid color": {
+			colors:    map[string]string{"match": "shiny"},
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			theme, err := DefaultTheme().WithColors(tt.colors)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WithColors() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, theme.Colors[tt.role]); diff != "" {
+				t.Errorf("color mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestThemeWithColorsKeepsDefaults(t *testing.T) {
+	t.Parallel()
+
+	if _, err := DefaultTheme().WithColors(map[string]string{"match": "red"}); err != nil {
+		t.Fatalf("WithColors() error = %v", err)
+	}
+	if diff := cmp.Diff(tcell.ColorGreen, DefaultTheme().Colors[ColorMatch]); diff != "" {
+		t.Errorf("default color changed (-want +got):\n%s", diff)
+	}
+}
+
+func TestASCIIThemeIcons(t *testing.T) {
+	t.Parallel()
+
+	icons := themeIcons[ThemeASCII]
+	texts := []string{
+		icons.Main, icons.Feature, icons.Locked, icons.Labels, icons.Dirty, icons.Clean, icons.Sync, icons.Path,
+		icons.List, icons.Filter, icons.Running, icons.Done, icons.Failed, icons.Marker, string(icons.Border),
+		icons.BarFull, icons.BarEmpty,
+	}
+	for _, icon := range icons.Ages {
+		texts = append(texts, icon)
+	}
+	texts = append(texts, icons.Spinner...)
+	for _, text := range texts {
+		for _, r := range text {
+			if r > unicode.MaxASCII {
+				t.Errorf("icon %q is not ASCII", text)
+			}
+		}
+	}
+}
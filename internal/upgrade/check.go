@@ -0,0 +1,76 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkFileName is the name of the file inside the state directory that
+// records the last check for a new release.
+const checkFileName = "upgrade-check.json"
+
+// CheckInterval is how often giwo looks for a new release, and reminds the
+// user of one, on its own.
+const CheckInterval = 24 * time.Hour
+
+// CheckState records the last check for a new release.
+type CheckState struct {
+	// CheckedAt is when releases were last looked up, successfully or not.
+	CheckedAt time.Time `json:"checked_at"`
+
+	// Latest is the newest release found then, on the channel checked.
+	Latest  string `json:"latest,omitempty"`
+	Channel string `json:"channel,omitempty"`
+
+	// NotifiedAt is when the user was last told about Latest.
+	NotifiedAt time.Time `json:"notified_at,omitzero"`
+}
+
+// LoadCheck reads the state of the last check from stateDir. Without one, it
+// returns the zero state, which is due for a check.
+func LoadCheck(stateDir string) (*CheckState, error) {
+	data, err := os.ReadFile(filepath.Join(stateDir, checkFileName))
+	if os.IsNotExist(err) {
+		return &CheckState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upgrade check: %w", err)
+	}
+
+	var state CheckState
+	if err := json.Unmarshal(data, &state); err != nil {
+		// A corrupt file only means checking again
+		return &CheckState{}, nil
+	}
+	return &state, nil
+}
+
+// Save writes the state to stateDir.
+func (s *CheckState) Save(stateDir string) error {
+	if err := os.MkdirAll(stateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stateDir, checkFileName), data, 0o644)
+}
+
+// Due reports whether releases on channel should be looked up again at now.
+func (s *CheckState) Due(channel string, now time.Time) bool {
+	return s.Channel != channel || now.Sub(s.CheckedAt) >= CheckInterval
+}
+
+// Notice returns the version the user should be told about at now, running
+// version current: Latest if it is newer and they were not told in the last
+// CheckInterval, and "" otherwise.
+func (s *CheckState) Notice(current string, now time.Time) string {
+	if s.Latest == "" || Compare(s.Latest, current) <= 0 || now.Sub(s.NotifiedAt) < CheckInterval {
+		return ""
+	}
+	return s.Latest
+}
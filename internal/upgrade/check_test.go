@@ -0,0 +1,89 @@
+package upgrade
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckState(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, tt := range map[string]struct {
+		state      CheckState
+		channel    string
+		wantDue    bool
+		wantNotice string
+	}{
+		"never checked": {
+			channel: "stable",
+			wantDue: true,
+		},
+		"checked recently": {
+			state:   CheckState{CheckedAt: now.Add(-time.Hour), Latest: "v1.2.0", Channel: "stable"},
+			channel: "stable",
+		},
+		"checked a day ago": {
+			state:   CheckState{CheckedAt: now.Add(-25 * time.Hour), Latest: "v1.2.0", Channel: "stable"},
+			channel: "stable",
+			wantDue: true,
+		},
+		"channel changed": {
+			state:   CheckState{CheckedAt: now.Add(-time.Hour), Latest: "v1.2.0", Channel: "stable"},
+			channel: "prerelease",
+			wantDue: true,
+		},
+		"newer release": {
+			state:      CheckState{CheckedAt: now.Add(-time.Hour), Latest: "v1.3.0", Channel: "stable"},
+			channel:    "stable",
+			wantNotice: "v1.3.0",
+		},
+		"newer release, told recently": {
+			state:   CheckState{CheckedAt: now.Add(-time.Hour), Latest: "v1.3.0", Channel: "stable", NotifiedAt: now.Add(-2 * time.Hour)},
+			channel: "stable",
+		},
+		"newer release, told yesterday": {
+			state:      CheckState{CheckedAt: now.Add(-time.Hour), Latest: "v1.3.0", Channel: "stable", NotifiedAt: now.Add(-30 * time.Hour)},
+			channel:    "stable",
+			wantNotice: "v1.3.0",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.wantDue, tt.state.Due(tt.channel, now)); diff != "" {
+				t.Errorf("Due mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantNotice, tt.state.Notice("v1.2.0", now)); diff != "" {
+				t.Errorf("Notice mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadSaveCheck(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "state")
+	state, err := LoadCheck(dir)
+	if err != nil {
+		t.Fatalf("LoadCheck failed: %v", err)
+	}
+	if diff := cmp.Diff(&CheckState{}, state); diff != "" {
+		t.Errorf("state before saving mismatch (-want +got):\n%s", diff)
+	}
+
+	expected := &CheckState{CheckedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), Latest: "v1.3.0", Channel: "stable"}
+	if err := expected.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, err := LoadCheck(dir)
+	if err != nil {
+		t.Fatalf("LoadCheck failed: %v", err)
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("LoadCheck mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,167 @@
+package upgrade
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the release assets besides the archives.
+const (
+	ChecksumsAsset = "checksums.txt"
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// ArchiveName returns the name of the release archive of version for an
+// operating system and architecture, as GOOS and GOARCH name them, such as
+// giwo_1.2.0_linux_amd64.tar.gz. Windows archives are zip files.
+func ArchiveName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("giwo_%s_%s_%s.%s", strings.TrimPrefix(version, "v"), goos, goarch, ext)
+}
+
+// VerifyChecksum checks data, the content of the asset called name, against
+// the SHA-256 sums of the checksums file, in the format of sha256sum.
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	var expected string
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			expected = strings.ToLower(fields[0])
+			break
+		}
+	}
+	if expected == "" {
+		return fmt.Errorf("%s has no checksum for %s", ChecksumsAsset, name)
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, got)
+	}
+	return nil
+}
+
+// VerifySignature checks the Ed25519 signature of the checksums file against
+// publicKey. Both the signature and the key are base64-encoded.
+func VerifySignature(checksums, signature []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release signing key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid signature of %s: %w", ChecksumsAsset, err)
+	}
+	if !ed25519.Verify(key, checksums, sig) {
+		return fmt.Errorf("the signature of %s does not match the release signing key", ChecksumsAsset)
+	}
+	return nil
+}
+
+// ExtractBinary returns the file called binary, such as giwo or giwo.exe, at
+// any depth of the release archive called name, a tar.gz or zip file.
+func ExtractBinary(name string, archive []byte, binary string) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		return extractZip(archive, binary)
+	}
+	return extractTarGz(archive, binary)
+}
+
+func extractTarGz(archive []byte, binary string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binary {
+			return io.ReadAll(tr)
+		}
+	}
+	return nil, fmt.Errorf("the archive has no %s", binary)
+}
+
+func extractZip(archive []byte, binary string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || path.Base(f.Name) != binary {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("the archive has no %s", binary)
+}
+
+// Replace replaces the executable at exe with binary. The new binary is
+// written next to it and renamed over it, so that exe is never left half
+// written. The old binary is moved aside first, as Windows does not allow
+// replacing a running executable but does allow renaming it; it is removed
+// where possible and otherwise on the next upgrade.
+func Replace(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return fmt.Errorf("failed to find the running executable: %w", err)
+	}
+
+	dir := filepath.Dir(exe)
+	tmp, err := os.CreateTemp(dir, ".giwo-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("failed to make the new binary executable: %w", err)
+	}
+
+	old := exe + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("failed to move the running executable aside: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		// Put the old binary back rather than leaving no giwo at all
+		_ = os.Rename(old, exe)
+		return fmt.Errorf("failed to install the new binary: %w", err)
+	}
+	_ = os.Remove(old)
+	return nil
+}
@@ -0,0 +1,189 @@
+package upgrade
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestArchiveName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		version, goos, goarch string
+		expected              string
+	}{
+		"linux":   {version: "v1.2.0", goos: "linux", goarch: "amd64", expected: "giwo_1.2.0_linux_amd64.tar.gz"},
+		"darwin":  {version: "v1.3.0-rc.1", goos: "darwin", goarch: "arm64", expected: "giwo_1.3.0-rc.1_darwin_arm64.tar.gz"},
+		"windows": {version: "v1.2.0", goos: "windows", goarch: "amd64", expected: "giwo_1.2.0_windows_amd64.zip"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, ArchiveName(tt.version, tt.goos, tt.goarch)); diff != "" {
+				t.Errorf("ArchiveName mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("archive")
+	sum := sha256.Sum256(data)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  giwo_1.2.0_linux_amd64.tar.gz\n" +
+		hex.EncodeToString(make([]byte, 32)) + " *giwo_1.2.0_windows_amd64.zip\n")
+
+	for name, tt := range map[string]struct {
+		asset   string
+		data    []byte
+		wantErr bool
+	}{
+		"match":              {asset: "giwo_1.2.0_linux_amd64.tar.gz", data: data},
+		"mismatch":           {asset: "giwo_1.2.0_linux_amd64.tar.gz", data: []byte("tampered"), wantErr: true},
+		"binary mode marker": {asset: "giwo_1.2.0_windows_amd64.zip", data: data, wantErr: true},
+		"not listed":         {asset: "giwo_1.2.0_darwin_arm64.tar.gz", data: data, wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := VerifyChecksum(checksums, tt.asset, tt.data)
+			if diff := cmp.Diff(tt.wantErr, err != nil); diff != "" {
+				t.Errorf("VerifyChecksum error mismatch (-want +got):\n%s\nerror: %v", diff, err)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksums := []byte("abc  giwo_1.2.0_linux_amd64.tar.gz\n")
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, checksums)) + "\n")
+	key := base64.StdEncoding.EncodeToString(public)
+
+	if err := VerifySignature(checksums, signature, key); err != nil {
+		t.Errorf("VerifySignature failed: %v", err)
+	}
+	if err := VerifySignature([]byte("tampered"), signature, key); err == nil {
+		t.Error("VerifySignature accepted tampered checksums")
+	}
+	if err := VerifySignature(checksums, signature, base64.StdEncoding.EncodeToString(otherPublic)); err == nil {
+		t.Error("VerifySignature accepted another key")
+	}
+	if err := VerifySignature(checksums, signature, "not a key"); err == nil {
+		t.Error("VerifySignature accepted an invalid key")
+	}
+}
+
+func TestExtractBinary(t *testing.T) {
+	binary := []byte("#!/bin/giwo\n")
+
+	var tgz bytes.Buffer
+	gz := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"giwo_1.2.0_linux_amd64/README.md", []byte("readme")}, {"giwo_1.2.0_linux_amd64/giwo", binary}} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o755, Size: int64(len(f.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("giwo.exe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tt := range map[string]struct {
+		asset   string
+		archive []byte
+		binary  string
+		wantErr bool
+	}{
+		"tar.gz in a directory": {asset: "giwo_1.2.0_linux_amd64.tar.gz", archive: tgz.Bytes(), binary: "giwo"},
+		"zip":                   {asset: "giwo_1.2.0_windows_amd64.zip", archive: zipped.Bytes(), binary: "giwo.exe"},
+		"missing binary":        {asset: "giwo_1.2.0_linux_amd64.tar.gz", archive: tgz.Bytes(), binary: "giwo.exe", wantErr: true},
+		"not an archive":        {asset: "giwo_1.2.0_linux_amd64.tar.gz", archive: []byte("html"), binary: "giwo", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ExtractBinary(tt.asset, tt.archive, tt.binary)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ExtractBinary succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractBinary failed: %v", err)
+			}
+			if diff := cmp.Diff(binary, got); diff != "" {
+				t.Errorf("ExtractBinary mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "giwo")
+	if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Replace(exe, []byte("new")); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("new", string(data)); diff != "" {
+		t.Errorf("binary mismatch (-want +got):\n%s", diff)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(1, len(entries)); diff != "" {
+		t.Errorf("files left behind mismatch (-want +got):\n%s", diff)
+	}
+}
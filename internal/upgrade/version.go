@@ -0,0 +1,112 @@
+// Package upgrade replaces the giwo binary with a newer release and keeps
+// track of when giwo last looked for one.
+package upgrade
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare compares the versions a and b, such as v1.2.0 or 1.3.0-rc.1, as
+// semantic versions: it returns -1 if a is older than b, 1 if it is newer and
+// 0 if they are the same. A prerelease is older than its release. Build
+// metadata after + is ignored.
+func Compare(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	for i := range max(len(aCore), len(bCore)) {
+		x, y := part(aCore, i), part(bCore, i)
+		if x != y {
+			return sign(x - y)
+		}
+	}
+
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(strings.Split(aPre, "."), strings.Split(bPre, "."))
+}
+
+// IsRelease reports whether version is that of a release, such as v1.2.0,
+// rather than of a development build.
+func IsRelease(version string) bool {
+	core, _ := splitVersion(version)
+	if len(core) == 0 {
+		return false
+	}
+	for _, n := range core {
+		if n < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// splitVersion returns the numbers of the version and its prerelease part.
+// Parts that are not numbers are -1.
+func splitVersion(version string) ([]int, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	core, pre, _ := strings.Cut(version, "-")
+	if core == "" {
+		return nil, pre
+	}
+
+	var numbers []int
+	for _, field := range strings.Split(core, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			n = -1
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, pre
+}
+
+// comparePrerelease compares prerelease identifiers: numbers numerically and
+// below words, words alphabetically, and a shorter list lower if they agree.
+func comparePrerelease(a, b []string) int {
+	for i := range min(len(a), len(b)) {
+		x, xErr := strconv.Atoi(a[i])
+		y, yErr := strconv.Atoi(b[i])
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				return sign(x - y)
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(a) - len(b))
+}
+
+// part returns the ith number of a version, 0 for missing ones.
+func part(numbers []int, i int) int {
+	if i < len(numbers) {
+		return numbers[i]
+	}
+	return 0
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
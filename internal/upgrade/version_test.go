@@ -0,0 +1,58 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompare(t *testing.T) {
+	for name, tt := range map[string]struct {
+		a, b     string
+		expected int
+	}{
+		"equal":                        {a: "v1.2.0", b: "v1.2.0", expected: 0},
+		"without v":                    {a: "1.2.0", b: "v1.2.0", expected: 0},
+		"missing patch":                {a: "v1.2", b: "v1.2.0", expected: 0},
+		"patch":                        {a: "v1.2.1", b: "v1.2.0", expected: 1},
+		"minor over patch":             {a: "v1.2.9", b: "v1.10.0", expected: -1},
+		"major":                        {a: "v2.0.0", b: "v1.99.99", expected: 1},
+		"prerelease before release":    {a: "v1.3.0-rc.1", b: "v1.3.0", expected: -1},
+		"prerelease after older":       {a: "v1.3.0-rc.1", b: "v1.2.0", expected: 1},
+		"numeric prerelease parts":     {a: "v1.3.0-rc.10", b: "v1.3.0-rc.2", expected: 1},
+		"words after numbers":          {a: "v1.3.0-beta", b: "v1.3.0-1", expected: 1},
+		"words alphabetically":         {a: "v1.3.0-alpha", b: "v1.3.0-beta", expected: -1},
+		"longer prerelease is greater": {a: "v1.3.0-rc.1.1", b: "v1.3.0-rc.1", expected: 1},
+		"build metadata ignored":       {a: "v1.3.0+abc", b: "v1.3.0", expected: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, Compare(tt.a, tt.b)); diff != "" {
+				t.Errorf("Compare(%q, %q) mismatch (-want +got):\n%s", tt.a, tt.b, diff)
+			}
+			if diff := cmp.Diff(-tt.expected, Compare(tt.b, tt.a)); diff != "" {
+				t.Errorf("Compare(%q, %q) mismatch (-want +got):\n%s", tt.b, tt.a, diff)
+			}
+		})
+	}
+}
+
+func TestIsRelease(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"v1.2.0":      true,
+		"1.2.0":       true,
+		"v1.3.0-rc.1": true,
+		"dev":         false,
+		"(devel)":     false,
+		"":            false,
+	} {
+		t.Run(version, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(expected, IsRelease(version)); diff != "" {
+				t.Errorf("IsRelease mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
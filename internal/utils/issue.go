@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultIssueBranchTemplate names branches created from issues, e.g. "1234-fix-login-crash".
+const DefaultIssueBranchTemplate = "{{.ID}}-{{.Slug}}"
+
+// issueSlugLength caps the part of a branch name derived from an issue title.
+const issueSlugLength = 40
+
+// IssueBranchData is available to issue branch templates.
+type IssueBranchData struct {
+	// ID identifies the issue, e.g. "1234" or "PROJ-123".
+	ID string
+
+	Title string
+
+	// Slug is the title lowercased, with runs of other characters than ASCII
+	// letters and digits replaced by dashes, e.g. "fix-login-crash".
+	Slug string
+}
+
+// ParseIssueBranchTemplate parses a branch template over IssueBranchData.
+// An empty text selects DefaultIssueBranchTemplate.
+func ParseIssueBranchTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultIssueBranchTemplate
+	}
+	tmpl, err := template.New("branch").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// IssueBranchName renders the branch name of an issue with the template text.
+// Dashes and slashes left dangling by an empty slug are trimmed, and the result
+// must be a valid branch name.
+func IssueBranchName(text, id, title string) (string, error) {
+	tmpl, err := ParseIssueBranchTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	data := IssueBranchData{ID: id, Title: title, Slug: Slugify(title, issueSlugLength)}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render branch name: %w", err)
+	}
+
+	name := strings.Trim(b.String(), "-/")
+	if err := ValidateBranchName(name); err != nil {
+		return "", fmt.Errorf("branch name %q generated from issue %s is invalid: %w", name, id, err)
+	}
+	return name, nil
+}
+
+// Slugify turns s into lowercase words of letters and digits, in any script,
+// joined by dashes, cut at a word boundary to at most maxLen characters.
+// Apostrophes are dropped.
+func Slugify(s string, maxLen int) string {
+	// Apostrophes would otherwise split words like "don't"
+	s = strings.NewReplacer("'", "", "’", "").Replace(s)
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		// Marks belong to the letter before them, as in Devanagari
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r))
+	})
+
+	var slug string
+	for _, word := range words {
+		next := word
+		if slug != "" {
+			next = slug + "-" + word
+		}
+		if utf8.RuneCountInString(next) > maxLen {
+			if slug == "" {
+				// A single overlong word is cut rather than dropped
+				return string([]rune(word)[:maxLen])
+			}
+			break
+		}
+		slug = next
+	}
+	return slug
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSlugify(t *testing.T) {
+	for name, tt := range map[string]struct {
+		input    string
+		maxLen   int
+		expected string
+	}{
+		"title":            {"Fix login crash", 40, "fix-login-crash"},
+		"punctuation":      {"[API] Don't retry 5xx errors!", 40, "api-dont-retry-5xx-errors"},
+		"cut at word":      {"Fix login crash on startup", 16, "fix-login-crash"},
+		"long single word": {"Supercalifragilistic", 5, "super"},
+		"non-ascii kept":   {"Réparer la connexion ログイン", 40, "réparer-la-connexion-ログイン"},
+		"cut in runes":     {"ブックマーク追加", 6, "ブックマーク"},
+		"empty":            {"!!!", 40, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, Slugify(tt.input, tt.maxLen)); diff != "" {
+				t.Errorf("Slugify mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIssueBranchName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		template string
+		id       string
+		title    string
+		expected string
+		wantErr  bool
+	}{
+		"default template": {
+			id:       "1234",
+			title:    "Fix login crash",
+			expected: "1234-fix-login-crash",
+		},
+		"custom template": {
+			template: "feature/{{.ID}}/{{.Slug}}",
+			id:       "PROJ-7",
+			title:    "Add SSO",
+			expected: "feature/PROJ-7/add-sso",
+		},
+		"empty slug": {
+			id:       "42",
+			title:    "???",
+			expected: "42",
+		},
+		"unicode title": {
+			id:       "42",
+			title:    "ログイン画面",
+			expected: "42-ログイン画面",
+		},
+		"invalid template": {
+			template: "{{.ID",
+			id:       "1",
+			wantErr:  true,
+		},
+		"unknown field": {
+			template: "{{.Number}}",
+			id:       "1",
+			wantErr:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := IssueBranchName(tt.template, tt.id, tt.title)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IssueBranchName failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("IssueBranchName mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
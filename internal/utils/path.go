@@ -0,0 +1,19 @@
+package utils
+
+import "path/filepath"
+
+// ResolvePath returns path cleaned and with symlinks resolved, as git records
+// worktree paths. Only the part of it that exists is resolved, so that a
+// directory that is yet to be created, like a worktree directory before the
+// first worktree, resolves the same before and after.
+func ResolvePath(path string) string {
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(ResolvePath(parent), filepath.Base(path))
+}
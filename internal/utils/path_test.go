@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolvePath(t *testing.T) {
+	t.Parallel()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(dir, "real")
+	if err := os.Mkdir(real, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tt := range map[string]struct {
+		path string
+		want string
+	}{
+		"real":                  {path: real, want: real},
+		"symlink":               {path: filepath.Join(dir, "link"), want: real},
+		"missing below symlink": {path: filepath.Join(dir, "link", "wts", "feat"), want: filepath.Join(real, "wts", "feat")},
+		"unclean":               {path: filepath.Join(dir, "link") + "/./wts/../wts/", want: filepath.Join(real, "wts")},
+		"missing":               {path: filepath.Join(dir, "nothing", "here"), want: filepath.Join(dir, "nothing", "here")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, ResolvePath(tt.path)); diff != "" {
+				t.Errorf("ResolvePath(%q) mismatch (-want +got):\n%s", tt.path, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// ShellKind tells how a shell takes a command line.
+type ShellKind string
+
+// Shell kinds.
+const (
+	ShellPOSIX      ShellKind = "posix"
+	ShellPowerShell ShellKind = "powershell"
+	ShellCmd        ShellKind = "cmd"
+)
+
+// Shell is a command interpreter found on the system.
+type Shell struct {
+	// Path is the executable, e.g. /bin/zsh or pwsh.exe.
+	Path string
+	Kind ShellKind
+}
+
+// ScriptArgs returns the arguments that make the shell run script.
+func (s Shell) ScriptArgs(script string) []string {
+	switch s.Kind {
+	case ShellPowerShell:
+		return []string{"-NoProfile", "-Command", script}
+	case ShellCmd:
+		return []string{"/C", script}
+	}
+	return []string{"-c", script}
+}
+
+// UserShell returns the shell the user works in: $SHELL where it is set, which
+// includes Git Bash and MSYS2 on Windows, and otherwise /bin/sh, or on Windows
+// PowerShell when running inside it and cmd.exe ($ComSpec) if not.
+func UserShell() Shell {
+	return userShell(runtime.GOOS, os.Getenv, exec.LookPath)
+}
+
+// ScriptShell returns the shell that runs configured commands such as setup
+// steps, which are usually written for sh: sh wherever it can be found, which
+// includes Git for Windows installs that put it on the PATH, and the user's
+// shell otherwise.
+func ScriptShell() Shell {
+	return scriptShell(runtime.GOOS, os.Getenv, exec.LookPath)
+}
+
+func userShell(goos string, getenv func(string) string, lookPath func(string) (string, error)) Shell {
+	shell := getenv("SHELL")
+	if goos != "windows" {
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		return Shell{Path: shell, Kind: ShellPOSIX}
+	}
+
+	// Git Bash and MSYS2 set $SHELL to a path like /usr/bin/bash, which only
+	// they understand; the executable is found on the PATH instead
+	if shell != "" {
+		if found, err := lookPath(path.Base(strings.ReplaceAll(shell, `\`, "/"))); err == nil {
+			return Shell{Path: found, Kind: ShellPOSIX}
+		}
+	}
+
+	// PowerShell adds the user's module directories to PSModulePath, which has
+	// fewer entries in processes started from cmd.exe or Explorer
+	if len(strings.Split(getenv("PSModulePath"), ";")) >= 3 {
+		for _, name := range []string{"pwsh.exe", "powershell.exe"} {
+			if path, err := lookPath(name); err == nil {
+				return Shell{Path: path, Kind: ShellPowerShell}
+			}
+		}
+	}
+	if comspec := getenv("ComSpec"); comspec != "" {
+		return Shell{Path: comspec, Kind: ShellCmd}
+	}
+	return Shell{Path: "cmd.exe", Kind: ShellCmd}
+}
+
+func scriptShell(goos string, getenv func(string) string, lookPath func(string) (string, error)) Shell {
+	if goos != "windows" {
+		return Shell{Path: "sh", Kind: ShellPOSIX}
+	}
+	if path, err := lookPath("sh.exe"); err == nil {
+		return Shell{Path: path, Kind: ShellPOSIX}
+	}
+	return userShell(goos, getenv, lookPath)
+}
+
+// ShellQuote quotes s for safe use as a single word in a POSIX shell command line.
+// Words consisting only of safe characters are returned unchanged for readability.
+func ShellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+
+	safe := true
+	for _, r := range s {
+		if !isShellSafe(r) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ShellJoin quotes each word and joins them into a POSIX shell command line.
+func ShellJoin(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = ShellQuote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// isShellSafe reports whether r never needs quoting in a POSIX shell word.
+func isShellSafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("-_./:@%+=,", r)
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShellQuote(t *testing.T) {
+	for name, tt := range map[string]struct {
+		input    string
+		expected string
+	}{
+		"plain word":       {"make", "make"},
+		"path":             {"/home/me/src/repo", "/home/me/src/repo"},
+		"empty":            {"", "''"},
+		"spaces":           {"feature auth", "'feature auth'"},
+		"single quote":     {"it's", `'it'\''s'`},
+		"shell metachars":  {"a;rm -rf $HOME", "'a;rm -rf $HOME'"},
+		"unicode":          {"ブックマーク", "'ブックマーク'"},
+		"flag with equals": {"-j=8", "-j=8"},
+		"glob":             {"*.go", "'*.go'"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.expected, ShellQuote(tt.input)); diff != "" {
+				t.Errorf("ShellQuote(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+
+	// The shell must see the quoted word as exactly the original string
+	roundTrips := func(name trickyName) bool {
+		if strings.ContainsRune(string(name), 0) {
+			return true
+		}
+		out, err := exec.Command(sh, "-c", "printf '%s' "+ShellQuote(string(name))).Output()
+		return err == nil && string(out) == string(name)
+	}
+	if err := quick.Check(roundTrips, &quick.Config{MaxCount: 300}); err != nil {
+		t.Errorf("ShellQuote() did not survive the shell: %v", err)
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	t.Parallel()
+
+	result := ShellJoin([]string{"cd", "/tmp/my dir", "&&", "make", "-j"})
+	if diff := cmp.Diff("cd '/tmp/my dir' '&&' make -j", result); diff != "" {
+		t.Errorf("ShellJoin mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUserShell(t *testing.T) {
+	for name, tt := range map[string]struct {
+		goos     string
+		env      map[string]string
+		path     []string
+		expected Shell
+	}{
+		"unix shell": {
+			goos:     "linux",
+			env:      map[string]string{"SHELL": "/bin/zsh"},
+			expected: Shell{Path: "/bin/zsh", Kind: ShellPOSIX},
+		},
+		"unix without shell": {
+			goos:     "darwin",
+			expected: Shell{Path: "/bin/sh", Kind: ShellPOSIX},
+		},
+		"git bash": {
+			goos:     "windows",
+			env:      map[string]string{"SHELL": "/usr/bin/bash"},
+			path:     []string{"bash"},
+			expected: Shell{Path: `C:\bin\bash`, Kind: ShellPOSIX},
+		},
+		"powershell 7": {
+			goos:     "windows",
+			env:      map[string]string{"PSModulePath": `C:\Users\me\Documents\PowerShell\Modules;C:\Program Files\PowerShell\Modules;C:\WINDOWS\system32\WindowsPowerShell\v1.0\Modules`},
+			path:     []string{"pwsh.exe", "powershell.exe"},
+			expected: Shell{Path: `C:\bin\pwsh.exe`, Kind: ShellPowerShell},
+		},
+		"windows powershell": {
+			goos:     "windows",
+			env:      map[string]string{"PSModulePath": `C:\Users\me\Documents\WindowsPowerShell\Modules;C:\Program Files\WindowsPowerShell\Modules;C:\WINDOWS\system32\WindowsPowerShell\v1.0\Modules`},
+			path:     []string{"powershell.exe"},
+			expected: Shell{Path: `C:\bin\powershell.exe`, Kind: ShellPowerShell},
+		},
+		"cmd": {
+			goos:     "windows",
+			env:      map[string]string{"PSModulePath": `C:\Program Files\WindowsPowerShell\Modules;C:\WINDOWS\system32\WindowsPowerShell\v1.0\Modules`, "ComSpec": `C:\WINDOWS\system32\cmd.exe`},
+			path:     []string{"powershell.exe"},
+			expected: Shell{Path: `C:\WINDOWS\system32\cmd.exe`, Kind: ShellCmd},
+		},
+		"msys shell not on path": {
+			goos:     "windows",
+			env:      map[string]string{"SHELL": "/usr/bin/bash"},
+			expected: Shell{Path: "cmd.exe", Kind: ShellCmd},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := userShell(tt.goos, fakeGetenv(tt.env), fakeLookPath(tt.path))
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("userShell() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScriptShell(t *testing.T) {
+	for name, tt := range map[string]struct {
+		goos     string
+		env      map[string]string
+		path     []string
+		expected Shell
+	}{
+		"unix":               {goos: "linux", env: map[string]string{"SHELL": "/bin/zsh"}, expected: Shell{Path: "sh", Kind: ShellPOSIX}},
+		"git for windows":    {goos: "windows", path: []string{"sh.exe"}, expected: Shell{Path: `C:\bin\sh.exe`, Kind: ShellPOSIX}},
+		"windows without sh": {goos: "windows", env: map[string]string{"ComSpec": `C:\WINDOWS\system32\cmd.exe`}, expected: Shell{Path: `C:\WINDOWS\system32\cmd.exe`, Kind: ShellCmd}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := scriptShell(tt.goos, fakeGetenv(tt.env), fakeLookPath(tt.path))
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("scriptShell() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShellScriptArgs(t *testing.T) {
+	for name, tt := range map[string]struct {
+		kind     ShellKind
+		expected []string
+	}{
+		"posix":      {kind: ShellPOSIX, expected: []string{"-c", "make setup"}},
+		"powershell": {kind: ShellPowerShell, expected: []string{"-NoProfile", "-Command", "make setup"}},
+		"cmd":        {kind: ShellCmd, expected: []string{"/C", "make setup"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Shell{Kind: tt.kind}.ScriptArgs("make setup")
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("ScriptArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeGetenv looks variables up in env.
+func fakeGetenv(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+// fakeLookPath finds the given executables in C:\bin.
+func fakeLookPath(found []string) func(string) (string, error) {
+	return func(file string) (string, error) {
+		if slices.Contains(found, file) {
+			return `C:\bin\` + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+}
@@ -4,6 +4,7 @@ package utils
 import (
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/knwoop/giwo/internal/errors"
 )
@@ -11,24 +12,26 @@ import (
 // Git branch name restrictions based on git-check-ref-format.
 var (
 	// invalidBranchChars contains characters that are not allowed in branch names.
-	invalidBranchChars = []string{"..", "~", "^", ":", "?", "*", "[", "\\"}
+	invalidBranchChars = []string{"..", "~", "^", ":", "?", "*", "[", "\\", "@{"}
 
 	// reservedNames contains branch names that are reserved and cannot be used.
-	reservedNames = []string{"HEAD", "head"}
+	reservedNames = []string{"HEAD", "head", "@"}
 
 	// refsPattern matches branch names starting with "refs/".
 	refsPattern = regexp.MustCompile(`^refs/`)
 )
 
 // ValidateBranchName validates a Git branch name according to Git naming rules.
+// Any other character is allowed, so names such as "feature/ブックマーク追加" are
+// valid; slashes separate components, each of which git stores as a directory.
 // It returns a ValidationError if the name is invalid.
 func ValidateBranchName(name string) error {
 	if name == "" {
 		return errors.NewValidationError("branch_name", name, errors.ErrInvalidBranchName)
 	}
 
-	// Check for spaces
-	if strings.Contains(name, " ") {
+	// Check for spaces and control characters such as tabs or newlines
+	if strings.ContainsFunc(name, func(r rune) bool { return r == ' ' || isControl(r) }) {
 		return errors.NewValidationError("branch_name", name,
 			errors.ErrInvalidBranchName)
 	}
@@ -48,6 +51,15 @@ func ValidateBranchName(name string) error {
 			errors.ErrInvalidBranchName)
 	}
 
+	// Check for empty components, components starting with a period, such as
+	// "feature/.x", and components ending with ".lock", which git uses for locks
+	for _, component := range strings.Split(name, "/") {
+		if component == "" || strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return errors.NewValidationError("branch_name", name,
+				errors.ErrInvalidBranchName)
+		}
+	}
+
 	// Check for reserved names
 	for _, reserved := range reservedNames {
 		if strings.EqualFold(name, reserved) {
@@ -67,26 +79,69 @@ func ValidateBranchName(name string) error {
 
 // SanitizeBranchName converts an arbitrary string into a valid Git branch name.
 // It replaces invalid characters and formats according to Git naming conventions.
+// Characters git accepts, such as non-ASCII letters, are kept.
 func SanitizeBranchName(name string) string {
 	// Trim whitespace
 	name = strings.TrimSpace(name)
 
-	// Replace spaces and underscores with dashes
-	name = strings.ReplaceAll(name, " ", "-")
-	name = strings.ReplaceAll(name, "_", "-")
+	// Replace whitespace, control characters and underscores with dashes
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || isControl(r) || r == '_' {
+			return '-'
+		}
+		return r
+	}, name)
 
 	// Replace invalid characters with dashes
 	for _, char := range invalidBranchChars {
 		name = strings.ReplaceAll(name, char, "-")
 	}
 
-	// Remove leading/trailing dashes and periods
-	name = strings.Trim(name, "-.")
+	// Trimming the ends can expose another invalid end, as in "-/x", so tidy
+	// up until nothing changes
+	for {
+		tidied := tidyBranchName(name)
+		if tidied == name {
+			break
+		}
+		name = tidied
+	}
 
 	// Provide default name if result is empty
 	if name == "" {
 		return "unnamed-branch"
 	}
 
+	for _, reserved := range reservedNames {
+		if strings.EqualFold(name, reserved) {
+			return name + "-branch"
+		}
+	}
+
 	return name
 }
+
+// tidyBranchName drops empty components and leading periods of components,
+// renames ".lock" suffixes of components, removes a "refs/" prefix and removes
+// leading and trailing dashes and periods.
+func tidyBranchName(name string) string {
+	var components []string
+	for _, component := range strings.Split(name, "/") {
+		component = strings.TrimLeft(component, ".")
+		if strings.HasSuffix(component, ".lock") {
+			component = strings.TrimSuffix(component, ".lock") + "-lock"
+		}
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+	name = strings.Join(components, "/")
+	name = strings.TrimPrefix(name, "refs/")
+	return strings.Trim(name, "-.")
+}
+
+// isControl reports whether r is an ASCII control character, which git does
+// not allow in ref names.
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
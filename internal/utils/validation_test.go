@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"math/rand"
+	"os/exec"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -31,6 +36,21 @@ func TestValidateBranchName(t *testing.T) {
 		"name with asterisk":      {"feature*", true},
 		"name with bracket":       {"feature[1]", true},
 		"name with backslash":     {"feature\\auth", true},
+		"valid with slashes":      {"feature/auth/login", false},
+		"valid with unicode":      {"feature/ブックマーク追加", false},
+		"valid with emoji":        {"fix-🚀", false},
+		"valid with at sign":      {"feature@2", false},
+		"name with tab":           {"feature\tauth", true},
+		"name with newline":       {"feature\nauth", true},
+		"name with delete":        {"feature\x7fauth", true},
+		"name with at brace":      {"feature@{1}", true},
+		"lone at sign":            {"@", true},
+		"double slash":            {"feature//auth", true},
+		"leading slash":           {"/feature", true},
+		"trailing slash":          {"feature/", true},
+		"component with dot":      {"feature/.auth", true},
+		"ending with lock":        {"feature.lock", true},
+		"component with lock":     {"feature.lock/auth", true},
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
@@ -59,6 +79,15 @@ func TestSanitizeBranchName(t *testing.T) {
 		"empty string":                      {"", "unnamed-branch"},
 		"only invalid chars":                {"..~~", "unnamed-branch"},
 		"mixed invalid chars":               {"feature~auth:test", "feature-auth-test"},
+		"unicode kept":                      {"feature/ブックマーク 追加", "feature/ブックマーク-追加"},
+		"tabs and newlines":                 {"feature\tauth\nx", "feature-auth-x"},
+		"at brace":                          {"feature@{1}", "feature-1}"},
+		"empty components":                  {"/feature//auth/", "feature/auth"},
+		"component with dot":                {"feature/.auth", "feature/auth"},
+		"lock suffix":                       {"feature.lock", "feature-lock"},
+		"refs prefix":                       {"refs/heads/feature", "heads/feature"},
+		"reserved name":                     {"HEAD", "HEAD-branch"},
+		"lone at sign":                      {"@", "@-branch"},
 	} {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
@@ -69,3 +98,72 @@ func TestSanitizeBranchName(t *testing.T) {
 		})
 	}
 }
+
+// trickyName is a branch name candidate for property tests, assembled from
+// pieces that are easy to get wrong: slashes, dots, unicode, spaces, control
+// characters and everything git reserves.
+type trickyName string
+
+var trickyPieces = []string{
+	"feature", "fix", "a", "1", "ブックマーク", "追加", "é", "🚀", "Ärger",
+	"/", "//", ".", "..", "-", "_", " ", "\t", "\n", "\x7f", "\x01",
+	"@", "{", "}", "@{", ".lock", "~", "^", ":", "?", "*", "[", "\\",
+	"'", "\"", "$", "`", ";", "&", "!", "(", "HEAD", "refs/",
+}
+
+// Generate implements quick.Generator.
+func (trickyName) Generate(rand *rand.Rand, _ int) reflect.Value {
+	var b strings.Builder
+	for range rand.Intn(8) + 1 {
+		b.WriteString(trickyPieces[rand.Intn(len(trickyPieces))])
+	}
+	return reflect.ValueOf(trickyName(b.String()))
+}
+
+func TestSanitizeBranchNameProperties(t *testing.T) {
+	t.Parallel()
+
+	valid := func(name trickyName) bool {
+		return ValidateBranchName(SanitizeBranchName(string(name))) == nil
+	}
+	if err := quick.Check(valid, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Errorf("SanitizeBranchName() returned an invalid name: %v", err)
+	}
+
+	idempotent := func(name trickyName) bool {
+		once := SanitizeBranchName(string(name))
+		return SanitizeBranchName(once) == once
+	}
+	if err := quick.Check(idempotent, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Errorf("SanitizeBranchName() is not idempotent: %v", err)
+	}
+
+	// Valid names are kept as they are, apart from underscores
+	keepsValid := func(name trickyName) bool {
+		s := strings.ReplaceAll(string(name), "_", "-")
+		return ValidateBranchName(s) != nil || SanitizeBranchName(s) == s
+	}
+	if err := quick.Check(keepsValid, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Errorf("SanitizeBranchName() changed a valid name: %v", err)
+	}
+}
+
+func TestValidateBranchNameAgreesWithGit(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	// Every name accepted must be accepted by git too; giwo is stricter
+	// about leading and trailing dashes, and reserves HEAD in any case
+	accepted := func(name trickyName) bool {
+		if ValidateBranchName(string(name)) != nil {
+			return true
+		}
+		return exec.Command("git", "check-ref-format", "--branch", string(name)).Run() == nil
+	}
+	if err := quick.Check(accepted, &quick.Config{MaxCount: 300}); err != nil {
+		t.Errorf("ValidateBranchName() accepted a name git rejects: %v", err)
+	}
+}
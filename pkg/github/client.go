@@ -29,6 +29,7 @@ type Repository struct {
 // Client handles GitHub API interactions.
 type Client struct {
 	token      string
+	baseURL    string
 	httpClient *http.Client
 }
 
@@ -36,13 +37,24 @@ type Client struct {
 // It uses the GITHUB_TOKEN environment variable for authentication.
 func New() *Client {
 	return &Client{
-		token: os.Getenv("GITHUB_TOKEN"),
+		token:   os.Getenv("GITHUB_TOKEN"),
+		baseURL: GitHubAPIBaseURL,
 		httpClient: &http.Client{
 			Timeout: DefaultRequestTimeout,
 		},
 	}
 }
 
+// NewWithToken creates a new GitHub client authenticated with token.
+// An empty token falls back to the GITHUB_TOKEN environment variable.
+func NewWithToken(token string) *Client {
+	c := New()
+	if token != "" {
+		c.token = token
+	}
+	return c
+}
+
 // GetDefaultBranch returns the default branch for a GitHub repository.
 // It falls back to local Git inspection if the API is unavailable.
 func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (string, error) {
@@ -50,7 +62,7 @@ func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (stri
 		return c.fallbackDefaultBranch(ctx)
 	}
 
-	url := fmt.Sprintf("%s/repos/%s/%s", GitHubAPIBaseURL, owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -0,0 +1,18 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+// IssueTitle returns the title of an issue. It uses the API when a token is
+// configured and the gh CLI otherwise.
+func (c *Client) IssueTitle(ctx context.Context, owner, repo string, number int) (string, error) {
+	var issue struct {
+		Title string `json:"title"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/issues/%d", owner, repo, number), &issue); err != nil {
+		return "", fmt.Errorf("failed to look up issue #%d: %w", number, err)
+	}
+	return issue.Title, nil
+}
@@ -0,0 +1,36 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIssueTitle(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/knwoop/giwo/issues/1234", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number":1234,"title":"Fix login crash"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewWithToken("secret")
+	client.baseURL = server.URL
+
+	got, err := client.IssueTitle(context.Background(), "knwoop", "giwo", 1234)
+	if err != nil {
+		t.Fatalf("IssueTitle failed: %v", err)
+	}
+	if diff := cmp.Diff("Fix login crash", got); diff != "" {
+		t.Errorf("IssueTitle mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.IssueTitle(context.Background(), "knwoop", "giwo", 1); err == nil {
+		t.Error("expected an error for a missing issue")
+	}
+}
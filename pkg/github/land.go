@@ -0,0 +1,76 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Merge methods of MergePullRequest.
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// CreatePullRequest opens a pull request from branch into base, the default
+// branch if empty, with the gh CLI, titled and described after its commits.
+// It returns the URL of the pull request.
+func CreatePullRequest(ctx context.Context, owner, repo, branch, base string) (string, error) {
+	output, err := gh(ctx, createArgs(owner, repo, branch, base)...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// MergePullRequest asks GitHub, with the gh CLI, to merge the pull request of
+// branch with method once its requirements, such as checks and reviews, are
+// met, or right away if they are.
+func MergePullRequest(ctx context.Context, owner, repo, branch, method string) error {
+	args, err := mergeArgs(owner, repo, branch, method)
+	if err != nil {
+		return err
+	}
+	_, err = gh(ctx, args...)
+	return err
+}
+
+// createArgs returns the arguments of 'gh pr create' for CreatePullRequest.
+func createArgs(owner, repo, branch, base string) []string {
+	args := []string{"pr", "create", "--repo", owner + "/" + repo, "--head", branch, "--fill"}
+	if base != "" {
+		args = append(args, "--base", base)
+	}
+	return args
+}
+
+// mergeArgs returns the arguments of 'gh pr merge' for MergePullRequest.
+func mergeArgs(owner, repo, branch, method string) ([]string, error) {
+	switch method {
+	case MergeMethodMerge, MergeMethodSquash, MergeMethodRebase:
+	default:
+		return nil, fmt.Errorf("invalid merge method %q: must be merge, squash or rebase", method)
+	}
+	return []string{"pr", "merge", branch, "--repo", owner + "/" + repo, "--auto", "--" + method}, nil
+}
+
+// gh runs the gh CLI with args and returns its output.
+func gh(ctx context.Context, args ...string) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", fmt.Errorf("%w: install the gh CLI", errors.ErrGitHubAPIUnavailable)
+	}
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gh %s failed: %w: %s", strings.Join(args[:2], " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
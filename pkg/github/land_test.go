@@ -0,0 +1,47 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateArgs(t *testing.T) {
+	for name, tt := range map[string]struct {
+		base     string
+		expected []string
+	}{
+		"default branch": {"", []string{"pr", "create", "--repo", "knwoop/giwo", "--head", "feature-x", "--fill"}},
+		"base":           {"release-1.2", []string{"pr", "create", "--repo", "knwoop/giwo", "--head", "feature-x", "--fill", "--base", "release-1.2"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.expected, createArgs("knwoop", "giwo", "feature-x", tt.base)); diff != "" {
+				t.Errorf("createArgs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeArgs(t *testing.T) {
+	for name, tt := range map[string]struct {
+		method   string
+		expected []string
+		wantErr  bool
+	}{
+		"squash":  {method: MergeMethodSquash, expected: []string{"pr", "merge", "feature-x", "--repo", "knwoop/giwo", "--auto", "--squash"}},
+		"rebase":  {method: MergeMethodRebase, expected: []string{"pr", "merge", "feature-x", "--repo", "knwoop/giwo", "--auto", "--rebase"}},
+		"unknown": {method: "fast-forward", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := mergeArgs("knwoop", "giwo", "feature-x", tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeArgs error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("mergeArgs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
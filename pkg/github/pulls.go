@@ -0,0 +1,227 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Pull request states reported by PullRequestStatus.
+const (
+	PRStateOpen   = "open"
+	PRStateDraft  = "draft"
+	PRStateMerged = "merged"
+	PRStateClosed = "closed"
+)
+
+// Check states summarizing the CI checks of a pull request's head commit.
+const (
+	ChecksSuccess = "success"
+	ChecksFailure = "failure"
+	ChecksPending = "pending"
+)
+
+// PullRequestStatus describes the pull request of a branch and the state of its CI checks.
+type PullRequestStatus struct {
+	Number int    `json:"number"`
+	State  string `json:"state"`
+	URL    string `json:"url"`
+
+	// Checks summarizes the CI checks; it is empty if the head commit has none.
+	Checks string `json:"checks,omitempty"`
+}
+
+// check is a single CI check run or commit status, as reported by either the API or gh.
+type check struct {
+	// Status and Conclusion are set for check runs.
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+
+	// State is set for commit statuses.
+	State string `json:"state"`
+}
+
+// PullRequestStatus returns the most recent pull request for branch, or nil if there is none.
+// It uses the API when a token is configured and the gh CLI otherwise.
+func (c *Client) PullRequestStatus(ctx context.Context, owner, repo, branch string) (*PullRequestStatus, error) {
+	if c.token != "" {
+		return c.apiPullRequestStatus(ctx, owner, repo, branch)
+	}
+	if _, err := exec.LookPath("gh"); err == nil {
+		return ghPullRequestStatus(ctx, owner, repo, branch)
+	}
+	return nil, fmt.Errorf("%w: set GITHUB_TOKEN or install the gh CLI", errors.ErrGitHubAPIUnavailable)
+}
+
+// apiPullRequestStatus looks up the pull request and its checks through the REST API.
+func (c *Client) apiPullRequestStatus(ctx context.Context, owner, repo, branch string) (*PullRequestStatus, error) {
+	query := url.Values{
+		"head":     {owner + ":" + branch},
+		"state":    {"all"},
+		"per_page": {"1"},
+	}
+
+	var pulls []struct {
+		Number   int     `json:"number"`
+		State    string  `json:"state"`
+		HTMLURL  string  `json:"html_url"`
+		Draft    bool    `json:"draft"`
+		MergedAt *string `json:"merged_at"`
+		Head     struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/pulls?%s", owner, repo, query.Encode()), &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+
+	pr := pulls[0]
+	status := &PullRequestStatus{Number: pr.Number, URL: pr.HTMLURL, State: pr.State}
+	switch {
+	case pr.MergedAt != nil:
+		status.State = PRStateMerged
+	case pr.State == PRStateOpen && pr.Draft:
+		status.State = PRStateDraft
+	}
+
+	var runs struct {
+		CheckRuns []check `json:"check_runs"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/commits/%s/check-runs", owner, repo, pr.Head.SHA), &runs); err != nil {
+		return nil, err
+	}
+	status.Checks = summarizeChecks(runs.CheckRuns)
+
+	return status, nil
+}
+
+// getJSON performs an authenticated GET request against the API and decodes the response.
+// Without a token, the request is made through 'gh api' when the gh CLI is installed.
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	if c.token == "" {
+		if _, err := exec.LookPath("gh"); err == nil {
+			return ghAPI(ctx, path, v)
+		}
+		return fmt.Errorf("%w: set GITHUB_TOKEN or install the gh CLI", errors.ErrGitHubAPIUnavailable)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "gwt-cli")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrGitHubAPIUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %s", errors.ErrGitHubAPIUnavailable, path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// ghAPI performs a GET request through the gh CLI and decodes the response.
+func ghAPI(ctx context.Context, path string, v any) error {
+	cmd := exec.CommandContext(ctx, "gh", "api", strings.TrimPrefix(path, "/"))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("gh api failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err := json.Unmarshal(output, v); err != nil {
+		return fmt.Errorf("failed to decode gh output: %w", err)
+	}
+	return nil
+}
+
+// ghPullRequestStatus looks up the pull request and its checks with the gh CLI.
+func ghPullRequestStatus(ctx context.Context, owner, repo, branch string) (*PullRequestStatus, error) {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", branch,
+		"--repo", owner+"/"+repo,
+		"--json", "number,state,url,isDraft,statusCheckRollup")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "no pull requests found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gh pr view failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var pr struct {
+		Number            int     `json:"number"`
+		State             string  `json:"state"`
+		URL               string  `json:"url"`
+		IsDraft           bool    `json:"isDraft"`
+		StatusCheckRollup []check `json:"statusCheckRollup"`
+	}
+	if err := json.Unmarshal(output, &pr); err != nil {
+		return nil, fmt.Errorf("failed to decode gh output: %w", err)
+	}
+
+	status := &PullRequestStatus{
+		Number: pr.Number,
+		State:  strings.ToLower(pr.State),
+		URL:    pr.URL,
+		Checks: summarizeChecks(pr.StatusCheckRollup),
+	}
+	if status.State == PRStateOpen && pr.IsDraft {
+		status.State = PRStateDraft
+	}
+	return status, nil
+}
+
+// summarizeChecks reduces checks to a single state: any failure fails,
+// otherwise anything unfinished is pending, otherwise all succeeded.
+func summarizeChecks(checks []check) string {
+	if len(checks) == 0 {
+		return ""
+	}
+
+	pending := false
+	for _, c := range checks {
+		state := strings.ToLower(c.State)
+		conclusion := strings.ToLower(c.Conclusion)
+		status := strings.ToLower(c.Status)
+
+		switch {
+		case state == "failure" || state == "error":
+			return ChecksFailure
+		case conclusion == "failure" || conclusion == "timed_out" || conclusion == "cancelled" || conclusion == "action_required":
+			return ChecksFailure
+		case state == "pending" || state == "expected":
+			pending = true
+		case state == "" && status != "completed":
+			pending = true
+		}
+	}
+
+	if pending {
+		return ChecksPending
+	}
+	return ChecksSuccess
+}
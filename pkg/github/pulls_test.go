@@ -0,0 +1,112 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSummarizeChecks(t *testing.T) {
+	for name, tt := range map[string]struct {
+		checks   []check
+		expected string
+	}{
+		"no checks":      {nil, ""},
+		"all succeeded":  {[]check{{Status: "completed", Conclusion: "success"}, {State: "SUCCESS"}}, ChecksSuccess},
+		"skipped":        {[]check{{Status: "completed", Conclusion: "skipped"}}, ChecksSuccess},
+		"running":        {[]check{{Status: "completed", Conclusion: "success"}, {Status: "in_progress"}}, ChecksPending},
+		"status pending": {[]check{{State: "PENDING"}}, ChecksPending},
+		"failure wins":   {[]check{{Status: "queued"}, {Status: "completed", Conclusion: "failure"}}, ChecksFailure},
+		"status error":   {[]check{{State: "ERROR"}}, ChecksFailure},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.expected, summarizeChecks(tt.checks)); diff != "" {
+				t.Errorf("summarizeChecks mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAPIPullRequestStatus(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/knwoop/giwo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("head") {
+		case "knwoop:feature":
+			w.Write([]byte(`[{"number":42,"state":"open","draft":true,"html_url":"https://github.com/knwoop/giwo/pull/42","head":{"sha":"abc"}}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+	mux.HandleFunc("/repos/knwoop/giwo/commits/abc/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"check_runs":[{"status":"completed","conclusion":"failure"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewWithToken("secret")
+	client.baseURL = server.URL
+
+	ctx := context.Background()
+	got, err := client.PullRequestStatus(ctx, "knwoop", "giwo", "feature")
+	if err != nil {
+		t.Fatalf("PullRequestStatus failed: %v", err)
+	}
+	expected := &PullRequestStatus{
+		Number: 42,
+		State:  PRStateDraft,
+		URL:    "https://github.com/knwoop/giwo/pull/42",
+		Checks: ChecksFailure,
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("PullRequestStatus mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = client.PullRequestStatus(ctx, "knwoop", "giwo", "no-pr")
+	if err != nil {
+		t.Fatalf("PullRequestStatus failed: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected no pull request, got %+v", got)
+	}
+}
+
+func TestApprovingReviewers(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/knwoop/giwo/pulls/7/reviews" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[
+			{"user":{"login":"alice"},"state":"APPROVED"},
+			{"user":{"login":"bob"},"state":"APPROVED"},
+			{"user":{"login":"bob"},"state":"CHANGES_REQUESTED"},
+			{"user":{"login":"carol"},"state":"CHANGES_REQUESTED"},
+			{"user":{"login":"carol"},"state":"APPROVED"},
+			{"user":{"login":"carol"},"state":"COMMENTED"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewWithToken("secret")
+	client.baseURL = server.URL
+
+	got, err := client.ApprovingReviewers(context.Background(), "knwoop", "giwo", 7)
+	if err != nil {
+		t.Fatalf("ApprovingReviewers failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"alice", "carol"}, got); diff != "" {
+		t.Errorf("ApprovingReviewers mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,116 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// DownloadTimeout bounds the download of a release asset, which is larger than
+// an API response.
+const DownloadTimeout = 5 * time.Minute
+
+// Release is a published release of a repository.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	HTMLURL    string  `json:"html_url"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Asset returns the asset of the release called name, or nil.
+func (r *Release) Asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Releases returns the most recent releases of a repository, newest first.
+// Releases of public repositories need no token, though without one the API
+// allows few requests an hour.
+func (c *Client) Releases(ctx context.Context, owner, repo string) ([]Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/%s/releases?per_page=30", c.baseURL, owner, repo), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "gwt-cli")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrGitHubAPIUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: listing releases returned %s", errors.ErrGitHubAPIUnavailable, resp.Status)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return releases, nil
+}
+
+// LatestRelease returns the first release of releases, newest first, that is
+// not a draft, skipping prereleases unless prerelease is set. It returns nil
+// if there is none.
+func LatestRelease(releases []Release, prerelease bool) *Release {
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || (r.Prerelease && !prerelease) {
+			continue
+		}
+		return r
+	}
+	return nil
+}
+
+// Download returns the content of a release asset.
+func (c *Client) Download(ctx context.Context, asset *Asset) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, DownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gwt-cli")
+
+	// The client's timeout is meant for API calls
+	resp, err := (&http.Client{Transport: c.httpClient.Transport}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", asset.Name, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,61 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReleases(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/knwoop/giwo/releases", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"tag_name":"v1.4.0","draft":true},
+			{"tag_name":"v1.3.0-rc.1","prerelease":true,"assets":[{"name":"checksums.txt","browser_download_url":"` + "http://" + r.Host + `/download/checksums.txt","size":3}]},
+			{"tag_name":"v1.2.0","html_url":"https://github.com/knwoop/giwo/releases/tag/v1.2.0"}
+		]`))
+	})
+	mux.HandleFunc("/download/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("abc"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New()
+	client.baseURL = server.URL
+
+	ctx := context.Background()
+	releases, err := client.Releases(ctx, "knwoop", "giwo")
+	if err != nil {
+		t.Fatalf("Releases failed: %v", err)
+	}
+
+	stable := LatestRelease(releases, false)
+	if diff := cmp.Diff("v1.2.0", stable.TagName); diff != "" {
+		t.Errorf("stable release mismatch (-want +got):\n%s", diff)
+	}
+	prerelease := LatestRelease(releases, true)
+	if diff := cmp.Diff("v1.3.0-rc.1", prerelease.TagName); diff != "" {
+		t.Errorf("prerelease mismatch (-want +got):\n%s", diff)
+	}
+	if LatestRelease(releases[:1], true) != nil {
+		t.Error("LatestRelease returned a draft")
+	}
+
+	asset := prerelease.Asset("checksums.txt")
+	if asset == nil {
+		t.Fatal("checksums.txt asset not found")
+	}
+	data, err := client.Download(ctx, asset)
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if diff := cmp.Diff("abc", string(data)); diff != "" {
+		t.Errorf("Download mismatch (-want +got):\n%s", diff)
+	}
+}
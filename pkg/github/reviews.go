@@ -0,0 +1,61 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ApprovingReviewers returns the logins whose latest review of a pull request is an approval.
+func (c *Client) ApprovingReviewers(ctx context.Context, owner, repo string, number int) ([]string, error) {
+	var reviews []struct {
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		State string `json:"state"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews?per_page=100", owner, repo, number), &reviews); err != nil {
+		return nil, err
+	}
+
+	// Reviews are returned oldest first; a later review supersedes an earlier approval
+	latest := make(map[string]string)
+	for _, review := range reviews {
+		if review.State == "COMMENTED" {
+			continue
+		}
+		latest[review.User.Login] = review.State
+	}
+
+	var approvers []string
+	for login, state := range latest {
+		if state == "APPROVED" {
+			approvers = append(approvers, login)
+		}
+	}
+	sort.Strings(approvers)
+
+	return approvers, nil
+}
+
+// TeamMembers returns the logins of the members of a team given as "org/team".
+func (c *Client) TeamMembers(ctx context.Context, team string) ([]string, error) {
+	org, slug, ok := strings.Cut(strings.TrimPrefix(team, "@"), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid team %q: expected org/team", team)
+	}
+
+	var members []struct {
+		Login string `json:"login"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/teams/%s/members?per_page=100", org, slug), &members); err != nil {
+		return nil, err
+	}
+
+	logins := make([]string, 0, len(members))
+	for _, member := range members {
+		logins = append(logins, member.Login)
+	}
+	return logins, nil
+}
@@ -0,0 +1,81 @@
+// Package jira provides Jira API integration for giwo.
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultRequestTimeout is the default timeout for HTTP requests.
+const DefaultRequestTimeout = 10 * time.Second
+
+// Client handles Jira REST API interactions.
+type Client struct {
+	baseURL    string
+	email      string
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a client for the Jira site at baseURL, e.g. https://acme.atlassian.net.
+// It authenticates with the JIRA_API_TOKEN environment variable: together with
+// JIRA_EMAIL as a Jira Cloud API token, on its own as a personal access token.
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		email:   os.Getenv("JIRA_EMAIL"),
+		token:   os.Getenv("JIRA_API_TOKEN"),
+		httpClient: &http.Client{
+			Timeout: DefaultRequestTimeout,
+		},
+	}
+}
+
+// IssueSummary returns the summary of the issue with key, e.g. PROJ-123.
+func (c *Client) IssueSummary(ctx context.Context, key string) (string, error) {
+	endpoint := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary", c.baseURL, url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	switch {
+	case c.token != "" && c.email != "":
+		req.SetBasicAuth(c.email, c.token)
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "giwo-cli")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up issue %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Jira also answers 404 for issues the credentials may not see
+		return "", fmt.Errorf("issue %s not found; check the key and JIRA_EMAIL/JIRA_API_TOKEN", key)
+	default:
+		return "", fmt.Errorf("failed to look up issue %s: %s", key, resp.Status)
+	}
+
+	var issue struct {
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return issue.Fields.Summary, nil
+}
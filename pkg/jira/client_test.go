@@ -0,0 +1,42 @@
+package jira
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIssueSummary(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/2/issue/PROJ-123", func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "me@example.com" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"key":"PROJ-123","fields":{"summary":"Fix login crash"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(server.URL + "/")
+	client.email = "me@example.com"
+	client.token = "secret"
+
+	ctx := context.Background()
+	got, err := client.IssueSummary(ctx, "PROJ-123")
+	if err != nil {
+		t.Fatalf("IssueSummary failed: %v", err)
+	}
+	if diff := cmp.Diff("Fix login crash", got); diff != "" {
+		t.Errorf("IssueSummary mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.IssueSummary(ctx, "PROJ-404"); err == nil {
+		t.Error("expected an error for a missing issue")
+	}
+}
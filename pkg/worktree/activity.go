@@ -0,0 +1,117 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ActiveAt returns when the worktree was last worked in: its last activity if
+// known, or else its last commit.
+func (wt *Worktree) ActiveAt() time.Time {
+	if !wt.LastActivity.IsZero() {
+		return wt.LastActivity
+	}
+	return wt.CommitTime
+}
+
+// SortByActivity sorts worktrees by their last activity, most recent first.
+// Worktrees without activity information come last, in their original order.
+func SortByActivity(worktrees []*Worktree) {
+	slices.SortStableFunc(worktrees, func(a, b *Worktree) int {
+		return b.ActiveAt().Compare(a.ActiveAt())
+	})
+}
+
+// Inactive returns the worktrees that have not been worked in since before
+// now minus age. Worktrees without activity information are never inactive.
+func Inactive(worktrees []*Worktree, age time.Duration, now time.Time) []*Worktree {
+	var inactive []*Worktree
+	for _, wt := range worktrees {
+		if at := wt.ActiveAt(); !at.IsZero() && now.Sub(at) >= age {
+			inactive = append(inactive, wt)
+		}
+	}
+	return inactive
+}
+
+// getActivity sets the last activity of a worktree: the latest of its last
+// commit, the last movement of its HEAD, the last change of its index and the
+// modification of the changed files given relative to the worktree.
+func getActivity(wt *Worktree, changed []string) {
+	latest := wt.CommitTime
+	later := func(t time.Time) {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+
+	gitDir := worktreeGitDir(wt.Path)
+	later(reflogTime(filepath.Join(gitDir, "logs", "HEAD")))
+	if info, err := os.Stat(filepath.Join(gitDir, "index")); err == nil {
+		later(info.ModTime())
+	}
+	for _, file := range changed {
+		// Deleted files have no modification time
+		if info, err := os.Lstat(filepath.Join(wt.Path, file)); err == nil {
+			later(info.ModTime())
+		}
+	}
+
+	wt.LastActivity = latest
+	if !latest.IsZero() {
+		wt.ActivityAge = formatTimeAgo(latest)
+	}
+}
+
+// reflogTime returns the time of the last entry of the reflog at path, or the
+// zero time if it is missing or unreadable.
+func reflogTime(path string) time.Time {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	entries := strings.TrimRight(string(data), "\n")
+	return parseReflogTime(entries[strings.LastIndex(entries, "\n")+1:])
+}
+
+// parseReflogTime returns the time of a reflog entry, which has the form
+// "<old> <new> <name> <<email>> <timestamp> <zone>\t<message>".
+func parseReflogTime(entry string) time.Time {
+	entry, _, _ = strings.Cut(entry, "\t")
+	end := strings.LastIndex(entry, ">")
+	if end < 0 {
+		return time.Time{}
+	}
+
+	fields := strings.Fields(entry[end+1:])
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+	timestamp, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0)
+}
+
+// statusPath returns the path of the file a line of 'git status --porcelain'
+// is about, the destination for renames.
+func statusPath(line string) string {
+	if len(line) < 4 {
+		return ""
+	}
+
+	path := line[3:]
+	if _, to, ok := strings.Cut(path, " -> "); ok {
+		path = to
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		path = unquoted
+	}
+	return path
+}
@@ -0,0 +1,132 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseReflogTime(t *testing.T) {
+	for name, tt := range map[string]struct {
+		entry    string
+		expected time.Time
+	}{
+		"checkout": {
+			entry:    "1111111 2222222 Jane Doe <jane@example.com> 1700000000 +0900\tcheckout: moving from main to feature",
+			expected: time.Unix(1700000000, 0),
+		},
+		"email with spaces in the name": {
+			entry:    "1111111 2222222 Jane Q. Doe <jane@example.com> 1700000100 -0500\tcommit: fix > bug",
+			expected: time.Unix(1700000100, 0),
+		},
+		"empty":            {entry: ""},
+		"missing identity": {entry: "1111111 2222222 1700000000 +0000"},
+		"bad timestamp":    {entry: "1111111 2222222 Jane <jane@example.com> soon +0000\tcommit"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, parseReflogTime(tt.entry)); diff != "" {
+				t.Errorf("parseReflogTime(%q) mismatch (-want +got):\n%s", tt.entry, diff)
+			}
+		})
+	}
+}
+
+func TestStatusPath(t *testing.T) {
+	for name, tt := range map[string]struct {
+		line     string
+		expected string
+	}{
+		"modified":  {line: " M cmd/root.go", expected: "cmd/root.go"},
+		"untracked": {line: "?? notes/", expected: "notes/"},
+		"renamed":   {line: "R  old.go -> new.go", expected: "new.go"},
+		"quoted":    {line: `?? "with space.txt"`, expected: "with space.txt"},
+		"too short": {line: "M", expected: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, statusPath(tt.line)); diff != "" {
+				t.Errorf("statusPath(%q) mismatch (-want +got):\n%s", tt.line, diff)
+			}
+		})
+	}
+}
+
+func TestGetActivity(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	if err := os.MkdirAll(filepath.Join(gitDir, "logs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	reflog := "0000000 1111111 Jane <jane@example.com> 1700000000 +0000\tcommit (initial): init\n" +
+		"1111111 2222222 Jane <jane@example.com> 1700000500 +0000\tcheckout: moving from main to feature\n"
+	if err := os.WriteFile(filepath.Join(gitDir, "logs", "HEAD"), []byte(reflog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := time.Unix(1700001000, 0)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "main.go"), edited, edited); err != nil {
+		t.Fatal(err)
+	}
+
+	wt := &Worktree{Path: dir, CommitTime: time.Unix(1700000000, 0)}
+	getActivity(wt, nil)
+	if diff := cmp.Diff(time.Unix(1700000500, 0), wt.LastActivity); diff != "" {
+		t.Errorf("LastActivity from the reflog mismatch (-want +got):\n%s", diff)
+	}
+
+	getActivity(wt, []string{"main.go", "deleted.go"})
+	if diff := cmp.Diff(edited, wt.LastActivity); diff != "" {
+		t.Errorf("LastActivity from changed files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSortByActivity(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	worktrees := []*Worktree{
+		{Path: "/wt/unknown"},
+		{Path: "/wt/old", CommitTime: now.Add(-48 * time.Hour)},
+		{Path: "/wt/active", CommitTime: now.Add(-72 * time.Hour), LastActivity: now.Add(-time.Hour)},
+		{Path: "/wt/recent", CommitTime: now.Add(-2 * time.Hour)},
+	}
+
+	SortByActivity(worktrees)
+	var paths []string
+	for _, wt := range worktrees {
+		paths = append(paths, wt.Path)
+	}
+	if diff := cmp.Diff([]string{"/wt/active", "/wt/recent", "/wt/old", "/wt/unknown"}, paths); diff != "" {
+		t.Errorf("SortByActivity() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInactive(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	worktrees := []*Worktree{
+		{Path: "/wt/unknown"},
+		{Path: "/wt/stale", CommitTime: now.Add(-40 * 24 * time.Hour)},
+		{Path: "/wt/edited", CommitTime: now.Add(-40 * 24 * time.Hour), LastActivity: now.Add(-time.Hour)},
+	}
+
+	var paths []string
+	for _, wt := range Inactive(worktrees, 30*24*time.Hour, now) {
+		paths = append(paths, wt.Path)
+	}
+	if diff := cmp.Diff([]string{"/wt/stale"}, paths); diff != "" {
+		t.Errorf("Inactive() mismatch (-want +got):\n%s", diff)
+	}
+}
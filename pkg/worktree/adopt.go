@@ -0,0 +1,144 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// AdoptOptions configures Adopt.
+type AdoptOptions struct {
+	// Path is the worktree to adopt.
+	Path string
+
+	// Base is the branch the worktree's branch started from, if known.
+	Base string
+
+	// Template is the key of the template override the worktree is set up
+	// with, as 'giwo create --template' takes it.
+	Template string
+
+	// Labels are attached to the worktree.
+	Labels []string
+}
+
+// Unmanaged returns the worktrees git knows that giwo does not, such as those
+// created with 'git worktree add', in or below dir, or anywhere if dir is
+// empty. The main worktree and worktrees whose directory is gone are left out.
+func (m *Manager) Unmanaged(ctx context.Context, dir string) ([]*Worktree, error) {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var unmanaged []*Worktree
+	for _, wt := range worktrees {
+		if wt.Path == m.mainRoot || wt.Prunable || !within(wt.Path, dir) {
+			continue
+		}
+		if _, ok := store[wt.Path]; !ok {
+			unmanaged = append(unmanaged, wt)
+		}
+	}
+	return unmanaged, nil
+}
+
+// Adopt records a worktree created outside giwo, such as with 'git worktree
+// add', in giwo's metadata with the base, template and labels of opts, as if
+// giwo had created it. The worktree must be registered with git, exist and not
+// be the main worktree. It reports whether the worktree was adopted, or known
+// to giwo already, in which case only the given options are recorded.
+func (m *Manager) Adopt(ctx context.Context, opts AdoptOptions) (*Worktree, bool, error) {
+	path, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	// git lists worktrees by their real path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	wt, err := m.findRegistered(ctx, path)
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case wt == nil && exists(filepath.Join(path, ".git")):
+		return nil, false, fmt.Errorf("%w: %s is not a worktree of %s; run giwo in its own repository",
+			errors.ErrWorktreeNotFound, path, m.mainRoot)
+	case wt == nil:
+		return nil, false, fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, path)
+	case wt.Path == m.mainRoot:
+		return nil, false, fmt.Errorf("the main worktree is always managed")
+	}
+	if _, err := os.Stat(wt.Path); err != nil {
+		return nil, false, fmt.Errorf("%w: the directory of %s is gone; 'giwo prune' cleans it up", errors.ErrWorktreeNotFound, wt.Path)
+	}
+	for _, label := range opts.Labels {
+		if err := ValidateLabel(label); err != nil {
+			return nil, false, err
+		}
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, false, err
+	}
+	_, managed := store[wt.Path]
+
+	if err := m.updateMetadata(wt.Path, func(meta *Metadata) {
+		if opts.Base != "" {
+			meta.Base = opts.Base
+		}
+		if opts.Template != "" {
+			meta.Template = opts.Template
+		}
+		wt.Base, wt.Template = meta.Base, meta.Template
+	}); err != nil {
+		return nil, false, fmt.Errorf("failed to record worktree metadata: %w", err)
+	}
+	if wt.Labels, err = m.Label(wt.Path, opts.Labels, nil); err != nil {
+		return nil, false, fmt.Errorf("failed to label worktree: %w", err)
+	}
+
+	return wt, !managed, nil
+}
+
+// AdoptDestination returns where the worktree wt goes in the worktree
+// directory: under its branch name, or the name of its directory for a
+// detached HEAD. It returns "" if it is there already.
+func (m *Manager) AdoptDestination(wt *Worktree) string {
+	name := filepath.Base(wt.Path)
+	if !wt.Detached && wt.Branch != "" {
+		name = wt.Branch
+	}
+	dest := m.WorktreePath(name)
+	if dest == wt.Path {
+		return ""
+	}
+	return dest
+}
+
+// RecordTemplate records the key of the template override the worktree at
+// path was set up with.
+func (m *Manager) RecordTemplate(path, template string) error {
+	return m.updateMetadata(path, func(meta *Metadata) { meta.Template = template })
+}
+
+// within reports whether path is dir or lies below it. Every path is within
+// an empty dir.
+func within(path, dir string) bool {
+	if dir == "" {
+		return true
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,58 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithin(t *testing.T) {
+	for name, tt := range map[string]struct {
+		path     string
+		dir      string
+		expected bool
+	}{
+		"below":          {path: "/src/scratch/hotfix", dir: "/src/scratch", expected: true},
+		"same":           {path: "/src/scratch", dir: "/src/scratch", expected: true},
+		"sibling prefix": {path: "/src/scratch-old/hotfix", dir: "/src/scratch"},
+		"above":          {path: "/src", dir: "/src/scratch"},
+		"no directory":   {path: "/anywhere/hotfix", expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, within(tt.path, tt.dir)); diff != "" {
+				t.Errorf("within() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAdoptDestination(t *testing.T) {
+	m := &Manager{worktreeDir: "/repo/.worktree"}
+
+	for name, tt := range map[string]struct {
+		worktree *Worktree
+		expected string
+	}{
+		"named after branch": {
+			worktree: &Worktree{Path: "/src/scratch/fix", Branch: "feature/login"},
+			expected: "/repo/.worktree/feature/login",
+		},
+		"detached": {
+			worktree: &Worktree{Path: "/src/scratch/build", Branch: "HEAD", Detached: true},
+			expected: "/repo/.worktree/build",
+		},
+		"in place": {
+			worktree: &Worktree{Path: "/repo/.worktree/hotfix", Branch: "hotfix"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, m.AdoptDestination(tt.worktree)); diff != "" {
+				t.Errorf("AdoptDestination() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// archiveRefPrefix is where archived worktrees are kept, one ref per worktree name.
+const archiveRefPrefix = "refs/giwo/archive/"
+
+// Archive is a worktree that was snapshotted and removed by Manager.Archive.
+type Archive struct {
+	// Name is the worktree's name relative to the worktree directory, which it is restored to.
+	Name string
+
+	// Branch is empty for worktrees that had a detached HEAD.
+	Branch string
+
+	// Head is the commit the worktree was on, and Base the one it was originally created from.
+	Head string
+	Base string
+
+	Time time.Time
+
+	// Snapshot is the commit holding the worktree's files, with Head as its parent.
+	Snapshot string
+}
+
+// Archive records the state of the named worktree under refs/giwo/archive/ and
+// removes it, keeping its branch. The snapshot holds the branch, the commit the
+// worktree was on and all files that are not ignored, including uncommitted and
+// untracked ones; which changes were staged is not kept. Locked worktrees are refused.
+func (m *Manager) Archive(ctx context.Context, name string) (*Archive, error) {
+	path := m.WorktreePath(name)
+	wt, err := m.findRegistered(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, name)
+	}
+	if wt.Locked {
+		return nil, lockedError(wt)
+	}
+
+	ref := archiveRefPrefix + name
+	if m.refExists(ctx, ref) {
+		return nil, fmt.Errorf("%w: %s; restore it first", errors.ErrArchiveExists, name)
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{Name: name, Head: wt.Head, Time: time.Now()}
+	if !wt.Detached {
+		archive.Branch = wt.Branch
+	}
+	if meta, ok := store[path]; ok {
+		archive.Base = meta.Base
+	}
+
+	done := m.step("Snapshotting " + name)
+	tree, err := m.snapshotTree(ctx, path)
+	done(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+
+	message := fmt.Sprintf("giwo archive of %s\n\nBranch: %s\nBase: %s\n", name, archive.Branch, archive.Base)
+	snapshot, err := m.gitOutput(ctx, path, "commit-tree", tree, "-p", wt.Head, "-m", message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record snapshot: %w", err)
+	}
+	archive.Snapshot = strings.TrimSpace(snapshot)
+
+	// The empty old value makes the update fail if the ref appeared in the meantime
+	if err := m.runGitCommand(ctx, "update-ref", ref, archive.Snapshot, ""); err != nil {
+		return nil, fmt.Errorf("failed to record archive: %w", err)
+	}
+
+	if err := m.Remove(ctx, RemoveOptions{Name: name, Force: true, KeepBranch: true}); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// snapshotTree writes a tree of all files in the worktree at path that are not ignored,
+// using a temporary index so that the worktree's own index is left alone.
+func (m *Manager) snapshotTree(ctx context.Context, path string) (string, error) {
+	dir, err := os.MkdirTemp("", "giwo-archive-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(dir, "index")}
+	var tree bytes.Buffer
+	for _, args := range [][]string{{"read-tree", "HEAD"}, {"add", "--all"}, {"write-tree"}} {
+		tree.Reset()
+		if err := m.runGit(ctx, GitCommand{Dir: path, Args: args, Env: env, Stdout: &tree}); err != nil {
+			return "", errors.NewGitError(args[0], args[1:], err)
+		}
+	}
+
+	return strings.TrimSpace(tree.String()), nil
+}
+
+// Archives returns the archived worktrees, most recent first.
+func (m *Manager) Archives(ctx context.Context) ([]Archive, error) {
+	output, err := m.gitOutput(ctx, m.repoRoot, "for-each-ref", "--format=%(refname)", archiveRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+
+	var archives []Archive
+	for _, ref := range strings.Fields(output) {
+		archive, err := m.readArchive(ctx, strings.TrimPrefix(ref, archiveRefPrefix))
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, *archive)
+	}
+	sort.Slice(archives, func(i, j int) bool { return archives[i].Time.After(archives[j].Time) })
+
+	return archives, nil
+}
+
+// DeleteArchive drops the archive of the named worktree. Its branch is kept.
+func (m *Manager) DeleteArchive(ctx context.Context, name string) error {
+	if !m.refExists(ctx, archiveRefPrefix+name) {
+		return fmt.Errorf("%w: %s", errors.ErrArchiveNotFound, name)
+	}
+	if err := m.runGitCommand(ctx, "update-ref", "-d", archiveRefPrefix+name); err != nil {
+		return fmt.Errorf("failed to drop archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// readArchive reads the archive of the named worktree.
+func (m *Manager) readArchive(ctx context.Context, name string) (*Archive, error) {
+	ref := archiveRefPrefix + name
+	if !m.refExists(ctx, ref) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrArchiveNotFound, name)
+	}
+
+	output, err := m.gitOutput(ctx, m.repoRoot, "log", "-1", "--format=%H%x00%P%x00%ct%x00%B", ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", name, err)
+	}
+	return parseArchive(name, output)
+}
+
+// parseArchive parses the snapshot commit of an archive, as printed by
+// 'git log --format=%H%x00%P%x00%ct%x00%B'.
+func parseArchive(name, output string) (*Archive, error) {
+	fields := strings.SplitN(output, "\x00", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid archive %s", name)
+	}
+
+	timestamp, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive %s: %w", name, err)
+	}
+
+	archive := &Archive{
+		Name:     name,
+		Snapshot: fields[0],
+		Head:     strings.Fields(fields[1] + " ")[0],
+		Time:     time.Unix(timestamp, 0),
+	}
+	for _, line := range strings.Split(fields[3], "\n") {
+		// Archives of detached worktrees used to record HEAD as their branch
+		if branch, ok := strings.CutPrefix(line, "Branch: "); ok && strings.TrimSpace(branch) != "HEAD" {
+			archive.Branch = strings.TrimSpace(branch)
+		}
+		if base, ok := strings.CutPrefix(line, "Base: "); ok {
+			archive.Base = strings.TrimSpace(base)
+		}
+	}
+
+	return archive, nil
+}
+
+// Restore recreates an archived worktree at its original place with its files
+// as they were, as uncommitted changes, and drops the archive. The branch is
+// recreated if it was deleted. If it has moved on since, restoring is refused
+// unless force is set, as the snapshot would then undo the new commits in the
+// working tree. It returns the path of the restored worktree.
+func (m *Manager) Restore(ctx context.Context, name string, force bool) (string, error) {
+	archive, err := m.readArchive(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	path := m.WorktreePath(name)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%w: %s", errors.ErrWorktreeExists, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	var args []string
+	switch {
+	case archive.Branch == "":
+		args = []string{"--detach", path, archive.Head}
+	case m.refExists(ctx, "refs/heads/"+archive.Branch):
+		tip, err := m.gitOutput(ctx, m.repoRoot, "rev-parse", "refs/heads/"+archive.Branch)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(tip) != archive.Head && !force {
+			return "", fmt.Errorf("branch %s has moved on since it was archived; use --force to restore the snapshot on top of it anyway", archive.Branch)
+		}
+		args = []string{path, archive.Branch}
+	default:
+		args = []string{"-b", archive.Branch, path, archive.Head}
+	}
+
+	if err := m.addWorktree(ctx, path, args...); err != nil {
+		return "", err
+	}
+
+	// Check out the snapshot's files, then reset the index so that they show up as
+	// changes. This comes before setup, which should see the files as they were.
+	if _, err := m.gitOutput(ctx, path, "read-tree", "-u", "--reset", archive.Snapshot); err != nil {
+		return "", fmt.Errorf("failed to restore files: %w", err)
+	}
+	if _, err := m.gitOutput(ctx, path, "reset", "--quiet"); err != nil {
+		return "", fmt.Errorf("failed to restore files: %w", err)
+	}
+
+	if err := m.finishCreate(ctx, path, archive.Branch, archive.Base); err != nil {
+		return "", fmt.Errorf("failed to set up the restored worktree, whose archive is kept: %w", err)
+	}
+
+	if err := m.runGitCommand(ctx, "update-ref", "-d", archiveRefPrefix+name); err != nil {
+		return "", fmt.Errorf("failed to drop archive: %w", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,75 @@
+package worktree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseArchive(t *testing.T) {
+	for name, tt := range map[string]struct {
+		output    string
+		expected  *Archive
+		wantError bool
+	}{
+		"branch": {
+			output: "aaa\x00bbb\x001700000000\x00giwo archive of feature/x\n\nBranch: feature/x\nBase: main\n\n",
+			expected: &Archive{
+				Name:     "feature/x",
+				Branch:   "feature/x",
+				Head:     "bbb",
+				Base:     "main",
+				Time:     time.Unix(1700000000, 0),
+				Snapshot: "aaa",
+			},
+		},
+		"detached": {
+			output: "aaa\x00bbb\x001700000000\x00giwo archive of feature/x\n\nBranch: \nBase: v1.0.0\n",
+			expected: &Archive{
+				Name:     "feature/x",
+				Head:     "bbb",
+				Base:     "v1.0.0",
+				Time:     time.Unix(1700000000, 0),
+				Snapshot: "aaa",
+			},
+		},
+		"detached, recorded as HEAD": {
+			output: "aaa\x00bbb\x001700000000\x00giwo archive of feature/x\n\nBranch: HEAD\nBase: v1.0.0\n",
+			expected: &Archive{
+				Name:     "feature/x",
+				Head:     "bbb",
+				Base:     "v1.0.0",
+				Time:     time.Unix(1700000000, 0),
+				Snapshot: "aaa",
+			},
+		},
+		"truncated": {
+			output:    "aaa\x00bbb",
+			wantError: true,
+		},
+		"invalid time": {
+			output:    "aaa\x00bbb\x00yesterday\x00giwo archive of feature/x\n",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			archive, err := parseArchive("feature/x", tt.output)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("parseArchive expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArchive unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, archive); diff != "" {
+				t.Errorf("parseArchive mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package worktree
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Priority ranks suggestions by how much they matter.
+type Priority int
+
+// Priorities, most urgent first.
+const (
+	PriorityHigh Priority = iota
+	PriorityMedium
+	PriorityLow
+)
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// MarshalText encodes the priority by name.
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// Suggestion is a giwo command worth running, as proposed by Suggest.
+type Suggestion struct {
+	Priority Priority `json:"priority"`
+	Command  string   `json:"command"`
+	Reason   string   `json:"reason"`
+
+	// Worktrees are the paths of the worktrees the suggestion is about.
+	Worktrees []string `json:"worktrees"`
+}
+
+// SuggestOptions configures Suggest.
+type SuggestOptions struct {
+	// Now is the time commit ages are measured against.
+	Now time.Time
+
+	// StaleAfter is the commit age from which a worktree counts as abandoned.
+	StaleAfter time.Duration
+
+	// Name returns the name giwo commands know a worktree by.
+	Name func(*Worktree) string
+}
+
+// Suggest inspects worktrees, as returned by List, and proposes what to do
+// next, most urgent first:
+//
+//   - high: remove clean worktrees of merged branches, rebase diverged branches
+//   - medium: push unpushed commits, finish or discard old uncommitted work,
+//     save uncommitted work of merged branches
+//   - low: prune worktrees whose directory is gone, fast-forward branches that
+//     are behind, archive abandoned worktrees
+//
+// merged lists the branches merged upstream. The main worktree and locked
+// worktrees are left alone.
+func Suggest(worktrees []*Worktree, merged []string, opts SuggestOptions) []Suggestion {
+	isMerged := make(map[string]bool, len(merged))
+	for _, branch := range merged {
+		isMerged[branch] = true
+	}
+
+	var suggestions []Suggestion
+	var removable, prunable []*Worktree
+	for _, wt := range worktrees {
+		if wt.IsMain || wt.Locked {
+			continue
+		}
+		if wt.Prunable {
+			prunable = append(prunable, wt)
+			continue
+		}
+
+		name := opts.Name(wt)
+		changes := wt.Added + wt.Modified + wt.Deleted
+		stale := !wt.CommitTime.IsZero() && opts.Now.Sub(wt.CommitTime) >= opts.StaleAfter
+
+		if wt.Branch != "" && isMerged[wt.Branch] {
+			if wt.IsClean {
+				removable = append(removable, wt)
+				continue
+			}
+			suggestions = append(suggestions, Suggestion{
+				Priority:  PriorityMedium,
+				Command:   "giwo show " + name,
+				Reason:    fmt.Sprintf("%s is merged but has %s; commit or discard them, then remove the worktree", name, plural(changes, "uncommitted change")),
+				Worktrees: []string{wt.Path},
+			})
+			continue
+		}
+
+		switch {
+		case wt.Ahead > 0 && wt.Behind > 0:
+			suggestions = append(suggestions, Suggestion{
+				Priority:  PriorityHigh,
+				Command:   fmt.Sprintf("giwo exec %s -- git pull --rebase", name),
+				Reason:    fmt.Sprintf("%s has diverged from %s: %d ahead, %d behind", name, wt.Upstream, wt.Ahead, wt.Behind),
+				Worktrees: []string{wt.Path},
+			})
+		case wt.Ahead > 0 && wt.Upstream == "origin/"+wt.Branch:
+			suggestions = append(suggestions, Suggestion{
+				Priority:  PriorityMedium,
+				Command:   fmt.Sprintf("giwo exec %s -- git push", name),
+				Reason:    fmt.Sprintf("%s has %s not on %s", name, plural(wt.Ahead, "unpushed commit"), wt.Upstream),
+				Worktrees: []string{wt.Path},
+			})
+		case wt.Ahead > 0:
+			// The branch tracks its base, so it has never been pushed
+			suggestions = append(suggestions, Suggestion{
+				Priority:  PriorityMedium,
+				Command:   fmt.Sprintf("giwo exec %s -- git push -u origin %s", name, wt.Branch),
+				Reason:    fmt.Sprintf("%s has %s on top of %s and was never pushed", name, plural(wt.Ahead, "commit"), wt.Upstream),
+				Worktrees: []string{wt.Path},
+			})
+		case wt.Behind > 0 && wt.IsClean:
+			suggestions = append(suggestions, Suggestion{
+				Priority:  PriorityLow,
+				Command:   fmt.Sprintf("giwo exec %s -- git pull --ff-only", name),
+				Reason:    fmt.Sprintf("%s is %s behind %s", name, plural(wt.Behind, "commit"), wt.Upstream),
+				Worktrees: []string{wt.Path},
+			})
+		}
+
+		switch {
+		case stale && !wt.IsClean:
+			suggestions = append(suggestions, Suggestion{
+				Priority:  PriorityMedium,
+				Command:   "giwo switch " + name,
+				Reason:    fmt.Sprintf("%s has %s and was last committed to %s; finish or discard the work", name, plural(changes, "uncommitted change"), wt.CommitAge),
+				Worktrees: []string{wt.Path},
+			})
+		case stale && wt.Ahead == 0:
+			suggestions = append(suggestions, Suggestion{
+				Priority:  PriorityLow,
+				Command:   "giwo archive " + name,
+				Reason:    fmt.Sprintf("%s was last committed to %s; archive it to free the directory, 'giwo restore' brings it back", name, wt.CommitAge),
+				Worktrees: []string{wt.Path},
+			})
+		}
+	}
+
+	switch len(removable) {
+	case 0:
+	case 1:
+		name := opts.Name(removable[0])
+		suggestions = append(suggestions, Suggestion{
+			Priority:  PriorityHigh,
+			Command:   "giwo remove " + name,
+			Reason:    fmt.Sprintf("%s is merged and has no uncommitted changes", name),
+			Worktrees: []string{removable[0].Path},
+		})
+	default:
+		command := "giwo clean"
+		if slices.ContainsFunc(removable, func(wt *Worktree) bool { return wt.Kind == KindMaintenance }) {
+			command += " --include-maintenance"
+		}
+		suggestions = append(suggestions, Suggestion{
+			Priority:  PriorityHigh,
+			Command:   command,
+			Reason:    fmt.Sprintf("%s are merged and have no uncommitted changes", joinNames(removable, opts.Name)),
+			Worktrees: worktreePaths(removable),
+		})
+	}
+
+	if len(prunable) > 0 {
+		reason := fmt.Sprintf("the directories of %s are gone", joinNames(prunable, opts.Name))
+		if len(prunable) == 1 {
+			reason = fmt.Sprintf("the directory of %s is gone", opts.Name(prunable[0]))
+		}
+		suggestions = append(suggestions, Suggestion{
+			Priority:  PriorityLow,
+			Command:   "giwo prune",
+			Reason:    reason,
+			Worktrees: worktreePaths(prunable),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Priority < suggestions[j].Priority })
+	return suggestions
+}
+
+// plural formats n things, adding an s for more than one.
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// joinNames lists the names of worktrees.
+func joinNames(worktrees []*Worktree, name func(*Worktree) string) string {
+	names := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		names[i] = name(wt)
+	}
+	return strings.Join(names, ", ")
+}
+
+// worktreePaths returns the paths of worktrees.
+func worktreePaths(worktrees []*Worktree) []string {
+	p := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		p[i] = wt.Path
+	}
+	return p
+}
@@ -0,0 +1,92 @@
+package worktree
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSuggest(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-2 * time.Hour)
+	old := now.Add(-60 * 24 * time.Hour)
+
+	for name, tt := range map[string]struct {
+		worktrees []*Worktree
+		merged    []string
+		expected  []Suggestion
+	}{
+		"nothing to do": {
+			worktrees: []*Worktree{
+				{Path: "/repo", Branch: "main", IsMain: true, IsClean: true, Behind: 3, CommitTime: old},
+				{Path: "/wt/feat", Branch: "feat", IsClean: true, CommitTime: recent},
+				{Path: "/wt/held", Branch: "held", IsClean: true, Locked: true, CommitTime: old},
+			},
+			merged: []string{"held"},
+		},
+		"merged worktree": {
+			worktrees: []*Worktree{
+				{Path: "/wt/done", Branch: "done", IsClean: true, CommitTime: recent},
+			},
+			merged: []string{"done"},
+			expected: []Suggestion{
+				{Priority: PriorityHigh, Command: "giwo remove done", Reason: "done is merged and has no uncommitted changes", Worktrees: []string{"/wt/done"}},
+			},
+		},
+		"several merged worktrees": {
+			worktrees: []*Worktree{
+				{Path: "/wt/a", Branch: "a", IsClean: true, CommitTime: old, CommitAge: "60d ago"},
+				{Path: "/wt/b", Branch: "b", IsClean: true, CommitTime: recent},
+				{Path: "/wt/c", Branch: "c", Modified: 2, CommitTime: recent},
+			},
+			merged: []string{"a", "b", "c"},
+			expected: []Suggestion{
+				{Priority: PriorityHigh, Command: "giwo clean", Reason: "a, b are merged and have no uncommitted changes", Worktrees: []string{"/wt/a", "/wt/b"}},
+				{Priority: PriorityMedium, Command: "giwo show c", Reason: "c is merged but has 2 uncommitted changes; commit or discard them, then remove the worktree", Worktrees: []string{"/wt/c"}},
+			},
+		},
+		"upstream sync": {
+			worktrees: []*Worktree{
+				{Path: "/wt/behind", Branch: "behind", IsClean: true, Upstream: "origin/behind", Behind: 1, CommitTime: recent},
+				{Path: "/wt/ahead", Branch: "ahead", IsClean: true, Upstream: "origin/ahead", Ahead: 3, CommitTime: recent},
+				{Path: "/wt/new", Branch: "new", IsClean: true, Upstream: "origin/main", Ahead: 1, CommitTime: recent},
+				{Path: "/wt/diverged", Branch: "diverged", Upstream: "origin/diverged", Ahead: 1, Behind: 4, CommitTime: recent},
+			},
+			expected: []Suggestion{
+				{Priority: PriorityHigh, Command: "giwo exec diverged -- git pull --rebase", Reason: "diverged has diverged from origin/diverged: 1 ahead, 4 behind", Worktrees: []string{"/wt/diverged"}},
+				{Priority: PriorityMedium, Command: "giwo exec ahead -- git push", Reason: "ahead has 3 unpushed commits not on origin/ahead", Worktrees: []string{"/wt/ahead"}},
+				{Priority: PriorityMedium, Command: "giwo exec new -- git push -u origin new", Reason: "new has 1 commit on top of origin/main and was never pushed", Worktrees: []string{"/wt/new"}},
+				{Priority: PriorityLow, Command: "giwo exec behind -- git pull --ff-only", Reason: "behind is 1 commit behind origin/behind", Worktrees: []string{"/wt/behind"}},
+			},
+		},
+		"stale worktrees": {
+			worktrees: []*Worktree{
+				{Path: "/wt/wip", Branch: "wip", Added: 1, CommitTime: old, CommitAge: "60d ago"},
+				{Path: "/wt/idle", Branch: "idle", IsClean: true, CommitTime: old, CommitAge: "60d ago"},
+				{Path: "/wt/unpushed", Branch: "unpushed", IsClean: true, Upstream: "origin/unpushed", Ahead: 1, CommitTime: old, CommitAge: "60d ago"},
+				{Path: "/wt/gone", Branch: "gone", Prunable: true},
+			},
+			expected: []Suggestion{
+				{Priority: PriorityMedium, Command: "giwo switch wip", Reason: "wip has 1 uncommitted change and was last committed to 60d ago; finish or discard the work", Worktrees: []string{"/wt/wip"}},
+				{Priority: PriorityMedium, Command: "giwo exec unpushed -- git push", Reason: "unpushed has 1 unpushed commit not on origin/unpushed", Worktrees: []string{"/wt/unpushed"}},
+				{Priority: PriorityLow, Command: "giwo archive idle", Reason: "idle was last committed to 60d ago; archive it to free the directory, 'giwo restore' brings it back", Worktrees: []string{"/wt/idle"}},
+				{Priority: PriorityLow, Command: "giwo prune", Reason: "the directory of gone is gone", Worktrees: []string{"/wt/gone"}},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			suggestions := Suggest(tt.worktrees, tt.merged, SuggestOptions{
+				Now:        now,
+				StaleAfter: 30 * 24 * time.Hour,
+				Name:       func(wt *Worktree) string { return filepath.Base(wt.Path) },
+			})
+			if diff := cmp.Diff(tt.expected, suggestions); diff != "" {
+				t.Errorf("Suggest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
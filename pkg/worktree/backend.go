@@ -0,0 +1,126 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitCommand is a git invocation run through a GitBackend.
+type GitCommand struct {
+	// Dir is the directory git runs in.
+	Dir  string
+	Args []string
+
+	// Env is added to the environment, e.g. "GIT_INDEX_FILE=/tmp/index".
+	Env []string
+
+	// Stdout and Stderr receive git's output if set. Without Stderr, what git
+	// prints there is included in the error of a failed command.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// GitBackend performs the git operations of a Manager.
+// Errors of commands that ran wrap the *exec.ExitError with git's exit status.
+type GitBackend interface {
+	// Run runs a git command.
+	Run(ctx context.Context, cmd GitCommand) error
+
+	// ListWorktrees returns the worktrees registered in the repository whose
+	// main worktree is at repoRoot and whose shared git directory is gitCommonDir,
+	// main worktree first, as 'git worktree list' reports them.
+	ListWorktrees(ctx context.Context, repoRoot, gitCommonDir string) ([]*Worktree, error)
+
+	// ResolveCommit returns the commit ref resolves to in the repository at dir,
+	// or false if it does not resolve to one.
+	ResolveCommit(ctx context.Context, dir, ref string) (string, bool)
+}
+
+// Backend names accepted by NewGitBackend.
+const (
+	BackendExec  = "exec"
+	BackendGoGit = "go-git"
+)
+
+// NewGitBackend returns the backend called name: "exec" (the default) runs the
+// git executable for everything, while "go-git" lists worktrees and resolves
+// revisions with go-git to spawn fewer processes.
+func NewGitBackend(name string) (GitBackend, error) {
+	switch name {
+	case "", BackendExec:
+		return ExecBackend{}, nil
+	case BackendGoGit:
+		return GoGitBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown git backend %q: must be %s or %s", name, BackendExec, BackendGoGit)
+	}
+}
+
+// SetGitBackend replaces the backend performing git operations, ExecBackend by default.
+func (m *Manager) SetGitBackend(backend GitBackend) {
+	m.backend = backend
+}
+
+// git returns the manager's backend.
+func (m *Manager) git() GitBackend {
+	if m.backend == nil {
+		return ExecBackend{}
+	}
+	return m.backend
+}
+
+// runGit runs a git command through the backend, timing and logging it.
+func (m *Manager) runGit(ctx context.Context, cmd GitCommand) error {
+	done := m.timeGit(cmd.Dir, cmd.Args...)
+	err := m.git().Run(ctx, cmd)
+	done(err)
+	return err
+}
+
+// ExecBackend runs the git executable.
+type ExecBackend struct{}
+
+// Run implements GitBackend.
+func (ExecBackend) Run(ctx context.Context, c GitCommand) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", c.Args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	if c.Stderr == nil {
+		cmd.Stderr = &stderr
+	}
+
+	err := cmd.Run()
+	if msg := strings.TrimSpace(stderr.String()); err != nil && msg != "" {
+		err = fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
+
+// ListWorktrees implements GitBackend.
+func (b ExecBackend) ListWorktrees(ctx context.Context, repoRoot, gitCommonDir string) ([]*Worktree, error) {
+	var output bytes.Buffer
+	if err := b.Run(ctx, GitCommand{Dir: repoRoot, Args: []string{"worktree", "list", "--porcelain"}, Stdout: &output}); err != nil {
+		return nil, err
+	}
+	return parseWorktreeList(output.String())
+}
+
+// ResolveCommit implements GitBackend.
+func (b ExecBackend) ResolveCommit(ctx context.Context, dir, ref string) (string, bool) {
+	var output bytes.Buffer
+	err := b.Run(ctx, GitCommand{Dir: dir, Args: []string{"rev-parse", "--verify", "--quiet", ref + "^{commit}"}, Stdout: &output})
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(output.String()), true
+}
@@ -0,0 +1,106 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BaseStatus compares a local branch that new worktrees start from with its upstream.
+type BaseStatus struct {
+	Branch string
+
+	// Upstream is the branch tracked by Branch, e.g. "origin/main".
+	Upstream string
+
+	// Ahead and Behind count the commits only on Branch and only on Upstream.
+	Ahead  int
+	Behind int
+}
+
+// CanFastForward reports whether the branch is behind its upstream and can
+// catch up without a merge.
+func (s *BaseStatus) CanFastForward() bool {
+	return s.Behind > 0 && s.Ahead == 0
+}
+
+// CheckBase fetches the upstream of the local branch and compares the two.
+// It returns nil if branch is not a local branch with an upstream, such as a
+// tag, a commit or a branch that was never pushed.
+func (m *Manager) CheckBase(ctx context.Context, branch string) (*BaseStatus, error) {
+	ref := "refs/heads/" + branch
+	output, err := m.gitOutput(ctx, m.repoRoot, "for-each-ref", "--format=%(upstream:short) %(upstream:remotename)", ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the upstream of %s: %w", branch, err)
+	}
+	upstream, remote, _ := strings.Cut(strings.TrimSpace(output), " ")
+	if upstream == "" {
+		return nil, nil
+	}
+
+	// Branches tracking another local branch have nothing to fetch
+	switch {
+	case remote == ".":
+	case m.offline:
+		m.skipFetch()
+	default:
+		done := m.step("Fetching " + remote)
+		err := m.runGitCommand(ctx, "fetch", remote)
+		done(err)
+		if IsNetworkError(err) {
+			m.goOffline(err)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", remote, err)
+		}
+	}
+
+	if !m.refExists(ctx, upstream) {
+		return nil, fmt.Errorf("its upstream %s is gone", upstream)
+	}
+	counts, err := m.gitOutput(ctx, m.repoRoot, "rev-list", "--left-right", "--count", ref+"..."+upstream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s with %s: %w", branch, upstream, err)
+	}
+	status := &BaseStatus{Branch: branch, Upstream: upstream}
+	if _, err := fmt.Sscan(counts, &status.Ahead, &status.Behind); err != nil {
+		return nil, fmt.Errorf("failed to compare %s with %s: unexpected output %q", branch, upstream, counts)
+	}
+	return status, nil
+}
+
+// UpdateBase fast-forwards the branch of status to its upstream. A branch
+// checked out in a worktree is merged there, so that its files follow.
+func (m *Manager) UpdateBase(ctx context.Context, status *BaseStatus) error {
+	if !status.CanFastForward() {
+		return fmt.Errorf("%s cannot be fast-forwarded to %s: %d ahead, %d behind", status.Branch, status.Upstream, status.Ahead, status.Behind)
+	}
+
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == status.Branch {
+			if err := m.runGit(ctx, GitCommand{Dir: wt.Path, Args: []string{"merge", "--ff-only", "--quiet", status.Upstream}}); err != nil {
+				return fmt.Errorf("failed to fast-forward %s in %s: %w", status.Branch, wt.Path, err)
+			}
+			return nil
+		}
+	}
+
+	ref := "refs/heads/" + status.Branch
+	old, err := m.gitOutput(ctx, m.repoRoot, "rev-parse", ref)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", status.Branch, err)
+	}
+	target, err := m.gitOutput(ctx, m.repoRoot, "rev-parse", status.Upstream)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", status.Upstream, err)
+	}
+	// Passing the old value makes the update fail if the branch moved in the meantime
+	message := "giwo: fast-forward to " + status.Upstream
+	if err := m.runGitCommand(ctx, "update-ref", "-m", message, ref, strings.TrimSpace(target), strings.TrimSpace(old)); err != nil {
+		return fmt.Errorf("failed to fast-forward %s: %w", status.Branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// Operations of a batch.
+const (
+	BatchCreate = "create"
+	BatchRemove = "remove"
+	BatchExec   = "exec"
+)
+
+// Outcomes of a batch operation.
+const (
+	BatchOK      = "ok"
+	BatchFailed  = "failed"
+	BatchSkipped = "skipped"
+)
+
+// BatchOp is one operation of a batch: creating a worktree, removing one or
+// running a command in one. Worktrees are named by their branch.
+type BatchOp struct {
+	// ID names the operation for depends_on and in the results. Defaults to
+	// its index in the batch, such as "0".
+	ID string `json:"id,omitempty"`
+
+	// Op is create, remove or exec.
+	Op string `json:"op"`
+
+	// Branch names the worktree: the new branch for create.
+	Branch string `json:"branch"`
+
+	// Base is the remote branch create starts from, the default branch if
+	// empty, and From the commit, tag or branch it starts at instead.
+	Base string `json:"base,omitempty"`
+	From string `json:"from,omitempty"`
+
+	// Force makes create replace an existing directory and remove discard
+	// uncommitted changes. KeepBranch makes remove keep the branch.
+	Force      bool `json:"force,omitempty"`
+	KeepBranch bool `json:"keep_branch,omitempty"`
+
+	// Command is the program and arguments exec runs in the worktree.
+	Command []string `json:"command,omitempty"`
+
+	// DependsOn are the IDs of operations that must succeed first. Operations
+	// on the same worktree also run in the order they are given, and are
+	// skipped if an earlier create of it did not succeed.
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// BatchResult reports the outcome of one operation of a batch.
+type BatchResult struct {
+	ID     string `json:"id"`
+	Op     string `json:"op"`
+	Branch string `json:"branch"`
+
+	// Status is ok, failed, or skipped when an operation it depends on did not succeed.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// Path is the worktree the operation was about.
+	Path string `json:"path,omitempty"`
+
+	// ExitCode, Stdout and Stderr are those of the command of an exec operation.
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// DecodeBatch reads a JSON array of operations, rejecting unknown fields so
+// that typos do not go unnoticed, and validates it.
+func DecodeBatch(r io.Reader) ([]BatchOp, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var ops []BatchOp
+	if err := decoder.Decode(&ops); err != nil {
+		return nil, fmt.Errorf("invalid batch: %w", err)
+	}
+	if err := ValidateBatch(ops); err != nil {
+		return nil, fmt.Errorf("invalid batch: %w", err)
+	}
+	return ops, nil
+}
+
+// ValidateBatch fills in the default IDs of ops and checks that each
+// operation is complete and that the dependencies exist and have no cycle.
+func ValidateBatch(ops []BatchOp) error {
+	ids := make(map[string]bool, len(ops))
+	for i := range ops {
+		op := &ops[i]
+		if op.ID == "" {
+			op.ID = strconv.Itoa(i)
+		}
+		if ids[op.ID] {
+			return fmt.Errorf("[%d]: duplicate id %q", i, op.ID)
+		}
+		ids[op.ID] = true
+
+		if op.Branch == "" {
+			return fmt.Errorf("[%d]: branch is required", i)
+		}
+		switch op.Op {
+		case BatchCreate:
+			if err := utils.ValidateBranchName(op.Branch); err != nil {
+				return fmt.Errorf("[%d]: invalid branch name: %w", i, err)
+			}
+			if op.Base != "" && op.From != "" {
+				return fmt.Errorf("[%d]: base and from cannot be used together", i)
+			}
+		case BatchRemove:
+		case BatchExec:
+			if len(op.Command) == 0 {
+				return fmt.Errorf("[%d]: command is required for exec", i)
+			}
+		default:
+			return fmt.Errorf("[%d]: op must be create, remove or exec, got %q", i, op.Op)
+		}
+	}
+	for i, op := range ops {
+		for _, dep := range op.DependsOn {
+			if !ids[dep] {
+				return fmt.Errorf("[%d]: depends on unknown id %q", i, dep)
+			}
+		}
+	}
+	_, err := batchOrder(ops)
+	return err
+}
+
+// batchDeps returns, for each operation of ops, the indexes of the operations
+// it runs after: those named in DependsOn and the earlier ones on the same
+// worktree. Of these, it requires to succeed those named in DependsOn and the
+// creation of its worktree, so that a worktree is removed even if a command
+// run in it failed.
+func batchDeps(ops []BatchOp) (after, requires [][]int) {
+	index := make(map[string]int, len(ops))
+	for i, op := range ops {
+		index[op.ID] = i
+	}
+	after = make([][]int, len(ops))
+	requires = make([][]int, len(ops))
+	for i, op := range ops {
+		for _, id := range op.DependsOn {
+			if j, ok := index[id]; ok && !slices.Contains(requires[i], j) {
+				requires[i] = append(requires[i], j)
+			}
+		}
+		after[i] = slices.Clone(requires[i])
+		for j := range i {
+			if ops[j].Branch != op.Branch {
+				continue
+			}
+			if !slices.Contains(after[i], j) {
+				after[i] = append(after[i], j)
+			}
+			if ops[j].Op == BatchCreate && !slices.Contains(requires[i], j) {
+				requires[i] = append(requires[i], j)
+			}
+		}
+	}
+	return after, requires
+}
+
+// batchOrder returns the indexes of ops in the order to run them: each after
+// the operations it depends on, and otherwise in the order given.
+func batchOrder(ops []BatchOp) ([]int, error) {
+	deps, _ := batchDeps(ops)
+	done := make([]bool, len(ops))
+	order := make([]int, 0, len(ops))
+	for len(order) < len(ops) {
+		next := -1
+		for i := range ops {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, j := range deps[i] {
+				ready = ready && done[j]
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for i, op := range ops {
+				if !done[i] {
+					cycle = append(cycle, op.ID)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle among %v", cycle)
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+	return order, nil
+}
+
+// RunBatch runs ops, validated with ValidateBatch, one at a time in
+// dependency order, and returns their results in the order given. An
+// operation is skipped if one named in its DependsOn, or the creation of its
+// worktree, did not succeed; the others run regardless of failures. RunBatch
+// never prompts.
+func (m *Manager) RunBatch(ctx context.Context, ops []BatchOp) ([]BatchResult, error) {
+	order, err := batchOrder(ops)
+	if err != nil {
+		return nil, err
+	}
+	_, requires := batchDeps(ops)
+
+	results := make([]BatchResult, len(ops))
+	for _, i := range order {
+		op := ops[i]
+		result := &results[i]
+		*result = BatchResult{ID: op.ID, Op: op.Op, Branch: op.Branch}
+
+		if j := slices.IndexFunc(requires[i], func(j int) bool { return results[j].Status != BatchOK }); j >= 0 {
+			result.Status = BatchSkipped
+			result.Error = fmt.Sprintf("depends on %s, which did not succeed", ops[requires[i][j]].ID)
+			continue
+		}
+
+		start := time.Now()
+		err := m.runBatchOp(ctx, op, result)
+		result.DurationMS = time.Since(start).Milliseconds()
+		result.Status = BatchOK
+		if err != nil {
+			result.Status = BatchFailed
+			result.Error = err.Error()
+		}
+	}
+	return results, nil
+}
+
+// runBatchOp runs one operation, recording its details in result.
+func (m *Manager) runBatchOp(ctx context.Context, op BatchOp, result *BatchResult) error {
+	switch op.Op {
+	case BatchCreate:
+		result.Path = m.WorktreePath(op.Branch)
+		if op.From != "" {
+			return m.CreateAt(ctx, op.Branch, op.From, false, op.Force)
+		}
+		base := op.Base
+		if base == "" {
+			base = m.DefaultBranch(ctx)
+		}
+		return m.Create(ctx, CreateOptions{Branch: op.Branch, Base: base, Force: op.Force})
+
+	case BatchRemove:
+		result.Path = m.WorktreePath(op.Branch)
+		return m.Remove(ctx, RemoveOptions{Name: op.Branch, Force: op.Force, KeepBranch: op.KeepBranch})
+
+	case BatchExec:
+		wt, err := m.batchWorktree(ctx, op.Branch)
+		if err != nil {
+			return err
+		}
+		result.Path = wt.Path
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, op.Command[0], op.Command[1:]...)
+		cmd.Dir = wt.Path
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err = cmd.Run()
+		result.Stdout, result.Stderr = stdout.String(), stderr.String()
+
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			code := 0
+			result.ExitCode = &code
+		case stderrors.As(err, &exitErr):
+			code := exitErr.ExitCode()
+			result.ExitCode = &code
+		}
+		return err
+	}
+	return fmt.Errorf("unknown op %q", op.Op)
+}
+
+// batchWorktree returns the worktree of branch, wherever it is checked out,
+// or the worktree named after it.
+func (m *Manager) batchWorktree(ctx context.Context, branch string) (*Worktree, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	path := m.WorktreePath(branch)
+	for _, wt := range worktrees {
+		if wt.Branch == branch || wt.Path == path {
+			return wt, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no worktree has %s checked out", errors.ErrWorktreeNotFound, branch)
+}
@@ -0,0 +1,193 @@
+package worktree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateBatch(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		ops       []BatchOp
+		wantIDs   []string
+		wantError string
+	}{
+		"default ids": {
+			ops: []BatchOp{
+				{Op: BatchCreate, Branch: "a"},
+				{ID: "test-a", Op: BatchExec, Branch: "a", Command: []string{"make", "test"}},
+				{Op: BatchRemove, Branch: "a", DependsOn: []string{"test-a"}},
+			},
+			wantIDs: []string{"0", "test-a", "2"},
+		},
+		"duplicate id": {
+			ops: []BatchOp{
+				{ID: "x", Op: BatchCreate, Branch: "a"},
+				{ID: "x", Op: BatchCreate, Branch: "b"},
+			},
+			wantError: `duplicate id "x"`,
+		},
+		"missing branch": {
+			ops:       []BatchOp{{Op: BatchCreate}},
+			wantError: "branch is required",
+		},
+		"invalid branch name": {
+			ops:       []BatchOp{{Op: BatchCreate, Branch: "bad..name"}},
+			wantError: "invalid branch name",
+		},
+		"unknown op": {
+			ops:       []BatchOp{{Op: "rename", Branch: "a"}},
+			wantError: `op must be create, remove or exec, got "rename"`,
+		},
+		"exec without command": {
+			ops:       []BatchOp{{Op: BatchExec, Branch: "a"}},
+			wantError: "command is required",
+		},
+		"base and from": {
+			ops:       []BatchOp{{Op: BatchCreate, Branch: "a", Base: "main", From: "v1.0"}},
+			wantError: "base and from cannot be used together",
+		},
+		"unknown dependency": {
+			ops:       []BatchOp{{Op: BatchCreate, Branch: "a", DependsOn: []string{"setup"}}},
+			wantError: `depends on unknown id "setup"`,
+		},
+		"cycle": {
+			ops: []BatchOp{
+				{ID: "a", Op: BatchCreate, Branch: "a", DependsOn: []string{"b"}},
+				{ID: "b", Op: BatchCreate, Branch: "b", DependsOn: []string{"a"}},
+			},
+			wantError: "dependency cycle among [a b]",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateBatch(tt.ops)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("ValidateBatch() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateBatch() error = %v", err)
+			}
+			var ids []string
+			for _, op := range tt.ops {
+				ids = append(ids, op.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, ids); diff != "" {
+				t.Errorf("ValidateBatch() ids mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBatchOrder(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		ops  []BatchOp
+		want []int
+	}{
+		"in order given": {
+			ops: []BatchOp{
+				{ID: "0", Branch: "a"},
+				{ID: "1", Branch: "b"},
+				{ID: "2", Branch: "a"},
+			},
+			want: []int{0, 1, 2},
+		},
+		"explicit dependency first": {
+			ops: []BatchOp{
+				{ID: "test", Branch: "app", DependsOn: []string{"lib"}},
+				{ID: "lib", Branch: "lib"},
+			},
+			want: []int{1, 0},
+		},
+		"same worktree keeps its order": {
+			ops: []BatchOp{
+				{ID: "create-a", Branch: "a", DependsOn: []string{"create-b"}},
+				{ID: "test-a", Branch: "a"},
+				{ID: "create-b", Branch: "b"},
+			},
+			want: []int{2, 0, 1},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := batchOrder(tt.ops)
+			if err != nil {
+				t.Fatalf("batchOrder() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("batchOrder() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeBatch(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		input     string
+		want      []BatchOp
+		wantError bool
+	}{
+		"operations": {
+			input: `[{"op": "create", "branch": "a", "base": "main"}, {"op": "exec", "branch": "a", "command": ["go", "test"]}]`,
+			want: []BatchOp{
+				{ID: "0", Op: BatchCreate, Branch: "a", Base: "main"},
+				{ID: "1", Op: BatchExec, Branch: "a", Command: []string{"go", "test"}},
+			},
+		},
+		"unknown field": {
+			input:     `[{"op": "create", "branch": "a", "bsae": "main"}]`,
+			wantError: true,
+		},
+		"not an array": {
+			input:     `{"op": "create", "branch": "a"}`,
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := DecodeBatch(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantError {
+				t.Fatalf("DecodeBatch() error = %v, wantError %v", err, tt.wantError)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("DecodeBatch() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBatchRequires(t *testing.T) {
+	t.Parallel()
+
+	ops := []BatchOp{
+		{ID: "create", Op: BatchCreate, Branch: "a"},
+		{ID: "test", Op: BatchExec, Branch: "a"},
+		{ID: "lint", Op: BatchExec, Branch: "a"},
+		{ID: "remove", Op: BatchRemove, Branch: "a"},
+		{ID: "other", Op: BatchCreate, Branch: "b", DependsOn: []string{"test"}},
+	}
+	after, requires := batchDeps(ops)
+
+	wantAfter := [][]int{nil, {0}, {0, 1}, {0, 1, 2}, {1}}
+	if diff := cmp.Diff(wantAfter, after); diff != "" {
+		t.Errorf("batchDeps() after mismatch (-want +got):\n%s", diff)
+	}
+	// Removing the worktree does not require the commands run in it to succeed
+	wantRequires := [][]int{nil, {0}, {0}, {0}, {1}}
+	if diff := cmp.Diff(wantRequires, requires); diff != "" {
+		t.Errorf("batchDeps() requires mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,139 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// KindBisect marks the worktree a bisect session runs in; see StartBisect.
+const KindBisect = "bisect"
+
+// bisectName is the name of the worktree bisect sessions run in.
+const bisectName = "bisect"
+
+// firstBadPattern matches the line with which git reports the end of a bisect.
+var firstBadPattern = regexp.MustCompile(`(?m)^([0-9a-f]{40,64}) is the first bad commit$`)
+
+// BisectState is where a bisect session stands after a step.
+type BisectState struct {
+	// Path is the worktree the session runs in.
+	Path string
+
+	// Current is the commit checked out to be tested next.
+	Current string
+
+	// FirstBad is the first bad commit, set once the session found it.
+	FirstBad string
+}
+
+// Bisection returns the worktree of the running bisect session, or nil if there
+// is none.
+func (m *Manager) Bisection(ctx context.Context) (*Worktree, error) {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wt := range worktrees {
+		if meta, ok := store[wt.Path]; ok && meta.Kind == KindBisect {
+			wt.Kind = meta.Kind
+			return wt, nil
+		}
+	}
+	return nil, nil
+}
+
+// StartBisect starts a bisect session looking for the commit between good and
+// bad that broke things. It runs in a worktree of its own with a detached HEAD,
+// so that the worktree the user works in is left untouched, as git keeps the
+// state of a bisect per worktree. git's output is written to w.
+func (m *Manager) StartBisect(ctx context.Context, w io.Writer, bad string, good ...string) (*BisectState, error) {
+	if wt, err := m.Bisection(ctx); err != nil {
+		return nil, err
+	} else if wt != nil {
+		return nil, fmt.Errorf("%w: a bisect session is running in %s", errors.ErrWorktreeExists, wt.Path)
+	}
+
+	if err := m.CreateAt(ctx, bisectName, bad, true, false); err != nil {
+		return nil, err
+	}
+	path := m.WorktreePath(bisectName)
+	if err := m.updateMetadata(path, func(meta *Metadata) {
+		meta.Kind = KindBisect
+		// Left behind sessions are cleaned up with the other throwaway worktrees
+		meta.Ephemeral = true
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record the bisect worktree: %w", err)
+	}
+
+	state, err := m.bisect(ctx, path, w, append([]string{"start", bad}, good...)...)
+	if err != nil {
+		// Such as for a good commit that is not an ancestor of the bad one
+		_ = m.Remove(context.WithoutCancel(ctx), RemoveOptions{Name: bisectName, Force: true, KeepBranch: true})
+		return nil, err
+	}
+	return state, nil
+}
+
+// Bisect runs 'git bisect' with args, such as good, bad, skip or run, in the
+// worktree of the running bisect session and returns where it stands then.
+// git's output is written to w. It fails with ErrNoBisect if no session runs.
+func (m *Manager) Bisect(ctx context.Context, w io.Writer, args ...string) (*BisectState, error) {
+	wt, err := m.Bisection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		return nil, errors.ErrNoBisect
+	}
+	return m.bisect(ctx, wt.Path, w, args...)
+}
+
+// bisect runs 'git bisect' with args in the worktree at path.
+func (m *Manager) bisect(ctx context.Context, path string, w io.Writer, args ...string) (*BisectState, error) {
+	var output bytes.Buffer
+	args = append([]string{"bisect"}, args...)
+	err := m.runGit(ctx, GitCommand{Dir: path, Args: args, Stdout: io.MultiWriter(w, &output), Stderr: w})
+	if err != nil {
+		return nil, errors.NewGitError(args[0], args[1:], err)
+	}
+
+	state := &BisectState{Path: path, FirstBad: parseFirstBad(output.String())}
+	if head, err := m.gitOutput(ctx, path, "rev-parse", "HEAD"); err == nil {
+		state.Current = strings.TrimSpace(head)
+	}
+	return state, nil
+}
+
+// EndBisect ends the running bisect session by removing its worktree, and with
+// it the session's state.
+func (m *Manager) EndBisect(ctx context.Context) error {
+	wt, err := m.Bisection(ctx)
+	if err != nil {
+		return err
+	}
+	if wt == nil {
+		return errors.ErrNoBisect
+	}
+	// Whatever builds and tests left in the worktree is of no use anymore
+	return m.Remove(ctx, RemoveOptions{Name: bisectName, Force: true, KeepBranch: true})
+}
+
+// parseFirstBad returns the first bad commit git reports in the output of a
+// bisect step, or "" if the bisect is not done.
+func parseFirstBad(output string) string {
+	if match := firstBadPattern.FindStringSubmatch(output); match != nil {
+		return match[1]
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFirstBad(t *testing.T) {
+	for name, tt := range map[string]struct {
+		output   string
+		expected string
+	}{
+		"in progress": {
+			output: "Bisecting: 3 revisions left to test after this (roughly 2 steps)\n[8acfe34e76e94a63b4f79c16da4bc4aec6e88886] c4\n",
+		},
+		"found": {
+			output: "07e1512fc072a4fcefcddb307022de0641dd0e8e is the first bad commit\n" +
+				"commit 07e1512fc072a4fcefcddb307022de0641dd0e8e\nAuthor: a <a@example.com>\n\n    c5\n",
+			expected: "07e1512fc072a4fcefcddb307022de0641dd0e8e",
+		},
+		"found by run": {
+			output: "running  'make' 'test'\n" +
+				"07e1512fc072a4fcefcddb307022de0641dd0e8e is the first bad commit\n" +
+				"bisect found first bad commit",
+			expected: "07e1512fc072a4fcefcddb307022de0641dd0e8e",
+		},
+		"only skipped commits left": {
+			output: "There are only 'skip'ped commits left to test.\nThe first bad commit could be any of:\n" +
+				"07e1512fc072a4fcefcddb307022de0641dd0e8e\n65efb50a98d1eba9a07cde347dcbcf647d07e1e4\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, parseFirstBad(tt.output)); diff != "" {
+				t.Errorf("parseFirstBad() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package worktree
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// cacheFileName is the name of the status cache file inside the state directory.
+	cacheFileName = "cache.json"
+
+	// cacheTTL bounds how long a cached status is trusted. Editing a tracked file
+	// does not touch the index until git next refreshes it, so the key alone
+	// cannot detect every change.
+	cacheTTL = 10 * time.Minute
+)
+
+// cacheKey identifies the repository state a cached status was computed for.
+// Any commit, checkout, staging operation or fetch changes the key.
+type cacheKey struct {
+	Head         string    `json:"head"`
+	IndexModTime time.Time `json:"index_mod_time"`
+	FetchModTime time.Time `json:"fetch_mod_time"`
+}
+
+// cachedStatus holds the expensive-to-compute fields of a worktree.
+type cachedStatus struct {
+	IsClean    bool      `json:"is_clean"`
+	Added      int       `json:"added"`
+	Modified   int       `json:"modified"`
+	Deleted    int       `json:"deleted"`
+	Upstream   string    `json:"upstream,omitempty"`
+	Ahead      int       `json:"ahead"`
+	Behind     int       `json:"behind"`
+	LastCommit string    `json:"last_commit"`
+	CommitTime time.Time `json:"commit_time"`
+
+	LastActivity time.Time `json:"last_activity,omitzero"`
+}
+
+// cacheEntry is a cached status together with the key it is valid for.
+type cacheEntry struct {
+	Key      cacheKey     `json:"key"`
+	StoredAt time.Time    `json:"stored_at"`
+	Status   cachedStatus `json:"status"`
+}
+
+// statusCache caches worktree status on disk. It is safe for concurrent use,
+// and writes are atomic so that concurrent giwo processes never see a torn file.
+type statusCache struct {
+	mu       sync.Mutex
+	path     string
+	entries  map[string]*cacheEntry
+	modified bool
+}
+
+// loadStatusCache reads the cache at path. A missing or corrupt file yields an empty cache.
+func loadStatusCache(path string) *statusCache {
+	c := &statusCache{path: path, entries: make(map[string]*cacheEntry)}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &c.entries); err != nil {
+			c.entries = make(map[string]*cacheEntry)
+		}
+	}
+	return c
+}
+
+// get returns the cached status of the worktree at path if it is still valid for key.
+func (c *statusCache) get(path string, key cacheKey) (cachedStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || !entry.Key.equal(key) || time.Since(entry.StoredAt) > cacheTTL {
+		return cachedStatus{}, false
+	}
+	return entry.Status, true
+}
+
+// peek returns the last cached status of the worktree at path, however stale.
+func (c *statusCache) peek(path string) (cachedStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok {
+		return cachedStatus{}, false
+	}
+	return entry.Status, true
+}
+
+// put stores the status of the worktree at path.
+func (c *statusCache) put(path string, key cacheKey, status cachedStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[path] = &cacheEntry{Key: key, StoredAt: time.Now(), Status: status}
+	c.modified = true
+}
+
+// retain drops entries of worktrees that no longer exist.
+func (c *statusCache) retain(paths map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path := range c.entries {
+		if !paths[path] {
+			delete(c.entries, path)
+			c.modified = true
+		}
+	}
+}
+
+// save writes the cache to disk if it changed.
+func (c *statusCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.modified {
+		return nil
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), cacheFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+
+	c.modified = false
+	return nil
+}
+
+// equal reports whether two keys describe the same repository state.
+func (k cacheKey) equal(other cacheKey) bool {
+	return k.Head == other.Head && k.IndexModTime.Equal(other.IndexModTime) && k.FetchModTime.Equal(other.FetchModTime)
+}
+
+// statusCacheKey computes the cache key of a worktree without running git.
+func (m *Manager) statusCacheKey(wt *Worktree) cacheKey {
+	key := cacheKey{Head: wt.Head}
+
+	if info, err := os.Stat(filepath.Join(worktreeGitDir(wt.Path), "index")); err == nil {
+		key.IndexModTime = info.ModTime()
+	}
+	if info, err := os.Stat(filepath.Join(m.gitCommonDir, "FETCH_HEAD")); err == nil {
+		key.FetchModTime = info.ModTime()
+	}
+	return key
+}
+
+// statusFromWorktree extracts the cacheable fields of a worktree.
+func statusFromWorktree(wt *Worktree) cachedStatus {
+	return cachedStatus{
+		IsClean:    wt.IsClean,
+		Added:      wt.Added,
+		Modified:   wt.Modified,
+		Deleted:    wt.Deleted,
+		Upstream:   wt.Upstream,
+		Ahead:      wt.Ahead,
+		Behind:     wt.Behind,
+		LastCommit: wt.LastCommit,
+		CommitTime: wt.CommitTime,
+
+		LastActivity: wt.LastActivity,
+	}
+}
+
+// apply copies a cached status into a worktree.
+func (s cachedStatus) apply(wt *Worktree) {
+	wt.StatusPending = false
+	wt.IsClean = s.IsClean
+	wt.Added = s.Added
+	wt.Modified = s.Modified
+	wt.Deleted = s.Deleted
+	wt.Upstream = s.Upstream
+	wt.Ahead = s.Ahead
+	wt.Behind = s.Behind
+	wt.LastCommit = s.LastCommit
+	wt.CommitTime = s.CommitTime
+	if !s.CommitTime.IsZero() {
+		wt.CommitAge = formatTimeAgo(s.CommitTime)
+	}
+	wt.LastActivity = s.LastActivity
+	if !s.LastActivity.IsZero() {
+		wt.ActivityAge = formatTimeAgo(s.LastActivity)
+	}
+}
+
+// worktreeGitDir returns the git directory of the worktree at path.
+// Linked worktrees have a .git file pointing to their directory below the common git dir.
+func worktreeGitDir(path string) string {
+	dotGit := filepath.Join(path, ".git")
+
+	info, err := os.Stat(dotGit)
+	if err != nil || info.IsDir() {
+		return dotGit
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit
+	}
+
+	dir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(data)), "gitdir:"))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return filepath.Clean(dir)
+}
@@ -0,0 +1,150 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStatusCache(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "giwo", cacheFileName)
+	indexTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := cacheKey{Head: "1111", IndexModTime: indexTime}
+	status := cachedStatus{Modified: 2, Ahead: 1, LastCommit: "Fix parser"}
+
+	cache := loadStatusCache(path)
+	cache.put("/repo/.worktree/feature", key, status)
+	if err := cache.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	reloaded := loadStatusCache(path)
+	got, ok := reloaded.get("/repo/.worktree/feature", key)
+	if !ok {
+		t.Fatal("expected cache hit after reload")
+	}
+	if diff := cmp.Diff(status, got); diff != "" {
+		t.Errorf("cached status mismatch (-want +got):\n%s", diff)
+	}
+
+	for name, changed := range map[string]cacheKey{
+		"new commit":    {Head: "2222", IndexModTime: indexTime},
+		"index changed": {Head: "1111", IndexModTime: indexTime.Add(time.Second)},
+		"fetched":       {Head: "1111", IndexModTime: indexTime, FetchModTime: indexTime},
+	} {
+		if _, ok := reloaded.get("/repo/.worktree/feature", changed); ok {
+			t.Errorf("%s: expected cache miss", name)
+		}
+	}
+
+	if _, ok := reloaded.peek("/repo/.worktree/feature"); !ok {
+		t.Error("expected peek to ignore the key")
+	}
+
+	reloaded.retain(map[string]bool{})
+	if _, ok := reloaded.peek("/repo/.worktree/feature"); ok {
+		t.Error("expected retain to drop removed worktrees")
+	}
+}
+
+func TestStatusCacheExpires(t *testing.T) {
+	t.Parallel()
+
+	cache := loadStatusCache(filepath.Join(t.TempDir(), cacheFileName))
+	key := cacheKey{Head: "1111"}
+	cache.put("/repo", key, cachedStatus{})
+	cache.entries["/repo"].StoredAt = time.Now().Add(-2 * cacheTTL)
+
+	if _, ok := cache.get("/repo", key); ok {
+		t.Error("expected expired entry to miss")
+	}
+}
+
+func TestStatusCacheConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	cache := loadStatusCache(filepath.Join(t.TempDir(), cacheFileName))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := filepath.Join("/repo", string(rune('a'+i)))
+			cache.put(path, cacheKey{}, cachedStatus{Added: i})
+			cache.get(path, cacheKey{})
+			_ = cache.save()
+		}()
+	}
+	wg.Wait()
+
+	if diff := cmp.Diff(20, len(cache.entries)); diff != "" {
+		t.Errorf("entry count mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWorktreeGitDir(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	mainPath := filepath.Join(root, "repo")
+	linkedPath := filepath.Join(root, "repo", ".worktree", "feature")
+	if err := os.MkdirAll(filepath.Join(mainPath, ".git", "worktrees", "feature"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(linkedPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gitdir := filepath.Join(mainPath, ".git", "worktrees", "feature")
+	if err := os.WriteFile(filepath.Join(linkedPath, ".git"), []byte("gitdir: "+gitdir+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(filepath.Join(mainPath, ".git"), worktreeGitDir(mainPath)); diff != "" {
+		t.Errorf("main worktree git dir mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(gitdir, worktreeGitDir(linkedPath)); diff != "" {
+		t.Errorf("linked worktree git dir mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadStatusFromCache(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{gitCommonDir: t.TempDir()}
+	var worktrees []*Worktree
+	cache := loadStatusCache(filepath.Join(m.StateDir(), cacheFileName))
+	for i := range 8 {
+		wt := &Worktree{Path: filepath.Join(t.TempDir(), "wt"), Head: "1111", StatusPending: true}
+		worktrees = append(worktrees, wt)
+		cache.put(wt.Path, m.statusCacheKey(wt), cachedStatus{IsClean: i%2 == 0, Ahead: i})
+	}
+	if err := cache.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	got := make(map[int]int)
+	m.LoadStatus(context.Background(), worktrees, 3, func(i int, status *Worktree) {
+		if status.StatusPending {
+			t.Errorf("status of worktree %d still pending", i)
+		}
+		got[i] = status.Ahead
+	})
+
+	expected := map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("loaded status mismatch (-want +got):\n%s", diff)
+	}
+	for i, wt := range worktrees {
+		if !wt.StatusPending || wt.Ahead != 0 {
+			t.Errorf("LoadStatus modified worktree %d: %+v", i, wt)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package worktree
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CheckoutOptions configures what a new worktree gets besides the files of its
+// commit, which git worktree add leaves out.
+type CheckoutOptions struct {
+	// Submodules initializes and checks out the submodules of a new worktree,
+	// recursively, if it has any.
+	Submodules bool
+
+	// LFS downloads the Git LFS objects of a new worktree if it uses Git LFS.
+	LFS bool
+
+	// Hooks makes the git hooks of a relative core.hooksPath, which git looks
+	// up in each worktree, run in new worktrees too. See SyncHooks.
+	Hooks bool
+
+	// Sparse, if set, limits new worktrees to these directories, relative to
+	// the repository root, with a cone-mode sparse checkout. Files directly in
+	// the repository root are always checked out.
+	Sparse []string
+}
+
+// SetCheckoutOptions sets what new worktrees get besides the files of their
+// commit. Without it, submodules, LFS objects and hooks are left to the user.
+func (m *Manager) SetCheckoutOptions(opts CheckoutOptions) {
+	m.checkout = opts
+}
+
+// completeCheckout checks out the submodules and LFS objects of the new
+// worktree at path as the checkout options say. Offline, submodules are
+// checked out from what was fetched before and LFS files from the local
+// LFS store.
+func (m *Manager) completeCheckout(ctx context.Context, path string) error {
+	if m.checkout.Submodules && hasSubmodules(path) {
+		done := m.step("Checking out submodules")
+		err := m.runGitStep(ctx, path, submoduleUpdateArgs(m.offline, m.progress != nil))
+		done(err)
+		if err != nil {
+			return fmt.Errorf("failed to check out submodules: %w", err)
+		}
+	}
+
+	if m.checkout.LFS && usesLFS(path) {
+		if _, err := exec.LookPath("git-lfs"); err != nil {
+			return fmt.Errorf("the worktree uses Git LFS but git-lfs is not installed; install it from https://git-lfs.com and run 'git lfs pull' in %s", path)
+		}
+		done := m.step("Downloading LFS objects")
+		err := m.runGitStep(ctx, path, lfsArgs(m.offline))
+		done(err)
+		if err != nil {
+			return fmt.Errorf("failed to download LFS objects: %w", err)
+		}
+	}
+	return nil
+}
+
+// sparseCheckout limits the worktree at path, created without a checkout, to
+// the sparse directories of the checkout options. Directories that its commit
+// does not have are reported, as they are most likely misspelled.
+func (m *Manager) sparseCheckout(ctx context.Context, path string) error {
+	if _, err := m.gitOutput(ctx, path, sparseArgs(m.checkout.Sparse)...); err != nil {
+		return fmt.Errorf("failed to set up the sparse checkout: %w", err)
+	}
+	for _, dir := range m.checkout.Sparse {
+		if _, err := m.gitOutput(ctx, path, "rev-parse", "--verify", "--quiet", "HEAD:"+dir); err != nil {
+			fmt.Printf("⚠️  Warning: sparse directory %s does not exist in the checked-out commit\n", dir)
+		}
+	}
+	return nil
+}
+
+// sparseArgs returns the arguments of the git command limiting a worktree to dirs.
+func sparseArgs(dirs []string) []string {
+	return append([]string{"sparse-checkout", "set", "--cone", "--"}, dirs...)
+}
+
+// runGitStep runs git with args in dir, reporting progress if a progress
+// function is set.
+func (m *Manager) runGitStep(ctx context.Context, dir string, args []string) error {
+	if m.progress != nil {
+		return m.runGitProgress(ctx, dir, args...)
+	}
+	_, err := m.gitOutput(ctx, dir, args...)
+	return err
+}
+
+// submoduleUpdateArgs returns the arguments of the git command checking out
+// the submodules of a worktree. Offline, nothing is fetched.
+func submoduleUpdateArgs(offline, progress bool) []string {
+	args := []string{"submodule", "update", "--init", "--recursive"}
+	if offline {
+		args = append(args, "--no-fetch")
+	}
+	if progress {
+		args = append(args, "--progress")
+	}
+	return args
+}
+
+// lfsArgs returns the arguments of the git command filling in the LFS files of
+// a worktree: pulled from the remote, or offline from the local LFS store.
+func lfsArgs(offline bool) []string {
+	if offline {
+		return []string{"lfs", "checkout"}
+	}
+	return []string{"lfs", "pull"}
+}
+
+// hasSubmodules reports whether the worktree at path declares submodules.
+func hasSubmodules(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".gitmodules"))
+	return err == nil
+}
+
+// usesLFS reports whether the .gitattributes file of the worktree at path
+// puts any files in Git LFS.
+func usesLFS(path string) bool {
+	f, err := os.Open(filepath.Join(path, ".gitattributes"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if lfsAttributeLine(scanner.Text()) {
+			return true
+		}
+	}
+	return false
+}
+
+// lfsAttributeLine reports whether a .gitattributes line sets the LFS filter.
+func lfsAttributeLine(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+		return false
+	}
+	for _, attr := range fields[1:] {
+		if attr == "filter=lfs" {
+			return true
+		}
+	}
+	return false
+}
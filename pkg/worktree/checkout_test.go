@@ -0,0 +1,90 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUsesLFS(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		attributes string
+		missing    bool
+		want       bool
+	}{
+		"tracked patterns": {
+			attributes: "*.psd filter=lfs diff=lfs merge=lfs -text\n",
+			want:       true,
+		},
+		"among other attributes": {
+			attributes: "*.go text eol=lf\n\nassets/** filter=lfs diff=lfs merge=lfs -text\n",
+			want:       true,
+		},
+		"commented out": {
+			attributes: "# *.psd filter=lfs diff=lfs merge=lfs -text\n",
+			want:       false,
+		},
+		"other filter": {
+			attributes: "*.secret filter=git-crypt diff=git-crypt\n",
+			want:       false,
+		},
+		"no gitattributes": {
+			missing: true,
+			want:    false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if !tt.missing {
+				if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte(tt.attributes), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(tt.want, usesLFS(dir)); diff != "" {
+				t.Errorf("usesLFS() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckoutArgs(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		offline, progress bool
+		wantSubmodules    []string
+		wantLFS           []string
+	}{
+		"online": {
+			wantSubmodules: []string{"submodule", "update", "--init", "--recursive"},
+			wantLFS:        []string{"lfs", "pull"},
+		},
+		"online with progress": {
+			progress:       true,
+			wantSubmodules: []string{"submodule", "update", "--init", "--recursive", "--progress"},
+			wantLFS:        []string{"lfs", "pull"},
+		},
+		"offline": {
+			offline:        true,
+			wantSubmodules: []string{"submodule", "update", "--init", "--recursive", "--no-fetch"},
+			wantLFS:        []string{"lfs", "checkout"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.wantSubmodules, submoduleUpdateArgs(tt.offline, tt.progress)); diff != "" {
+				t.Errorf("submoduleUpdateArgs() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantLFS, lfsArgs(tt.offline)); diff != "" {
+				t.Errorf("lfsArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BranchCollision describes an existing branch that a new branch of the same
+// name would collide with.
+type BranchCollision struct {
+	// Branch is the name that is taken.
+	Branch string
+
+	// Remote is set if the branch only exists on origin, as last fetched.
+	Remote bool
+
+	// Owner is the author email of the branch's last commit.
+	Owner string
+}
+
+// OwnedBy reports whether the branch's last commit was authored with email,
+// in which case the branch is most likely the user's own.
+func (c *BranchCollision) OwnedBy(email string) bool {
+	return email != "" && strings.EqualFold(c.Owner, email)
+}
+
+// CheckBranchCollision looks for a local branch or a branch on origin named
+// branch. It returns nil if the name is free. Remote branches are known as of
+// the last fetch.
+func (m *Manager) CheckBranchCollision(ctx context.Context, branch string) (*BranchCollision, error) {
+	local, remote := "refs/heads/"+branch, "refs/remotes/origin/"+branch
+	output, err := m.gitOutput(ctx, m.repoRoot, "for-each-ref", "--format=%(refname)%00%(authoremail)", local, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	var collision *BranchCollision
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		ref, email, _ := strings.Cut(line, "\x00")
+		// for-each-ref also matches refs below the pattern, such as refs/heads/<branch>/x
+		if ref != local && ref != remote {
+			continue
+		}
+		owner := strings.Trim(email, "<>")
+		if ref == local {
+			return &BranchCollision{Branch: branch, Owner: owner}, nil
+		}
+		collision = &BranchCollision{Branch: branch, Remote: true, Owner: owner}
+	}
+	return collision, nil
+}
+
+// BranchAlternatives returns names for branch in namespace, such as
+// "knwoop/feature-x", that are neither taken locally nor on origin.
+func (m *Manager) BranchAlternatives(ctx context.Context, branch, namespace string) ([]string, error) {
+	var free []string
+	for _, name := range namespacedBranches(branch, namespace) {
+		collision, err := m.CheckBranchCollision(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if collision == nil {
+			free = append(free, name)
+		}
+	}
+	return free, nil
+}
+
+// namespacedBranches returns the namespaced variants of branch, the prefixed
+// form first.
+func namespacedBranches(branch, namespace string) []string {
+	if namespace == "" {
+		return nil
+	}
+	if strings.HasPrefix(branch, namespace+"/") {
+		// Already namespaced; only a numbered variant is left
+		return []string{branch + "-2"}
+	}
+	return []string{namespace + "/" + branch, branch + "-" + namespace}
+}
+
+// NamespaceFromEmail derives a branch namespace from a git email address: the
+// GitHub user of a noreply address such as 1234+knwoop@users.noreply.github.com,
+// and the lowercased local part otherwise. It returns "" if nothing usable is left.
+func NamespaceFromEmail(email string) string {
+	local, _, _ := strings.Cut(strings.Trim(email, "<>"), "@")
+	if _, user, ok := strings.Cut(local, "+"); ok && strings.HasSuffix(email, "users.noreply.github.com") {
+		local = user
+	} else {
+		local, _, _ = strings.Cut(local, "+")
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(local) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == '.':
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-_")
+}
+
+// UserEmail returns the email git records commits in the repository with, or
+// "" if none is configured.
+func (m *Manager) UserEmail(ctx context.Context) string {
+	output, err := m.gitOutput(ctx, m.repoRoot, "config", "user.email")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// recordHeadRef records that the worktree at path publishes its work as branch
+// headRef on origin, for pull requests.
+func (m *Manager) recordHeadRef(path, headRef string) error {
+	if err := m.updateMetadata(path, func(meta *Metadata) { meta.HeadRef = headRef }); err != nil {
+		return fmt.Errorf("failed to record head ref: %w", err)
+	}
+	return nil
+}
+
+// CheckedOut returns the worktree that has branch checked out, including the
+// main worktree, or nil if none has. Git checks a branch out in one worktree
+// only, unless forced.
+func (m *Manager) CheckedOut(ctx context.Context, branch string) (*Worktree, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return checkedOutIn(worktrees, branch), nil
+}
+
+// checkedOutIn returns the first of worktrees that has branch checked out, or nil.
+func checkedOutIn(worktrees []*Worktree, branch string) *Worktree {
+	for _, wt := range worktrees {
+		if wt.Branch == branch && !wt.Detached {
+			return wt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNamespacedBranches(t *testing.T) {
+	for name, tt := range map[string]struct {
+		branch    string
+		namespace string
+		expected  []string
+	}{
+		"plain":            {branch: "feature-x", namespace: "knwoop", expected: []string{"knwoop/feature-x", "feature-x-knwoop"}},
+		"with slash":       {branch: "fix/login", namespace: "knwoop", expected: []string{"knwoop/fix/login", "fix/login-knwoop"}},
+		"already prefixed": {branch: "knwoop/feature-x", namespace: "knwoop", expected: []string{"knwoop/feature-x-2"}},
+		"no namespace":     {branch: "feature-x"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, namespacedBranches(tt.branch, tt.namespace)); diff != "" {
+				t.Errorf("namespacedBranches(%q, %q) mismatch (-want +got):\n%s", tt.branch, tt.namespace, diff)
+			}
+		})
+	}
+}
+
+func TestNamespaceFromEmail(t *testing.T) {
+	for name, tt := range map[string]struct {
+		email    string
+		expected string
+	}{
+		"plain":           {email: "knwoop@example.com", expected: "knwoop"},
+		"dotted":          {email: "Ken.Woop@example.com", expected: "ken-woop"},
+		"subaddress":      {email: "knwoop+git@example.com", expected: "knwoop"},
+		"github noreply":  {email: "1234+knwoop@users.noreply.github.com", expected: "knwoop"},
+		"angle brackets":  {email: "<knwoop@example.com>", expected: "knwoop"},
+		"nothing usable":  {email: "@example.com"},
+		"only separators": {email: "...@example.com"},
+		"empty":           {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, NamespaceFromEmail(tt.email)); diff != "" {
+				t.Errorf("NamespaceFromEmail(%q) mismatch (-want +got):\n%s", tt.email, diff)
+			}
+		})
+	}
+}
+
+func TestBranchCollisionOwnedBy(t *testing.T) {
+	for name, tt := range map[string]struct {
+		owner    string
+		email    string
+		expected bool
+	}{
+		"same":          {owner: "knwoop@example.com", email: "knwoop@example.com", expected: true},
+		"case differs":  {owner: "Knwoop@Example.com", email: "knwoop@example.com", expected: true},
+		"someone else":  {owner: "alice@example.com", email: "knwoop@example.com"},
+		"unknown email": {owner: "alice@example.com"},
+		"both empty":    {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			collision := &BranchCollision{Branch: "feature-x", Owner: tt.owner}
+			if diff := cmp.Diff(tt.expected, collision.OwnedBy(tt.email)); diff != "" {
+				t.Errorf("OwnedBy(%q) mismatch (-want +got):\n%s", tt.email, diff)
+			}
+		})
+	}
+}
+
+func TestCheckedOutIn(t *testing.T) {
+	worktrees := []*Worktree{
+		{Path: "/repo", Branch: "main", IsMain: true},
+		{Path: "/repo/.worktree/feature-x", Branch: "feature-x"},
+		{Path: "/repo/.worktree/bisect", Branch: "fix", Detached: true},
+	}
+
+	for name, tt := range map[string]struct {
+		branch   string
+		expected string
+	}{
+		"main worktree":    {branch: "main", expected: "/repo"},
+		"linked worktree":  {branch: "feature-x", expected: "/repo/.worktree/feature-x"},
+		"detached":         {branch: "fix"},
+		"not checked out":  {branch: "feature-y"},
+		"prefix of branch": {branch: "feature"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ""
+			if wt := checkedOutIn(worktrees, tt.branch); wt != nil {
+				got = wt.Path
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("checkedOutIn(%q) mismatch (-want +got):\n%s", tt.branch, diff)
+			}
+		})
+	}
+}
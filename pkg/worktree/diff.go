@@ -0,0 +1,133 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// DiffSummary describes how a worktree's branch differs from its base.
+type DiffSummary struct {
+	// Base is the ref the worktree was compared against.
+	Base string `json:"base"`
+
+	// Stat is the diffstat of the changes since the branch left the base.
+	Stat string `json:"stat"`
+
+	// Commits are the one-line summaries of the commits not on the base, newest first.
+	Commits []string `json:"commits"`
+
+	// Files are the paths changed since the branch left the base, relative to the repository root.
+	Files []string `json:"files"`
+}
+
+// DiffSummary returns the diffstat and commit list of a worktree versus its recorded base.
+// Worktrees without a recorded base are compared against the default branch.
+func (m *Manager) DiffSummary(ctx context.Context, wt *Worktree) (*DiffSummary, error) {
+	base, err := m.ResolveBase(ctx, wt)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := m.gitOutput(ctx, wt.Path, "diff", "--stat", base+"...HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := m.gitOutput(ctx, wt.Path, "log", "--oneline", "--no-decorate", base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := m.gitOutput(ctx, wt.Path, "diff", "--name-only", base+"...HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &DiffSummary{
+		Base: base,
+		Stat: strings.TrimRight(stat, "\n"),
+	}
+	for _, line := range strings.Split(log, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			summary.Commits = append(summary.Commits, line)
+		}
+	}
+	for _, line := range strings.Split(files, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			summary.Files = append(summary.Files, line)
+		}
+	}
+
+	return summary, nil
+}
+
+// ResolveBase returns the ref a worktree should be compared against.
+// A recorded base branch is preferred in its remote-tracking form, since that is
+// what Create branches from; otherwise the default branch is used.
+func (m *Manager) ResolveBase(ctx context.Context, wt *Worktree) (string, error) {
+	var candidates []string
+	if wt.Base != "" {
+		candidates = append(candidates, "origin/"+wt.Base, wt.Base)
+	} else {
+		candidates = append(candidates, "origin/main", "origin/master", "main", "master")
+	}
+
+	for _, ref := range candidates {
+		if m.refExists(ctx, ref) {
+			return ref, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: cannot resolve base for %s", errors.ErrBranchNotFound, wt.Path)
+}
+
+// errNotCommit records lookups of refs that do not resolve to a commit.
+var errNotCommit = stderrors.New("not a commit")
+
+// refExists reports whether ref resolves to a commit.
+func (m *Manager) refExists(ctx context.Context, ref string) bool {
+	done := m.timeGit(m.repoRoot, "rev-parse", "--verify", ref)
+	_, ok := m.git().ResolveCommit(ctx, m.repoRoot, ref)
+	if !ok {
+		done(errNotCommit)
+		return false
+	}
+	done(nil)
+	return true
+}
+
+// gitOutput runs a git command in dir and returns its standard output.
+func (m *Manager) gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	var output bytes.Buffer
+	if err := m.runGit(ctx, GitCommand{Dir: dir, Args: args, Stdout: &output}); err != nil {
+		return "", errors.NewGitError(args[0], args[1:], err)
+	}
+	return output.String(), nil
+}
+
+// ComparableRevision returns a revision representing the worktree for comparisons.
+// With workingTree set, uncommitted changes to tracked files are included by
+// snapshotting them with 'git stash create', which leaves the worktree untouched.
+// Otherwise, or if there are no such changes, the worktree's HEAD commit is returned.
+func (m *Manager) ComparableRevision(ctx context.Context, wt *Worktree, workingTree bool) (string, error) {
+	if workingTree {
+		snapshot, err := m.gitOutput(ctx, wt.Path, "stash", "create")
+		if err != nil {
+			return "", err
+		}
+		if snapshot = strings.TrimSpace(snapshot); snapshot != "" {
+			return snapshot, nil
+		}
+	}
+
+	head, err := m.gitOutput(ctx, wt.Path, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(head), nil
+}
@@ -0,0 +1,22 @@
+// Package worktree provides functionality for managing Git worktrees.
+//
+// It is the library behind the giwo command and can be embedded in other
+// programs. A Manager operates on one repository:
+//
+//	manager, err := worktree.NewAt(repoDir)
+//	if err != nil {
+//		return err
+//	}
+//	if err := manager.Create(ctx, worktree.CreateOptions{Branch: "feature-x", Base: "main"}); err != nil {
+//		return err
+//	}
+//	worktrees, err := manager.List(ctx, worktree.ListOptions{WithStatus: true})
+//
+// Every operation takes a context, which cancels the git commands it runs.
+// Failures can be told apart with errors.Is and the Err variables, such as
+// ErrWorktreeExists or ErrDirty.
+//
+// Behavior the giwo command reads from its configuration files, like where
+// worktrees are created or what they are set up with, is configured with the
+// Manager's Set methods, and Subscribe reports what the Manager does.
+package worktree
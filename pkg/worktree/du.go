@@ -0,0 +1,85 @@
+package worktree
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// MeasureDiskUsage sets the DiskUsage of each worktree, measuring them concurrently.
+// Everything in a worktree is counted, including ignored files such as build
+// artifacts, except worktrees nested in it and its .git, whose object store is
+// shared by all worktrees; see GitDirSize. Symlinks are not followed, so shared
+// assets linked into worktrees are not counted.
+func MeasureDiskUsage(ctx context.Context, worktrees []*Worktree) error {
+	nested := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		nested[wt.Path] = true
+	}
+
+	errs := make([]error, len(worktrees))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			wt.DiskUsage, errs[i] = dirSize(ctx, wt.Path, func(path string) bool {
+				return (path != wt.Path && nested[path]) || path == filepath.Join(wt.Path, ".git")
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GitDirSize returns the size of the repository's common git directory, which
+// holds the objects shared by all worktrees.
+func (m *Manager) GitDirSize(ctx context.Context) (int64, error) {
+	return dirSize(ctx, m.gitCommonDir, func(string) bool { return false })
+}
+
+// dirSize returns the total size of the files under dir, skipping the
+// directories for which skip returns true. Files that disappear while walking
+// are ignored, as are directories that cannot be read.
+func dirSize(ctx context.Context, dir string, skip func(string) bool) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if skip(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,57 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMeasureDiskUsage(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	files := map[string]int{
+		"main.go":                       100,
+		"build/app":                     1000,
+		".git/objects/pack/pack-1.pack": 5000,
+		".worktree/feature/main.go":     200,
+		".worktree/feature/.git":        30,
+		".worktree/feature/dist/app.js": 400,
+	}
+	for name, size := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Shared assets are linked, not copied, and are not counted again
+	if err := os.Symlink(filepath.Join(root, "build"), filepath.Join(root, ".worktree/feature/build")); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees := []*Worktree{
+		{Path: root},
+		{Path: filepath.Join(root, ".worktree/feature")},
+		{Path: filepath.Join(root, ".worktree/gone")},
+	}
+	if err := MeasureDiskUsage(context.Background(), worktrees); err != nil {
+		t.Fatalf("MeasureDiskUsage failed: %v", err)
+	}
+
+	symlink := int64(len(filepath.Join(root, "build")))
+	expected := []int64{1100, 630 + symlink, 0}
+	var got []int64
+	for _, wt := range worktrees {
+		got = append(got, wt.DiskUsage)
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("disk usage mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,78 @@
+package worktree
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// EnvData describes a worktree to the templates of user-defined environment variables,
+// e.g. "postgres://localhost/app_{{.Name}}".
+type EnvData struct {
+	// Path is the worktree's path, and Name its directory name.
+	Path string
+	Name string
+
+	// Branch is empty for worktrees with a detached HEAD.
+	Branch string
+
+	// RepoRoot is the path of the main worktree, and Repo its directory name.
+	RepoRoot string
+	Repo     string
+
+	// PortOffset is the worktree's port offset; see Manager.PortOffset.
+	PortOffset int
+}
+
+// Port returns base shifted by the worktree's port offset, e.g. {{.Port 3000}}.
+func (d EnvData) Port(base int) int {
+	return base + d.PortOffset
+}
+
+// NewEnvData returns the template data for wt in the repository whose main worktree is at repoRoot.
+func NewEnvData(wt *Worktree, repoRoot string, portOffset int) EnvData {
+	return EnvData{
+		Path:       wt.Path,
+		Name:       filepath.Base(wt.Path),
+		Branch:     wt.Branch,
+		RepoRoot:   repoRoot,
+		Repo:       filepath.Base(repoRoot),
+		PortOffset: portOffset,
+	}
+}
+
+// Env returns the environment variables describing a worktree: GIWO_WORKTREE_PATH,
+// GIWO_BRANCH, GIWO_REPO, GIWO_REPO_ROOT and GIWO_PORT_OFFSET, followed by vars sorted by name,
+// whose values are executed as templates with data.
+func Env(data EnvData, vars map[string]string) ([][2]string, error) {
+	env := [][2]string{
+		{"GIWO_WORKTREE_PATH", data.Path},
+		{"GIWO_BRANCH", data.Branch},
+		{"GIWO_REPO", data.Repo},
+		{"GIWO_REPO_ROOT", data.RepoRoot},
+		{"GIWO_PORT_OFFSET", strconv.Itoa(data.PortOffset)},
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(vars[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", name, err)
+		}
+		var value strings.Builder
+		if err := tmpl.Execute(&value, data); err != nil {
+			return nil, fmt.Errorf("failed to expand %s: %w", name, err)
+		}
+		env = append(env, [2]string{name, value.String()})
+	}
+
+	return env, nil
+}
@@ -0,0 +1,69 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEnv(t *testing.T) {
+	data := NewEnvData(&Worktree{Branch: "feature/auth", Path: "/src/app/.worktree/feature-auth"}, "/src/app", 30)
+	builtin := [][2]string{
+		{"GIWO_WORKTREE_PATH", "/src/app/.worktree/feature-auth"},
+		{"GIWO_BRANCH", "feature/auth"},
+		{"GIWO_REPO", "app"},
+		{"GIWO_REPO_ROOT", "/src/app"},
+		{"GIWO_PORT_OFFSET", "30"},
+	}
+
+	for name, tt := range map[string]struct {
+		vars      map[string]string
+		expected  [][2]string
+		wantError bool
+	}{
+		"built-in only": {
+			expected: builtin,
+		},
+		"templates sorted by name": {
+			vars: map[string]string{
+				"DATABASE_URL":         "postgres://localhost/{{.Repo}}_{{.Name}}",
+				"COMPOSE_PROJECT_NAME": "{{.Repo}}-{{.Name}}",
+				"PORT":                 "{{.Port 3000}}",
+				"STATIC":               "value",
+			},
+			expected: append(builtin[:len(builtin):len(builtin)],
+				[2]string{"COMPOSE_PROJECT_NAME", "app-feature-auth"},
+				[2]string{"DATABASE_URL", "postgres://localhost/app_feature-auth"},
+				[2]string{"PORT", "3030"},
+				[2]string{"STATIC", "value"},
+			),
+		},
+		"invalid template": {
+			vars:      map[string]string{"BROKEN": "{{.Name"},
+			wantError: true,
+		},
+		"unknown field": {
+			vars:      map[string]string{"BROKEN": "{{.Port}}"},
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := Env(data, tt.vars)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Env expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Env unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, env); diff != "" {
+				t.Errorf("Env mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
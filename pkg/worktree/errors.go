@@ -0,0 +1,61 @@
+package worktree
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Errors returned by Manager operations, wrapped with details such as the path
+// of the worktree. Check for them with errors.Is.
+var (
+	ErrNotGitRepository  = errors.ErrNotGitRepository
+	ErrWorktreeExists    = errors.ErrWorktreeExists
+	ErrWorktreeNotFound  = errors.ErrWorktreeNotFound
+	ErrWorktreeLocked    = errors.ErrWorktreeLocked
+	ErrWorktreeAmbiguous = errors.ErrWorktreeAmbiguous
+	ErrBranchExists      = errors.ErrBranchExists
+	ErrBranchNotFound    = errors.ErrBranchNotFound
+	ErrBranchCheckedOut  = errors.ErrBranchCheckedOut
+	ErrDetachedHead      = errors.ErrDetachedHead
+	ErrArchiveNotFound   = errors.ErrArchiveNotFound
+	ErrArchiveExists     = errors.ErrArchiveExists
+	ErrDirty             = errors.ErrWorktreeDirty
+	ErrCancelled         = errors.ErrOperationCancelled
+)
+
+// BranchCheckedOutError is returned with ErrBranchCheckedOut and tells where
+// the branch is checked out.
+type BranchCheckedOutError = errors.BranchCheckedOutError
+
+// Messages of 'git worktree add' that have a typed error.
+var (
+	checkedOutPattern    = regexp.MustCompile(`'([^']+)' is already (?:checked out|used by worktree) at '([^']+)'`)
+	branchExistsPattern  = regexp.MustCompile(`a branch named '([^']+)' already exists`)
+	invalidRefPattern    = regexp.MustCompile(`(?:invalid reference|not a valid object name): '?([^'\s]+)'?`)
+	worktreeExistPattern = regexp.MustCompile(`'([^']+)' already exists`)
+)
+
+// worktreeAddError turns a failure of 'git worktree add' into a typed error when
+// git's message says why, so that callers need not parse it. Other errors are
+// returned unchanged.
+func worktreeAddError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if m := checkedOutPattern.FindStringSubmatch(msg); m != nil {
+		return &BranchCheckedOutError{Branch: m[1], Path: m[2], Err: err}
+	}
+	if m := branchExistsPattern.FindStringSubmatch(msg); m != nil {
+		return fmt.Errorf("%w: %s", errors.ErrBranchExists, m[1])
+	}
+	if m := invalidRefPattern.FindStringSubmatch(msg); m != nil {
+		return fmt.Errorf("%w: %s", errors.ErrBranchNotFound, m[1])
+	}
+	if m := worktreeExistPattern.FindStringSubmatch(msg); m != nil {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, m[1])
+	}
+	return err
+}
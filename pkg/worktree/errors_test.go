@@ -0,0 +1,66 @@
+package worktree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+)
+
+func TestWorktreeAddError(t *testing.T) {
+	for name, tt := range map[string]struct {
+		stderr   string
+		sentinel error
+		expected string
+	}{
+		"checked out": {
+			stderr:   "fatal: 'main' is already checked out at '/repo'",
+			sentinel: ErrBranchCheckedOut,
+			expected: "branch main is already checked out in /repo",
+		},
+		"used by worktree": {
+			stderr:   "fatal: 'feat' is already used by worktree at '/repo/.worktree/feat'",
+			sentinel: ErrBranchCheckedOut,
+			expected: "branch feat is already checked out in /repo/.worktree/feat",
+		},
+		"branch exists": {
+			stderr:   "fatal: a branch named 'feat' already exists",
+			sentinel: ErrBranchExists,
+			expected: "branch already exists: feat",
+		},
+		"invalid reference": {
+			stderr:   "fatal: invalid reference: origin/nope",
+			sentinel: ErrBranchNotFound,
+			expected: "branch not found: origin/nope",
+		},
+		"invalid object name": {
+			stderr:   "Preparing worktree (new branch 'x')\nfatal: not a valid object name: 'nope'",
+			sentinel: ErrBranchNotFound,
+			expected: "branch not found: nope",
+		},
+		"directory exists": {
+			stderr:   "fatal: '/repo/.worktree/feat' already exists",
+			sentinel: ErrWorktreeExists,
+			expected: "worktree already exists: /repo/.worktree/feat",
+		},
+		"other failure": {
+			stderr:   "fatal: not a git repository",
+			expected: "git worktree failed: exit status 128: fatal: not a git repository",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gitErr := giwoerrors.NewGitError("worktree", []string{"add"}, errors.New("exit status 128: "+tt.stderr))
+			err := worktreeAddError(gitErr)
+
+			if diff := cmp.Diff(tt.expected, err.Error()); diff != "" {
+				t.Errorf("Error() mismatch (-want +got):\n%s", diff)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("Expected error to wrap %v", tt.sentinel)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package worktree
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event is published by a Manager when something happens to a worktree.
+// Subscribers tell events apart with a type switch on the concrete types below.
+type Event interface {
+	// EventTime returns when the event happened.
+	EventTime() time.Time
+}
+
+// WorktreeCreated is published after a worktree has been created.
+type WorktreeCreated struct {
+	Time time.Time
+
+	Path string
+
+	// Branch is empty for worktrees with a detached HEAD.
+	Branch string
+
+	// Base is the branch, tag or commit the worktree was created from.
+	Base string
+}
+
+// WorktreeRemoved is published after a worktree has been removed.
+type WorktreeRemoved struct {
+	Time time.Time
+
+	Path   string
+	Branch string
+
+	// BranchDeleted reports whether the branch was deleted along with the worktree.
+	BranchDeleted bool
+}
+
+// SwitchResolved is published when Resolve has determined the worktree to switch to.
+type SwitchResolved struct {
+	Time time.Time
+
+	Filter   string
+	Worktree *Worktree
+}
+
+// HookRan is published after a hook command has run in a worktree.
+type HookRan struct {
+	Time time.Time
+
+	Hook     string
+	Path     string
+	Duration time.Duration
+
+	// Err is the error the hook failed with, if any.
+	Err error
+}
+
+// ConfigReloaded is published by NotifyConfigReloaded after the settings of a
+// long-running manager were reloaded, so that subscribers can refresh what
+// they derived from them.
+type ConfigReloaded struct {
+	Time time.Time
+}
+
+// EventTime implements Event.
+func (e WorktreeCreated) EventTime() time.Time { return e.Time }
+
+// EventTime implements Event.
+func (e WorktreeRemoved) EventTime() time.Time { return e.Time }
+
+// EventTime implements Event.
+func (e SwitchResolved) EventTime() time.Time { return e.Time }
+
+// EventTime implements Event.
+func (e HookRan) EventTime() time.Time { return e.Time }
+
+// EventTime implements Event.
+func (e ConfigReloaded) EventTime() time.Time { return e.Time }
+
+// NotifyConfigReloaded publishes ConfigReloaded. Settings are applied through
+// setters like SetTemplateFunc, so the manager cannot tell by itself when a
+// new configuration has been applied in full.
+func (m *Manager) NotifyConfigReloaded() {
+	m.publish(ConfigReloaded{Time: time.Now()})
+}
+
+// eventBus delivers events to subscribers. Its zero value has no subscribers.
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber queues events for one subscription so that a slow subscriber
+// never blocks the operation publishing them.
+type subscriber struct {
+	mu     sync.Mutex
+	queue  []Event
+	notify chan struct{}
+}
+
+// Subscribe calls fn with every event the manager publishes until ctx is done.
+// Events are delivered in order on a goroutine owned by the subscription, so fn
+// does not need to be safe for concurrent use, and a slow fn does not slow down
+// the manager. Events still queued when ctx is done are dropped.
+func (m *Manager) Subscribe(ctx context.Context, fn func(Event)) {
+	sub := &subscriber{notify: make(chan struct{}, 1)}
+
+	m.events.mu.Lock()
+	if m.events.subscribers == nil {
+		m.events.subscribers = make(map[*subscriber]struct{})
+	}
+	m.events.subscribers[sub] = struct{}{}
+	m.events.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.events.mu.Lock()
+			delete(m.events.subscribers, sub)
+			m.events.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.notify:
+			}
+
+			sub.mu.Lock()
+			queue := sub.queue
+			sub.queue = nil
+			sub.mu.Unlock()
+
+			for _, ev := range queue {
+				if ctx.Err() != nil {
+					return
+				}
+				fn(ev)
+			}
+		}
+	}()
+}
+
+// publish queues ev for every subscriber.
+func (m *Manager) publish(ev Event) {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	for sub := range m.events.subscribers {
+		sub.mu.Lock()
+		sub.queue = append(sub.queue, ev)
+		sub.mu.Unlock()
+
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+			// A wakeup is already pending and will pick up this event
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package worktree
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		received []Event
+		done     = make(chan struct{})
+	)
+	m.Subscribe(ctx, func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, ev)
+		if len(received) == 3 {
+			close(done)
+		}
+	})
+
+	now := time.Now()
+	events := []Event{
+		WorktreeCreated{Time: now, Path: "/repo/.worktree/a", Branch: "a", Base: "main"},
+		SwitchResolved{Time: now, Filter: "a", Worktree: &Worktree{Branch: "a"}},
+		WorktreeRemoved{Time: now, Path: "/repo/.worktree/a", Branch: "a", BranchDeleted: true},
+	}
+	for _, ev := range events {
+		m.publish(ev)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for events")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := cmp.Diff(events, received); diff != "" {
+		t.Errorf("received events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.Subscribe(ctx, func(Event) {})
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.events.mu.Lock()
+		n := len(m.events.subscribers)
+		m.events.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber was not removed after cancellation")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Publishing without subscribers is a no-op
+	m.publish(HookRan{Time: time.Now(), Hook: "post-create"})
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{}
+	m.publish(WorktreeCreated{Time: time.Now()})
+}
@@ -0,0 +1,27 @@
+package worktree_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/knwoop/giwo/pkg/worktree"
+)
+
+func ExampleManager_Remove() {
+	manager, err := worktree.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = manager.Remove(context.Background(), worktree.RemoveOptions{Name: "feature-x"})
+	switch {
+	case errors.Is(err, worktree.ErrDirty):
+		fmt.Println("feature-x has uncommitted changes; set Force to discard them")
+	case errors.Is(err, worktree.ErrWorktreeLocked):
+		fmt.Println("feature-x is locked")
+	case err != nil:
+		log.Fatal(err)
+	}
+}
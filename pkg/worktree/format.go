@@ -0,0 +1,78 @@
+package worktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// FormatData is what custom list formats are executed with: the fields of the
+// worktree, such as {{.Branch}} or {{.Ahead}}, and its name.
+type FormatData struct {
+	*Worktree
+
+	// Name is the worktree's directory relative to the worktree directory.
+	Name string
+}
+
+// formatEscapes are the escape sequences expanded in custom formats, so that
+// formats can be written on the command line in single quotes.
+var formatEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`)
+
+// formatFuncs are the functions available to custom formats.
+var formatFuncs = template.FuncMap{
+	// join joins a list such as .Labels: {{join .Labels ","}}
+	"join": func(elems []string, sep string) string { return strings.Join(elems, sep) },
+	// short abbreviates a commit hash: {{short .Head}}
+	"short": shortHash,
+	// json encodes any value: {{json .Labels}}
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// IsCustomFormat reports whether an output format is a template rather than
+// one of the built-in formats.
+func IsCustomFormat(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// ParseFormat parses a custom format, a Go template over FormatData such as
+// '{{.Branch}}\t{{.Path}}', in the spirit of 'git for-each-ref --format'.
+// \t, \n and \\ are expanded. Unknown fields are reported here rather than
+// halfway through the output.
+func ParseFormat(format string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(formatEscapes.Replace(format))
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	sample := FormatData{Worktree: &Worktree{Remote: &RemoteStatus{}}}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("invalid format: %w", err)
+	}
+	return tmpl, nil
+}
+
+// WriteFormatted writes a line for each worktree, executing tmpl with it.
+func WriteFormatted(w io.Writer, tmpl *template.Template, data []FormatData) error {
+	for _, d := range data {
+		if err := tmpl.Execute(w, d); err != nil {
+			return fmt.Errorf("failed to format %s: %w", d.Path, err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shortHash abbreviates a commit hash to 7 characters.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,101 @@
+package worktree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteFormatted(t *testing.T) {
+	data := []FormatData{
+		{Worktree: &Worktree{Path: "/repo", Branch: "main", Head: "0123456789abcdef", IsMain: true}, Name: "main"},
+		{Worktree: &Worktree{Path: "/repo/.worktree/feat", Branch: "feat", Ahead: 2, Labels: []string{"review", "urgent"},
+			Remote: &RemoteStatus{PRNumber: 42}}, Name: "feat"},
+	}
+
+	for name, tt := range map[string]struct {
+		format   string
+		expected string
+	}{
+		"tab separated": {
+			format:   `{{.Branch}}\t{{.Path}}\t{{.Ahead}}`,
+			expected: "main\t/repo\t0\nfeat\t/repo/.worktree/feat\t2\n",
+		},
+		"name and short head": {
+			format:   "{{.Name}} {{short .Head}}",
+			expected: "main 0123456\nfeat \n",
+		},
+		"labels": {
+			format:   `{{.Branch}}:{{join .Labels ","}}`,
+			expected: "main:\nfeat:review,urgent\n",
+		},
+		"json": {
+			format:   "{{json .Labels}}",
+			expected: "null\n[\"review\",\"urgent\"]\n",
+		},
+		"optional remote": {
+			format:   "{{.Branch}}{{with .Remote}} #{{.PRNumber}}{{end}}",
+			expected: "main\nfeat #42\n",
+		},
+		"conditional": {
+			format:   `{{if .IsMain}}*{{else}} {{end}} {{.Branch}}`,
+			expected: "* main\n  feat\n",
+		},
+		"escaped backslash": {
+			format:   `{{.Branch}}\\n`,
+			expected: "main\\n\nfeat\\n\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := ParseFormat(tt.format)
+			if err != nil {
+				t.Fatalf("ParseFormat(%q) failed: %v", tt.format, err)
+			}
+			var got strings.Builder
+			if err := WriteFormatted(&got, tmpl, data); err != nil {
+				t.Fatalf("WriteFormatted failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got.String()); diff != "" {
+				t.Errorf("WriteFormatted(%q) mismatch (-want +got):\n%s", tt.format, diff)
+			}
+		})
+	}
+}
+
+func TestParseFormatErrors(t *testing.T) {
+	for name, format := range map[string]string{
+		"unknown field":    "{{.Brnach}}",
+		"unknown function": "{{upper .Branch}}",
+		"unclosed action":  "{{.Branch",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ParseFormat(format); err == nil {
+				t.Errorf("ParseFormat(%q) succeeded, want error", format)
+			}
+		})
+	}
+}
+
+func TestIsCustomFormat(t *testing.T) {
+	for name, tt := range map[string]struct {
+		format   string
+		expected bool
+	}{
+		"table":    {format: "table"},
+		"json":     {format: "json"},
+		"template": {format: "{{.Branch}}", expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, IsCustomFormat(tt.format)); diff != "" {
+				t.Errorf("IsCustomFormat(%q) mismatch (-want +got):\n%s", tt.format, diff)
+			}
+		})
+	}
+}
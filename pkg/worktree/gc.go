@@ -0,0 +1,203 @@
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// remoteCacheMaxAge is how long GC keeps the forge information of a branch.
+// Older information is more likely misleading than helpful.
+const remoteCacheMaxAge = 30 * 24 * time.Hour
+
+// GCOptions selects what GC cleans up besides giwo's own state.
+type GCOptions struct {
+	// ArchiveRetention is how long archives are kept; they are kept until
+	// restored if zero.
+	ArchiveRetention time.Duration
+
+	// Maintenance runs 'git maintenance run' on the object store the
+	// worktrees share.
+	Maintenance bool
+
+	// DryRun only reports what would be cleaned up.
+	DryRun bool
+}
+
+// GCReport tells what GC cleaned up, or would clean up in a dry run.
+type GCReport struct {
+	// Pruned are the worktrees git forgets, as their directory is gone.
+	Pruned []string `json:"pruned,omitempty"`
+
+	// Metadata, Labels and Cache count the entries dropped from giwo's state
+	// files for worktrees that no longer exist.
+	Metadata int `json:"metadata"`
+	Labels   int `json:"labels"`
+	Cache    int `json:"cache"`
+
+	// Remote counts the forge information entries older than a month.
+	Remote int `json:"remote"`
+
+	// Archives are those older than the retention.
+	Archives []Archive `json:"archives,omitempty"`
+
+	// Maintenance tells whether 'git maintenance run' ran.
+	Maintenance bool `json:"maintenance"`
+}
+
+// GC prunes worktrees whose directory is gone, as Prune does, and drops what
+// giwo recorded about them: metadata, labels and cached status. It also drops
+// stale forge information, expires archives and, if asked to, runs git's
+// maintenance once for the shared object store, rather than in each worktree.
+func (m *Manager) GC(ctx context.Context, opts GCOptions) (*GCReport, error) {
+	report := &GCReport{}
+
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	alive := gcSurvivors(worktrees, metadata)
+	for _, wt := range worktrees {
+		if !alive[wt.Path] {
+			report.Pruned = append(report.Pruned, wt.Path)
+		}
+	}
+	if !opts.DryRun {
+		if _, _, err := m.Prune(ctx, false); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Metadata = dropDead(metadata, alive)
+	if report.Metadata > 0 && !opts.DryRun {
+		if err := m.saveMetadata(metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	labels, err := m.loadLabels()
+	if err != nil {
+		return nil, err
+	}
+	report.Labels = dropDead(labels, alive)
+	if report.Labels > 0 && !opts.DryRun {
+		if err := m.saveLabels(labels); err != nil {
+			return nil, err
+		}
+	}
+
+	cache := loadStatusCache(filepath.Join(m.StateDir(), cacheFileName))
+	report.Cache = len(cache.entries)
+	cache.retain(alive)
+	report.Cache -= len(cache.entries)
+	if !opts.DryRun {
+		if err := cache.save(); err != nil {
+			return nil, fmt.Errorf("failed to save the status cache: %w", err)
+		}
+	}
+
+	if err := m.expireRemoteCache(report, time.Now(), opts.DryRun); err != nil {
+		return nil, err
+	}
+
+	if opts.ArchiveRetention > 0 {
+		archives, err := m.Archives(ctx)
+		if err != nil {
+			return nil, err
+		}
+		report.Archives = expiredArchives(archives, opts.ArchiveRetention, time.Now())
+		if !opts.DryRun {
+			for _, archive := range report.Archives {
+				if err := m.DeleteArchive(ctx, archive.Name); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if opts.Maintenance && !opts.DryRun {
+		done := m.step("Running git maintenance")
+		err := m.runGit(ctx, GitCommand{Dir: m.repoRoot, Args: []string{"maintenance", "run"}})
+		done(err)
+		if err != nil {
+			return nil, errors.NewGitError("maintenance run", nil, err)
+		}
+		report.Maintenance = true
+	}
+
+	return report, nil
+}
+
+// expireRemoteCache drops the forge information older than remoteCacheMaxAge
+// at now, counting it in report.
+func (m *Manager) expireRemoteCache(report *GCReport, now time.Time, dryRun bool) error {
+	cache, err := m.loadRemoteCache()
+	if err != nil {
+		return err
+	}
+	for head, entry := range cache {
+		if now.Sub(entry.FetchedAt) > remoteCacheMaxAge {
+			delete(cache, head)
+			report.Remote++
+		}
+	}
+	if report.Remote == 0 || dryRun {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the forge cache: %w", err)
+	}
+	return os.WriteFile(filepath.Join(m.StateDir(), remoteCacheFileName), data, 0o644)
+}
+
+// gcSurvivors returns the paths of the worktrees that pruning keeps: those
+// whose directory exists, and locked and maintenance worktrees, which Prune
+// keeps even without one.
+func gcSurvivors(worktrees []*Worktree, metadata metadataStore) map[string]bool {
+	alive := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		meta := metadata[wt.Path]
+		if !wt.Prunable || wt.Locked || (meta != nil && meta.Kind == KindMaintenance) {
+			alive[wt.Path] = true
+		}
+	}
+	return alive
+}
+
+// dropDead deletes the entries of store keyed by paths that are not alive and
+// returns how many it deleted.
+func dropDead[V any](store map[string]V, alive map[string]bool) int {
+	dropped := 0
+	for path := range store {
+		if !alive[path] {
+			delete(store, path)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// expiredArchives returns the archives made more than retention before now,
+// oldest first.
+func expiredArchives(archives []Archive, retention time.Duration, now time.Time) []Archive {
+	var expired []Archive
+	for _, archive := range archives {
+		if now.Sub(archive.Time) > retention {
+			expired = append(expired, archive)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Time.Before(expired[j].Time) })
+	return expired
+}
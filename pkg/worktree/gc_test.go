@@ -0,0 +1,85 @@
+package worktree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGCSurvivors(t *testing.T) {
+	t.Parallel()
+
+	worktrees := []*Worktree{
+		{Path: "/repo"},
+		{Path: "/repo/.worktree/gone", Prunable: true},
+		{Path: "/repo/.worktree/locked", Prunable: true, Locked: true},
+		{Path: "/repo/.worktree/v1.2", Prunable: true},
+		{Path: "/repo/.worktree/feature"},
+	}
+	metadata := metadataStore{
+		"/repo/.worktree/v1.2":    {Kind: KindMaintenance},
+		"/repo/.worktree/feature": {Base: "main"},
+	}
+
+	expected := map[string]bool{
+		"/repo":                   true,
+		"/repo/.worktree/locked":  true,
+		"/repo/.worktree/v1.2":    true,
+		"/repo/.worktree/feature": true,
+	}
+	if diff := cmp.Diff(expected, gcSurvivors(worktrees, metadata)); diff != "" {
+		t.Errorf("gcSurvivors() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDropDead(t *testing.T) {
+	t.Parallel()
+
+	labels := labelStore{
+		"/repo/.worktree/a":    {"review"},
+		"/repo/.worktree/gone": {"wip"},
+	}
+	dropped := dropDead(labels, map[string]bool{"/repo/.worktree/a": true})
+
+	if diff := cmp.Diff(1, dropped); diff != "" {
+		t.Errorf("dropped count mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(labelStore{"/repo/.worktree/a": {"review"}}, labels); diff != "" {
+		t.Errorf("remaining labels mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExpiredArchives(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	archives := []Archive{
+		{Name: "recent", Time: now.Add(-day)},
+		{Name: "old", Time: now.Add(-60 * day)},
+		{Name: "older", Time: now.Add(-120 * day)},
+	}
+
+	for name, tt := range map[string]struct {
+		retention time.Duration
+		want      []string
+	}{
+		"a month":  {retention: 30 * day, want: []string{"older", "old"}},
+		"a year":   {retention: 365 * day, want: nil},
+		"an hour":  {retention: time.Hour, want: []string{"older", "old", "recent"}},
+		"100 days": {retention: 100 * day, want: []string{"older"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, archive := range expiredArchives(archives, tt.retention, now) {
+				got = append(got, archive.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("expiredArchives() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package worktree
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/filesystem/dotgit"
+)
+
+// zeroHash is what git reports as the HEAD of a branch without commits.
+const zeroHash = "0000000000000000000000000000000000000000"
+
+// GoGitBackend reads the worktree list and resolves revisions with go-git
+// instead of starting git, which is slow on platforms where spawning processes
+// is expensive. Everything else, and layouts go-git does not handle such as
+// bare repositories or the reftable ref format, is left to the embedded
+// ExecBackend. go-git does not list linked worktrees, so their administrative
+// files are read directly; their HEAD and refs go through go-git's storage.
+type GoGitBackend struct {
+	ExecBackend
+}
+
+// ListWorktrees implements GitBackend.
+func (b GoGitBackend) ListWorktrees(ctx context.Context, repoRoot, gitCommonDir string) ([]*Worktree, error) {
+	if worktrees, ok := readWorktrees(gitCommonDir); ok {
+		return worktrees, nil
+	}
+	return b.ExecBackend.ListWorktrees(ctx, repoRoot, gitCommonDir)
+}
+
+// ResolveCommit implements GitBackend. Revisions go-git cannot parse or
+// evaluate, and those that may be ambiguous with an abbreviated commit, go to git.
+func (b GoGitBackend) ResolveCommit(ctx context.Context, dir, ref string) (string, bool) {
+	if isHex(ref) {
+		return b.ExecBackend.ResolveCommit(ctx, dir, ref)
+	}
+	gitDir, commonDir, ok := findGitDirs(dir)
+	if !ok {
+		return b.ExecBackend.ResolveCommit(ctx, dir, ref)
+	}
+	repo, err := git.Open(openStorage(gitDir, commonDir), osfs.New(dir))
+	if err != nil {
+		return b.ExecBackend.ResolveCommit(ctx, dir, ref)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	switch {
+	case err == nil:
+		return hash.String(), true
+	case stderrors.Is(err, plumbing.ErrReferenceNotFound) && isPlainRefName(ref):
+		return "", false
+	default:
+		return b.ExecBackend.ResolveCommit(ctx, dir, ref)
+	}
+}
+
+// readWorktrees reads the worktrees of the repository with the git directory
+// gitCommonDir as 'git worktree list' reports them, main worktree first and the
+// others sorted by path. It returns false for layouts it does not handle.
+func readWorktrees(gitCommonDir string) ([]*Worktree, bool) {
+	if filepath.Base(gitCommonDir) != ".git" || exists(filepath.Join(gitCommonDir, "reftable")) {
+		return nil, false
+	}
+
+	main := &Worktree{Path: filepath.Dir(gitCommonDir)}
+	if !readHead(main, gitCommonDir, gitCommonDir) {
+		return nil, false
+	}
+
+	entries, err := os.ReadDir(filepath.Join(gitCommonDir, "worktrees"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false
+	}
+
+	var linked []*Worktree
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		adminDir := filepath.Join(gitCommonDir, "worktrees", entry.Name())
+
+		gitFile, err := readTrimmed(filepath.Join(adminDir, "gitdir"))
+		if err != nil {
+			// git reports these as prunable without a path
+			return nil, false
+		}
+		if !filepath.IsAbs(gitFile) {
+			gitFile = filepath.Join(adminDir, gitFile)
+		}
+
+		wt := &Worktree{Path: filepath.Dir(filepath.Clean(gitFile))}
+		if reason, err := readTrimmed(filepath.Join(adminDir, "locked")); err == nil {
+			wt.Locked = true
+			wt.LockReason = reason
+		}
+		// Locked worktrees are never pruned, so git does not report them as prunable
+		wt.Prunable = !wt.Locked && !exists(gitFile)
+
+		if !readHead(wt, adminDir, gitCommonDir) {
+			return nil, false
+		}
+		linked = append(linked, wt)
+	}
+	sort.Slice(linked, func(i, j int) bool { return linked[i].Path < linked[j].Path })
+
+	return append([]*Worktree{main}, linked...), true
+}
+
+// readHead fills in the branch and HEAD commit of wt from the git directory
+// gitDir of a worktree of the repository whose shared git directory is commonDir.
+func readHead(wt *Worktree, gitDir, commonDir string) bool {
+	s := openStorage(gitDir, commonDir)
+	head, err := s.Reference(plumbing.HEAD)
+	if err != nil {
+		return false
+	}
+
+	if head.Type() == plumbing.HashReference {
+		wt.Head = head.Hash().String()
+		wt.Branch = "HEAD"
+		wt.Detached = true
+		return true
+	}
+
+	wt.Branch = strings.TrimPrefix(head.Target().String(), "refs/heads/")
+	ref, err := storer.ResolveReference(s, head.Target())
+	switch {
+	case stderrors.Is(err, plumbing.ErrReferenceNotFound):
+		// A branch without commits yet
+		wt.Head = zeroHash
+	case err != nil:
+		return false
+	default:
+		wt.Head = ref.Hash().String()
+	}
+	return true
+}
+
+// openStorage returns go-git's storage for the git directory gitDir of a
+// worktree, sharing everything but its own refs with commonDir.
+func openStorage(gitDir, commonDir string) *filesystem.Storage {
+	var fs billy.Filesystem = osfs.New(gitDir)
+	if gitDir != commonDir {
+		fs = worktreeFilesystem{
+			RepositoryFilesystem: dotgit.NewRepositoryFilesystem(fs, osfs.New(commonDir)),
+			gitDir:               fs,
+		}
+	}
+	return filesystem.NewStorage(fs, cache.NewObjectLRUDefault())
+}
+
+// worktreeFilesystem reads the refs git keeps per worktree from its own git
+// directory. go-git's RepositoryFilesystem only does so for the directories
+// holding them, not for the refs inside, so that refs/bisect/bad would be looked
+// up in the shared git directory. The backend only reads, so writes are not routed.
+type worktreeFilesystem struct {
+	*dotgit.RepositoryFilesystem
+	gitDir billy.Filesystem
+}
+
+func (fs worktreeFilesystem) route(name string) billy.Filesystem {
+	name = filepath.ToSlash(filepath.Clean(name))
+	for _, dir := range []string{"refs/bisect", "refs/worktree", "refs/rewritten"} {
+		if name == dir || strings.HasPrefix(name, dir+"/") {
+			return fs.gitDir
+		}
+	}
+	return fs.RepositoryFilesystem
+}
+
+func (fs worktreeFilesystem) Open(name string) (billy.File, error) {
+	return fs.route(name).Open(name)
+}
+
+func (fs worktreeFilesystem) OpenFile(name string, flag int, perm os.FileMode) (billy.File, error) {
+	return fs.route(name).OpenFile(name, flag, perm)
+}
+
+func (fs worktreeFilesystem) Stat(name string) (os.FileInfo, error) {
+	return fs.route(name).Stat(name)
+}
+
+func (fs worktreeFilesystem) Lstat(name string) (os.FileInfo, error) {
+	return fs.route(name).Lstat(name)
+}
+
+func (fs worktreeFilesystem) ReadDir(name string) ([]os.FileInfo, error) {
+	return fs.route(name).ReadDir(name)
+}
+
+// findGitDirs returns the git directory of the worktree at dir, which must be
+// its top level, and the shared git directory of its repository.
+func findGitDirs(dir string) (string, string, bool) {
+	gitDir := worktreeGitDir(dir)
+	info, err := os.Stat(gitDir)
+	if err != nil || !info.IsDir() || exists(filepath.Join(gitDir, "reftable")) {
+		return "", "", false
+	}
+
+	common, err := readTrimmed(filepath.Join(gitDir, "commondir"))
+	if os.IsNotExist(err) {
+		return gitDir, gitDir, true
+	}
+	if err != nil {
+		return "", "", false
+	}
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+	common = filepath.Clean(common)
+	if exists(filepath.Join(common, "reftable")) {
+		return "", "", false
+	}
+	return gitDir, common, true
+}
+
+// isPlainRefName reports whether ref is a plain ref name rather than a revision
+// expression such as "HEAD~2", "main@{1}" or an abbreviated commit.
+func isPlainRefName(ref string) bool {
+	if ref == "" || ref == "HEAD" || strings.ContainsAny(ref, "~^:@{}[]?* \\") || strings.Contains(ref, "..") {
+		return false
+	}
+	return !isHex(ref)
+}
+
+// isHex reports whether s consists of hexadecimal digits only.
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// readTrimmed returns the contents of the file at path without surrounding whitespace.
+func readTrimmed(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,202 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	hashA = "1111111111111111111111111111111111111111"
+	hashB = "2222222222222222222222222222222222222222"
+	hashC = "3333333333333333333333333333333333333333"
+)
+
+// writeFiles creates files with the given contents below root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadWorktrees(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	gitDir := filepath.Join(repo, ".git")
+	writeFiles(t, root, map[string]string{
+		"repo/.git/HEAD":                     "ref: refs/heads/main\n",
+		"repo/.git/refs/heads/main":          hashA + "\n",
+		"repo/.git/packed-refs":              "# pack-refs with: peeled fully-peeled sorted\n" + hashB + " refs/heads/feature\n",
+		"repo/.git/worktrees/feature/gitdir": filepath.Join(root, "trees/feature/.git") + "\n",
+		"repo/.git/worktrees/feature/HEAD":   "ref: refs/heads/feature\n",
+		"repo/.git/worktrees/feature/locked": "on a usb stick\n",
+		"repo/.git/worktrees/bisect/gitdir":  filepath.Join(root, "trees/bisect/.git") + "\n",
+		"repo/.git/worktrees/bisect/HEAD":    hashC + "\n",
+		"repo/.git/worktrees/new/gitdir":     filepath.Join(root, "trees/new/.git") + "\n",
+		"repo/.git/worktrees/new/HEAD":       "ref: refs/heads/new\n",
+		"trees/bisect/.git":                  "gitdir: " + filepath.Join(gitDir, "worktrees/bisect") + "\n",
+		"trees/new/.git":                     "gitdir: " + filepath.Join(gitDir, "worktrees/new") + "\n",
+	})
+
+	worktrees, ok := readWorktrees(gitDir)
+	if !ok {
+		t.Fatal("readWorktrees did not handle the repository")
+	}
+
+	expected := []*Worktree{
+		{Path: repo, Branch: "main", Head: hashA},
+		{Path: filepath.Join(root, "trees/bisect"), Branch: "HEAD", Head: hashC, Detached: true},
+		// The directory is gone, but the lock keeps it from being prunable
+		{Path: filepath.Join(root, "trees/feature"), Branch: "feature", Head: hashB, Locked: true, LockReason: "on a usb stick"},
+		{Path: filepath.Join(root, "trees/new"), Branch: "new", Head: zeroHash},
+	}
+	if diff := cmp.Diff(expected, worktrees); diff != "" {
+		t.Errorf("readWorktrees mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadWorktreesUnsupported(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"bare.git/HEAD":       "ref: refs/heads/main\n",
+		"repo/.git/HEAD":      "ref: refs/heads/main\n",
+		"repo/.git/reftable/": "",
+	})
+
+	for _, dir := range []string{"bare.git", "repo/.git"} {
+		if _, ok := readWorktrees(filepath.Join(root, dir)); ok {
+			t.Errorf("readWorktrees(%s) should leave the repository to git", dir)
+		}
+	}
+}
+
+func TestIsPlainRefName(t *testing.T) {
+	for ref, expected := range map[string]bool{
+		"main":         true,
+		"origin/main":  true,
+		"HEAD":         false,
+		"main~2":       false,
+		"main@{1}":     false,
+		"v1.0^{}":      false,
+		"a..b":         false,
+		"deadbeef":     false,
+		"feature/deaf": true,
+	} {
+		t.Run(ref, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(expected, isPlainRefName(ref)); diff != "" {
+				t.Errorf("isPlainRefName mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// gitRepo runs git in dir with a fixed identity and without the user's
+// configuration, failing the test if it fails.
+func gitRepo(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=giwo", "-c", "user.email=giwo@example.com", "-c", "init.defaultBranch=main"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL="+os.DevNull, "GIT_CONFIG_NOSYSTEM=1")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// TestGoGitBackendMatchesGit checks the go-git backend against what git itself
+// reports for a repository with loose and packed refs, annotated tags, whose
+// packed-refs entries carry peeled values, symbolic refs, refs kept per worktree
+// and linked worktrees that are locked, detached or gone.
+func TestGoGitBackendMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	for name, tt := range map[string]struct {
+		packed bool
+	}{
+		"loose refs":  {},
+		"packed refs": {packed: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo := filepath.Join(root, "repo")
+			gitRepo(t, root, "init", "--quiet", repo)
+			gitRepo(t, repo, "commit", "--quiet", "--allow-empty", "-m", "first")
+			gitRepo(t, repo, "tag", "-a", "-m", "release", "v1")
+			gitRepo(t, repo, "commit", "--quiet", "--allow-empty", "-m", "second")
+			gitRepo(t, repo, "tag", "v2")
+			gitRepo(t, repo, "branch", "feature/x", "HEAD~1")
+			gitRepo(t, repo, "update-ref", "refs/remotes/origin/main", "HEAD~1")
+			gitRepo(t, repo, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+			trees := filepath.Join(root, "trees")
+			gitRepo(t, repo, "worktree", "add", "--quiet", filepath.Join(trees, "feature"), "feature/x")
+			gitRepo(t, repo, "worktree", "add", "--quiet", "--detach", filepath.Join(trees, "detached"), "v1")
+			gitRepo(t, repo, "worktree", "add", "--quiet", "-b", "locked", filepath.Join(trees, "locked"))
+			gitRepo(t, repo, "worktree", "lock", "--reason", "on a USB drive", filepath.Join(trees, "locked"))
+			gitRepo(t, repo, "worktree", "add", "--quiet", "-b", "gone", filepath.Join(trees, "gone"))
+			if err := os.RemoveAll(filepath.Join(trees, "gone")); err != nil {
+				t.Fatal(err)
+			}
+			// Refs of the feature worktree only, which the main worktree does not see
+			gitRepo(t, filepath.Join(trees, "feature"), "update-ref", "refs/bisect/bad", "main")
+			gitRepo(t, filepath.Join(trees, "feature"), "update-ref", "refs/worktree/mark", "v1")
+			if tt.packed {
+				gitRepo(t, repo, "pack-refs", "--all")
+				packed, err := os.ReadFile(filepath.Join(repo, ".git", "packed-refs"))
+				if err != nil || !strings.Contains(string(packed), "\n^") {
+					t.Fatalf("expected packed-refs with peeled tags, got %q (%v)", packed, err)
+				}
+			}
+
+			ctx := context.Background()
+			gitCommonDir := filepath.Join(repo, ".git")
+			expected, err := ExecBackend{}.ListWorktrees(ctx, repo, gitCommonDir)
+			if err != nil {
+				t.Fatalf("git worktree list: %v", err)
+			}
+			worktrees, ok := readWorktrees(gitCommonDir)
+			if !ok {
+				t.Fatal("readWorktrees did not handle the repository")
+			}
+			if diff := cmp.Diff(expected, worktrees); diff != "" {
+				t.Errorf("ListWorktrees mismatch with git (-git +go-git):\n%s", diff)
+			}
+
+			for _, dir := range []string{repo, filepath.Join(trees, "feature")} {
+				for _, ref := range []string{"main", "feature/x", "heads/main", "refs/heads/main", "origin/main", "origin", "v1", "v2", "HEAD", "HEAD~1", "bisect/bad", "refs/worktree/mark", "missing"} {
+					hash, ok := ExecBackend{}.ResolveCommit(ctx, dir, ref)
+					goGitHash, goGitOK := GoGitBackend{}.ResolveCommit(ctx, dir, ref)
+					if diff := cmp.Diff([]any{hash, ok}, []any{goGitHash, goGitOK}); diff != "" {
+						t.Errorf("ResolveCommit(%q) in %s mismatch with git (-git +go-git):\n%s", ref, dir, diff)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// States of the git hooks of a worktree.
+const (
+	// HooksShared means git finds the hooks from every worktree on its own:
+	// they are in the git directory, or core.hooksPath is absolute.
+	HooksShared = "shared"
+
+	// HooksPresent means the worktree has its own hooks directory, such as
+	// one committed to the repository.
+	HooksPresent = "present"
+
+	// HooksLinked and HooksCopied mean the hooks directory was symlinked, or
+	// copied where symbolic links are not available, from another worktree.
+	HooksLinked = "linked"
+	HooksCopied = "copied"
+
+	// HooksMissing means no worktree has the hooks directory, as when the
+	// tool that generates it, such as husky, was not installed yet.
+	HooksMissing = "missing"
+)
+
+// HooksStatus describes how the git hooks of a worktree are found.
+type HooksStatus struct {
+	// HooksPath is core.hooksPath, empty if it is not set.
+	HooksPath string `json:"hooks_path,omitempty"`
+
+	// Dir is the hooks directory git uses in the worktree.
+	Dir string `json:"dir"`
+
+	// Source is the hooks directory that was linked or copied into the worktree.
+	Source string `json:"source,omitempty"`
+
+	State string `json:"state"`
+}
+
+// SyncHooks makes the git hooks of the repository run in the worktree at path.
+// Linked worktrees share the hooks in the git directory, but a relative
+// core.hooksPath, as set by husky or for a .githooks directory, is looked up
+// in each worktree, where it may not exist if it is generated rather than
+// committed. SyncHooks symlinks it from the worktree the command runs in, or
+// the main worktree, and copies it where symbolic links are not available.
+// The link is excluded from git status so that it does not show as untracked.
+func (m *Manager) SyncHooks(ctx context.Context, path string) (*HooksStatus, error) {
+	hooksPath := ""
+	if output, err := m.gitOutput(ctx, path, "config", "--get", "core.hooksPath"); err == nil {
+		hooksPath = strings.TrimSpace(output)
+	}
+	status := &HooksStatus{HooksPath: hooksPath, State: HooksShared}
+	if hooksPath == "" || filepath.IsAbs(hooksPath) || strings.HasPrefix(hooksPath, "~") {
+		status.Dir = hooksPath
+		if hooksPath == "" {
+			status.Dir = filepath.Join(m.gitCommonDir, "hooks")
+		}
+		return status, nil
+	}
+
+	status.Dir = filepath.Join(path, filepath.FromSlash(hooksPath))
+	if _, err := os.Lstat(status.Dir); err == nil {
+		status.State = HooksPresent
+		return status, nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(hooksPath)) {
+		// Nothing is linked outside of the worktree
+		status.State = HooksMissing
+		return status, nil
+	}
+
+	for _, root := range []string{m.repoRoot, m.mainRoot} {
+		if root == path || root == "" {
+			continue
+		}
+		source := filepath.Join(root, filepath.FromSlash(hooksPath))
+		if info, err := os.Stat(source); err != nil || !info.IsDir() {
+			continue
+		}
+		status.Source = source
+
+		if err := os.MkdirAll(filepath.Dir(status.Dir), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create the hooks directory: %w", err)
+		}
+		status.State = HooksLinked
+		if err := os.Symlink(source, status.Dir); err != nil {
+			status.State = HooksCopied
+			if err := copyDir(source, status.Dir); err != nil {
+				return nil, fmt.Errorf("failed to copy the hooks from %s: %w", source, err)
+			}
+		}
+		if err := m.excludeFromStatus(ctx, path, hooksPath); err != nil {
+			m.log().Debug("failed to exclude the hooks directory", "path", hooksPath, "error", err)
+		}
+		return status, nil
+	}
+
+	status.State = HooksMissing
+	return status, nil
+}
+
+// syncNewHooks runs SyncHooks for a new worktree, reporting what it did.
+func (m *Manager) syncNewHooks(ctx context.Context, path string) {
+	status, err := m.SyncHooks(ctx, path)
+	switch {
+	case err != nil:
+		fmt.Printf("⚠️  Warning: %v\n", err)
+	case status.State == HooksLinked || status.State == HooksCopied:
+		fmt.Printf("🪝 Git hooks in %s %s from %s\n", status.HooksPath, status.State, status.Source)
+	case status.State == HooksMissing:
+		fmt.Printf("⚠️  Warning: core.hooksPath is %s, which no worktree has, so git hooks will not run until it is generated\n", status.HooksPath)
+	}
+}
+
+// excludeFromStatus adds rel, a path in the worktree at path, to the exclude
+// file of the repository unless git ignores it already. Like the hooks, the
+// exclude file is shared by all worktrees.
+func (m *Manager) excludeFromStatus(ctx context.Context, path, rel string) error {
+	rel = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(rel)), "/")
+	if _, err := m.gitOutput(ctx, path, "check-ignore", "--quiet", "--no-index", rel); err == nil {
+		return nil
+	}
+
+	excludeFile := filepath.Join(m.gitCommonDir, "info", "exclude")
+	pattern := "/" + rel
+	if data, err := os.ReadFile(excludeFile); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) == pattern {
+				return nil
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(excludeFile), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(excludeFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "\n# git hooks linked into worktrees by giwo\n%s\n", pattern)
+	return err
+}
+
+// copyDir copies the directory src to dst, keeping file permissions, which
+// makes hooks executable.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
@@ -0,0 +1,58 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyDir(t *testing.T) {
+	t.Parallel()
+
+	src := filepath.Join(t.TempDir(), "_")
+	writeFile(t, filepath.Join(src, "pre-commit"), "#!/bin/sh\nnpx lint-staged\n", 0o755)
+	writeFile(t, filepath.Join(src, "nested", "h"), "#!/bin/sh\n", 0o755)
+	writeFile(t, filepath.Join(src, ".gitignore"), "*\n", 0o644)
+
+	dst := filepath.Join(t.TempDir(), ".husky", "_")
+	if err := copyDir(src, dst); err != nil {
+		t.Fatalf("copyDir failed: %v", err)
+	}
+
+	for rel, expected := range map[string]string{
+		"pre-commit": "#!/bin/sh\nnpx lint-staged\n",
+		"nested/h":   "#!/bin/sh\n",
+		".gitignore": "*\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", rel, err)
+		}
+		if diff := cmp.Diff(expected, string(data)); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", rel, diff)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dst, "pre-commit"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(os.FileMode(0o755), info.Mode().Perm()); diff != "" {
+			t.Errorf("hook permissions mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+}
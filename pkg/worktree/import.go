@@ -0,0 +1,105 @@
+package worktree
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// ImportEntry is one worktree to create, read from a line of an import file.
+type ImportEntry struct {
+	Branch string
+
+	// Base is the branch the new one starts from, the default branch if empty.
+	Base string
+
+	// Template is the key of the template override to apply, if any.
+	Template string
+}
+
+// ParseImport reads the worktrees to create from r, one per line as
+// "branch [base [template]]". Blank lines and lines starting with # are
+// skipped. A branch may appear once only.
+func ParseImport(r io.Reader) ([]ImportEntry, error) {
+	var entries []ImportEntry
+	seen := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected 'branch [base [template]]', got %d fields", n, len(fields))
+		}
+		entry := ImportEntry{Branch: fields[0]}
+		if len(fields) > 1 {
+			entry.Base = fields[1]
+		}
+		if len(fields) > 2 {
+			entry.Template = fields[2]
+		}
+
+		if err := utils.ValidateBranchName(entry.Branch); err != nil {
+			return nil, fmt.Errorf("line %d: invalid branch name: %w", n, err)
+		}
+		if first, ok := seen[entry.Branch]; ok {
+			return nil, fmt.Errorf("line %d: branch %s is already on line %d", n, entry.Branch, first)
+		}
+		seen[entry.Branch] = n
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read worktrees: %w", err)
+	}
+	return entries, nil
+}
+
+// CreateAll creates the worktrees of opts, at most jobs at once, or all at
+// once if jobs is zero, and returns the error of each, nil for those created.
+// Origin is fetched once for all of them, and while worktrees are registered
+// with git one at a time, copying their templates and running their setup
+// happen concurrently.
+func (m *Manager) CreateAll(ctx context.Context, opts []CreateOptions, jobs int) []error {
+	errs := make([]error, len(opts))
+	for i, o := range opts {
+		errs[i] = m.prepareCreate(o)
+	}
+	if err := m.fetchForCreate(ctx); err != nil {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+
+	if jobs <= 0 {
+		jobs = len(opts)
+	}
+	var (
+		wg    sync.WaitGroup
+		slots = make(chan struct{}, jobs)
+	)
+	for i, o := range opts {
+		if errs[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = m.createFetched(ctx, o)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,67 @@
+package worktree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseImport(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		input   string
+		want    []ImportEntry
+		wantErr string
+	}{
+		"branches": {
+			input: "feature-a\nfeature-b\n",
+			want:  []ImportEntry{{Branch: "feature-a"}, {Branch: "feature-b"}},
+		},
+		"base and template": {
+			input: "release/1.2 main\nhotfix-42 release/1.1 backend\n",
+			want: []ImportEntry{
+				{Branch: "release/1.2", Base: "main"},
+				{Branch: "hotfix-42", Base: "release/1.1", Template: "backend"},
+			},
+		},
+		"comments and blank lines": {
+			input: "# streams of the 2.0 release\n\n  feature-a   develop  \n",
+			want:  []ImportEntry{{Branch: "feature-a", Base: "develop"}},
+		},
+		"empty": {
+			input: "# nothing yet\n",
+		},
+		"too many fields": {
+			input:   "feature-a main backend extra\n",
+			wantErr: "line 1: expected 'branch [base [template]]', got 4 fields",
+		},
+		"invalid branch": {
+			input:   "ok\nbad..name\n",
+			wantErr: "line 2: invalid branch name",
+		},
+		"duplicate branch": {
+			input:   "feature-a\n# again\nfeature-a main\n",
+			wantErr: "line 3: branch feature-a is already on line 1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseImport(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseImport() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseImport() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseImport() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
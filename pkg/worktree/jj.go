@@ -0,0 +1,86 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// jjBookmarksTemplate prints the names of the local bookmarks of a revision, separated by spaces.
+const jjBookmarksTemplate = `local_bookmarks.map(|b| b.name()).join(" ")`
+
+// JJColocated reports whether the repository is colocated with jj (Jujutsu), i.e.
+// jj manages the main worktree's working copy on top of the same git repository.
+// jj keeps git's HEAD detached there and imports changes to git branches as
+// changes to its bookmarks.
+func (m *Manager) JJColocated() bool {
+	info, err := os.Stat(filepath.Join(m.JJWorkspace(), ".jj", "repo"))
+	return err == nil && info.IsDir()
+}
+
+// JJWorkspace returns the directory of the worktree jj manages in a colocated
+// repository, the one holding the git directory.
+func (m *Manager) JJWorkspace() string {
+	return filepath.Dir(m.gitCommonDir)
+}
+
+// jjBookmarks returns the jj bookmarks at the commit checked out in jj's workspace,
+// the parent of jj's working-copy commit and git's detached HEAD. They are read
+// with jj if it is installed, and otherwise from the git branches jj exports them as.
+func (m *Manager) jjBookmarks(ctx context.Context) []string {
+	dir := m.JJWorkspace()
+
+	if _, err := exec.LookPath("jj"); err == nil {
+		// --ignore-working-copy keeps jj from snapshotting the working copy, which would record a new operation
+		cmd := exec.CommandContext(ctx, "jj", "log", "--ignore-working-copy", "--no-graph", "--color=never",
+			"-r", "@-", "-T", jjBookmarksTemplate)
+		cmd.Dir = dir
+		if output, err := cmd.Output(); err == nil {
+			return strings.Fields(string(output))
+		}
+	}
+
+	output, err := m.gitOutput(ctx, dir, "for-each-ref", "--points-at", "HEAD", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(output)
+}
+
+// jjBookmark returns the bookmark checked out in jj's workspace, or "" if there is none.
+func (m *Manager) jjBookmark(ctx context.Context, worktrees []*Worktree) string {
+	return pickBookmark(m.jjBookmarks(ctx), worktrees)
+}
+
+// pickBookmark returns the first of the bookmarks that is not the branch of one of
+// the worktrees. jj imports the branches of other worktrees as bookmarks too, and
+// a new worktree's branch starts at the same commit.
+func pickBookmark(bookmarks []string, worktrees []*Worktree) string {
+	checkedOut := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		if !wt.Detached {
+			checkedOut[wt.Branch] = true
+		}
+	}
+	for _, bookmark := range bookmarks {
+		if !checkedOut[bookmark] {
+			return bookmark
+		}
+	}
+	return ""
+}
+
+// setJJBookmark records the jj bookmark of the worktree jj manages, whose HEAD
+// git reports as detached, so that it shows up with a name.
+func (m *Manager) setJJBookmark(ctx context.Context, worktrees []*Worktree) {
+	if !m.JJColocated() {
+		return
+	}
+	for _, wt := range worktrees {
+		if wt.Path == m.JJWorkspace() && wt.Detached {
+			wt.Bookmark = m.jjBookmark(ctx, worktrees)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPickBookmark(t *testing.T) {
+	worktrees := []*Worktree{
+		{Path: "/repo", Branch: "HEAD", Detached: true},
+		{Path: "/repo/.worktree/feature", Branch: "feature"},
+	}
+
+	for name, tt := range map[string]struct {
+		bookmarks []string
+		expected  string
+	}{
+		"none":                              {bookmarks: nil, expected: ""},
+		"single":                            {bookmarks: []string{"main"}, expected: "main"},
+		"branches of worktrees are skipped": {bookmarks: []string{"feature", "main"}, expected: "main"},
+		"only branches of worktrees":        {bookmarks: []string{"feature"}, expected: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, pickBookmark(tt.bookmarks, worktrees)); diff != "" {
+				t.Errorf("pickBookmark mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
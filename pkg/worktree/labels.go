@@ -0,0 +1,125 @@
+package worktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// labelsFileName is the name of the file holding worktree labels in the state directory.
+const labelsFileName = "labels.json"
+
+// labelStore maps worktree paths to their labels, sorted.
+type labelStore map[string][]string
+
+// ValidateLabel checks that label can be attached to a worktree: a non-empty
+// word without whitespace or commas, not starting with + or -.
+func ValidateLabel(label string) error {
+	switch {
+	case label == "":
+		return fmt.Errorf("label cannot be empty")
+	case strings.HasPrefix(label, "+") || strings.HasPrefix(label, "-"):
+		return fmt.Errorf("label %q cannot start with + or -", label)
+	case strings.ContainsFunc(label, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }):
+		return fmt.Errorf("label %q cannot contain whitespace or commas", label)
+	}
+	return nil
+}
+
+// Label attaches the labels in add to the worktree at path and detaches those
+// in remove, and returns its labels afterwards, sorted.
+func (m *Manager) Label(path string, add, remove []string) ([]string, error) {
+	for _, label := range add {
+		if err := ValidateLabel(label); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := m.loadLabels()
+	if err != nil {
+		return nil, err
+	}
+	labels := updateLabels(store[path], add, remove)
+	if len(labels) == 0 {
+		delete(store, path)
+	} else {
+		store[path] = labels
+	}
+	if err := m.saveLabels(store); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// updateLabels returns labels with add attached and remove detached, sorted and without duplicates.
+func updateLabels(labels, add, remove []string) []string {
+	updated := slices.Concat(labels, add)
+	updated = slices.DeleteFunc(updated, func(label string) bool { return slices.Contains(remove, label) })
+	slices.Sort(updated)
+	return slices.Compact(updated)
+}
+
+// applyLabels sets the labels of worktrees.
+func (m *Manager) applyLabels(worktrees []*Worktree) error {
+	store, err := m.loadLabels()
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		wt.Labels = store[wt.Path]
+	}
+	return nil
+}
+
+// moveLabels moves the labels of the worktree at oldPath to newPath, or drops
+// them if newPath is empty.
+func (m *Manager) moveLabels(oldPath, newPath string) error {
+	store, err := m.loadLabels()
+	if err != nil {
+		return err
+	}
+	labels, ok := store[oldPath]
+	if !ok {
+		return nil
+	}
+	delete(store, oldPath)
+	if newPath != "" {
+		store[newPath] = labels
+	}
+	return m.saveLabels(store)
+}
+
+// loadLabels reads the label store, returning an empty store if none exists yet.
+func (m *Manager) loadLabels() (labelStore, error) {
+	store := make(labelStore)
+
+	data, err := os.ReadFile(filepath.Join(m.StateDir(), labelsFileName))
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse labels: %w", err)
+	}
+	return store, nil
+}
+
+// saveLabels writes the label store to disk.
+func (m *Manager) saveLabels(store labelStore) error {
+	if err := os.MkdirAll(m.StateDir(), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode labels: %w", err)
+	}
+	return os.WriteFile(filepath.Join(m.StateDir(), labelsFileName), data, 0o644)
+}
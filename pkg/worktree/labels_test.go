@@ -0,0 +1,103 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateLabel(t *testing.T) {
+	for name, tt := range map[string]struct {
+		label   string
+		wantErr bool
+	}{
+		"word":         {label: "review"},
+		"with dash":    {label: "needs-qa"},
+		"with slash":   {label: "team/infra"},
+		"empty":        {label: "", wantErr: true},
+		"plus prefix":  {label: "+review", wantErr: true},
+		"minus prefix": {label: "-review", wantErr: true},
+		"space":        {label: "needs review", wantErr: true},
+		"comma":        {label: "review,urgent", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateLabel(tt.label)
+			if diff := cmp.Diff(tt.wantErr, err != nil); diff != "" {
+				t.Errorf("ValidateLabel(%q) error = %v (-want +got):\n%s", tt.label, err, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateLabels(t *testing.T) {
+	for name, tt := range map[string]struct {
+		labels   []string
+		add      []string
+		remove   []string
+		expected []string
+	}{
+		"add to none": {
+			add:      []string{"urgent", "review"},
+			expected: []string{"review", "urgent"},
+		},
+		"duplicates": {
+			labels:   []string{"review"},
+			add:      []string{"review", "urgent", "urgent"},
+			expected: []string{"review", "urgent"},
+		},
+		"remove": {
+			labels:   []string{"review", "urgent"},
+			remove:   []string{"urgent", "unknown"},
+			expected: []string{"review"},
+		},
+		"remove all": {
+			labels:   []string{"review"},
+			remove:   []string{"review"},
+			expected: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := updateLabels(tt.labels, tt.add, tt.remove)
+			if len(got) == 0 {
+				got = nil
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("updateLabels mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterByLabel(t *testing.T) {
+	worktrees := []*Worktree{
+		{Branch: "main", Path: "/repo"},
+		{Branch: "feat-a", Path: "/repo/.worktree/feat-a", Labels: []string{"review"}},
+		{Branch: "feat-b", Path: "/repo/.worktree/feat-b", Labels: []string{"review", "urgent"}},
+	}
+
+	for name, tt := range map[string]struct {
+		label    string
+		expected []string
+	}{
+		"label":    {label: "urgent", expected: []string{"feat-b"}},
+		"shared":   {label: "review", expected: []string{"feat-a", "feat-b"}},
+		"no label": {label: "", expected: []string{"main", "feat-a", "feat-b"}},
+		"unknown":  {label: "wip", expected: nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, wt := range FilterByLabel(worktrees, tt.label) {
+				got = append(got, wt.Branch)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("FilterByLabel mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package worktree
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// repoLayout is where the parts of a repository are.
+type repoLayout struct {
+	// repoRoot is the worktree the manager works in: the root of the
+	// worktree containing the directory, the main worktree from inside the
+	// git directory, or the repository itself when it is bare.
+	repoRoot string
+
+	// gitCommonDir is the git directory shared by all worktrees.
+	gitCommonDir string
+
+	// mainRoot is the root of the main worktree, or the repository itself when
+	// it is bare.
+	mainRoot string
+	bare     bool
+}
+
+// resolveLayout finds the repository containing dir, which may be a worktree,
+// its git directory or a bare repository.
+func resolveLayout(dir string) (*repoLayout, error) {
+	output, err := revParse(dir, "--is-bare-repository", "--is-inside-git-dir", "--git-common-dir")
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(output, "\n")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected output of git rev-parse: %q", output)
+	}
+	bare, insideGitDir := fields[0] == "true", fields[1] == "true"
+
+	// git reports the common dir relative to dir when it can
+	commonDir := filepath.FromSlash(fields[2])
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	if commonDir, err = filepath.Abs(commonDir); err != nil {
+		return nil, err
+	}
+
+	layout := &repoLayout{gitCommonDir: commonDir}
+	if layout.mainRoot, layout.bare, err = mainWorktree(commonDir); err != nil {
+		return nil, err
+	}
+	switch {
+	case bare || insideGitDir:
+		layout.repoRoot = layout.mainRoot
+	default:
+		root, err := revParse(dir, "--show-toplevel")
+		if err != nil {
+			return nil, err
+		}
+		layout.repoRoot = filepath.FromSlash(root)
+	}
+	return layout, nil
+}
+
+// mainWorktree returns the root of the main worktree of the repository with
+// the git directory commonDir, and whether the repository is bare, in which
+// case the root is the repository itself. The usual .git directory is inside
+// the main worktree; other layouts, such as submodules or bare repositories,
+// are asked of git. A bare repository named .git counts as the git directory of
+// the directory holding it, which is where its worktrees are kept.
+func mainWorktree(commonDir string) (string, bool, error) {
+	if filepath.Base(commonDir) == ".git" {
+		return filepath.Dir(commonDir), false, nil
+	}
+
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd.Dir = commonDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false, gitFailure(err)
+	}
+	root, bare := parseMainWorktree(string(output))
+	if root == "" {
+		return "", false, fmt.Errorf("git worktree list did not report the main worktree")
+	}
+	return root, bare, nil
+}
+
+// parseMainWorktree returns the first worktree of 'git worktree list
+// --porcelain', which is the main worktree, and whether it is a bare repository.
+func parseMainWorktree(output string) (string, bool) {
+	block, _, _ := strings.Cut(strings.ReplaceAll(output, "\r\n", "\n"), "\n\n")
+	var root string
+	bare := false
+	for _, line := range strings.Split(block, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			root = filepath.FromSlash(strings.TrimPrefix(line, "worktree "))
+		case line == "bare":
+			bare = true
+		}
+	}
+	return root, bare
+}
+
+// defaultWorktreeDir returns where worktrees go unless configured otherwise:
+// .worktree in the root of the main worktree. A bare repository has none, so
+// they go next to it instead: in .worktree beside a hidden one such as
+// app/.bare, where the directory holding it is the project, and in
+// app.worktree beside app.git.
+func defaultWorktreeDir(mainRoot string, bare bool) string {
+	if !bare {
+		return filepath.Join(mainRoot, ".worktree")
+	}
+	name := filepath.Base(mainRoot)
+	if strings.HasPrefix(name, ".") {
+		return filepath.Join(filepath.Dir(mainRoot), ".worktree")
+	}
+	return filepath.Join(filepath.Dir(mainRoot), strings.TrimSuffix(name, ".git")+".worktree")
+}
+
+// trackRemoteBranches makes origin of a bare repository cloned with 'git clone
+// --bare', which fetches no remote-tracking branches, fetch them as a regular
+// clone does, since new worktrees start from them.
+func (m *Manager) trackRemoteBranches(ctx context.Context) error {
+	if !m.bare {
+		return nil
+	}
+	if _, err := m.gitOutput(ctx, m.repoRoot, "config", "--get-all", "remote.origin.fetch"); err == nil {
+		return nil
+	}
+	if _, err := m.gitOutput(ctx, m.repoRoot, "remote", "get-url", "origin"); err != nil {
+		// Without origin there is nothing to track
+		return nil
+	}
+	if _, err := m.gitOutput(ctx, m.repoRoot, "config", "--add", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return fmt.Errorf("failed to configure origin to fetch remote-tracking branches: %w", err)
+	}
+	fmt.Println("🔧 Configured origin to fetch remote-tracking branches, which bare clones leave out")
+	return nil
+}
+
+// revParse runs git rev-parse with args in dir and returns its trimmed output.
+func revParse(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"rev-parse"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", gitFailure(err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// gitFailure returns the error git printed for a failed command run with
+// Output, such as "fatal: not a git repository", rather than its exit status.
+func gitFailure(err error) error {
+	var exitErr *exec.ExitError
+	if stderrors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return stderrors.New(strings.TrimPrefix(msg, "fatal: "))
+		}
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package worktree
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMainWorktree(t *testing.T) {
+	for name, tt := range map[string]struct {
+		output   string
+		wantRoot string
+		wantBare bool
+	}{
+		"main worktree": {
+			output:   "worktree /src/app\nHEAD 1111\nbranch refs/heads/main\n\nworktree /src/app/.worktree/feat\nHEAD 2222\nbranch refs/heads/feat\n\n",
+			wantRoot: "/src/app",
+		},
+		"bare repository": {
+			output:   "worktree /src/app.git\nbare\n\nworktree /src/app.worktree/feat\nHEAD 2222\nbranch refs/heads/feat\n\n",
+			wantRoot: "/src/app.git",
+			wantBare: true,
+		},
+		"crlf line endings": {
+			output:   "worktree /src/app.git\r\nbare\r\n\r\n",
+			wantRoot: "/src/app.git",
+			wantBare: true,
+		},
+		"no output": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root, bare := parseMainWorktree(tt.output)
+			if diff := cmp.Diff(filepath.FromSlash(tt.wantRoot), root); diff != "" {
+				t.Errorf("root mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantBare, bare); diff != "" {
+				t.Errorf("bare mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefaultWorktreeDir(t *testing.T) {
+	for name, tt := range map[string]struct {
+		mainRoot string
+		bare     bool
+		expected string
+	}{
+		"main worktree":            {mainRoot: "/src/app", expected: "/src/app/.worktree"},
+		"bare repository":          {mainRoot: "/src/app.git", bare: true, expected: "/src/app.worktree"},
+		"bare repository, no .git": {mainRoot: "/src/app", bare: true, expected: "/src/app.worktree"},
+		"hidden bare repository":   {mainRoot: "/src/app/.bare", bare: true, expected: "/src/app/.worktree"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := defaultWorktreeDir(filepath.FromSlash(tt.mainRoot), tt.bare)
+			if diff := cmp.Diff(filepath.FromSlash(tt.expected), got); diff != "" {
+				t.Errorf("defaultWorktreeDir mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
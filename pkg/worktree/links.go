@@ -0,0 +1,171 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LinkState describes the health of a symlink giwo created in a worktree.
+type LinkState string
+
+const (
+	// LinkOK means the link points at its expected source, which exists.
+	LinkOK LinkState = "ok"
+
+	// LinkMissing means the link no longer exists in the worktree.
+	LinkMissing LinkState = "missing"
+
+	// LinkDangling means the link's target does not exist.
+	LinkDangling LinkState = "dangling"
+
+	// LinkMoved means the link points somewhere other than its expected source.
+	LinkMoved LinkState = "moved"
+)
+
+// LinkStatus is the result of verifying one symlink.
+type LinkStatus struct {
+	// Path is the absolute path of the link inside the worktree.
+	Path string `json:"path"`
+
+	// Target is where the link currently points, empty if it is missing.
+	Target string `json:"target,omitempty"`
+
+	// Expected is the shared source the link should point at.
+	Expected string `json:"expected"`
+
+	State LinkState `json:"state"`
+}
+
+// SetLinks sets the paths, relative to the repository root, that are symlinked
+// into new worktrees instead of copied, such as node_modules or data directories.
+func (m *Manager) SetLinks(paths []string) {
+	m.links = paths
+}
+
+// createLinks symlinks the shared paths links into a new worktree and records them.
+// Paths that do not exist in the repository root or already exist in the worktree are skipped.
+func (m *Manager) createLinks(worktreePath string, links []string) error {
+	created := make(map[string]string)
+	for _, rel := range links {
+		source := filepath.Join(m.repoRoot, rel)
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+
+		link := filepath.Join(worktreePath, rel)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+			return err
+		}
+		if err := os.Symlink(source, link); err != nil {
+			return err
+		}
+		created[rel] = source
+	}
+
+	if len(created) == 0 {
+		return nil
+	}
+	return m.updateMetadata(worktreePath, func(meta *Metadata) {
+		if meta.Links == nil {
+			meta.Links = make(map[string]string)
+		}
+		for rel, source := range created {
+			meta.Links[rel] = source
+		}
+	})
+}
+
+// VerifyLinks checks every symlink giwo created in the worktree, sorted by path.
+// A link is expected to point at the same path under the current repository root,
+// so links keep working when the repository itself is moved.
+func (m *Manager) VerifyLinks(wt *Worktree) ([]LinkStatus, error) {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, ok := store[wt.Path]
+	if !ok {
+		return nil, nil
+	}
+
+	var statuses []LinkStatus
+	for rel := range meta.Links {
+		statuses = append(statuses, m.verifyLink(wt.Path, rel))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Path < statuses[j].Path })
+
+	return statuses, nil
+}
+
+// verifyLink checks the link rel inside worktreePath.
+func (m *Manager) verifyLink(worktreePath, rel string) LinkStatus {
+	status := LinkStatus{
+		Path:     filepath.Join(worktreePath, rel),
+		Expected: filepath.Join(m.repoRoot, rel),
+	}
+
+	target, err := os.Readlink(status.Path)
+	if err != nil {
+		status.State = LinkMissing
+		return status
+	}
+	status.Target = target
+
+	switch {
+	case filepath.Clean(target) != status.Expected:
+		status.State = LinkMoved
+	case !exists(target):
+		status.State = LinkDangling
+	default:
+		status.State = LinkOK
+	}
+	return status
+}
+
+// RepairLink re-points a broken link at its expected source and records the new target.
+// It fails if the expected source does not exist, or if something other than a
+// symlink is in the way.
+func (m *Manager) RepairLink(wt *Worktree, status LinkStatus) error {
+	if !exists(status.Expected) {
+		return fmt.Errorf("cannot repair %s: %s does not exist", status.Path, status.Expected)
+	}
+
+	if info, err := os.Lstat(status.Path); err == nil {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("cannot repair %s: not a symlink", status.Path)
+		}
+		if err := os.Remove(status.Path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", status.Path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(status.Path), 0o755); err != nil {
+		return err
+	}
+	if err := os.Symlink(status.Expected, status.Path); err != nil {
+		return fmt.Errorf("failed to create link %s: %w", status.Path, err)
+	}
+
+	rel, err := filepath.Rel(wt.Path, status.Path)
+	if err != nil {
+		return err
+	}
+	return m.updateMetadata(wt.Path, func(meta *Metadata) {
+		if meta.Links == nil {
+			meta.Links = make(map[string]string)
+		}
+		meta.Links[rel] = status.Expected
+	})
+}
+
+// exists reports whether path exists, following symlinks.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,92 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVerifyAndRepairLinks(t *testing.T) {
+	t.Parallel()
+
+	repoRoot := t.TempDir()
+	m := &Manager{
+		repoRoot:     repoRoot,
+		gitCommonDir: filepath.Join(repoRoot, ".git"),
+		worktreeDir:  filepath.Join(repoRoot, ".worktree"),
+	}
+	m.SetLinks([]string{"node_modules", "data", "cache", "absent"})
+
+	for _, dir := range []string{"node_modules", "data", "cache"} {
+		if err := os.MkdirAll(filepath.Join(repoRoot, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wt := &Worktree{Path: m.WorktreePath("feature")}
+	if err := os.MkdirAll(wt.Path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.createLinks(wt.Path, m.links); err != nil {
+		t.Fatalf("createLinks failed: %v", err)
+	}
+
+	// Break the links in each possible way
+	if err := os.Remove(filepath.Join(wt.Path, "data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(repoRoot, "cache")); err != nil {
+		t.Fatal(err)
+	}
+	elsewhere := t.TempDir()
+	if err := os.Remove(filepath.Join(wt.Path, "node_modules")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(elsewhere, filepath.Join(wt.Path, "node_modules")); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := m.VerifyLinks(wt)
+	if err != nil {
+		t.Fatalf("VerifyLinks failed: %v", err)
+	}
+
+	states := make(map[string]LinkState)
+	for _, status := range statuses {
+		rel, _ := filepath.Rel(wt.Path, status.Path)
+		states[rel] = status.State
+	}
+	expected := map[string]LinkState{
+		"cache":        LinkDangling,
+		"data":         LinkMissing,
+		"node_modules": LinkMoved,
+	}
+	if diff := cmp.Diff(expected, states); diff != "" {
+		t.Fatalf("link states mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, status := range statuses {
+		err := m.RepairLink(wt, status)
+		if status.State == LinkDangling {
+			if err == nil {
+				t.Errorf("expected repairing %s to fail without a source", status.Path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("RepairLink(%s) failed: %v", status.Path, err)
+		}
+	}
+
+	statuses, err = m.VerifyLinks(wt)
+	if err != nil {
+		t.Fatalf("VerifyLinks failed: %v", err)
+	}
+	for _, status := range statuses {
+		if status.State != LinkOK && status.State != LinkDangling {
+			t.Errorf("%s: expected repaired link, got %s", status.Path, status.State)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package worktree
+
+import (
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// SetLogger sets the logger the manager traces its work to. Every git command
+// is logged at info level with its arguments, directory, duration and exit
+// status; finer steps like filesystem work and cache lookups at debug level.
+// Nothing is logged by default.
+func (m *Manager) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+}
+
+// log returns the manager's logger, which discards everything if none was set.
+func (m *Manager) log() *slog.Logger {
+	if m.logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return m.logger
+}
+
+// logGit logs a git command run with args in dir that took d and ended with err.
+func (m *Manager) logGit(dir string, args []string, d time.Duration, err error) {
+	logger := m.log()
+	if !logger.Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
+
+	attrs := []any{
+		slog.String("args", strings.Join(args, " ")),
+		slog.String("dir", dir),
+		slog.Duration("duration", d),
+		slog.Int("exit", gitExitCode(err)),
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if stderrors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			attrs = append(attrs, slog.String("stderr", strings.TrimSpace(string(exitErr.Stderr))))
+		}
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.Info("git", attrs...)
+}
+
+// gitExitCode returns the exit status of a git command that ended with err:
+// 0 for success and -1 if git did not exit by itself, e.g. when it could not be started.
+func gitExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if stderrors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
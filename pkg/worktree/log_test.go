@@ -0,0 +1,60 @@
+package worktree
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLogGit(t *testing.T) {
+	for name, tt := range map[string]struct {
+		err      error
+		expected string
+	}{
+		"success": {
+			expected: "level=INFO msg=git args=\"worktree add -b feature\" dir=/repo duration=1.5s exit=0\n",
+		},
+		"not started": {
+			err:      errors.New("executable file not found"),
+			expected: "level=INFO msg=git args=\"worktree add -b feature\" dir=/repo duration=1.5s exit=-1 error=\"executable file not found\"\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			m := &Manager{}
+			m.SetLogger(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{
+				// Drop the time to make the output predictable
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			})))
+
+			m.logGit("/repo", []string{"worktree", "add", "-b", "feature"}, 1500*time.Millisecond, tt.err)
+			if diff := cmp.Diff(tt.expected, out.String()); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGitExitCode(t *testing.T) {
+	t.Parallel()
+
+	err := exec.Command("sh", "-c", "exit 3").Run()
+	if diff := cmp.Diff(3, gitExitCode(err)); diff != "" {
+		t.Errorf("gitExitCode mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(0, gitExitCode(nil)); diff != "" {
+		t.Errorf("gitExitCode mismatch (-want +got):\n%s", diff)
+	}
+}
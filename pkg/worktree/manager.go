@@ -1,40 +1,75 @@
-// Package worktree provides functionality for managing Git worktrees.
 package worktree
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
 )
 
 // Manager handles Git worktree operations.
 type Manager struct {
-	repoRoot    string
-	worktreeDir string
+	repoRoot     string
+	gitCommonDir string
+	mainRoot     string
+	bare         bool
+	worktreeDir  string
+	templates    []string
+	template     TemplateFunc
+	links        []string
+	ports        PortOptions
+	paths        PathOptions
+	checkout     CheckoutOptions
+	progress     ProgressFunc
+	steps        StepFunc
+	noCache      bool
+	rollback     bool
+	offline      bool
+	offlineNoted bool
+	events       eventBus
+	timings      timingLog
+	logger       *slog.Logger
+	backend      GitBackend
+
+	// addMu serializes registering worktrees, which writes git's config, and
+	// metadataMu updates of the metadata, for creations that run concurrently.
+	addMu      sync.Mutex
+	metadataMu sync.Mutex
 }
 
 // New creates a new Manager instance.
 // It returns an error if the current directory is not in a Git repository.
 func New() (*Manager, error) {
-	repoRoot, err := getGitRoot()
+	return NewAt("")
+}
+
+// NewAt creates a new Manager instance for the repository containing dir.
+// An empty dir means the current directory, which may also be inside the git
+// directory or a bare repository.
+func NewAt(dir string) (*Manager, error) {
+	layout, err := resolveLayout(dir)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errors.ErrNotGitRepository, err)
 	}
 
-	worktreeDir := filepath.Join(repoRoot, ".worktree")
-
 	return &Manager{
-		repoRoot:    repoRoot,
-		worktreeDir: worktreeDir,
+		repoRoot:     layout.repoRoot,
+		gitCommonDir: layout.gitCommonDir,
+		mainRoot:     layout.mainRoot,
+		bare:         layout.bare,
+		worktreeDir:  defaultWorktreeDir(layout.mainRoot, layout.bare),
 	}, nil
 }
 
@@ -43,39 +78,534 @@ func (m *Manager) WorktreeDir() string {
 	return m.worktreeDir
 }
 
+// SetWorktreeDir overrides the directory where worktrees are stored.
+func (m *Manager) SetWorktreeDir(dir string) {
+	m.worktreeDir = utils.ResolvePath(dir)
+}
+
+// DisableCache makes List recompute the status of every worktree instead of
+// trusting the status cache. Fresh results are still written back to the cache.
+func (m *Manager) DisableCache() {
+	m.noCache = true
+}
+
 // RepoRoot returns the root directory of the Git repository.
 func (m *Manager) RepoRoot() string {
 	return m.repoRoot
 }
 
-// List returns all worktrees with their current status.
-func (m *Manager) List(ctx context.Context) ([]*Worktree, error) {
-	cmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
-	cmd.Dir = m.repoRoot
-	output, err := cmd.Output()
+// MainRoot returns the root directory of the repository's main worktree, which
+// differs from RepoRoot when the manager was created in a linked worktree. For a
+// bare repository, which has no main worktree, it is the repository itself.
+func (m *Manager) MainRoot() string {
+	return m.mainRoot
+}
+
+// Bare reports whether the repository is bare. Its worktrees are all linked
+// worktrees, and RepoRoot is the repository itself unless the manager was
+// created in one of them.
+func (m *Manager) Bare() bool {
+	return m.bare
+}
+
+// BaseDir returns the directory a relative worktree directory is resolved
+// against: the root of the main worktree, or the directory holding a bare
+// repository.
+func (m *Manager) BaseDir() string {
+	if m.bare {
+		return filepath.Dir(m.mainRoot)
+	}
+	return m.mainRoot
+}
+
+// WorktreePath returns the path of the worktree with the given name, which is
+// made a valid directory name as described by PathOptions.DirName. If name had
+// to be altered and its directory is taken by the worktree of another branch,
+// such as feature/foo?bar for feature/foo:bar, the one of
+// PathOptions.HashedDirName is used instead.
+func (m *Manager) WorktreePath(name string) string {
+	dir := m.paths.DirName(name)
+	if dir != name {
+		hashed := m.paths.HashedDirName(name)
+		if exists(filepath.Join(m.worktreeDir, hashed)) || m.takenByOther(filepath.Join(m.worktreeDir, dir), name) {
+			dir = hashed
+		}
+	}
+	return filepath.Join(m.worktreeDir, dir)
+}
+
+// takenByOther reports whether path holds something other than the worktree
+// called name: a directory that is no worktree, or the worktree of another
+// branch. Detached worktrees and those whose branch differs from their name,
+// which do not tell the name they were created for, are taken as its own.
+func (m *Manager) takenByOther(path, name string) bool {
+	if !exists(path) {
+		return false
+	}
+	gitDir := linkedGitDir(path)
+	if gitDir == "" {
+		return true
+	}
+	branch := adminBranch(gitDir)
+	if branch == "" || branch == name {
+		return false
+	}
+	store, err := m.loadMetadata()
 	if err != nil {
-		return nil, errors.NewGitError("worktree list", []string{"--porcelain"}, err)
+		return false
 	}
+	meta, ok := store[path]
+	return !ok || meta.HeadRef != branch
+}
+
+// ListOptions configures List.
+type ListOptions struct {
+	// WithStatus computes the status of every worktree before returning. Without
+	// it, List returns right away, and only worktrees whose cached status is still
+	// valid carry it; LoadStatus computes the others.
+	WithStatus bool
+
+	// Concurrency bounds how many worktrees have their status computed at once,
+	// the number of CPUs if zero.
+	Concurrency int
+}
 
-	worktrees, err := m.parseWorktreeList(string(output))
+// List returns all worktrees with their giwo metadata and labels, and their
+// current status as described by ListOptions.
+func (m *Manager) List(ctx context.Context, opts ListOptions) ([]*Worktree, error) {
+	worktrees, err := m.listRegistered(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse worktree list: %w", err)
+		return nil, err
 	}
 
-	// Enrich each worktree with additional information
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.applyLabels(worktrees); err != nil {
+		return nil, err
+	}
+
+	cache := loadStatusCache(filepath.Join(m.StateDir(), cacheFileName))
 	for _, wt := range worktrees {
-		if err := m.enrichWorktree(ctx, wt); err != nil {
-			// Log warning but continue with other worktrees
+		wt.IsMain = wt.Path == m.mainRoot
+		if meta, ok := store[wt.Path]; ok {
+			wt.Base = meta.Base
+			wt.Kind = meta.Kind
+			wt.Ephemeral = meta.Ephemeral
+			wt.Submodules = meta.Submodules
+			wt.HeadRef = meta.HeadRef
+			wt.Template = meta.Template
+		}
+		if opts.WithStatus {
+			continue
+		}
+		if m.noCache {
+			wt.StatusPending = true
 			continue
 		}
+		if status, ok := cache.get(wt.Path, m.statusCacheKey(wt)); ok {
+			status.apply(wt)
+		} else {
+			wt.StatusPending = true
+		}
 	}
 
+	m.setJJBookmark(ctx, worktrees)
+
+	if opts.WithStatus {
+		m.LoadStatus(ctx, worktrees, opts.Concurrency, func(i int, status *Worktree) {
+			*worktrees[i] = *status
+		})
+	}
 	return worktrees, nil
 }
 
-// Create creates a new worktree and branch.
-func (m *Manager) Create(ctx context.Context, branchName, baseBranch string, force bool) error {
-	worktreePath := filepath.Join(m.worktreeDir, branchName)
+// LoadStatus computes the status of worktrees as returned by List, at most
+// concurrency at once or as many as there are CPUs if it is zero, and calls fn
+// with the index of each worktree and a copy of it carrying its status. Cached
+// status is reused for worktrees whose HEAD, index and remote refs have not
+// changed. The worktrees themselves are not modified, so that they can be
+// shown while their status loads; fn is called from one goroutine at a time,
+// and not for worktrees whose status cannot be computed. LoadStatus returns
+// once all are done.
+func (m *Manager) LoadStatus(ctx context.Context, worktrees []*Worktree, concurrency int, fn func(i int, status *Worktree)) {
+	m.loadStatus(ctx, worktrees, concurrency, nil, fn)
+}
+
+// loadStatus is LoadStatus, computing the status of worktrees for which stale
+// returns true even if their cached status is valid.
+func (m *Manager) loadStatus(ctx context.Context, worktrees []*Worktree, concurrency int, stale func(*Worktree) bool, fn func(i int, status *Worktree)) {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	cache := loadStatusCache(filepath.Join(m.StateDir(), cacheFileName))
+	live := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		live[wt.Path] = true
+	}
+
+	var (
+		wg    sync.WaitGroup
+		fnMu  sync.Mutex
+		slots = make(chan struct{}, concurrency)
+	)
+	for i, wt := range worktrees {
+		status := *wt
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if !m.loadWorktreeStatus(ctx, cache, &status, stale != nil && stale(wt)) {
+				return
+			}
+			fnMu.Lock()
+			defer fnMu.Unlock()
+			fn(i, &status)
+		}()
+	}
+	wg.Wait()
+
+	// The cache is only an optimization, so failing to write it is not an error
+	cache.retain(live)
+	_ = cache.save()
+}
+
+// loadWorktreeStatus sets the status of wt from the cache if it is valid, or
+// computes and caches it otherwise, or right away if noCache is set. It reports
+// whether the status is known.
+func (m *Manager) loadWorktreeStatus(ctx context.Context, cache *statusCache, wt *Worktree, noCache bool) bool {
+	if !m.noCache && !noCache {
+		if status, ok := cache.get(wt.Path, m.statusCacheKey(wt)); ok {
+			m.log().Debug("status cache hit", "path", wt.Path)
+			status.apply(wt)
+			return true
+		}
+	}
+
+	m.log().Debug("computing status", "path", wt.Path)
+	if err := m.enrichWorktree(ctx, wt); err != nil {
+		// Log warning but continue with other worktrees
+		return false
+	}
+
+	// Computing status may refresh the index, so key the result by the state afterwards
+	cache.put(wt.Path, m.statusCacheKey(wt), statusFromWorktree(wt))
+	return true
+}
+
+// Status returns the worktree matching filter, as Match finds it, with its
+// current status. Unlike List, it does not consult the status cache.
+func (m *Manager) Status(ctx context.Context, filter string) (*Worktree, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wt, err := Match(worktrees, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if meta, ok := store[wt.Path]; ok {
+		wt.Base = meta.Base
+		wt.Kind = meta.Kind
+		wt.Ephemeral = meta.Ephemeral
+		wt.Submodules = meta.Submodules
+		wt.HeadRef = meta.HeadRef
+		wt.Template = meta.Template
+	}
+	if err := m.applyLabels([]*Worktree{wt}); err != nil {
+		return nil, err
+	}
+
+	if err := m.enrichWorktree(ctx, wt); err != nil {
+		return nil, fmt.Errorf("failed to get status of %s: %w", wt.Path, err)
+	}
+	return wt, nil
+}
+
+// ListFast returns the registered worktrees annotated with their last cached status,
+// however stale. It avoids running git in every worktree, which makes it suitable
+// for latency-sensitive callers like shell completion. Worktrees that have never
+// been listed only carry their identity.
+func (m *Manager) ListFast(ctx context.Context) ([]*Worktree, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := loadStatusCache(filepath.Join(m.StateDir(), cacheFileName))
+	for _, wt := range worktrees {
+		wt.IsMain = wt.Path == m.mainRoot
+		if status, ok := cache.peek(wt.Path); ok {
+			status.apply(wt)
+		}
+	}
+	if err := m.applyLabels(worktrees); err != nil {
+		return nil, err
+	}
+
+	return worktrees, nil
+}
+
+// listRegistered returns the worktrees known to git without enriching them with status.
+func (m *Manager) listRegistered(ctx context.Context) ([]*Worktree, error) {
+	done := m.timeGit(m.repoRoot, "worktree", "list")
+	worktrees, err := m.git().ListWorktrees(ctx, m.repoRoot, m.gitCommonDir)
+	done(err)
+	if err != nil {
+		return nil, errors.NewGitError("worktree list", []string{"--porcelain"}, err)
+	}
+
+	return worktrees, nil
+}
+
+// findRegistered returns the registered worktree at path, or nil if git does not know it.
+func (m *Manager) findRegistered(ctx context.Context, path string) (*Worktree, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The path may be spelled through a symlink, unlike git's
+	path = utils.ResolvePath(path)
+	for _, wt := range worktrees {
+		if utils.ResolvePath(wt.Path) == path {
+			return wt, nil
+		}
+	}
+	return nil, nil
+}
+
+// Lock locks the worktree at path so that it cannot be pruned, moved or removed.
+func (m *Manager) Lock(ctx context.Context, path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
+	}
+	args = append(args, path)
+
+	if err := m.runGitCommand(ctx, args...); err != nil {
+		return fmt.Errorf("failed to lock worktree: %w", err)
+	}
+	return nil
+}
+
+// Unlock unlocks the worktree at path.
+func (m *Manager) Unlock(ctx context.Context, path string) error {
+	if err := m.runGitCommand(ctx, "worktree", "unlock", path); err != nil {
+		return fmt.Errorf("failed to unlock worktree: %w", err)
+	}
+	return nil
+}
+
+// CreateFromTag creates a maintenance worktree with a new branch starting at a release tag.
+// Tags are fetched first so that tags published after the last fetch can be used.
+func (m *Manager) CreateFromTag(ctx context.Context, branchName, tag string, force bool) error {
+	if err := m.fetch(ctx, "--tags"); err != nil {
+		return fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	if err := m.CreateAt(ctx, branchName, "refs/tags/"+tag, false, force); err != nil {
+		return err
+	}
+
+	return m.updateMetadata(m.WorktreePath(branchName), func(meta *Metadata) {
+		meta.Base = tag
+		meta.Kind = KindMaintenance
+	})
+}
+
+// Push pushes a branch to origin, optionally setting it as the upstream.
+func (m *Manager) Push(ctx context.Context, branchName string, setUpstream bool) error {
+	if err := m.runGitCommand(ctx, pushArgs(branchName, setUpstream)...); err != nil {
+		return fmt.Errorf("failed to push branch: %w", err)
+	}
+	return nil
+}
+
+// pushArgs returns the git command pushing a branch to origin.
+func pushArgs(branchName string, setUpstream bool) []string {
+	args := []string{"push"}
+	if setUpstream {
+		args = append(args, "--set-upstream")
+	}
+	return append(args, "origin", branchName)
+}
+
+// SetUpstream configures origin/<branch> as the upstream of a branch.
+// The remote branch does not need to exist yet.
+func (m *Manager) SetUpstream(ctx context.Context, branchName string) error {
+	if err := m.runGitCommand(ctx, "config", fmt.Sprintf("branch.%s.remote", branchName), "origin"); err != nil {
+		return fmt.Errorf("failed to set upstream: %w", err)
+	}
+	if err := m.runGitCommand(ctx, "config", fmt.Sprintf("branch.%s.merge", branchName), "refs/heads/"+branchName); err != nil {
+		return fmt.Errorf("failed to set upstream: %w", err)
+	}
+	return nil
+}
+
+// Prune removes administrative files of worktrees whose directories are gone,
+// along with giwo metadata of worktrees git no longer knows about.
+// Unless includeMaintenance is set, prunable maintenance worktrees are kept by
+// locking them for the duration of the prune. It returns git's verbose output
+// and the paths of the worktrees that were kept.
+func (m *Manager) Prune(ctx context.Context, includeMaintenance bool) (string, []string, error) {
+	var kept []string
+	if !includeMaintenance {
+		worktrees, err := m.listRegistered(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		store, err := m.loadMetadata()
+		if err != nil {
+			return "", nil, err
+		}
+
+		for _, wt := range worktrees {
+			meta, ok := store[wt.Path]
+			if !wt.Prunable || wt.Locked || !ok || meta.Kind != KindMaintenance {
+				continue
+			}
+			if err := m.Lock(ctx, wt.Path, "giwo: protecting maintenance worktree during prune"); err != nil {
+				return "", nil, err
+			}
+			kept = append(kept, wt.Path)
+		}
+	}
+
+	done := m.step("Pruning worktrees")
+	var output bytes.Buffer
+	pruneErr := m.runGit(ctx, GitCommand{Dir: m.repoRoot, Args: []string{"worktree", "prune", "-v"}, Stdout: &output, Stderr: &output})
+	done(pruneErr)
+
+	for _, path := range kept {
+		if err := m.Unlock(ctx, path); err != nil {
+			fmt.Printf("⚠️  Warning: failed to unlock '%s': %v\n", path, err)
+		}
+	}
+
+	if pruneErr != nil {
+		return "", nil, errors.NewGitError("worktree prune", []string{"-v"}, pruneErr)
+	}
+
+	if err := m.pruneMetadata(ctx); err != nil {
+		fmt.Printf("⚠️  Warning: failed to prune worktree metadata: %v\n", err)
+	}
+
+	return output.String(), kept, nil
+}
+
+// CreateOptions configures Create.
+type CreateOptions struct {
+	// Branch is the new branch, which also names the worktree directory unless Name is set.
+	Branch string
+
+	// Name is the worktree directory, if it differs from Branch, such as when
+	// the branch was namespaced because its name was taken. The branch is then
+	// recorded as the worktree's pull request head.
+	Name string
+
+	// Base is the remote branch the new branch starts from, "main" if empty.
+	Base string
+
+	// Force creates the worktree even if its directory already exists.
+	// Without it, Create fails with ErrWorktreeExists.
+	Force bool
+}
+
+// Create fetches origin and creates a worktree with a new branch starting at
+// the base branch on origin.
+func (m *Manager) Create(ctx context.Context, opts CreateOptions) error {
+	if err := m.prepareCreate(opts); err != nil {
+		return err
+	}
+	if err := m.fetchForCreate(ctx); err != nil {
+		return err
+	}
+	return m.createFetched(ctx, opts)
+}
+
+// prepareCreate checks that the worktree of opts can be created and makes the
+// worktree directory.
+func (m *Manager) prepareCreate(opts CreateOptions) error {
+	if !opts.Force {
+		if _, err := os.Stat(m.WorktreePath(createName(opts))); err == nil {
+			return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, m.WorktreePath(createName(opts)))
+		}
+	}
+
+	if err := os.MkdirAll(m.worktreeDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	return nil
+}
+
+// fetchForCreate fetches the latest changes of origin, which new branches
+// start from.
+func (m *Manager) fetchForCreate(ctx context.Context) error {
+	if err := m.trackRemoteBranches(ctx); err != nil {
+		return err
+	}
+	if err := m.fetch(ctx, "--prune"); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+	return nil
+}
+
+// createFetched creates the worktree of opts once origin has been fetched.
+func (m *Manager) createFetched(ctx context.Context, opts CreateOptions) error {
+	branchName, baseBranch := opts.Branch, opts.Base
+	name := createName(opts)
+	worktreePath := m.WorktreePath(name)
+
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+
+	// Create the worktree
+	undo := m.beginCreate(ctx, worktreePath, branchName)
+	m.addMu.Lock()
+	err := m.addWorktree(ctx, worktreePath, createArgs(branchName, worktreePath, baseBranch)...)
+	m.addMu.Unlock()
+	if err != nil {
+		return m.abortCreate(ctx, undo, worktreePath, fmt.Errorf("failed to create worktree: %w", err))
+	}
+
+	if err := m.finishCreate(ctx, worktreePath, branchName, baseBranch); err != nil {
+		return m.abortCreate(ctx, undo, worktreePath, err)
+	}
+	if name != branchName {
+		if err := m.recordHeadRef(worktreePath, branchName); err != nil {
+			fmt.Printf("⚠️  Warning: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// createName returns the name of the worktree directory of opts.
+func createName(opts CreateOptions) string {
+	if opts.Name != "" {
+		return opts.Name
+	}
+	return opts.Branch
+}
+
+// CreateAt creates a worktree starting at an arbitrary revision (commit, tag or stash@{n}).
+// If detach is true the worktree gets a detached HEAD named only by its directory;
+// otherwise a new branch called name is created at the revision.
+// Unlike Create, no fetch is performed since the revision is expected to exist locally.
+func (m *Manager) CreateAt(ctx context.Context, name, rev string, detach, force bool) error {
+	worktreePath := m.WorktreePath(name)
 
 	if !force {
 		if _, err := os.Stat(worktreePath); err == nil {
@@ -87,85 +617,337 @@ func (m *Manager) Create(ctx context.Context, branchName, baseBranch string, for
 		return fmt.Errorf("failed to create worktree directory: %w", err)
 	}
 
-	if baseBranch == "" {
-		baseBranch = "main"
+	branch := name
+	if detach {
+		branch = ""
+	}
+	undo := m.beginCreate(ctx, worktreePath, branch)
+	if err := m.addWorktree(ctx, worktreePath, createAtArgs(name, worktreePath, rev, detach)...); err != nil {
+		return m.abortCreate(ctx, undo, worktreePath, fmt.Errorf("failed to create worktree: %w", err))
 	}
 
-	// Fetch the latest changes
-	if err := m.runGitCommand(ctx, "fetch", "--prune"); err != nil {
-		return fmt.Errorf("failed to fetch: %w", err)
+	// Record the resolved commit since revisions like HEAD~1 or stash@{0} move over time
+	base := rev
+	if commit, err := m.gitOutput(ctx, worktreePath, "rev-parse", "HEAD"); err == nil {
+		base = strings.TrimSpace(commit)
+	}
+	if err := m.finishCreate(ctx, worktreePath, branch, base); err != nil {
+		return m.abortCreate(ctx, undo, worktreePath, err)
 	}
 
-	// Create the worktree
-	args := []string{"worktree", "add", "-b", branchName, worktreePath, fmt.Sprintf("origin/%s", baseBranch)}
-	if err := m.runGitCommand(ctx, args...); err != nil {
-		return fmt.Errorf("failed to create worktree: %w", err)
+	return nil
+}
+
+// CreateShared creates a worktree for branch although another worktree has it
+// checked out, as 'git worktree add --force' allows. Commits made in either
+// worktree move the branch under the other, which then shows them as changes
+// to revert, so this is best kept for reading or building the branch.
+func (m *Manager) CreateShared(ctx context.Context, branch string, force bool) error {
+	worktreePath := m.WorktreePath(branch)
+
+	if !force {
+		if _, err := os.Stat(worktreePath); err == nil {
+			return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+		}
+	}
+
+	if err := os.MkdirAll(m.worktreeDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	// The branch exists already, so only the worktree is rolled back
+	undo := m.beginCreate(ctx, worktreePath, "")
+	if err := m.addWorktree(ctx, worktreePath, sharedArgs(branch, worktreePath)...); err != nil {
+		return m.abortCreate(ctx, undo, worktreePath, fmt.Errorf("failed to create worktree: %w", err))
+	}
+
+	if err := m.finishCreate(ctx, worktreePath, branch, branch); err != nil {
+		return m.abortCreate(ctx, undo, worktreePath, err)
+	}
+	return nil
+}
+
+// createArgs returns the arguments of 'git worktree add' for Create.
+func createArgs(branch, path, base string) []string {
+	return []string{"-b", branch, path, "origin/" + base}
+}
+
+// sharedArgs returns the arguments of 'git worktree add' for CreateShared.
+func sharedArgs(branch, path string) []string {
+	return []string{"--force", path, branch}
+}
+
+// createAtArgs returns the arguments of 'git worktree add' for CreateAt.
+func createAtArgs(name, path, rev string, detach bool) []string {
+	if detach {
+		return []string{"--detach", path, rev}
+	}
+	return []string{"-b", name, path, rev}
+}
+
+// finishCreate performs the non-fatal steps shared by all ways of creating a worktree
+// and announces the new worktree to subscribers. branch is empty for a detached HEAD.
+// With rollback enabled, a failing setup step or a cancelled ctx is fatal instead:
+// the error is returned for the caller to roll back.
+func (m *Manager) finishCreate(ctx context.Context, worktreePath, branch, base string) error {
+	template, err := m.templateFor(branch)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: failed to resolve the worktree template: %v\n", err)
+	}
+
+	if err := m.completeCheckout(ctx, worktreePath); err != nil {
+		fmt.Printf("⚠️  Warning: %v\n", err)
 	}
 
 	// Copy configuration files
-	if err := m.copyConfigFiles(worktreePath); err != nil {
+	done := m.timeOp("fs copy templates", worktreePath)
+	err = m.copyConfigFiles(worktreePath, template.Files)
+	done(err)
+	if err != nil {
 		// This is not a fatal error, just log a warning
 		fmt.Printf("⚠️  Warning: failed to copy config files: %v\n", err)
 	}
 
+	if err := m.updateMetadata(worktreePath, func(meta *Metadata) { meta.Base = base }); err != nil {
+		fmt.Printf("⚠️  Warning: failed to record worktree metadata: %v\n", err)
+	}
+
+	done = m.timeOp("fs link shared paths", worktreePath)
+	err = m.createLinks(worktreePath, template.Links)
+	done(err)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: failed to link shared paths: %v\n", err)
+	}
+
+	if err := m.runSetup(ctx, worktreePath, branch, template.Setup); err != nil {
+		if m.rollback {
+			return err
+		}
+		fmt.Printf("⚠️  Warning: %v\n", err)
+	}
+
+	// After setup, which may generate the hooks directory, as npm install does with husky
+	if m.checkout.Hooks {
+		m.syncNewHooks(ctx, worktreePath)
+	}
+	if m.rollback && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	m.publish(WorktreeCreated{Time: time.Now(), Path: worktreePath, Branch: branch, Base: base})
 	return nil
 }
 
-// Remove removes a worktree and optionally its branch.
-func (m *Manager) Remove(ctx context.Context, branchName string, force, keepBranch bool) error {
-	worktreePath := filepath.Join(m.worktreeDir, branchName)
+// RemoveOptions configures Remove.
+type RemoveOptions struct {
+	// Name is the name of the worktree, i.e. its directory in the worktree directory.
+	Name string
 
-	if !force {
-		if !m.confirmRemoval(branchName, worktreePath) {
-			return errors.ErrOperationCancelled
+	// Force removes the worktree even if it has uncommitted changes, which are lost.
+	// Without it, Remove fails with ErrDirty for such a worktree.
+	Force bool
+
+	// KeepBranch keeps the branch of the worktree instead of deleting it.
+	// A worktree with a detached HEAD has no branch, so it is implied for it.
+	KeepBranch bool
+
+	// Confirm, if set, is asked before anything is removed. Remove fails with
+	// ErrCancelled if it returns false.
+	Confirm func(name, path string) bool
+}
+
+// Remove removes a worktree and, unless opts.KeepBranch is set, its branch.
+// Locked worktrees are never removed; Remove fails with ErrWorktreeLocked for them.
+func (m *Manager) Remove(ctx context.Context, opts RemoveOptions) error {
+	worktreePath := m.WorktreePath(opts.Name)
+
+	wt, err := m.findRegistered(ctx, worktreePath)
+	if err != nil {
+		return err
+	}
+	if wt == nil {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, worktreePath)
+	}
+	if wt.Locked {
+		return lockedError(wt)
+	}
+	detached := wt.Detached
+	// The name may differ from the branch, e.g. for a namespaced branch
+	branchName := wt.Branch
+
+	force, err := m.checkRemovable(ctx, worktreePath, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	if opts.Confirm != nil && !opts.Confirm(opts.Name, worktreePath) {
+		return errors.ErrOperationCancelled
+	}
+
+	// git refuses to remove worktrees with submodules checked out
+	store, err := m.loadMetadata()
+	if err != nil {
+		return err
+	}
+	if meta, ok := store[worktreePath]; ok && len(meta.Submodules) > 0 {
+		if err := m.removeSubmoduleWorktrees(ctx, worktreePath, wt.Branch, meta.Submodules, opts.Force, opts.KeepBranch); err != nil {
+			return err
 		}
 	}
 
 	// Remove the worktree
-	if err := m.runGitCommand(ctx, "worktree", "remove", worktreePath); err != nil {
-		// Try with force flag
-		if err := m.runGitCommand(ctx, "worktree", "remove", "--force", worktreePath); err != nil {
-			return fmt.Errorf("failed to remove worktree: %w", err)
-		}
+	done := m.step("Removing " + opts.Name)
+	if err := m.runGitCommand(ctx, removeArgs(worktreePath, force)...); err != nil {
+		done(err)
+		return fmt.Errorf("failed to remove worktree: %w", err)
+	}
+	done(nil)
+
+	if err := m.deleteMetadata(worktreePath); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update worktree metadata: %v\n", err)
+	}
+	if err := m.moveLabels(worktreePath, ""); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update worktree labels: %v\n", err)
 	}
 
 	// Remove the branch if requested
-	if !keepBranch {
+	branchDeleted := false
+	if !opts.KeepBranch && !detached {
 		if err := m.runGitCommand(ctx, "branch", "-D", branchName); err != nil {
 			fmt.Printf("⚠️  Warning: failed to delete branch '%s': %v\n", branchName, err)
+		} else {
+			branchDeleted = true
 		}
 	}
 
+	removed := WorktreeRemoved{Time: time.Now(), Path: worktreePath, BranchDeleted: branchDeleted}
+	if !detached {
+		removed.Branch = branchName
+	}
+	m.publish(removed)
+
 	return nil
 }
 
+// checkRemovable fails with ErrWorktreeDirty if the worktree at path, or a
+// repository nested in it, has uncommitted changes, or if a nested repository
+// has unpushed commits, unless force is set. It returns whether git must be
+// forced to remove the worktree: git refuses to remove a worktree with
+// submodules checked out without --force, even when, as checked here, nothing
+// in them would be lost.
+func (m *Manager) checkRemovable(ctx context.Context, path string, force bool) (bool, error) {
+	if force {
+		return true, nil
+	}
+	// A worktree whose directory is gone cannot be checked, and has nothing to lose
+	dirty, err := m.hasChanges(ctx, path)
+	if err != nil {
+		return false, nil
+	}
+	if dirty {
+		return false, fmt.Errorf("%w: %s", errors.ErrWorktreeDirty, path)
+	}
+	nested, err := m.NestedChanges(ctx, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check nested repositories: %w", err)
+	}
+	if len(nested) > 0 {
+		paths := make([]string, len(nested))
+		for i, change := range nested {
+			paths[i] = change.Path
+		}
+		return false, fmt.Errorf("%w: %s has unsaved work in nested repositories: %s", errors.ErrWorktreeDirty, path, strings.Join(paths, ", "))
+	}
+	return hasSubmodules(path), nil
+}
+
+// removeArgs returns the git command removing the worktree at path.
+func removeArgs(path string, force bool) []string {
+	if force {
+		return []string{"worktree", "remove", "--force", path}
+	}
+	return []string{"worktree", "remove", path}
+}
+
 // GetMergedBranches returns a list of branches that have been merged.
 func (m *Manager) GetMergedBranches(ctx context.Context) ([]string, error) {
 	// Try main first, then master
 	for _, mainBranch := range []string{"main", "master"} {
-		cmd := exec.CommandContext(ctx, "git", "branch", "--merged", fmt.Sprintf("origin/%s", mainBranch))
-		cmd.Dir = m.repoRoot
-		output, err := cmd.Output()
+		output, err := m.gitOutput(ctx, m.repoRoot, "branch", "--merged", fmt.Sprintf("origin/%s", mainBranch))
 		if err != nil {
 			continue
 		}
 
-		return m.parseBranchList(string(output)), nil
+		return m.parseBranchList(output), nil
 	}
 
 	return nil, fmt.Errorf("failed to determine merged branches: no main/master branch found")
 }
 
+// Branches returns the local branches followed by the remote-tracking ones,
+// each most recently committed to first.
+func (m *Manager) Branches(ctx context.Context) ([]string, error) {
+	output, err := m.gitOutput(ctx, m.repoRoot, "for-each-ref", "--sort=-committerdate", "--format=%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return parseBranches(output), nil
+}
+
+// parseBranches turns full ref names, one per line, into short branch names,
+// local branches first. Symbolic refs like origin/HEAD are skipped.
+func parseBranches(output string) []string {
+	var local, remote []string
+	for _, ref := range strings.Split(strings.TrimSpace(output), "\n") {
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			local = append(local, strings.TrimPrefix(ref, "refs/heads/"))
+		case strings.HasPrefix(ref, "refs/remotes/") && !strings.HasSuffix(ref, "/HEAD"):
+			remote = append(remote, strings.TrimPrefix(ref, "refs/remotes/"))
+		}
+	}
+	return append(local, remote...)
+}
+
+// SetTemplates replaces the files copied from the repository root into new worktrees.
+// Passing nil restores the default ConfigFiles.
+func (m *Manager) SetTemplates(files []string) {
+	m.templates = files
+}
+
+// RemoteURL returns the URL of the origin remote, or an empty string if there is none.
+func (m *Manager) RemoteURL(ctx context.Context) string {
+	output, err := m.gitOutput(ctx, m.repoRoot, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// SetIdentity records the git author identity in the repository configuration.
+// Empty values are left untouched.
+func (m *Manager) SetIdentity(ctx context.Context, name, email string) error {
+	if name != "" {
+		if err := m.runGitCommand(ctx, "config", "user.name", name); err != nil {
+			return fmt.Errorf("failed to set user.name: %w", err)
+		}
+	}
+	if email != "" {
+		if err := m.runGitCommand(ctx, "config", "user.email", email); err != nil {
+			return fmt.Errorf("failed to set user.email: %w", err)
+		}
+	}
+	return nil
+}
+
 // GetRepoInfo extracts GitHub repository information from Git remote.
 func (m *Manager) GetRepoInfo() (owner, repo string, err error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	cmd.Dir = m.repoRoot
-	output, err := cmd.Output()
+	output, err := m.gitOutput(context.Background(), m.repoRoot, "remote", "get-url", "origin")
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get remote URL: %w", err)
 	}
 
-	remoteURL := strings.TrimSpace(string(output))
+	remoteURL := strings.TrimSpace(output)
 	owner, repo = parseGitHubURL(remoteURL)
 
 	if owner == "" || repo == "" {
@@ -176,7 +958,7 @@ func (m *Manager) GetRepoInfo() (owner, repo string, err error) {
 }
 
 // parseWorktreeList parses the output of 'git worktree list --porcelain'.
-func (m *Manager) parseWorktreeList(output string) ([]*Worktree, error) {
+func parseWorktreeList(output string) ([]*Worktree, error) {
 	var worktrees []*Worktree
 	lines := strings.Split(output, "\n")
 
@@ -192,7 +974,8 @@ func (m *Manager) parseWorktreeList(output string) ([]*Worktree, error) {
 		}
 
 		if strings.HasPrefix(line, "worktree ") {
-			path := strings.TrimPrefix(line, "worktree ")
+			// git reports C:/like/this on Windows
+			path := filepath.FromSlash(strings.TrimPrefix(line, "worktree "))
 			current = &Worktree{Path: path}
 		} else if strings.HasPrefix(line, "branch ") && current != nil {
 			branch := strings.TrimPrefix(line, "branch ")
@@ -201,7 +984,18 @@ func (m *Manager) parseWorktreeList(output string) ([]*Worktree, error) {
 			}
 			current.Branch = branch
 		} else if strings.HasPrefix(line, "HEAD ") && current != nil {
+			current.Head = strings.TrimPrefix(line, "HEAD ")
 			current.Branch = "HEAD"
+		} else if line == "detached" && current != nil {
+			current.Detached = true
+		} else if (line == "locked" || strings.HasPrefix(line, "locked ")) && current != nil {
+			current.Locked = true
+			current.LockReason = strings.TrimSpace(strings.TrimPrefix(line, "locked"))
+		} else if strings.HasPrefix(line, "prunable") && current != nil {
+			current.Prunable = true
+		} else if line == "bare" {
+			// A bare repository has no files to work on
+			current = nil
 		}
 	}
 
@@ -214,41 +1008,49 @@ func (m *Manager) parseWorktreeList(output string) ([]*Worktree, error) {
 
 // enrichWorktree adds status information to a worktree.
 func (m *Manager) enrichWorktree(ctx context.Context, wt *Worktree) error {
-	wt.IsMain = wt.Path == m.repoRoot
+	wt.IsMain = wt.Path == m.mainRoot
 
-	if err := m.getGitStatus(ctx, wt); err != nil {
+	changed, err := m.getGitStatus(ctx, wt)
+	if err != nil {
 		return err
 	}
 
 	if err := m.getCommitInfo(ctx, wt); err != nil {
 		return err
 	}
+	getActivity(wt, changed)
 
 	if err := m.getRemoteStatus(ctx, wt); err != nil {
 		return err
 	}
 
+	wt.StatusPending = false
 	return nil
 }
 
-// getGitStatus populates the status fields of a worktree.
-func (m *Manager) getGitStatus(ctx context.Context, wt *Worktree) error {
-	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
-	cmd.Dir = wt.Path
-	output, err := cmd.Output()
+// getGitStatus populates the status fields of a worktree and returns the
+// paths of its changed files.
+func (m *Manager) getGitStatus(ctx context.Context, wt *Worktree) ([]string, error) {
+	output, err := m.gitOutput(ctx, wt.Path, "status", "--porcelain")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	statusOutput := strings.TrimSpace(string(output))
+	// Leading spaces are part of the status of the first file
+	statusOutput := strings.TrimRight(output, "\n")
 	wt.IsClean = len(statusOutput) == 0
+	wt.Added, wt.Modified, wt.Deleted = 0, 0, 0
 
+	var changed []string
 	if !wt.IsClean {
 		lines := strings.Split(statusOutput, "\n")
 		for _, line := range lines {
 			if len(line) < 2 {
 				continue
 			}
+			if path := statusPath(line); path != "" {
+				changed = append(changed, path)
+			}
 
 			status := line[:2]
 			switch {
@@ -262,19 +1064,17 @@ func (m *Manager) getGitStatus(ctx context.Context, wt *Worktree) error {
 		}
 	}
 
-	return nil
+	return changed, nil
 }
 
 // getCommitInfo populates commit-related fields of a worktree.
 func (m *Manager) getCommitInfo(ctx context.Context, wt *Worktree) error {
-	cmd := exec.CommandContext(ctx, "git", "log", "-1", "--format=%s|%ct")
-	cmd.Dir = wt.Path
-	output, err := cmd.Output()
+	output, err := m.gitOutput(ctx, wt.Path, "log", "-1", "--format=%s|%ct")
 	if err != nil {
 		return err
 	}
 
-	parts := strings.Split(strings.TrimSpace(string(output)), "|")
+	parts := strings.Split(strings.TrimSpace(output), "|")
 	if len(parts) >= 2 {
 		wt.LastCommit = parts[0]
 		if timestamp, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
@@ -292,16 +1092,19 @@ func (m *Manager) getRemoteStatus(ctx context.Context, wt *Worktree) error {
 		return nil
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "rev-list", "--count", "--left-right",
-		fmt.Sprintf("origin/%s...HEAD", wt.Branch))
-	cmd.Dir = wt.Path
-	output, err := cmd.Output()
+	upstream := fmt.Sprintf("origin/%s", wt.Branch)
+	if name, err := m.gitOutput(ctx, wt.Path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		upstream = strings.TrimSpace(name)
+	}
+
+	output, err := m.gitOutput(ctx, wt.Path, "rev-list", "--count", "--left-right", upstream+"...HEAD")
 	if err != nil {
 		// Not an error if remote branch doesn't exist
 		return nil
 	}
 
-	parts := strings.Fields(strings.TrimSpace(string(output)))
+	wt.Upstream = upstream
+	parts := strings.Fields(strings.TrimSpace(output))
 	if len(parts) >= 2 {
 		if behind, err := strconv.Atoi(parts[0]); err == nil {
 			wt.Behind = behind
@@ -314,25 +1117,35 @@ func (m *Manager) getRemoteStatus(ctx context.Context, wt *Worktree) error {
 	return nil
 }
 
+// lockedError returns an error explaining that the worktree is locked.
+func lockedError(wt *Worktree) error {
+	if wt.LockReason != "" {
+		return fmt.Errorf("%w: %s (reason: %s)", errors.ErrWorktreeLocked, wt.Path, wt.LockReason)
+	}
+	return fmt.Errorf("%w: %s", errors.ErrWorktreeLocked, wt.Path)
+}
+
 // runGitCommand runs a git command in the repository root.
 func (m *Manager) runGitCommand(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = m.repoRoot
-	if err := cmd.Run(); err != nil {
+	if err := m.runGit(ctx, GitCommand{Dir: m.repoRoot, Args: args}); err != nil {
 		return errors.NewGitError(args[0], args[1:], err)
 	}
 	return nil
 }
 
-// copyConfigFiles copies configuration files to the new worktree.
-func (m *Manager) copyConfigFiles(destPath string) error {
-	for _, file := range ConfigFiles {
+// copyConfigFiles copies files from the repository root to the new worktree.
+// Files missing in the repository root are skipped.
+func (m *Manager) copyConfigFiles(destPath string, files []string) error {
+	for _, file := range files {
 		srcPath := filepath.Join(m.repoRoot, file)
 		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
 			continue
 		}
 
 		destFilePath := filepath.Join(destPath, file)
+		if err := os.MkdirAll(filepath.Dir(destFilePath), 0o755); err != nil {
+			return err
+		}
 		if err := copyFile(srcPath, destFilePath); err != nil {
 			return err
 		}
@@ -340,21 +1153,14 @@ func (m *Manager) copyConfigFiles(destPath string) error {
 	return nil
 }
 
-// confirmRemoval prompts the user for confirmation.
-func (m *Manager) confirmRemoval(branchName, worktreePath string) bool {
-	fmt.Printf("Remove worktree '%s' at %s? [y/N]: ", branchName, worktreePath)
-	reader := bufio.NewReader(os.Stdin)
-	response, _ := reader.ReadString('\n')
-	return strings.ToLower(strings.TrimSpace(response)) == "y"
-}
-
 // parseBranchList parses the output of 'git branch --merged'.
 func (m *Manager) parseBranchList(output string) []string {
 	var branches []string
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
 		branch := strings.TrimSpace(line)
-		branch = strings.TrimPrefix(branch, "* ")
+		// * marks the current branch and + those checked out in other worktrees
+		branch = strings.TrimPrefix(strings.TrimPrefix(branch, "* "), "+ ")
 		if branch != "" && !isProtectedBranch(branch) {
 			branches = append(branches, branch)
 		}
@@ -366,46 +1172,57 @@ func (m *Manager) parseBranchList(output string) []string {
 
 // GetCurrentBranch returns the current branch name.
 func (m *Manager) GetCurrentBranch(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = m.repoRoot
-	output, err := cmd.Output()
+	output, err := m.gitOutput(ctx, m.repoRoot, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
-	
-	branch := strings.TrimSpace(string(output))
+
+	branch := strings.TrimSpace(output)
+	if branch == "HEAD" && m.JJColocated() && m.repoRoot == m.JJWorkspace() {
+		// jj keeps HEAD detached at the commit of its current bookmark
+		if worktrees, err := m.listRegistered(ctx); err == nil {
+			if bookmark := m.jjBookmark(ctx, worktrees); bookmark != "" {
+				return bookmark, nil
+			}
+		}
+	}
 	if branch == "HEAD" {
 		// We're in detached HEAD state, try to get symbolic name
-		cmd = exec.CommandContext(ctx, "git", "describe", "--contains", "--all", "HEAD")
-		cmd.Dir = m.repoRoot
-		output, err = cmd.Output()
+		output, err = m.gitOutput(ctx, m.repoRoot, "describe", "--contains", "--all", "HEAD")
 		if err != nil {
-			return "", fmt.Errorf("in detached HEAD state and cannot determine branch")
+			return "", fmt.Errorf("%w: cannot determine the current branch", errors.ErrDetachedHead)
 		}
-		branch = strings.TrimSpace(string(output))
+		branch = strings.TrimSpace(output)
 		// Remove refs/heads/ prefix if present
 		if strings.HasPrefix(branch, "heads/") {
 			branch = strings.TrimPrefix(branch, "heads/")
 		}
 	}
-	
+
 	return branch, nil
 }
 
-// getGitRoot returns the root directory of the Git repository.
-func getGitRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+// copyFile copies a file from src to dst, keeping its permissions.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
 	if err != nil {
-		return "", err
+		return err
 	}
-	return strings.TrimSpace(string(output)), nil
-}
+	defer in.Close()
 
-// copyFile copies a file from src to dst.
-func copyFile(src, dst string) error {
-	cmd := exec.Command("cp", src, dst)
-	return cmd.Run()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
 }
 
 // formatTimeAgo formats a time duration as a human-readable string.
@@ -421,9 +1238,12 @@ func formatTimeAgo(t time.Time) string {
 	case duration < 24*time.Hour:
 		hours := int(duration.Hours())
 		return fmt.Sprintf("%dh ago", hours)
-	default:
+	case duration < 14*24*time.Hour:
 		days := int(duration.Hours() / 24)
 		return fmt.Sprintf("%dd ago", days)
+	default:
+		weeks := int(duration.Hours() / (24 * 7))
+		return fmt.Sprintf("%dw ago", weeks)
 	}
 }
 
@@ -0,0 +1,234 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	for name, tt := range map[string]struct {
+		output   string
+		expected []*Worktree
+	}{
+		"branch worktree": {
+			output: "worktree /repo\nHEAD 1111\nbranch refs/heads/main\n\n",
+			expected: []*Worktree{
+				{Path: "/repo", Branch: "main", Head: "1111"},
+			},
+		},
+		"detached worktree": {
+			output: "worktree /repo/.worktree/bisect\nHEAD 2222\ndetached\n\n",
+			expected: []*Worktree{
+				{Path: "/repo/.worktree/bisect", Branch: "HEAD", Head: "2222", Detached: true},
+			},
+		},
+		"locked worktree with reason": {
+			output: "worktree /repo/.worktree/feature\nHEAD 3333\nbranch refs/heads/feature\nlocked on usb drive\n\n",
+			expected: []*Worktree{
+				{Path: "/repo/.worktree/feature", Branch: "feature", Head: "3333", Locked: true, LockReason: "on usb drive"},
+			},
+		},
+		"bare repository": {
+			output: "worktree /src/app.git\nbare\n\nworktree /src/app.worktree/feature\nHEAD 4444\nbranch refs/heads/feature\n\n",
+			expected: []*Worktree{
+				{Path: "/src/app.worktree/feature", Branch: "feature", Head: "4444"},
+			},
+		},
+		"locked worktree without reason": {
+			output: "worktree /repo/.worktree/feature\nHEAD 3333\nbranch refs/heads/feature\nlocked\n",
+			expected: []*Worktree{
+				{Path: "/repo/.worktree/feature", Branch: "feature", Head: "3333", Locked: true},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			worktrees, err := parseWorktreeList(tt.output)
+			if err != nil {
+				t.Fatalf("parseWorktreeList failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, worktrees); diff != "" {
+				t.Errorf("parseWorktreeList mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseBranches(t *testing.T) {
+	for name, tt := range map[string]struct {
+		output   string
+		expected []string
+	}{
+		"local before remote": {
+			output:   "refs/remotes/origin/main\nrefs/heads/feat\nrefs/remotes/origin/HEAD\nrefs/heads/main\nrefs/remotes/upstream/release/1.2\n",
+			expected: []string{"feat", "main", "origin/main", "upstream/release/1.2"},
+		},
+		"no branches": {
+			output: "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, parseBranches(tt.output)); diff != "" {
+				t.Errorf("parseBranches() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeBackend is a GitBackend with a fixed set of worktrees that records the
+// commands it is asked to run.
+type fakeBackend struct {
+	mu        sync.Mutex
+	worktrees []*Worktree
+	commands  [][]string
+}
+
+// Run implements GitBackend.
+func (b *fakeBackend) Run(ctx context.Context, cmd GitCommand) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.commands = append(b.commands, cmd.Args)
+	return nil
+}
+
+// ListWorktrees implements GitBackend.
+func (b *fakeBackend) ListWorktrees(ctx context.Context, repoRoot, gitCommonDir string) ([]*Worktree, error) {
+	worktrees := make([]*Worktree, len(b.worktrees))
+	for i, wt := range b.worktrees {
+		copied := *wt
+		worktrees[i] = &copied
+	}
+	return worktrees, nil
+}
+
+// ResolveCommit implements GitBackend.
+func (b *fakeBackend) ResolveCommit(ctx context.Context, dir, ref string) (string, bool) {
+	return "", false
+}
+
+func TestRemoveDeletesCheckedOutBranch(t *testing.T) {
+	for name, tt := range map[string]struct {
+		worktree *Worktree
+		opts     RemoveOptions
+		expected [][]string
+		removed  WorktreeRemoved
+	}{
+		"branch named after the worktree": {
+			worktree: &Worktree{Path: "/repo/.worktree/feature-x", Branch: "feature-x"},
+			opts:     RemoveOptions{Name: "feature-x", Force: true},
+			expected: [][]string{
+				{"worktree", "remove", "--force", "/repo/.worktree/feature-x"},
+				{"branch", "-D", "feature-x"},
+			},
+			removed: WorktreeRemoved{Path: "/repo/.worktree/feature-x", Branch: "feature-x", BranchDeleted: true},
+		},
+		"namespaced branch": {
+			worktree: &Worktree{Path: "/repo/.worktree/feature-x", Branch: "me/feature-x"},
+			opts:     RemoveOptions{Name: "feature-x", Force: true},
+			expected: [][]string{
+				{"worktree", "remove", "--force", "/repo/.worktree/feature-x"},
+				{"branch", "-D", "me/feature-x"},
+			},
+			removed: WorktreeRemoved{Path: "/repo/.worktree/feature-x", Branch: "me/feature-x", BranchDeleted: true},
+		},
+		"kept branch": {
+			worktree: &Worktree{Path: "/repo/.worktree/feature-x", Branch: "me/feature-x"},
+			opts:     RemoveOptions{Name: "feature-x", Force: true, KeepBranch: true},
+			expected: [][]string{
+				{"worktree", "remove", "--force", "/repo/.worktree/feature-x"},
+			},
+			removed: WorktreeRemoved{Path: "/repo/.worktree/feature-x", Branch: "me/feature-x"},
+		},
+		"detached HEAD": {
+			worktree: &Worktree{Path: "/repo/.worktree/bisect", Branch: "HEAD", Detached: true},
+			opts:     RemoveOptions{Name: "bisect", Force: true},
+			expected: [][]string{
+				{"worktree", "remove", "--force", "/repo/.worktree/bisect"},
+			},
+			removed: WorktreeRemoved{Path: "/repo/.worktree/bisect"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			backend := &fakeBackend{worktrees: []*Worktree{
+				{Path: "/repo", Branch: "main", IsMain: true},
+				tt.worktree,
+			}}
+			m := &Manager{repoRoot: "/repo", gitCommonDir: t.TempDir(), worktreeDir: "/repo/.worktree", backend: backend}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			events := make(chan Event, 1)
+			m.Subscribe(ctx, func(ev Event) { events <- ev })
+
+			if err := m.Remove(ctx, tt.opts); err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, backend.commands); diff != "" {
+				t.Errorf("Remove() commands mismatch (-want +got):\n%s", diff)
+			}
+
+			select {
+			case ev := <-events:
+				if diff := cmp.Diff(tt.removed, ev, cmpopts.IgnoreFields(WorktreeRemoved{}, "Time")); diff != "" {
+					t.Errorf("Remove() event mismatch (-want +got):\n%s", diff)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the removal event")
+			}
+		})
+	}
+}
+
+func TestFindRegisteredThroughSymlink(t *testing.T) {
+	t.Parallel()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(dir, "real")
+	if err := os.MkdirAll(filepath.Join(real, "wts", "feat"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &fakeBackend{worktrees: []*Worktree{{Path: filepath.Join(real, "wts", "feat"), Branch: "feat"}}}
+	m := &Manager{backend: backend}
+	m.SetWorktreeDir(filepath.Join(link, "wts"))
+
+	for name, path := range map[string]string{
+		"worktree path":  m.WorktreePath("feat"),
+		"symlinked path": filepath.Join(link, "wts", "feat"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt, err := m.findRegistered(context.Background(), path)
+			if err != nil {
+				t.Fatalf("findRegistered() error = %v", err)
+			}
+			if wt == nil {
+				t.Fatalf("findRegistered(%q) found nothing", path)
+			}
+			if diff := cmp.Diff("feat", wt.Branch); diff != "" {
+				t.Errorf("findRegistered() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
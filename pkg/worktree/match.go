@@ -0,0 +1,129 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Match resolves filter to a single worktree without prompting.
+// A worktree whose branch, repository-qualified name or path equals filter wins;
+// otherwise filter must match exactly one worktree by branch substring, ignoring case.
+// It fails with ErrWorktreeNotFound or ErrWorktreeAmbiguous.
+func Match(worktrees []*Worktree, filter string) (*Worktree, error) {
+	candidates := Candidates(worktrees, filter)
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("%w: no worktree matches %q", errors.ErrWorktreeNotFound, filter)
+	case 1:
+		return candidates[0], nil
+	}
+
+	names := make([]string, 0, len(candidates))
+	for _, wt := range candidates {
+		names = append(names, qualifiedName(wt))
+	}
+	return nil, fmt.Errorf("%w: %q matches %s", errors.ErrWorktreeAmbiguous, filter, strings.Join(names, ", "))
+}
+
+// Candidates returns the worktrees Match chooses from: those whose branch,
+// repository-qualified name or path equals filter if there are any, and
+// otherwise those whose branch contains filter, ignoring case.
+func Candidates(worktrees []*Worktree, filter string) []*Worktree {
+	path := filter
+	if abs, err := filepath.Abs(filter); err == nil && filter != "" {
+		path = abs
+	}
+
+	var exact []*Worktree
+	for _, wt := range worktrees {
+		if wt.Branch == filter || qualifiedName(wt) == filter || wt.Path == path {
+			exact = append(exact, wt)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return FilterByBranch(worktrees, filter)
+}
+
+// MatchPath resolves filter to the worktree at exactly that path, relative paths
+// being taken from the current directory. Unlike Match it never guesses from
+// branch names, for callers that must act only on what they were told.
+func MatchPath(worktrees []*Worktree, filter string) (*Worktree, error) {
+	if filter == "" {
+		return nil, fmt.Errorf("%w: a worktree path is required", errors.ErrWorktreeNotFound)
+	}
+	path, err := filepath.Abs(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	for _, wt := range worktrees {
+		if wt.Path == path {
+			return wt, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no worktree at %s", errors.ErrWorktreeNotFound, path)
+}
+
+// FilterByBranch returns the worktrees whose branch contains filter, ignoring case.
+func FilterByBranch(worktrees []*Worktree, filter string) []*Worktree {
+	if filter == "" {
+		return worktrees
+	}
+
+	filter = strings.ToLower(filter)
+	var filtered []*Worktree
+	for _, wt := range worktrees {
+		if strings.Contains(strings.ToLower(wt.Branch), filter) {
+			filtered = append(filtered, wt)
+		}
+	}
+	return filtered
+}
+
+// FilterByLabel returns the worktrees carrying label, or all of them for an empty label.
+func FilterByLabel(worktrees []*Worktree, label string) []*Worktree {
+	if label == "" {
+		return worktrees
+	}
+
+	var filtered []*Worktree
+	for _, wt := range worktrees {
+		if slices.Contains(wt.Labels, label) {
+			filtered = append(filtered, wt)
+		}
+	}
+	return filtered
+}
+
+// Resolve finds the worktree to switch to for filter, as Match does, and
+// publishes SwitchResolved so that subscribers can follow the user around.
+func (m *Manager) Resolve(ctx context.Context, filter string) (*Worktree, error) {
+	worktrees, err := m.List(ctx, ListOptions{WithStatus: true})
+	if err != nil {
+		return nil, err
+	}
+
+	wt, err := Match(worktrees, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	m.publish(SwitchResolved{Time: time.Now(), Filter: filter, Worktree: wt})
+	return wt, nil
+}
+
+// qualifiedName returns the branch, prefixed by the repository name in cross-repo mode.
+func qualifiedName(wt *Worktree) string {
+	if wt.Repo != "" {
+		return wt.Repo + "/" + wt.Branch
+	}
+	return wt.Branch
+}
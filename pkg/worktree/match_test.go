@@ -0,0 +1,145 @@
+package worktree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	giwoerrors "github.com/knwoop/giwo/internal/errors"
+)
+
+func TestMatch(t *testing.T) {
+	worktrees := []*Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+		{Branch: "feature-login", Path: "/repo/.worktree/feature-login"},
+		{Branch: "auth", Path: "/repo/.worktree/auth"},
+	}
+
+	for name, tt := range map[string]struct {
+		filter   string
+		expected string
+		wantErr  error
+	}{
+		"exact branch wins over substrings": {
+			filter:   "auth",
+			expected: "auth",
+		},
+		"unique substring": {
+			filter:   "LOGIN",
+			expected: "feature-login",
+		},
+		"path": {
+			filter:   "/repo/.worktree/feature-auth",
+			expected: "feature-auth",
+		},
+		"ambiguous": {
+			filter:  "feature",
+			wantErr: giwoerrors.ErrWorktreeAmbiguous,
+		},
+		"not found": {
+			filter:  "nothing",
+			wantErr: giwoerrors.ErrWorktreeNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt, err := Match(worktrees, tt.filter)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Match error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Match unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, wt.Branch); diff != "" {
+				t.Errorf("Match mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	worktrees := []*Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "feature-auth", Path: "/repo/.worktree/feature-auth"},
+		{Branch: "feature-login", Path: "/repo/.worktree/feature-login"},
+		{Branch: "auth", Path: "/repo/.worktree/auth"},
+	}
+
+	for name, tt := range map[string]struct {
+		filter   string
+		expected []string
+	}{
+		"exact branch only":   {filter: "auth", expected: []string{"auth"}},
+		"substrings":          {filter: "feature", expected: []string{"feature-auth", "feature-login"}},
+		"substrings any case": {filter: "IN", expected: []string{"main", "feature-login"}},
+		"none":                {filter: "nothing"},
+		"path of a worktree":  {filter: "/repo/.worktree/feature-login", expected: []string{"feature-login"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, wt := range Candidates(worktrees, tt.filter) {
+				got = append(got, wt.Branch)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("Candidates mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	worktrees := []*Worktree{
+		{Branch: "main", Path: "/repo", IsMain: true},
+		{Branch: "auth", Path: "/repo/.worktree/auth"},
+	}
+
+	for name, tt := range map[string]struct {
+		filter   string
+		expected string
+		wantErr  error
+	}{
+		"path": {
+			filter:   "/repo/.worktree/auth",
+			expected: "auth",
+		},
+		"uncleaned path": {
+			filter:   "/repo/.worktree/../.worktree/auth/",
+			expected: "auth",
+		},
+		"branch names are not matched": {
+			filter:  "auth",
+			wantErr: giwoerrors.ErrWorktreeNotFound,
+		},
+		"empty": {
+			filter:  "",
+			wantErr: giwoerrors.ErrWorktreeNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wt, err := MatchPath(worktrees, tt.filter)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("MatchPath error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MatchPath unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, wt.Branch); diff != "" {
+				t.Errorf("MatchPath mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
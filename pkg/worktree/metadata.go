@@ -0,0 +1,175 @@
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// metadataFileName is the name of the file holding giwo-specific worktree metadata.
+const metadataFileName = "worktrees.json"
+
+// KindMaintenance marks worktrees created from a release tag for maintenance work.
+const KindMaintenance = "maintenance"
+
+// Metadata holds giwo-specific information recorded for a worktree.
+// Git itself has no place to store it, so it lives under the common git directory.
+type Metadata struct {
+	Base      string    `json:"base,omitempty"`
+	Kind      string    `json:"kind,omitempty"`
+	Ephemeral bool      `json:"ephemeral,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// PortOffset is the port offset allocated to the worktree, 0 if none was yet.
+	PortOffset int `json:"port_offset,omitempty"`
+
+	// Submodules are the submodule paths checked out as worktrees of the
+	// submodules' repositories by CreateWithSubmodule.
+	Submodules []string `json:"submodules,omitempty"`
+
+	// Template is the key of the template override the worktree was set up
+	// with, such as with 'giwo create --template'.
+	Template string `json:"template,omitempty"`
+
+	// HeadRef is the branch pull requests of the worktree use as their head,
+	// recorded when the branch name differs from the worktree's name.
+	HeadRef string `json:"head_ref,omitempty"`
+
+	// Task describes the automated task the worktree was created for by StartTask.
+	Task *TaskInfo `json:"task,omitempty"`
+
+	// Links maps symlinks created in the worktree, relative to it, to their sources.
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// metadataStore is the on-disk representation of all recorded metadata, keyed by worktree path.
+type metadataStore map[string]*Metadata
+
+// StateDir returns the directory where giwo keeps its per-repository state.
+func (m *Manager) StateDir() string {
+	return filepath.Join(m.gitCommonDir, "giwo")
+}
+
+// loadMetadata reads the metadata store, returning an empty store if none exists yet.
+func (m *Manager) loadMetadata() (metadataStore, error) {
+	store := make(metadataStore)
+
+	data, err := os.ReadFile(filepath.Join(m.StateDir(), metadataFileName))
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	return store, nil
+}
+
+// saveMetadata writes the metadata store to disk.
+func (m *Manager) saveMetadata(store metadataStore) error {
+	if err := os.MkdirAll(m.StateDir(), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(m.StateDir(), metadataFileName), data, 0o644)
+}
+
+// updateMetadata applies fn to the metadata of the worktree at path and persists the result.
+func (m *Manager) updateMetadata(path string, fn func(*Metadata)) error {
+	m.metadataMu.Lock()
+	defer m.metadataMu.Unlock()
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	meta, ok := store[path]
+	if !ok {
+		meta = &Metadata{CreatedAt: time.Now()}
+		store[path] = meta
+	}
+	fn(meta)
+
+	return m.saveMetadata(store)
+}
+
+// deleteMetadata drops any metadata recorded for the worktree at path.
+func (m *Manager) deleteMetadata(path string) error {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := store[path]; !ok {
+		return nil
+	}
+	delete(store, path)
+
+	return m.saveMetadata(store)
+}
+
+// StaleMetadata returns the paths that have recorded metadata but are no longer registered with git.
+func (m *Manager) StaleMetadata(ctx context.Context) ([]string, error) {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		registered[wt.Path] = true
+	}
+
+	var stale []string
+	for path := range store {
+		if !registered[path] {
+			stale = append(stale, path)
+		}
+	}
+	sort.Strings(stale)
+
+	return stale, nil
+}
+
+// pruneMetadata drops metadata recorded for worktrees git no longer knows about.
+func (m *Manager) pruneMetadata(ctx context.Context) error {
+	stale, err := m.StaleMetadata(ctx)
+	if err != nil || len(stale) == 0 {
+		return err
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		delete(store, path)
+	}
+	return m.saveMetadata(store)
+}
+
+// MarkEphemeral flags the named worktree for automatic cleanup by 'giwo clean --ephemeral'.
+func (m *Manager) MarkEphemeral(name string) error {
+	return m.updateMetadata(m.WorktreePath(name), func(meta *Metadata) {
+		meta.Ephemeral = true
+	})
+}
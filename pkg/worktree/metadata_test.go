@@ -0,0 +1,79 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{gitCommonDir: t.TempDir(), worktreeDir: "/repo/.worktree"}
+
+	if err := m.updateMetadata(m.WorktreePath("bisect"), func(meta *Metadata) { meta.Base = "v1.2.0" }); err != nil {
+		t.Fatalf("updateMetadata failed: %v", err)
+	}
+	if err := m.MarkEphemeral("bisect"); err != nil {
+		t.Fatalf("MarkEphemeral failed: %v", err)
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		t.Fatalf("loadMetadata failed: %v", err)
+	}
+
+	meta, ok := store["/repo/.worktree/bisect"]
+	if !ok {
+		t.Fatal("expected metadata for /repo/.worktree/bisect")
+	}
+	if diff := cmp.Diff("v1.2.0", meta.Base); diff != "" {
+		t.Errorf("Base mismatch (-want +got):\n%s", diff)
+	}
+	if !meta.Ephemeral {
+		t.Error("expected worktree to be ephemeral")
+	}
+
+	if err := m.deleteMetadata("/repo/.worktree/bisect"); err != nil {
+		t.Fatalf("deleteMetadata failed: %v", err)
+	}
+	store, err = m.loadMetadata()
+	if err != nil {
+		t.Fatalf("loadMetadata failed: %v", err)
+	}
+	if diff := cmp.Diff(0, len(store)); diff != "" {
+		t.Errorf("store size mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRenameMetadata(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{gitCommonDir: t.TempDir(), worktreeDir: "/repo/.worktree"}
+	if err := m.updateMetadata(m.WorktreePath("old"), func(meta *Metadata) { meta.Base, meta.HeadRef = "main", "knwoop/old" }); err != nil {
+		t.Fatalf("updateMetadata failed: %v", err)
+	}
+
+	clearHeadRef := func(meta *Metadata) { meta.HeadRef = "" }
+	if err := m.renameMetadata(m.WorktreePath("old"), m.WorktreePath("feature/new"), clearHeadRef); err != nil {
+		t.Fatalf("renameMetadata failed: %v", err)
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		t.Fatalf("loadMetadata failed: %v", err)
+	}
+	if _, ok := store["/repo/.worktree/old"]; ok {
+		t.Error("expected metadata for the old path to be gone")
+	}
+	meta, ok := store["/repo/.worktree/feature/new"]
+	if !ok {
+		t.Fatal("expected metadata for /repo/.worktree/feature/new")
+	}
+	if diff := cmp.Diff("main", meta.Base); diff != "" {
+		t.Errorf("Base mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("", meta.HeadRef); diff != "" {
+		t.Errorf("HeadRef mismatch (-want +got):\n%s", diff)
+	}
+}
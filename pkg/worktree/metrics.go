@@ -0,0 +1,124 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Metrics counts the worktrees of a repository by state, for dashboards and
+// scripts that alert on worktrees piling up.
+type Metrics struct {
+	// Total counts all worktrees, the main one included.
+	Total int
+
+	// Dirty counts the worktrees with uncommitted changes.
+	Dirty int
+
+	// Ahead and Behind count the worktrees whose branch has commits its
+	// upstream does not have, and the other way around.
+	Ahead  int
+	Behind int
+
+	// Locked counts the locked worktrees, and Prunable those whose directory is gone.
+	Locked   int
+	Prunable int
+
+	// Stale counts the worktrees besides the main one without activity for the
+	// stale age, and StaleBytes is the disk space they use.
+	Stale      int
+	StaleBytes int64
+}
+
+// metric describes one value of Metrics for the output formats, under its
+// Prometheus and its shell variable name.
+type metric struct {
+	prom  string
+	env   string
+	help  string
+	value int64
+}
+
+// CollectMetrics counts worktrees by state. Worktrees besides the main one are
+// stale when their last activity is older than staleAfter at now; their disk
+// usage is measured, and written to their DiskUsage.
+func CollectMetrics(ctx context.Context, worktrees []*Worktree, staleAfter time.Duration, now time.Time) (Metrics, error) {
+	metrics := Metrics{Total: len(worktrees)}
+
+	var stale []*Worktree
+	for _, wt := range worktrees {
+		if !wt.IsClean {
+			metrics.Dirty++
+		}
+		if wt.Ahead > 0 {
+			metrics.Ahead++
+		}
+		if wt.Behind > 0 {
+			metrics.Behind++
+		}
+		if wt.Locked {
+			metrics.Locked++
+		}
+		if wt.Prunable {
+			metrics.Prunable++
+		}
+	}
+	for _, wt := range Inactive(worktrees, staleAfter, now) {
+		if !wt.IsMain && !wt.Prunable {
+			stale = append(stale, wt)
+		}
+	}
+	metrics.Stale = len(stale)
+
+	if err := MeasureDiskUsage(ctx, stale); err != nil {
+		return metrics, err
+	}
+	for _, wt := range stale {
+		metrics.StaleBytes += wt.DiskUsage
+	}
+	return metrics, nil
+}
+
+// list returns the values of the metrics in output order.
+func (m Metrics) list() []metric {
+	return []metric{
+		{"giwo_worktrees", "GIWO_WORKTREES", "Number of worktrees, the main one included.", int64(m.Total)},
+		{"giwo_worktrees_dirty", "GIWO_DIRTY", "Number of worktrees with uncommitted changes.", int64(m.Dirty)},
+		{"giwo_worktrees_ahead", "GIWO_AHEAD", "Number of worktrees with commits their upstream does not have.", int64(m.Ahead)},
+		{"giwo_worktrees_behind", "GIWO_BEHIND", "Number of worktrees behind their upstream.", int64(m.Behind)},
+		{"giwo_worktrees_locked", "GIWO_LOCKED", "Number of locked worktrees.", int64(m.Locked)},
+		{"giwo_worktrees_prunable", "GIWO_PRUNABLE", "Number of worktrees whose directory is gone.", int64(m.Prunable)},
+		{"giwo_worktrees_stale", "GIWO_STALE", "Number of worktrees without activity for the stale age.", int64(m.Stale)},
+		{"giwo_worktrees_stale_bytes", "GIWO_STALE_BYTES", "Disk space used by stale worktrees, in bytes.", m.StaleBytes},
+	}
+}
+
+// labelEscaper escapes label values for the Prometheus text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteMetrics writes metrics in the Prometheus text exposition format, as
+// gauges labeled with the repository, e.g. giwo_worktrees_dirty{repo="/src/app"} 2,
+// to be collected by node_exporter's textfile collector.
+func WriteMetrics(w io.Writer, metrics Metrics, repo string) error {
+	var b strings.Builder
+	for _, m := range metrics.list() {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.prom, m.help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", m.prom)
+		fmt.Fprintf(&b, "%s{repo=\"%s\"} %d\n", m.prom, labelEscaper.Replace(repo), m.value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMetricsEnv writes metrics as shell variable assignments, e.g.
+// GIWO_DIRTY=2, to be evaluated by scripts such as pre-push hooks.
+func WriteMetricsEnv(w io.Writer, metrics Metrics) error {
+	var b strings.Builder
+	for _, m := range metrics.list() {
+		fmt.Fprintf(&b, "%s=%d\n", m.env, m.value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
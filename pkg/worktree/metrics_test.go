@@ -0,0 +1,96 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCollectMetrics(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-60 * 24 * time.Hour)
+	staleDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(staleDir, "build.log"), make([]byte, 1000), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees := []*Worktree{
+		{Path: "/repo", IsMain: true, IsClean: true, LastActivity: old},
+		{Path: "/repo/.worktree/feature", IsClean: false, Ahead: 2, LastActivity: now},
+		{Path: "/repo/.worktree/fix", IsClean: true, Behind: 3, Locked: true, LastActivity: now},
+		{Path: staleDir, IsClean: true, Ahead: 1, Behind: 1, LastActivity: old},
+		{Path: "/repo/.worktree/gone", IsClean: true, Prunable: true, LastActivity: old},
+	}
+
+	metrics, err := CollectMetrics(context.Background(), worktrees, 30*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("CollectMetrics() error = %v", err)
+	}
+	expected := Metrics{Total: 5, Dirty: 1, Ahead: 2, Behind: 2, Locked: 1, Prunable: 1, Stale: 1, StaleBytes: 1000}
+	if diff := cmp.Diff(expected, metrics); diff != "" {
+		t.Errorf("CollectMetrics() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := Metrics{Total: 4, Dirty: 2, Stale: 1, StaleBytes: 2048}
+
+	var prom bytes.Buffer
+	if err := WriteMetrics(&prom, metrics, `/src/"app"`); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	expected := `# HELP giwo_worktrees Number of worktrees, the main one included.
+# TYPE giwo_worktrees gauge
+giwo_worktrees{repo="/src/\"app\""} 4
+# HELP giwo_worktrees_dirty Number of worktrees with uncommitted changes.
+# TYPE giwo_worktrees_dirty gauge
+giwo_worktrees_dirty{repo="/src/\"app\""} 2
+# HELP giwo_worktrees_ahead Number of worktrees with commits their upstream does not have.
+# TYPE giwo_worktrees_ahead gauge
+giwo_worktrees_ahead{repo="/src/\"app\""} 0
+# HELP giwo_worktrees_behind Number of worktrees behind their upstream.
+# TYPE giwo_worktrees_behind gauge
+giwo_worktrees_behind{repo="/src/\"app\""} 0
+# HELP giwo_worktrees_locked Number of locked worktrees.
+# TYPE giwo_worktrees_locked gauge
+giwo_worktrees_locked{repo="/src/\"app\""} 0
+# HELP giwo_worktrees_prunable Number of worktrees whose directory is gone.
+# TYPE giwo_worktrees_prunable gauge
+giwo_worktrees_prunable{repo="/src/\"app\""} 0
+# HELP giwo_worktrees_stale Number of worktrees without activity for the stale age.
+# TYPE giwo_worktrees_stale gauge
+giwo_worktrees_stale{repo="/src/\"app\""} 1
+# HELP giwo_worktrees_stale_bytes Disk space used by stale worktrees, in bytes.
+# TYPE giwo_worktrees_stale_bytes gauge
+giwo_worktrees_stale_bytes{repo="/src/\"app\""} 2048
+`
+	if diff := cmp.Diff(expected, prom.String()); diff != "" {
+		t.Errorf("WriteMetrics() mismatch (-want +got):\n%s", diff)
+	}
+
+	var env bytes.Buffer
+	if err := WriteMetricsEnv(&env, metrics); err != nil {
+		t.Fatalf("WriteMetricsEnv() error = %v", err)
+	}
+	expected = `GIWO_WORKTREES=4
+GIWO_DIRTY=2
+GIWO_AHEAD=0
+GIWO_BEHIND=0
+GIWO_LOCKED=0
+GIWO_PRUNABLE=0
+GIWO_STALE=1
+GIWO_STALE_BYTES=2048
+`
+	if diff := cmp.Diff(expected, env.String()); diff != "" {
+		t.Errorf("WriteMetricsEnv() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,117 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// MoveOptions configures Move.
+type MoveOptions struct {
+	// Path is the current path of the worktree.
+	Path string
+
+	// Dest is the new path, as returned by MoveDestination.
+	Dest string
+
+	// Force moves a locked worktree, which stays locked. Without it, Move fails
+	// with ErrWorktreeLocked for such a worktree.
+	Force bool
+}
+
+// MoveDestination resolves dest, relative to the worktree directory unless it
+// is absolute, and checks that it can take a worktree: it must lie inside the
+// worktree directory, so that the worktree keeps a name, form a name valid as a
+// branch name, not exist yet, and not lie inside another worktree.
+func (m *Manager) MoveDestination(ctx context.Context, dest string) (string, error) {
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(m.worktreeDir, dest)
+	}
+	dest = filepath.Clean(dest)
+
+	name, err := filepath.Rel(m.worktreeDir, dest)
+	if err != nil || name == "." || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the worktree directory %s", dest, m.worktreeDir)
+	}
+	if err := utils.ValidateBranchName(filepath.ToSlash(name)); err != nil {
+		return "", fmt.Errorf("invalid worktree name %q: %w", filepath.ToSlash(name), err)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return "", fmt.Errorf("%w: %s", errors.ErrWorktreeExists, dest)
+	}
+
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return "", err
+	}
+	// git lists the main worktree, which usually holds the worktree directory, first
+	for i, wt := range worktrees {
+		if i > 0 && strings.HasPrefix(dest, wt.Path+string(filepath.Separator)) {
+			return "", fmt.Errorf("%s is inside the worktree %s", dest, wt.Path)
+		}
+	}
+	return dest, nil
+}
+
+// Move moves a worktree to a new directory with 'git worktree move', which
+// rewrites the gitdir links on both sides, and carries its metadata and labels
+// over. The branch is left alone; Rename renames both. Worktrees paired with
+// submodule worktrees cannot be moved, since git refuses to move submodules.
+func (m *Manager) Move(ctx context.Context, opts MoveOptions) error {
+	wt, err := m.findRegistered(ctx, opts.Path)
+	if err != nil {
+		return err
+	}
+	if wt == nil {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, opts.Path)
+	}
+	if wt.Locked && !opts.Force {
+		return lockedError(wt)
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return err
+	}
+	if meta, ok := store[wt.Path]; ok && len(meta.Submodules) > 0 {
+		return fmt.Errorf("%s has submodule worktrees, which git cannot move; remove and recreate it instead", wt.Path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opts.Dest), 0o755); err != nil {
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	label := wt.Branch
+	if label == "" {
+		label = filepath.Base(wt.Path)
+	}
+	done := m.step("Moving " + label)
+	err = m.runGitCommand(ctx, moveArgs(wt, opts.Dest)...)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+
+	if err := m.renameMetadata(wt.Path, opts.Dest, nil); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update worktree metadata: %v\n", err)
+	}
+	if err := m.moveLabels(wt.Path, opts.Dest); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update worktree labels: %v\n", err)
+	}
+
+	return nil
+}
+
+// moveArgs returns the git command moving the worktree wt to dest.
+func moveArgs(wt *Worktree, dest string) []string {
+	args := []string{"worktree", "move"}
+	if wt.Locked {
+		// git moves a locked worktree only when forced twice; the lock stays
+		args = append(args, "--force", "--force")
+	}
+	return append(args, wt.Path, dest)
+}
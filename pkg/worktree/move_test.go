@@ -0,0 +1,42 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMoveDestinationRejects(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := &Manager{worktreeDir: dir}
+	if err := os.Mkdir(filepath.Join(dir, "taken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, dest := range map[string]string{
+		"worktree directory itself": ".",
+		"parent":                    "..",
+		"outside":                   "../elsewhere/feature",
+		"absolute outside":          "/elsewhere/feature",
+		"invalid name":              "feature x",
+		"existing":                  "taken",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Rejected before registered worktrees, which need git, are looked at
+			got, err := m.MoveDestination(context.Background(), dest)
+			if err == nil {
+				t.Fatalf("MoveDestination(%q) = %q, want error", dest, got)
+			}
+			if diff := cmp.Diff("", got); diff != "" {
+				t.Errorf("MoveDestination(%q) mismatch (-want +got):\n%s", dest, diff)
+			}
+		})
+	}
+}
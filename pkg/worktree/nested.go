@@ -0,0 +1,137 @@
+package worktree
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NestedChange is a git repository inside a worktree, such as a checked-out
+// submodule or a repository cloned into it, with work that removing the
+// worktree would lose. The status of the worktree itself can miss it: git
+// reports no changes for ignored directories, nor for submodules configured
+// with ignore = all, nor commits that were never pushed.
+type NestedChange struct {
+	// Path is the repository's path within the worktree, with slashes.
+	Path string `json:"path"`
+
+	// Submodule is set for the submodules the worktree declares.
+	Submodule bool `json:"submodule"`
+
+	// Dirty is set if the repository has uncommitted changes or untracked files.
+	Dirty bool `json:"dirty"`
+
+	// Unpushed is the number of commits at its HEAD that are on no remote,
+	// and NoRemote is set if it has no remotes at all.
+	Unpushed int  `json:"unpushed"`
+	NoRemote bool `json:"no_remote"`
+}
+
+// NestedChanges returns the repositories nested in the worktree at path that
+// have uncommitted changes or unpushed commits, in path order. Worktrees of
+// the repository nested in it are left out, as they are removed on their own.
+func (m *Manager) NestedChanges(ctx context.Context, path string) ([]NestedChange, error) {
+	registered, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	worktrees := make(map[string]bool, len(registered))
+	for _, wt := range registered {
+		worktrees[filepath.Clean(wt.Path)] = true
+	}
+
+	repos, err := findNestedRepos(path, worktrees)
+	if err != nil {
+		return nil, err
+	}
+	if len(repos) == 0 {
+		return nil, nil
+	}
+
+	var submodules []string
+	if hasSubmodules(path) {
+		if output, err := m.gitOutput(ctx, path, "config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.path$`); err == nil {
+			submodules = parseSubmodulePaths(output)
+		}
+	}
+
+	var changes []NestedChange
+	for _, rel := range repos {
+		change := NestedChange{Path: rel, Submodule: slices.Contains(submodules, rel)}
+		if err := m.inspectNested(ctx, filepath.Join(path, filepath.FromSlash(rel)), &change); err != nil {
+			m.log().Debug("failed to inspect nested repository", "path", rel, "error", err)
+			continue
+		}
+		if change.Dirty || change.Unpushed > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// inspectNested fills in the uncommitted changes and unpushed commits of the
+// repository at dir. A repository without commits has nothing to push.
+func (m *Manager) inspectNested(ctx context.Context, dir string, change *NestedChange) error {
+	status, err := m.gitOutput(ctx, dir, "status", "--porcelain", "--ignore-submodules=none")
+	if err != nil {
+		return err
+	}
+	change.Dirty = strings.TrimSpace(status) != ""
+
+	if _, err := m.gitOutput(ctx, dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return nil
+	}
+	remotes, err := m.gitOutput(ctx, dir, "remote")
+	if err != nil {
+		return err
+	}
+	change.NoRemote = strings.TrimSpace(remotes) == ""
+	count, err := m.gitOutput(ctx, dir, "rev-list", "--count", "HEAD", "--not", "--remotes")
+	if err != nil {
+		return err
+	}
+	change.Unpushed, _ = strconv.Atoi(strings.TrimSpace(count))
+	return nil
+}
+
+// findNestedRepos returns the paths, relative to root and with slashes, of
+// the directories below root that have a .git directory or file of their own,
+// skipping the directories in skip. Symbolic links are not followed, so
+// shared paths linked into the worktree are not searched.
+func findNestedRepos(root string, skip map[string]bool) ([]string, error) {
+	root = filepath.Clean(root)
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than failing the scan
+			if path != root && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.Name() == ".git" {
+			if dir := filepath.Dir(path); dir != root {
+				rel, err := filepath.Rel(root, dir)
+				if err == nil {
+					repos = append(repos, filepath.ToSlash(rel))
+				}
+			}
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && path != root && skip[path] {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return repos, nil
+}
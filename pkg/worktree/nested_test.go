@@ -0,0 +1,88 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindNestedRepos(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		dirs  []string
+		files []string
+		skip  []string
+		want  []string
+	}{
+		"none": {
+			dirs:  []string{".git", "src/app"},
+			files: []string{"src/app/main.go"},
+			want:  nil,
+		},
+		"worktree with a .git file": {
+			files: []string{".git", "README.md"},
+			want:  nil,
+		},
+		"submodule and clone": {
+			dirs:  []string{"vendor/tool/.git/objects"},
+			files: []string{".git", "lib/sub/.git"},
+			want:  []string{"lib/sub", "vendor/tool"},
+		},
+		"repository in a nested repository": {
+			dirs: []string{"outer/.git", "outer/inner/.git"},
+			want: []string{"outer", "outer/inner"},
+		},
+		"nested worktree skipped": {
+			files: []string{".worktree/other/.git", "lib/sub/.git"},
+			skip:  []string{".worktree/other"},
+			want:  []string{"lib/sub"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			for _, dir := range tt.dirs {
+				if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, file := range tt.files {
+				path := filepath.Join(root, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("gitdir: elsewhere\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			skip := make(map[string]bool)
+			for _, dir := range tt.skip {
+				skip[filepath.Join(root, dir)] = true
+			}
+
+			got, err := findNestedRepos(root, skip)
+			if err != nil {
+				t.Fatalf("findNestedRepos() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("findNestedRepos() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindNestedReposMissingWorktree(t *testing.T) {
+	t.Parallel()
+
+	got, err := findNestedRepos(filepath.Join(t.TempDir(), "gone"), nil)
+	if err != nil {
+		t.Fatalf("findNestedRepos() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("findNestedRepos() = %v, want none", got)
+	}
+}
@@ -0,0 +1,100 @@
+package worktree
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// networkErrors are messages of git failing to reach a remote, as opposed to
+// the remote refusing a request.
+var networkErrors = []string{
+	"could not resolve host",
+	"could not resolve hostname",
+	"temporary failure in name resolution",
+	"network is unreachable",
+	"no route to host",
+	"connection timed out",
+	"operation timed out",
+	"connection refused",
+	"failed to connect to",
+}
+
+// IsNetworkError reports whether err is git failing to reach a remote.
+func IsNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range networkErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetOffline makes the manager work from what was last fetched instead of
+// contacting remotes: fetches are skipped, and new worktrees start from the
+// remote-tracking branches as they are. A manager also goes offline by itself
+// when a fetch fails to reach the remote.
+func (m *Manager) SetOffline(offline bool) {
+	m.offline = offline
+}
+
+// Offline reports whether the manager works without contacting remotes,
+// because SetOffline asked for it or a fetch found no network.
+func (m *Manager) Offline() bool {
+	return m.offline
+}
+
+// LastFetch returns when a remote was last fetched from, or the zero time if
+// never. Git rewrites FETCH_HEAD on every fetch but leaves it empty when the
+// fetch fails, so the last update of a remote-tracking branch is used then.
+func (m *Manager) LastFetch() time.Time {
+	info, err := os.Stat(filepath.Join(m.gitCommonDir, "FETCH_HEAD"))
+	if err == nil && info.Size() > 0 {
+		return info.ModTime()
+	}
+
+	var last time.Time
+	_ = filepath.WalkDir(filepath.Join(m.gitCommonDir, "logs", "refs", "remotes"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+		return nil
+	})
+	return last
+}
+
+// skipFetch tells, once per manager, that remote-tracking branches are used as
+// last fetched and how old they are.
+func (m *Manager) skipFetch() {
+	if m.offlineNoted {
+		return
+	}
+	m.offlineNoted = true
+
+	fetched := "never fetched"
+	if t := m.LastFetch(); !t.IsZero() {
+		fetched = "last fetched " + utils.TimeAgo(t)
+	}
+	fmt.Printf("📴 Offline: using origin as %s\n", fetched)
+}
+
+// goOffline switches the manager offline after err, from a fetch, showed that
+// the remote cannot be reached.
+func (m *Manager) goOffline(err error) {
+	m.log().Info("remote unreachable, working offline", "error", err)
+	fmt.Printf("⚠️  Could not reach the remote; continuing offline\n")
+	m.offline = true
+	m.skipFetch()
+}
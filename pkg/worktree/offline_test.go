@@ -0,0 +1,150 @@
+package worktree
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestIsNetworkError(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil": {},
+		"dns over ssh": {
+			err:  stderrors.New("git fetch failed: ssh: Could not resolve hostname github.com: nodename nor servname provided"),
+			want: true,
+		},
+		"dns over https": {
+			err:  stderrors.New("fatal: unable to access 'https://github.com/knwoop/giwo/': Could not resolve host: github.com"),
+			want: true,
+		},
+		"unreachable": {
+			err:  stderrors.New("ssh: connect to host github.com port 22: Network is unreachable"),
+			want: true,
+		},
+		"timeout": {
+			err:  stderrors.New("Failed to connect to github.com port 443 after 75002 ms: Operation timed out"),
+			want: true,
+		},
+		"permission denied": {
+			err:  stderrors.New("git@github.com: Permission denied (publickey)."),
+			want: false,
+		},
+		"missing ref": {
+			err:  stderrors.New("fatal: couldn't find remote ref feature-x"),
+			want: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, IsNetworkError(tt.err)); diff != "" {
+				t.Errorf("IsNetworkError() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRemoteStatusCache(t *testing.T) {
+	t.Parallel()
+
+	m := &Manager{gitCommonDir: t.TempDir()}
+
+	fetched := []*Worktree{
+		{Branch: "feat-a", Remote: &RemoteStatus{PRNumber: 42, PRState: "open", Checks: "success"}},
+		{Branch: "feat-b", Remote: &RemoteStatus{Error: "timed out after 5s"}},
+	}
+	if err := m.SaveRemoteStatus(fetched); err != nil {
+		t.Fatalf("SaveRemoteStatus() error = %v", err)
+	}
+
+	worktrees := []*Worktree{
+		{Branch: "feat-a", Remote: &RemoteStatus{Error: "offline"}},
+		{Branch: "feat-b"},
+		{Branch: "feat-c", Remote: &RemoteStatus{PRNumber: 7, PRState: "merged"}},
+	}
+	if err := m.CachedRemoteStatus(worktrees); err != nil {
+		t.Fatalf("CachedRemoteStatus() error = %v", err)
+	}
+
+	want := []*RemoteStatus{
+		{PRNumber: 42, PRState: "open", Checks: "success"},
+		nil,
+		{PRNumber: 7, PRState: "merged"},
+	}
+	got := make([]*RemoteStatus, len(worktrees))
+	for i, wt := range worktrees {
+		got[i] = wt.Remote
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(RemoteStatus{}, "CachedAt")); diff != "" {
+		t.Errorf("CachedRemoteStatus() mismatch (-want +got):\n%s", diff)
+	}
+	if cachedAt := worktrees[0].Remote.CachedAt; time.Since(cachedAt) > time.Minute {
+		t.Errorf("CachedAt = %v, want the time of SaveRemoteStatus", cachedAt)
+	}
+	if !worktrees[2].Remote.CachedAt.IsZero() {
+		t.Errorf("fetched status was replaced by the cache")
+	}
+}
+
+func TestLastFetch(t *testing.T) {
+	t.Parallel()
+
+	fetchedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	refUpdatedAt := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, tt := range map[string]struct {
+		fetchHead   string
+		noFetchHead bool
+		reflog      bool
+		want        time.Time
+	}{
+		"never fetched":                {noFetchHead: true, want: time.Time{}},
+		"fetched":                      {fetchHead: "abc\t\tbranch 'main' of origin\n", reflog: true, want: fetchedAt},
+		"last fetch failed":            {fetchHead: "", reflog: true, want: refUpdatedAt},
+		"failed without tracking refs": {fetchHead: "", want: time.Time{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &Manager{gitCommonDir: t.TempDir()}
+			if !tt.noFetchHead {
+				path := filepath.Join(m.gitCommonDir, "FETCH_HEAD")
+				writeFileAt(t, path, tt.fetchHead, fetchedAt)
+			}
+			if tt.reflog {
+				path := filepath.Join(m.gitCommonDir, "logs", "refs", "remotes", "origin", "main")
+				writeFileAt(t, path, "entry\n", refUpdatedAt)
+			}
+
+			if diff := cmp.Diff(tt.want, m.LastFetch().UTC()); diff != "" {
+				t.Errorf("LastFetch() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// writeFileAt writes content to path, creating its directory, and sets its
+// modification time.
+func writeFileAt(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
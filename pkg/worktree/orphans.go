@@ -0,0 +1,195 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// Orphan is a directory in the worktree directory that looks like a former
+// worktree of the repository, because it has a .git file pointing into the
+// repository's git directory, but that git no longer knows about, e.g. after
+// its administrative files were pruned or the worktree was moved by hand.
+type Orphan struct {
+	Path string
+
+	// Branch is the branch the worktree had checked out, as recorded in its
+	// administrative files, or empty if they are gone or its HEAD was detached.
+	// Worktree directory names do not tell: they may be sanitized, or differ
+	// from the branch, like for namespaced branches.
+	Branch string
+
+	// Repairable reports whether the administrative files the .git file points
+	// at still exist, so that 'git worktree repair' can register it again.
+	Repairable bool
+}
+
+// Orphans scans the worktree directory for orphaned worktrees. Registered
+// worktrees, also when the worktree directory is reached through a symlink, and
+// nested repositories are skipped, and directories without a .git file are
+// searched since they may group worktrees of branches like feature/auth. So are
+// worktrees of other repositories, which a worktree directory shared between
+// repositories, such as ~/worktrees, holds as well.
+func (m *Manager) Orphans(ctx context.Context) ([]Orphan, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		registered[utils.ResolvePath(wt.Path)] = true
+	}
+
+	var orphans []Orphan
+	err = filepath.WalkDir(m.worktreeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == m.worktreeDir {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if !d.IsDir() || path == m.worktreeDir {
+			return nil
+		}
+		if registered[utils.ResolvePath(path)] {
+			return filepath.SkipDir
+		}
+
+		info, err := os.Lstat(filepath.Join(path, ".git"))
+		switch {
+		case err != nil:
+			return nil
+		case info.IsDir():
+			// A repository of its own, not a worktree
+			return filepath.SkipDir
+		}
+
+		gitDir := linkedGitDir(path)
+		if gitDir == "" || !ownsAdminDir(m.gitCommonDir, gitDir) || linksBack(path, gitDir) {
+			return filepath.SkipDir
+		}
+		orphans = append(orphans, Orphan{
+			Path:       path,
+			Branch:     adminBranch(gitDir),
+			Repairable: adminDirExists(path),
+		})
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", m.worktreeDir, err)
+	}
+
+	return orphans, nil
+}
+
+// linkedGitDir returns the administrative directory the .git file of the
+// worktree at path points at, or "" if there is no such file.
+func linkedGitDir(path string) string {
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return ""
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(path, gitDir)
+	}
+	return filepath.Clean(gitDir)
+}
+
+// ownsAdminDir reports whether gitDir is the administrative directory of a
+// worktree of the repository whose git directory is commonDir, i.e. lies in its
+// worktrees directory, whether or not it still exists.
+func ownsAdminDir(commonDir, gitDir string) bool {
+	worktrees := filepath.Join(commonDir, "worktrees")
+	parent := filepath.Dir(gitDir)
+	if parent == worktrees {
+		return true
+	}
+	// Either may be reached through a symlink, such as /tmp on macOS
+	resolvedWorktrees, err := filepath.EvalSymlinks(worktrees)
+	if err != nil {
+		return false
+	}
+	resolvedParent, err := filepath.EvalSymlinks(parent)
+	return err == nil && resolvedParent == resolvedWorktrees
+}
+
+// linksBack reports whether the administrative directory gitDir records the
+// worktree at path as its own, in which case git has it registered, whatever
+// path it is reached through.
+func linksBack(path, gitDir string) bool {
+	data, err := os.ReadFile(filepath.Join(gitDir, "gitdir"))
+	if err != nil {
+		return false
+	}
+	dotGit := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(dotGit) {
+		dotGit = filepath.Join(gitDir, dotGit)
+	}
+	return utils.ResolvePath(filepath.Dir(dotGit)) == utils.ResolvePath(path)
+}
+
+// adminDirExists reports whether the directory the .git file of the worktree
+// at path points at still exists.
+func adminDirExists(path string) bool {
+	gitDir := linkedGitDir(path)
+	if gitDir == "" {
+		return false
+	}
+	info, err := os.Stat(gitDir)
+	return err == nil && info.IsDir()
+}
+
+// adminBranch returns the branch the HEAD file in the administrative directory
+// gitDir of a worktree points at, or "" if it is gone or detached.
+func adminBranch(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	branch, _ := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/heads/")
+	if branch == strings.TrimSpace(string(data)) {
+		return ""
+	}
+	return branch
+}
+
+// RemoveOrphan deletes the directory of an orphaned worktree, including any
+// uncommitted work left in it. Worktrees git has registered are refused.
+func (m *Manager) RemoveOrphan(orphan Orphan) error {
+	if rel, err := filepath.Rel(m.worktreeDir, orphan.Path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("refusing to delete %s outside of %s", orphan.Path, m.worktreeDir)
+	}
+	if gitDir := linkedGitDir(orphan.Path); gitDir != "" && linksBack(orphan.Path, gitDir) {
+		return fmt.Errorf("%w: refusing to delete %s, which git has registered", errors.ErrWorktreeExists, orphan.Path)
+	}
+	if err := os.RemoveAll(orphan.Path); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", orphan.Path, err)
+	}
+	return nil
+}
+
+// AdoptOrphan registers an orphaned worktree with git again, keeping its files,
+// by repairing it in place. Orphans whose administrative files are gone cannot
+// be adopted, as nothing records the branch they had checked out anymore.
+func (m *Manager) AdoptOrphan(ctx context.Context, orphan Orphan) error {
+	if !orphan.Repairable {
+		return fmt.Errorf("%w: git's files for %s are gone, so its branch is unknown; check the branch out with 'giwo create' and copy the files over, or delete the directory",
+			errors.ErrWorktreeNotFound, orphan.Path)
+	}
+	if err := m.runGitCommand(ctx, "worktree", "repair", orphan.Path); err != nil {
+		return fmt.Errorf("failed to repair worktree: %w", err)
+	}
+
+	// Record the worktree as created by giwo from now on
+	return m.updateMetadata(orphan.Path, func(*Metadata) {})
+}
@@ -0,0 +1,205 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAdminDirExists(t *testing.T) {
+	for name, tt := range map[string]struct {
+		garbage  bool
+		adminDir bool
+		expected bool
+	}{
+		"admin dir exists": {
+			adminDir: true,
+			expected: true,
+		},
+		"admin dir pruned": {
+			expected: false,
+		},
+		"not a gitdir file": {
+			garbage:  true,
+			adminDir: true,
+			expected: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			admin := filepath.Join(dir, "admin")
+			if tt.adminDir {
+				if err := os.Mkdir(admin, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			worktree := filepath.Join(dir, "worktree")
+			if err := os.Mkdir(worktree, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			content := "gitdir: " + admin + "\n"
+			if tt.garbage {
+				content = "garbage\n"
+			}
+			if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.expected, adminDirExists(worktree)); diff != "" {
+				t.Errorf("adminDirExists mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRemoveOrphanOutsideWorktreeDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := &Manager{worktreeDir: filepath.Join(dir, ".worktree")}
+
+	for _, path := range []string{dir, m.worktreeDir} {
+		if err := m.RemoveOrphan(Orphan{Path: path}); err == nil {
+			t.Errorf("expected RemoveOrphan to refuse deleting %s", path)
+		}
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("expected %s to be kept: %v", dir, err)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	commonDir := filepath.Join(dir, "app", ".git")
+	worktreeDir := filepath.Join(dir, "worktrees")
+	files := map[string]string{
+		// Worktrees of the repository git no longer knows
+		filepath.Join(commonDir, "worktrees", "feature-x", "HEAD"): "ref: refs/heads/me/feature-x\n",
+		filepath.Join(worktreeDir, "feature-x", ".git"):            "gitdir: " + filepath.Join(commonDir, "worktrees", "feature-x") + "\n",
+		filepath.Join(worktreeDir, "fix", "login", ".git"):         "gitdir: " + filepath.Join(commonDir, "worktrees", "login") + "\n",
+		// A worktree of another repository sharing the worktree directory
+		filepath.Join(dir, "lib", ".git", "worktrees", "main", "HEAD"): "ref: refs/heads/main\n",
+		filepath.Join(worktreeDir, "lib-main", ".git"):                 "gitdir: " + filepath.Join(dir, "lib", ".git", "worktrees", "main") + "\n",
+		// A repository of its own
+		filepath.Join(worktreeDir, "clone", ".git", "HEAD"): "ref: refs/heads/main\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backend := &fakeBackend{worktrees: []*Worktree{{Path: filepath.Join(dir, "app"), Branch: "main", IsMain: true}}}
+	m := &Manager{repoRoot: filepath.Join(dir, "app"), gitCommonDir: commonDir, worktreeDir: worktreeDir, backend: backend}
+
+	orphans, err := m.Orphans(context.Background())
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+	expected := []Orphan{
+		{Path: filepath.Join(worktreeDir, "feature-x"), Branch: "me/feature-x", Repairable: true},
+		{Path: filepath.Join(worktreeDir, "fix", "login")},
+	}
+	if diff := cmp.Diff(expected, orphans); diff != "" {
+		t.Errorf("Orphans() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestOrphansThroughSymlink checks that worktrees git has registered by their
+// real path are not taken for orphans when the worktree directory is reached
+// through a symlink, nor deleted.
+func TestOrphansThroughSymlink(t *testing.T) {
+	t.Parallel()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	commonDir := filepath.Join(dir, "app", ".git")
+	real := filepath.Join(dir, "real")
+	link := filepath.Join(dir, "link")
+	files := map[string]string{
+		// Registered and listed by git
+		filepath.Join(commonDir, "worktrees", "feat", "gitdir"): filepath.Join(real, "wts", "feat", ".git") + "\n",
+		filepath.Join(real, "wts", "feat", ".git"):              "gitdir: " + filepath.Join(commonDir, "worktrees", "feat") + "\n",
+		// Registered, but not listed, as if git had spelled its path differently
+		filepath.Join(commonDir, "worktrees", "feat2", "gitdir"): filepath.Join(real, "wts", "feat2", ".git") + "\n",
+		filepath.Join(real, "wts", "feat2", ".git"):              "gitdir: " + filepath.Join(commonDir, "worktrees", "feat2") + "\n",
+		// Moved by hand, so that git records it elsewhere
+		filepath.Join(commonDir, "worktrees", "moved", "gitdir"): filepath.Join(dir, "elsewhere", "moved", ".git") + "\n",
+		filepath.Join(real, "wts", "moved", ".git"):              "gitdir: " + filepath.Join(commonDir, "worktrees", "moved") + "\n",
+	}
+	writeFiles(t, "/", files)
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &fakeBackend{worktrees: []*Worktree{
+		{Path: filepath.Join(dir, "app"), Branch: "main", IsMain: true},
+		{Path: filepath.Join(real, "wts", "feat"), Branch: "feat"},
+	}}
+	m := &Manager{repoRoot: filepath.Join(dir, "app"), gitCommonDir: commonDir, worktreeDir: filepath.Join(link, "wts"), backend: backend}
+
+	orphans, err := m.Orphans(context.Background())
+	if err != nil {
+		t.Fatalf("Orphans() error = %v", err)
+	}
+	expected := []Orphan{{Path: filepath.Join(link, "wts", "moved"), Repairable: true}}
+	if diff := cmp.Diff(expected, orphans); diff != "" {
+		t.Errorf("Orphans() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, name := range []string{"feat", "feat2"} {
+		path := filepath.Join(link, "wts", name)
+		if err := m.RemoveOrphan(Orphan{Path: path}); err == nil {
+			t.Errorf("expected RemoveOrphan to refuse deleting the registered worktree %s", path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be kept: %v", path, err)
+		}
+	}
+}
+
+func TestAdminBranch(t *testing.T) {
+	for name, tt := range map[string]struct {
+		head     string
+		expected string
+	}{
+		"branch": {
+			head:     "ref: refs/heads/me/feature-x\n",
+			expected: "me/feature-x",
+		},
+		"detached": {
+			head:     "0123456789abcdef0123456789abcdef01234567\n",
+			expected: "",
+		},
+		"pruned": {
+			expected: "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gitDir := t.TempDir()
+			if tt.head != "" {
+				if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte(tt.head), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if diff := cmp.Diff(tt.expected, adminBranch(gitDir)); diff != "" {
+				t.Errorf("adminBranch mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package worktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// timingsFileName is the name of the timing statistics file inside the state directory.
+	timingsFileName = "timings.json"
+
+	// maxTimingSamples bounds how many of the latest samples are kept per operation.
+	maxTimingSamples = 200
+)
+
+// Timing is a measured run of an operation: a git command like "git fetch", a
+// filesystem step like "fs copy templates", or a whole giwo command like "giwo create".
+type Timing struct {
+	Op       string        `json:"op"`
+	Dir      string        `json:"dir,omitempty"`
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+	Failed   bool          `json:"failed,omitempty"`
+}
+
+// TimingStats summarizes the recorded runs of an operation.
+type TimingStats struct {
+	Op     string        `json:"op"`
+	Count  int           `json:"count"`
+	Failed int           `json:"failed"`
+	P50    time.Duration `json:"p50"`
+	P95    time.Duration `json:"p95"`
+	Max    time.Duration `json:"max"`
+}
+
+// timingLog collects the timings measured by a Manager until they are saved.
+type timingLog struct {
+	mu      sync.Mutex
+	pending []Timing
+}
+
+// timeGit starts measuring the git command with args run in dir and returns the
+// function recording and logging its outcome.
+func (m *Manager) timeGit(dir string, args ...string) func(error) {
+	op := gitOpName(args)
+	start := time.Now()
+	return func(err error) {
+		d := time.Since(start)
+		m.RecordTiming(Timing{Op: op, Dir: dir, Time: start, Duration: d, Failed: err != nil})
+		m.logGit(dir, args, d, err)
+	}
+}
+
+// timeOp starts measuring the operation op in dir and returns the function
+// recording and logging its outcome.
+func (m *Manager) timeOp(op, dir string) func(error) {
+	start := time.Now()
+	return func(err error) {
+		d := time.Since(start)
+		m.RecordTiming(Timing{Op: op, Dir: dir, Time: start, Duration: d, Failed: err != nil})
+		if err != nil {
+			m.log().Debug(op, "dir", dir, "duration", d, "error", err)
+			return
+		}
+		m.log().Debug(op, "dir", dir, "duration", d)
+	}
+}
+
+// RecordTiming adds a measurement to be written by SaveTimings.
+func (m *Manager) RecordTiming(t Timing) {
+	m.timings.mu.Lock()
+	defer m.timings.mu.Unlock()
+
+	m.timings.pending = append(m.timings.pending, t)
+}
+
+// gitOpName names the git command run with args, e.g. "git fetch" or
+// "git worktree add" for commands with subcommands.
+func gitOpName(args []string) string {
+	words := []string{"git"}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		words = append(words, arg)
+		if len(words) == 3 || (arg != "worktree" && arg != "stash" && arg != "remote") {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Timings returns the recorded timings of the repository, oldest first.
+func (m *Manager) Timings() ([]Timing, error) {
+	store, err := m.loadTimings()
+	if err != nil {
+		return nil, err
+	}
+
+	var timings []Timing
+	for _, samples := range store {
+		timings = append(timings, samples...)
+	}
+	sort.SliceStable(timings, func(i, j int) bool { return timings[i].Time.Before(timings[j].Time) })
+	return timings, nil
+}
+
+// SaveTimings appends the timings measured since the last save to the
+// repository's statistics, keeping the latest samples of each operation.
+// The file is replaced atomically, but concurrent saves may lose samples
+// of each other, which statistics can afford.
+func (m *Manager) SaveTimings() error {
+	m.timings.mu.Lock()
+	defer m.timings.mu.Unlock()
+
+	if len(m.timings.pending) == 0 {
+		return nil
+	}
+
+	store, err := m.loadTimings()
+	if err != nil {
+		// A corrupt file is replaced rather than blocking every command
+		store = make(map[string][]Timing)
+	}
+	for _, t := range m.timings.pending {
+		samples := append(store[t.Op], t)
+		if len(samples) > maxTimingSamples {
+			samples = samples[len(samples)-maxTimingSamples:]
+		}
+		store[t.Op] = samples
+	}
+
+	if err := m.writeTimings(store); err != nil {
+		return err
+	}
+	m.timings.pending = nil
+	return nil
+}
+
+// ResetTimings deletes the recorded timings of the repository.
+func (m *Manager) ResetTimings() error {
+	err := os.Remove(filepath.Join(m.StateDir(), timingsFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reset timings: %w", err)
+	}
+	return nil
+}
+
+// loadTimings reads the timing statistics, keyed by operation.
+func (m *Manager) loadTimings() (map[string][]Timing, error) {
+	store := make(map[string][]Timing)
+
+	data, err := os.ReadFile(filepath.Join(m.StateDir(), timingsFileName))
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse timings: %w", err)
+	}
+	return store, nil
+}
+
+// writeTimings atomically replaces the timing statistics with store.
+func (m *Manager) writeTimings(store map[string][]Timing) error {
+	data, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+
+	dir := m.StateDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, timingsFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write timings: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write timings: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write timings: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, timingsFileName)); err != nil {
+		return fmt.Errorf("failed to write timings: %w", err)
+	}
+	return nil
+}
+
+// SummarizeTimings computes the statistics of every operation in timings,
+// slowest median first.
+func SummarizeTimings(timings []Timing) []TimingStats {
+	byOp := make(map[string][]Timing)
+	for _, t := range timings {
+		byOp[t.Op] = append(byOp[t.Op], t)
+	}
+
+	stats := make([]TimingStats, 0, len(byOp))
+	for op, samples := range byOp {
+		durations := make([]time.Duration, len(samples))
+		failed := 0
+		for i, t := range samples {
+			durations[i] = t.Duration
+			if t.Failed {
+				failed++
+			}
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+		stats = append(stats, TimingStats{
+			Op:     op,
+			Count:  len(samples),
+			Failed: failed,
+			P50:    percentile(durations, 50),
+			P95:    percentile(durations, 95),
+			Max:    durations[len(durations)-1],
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].P50 != stats[j].P50 {
+			return stats[i].P50 > stats[j].P50
+		}
+		return stats[i].Op < stats[j].Op
+	})
+	return stats
+}
+
+// SlowestTimings returns the n slowest runs in timings, slowest first.
+func SlowestTimings(timings []Timing, n int) []Timing {
+	slowest := append([]Timing(nil), timings...)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Duration > slowest[j].Duration })
+	if len(slowest) > n {
+		slowest = slowest[:n]
+	}
+	return slowest
+}
+
+// percentile returns the p-th percentile of sorted durations by the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// TimeBreakdown splits the time giwo commands took into the time spent in git,
+// in filesystem steps and in giwo itself.
+type TimeBreakdown struct {
+	Commands int           `json:"commands"`
+	Total    time.Duration `json:"total"`
+	Git      time.Duration `json:"git"`
+	FS       time.Duration `json:"fs"`
+}
+
+// Giwo returns the time not accounted for by git or filesystem steps.
+func (b TimeBreakdown) Giwo() time.Duration {
+	return max(b.Total-b.Git-b.FS, 0)
+}
+
+// BreakDownTimings attributes the git and filesystem timings in timings to the
+// giwo command during which they started. Operations without a recorded command,
+// like ones whose command record was rotated out, are not counted.
+func BreakDownTimings(timings []Timing) TimeBreakdown {
+	var b TimeBreakdown
+	for _, command := range timings {
+		if !strings.HasPrefix(command.Op, "giwo ") {
+			continue
+		}
+		b.Commands++
+		b.Total += command.Duration
+
+		end := command.Time.Add(command.Duration)
+		var git, fs time.Duration
+		for _, t := range timings {
+			if t.Time.Before(command.Time) || t.Time.After(end) {
+				continue
+			}
+			switch {
+			case strings.HasPrefix(t.Op, "git "):
+				git += t.Duration
+			case strings.HasPrefix(t.Op, "fs "):
+				fs += t.Duration
+			}
+		}
+		// Parallel git commands can add up to more than the command took
+		git = min(git, command.Duration)
+		b.Git += git
+		b.FS += min(fs, command.Duration-git)
+	}
+	return b
+}
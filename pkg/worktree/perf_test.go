@@ -0,0 +1,72 @@
+package worktree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGitOpName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		args     []string
+		expected string
+	}{
+		"command":         {args: []string{"fetch", "--prune", "origin"}, expected: "git fetch"},
+		"leading flags":   {args: []string{"--no-pager", "status"}, expected: "git status"},
+		"subcommand":      {args: []string{"worktree", "add", "-b", "feature", "/tmp/feature"}, expected: "git worktree add"},
+		"flag subcommand": {args: []string{"stash", "--quiet", "create"}, expected: "git stash create"},
+		"no arguments":    {args: nil, expected: "git"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, gitOpName(tt.args)); diff != "" {
+				t.Errorf("gitOpName mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSummarizeTimings(t *testing.T) {
+	t.Parallel()
+
+	var timings []Timing
+	for i := 1; i <= 20; i++ {
+		timings = append(timings, Timing{Op: "git status", Duration: time.Duration(i) * time.Millisecond})
+	}
+	timings = append(timings,
+		Timing{Op: "git fetch", Duration: 2 * time.Second},
+		Timing{Op: "git fetch", Duration: time.Second, Failed: true},
+	)
+
+	expected := []TimingStats{
+		{Op: "git fetch", Count: 2, Failed: 1, P50: time.Second, P95: 2 * time.Second, Max: 2 * time.Second},
+		{Op: "git status", Count: 20, P50: 10 * time.Millisecond, P95: 19 * time.Millisecond, Max: 20 * time.Millisecond},
+	}
+	if diff := cmp.Diff(expected, SummarizeTimings(timings)); diff != "" {
+		t.Errorf("SummarizeTimings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBreakDownTimings(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	timings := []Timing{
+		{Op: "giwo create", Time: start, Duration: time.Second},
+		{Op: "git fetch", Time: start.Add(100 * time.Millisecond), Duration: 500 * time.Millisecond},
+		{Op: "fs copy templates", Time: start.Add(700 * time.Millisecond), Duration: 100 * time.Millisecond},
+		// Outside of any giwo command
+		{Op: "git status", Time: start.Add(time.Hour), Duration: time.Second},
+	}
+
+	expected := TimeBreakdown{Commands: 1, Total: time.Second, Git: 500 * time.Millisecond, FS: 100 * time.Millisecond}
+	got := BreakDownTimings(timings)
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("BreakDownTimings mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(400*time.Millisecond, got.Giwo()); diff != "" {
+		t.Errorf("Giwo mismatch (-want +got):\n%s", diff)
+	}
+}
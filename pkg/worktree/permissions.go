@@ -0,0 +1,324 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// PermissionProblem is a worktree or a directory of the shared git directory
+// that cannot be worked with, typically because another user on a shared
+// machine created it.
+type PermissionProblem struct {
+	Path string
+
+	// UID is the owner of Path, and Owner their user name if it is known.
+	UID   int
+	Owner string
+
+	// Foreign is set when Path is owned by someone other than the user checked for.
+	Foreign bool
+
+	// Unsafe is set for worktrees owned by another user that are not covered by
+	// git's safe.directory setting, so that git refuses to run in them.
+	Unsafe bool
+
+	// NotWritable is set when the user checked for cannot create files in Path.
+	NotWritable bool
+}
+
+// Describe explains the problem, e.g. "owned by alice, not in safe.directory".
+func (p PermissionProblem) Describe() string {
+	var reasons []string
+	if p.Foreign {
+		reasons = append(reasons, "owned by "+p.ownerName())
+	}
+	if p.Unsafe {
+		reasons = append(reasons, "not in safe.directory")
+	}
+	if p.NotWritable {
+		reasons = append(reasons, "not writable")
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// ownerName returns the owner's user name, or their uid if it is unknown.
+func (p PermissionProblem) ownerName() string {
+	if p.Owner != "" {
+		return p.Owner
+	}
+	return "uid " + strconv.Itoa(p.UID)
+}
+
+// PermissionProblems checks the ownership and permissions of the worktrees and
+// of the directories in the shared git directory, where git writes objects, refs
+// and worktree administrative files for every worktree. They are checked for the
+// current user, or for the owner of the git directory when run as root. Within
+// the git directory only the topmost problematic directory of a tree is reported.
+// On platforms without Unix ownership nothing is reported.
+func (m *Manager) PermissionProblems(ctx context.Context) ([]PermissionProblem, error) {
+	uid, groups, err := m.permissionSubject()
+	if err != nil || uid < 0 {
+		return nil, err
+	}
+	var safe []string
+	if uid == os.Getuid() {
+		safe = m.safeDirectories(ctx)
+	}
+
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []PermissionProblem
+	for _, wt := range worktrees {
+		info, err := os.Stat(wt.Path)
+		if err != nil {
+			// Missing worktrees are for 'giwo prune'
+			continue
+		}
+		problem, ok := checkPermissions(wt.Path, info, uid, groups)
+		if !ok {
+			continue
+		}
+		// Root's safe.directory setting says nothing about the owner's
+		problem.Unsafe = problem.Foreign && uid == os.Getuid() && !safeDirectoryCovers(safe, wt.Path)
+		if problem.Unsafe || problem.NotWritable {
+			problems = append(problems, problem)
+		}
+	}
+
+	err = filepath.WalkDir(m.gitCommonDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if !os.IsPermission(err) {
+				return err
+			}
+			// An unreadable directory is reported like an unwritable one
+			if info, statErr := os.Lstat(path); statErr == nil {
+				if problem, ok := checkPermissions(path, info, uid, groups); ok {
+					problem.NotWritable = true
+					problems = append(problems, problem)
+				}
+			}
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if problem, ok := checkPermissions(path, info, uid, groups); ok && problem.NotWritable {
+			problems = append(problems, problem)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", m.gitCommonDir, err)
+	}
+
+	return problems, nil
+}
+
+// permissionSubject returns the user permissions are checked for and their groups:
+// the current user, or the owner of the git directory for root. The uid is -1
+// on platforms without Unix ownership.
+func (m *Manager) permissionSubject() (int, []int, error) {
+	me := os.Getuid()
+	if me != 0 {
+		if me < 0 {
+			return -1, nil, nil
+		}
+		groups, err := os.Getgroups()
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to look up groups: %w", err)
+		}
+		return me, groups, nil
+	}
+
+	info, err := os.Stat(m.gitCommonDir)
+	if err != nil {
+		return 0, nil, err
+	}
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return -1, nil, nil
+	}
+	groups := []int{gid}
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		if ids, err := u.GroupIds(); err == nil {
+			for _, id := range ids {
+				if n, err := strconv.Atoi(id); err == nil {
+					groups = append(groups, n)
+				}
+			}
+		}
+	}
+	return uid, groups, nil
+}
+
+// checkPermissions returns the owner of path and whether the user uid, a member
+// of groups, can write to it. It returns false if ownership is not available.
+func checkPermissions(path string, info fs.FileInfo, uid int, groups []int) (PermissionProblem, bool) {
+	owner, gid, ok := fileOwner(info)
+	if !ok {
+		return PermissionProblem{}, false
+	}
+	problem := PermissionProblem{
+		Path:        path,
+		UID:         owner,
+		Foreign:     owner != uid,
+		NotWritable: !writable(info.Mode(), owner, gid, uid, groups),
+	}
+	if u, err := user.LookupId(strconv.Itoa(owner)); err == nil {
+		problem.Owner = u.Username
+	}
+	return problem, true
+}
+
+// writable reports whether the user me, a member of groups, may write to a
+// directory with mode that is owned by uid and gid. Root may write anywhere.
+func writable(mode fs.FileMode, uid, gid, me int, groups []int) bool {
+	switch {
+	case me == 0:
+		return true
+	case uid == me:
+		return mode&0o200 != 0
+	case gid >= 0 && slices.Contains(groups, gid):
+		return mode&0o020 != 0
+	default:
+		return mode&0o002 != 0
+	}
+}
+
+// safeDirectories returns the safe.directory entries of the system and global
+// git configuration, the only scopes git honors for it.
+func (m *Manager) safeDirectories(ctx context.Context) []string {
+	var entries []string
+	for _, scope := range []string{"--system", "--global"} {
+		// git exits with 1 if the setting is absent
+		output, err := m.gitOutput(ctx, m.repoRoot, "config", scope, "--get-all", "safe.directory")
+		if err != nil {
+			continue
+		}
+		entries = append(entries, strings.Split(strings.TrimSuffix(output, "\n"), "\n")...)
+	}
+	return entries
+}
+
+// safeDirectoryCovers reports whether the safe.directory entries allow git to
+// work in path. An empty entry resets the list, "*" allows every directory and
+// entries ending in "/*" allow the directories below them.
+func safeDirectoryCovers(entries []string, path string) bool {
+	covered := false
+	for _, entry := range entries {
+		switch {
+		case entry == "":
+			covered = false
+		case entry == "*" || filepath.Clean(entry) == path:
+			covered = true
+		case strings.HasSuffix(entry, "/*") && strings.HasPrefix(path, strings.TrimSuffix(entry, "*")):
+			covered = true
+		}
+	}
+	return covered
+}
+
+// FixPermission repairs p as far as the current user can and returns what was done.
+// Root hands the tree at Path over to the owner of the git directory. Other users
+// trust worktrees of other users through safe.directory and restore write access
+// to files they own. Problems only the owner or root can fix are returned as an
+// error suggesting the command to run.
+func (m *Manager) FixPermission(ctx context.Context, p PermissionProblem) (string, error) {
+	if os.Getuid() == 0 {
+		info, err := os.Stat(m.gitCommonDir)
+		if err != nil {
+			return "", err
+		}
+		uid, gid, ok := fileOwner(info)
+		if !ok {
+			return "", fmt.Errorf("ownership of %s is not available", m.gitCommonDir)
+		}
+		if err := chownTree(p.Path, uid, gid); err != nil {
+			return "", fmt.Errorf("failed to change the owner of %s: %w", p.Path, err)
+		}
+		return fmt.Sprintf("changed the owner of %s to uid %d", p.Path, uid), nil
+	}
+
+	var done []string
+	if p.Unsafe {
+		if err := m.runGitCommand(ctx, "config", "--global", "--add", "safe.directory", p.Path); err != nil {
+			return "", fmt.Errorf("failed to add %s to safe.directory: %w", p.Path, err)
+		}
+		done = append(done, "added to safe.directory")
+	}
+	if p.NotWritable {
+		if p.Foreign {
+			return strings.Join(done, ", "), fmt.Errorf("%s is owned by %s; run 'sudo chown -R %s %s' or ask them to fix it",
+				p.Path, p.ownerName(), currentUserName(), utils.ShellQuote(p.Path))
+		}
+		if err := chmodTree(p.Path); err != nil {
+			return "", fmt.Errorf("failed to make %s writable: %w", p.Path, err)
+		}
+		done = append(done, "made writable")
+	}
+	return strings.Join(done, ", "), nil
+}
+
+// chownTree changes the owner of everything under root, without following symlinks.
+func chownTree(root string, uid, gid int) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+// chmodTree gives the owner write access to the files and directories under root
+// that they own, and access to the directories.
+func chmodTree(root string) error {
+	me := os.Getuid()
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if uid, _, ok := fileOwner(info); !ok || uid != me {
+			return nil
+		}
+		mode := info.Mode().Perm() | 0o200
+		if d.IsDir() {
+			mode |= 0o500
+		}
+		if mode == info.Mode().Perm() {
+			return nil
+		}
+		return os.Chmod(path, mode)
+	})
+}
+
+// currentUserName returns the name of the current user, for suggested commands.
+func currentUserName() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(os.Getuid())
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package worktree
+
+import "io/fs"
+
+// fileOwner reports that file ownership is not available on this platform.
+func fileOwner(fs.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
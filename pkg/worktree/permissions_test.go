@@ -0,0 +1,60 @@
+package worktree
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWritable(t *testing.T) {
+	for name, tt := range map[string]struct {
+		mode     fs.FileMode
+		uid, gid int
+		me       int
+		groups   []int
+		expected bool
+	}{
+		"owner":                     {mode: 0o755, uid: 1000, gid: 1000, me: 1000, expected: true},
+		"owner without write bit":   {mode: 0o555, uid: 1000, gid: 1000, me: 1000, expected: false},
+		"group member":              {mode: 0o775, uid: 1000, gid: 50, me: 1001, groups: []int{50}, expected: true},
+		"group member, group 0o755": {mode: 0o755, uid: 1000, gid: 50, me: 1001, groups: []int{50}, expected: false},
+		"other user":                {mode: 0o755, uid: 1000, gid: 1000, me: 1001, expected: false},
+		"world writable":            {mode: 0o777, uid: 1000, gid: 1000, me: 1001, expected: true},
+		"root":                      {mode: 0o555, uid: 1000, gid: 1000, me: 0, expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := writable(tt.mode, tt.uid, tt.gid, tt.me, tt.groups)
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("writable mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSafeDirectoryCovers(t *testing.T) {
+	const path = "/srv/repo/.worktree/feature"
+
+	for name, tt := range map[string]struct {
+		entries  []string
+		expected bool
+	}{
+		"none":             {entries: nil, expected: false},
+		"exact":            {entries: []string{"/srv/repo/.worktree/feature/"}, expected: true},
+		"other":            {entries: []string{"/srv/repo"}, expected: false},
+		"wildcard":         {entries: []string{"*"}, expected: true},
+		"prefix wildcard":  {entries: []string{"/srv/repo/*"}, expected: true},
+		"reset":            {entries: []string{"*", ""}, expected: false},
+		"reset then exact": {entries: []string{"*", "", path}, expected: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, safeDirectoryCovers(tt.entries, path)); diff != "" {
+				t.Errorf("safeDirectoryCovers mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
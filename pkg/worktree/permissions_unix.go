@@ -0,0 +1,17 @@
+//go:build unix
+
+package worktree
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileOwner returns the user and group owning the file described by info.
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
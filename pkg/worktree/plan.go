@@ -0,0 +1,358 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// Operations of a PlanStep.
+const (
+	// OpGit runs git.
+	OpGit = "git"
+
+	// OpFile changes files directly, without git.
+	OpFile = "file"
+
+	// OpRun runs a shell command, such as a setup step.
+	OpRun = "run"
+)
+
+// PlanStep is one operation of a Plan.
+type PlanStep struct {
+	Op string `json:"op"`
+
+	// Dir is the directory a git or shell command runs in.
+	Dir string `json:"dir,omitempty"`
+
+	// Args is the command line, starting with "git" for git commands. File
+	// operations have the equivalent shell command, such as "cp" or "ln -s", or
+	// none if they update giwo's state files.
+	Args []string `json:"args,omitempty"`
+
+	// Note says what the step is for.
+	Note string `json:"note,omitempty"`
+}
+
+// Plan is the sequence of operations a giwo command performs, in order, as
+// shown by 'giwo explain'. Building a plan only reads the repository.
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+// Git adds a git command run in dir.
+func (p *Plan) Git(dir, note string, args ...string) {
+	p.Steps = append(p.Steps, PlanStep{Op: OpGit, Dir: dir, Args: append([]string{"git"}, args...), Note: note})
+}
+
+// File adds a file operation, args being its shell equivalent if it has one.
+func (p *Plan) File(note string, args ...string) {
+	p.Steps = append(p.Steps, PlanStep{Op: OpFile, Args: args, Note: note})
+}
+
+// Run adds a shell command run in dir.
+func (p *Plan) Run(dir, note, command string) {
+	shell := utils.ScriptShell()
+	args := append([]string{shell.Path}, shell.ScriptArgs(command)...)
+	p.Steps = append(p.Steps, PlanStep{Op: OpRun, Dir: dir, Args: args, Note: note})
+}
+
+// String returns the command line of the step, quoted for the shell.
+func (s PlanStep) String() string {
+	if len(s.Args) > 0 && s.Op == OpGit && s.Dir != "" {
+		return utils.ShellJoin(append([]string{"git", "-C", s.Dir}, s.Args[1:]...))
+	}
+	if len(s.Args) > 0 && s.Op == OpRun && s.Dir != "" {
+		return "cd " + utils.ShellQuote(s.Dir) + " && " + utils.ShellJoin(s.Args)
+	}
+	return utils.ShellJoin(s.Args)
+}
+
+// metadataPath returns the file the worktree metadata is kept in.
+func (m *Manager) metadataPath() string {
+	return filepath.Join(m.StateDir(), metadataFileName)
+}
+
+// PlanCreate returns the operations Create performs for opts. It fails where
+// Create would fail before changing anything.
+func (m *Manager) PlanCreate(ctx context.Context, opts CreateOptions) (*Plan, error) {
+	name := opts.Branch
+	if opts.Name != "" {
+		name = opts.Name
+	}
+	base := opts.Base
+	if base == "" {
+		base = "main"
+	}
+	worktreePath := m.WorktreePath(name)
+	if err := m.checkNewWorktree(worktreePath, opts.Force); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	m.planWorktreeDir(plan)
+	plan.Git(m.repoRoot, "fetch the latest changes from origin", "fetch", "--prune")
+	m.planAddWorktree(plan, worktreePath, fmt.Sprintf("check out the new branch %s, starting at origin/%s", opts.Branch, base),
+		createArgs(opts.Branch, worktreePath, base))
+	if err := m.planFinishCreate(plan, worktreePath, opts.Branch, base); err != nil {
+		return nil, err
+	}
+	if name != opts.Branch {
+		plan.File(fmt.Sprintf("record %s as the pull request head in %s", opts.Branch, m.metadataPath()))
+	}
+	return plan, nil
+}
+
+// PlanCreateAt returns the operations CreateAt performs for the same arguments.
+func (m *Manager) PlanCreateAt(ctx context.Context, name, rev string, detach, force bool) (*Plan, error) {
+	worktreePath := m.WorktreePath(name)
+	if err := m.checkNewWorktree(worktreePath, force); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	m.planWorktreeDir(plan)
+	note := fmt.Sprintf("check out the new branch %s, starting at %s", name, rev)
+	branch := name
+	if detach {
+		note = fmt.Sprintf("check out %s with a detached HEAD", rev)
+		branch = ""
+	}
+	m.planAddWorktree(plan, worktreePath, note, createAtArgs(name, worktreePath, rev, detach))
+	if err := m.planFinishCreate(plan, worktreePath, branch, rev); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// PlanCreateFromTag returns the operations CreateFromTag performs for the same arguments.
+func (m *Manager) PlanCreateFromTag(ctx context.Context, branchName, tag string, force bool) (*Plan, error) {
+	plan := &Plan{}
+	plan.Git(m.repoRoot, "fetch the tags from origin", "fetch", "--tags")
+	at, err := m.PlanCreateAt(ctx, branchName, "refs/tags/"+tag, false, force)
+	if err != nil {
+		return nil, err
+	}
+	plan.Steps = append(plan.Steps, at.Steps...)
+	plan.File(fmt.Sprintf("record the worktree as a maintenance worktree of %s in %s", tag, m.metadataPath()))
+	return plan, nil
+}
+
+// checkNewWorktree fails like Create does if a worktree cannot be created at path.
+func (m *Manager) checkNewWorktree(path string, force bool) error {
+	if force {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, path)
+	}
+	return nil
+}
+
+// planWorktreeDir adds the creation of the worktree directory if it does not exist yet.
+func (m *Manager) planWorktreeDir(plan *Plan) {
+	if _, err := os.Stat(m.worktreeDir); err != nil {
+		plan.File("create the worktree directory", "mkdir", "-p", m.worktreeDir)
+	}
+}
+
+// planAddWorktree adds the 'git worktree add' of addWorktree with args for the
+// worktree at path, which is split up to set up a sparse checkout first.
+func (m *Manager) planAddWorktree(plan *Plan, path, note string, args []string) {
+	if len(m.checkout.Sparse) == 0 {
+		plan.Git(m.repoRoot, note, append([]string{"worktree", "add"}, args...)...)
+		return
+	}
+	plan.Git(m.repoRoot, note+", without checking out files yet", append([]string{"worktree", "add", "--no-checkout"}, args...)...)
+	plan.Git(path, "limit the worktree to "+strings.Join(m.checkout.Sparse, ", "), sparseArgs(m.checkout.Sparse)...)
+	plan.Git(path, "check out the files of the sparse directories", "checkout", "--force")
+}
+
+// planFinishCreate adds the steps of finishCreate for the worktree at path:
+// the submodules and LFS objects of the repository are checked out, the
+// template files that exist are copied, the links whose sources exist are
+// created and the setup steps are run.
+func (m *Manager) planFinishCreate(plan *Plan, path, branch, base string) error {
+	template, err := m.templateFor(branch)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the worktree template: %w", err)
+	}
+
+	// The worktree does not exist yet; the repository root has the same files in most cases
+	if m.checkout.Submodules && hasSubmodules(m.repoRoot) {
+		plan.Git(path, "check out the submodules", submoduleUpdateArgs(m.offline, false)...)
+	}
+	if m.checkout.LFS && usesLFS(m.repoRoot) {
+		plan.Git(path, "download the LFS objects", lfsArgs(m.offline)...)
+	}
+
+	for _, file := range template.Files {
+		source := filepath.Join(m.repoRoot, file)
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+		plan.File("copy a template file", "cp", source, filepath.Join(path, file))
+	}
+	plan.File(fmt.Sprintf("record %s as the base in %s", base, m.metadataPath()))
+	for _, rel := range template.Links {
+		source := filepath.Join(m.repoRoot, rel)
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+		plan.File("link a shared path", "ln", "-s", source, filepath.Join(path, rel))
+	}
+	if len(template.Setup) > 0 {
+		plan.File(fmt.Sprintf("allocate a port offset in %s", m.metadataPath()))
+	}
+	for _, step := range template.Setup {
+		plan.Run(path, "setup step "+step.Name, step.Run)
+	}
+	return nil
+}
+
+// PlanRemove returns the operations Remove performs for opts, which asks
+// opts.Confirm first. It fails where Remove would fail before changing anything.
+func (m *Manager) PlanRemove(ctx context.Context, opts RemoveOptions) (*Plan, error) {
+	worktreePath := m.WorktreePath(opts.Name)
+	wt, err := m.findRegistered(ctx, worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, worktreePath)
+	}
+	if wt.Locked {
+		return nil, lockedError(wt)
+	}
+	force, err := m.checkRemovable(ctx, worktreePath, opts.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	if meta, ok := store[worktreePath]; ok && len(meta.Submodules) > 0 {
+		plan.File("remove the submodule worktrees " + strings.Join(meta.Submodules, ", ") + " from their repositories")
+	}
+	plan.Git(m.repoRoot, "remove the worktree and its directory", removeArgs(worktreePath, force)...)
+	plan.File(fmt.Sprintf("forget the worktree's metadata and labels in %s", m.StateDir()))
+	if !opts.KeepBranch && !wt.Detached {
+		plan.Git(m.repoRoot, "delete the branch", "branch", "-D", wt.Branch)
+	}
+	return plan, nil
+}
+
+// hasChanges reports whether the worktree at path has uncommitted changes,
+// untracked files included, as Remove checks.
+func (m *Manager) hasChanges(ctx context.Context, path string) (bool, error) {
+	status, err := m.gitOutput(ctx, path, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(status) != "", nil
+}
+
+// PlanRename returns the operations Rename performs for the same arguments.
+// It fails where Rename would fail before changing anything.
+func (m *Manager) PlanRename(ctx context.Context, oldName, newName string) (*Plan, error) {
+	oldPath := m.WorktreePath(oldName)
+	newPath := m.WorktreePath(newName)
+
+	wt, err := m.findRegistered(ctx, oldPath)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, oldPath)
+	}
+	if wt.Locked {
+		return nil, lockedError(wt)
+	}
+	if wt.Detached {
+		return nil, fmt.Errorf("%w: %s has no branch to rename", errors.ErrDetachedHead, oldPath)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, newPath)
+	}
+	if m.refExists(ctx, "refs/heads/"+newName) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrBranchExists, newName)
+	}
+
+	plan := &Plan{}
+	plan.Git(m.repoRoot, "rename the branch", "branch", "-m", wt.Branch, newName)
+	if _, err := os.Stat(filepath.Dir(newPath)); err != nil {
+		plan.File("create the parent directory", "mkdir", "-p", filepath.Dir(newPath))
+	}
+	plan.Git(m.repoRoot, "move the worktree, rewriting its gitdir links", "worktree", "move", oldPath, newPath)
+	merge, err := m.gitOutput(ctx, m.repoRoot, "config", "--get", "branch."+wt.Branch+".merge")
+	if err == nil && strings.TrimSpace(merge) == "refs/heads/"+wt.Branch {
+		plan.Git(m.repoRoot, "point the upstream at the new branch name", "config", "branch."+newName+".merge", "refs/heads/"+newName)
+	}
+	plan.File(fmt.Sprintf("move the worktree's metadata and labels in %s", m.StateDir()))
+	return plan, nil
+}
+
+// PlanMove returns the operations Move performs for opts. It fails where Move
+// would fail before changing anything.
+func (m *Manager) PlanMove(ctx context.Context, opts MoveOptions) (*Plan, error) {
+	wt, err := m.findRegistered(ctx, opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, opts.Path)
+	}
+	if wt.Locked && !opts.Force {
+		return nil, lockedError(wt)
+	}
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if meta, ok := store[wt.Path]; ok && len(meta.Submodules) > 0 {
+		return nil, fmt.Errorf("%s has submodule worktrees, which git cannot move; remove and recreate it instead", wt.Path)
+	}
+
+	plan := &Plan{}
+	if _, err := os.Stat(filepath.Dir(opts.Dest)); err != nil {
+		plan.File("create the parent directory", "mkdir", "-p", filepath.Dir(opts.Dest))
+	}
+	plan.Git(m.repoRoot, "move the worktree, rewriting its gitdir links", moveArgs(wt, opts.Dest)...)
+	plan.File(fmt.Sprintf("move the worktree's metadata and labels in %s", m.StateDir()))
+	return plan, nil
+}
+
+// PlanIdentity adds the operations of SetIdentity to plan.
+func (m *Manager) PlanIdentity(plan *Plan, name, email string) {
+	if name != "" {
+		plan.Git(m.repoRoot, "set the git identity", "config", "user.name", name)
+	}
+	if email != "" {
+		plan.Git(m.repoRoot, "set the git identity", "config", "user.email", email)
+	}
+}
+
+// PlanPush adds the operations of Push to plan.
+func (m *Manager) PlanPush(plan *Plan, branchName string, setUpstream bool) {
+	plan.Git(m.repoRoot, "push the branch to origin", pushArgs(branchName, setUpstream)...)
+}
+
+// PlanSetUpstream adds the operations of SetUpstream to plan.
+func (m *Manager) PlanSetUpstream(plan *Plan, branchName string) {
+	plan.Git(m.repoRoot, "track origin/"+branchName, "config", fmt.Sprintf("branch.%s.remote", branchName), "origin")
+	plan.Git(m.repoRoot, "track origin/"+branchName, "config", fmt.Sprintf("branch.%s.merge", branchName), "refs/heads/"+branchName)
+}
+
+// PlanMarkEphemeral adds the operations of MarkEphemeral to plan.
+func (m *Manager) PlanMarkEphemeral(plan *Plan) {
+	plan.File(fmt.Sprintf("mark the worktree as ephemeral in %s", m.metadataPath()))
+}
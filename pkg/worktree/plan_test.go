@@ -0,0 +1,107 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlanStepString(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		step PlanStep
+		want string
+	}{
+		"git": {
+			step: PlanStep{Op: OpGit, Dir: "/repo", Args: []string{"git", "worktree", "add", "-b", "feature-x", "/repo/.worktree/feature-x", "origin/main"}},
+			want: "git -C /repo worktree add -b feature-x /repo/.worktree/feature-x origin/main",
+		},
+		"quoted": {
+			step: PlanStep{Op: OpGit, Dir: "/my repo", Args: []string{"git", "config", "user.name", "Ken Woo"}},
+			want: "git -C '/my repo' config user.name 'Ken Woo'",
+		},
+		"file": {
+			step: PlanStep{Op: OpFile, Args: []string{"cp", "/repo/.env", "/repo/.worktree/a/.env"}},
+			want: "cp /repo/.env /repo/.worktree/a/.env",
+		},
+		"state file": {
+			step: PlanStep{Op: OpFile, Note: "record main as the base"},
+			want: "",
+		},
+		"run": {
+			step: PlanStep{Op: OpRun, Dir: "/repo/.worktree/a", Args: []string{"sh", "-c", "npm ci"}},
+			want: "cd /repo/.worktree/a && sh -c 'npm ci'",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, tt.step.String()); diff != "" {
+				t.Errorf("String() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMoveArgs(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		wt   *Worktree
+		want []string
+	}{
+		"unlocked": {
+			wt:   &Worktree{Path: "/repo/.worktree/a"},
+			want: []string{"worktree", "move", "/repo/.worktree/a", "/repo/.worktree/b"},
+		},
+		"locked": {
+			wt:   &Worktree{Path: "/repo/.worktree/a", Locked: true},
+			want: []string{"worktree", "move", "--force", "--force", "/repo/.worktree/a", "/repo/.worktree/b"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, moveArgs(tt.wt, "/repo/.worktree/b")); diff != "" {
+				t.Errorf("moveArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanAddWorktree(t *testing.T) {
+	t.Parallel()
+
+	const path = "/repo/.worktree/a"
+	args := []string{"-b", "a", path, "origin/main"}
+	for name, tt := range map[string]struct {
+		sparse []string
+		want   []PlanStep
+	}{
+		"full checkout": {
+			want: []PlanStep{
+				{Op: OpGit, Dir: "/repo", Args: []string{"git", "worktree", "add", "-b", "a", path, "origin/main"}, Note: "check out a"},
+			},
+		},
+		"sparse checkout": {
+			sparse: []string{"services/api", "libs/common"},
+			want: []PlanStep{
+				{Op: OpGit, Dir: "/repo", Args: []string{"git", "worktree", "add", "--no-checkout", "-b", "a", path, "origin/main"}, Note: "check out a, without checking out files yet"},
+				{Op: OpGit, Dir: path, Args: []string{"git", "sparse-checkout", "set", "--cone", "--", "services/api", "libs/common"}, Note: "limit the worktree to services/api, libs/common"},
+				{Op: OpGit, Dir: path, Args: []string{"git", "checkout", "--force"}, Note: "check out the files of the sparse directories"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &Manager{repoRoot: "/repo", checkout: CheckoutOptions{Sparse: tt.sparse}}
+			plan := &Plan{}
+			m.planAddWorktree(plan, path, "check out a", args)
+			if diff := cmp.Diff(tt.want, plan.Steps); diff != "" {
+				t.Errorf("planAddWorktree() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
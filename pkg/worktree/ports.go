@@ -0,0 +1,102 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+)
+
+// PortOptions controls the port offsets allocated to worktrees, which let dev
+// servers of several worktrees run side by side, e.g. on 3000 plus the offset.
+type PortOptions struct {
+	// Step is the distance between offsets, leaving room for several ports per worktree.
+	// Defaults to 10.
+	Step int
+
+	// Slots is the number of offsets available, so offsets stay below Step*Slots.
+	// Defaults to 100.
+	Slots int
+
+	// Fixed maps branches to offsets chosen by the user instead of allocated.
+	Fixed map[string]int
+}
+
+// SetPortOptions sets how port offsets are allocated.
+func (m *Manager) SetPortOptions(opts PortOptions) {
+	m.ports = opts
+}
+
+// PortOffset returns the port offset of wt. The main worktree's offset is 0.
+// Other worktrees get the slot derived from a hash of their name, or the next
+// free one if it is taken, so the offset usually survives re-creating the
+// worktree. Once allocated, the offset is recorded and kept for the lifetime
+// of the worktree.
+func (m *Manager) PortOffset(ctx context.Context, wt *Worktree) (int, error) {
+	if offset, ok := m.ports.Fixed[wt.Branch]; ok && wt.Branch != "" {
+		return offset, nil
+	}
+
+	// git lists the main worktree first
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(worktrees) > 0 && worktrees[0].Path == wt.Path {
+		return 0, nil
+	}
+
+	store, err := m.loadMetadata()
+	if err != nil {
+		return 0, err
+	}
+	if meta, ok := store[wt.Path]; ok && meta.PortOffset > 0 {
+		return meta.PortOffset, nil
+	}
+
+	offset, err := allocatePortOffset(store, wt.Path, m.ports)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.updateMetadata(wt.Path, func(meta *Metadata) { meta.PortOffset = offset }); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// allocatePortOffset picks a free offset for the worktree at path, avoiding the
+// offsets recorded in store and the fixed ones. Slot 0 belongs to the main worktree.
+func allocatePortOffset(store metadataStore, path string, opts PortOptions) (int, error) {
+	step, slots := opts.Step, opts.Slots
+	if step <= 0 {
+		step = 10
+	}
+	if slots <= 0 {
+		slots = 100
+	}
+	if slots < 2 {
+		return 0, fmt.Errorf("no port offsets available: ports.slots must be at least 2")
+	}
+
+	taken := map[int]bool{0: true}
+	for other, meta := range store {
+		if other != path && meta.PortOffset > 0 {
+			taken[meta.PortOffset] = true
+		}
+	}
+	for _, offset := range opts.Fixed {
+		taken[offset] = true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(filepath.Base(path)))
+	first := 1 + int(h.Sum32()%uint32(slots-1))
+
+	for i := 0; i < slots-1; i++ {
+		slot := 1 + (first-1+i)%(slots-1)
+		if !taken[slot*step] {
+			return slot * step, nil
+		}
+	}
+	return 0, fmt.Errorf("all %d port offsets are taken; raise ports.slots or prune worktrees", slots-1)
+}
@@ -0,0 +1,61 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAllocatePortOffset(t *testing.T) {
+	t.Parallel()
+
+	const path = "/repo/.worktree/feature-auth"
+	opts := PortOptions{Step: 10, Slots: 5}
+
+	// The same name always hashes to the same offset
+	first, err := allocatePortOffset(metadataStore{}, path, opts)
+	if err != nil {
+		t.Fatalf("allocatePortOffset failed: %v", err)
+	}
+	if first <= 0 || first >= 50 || first%10 != 0 {
+		t.Fatalf("expected a non-zero multiple of 10 below 50, got %d", first)
+	}
+	again, err := allocatePortOffset(metadataStore{}, "/elsewhere/feature-auth", opts)
+	if err != nil {
+		t.Fatalf("allocatePortOffset failed: %v", err)
+	}
+	if diff := cmp.Diff(first, again); diff != "" {
+		t.Errorf("offset not stable (-want +got):\n%s", diff)
+	}
+
+	// Taken offsets are skipped, including fixed ones
+	store := metadataStore{"/repo/.worktree/other": {PortOffset: first}}
+	next, err := allocatePortOffset(store, path, opts)
+	if err != nil {
+		t.Fatalf("allocatePortOffset failed: %v", err)
+	}
+	if next == first || next == 0 {
+		t.Errorf("expected an offset other than %d and 0, got %d", first, next)
+	}
+
+	// The worktree's own recorded offset does not count as taken
+	store = metadataStore{path: {PortOffset: first}}
+	own, err := allocatePortOffset(store, path, opts)
+	if err != nil {
+		t.Fatalf("allocatePortOffset failed: %v", err)
+	}
+	if diff := cmp.Diff(first, own); diff != "" {
+		t.Errorf("own offset mismatch (-want +got):\n%s", diff)
+	}
+
+	// All slots taken
+	store = metadataStore{
+		"/a": {PortOffset: 10},
+		"/b": {PortOffset: 20},
+		"/c": {PortOffset: 30},
+	}
+	full := PortOptions{Step: 10, Slots: 5, Fixed: map[string]int{"main": 40}}
+	if _, err := allocatePortOffset(store, path, full); err == nil {
+		t.Error("expected an error when all offsets are taken")
+	}
+}
@@ -0,0 +1,195 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// KindPristine marks the worktree giwo keeps of the default branch, which
+// nobody works in and which follows the branch on origin; see EnsurePristine.
+const KindPristine = "pristine"
+
+// DefaultBranch returns the default branch of the repository: the branch
+// origin/HEAD points at, otherwise main or master, whichever exists, and "main"
+// if neither does.
+func (m *Manager) DefaultBranch(ctx context.Context) string {
+	if output, err := m.gitOutput(ctx, m.repoRoot, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := parseOriginHead(output); branch != "" {
+			return branch
+		}
+	}
+	for _, branch := range []string{"main", "master"} {
+		if m.refExists(ctx, "refs/remotes/origin/"+branch) || m.refExists(ctx, "refs/heads/"+branch) {
+			return branch
+		}
+	}
+	return "main"
+}
+
+// parseOriginHead returns the branch of the output of 'git symbolic-ref --short
+// refs/remotes/origin/HEAD', such as "main" for "origin/main".
+func parseOriginHead(output string) string {
+	branch, ok := strings.CutPrefix(strings.TrimSpace(output), "origin/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// Pristine returns the worktree giwo keeps of the default branch, or nil if
+// there is none. Its Base is the branch it follows.
+func (m *Manager) Pristine(ctx context.Context) (*Worktree, error) {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wt := range worktrees {
+		if meta, ok := store[wt.Path]; ok && meta.Kind == KindPristine {
+			wt.Kind = meta.Kind
+			wt.Base = meta.Base
+			return wt, nil
+		}
+	}
+	return nil, nil
+}
+
+// EnsurePristine returns the worktree giwo keeps of branch, creating it in the
+// worktree directory if there is none yet, and reports whether it was created.
+//
+// Git checks a branch out in one worktree only, so the worktree has the branch
+// itself checked out only if no other worktree has; otherwise, typically when
+// the branch is checked out in the repository root, it has the branch's commit
+// on origin checked out with a detached HEAD.
+func (m *Manager) EnsurePristine(ctx context.Context, branch string) (*Worktree, bool, error) {
+	if wt, err := m.Pristine(ctx); err != nil || wt != nil {
+		return wt, false, err
+	}
+
+	worktreePath := m.WorktreePath(branch)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return nil, false, fmt.Errorf("%w: %s", errors.ErrWorktreeExists, worktreePath)
+	}
+	if err := os.MkdirAll(m.worktreeDir, 0o755); err != nil {
+		return nil, false, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	if err := m.fetch(ctx, "--prune"); err != nil {
+		return nil, false, fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	checkedOut := false
+	for _, wt := range worktrees {
+		checkedOut = checkedOut || wt.Branch == branch
+	}
+	args := pristineAddArgs(worktreePath, branch, checkedOut,
+		m.refExists(ctx, "refs/heads/"+branch), m.refExists(ctx, "refs/remotes/origin/"+branch))
+	if args == nil {
+		return nil, false, fmt.Errorf("%w: %s", errors.ErrBranchNotFound, branch)
+	}
+	if err := m.addWorktree(ctx, worktreePath, args...); err != nil {
+		return nil, false, fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	checkout := branch
+	if checkedOut {
+		checkout = ""
+	}
+	m.finishCreate(ctx, worktreePath, checkout, branch)
+	if err := m.updateMetadata(worktreePath, func(meta *Metadata) { meta.Kind = KindPristine }); err != nil {
+		return nil, false, fmt.Errorf("failed to record the pristine worktree: %w", err)
+	}
+
+	wt, err := m.Pristine(ctx)
+	return wt, true, err
+}
+
+// pristineAddArgs returns the arguments of 'git worktree add' for the pristine
+// worktree of branch at path, or nil if the branch exists neither locally nor
+// on origin. checkedOut tells whether another worktree has the branch.
+func pristineAddArgs(path, branch string, checkedOut, local, remote bool) []string {
+	switch {
+	case checkedOut && remote:
+		return []string{"--detach", path, "origin/" + branch}
+	case checkedOut:
+		return []string{"--detach", path, branch}
+	case local:
+		return []string{path, branch}
+	case remote:
+		return []string{"--track", "-b", branch, path, "origin/" + branch}
+	}
+	return nil
+}
+
+// SyncPristine brings the pristine worktree wt up to date with its branch on
+// origin: the branch is fast-forwarded, or the detached HEAD moved. It fails
+// with ErrWorktreeDirty if someone changed tracked files in the worktree, which
+// would otherwise get in the way or be lost.
+func (m *Manager) SyncPristine(ctx context.Context, wt *Worktree) error {
+	if dirty, err := m.HasTrackedChanges(ctx, wt.Path); err != nil {
+		return err
+	} else if dirty {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeDirty, wt.Path)
+	}
+
+	if err := m.fetch(ctx, "origin", wt.Base); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+	upstream := "origin/" + wt.Base
+
+	args := []string{"checkout", "--quiet", "--detach", upstream}
+	if wt.Branch != "" {
+		args = []string{"merge", "--ff-only", "--quiet", upstream}
+	}
+	done := m.step("Updating " + wt.Base)
+	err := m.runGit(ctx, GitCommand{Dir: wt.Path, Args: args})
+	done(err)
+	if err != nil {
+		return fmt.Errorf("failed to update %s to %s: %w", wt.Path, upstream, err)
+	}
+	return nil
+}
+
+// DirtyCheckout returns the worktree that has branch checked out if it has
+// uncommitted changes to tracked files, or nil. New worktrees start from the
+// branch's commits, so such changes are not part of them.
+func (m *Manager) DirtyCheckout(ctx context.Context, branch string) (*Worktree, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wt := range worktrees {
+		if wt.Branch != branch || wt.Prunable {
+			continue
+		}
+		dirty, err := m.HasTrackedChanges(ctx, wt.Path)
+		if err != nil || !dirty {
+			return nil, err
+		}
+		return wt, nil
+	}
+	return nil, nil
+}
+
+// HasTrackedChanges reports whether tracked files in the worktree at path
+// have uncommitted changes. Untracked files, such as copied templates, do not count.
+func (m *Manager) HasTrackedChanges(ctx context.Context, path string) (bool, error) {
+	output, err := m.gitOutput(ctx, path, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return false, fmt.Errorf("failed to get the status of %s: %w", path, err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
@@ -0,0 +1,69 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseOriginHead(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		output string
+		want   string
+	}{
+		"main":               {output: "origin/main\n", want: "main"},
+		"slash in name":      {output: "origin/release/2.x\n", want: "release/2.x"},
+		"another remote":     {output: "upstream/main\n", want: ""},
+		"empty":              {output: "", want: ""},
+		"only remote prefix": {output: "origin/", want: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, parseOriginHead(tt.output)); diff != "" {
+				t.Errorf("parseOriginHead() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPristineAddArgs(t *testing.T) {
+	t.Parallel()
+
+	const path = "/repo/.worktree/main"
+	for name, tt := range map[string]struct {
+		checkedOut, local, remote bool
+		want                      []string
+	}{
+		"checked out in the repository root": {
+			checkedOut: true, local: true, remote: true,
+			want: []string{"--detach", path, "origin/main"},
+		},
+		"checked out without a remote": {
+			checkedOut: true, local: true,
+			want: []string{"--detach", path, "main"},
+		},
+		"free local branch": {
+			local: true, remote: true,
+			want: []string{path, "main"},
+		},
+		"only on origin": {
+			remote: true,
+			want:   []string{"--track", "-b", "main", path, "origin/main"},
+		},
+		"missing": {
+			want: nil,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := pristineAddArgs(path, "main", tt.checkedOut, tt.local, tt.remote)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("pristineAddArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// ProgressEvent reports the progress of one phase of a long-running git operation,
+// such as receiving objects during a fetch or updating files during checkout.
+type ProgressEvent struct {
+	// Phase is git's name for the phase, e.g. "Receiving objects" or "Updating files".
+	Phase string `json:"phase"`
+
+	// Current and Total count the units processed; Total is zero when git does not know it.
+	Current int64 `json:"current"`
+	Total   int64 `json:"total,omitempty"`
+
+	// Percent is the completion percentage reported by git.
+	Percent int `json:"percent,omitempty"`
+
+	// Throughput is the transfer rate reported by git, e.g. "2.10 MiB/s".
+	Throughput string `json:"throughput,omitempty"`
+
+	// ETA estimates the time left in the phase from its progress so far.
+	ETA time.Duration `json:"-"`
+
+	// ETASeconds is ETA in whole seconds, for consumers of the JSON event stream.
+	ETASeconds int64 `json:"eta_seconds,omitempty"`
+
+	// Done is set on the final event of a phase.
+	Done bool `json:"done,omitempty"`
+}
+
+// ProgressFunc receives progress events. It is called from the goroutine running git.
+type ProgressFunc func(ProgressEvent)
+
+var (
+	// progressCountedPattern matches lines such as "Receiving objects:  45% (450/1000), 1.20 MiB | 2.10 MiB/s".
+	progressCountedPattern = regexp.MustCompile(`^(?:remote: )?([A-Za-z][A-Za-z ]*):\s+(\d+)% \((\d+)/(\d+)\)(.*)$`)
+
+	// progressUncountedPattern matches lines such as "remote: Enumerating objects: 1234, done.".
+	progressUncountedPattern = regexp.MustCompile(`^(?:remote: )?([A-Za-z][A-Za-z ]*):\s+(\d+)(.*)$`)
+)
+
+// StepFunc is told when a step of a long operation starts, e.g. "Fetching origin",
+// and returns the function to call with the step's result once it ends.
+type StepFunc func(name string) func(error)
+
+// SetSteps makes long operations such as fetching, checking out and removing
+// worktrees report their steps to fn.
+func (m *Manager) SetSteps(fn StepFunc) {
+	m.steps = fn
+}
+
+// step reports the start of a step and returns the function ending it.
+func (m *Manager) step(name string) func(error) {
+	if m.steps == nil {
+		return func(error) {}
+	}
+	return m.steps(name)
+}
+
+// SetProgress makes create operations report fetch and checkout progress to fn.
+// Without it, git runs quietly as before.
+func (m *Manager) SetProgress(fn ProgressFunc) {
+	m.progress = fn
+}
+
+// fetch runs git fetch with args, reporting progress if a progress function is set.
+func (m *Manager) fetch(ctx context.Context, args ...string) error {
+	if m.offline {
+		m.skipFetch()
+		return nil
+	}
+
+	done := m.step("Fetching origin")
+	var err error
+	if m.progress == nil {
+		err = m.runGitCommand(ctx, append([]string{"fetch"}, args...)...)
+	} else {
+		err = m.runGitProgress(ctx, m.repoRoot, append([]string{"fetch", "--progress"}, args...)...)
+	}
+	done(err)
+
+	if IsNetworkError(err) {
+		m.goOffline(err)
+		return nil
+	}
+	return err
+}
+
+// addWorktree runs 'git worktree add' with args for the worktree at path.
+// With a progress function set, the checkout is run separately so that git reports its progress.
+// So it is for a sparse checkout, which must be set up before files are checked out.
+func (m *Manager) addWorktree(ctx context.Context, path string, args ...string) (err error) {
+	done := m.step("Checking out " + filepath.Base(path))
+	defer func() { done(err) }()
+
+	sparse := len(m.checkout.Sparse) > 0
+	if m.progress == nil && !sparse {
+		return worktreeAddError(m.runGitCommand(ctx, append([]string{"worktree", "add"}, args...)...))
+	}
+
+	if err := m.runGitCommand(ctx, append([]string{"worktree", "add", "--no-checkout"}, args...)...); err != nil {
+		return worktreeAddError(err)
+	}
+	if sparse {
+		if err := m.sparseCheckout(ctx, path); err != nil {
+			return err
+		}
+	}
+	if m.progress == nil {
+		_, err := m.gitOutput(ctx, path, "checkout", "--force")
+		return err
+	}
+	return m.runGitProgress(ctx, path, "checkout", "--progress", "--force")
+}
+
+// runGitProgress runs a git command in dir, parsing its progress output into events.
+// Other output on stderr is included in the returned error.
+func (m *Manager) runGitProgress(ctx context.Context, dir string, args ...string) error {
+	writer := &progressWriter{fn: m.progress, now: time.Now}
+
+	err := m.runGit(ctx, GitCommand{Dir: dir, Args: args, Stderr: writer})
+	writer.flush()
+	if err != nil {
+		if msg := strings.TrimSpace(writer.other.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return errors.NewGitError(args[0], args[1:], err)
+	}
+	return nil
+}
+
+// progressWriter turns git's carriage-return separated progress output into events.
+type progressWriter struct {
+	fn  ProgressFunc
+	now func() time.Time
+
+	line    []byte
+	other   bytes.Buffer
+	phase   string
+	started time.Time
+}
+
+// Write implements io.Writer.
+func (w *progressWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b != '\r' && b != '\n' {
+			w.line = append(w.line, b)
+			continue
+		}
+		w.handleLine(string(w.line))
+		w.line = w.line[:0]
+	}
+	return len(p), nil
+}
+
+// flush handles any incomplete final line.
+func (w *progressWriter) flush() {
+	if len(w.line) > 0 {
+		w.handleLine(string(w.line))
+		w.line = nil
+	}
+}
+
+// handleLine reports a progress line as an event and keeps any other line for error messages.
+func (w *progressWriter) handleLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	event, ok := parseProgressLine(line)
+	if !ok {
+		w.other.WriteString(line)
+		w.other.WriteByte('\n')
+		return
+	}
+
+	now := w.now()
+	if event.Phase != w.phase {
+		w.phase = event.Phase
+		w.started = now
+	}
+	event.ETA = estimateRemaining(now.Sub(w.started), event.Current, event.Total)
+	event.ETASeconds = int64(event.ETA / time.Second)
+
+	w.fn(event)
+}
+
+// parseProgressLine parses one line of git progress output.
+func parseProgressLine(line string) (ProgressEvent, bool) {
+	if match := progressCountedPattern.FindStringSubmatch(line); match != nil {
+		percent, _ := strconv.Atoi(match[2])
+		current, _ := strconv.ParseInt(match[3], 10, 64)
+		total, _ := strconv.ParseInt(match[4], 10, 64)
+		rest := match[5]
+
+		event := ProgressEvent{
+			Phase:   match[1],
+			Current: current,
+			Total:   total,
+			Percent: percent,
+			Done:    strings.HasSuffix(rest, "done."),
+		}
+		// The transfer rate follows the amount received, e.g. ", 1.20 MiB | 2.10 MiB/s"
+		if _, rate, ok := strings.Cut(rest, "|"); ok {
+			rate, _, _ = strings.Cut(rate, ",")
+			event.Throughput = strings.TrimSpace(rate)
+		}
+		return event, true
+	}
+
+	if match := progressUncountedPattern.FindStringSubmatch(line); match != nil {
+		current, _ := strconv.ParseInt(match[2], 10, 64)
+		return ProgressEvent{
+			Phase:   match[1],
+			Current: current,
+			Done:    strings.HasSuffix(match[3], "done."),
+		}, true
+	}
+
+	return ProgressEvent{}, false
+}
+
+// estimateRemaining extrapolates the time left from the time taken so far.
+// It returns zero when there is not enough information yet.
+func estimateRemaining(elapsed time.Duration, current, total int64) time.Duration {
+	if total <= 0 || current <= 0 || current >= total || elapsed < time.Second {
+		return 0
+	}
+	remaining := time.Duration(float64(elapsed) * float64(total-current) / float64(current))
+	return remaining.Round(time.Second)
+}
@@ -0,0 +1,98 @@
+package worktree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseProgressLine(t *testing.T) {
+	for name, tt := range map[string]struct {
+		line     string
+		expected ProgressEvent
+		ok       bool
+	}{
+		"receiving with throughput": {
+			line:     "Receiving objects:  45% (450/1000), 1.20 MiB | 2.10 MiB/s",
+			expected: ProgressEvent{Phase: "Receiving objects", Current: 450, Total: 1000, Percent: 45, Throughput: "2.10 MiB/s"},
+			ok:       true,
+		},
+		"receiving done": {
+			line:     "Receiving objects: 100% (1000/1000), 2.40 MiB | 2.30 MiB/s, done.",
+			expected: ProgressEvent{Phase: "Receiving objects", Current: 1000, Total: 1000, Percent: 100, Throughput: "2.30 MiB/s", Done: true},
+			ok:       true,
+		},
+		"lfs download": {
+			line:     "Downloading LFS objects:  50% (3/6), 12 MB | 4.1 MB/s",
+			expected: ProgressEvent{Phase: "Downloading LFS objects", Current: 3, Total: 6, Percent: 50, Throughput: "4.1 MB/s"},
+			ok:       true,
+		},
+		"checkout": {
+			line:     "Updating files:  50% (20000/40000)",
+			expected: ProgressEvent{Phase: "Updating files", Current: 20000, Total: 40000, Percent: 50},
+			ok:       true,
+		},
+		"remote counting": {
+			line:     "remote: Enumerating objects: 1234, done.",
+			expected: ProgressEvent{Phase: "Enumerating objects", Current: 1234, Done: true},
+			ok:       true,
+		},
+		"other output": {
+			line: "From github.com:knwoop/giwo",
+		},
+		"error": {
+			line: "fatal: couldn't find remote ref main",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseProgressLine(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("parseProgressLine ok = %v, want %v", ok, tt.ok)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("parseProgressLine mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := []time.Time{start, start.Add(10 * time.Second)}
+
+	var events []ProgressEvent
+	w := &progressWriter{
+		fn: func(event ProgressEvent) { events = append(events, event) },
+		now: func() time.Time {
+			now := clock[0]
+			if len(clock) > 1 {
+				clock = clock[1:]
+			}
+			return now
+		},
+	}
+
+	// Progress lines are separated by carriage returns and may be split across writes
+	w.Write([]byte("From /tmp/origin\nUpdating files:  10% (4000/40"))
+	w.Write([]byte("000)\rUpdating files:  25% (10000/40000)\r"))
+	w.Write([]byte("error: trailing message"))
+	w.flush()
+
+	if diff := cmp.Diff(2, len(events)); diff != "" {
+		t.Fatalf("event count mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(30*time.Second, events[1].ETA); diff != "" {
+		t.Errorf("ETA mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(int64(30), events[1].ETASeconds); diff != "" {
+		t.Errorf("ETASeconds mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("From /tmp/origin\nerror: trailing message\n", w.other.String()); diff != "" {
+		t.Errorf("other output mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,70 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PromptStatus returns the worktree the manager works in with its status, for
+// shell prompts. Its HEAD is read from the git directory, and its status taken
+// from the status cache while the cache is valid, so that in the common case no
+// git command runs. Otherwise the status of this worktree alone is computed and
+// cached for the next prompt.
+func (m *Manager) PromptStatus(ctx context.Context) (*Worktree, error) {
+	if m.bare && m.repoRoot == m.mainRoot {
+		return nil, fmt.Errorf("a bare repository has no worktree to show")
+	}
+
+	wt := &Worktree{Path: m.repoRoot, IsMain: m.repoRoot == m.mainRoot}
+	if exists(filepath.Join(m.gitCommonDir, "reftable")) || !readHead(wt, worktreeGitDir(wt.Path), m.gitCommonDir) {
+		// Layouts go-git does not handle
+		head, err := m.gitOutput(ctx, wt.Path, "rev-parse", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		wt.Head = strings.TrimSpace(head)
+		if wt.Branch, err = m.GetCurrentBranch(ctx); err != nil {
+			wt.Branch, wt.Detached = "HEAD", true
+		}
+	}
+
+	cache := loadStatusCache(filepath.Join(m.StateDir(), cacheFileName))
+	if !m.noCache {
+		if status, ok := cache.get(wt.Path, m.statusCacheKey(wt)); ok {
+			status.apply(wt)
+			return wt, nil
+		}
+	}
+
+	if err := m.enrichWorktree(ctx, wt); err != nil {
+		return nil, fmt.Errorf("failed to get status of %s: %w", wt.Path, err)
+	}
+	// The cache is only an optimization, so failing to write it is not an error
+	cache.put(wt.Path, m.statusCacheKey(wt), statusFromWorktree(wt))
+	_ = cache.save()
+
+	return wt, nil
+}
+
+// PromptSegment formats a worktree called name for a shell prompt: its name,
+// marked with * if it has uncommitted changes, and the commits it is ahead of
+// and behind its upstream, such as "feature-auth* ⇡2⇣1".
+func PromptSegment(wt *Worktree, name string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	if !wt.IsClean {
+		b.WriteString("*")
+	}
+	if wt.Ahead > 0 || wt.Behind > 0 {
+		b.WriteString(" ")
+	}
+	if wt.Ahead > 0 {
+		fmt.Fprintf(&b, "⇡%d", wt.Ahead)
+	}
+	if wt.Behind > 0 {
+		fmt.Fprintf(&b, "⇣%d", wt.Behind)
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPromptSegment(t *testing.T) {
+	for name, tt := range map[string]struct {
+		worktree *Worktree
+		expected string
+	}{
+		"clean":          {worktree: &Worktree{IsClean: true}, expected: "feature-auth"},
+		"dirty":          {worktree: &Worktree{Modified: 1}, expected: "feature-auth*"},
+		"ahead":          {worktree: &Worktree{IsClean: true, Ahead: 2}, expected: "feature-auth ⇡2"},
+		"behind":         {worktree: &Worktree{IsClean: true, Behind: 3}, expected: "feature-auth ⇣3"},
+		"dirty diverged": {worktree: &Worktree{Ahead: 2, Behind: 1}, expected: "feature-auth* ⇡2⇣1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, PromptSegment(tt.worktree, "feature-auth")); diff != "" {
+				t.Errorf("PromptSegment() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Outcomes of rebasing a worktree.
+const (
+	RebaseDone     = "rebased"
+	RebaseUpToDate = "up-to-date"
+	RebaseConflict = "conflict"
+	RebaseSkipped  = "skipped"
+)
+
+// RebaseOptions controls how Rebase deals with conflicts.
+type RebaseOptions struct {
+	// KeepConflicts leaves a conflicted rebase in progress for the user to
+	// resolve, instead of aborting it.
+	KeepConflicts bool
+}
+
+// RebaseResult describes how rebasing a worktree went.
+type RebaseResult struct {
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+
+	// Status is one of RebaseDone, RebaseUpToDate, RebaseConflict and RebaseSkipped.
+	Status string `json:"status"`
+
+	// Reason tells why the worktree was skipped.
+	Reason string `json:"reason,omitempty"`
+
+	// Conflicts lists the files that conflicted, while rebasing or while
+	// restoring the stashed changes.
+	Conflicts []string `json:"conflicts,omitempty"`
+
+	// Stashed tells whether uncommitted changes were stashed for the rebase.
+	// StashKept tells that they are still in the stash, since they could not
+	// be restored or the rebase was left in progress.
+	Stashed   bool `json:"stashed,omitempty"`
+	StashKept bool `json:"stash_kept,omitempty"`
+
+	// InProgress tells that the conflicted rebase was left for the user.
+	InProgress bool `json:"in_progress,omitempty"`
+}
+
+// rebaseStashMessage marks the stash entries Rebase creates.
+const rebaseStashMessage = "giwo rebase"
+
+// RebaseTarget fetches branch from origin and returns the ref to rebase onto:
+// the branch on origin if there is one, else the local branch.
+func (m *Manager) RebaseTarget(ctx context.Context, branch string) (string, error) {
+	if m.RemoteURL(ctx) != "" {
+		if err := m.fetch(ctx, "origin", branch); err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", branch, err)
+		}
+		if m.refExists(ctx, "refs/remotes/origin/"+branch) {
+			return "origin/" + branch, nil
+		}
+	}
+	if m.refExists(ctx, "refs/heads/"+branch) {
+		return branch, nil
+	}
+	return "", fmt.Errorf("%w: %s", errors.ErrBranchNotFound, branch)
+}
+
+// Rebase rebases the branch of wt onto the ref onto, as returned by
+// RebaseTarget. Uncommitted changes are stashed for the rebase and restored
+// afterwards. A rebase that conflicts is aborted, leaving the worktree as it
+// was, unless opts.KeepConflicts is set.
+func (m *Manager) Rebase(ctx context.Context, wt *Worktree, onto string, opts RebaseOptions) (*RebaseResult, error) {
+	result := &RebaseResult{Branch: wt.Branch, Path: wt.Path}
+	if wt.Detached {
+		result.Branch = ""
+	}
+	// A rebase in progress detaches HEAD, so it is looked for first
+	if !wt.Prunable && m.operationInProgress(ctx, wt.Path) {
+		result.Status, result.Reason = RebaseSkipped, "a rebase or merge is in progress"
+		return result, nil
+	}
+	if reason := rebaseSkipReason(wt, onto); reason != "" {
+		result.Status, result.Reason = RebaseSkipped, reason
+		return result, nil
+	}
+
+	if err := m.runGit(ctx, GitCommand{Dir: wt.Path, Args: []string{"merge-base", "--is-ancestor", onto, "HEAD"}}); err == nil {
+		result.Status = RebaseUpToDate
+		return result, nil
+	}
+
+	dirty, err := m.HasTrackedChanges(ctx, wt.Path)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		if err := m.runGit(ctx, GitCommand{Dir: wt.Path, Args: []string{"stash", "push", "--quiet", "--message", rebaseStashMessage}}); err != nil {
+			return nil, fmt.Errorf("failed to stash the changes in %s: %w", wt.Path, err)
+		}
+		result.Stashed = true
+	}
+
+	done := m.step("Rebasing " + wt.Branch + " onto " + onto)
+	err = m.runGit(ctx, GitCommand{Dir: wt.Path, Args: []string{"rebase", "--quiet", onto}})
+	done(err)
+	if err != nil {
+		result.Status = RebaseConflict
+		result.Conflicts = m.conflictedFiles(ctx, wt.Path)
+		if opts.KeepConflicts {
+			result.InProgress = true
+			result.StashKept = result.Stashed
+			return result, nil
+		}
+		if abortErr := m.runGit(ctx, GitCommand{Dir: wt.Path, Args: []string{"rebase", "--abort"}}); abortErr != nil {
+			return nil, fmt.Errorf("failed to abort the rebase in %s: %w", wt.Path, abortErr)
+		}
+	} else {
+		result.Status = RebaseDone
+	}
+
+	if result.Stashed {
+		if err := m.runGit(ctx, GitCommand{Dir: wt.Path, Args: []string{"stash", "pop", "--quiet"}}); err != nil {
+			// Git keeps the entry when it cannot be applied cleanly
+			result.StashKept = true
+			result.Conflicts = append(result.Conflicts, m.conflictedFiles(ctx, wt.Path)...)
+		}
+	}
+	return result, nil
+}
+
+// rebaseSkipReason returns why the worktree wt cannot be rebased onto onto,
+// or "" if it can.
+func rebaseSkipReason(wt *Worktree, onto string) string {
+	switch {
+	case wt.Prunable:
+		return "the directory is missing"
+	case wt.Detached || wt.Branch == "":
+		return "detached HEAD"
+	case wt.Kind == KindPristine:
+		return "the pristine worktree follows origin"
+	case wt.Branch == onto || "origin/"+wt.Branch == onto:
+		return "it is the branch to rebase onto"
+	}
+	return ""
+}
+
+// operationInProgress reports whether a rebase or merge is in progress in the
+// worktree at path, which another rebase would get in the way of.
+func (m *Manager) operationInProgress(ctx context.Context, path string) bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply", "MERGE_HEAD"} {
+		output, err := m.gitOutput(ctx, path, "rev-parse", "--path-format=absolute", "--git-path", name)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(strings.TrimSpace(output)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictedFiles returns the files with unresolved conflicts in the worktree at path.
+func (m *Manager) conflictedFiles(ctx context.Context, path string) []string {
+	output, err := m.gitOutput(ctx, path, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil
+	}
+	return parseLines(output)
+}
+
+// parseLines returns the non-empty lines of output.
+func parseLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
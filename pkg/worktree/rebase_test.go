@@ -0,0 +1,55 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRebaseSkipReason(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		wt   *Worktree
+		onto string
+		want string
+	}{
+		"feature branch":       {wt: &Worktree{Branch: "feature-x"}, onto: "origin/main"},
+		"detached":             {wt: &Worktree{Branch: "HEAD", Detached: true}, onto: "origin/main", want: "detached HEAD"},
+		"missing directory":    {wt: &Worktree{Branch: "feature-x", Prunable: true}, onto: "origin/main", want: "the directory is missing"},
+		"pristine":             {wt: &Worktree{Branch: "main", Kind: KindPristine}, onto: "origin/main", want: "the pristine worktree follows origin"},
+		"branch on origin":     {wt: &Worktree{Branch: "main"}, onto: "origin/main", want: "it is the branch to rebase onto"},
+		"local branch":         {wt: &Worktree{Branch: "develop"}, onto: "develop", want: "it is the branch to rebase onto"},
+		"similar branch names": {wt: &Worktree{Branch: "main-fix"}, onto: "origin/main"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, rebaseSkipReason(tt.wt, tt.onto)); diff != "" {
+				t.Errorf("rebaseSkipReason() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		output string
+		want   []string
+	}{
+		"empty":        {output: "", want: nil},
+		"one file":     {output: "a.txt\n", want: []string{"a.txt"}},
+		"blank lines":  {output: "a.txt\n\nsrc/b.go\n", want: []string{"a.txt", "src/b.go"}},
+		"windows eols": {output: "a.txt\r\nb.txt\r\n", want: []string{"a.txt", "b.txt"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, parseLines(tt.output)); diff != "" {
+				t.Errorf("parseLines() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
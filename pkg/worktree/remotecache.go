@@ -0,0 +1,91 @@
+package worktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// remoteCacheFileName is the name of the file caching forge information in the state directory.
+const remoteCacheFileName = "remote.json"
+
+// remoteCacheEntry is the forge information of a branch and when it was fetched.
+type remoteCacheEntry struct {
+	Status    RemoteStatus `json:"status"`
+	FetchedAt time.Time    `json:"fetched_at"`
+}
+
+// SaveRemoteStatus caches the forge information of the worktrees that have
+// it, by branch, for CachedRemoteStatus to fall back on.
+func (m *Manager) SaveRemoteStatus(worktrees []*Worktree) error {
+	cache, err := m.loadRemoteCache()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	changed := false
+	for _, wt := range worktrees {
+		if wt.Remote == nil || wt.Remote.Error != "" || !wt.Remote.CachedAt.IsZero() {
+			continue
+		}
+		cache[wt.PullRequestHead()] = remoteCacheEntry{Status: *wt.Remote, FetchedAt: now}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.StateDir(), 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the forge cache: %w", err)
+	}
+	return os.WriteFile(filepath.Join(m.StateDir(), remoteCacheFileName), data, 0o644)
+}
+
+// CachedRemoteStatus gives the worktrees whose forge information is missing or
+// failed to be fetched the information last cached for their branch, with
+// CachedAt telling how old it is. Worktrees without cached information keep
+// what they have.
+func (m *Manager) CachedRemoteStatus(worktrees []*Worktree) error {
+	cache, err := m.loadRemoteCache()
+	if err != nil {
+		return err
+	}
+
+	for _, wt := range worktrees {
+		if wt.Remote != nil && wt.Remote.Error == "" {
+			continue
+		}
+		entry, ok := cache[wt.PullRequestHead()]
+		if !ok {
+			continue
+		}
+		status := entry.Status
+		status.CachedAt = entry.FetchedAt
+		wt.Remote = &status
+	}
+	return nil
+}
+
+// loadRemoteCache reads the forge cache, returning an empty one if there is none yet.
+func (m *Manager) loadRemoteCache() (map[string]remoteCacheEntry, error) {
+	cache := make(map[string]remoteCacheEntry)
+
+	data, err := os.ReadFile(filepath.Join(m.StateDir(), remoteCacheFileName))
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the forge cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse the forge cache: %w", err)
+	}
+	return cache, nil
+}
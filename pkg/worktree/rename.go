@@ -0,0 +1,109 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// Rename renames the branch of the worktree called oldName and moves its directory
+// to the path for newName. 'git worktree move' rewrites the gitdir links on both sides,
+// and an upstream tracking the old branch name is pointed at the new one.
+// If moving the directory fails, the branch rename is undone.
+func (m *Manager) Rename(ctx context.Context, oldName, newName string) error {
+	oldPath := m.WorktreePath(oldName)
+	newPath := m.WorktreePath(newName)
+
+	wt, err := m.findRegistered(ctx, oldPath)
+	if err != nil {
+		return err
+	}
+	if wt == nil {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeNotFound, oldPath)
+	}
+	if wt.Locked {
+		return lockedError(wt)
+	}
+	if wt.Detached {
+		return fmt.Errorf("%w: %s has no branch to rename", errors.ErrDetachedHead, oldPath)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeExists, newPath)
+	}
+	if m.refExists(ctx, "refs/heads/"+newName) {
+		return fmt.Errorf("%w: %s", errors.ErrBranchExists, newName)
+	}
+
+	branch := wt.Branch
+	if err := m.runGitCommand(ctx, "branch", "-m", branch, newName); err != nil {
+		return fmt.Errorf("failed to rename branch: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
+		m.undoBranchRename(ctx, newName, branch)
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if err := m.runGitCommand(ctx, "worktree", "move", oldPath, newPath); err != nil {
+		m.undoBranchRename(ctx, newName, branch)
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+
+	if err := m.renameUpstream(ctx, branch, newName); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update upstream: %v\n", err)
+	}
+
+	// The branch is renamed along with the worktree, so a recorded head ref is stale
+	clearHeadRef := func(meta *Metadata) { meta.HeadRef = "" }
+	if err := m.renameMetadata(oldPath, newPath, clearHeadRef); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update worktree metadata: %v\n", err)
+	}
+	if err := m.moveLabels(oldPath, newPath); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update worktree labels: %v\n", err)
+	}
+
+	return nil
+}
+
+// undoBranchRename restores a branch name after a failed rename.
+func (m *Manager) undoBranchRename(ctx context.Context, current, original string) {
+	if err := m.runGitCommand(ctx, "branch", "-m", current, original); err != nil {
+		fmt.Printf("⚠️  Warning: failed to restore branch '%s': %v\n", original, err)
+	}
+}
+
+// renameUpstream points an upstream that tracked the old branch name at the new name,
+// so that the next push publishes the renamed branch. Other upstreams are left alone.
+// Git already moved the branch's configuration section along with the branch.
+func (m *Manager) renameUpstream(ctx context.Context, oldName, newName string) error {
+	merge, err := m.gitOutput(ctx, m.repoRoot, "config", "--get", "branch."+newName+".merge")
+	if err != nil || strings.TrimSpace(merge) != "refs/heads/"+oldName {
+		// No upstream, or one with a different name
+		return nil
+	}
+	return m.runGitCommand(ctx, "config", "branch."+newName+".merge", "refs/heads/"+newName)
+}
+
+// renameMetadata moves the metadata recorded for oldPath to newPath, applying
+// update to it on the way if it is not nil.
+func (m *Manager) renameMetadata(oldPath, newPath string, update func(*Metadata)) error {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	meta, ok := store[oldPath]
+	if !ok {
+		return nil
+	}
+	delete(store, oldPath)
+	if update != nil {
+		update(meta)
+	}
+	store[newPath] = meta
+
+	return m.saveMetadata(store)
+}
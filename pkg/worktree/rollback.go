@@ -0,0 +1,123 @@
+package worktree
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// rollback collects the steps undoing what an operation did so far, to undo
+// them if it fails midway.
+type rollback struct {
+	steps []rollbackStep
+}
+
+// rollbackStep undoes one step of an operation.
+type rollbackStep struct {
+	name string
+	undo func(ctx context.Context) error
+}
+
+// add registers undo, which must be safe to run whether or not the step it
+// undoes happened.
+func (r *rollback) add(name string, undo func(ctx context.Context) error) {
+	r.steps = append(r.steps, rollbackStep{name: name, undo: undo})
+}
+
+// run undoes the registered steps, latest first, and forgets them. They run even
+// if ctx is cancelled, as it is when the operation was interrupted. A failing
+// step does not stop the others; their errors are joined.
+func (r *rollback) run(ctx context.Context) error {
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		if err := r.steps[i].undo(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.steps[i].name, err))
+		}
+	}
+	r.steps = nil
+	return stderrors.Join(errs...)
+}
+
+// SetRollback makes creating a worktree transactional: if it is interrupted by
+// cancelling its context, or a setup step fails, the new worktree, its branch
+// and its metadata are removed again and the error returned. Without it, setup
+// failures are only warnings and a creation that fails midway leaves behind
+// what it did so far.
+func (m *Manager) SetRollback(enabled bool) {
+	m.rollback = enabled
+}
+
+// beginCreate returns the rollback of creating a worktree at path with branch,
+// empty for a detached HEAD or an existing branch, or nil without rollback
+// enabled. It must be called before the worktree is added, to tell what
+// existed before.
+func (m *Manager) beginCreate(ctx context.Context, path, branch string) *rollback {
+	if !m.rollback {
+		return nil
+	}
+
+	undo := &rollback{}
+	if branch != "" && !m.refExists(ctx, "refs/heads/"+branch) {
+		undo.add("delete branch "+branch, func(ctx context.Context) error {
+			if !m.refExists(ctx, "refs/heads/"+branch) {
+				return nil
+			}
+			return m.runGitCommand(ctx, "branch", "-D", branch)
+		})
+	}
+
+	existed := exists(path)
+	if existed {
+		// Never undo a worktree that was there before, which git refuses to add again
+		if wt, err := m.findRegistered(ctx, path); err != nil || wt != nil {
+			return undo
+		}
+	}
+	undo.add("remove "+filepath.Base(path), func(ctx context.Context) error {
+		wt, err := m.findRegistered(ctx, path)
+		if err != nil {
+			return err
+		}
+		if wt != nil && m.runGitCommand(ctx, removeArgs(path, true)...) == nil {
+			return nil
+		}
+		// git may have been stopped before registering the worktree, or
+		// refuses to remove it: drop its files and let git forget it
+		if !existed {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+		return m.runGitCommand(ctx, "worktree", "prune")
+	})
+
+	undo.add("drop metadata", func(context.Context) error {
+		if err := m.deleteMetadata(path); err != nil {
+			return err
+		}
+		return m.moveLabels(path, "")
+	})
+	return undo
+}
+
+// abortCreate rolls back a creation that failed with err, if undo is not nil,
+// and returns err with the outcome of the rollback.
+func (m *Manager) abortCreate(ctx context.Context, undo *rollback, path string, err error) error {
+	if undo == nil {
+		return err
+	}
+
+	done := m.step("Rolling back " + filepath.Base(path))
+	rerr := undo.run(ctx)
+	done(rerr)
+	if rerr != nil {
+		return fmt.Errorf("%w\n⚠️  Rolling back left parts of %s behind: %v", err, path, rerr)
+	}
+	return fmt.Errorf("%w\n↩️  %w: removed %s again", err, errors.ErrCreateRolledBack, path)
+}
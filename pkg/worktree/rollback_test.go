@@ -0,0 +1,60 @@
+package worktree
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRollback(t *testing.T) {
+	for name, tt := range map[string]struct {
+		failing  string
+		expected []string
+		wantErr  bool
+	}{
+		"latest first": {
+			expected: []string{"metadata", "worktree", "branch"},
+		},
+		"failing step": {
+			failing:  "worktree",
+			expected: []string{"metadata", "worktree", "branch"},
+			wantErr:  true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			var undone []string
+			var r rollback
+			for _, step := range []string{"branch", "worktree", "metadata"} {
+				r.add(step, func(ctx context.Context) error {
+					// Undoing an interrupted operation must not be interrupted itself
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					undone = append(undone, step)
+					if step == tt.failing {
+						return stderrors.New("failed")
+					}
+					return nil
+				})
+			}
+
+			err := r.run(ctx)
+			if diff := cmp.Diff(tt.wantErr, err != nil); diff != "" {
+				t.Fatalf("run() error = %v (-want +got):\n%s", err, diff)
+			}
+			if diff := cmp.Diff(tt.expected, undone); diff != "" {
+				t.Errorf("run() undone steps mismatch (-want +got):\n%s", diff)
+			}
+			if err := r.run(ctx); err != nil || len(undone) != len(tt.expected) {
+				t.Errorf("run() again undid steps again, error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// SetupStep is a shell command run in new worktrees, such as installing dependencies.
+type SetupStep struct {
+	Name string
+	Run  string
+}
+
+// Template is what a new worktree is set up with: files copied from the
+// repository root, paths symlinked from it and setup commands to run.
+type Template struct {
+	Files []string
+	Links []string
+	Setup []SetupStep
+}
+
+// TemplateFunc returns the template for a new worktree of branch, which is empty
+// for a detached HEAD.
+type TemplateFunc func(branch string) (Template, error)
+
+// SetTemplateFunc makes new worktrees be set up with the template fn returns for
+// their branch, instead of the files and links set with SetTemplates and SetLinks.
+func (m *Manager) SetTemplateFunc(fn TemplateFunc) {
+	m.template = fn
+}
+
+// templateFor returns the template for a new worktree of branch.
+func (m *Manager) templateFor(branch string) (Template, error) {
+	if m.template != nil {
+		return m.template(branch)
+	}
+
+	files := ConfigFiles
+	if m.templates != nil {
+		files = m.templates
+	}
+	return Template{Files: files, Links: m.links}, nil
+}
+
+// runSetup runs the setup steps in the new worktree at path, in order, with the
+// worktree's environment variables set. A failing step stops the setup. Output is
+// only shown for a failing step, as part of the returned error.
+func (m *Manager) runSetup(ctx context.Context, path, branch string, steps []SetupStep) error {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	offset, err := m.PortOffset(ctx, &Worktree{Path: path, Branch: branch})
+	if err != nil {
+		return err
+	}
+	vars, err := Env(NewEnvData(&Worktree{Path: path, Branch: branch}, m.repoRoot, offset), nil)
+	if err != nil {
+		return err
+	}
+	env := os.Environ()
+	for _, v := range vars {
+		env = append(env, v[0]+"="+v[1])
+	}
+
+	for _, step := range steps {
+		if err := m.runSetupStep(ctx, path, env, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSetupStep runs a setup step with sh, or the user's shell where there is none, in dir and announces it to subscribers.
+func (m *Manager) runSetupStep(ctx context.Context, dir string, env []string, step SetupStep) (err error) {
+	done := m.step("Running " + step.Name)
+	defer func() { done(err) }()
+
+	var output bytes.Buffer
+	shell := utils.ScriptShell()
+	cmd := exec.CommandContext(ctx, shell.Path, shell.ScriptArgs(step.Run)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	timed := m.timeOp("setup "+step.Name, dir)
+	start := time.Now()
+	err = cmd.Run()
+	timed(err)
+	if err != nil {
+		err = fmt.Errorf("setup step %s failed: %w", step.Name, err)
+		if msg := strings.TrimSpace(output.String()); msg != "" {
+			err = fmt.Errorf("%w\n%s", err, msg)
+		}
+	}
+	m.publish(HookRan{Time: time.Now(), Hook: step.Name, Path: dir, Duration: time.Since(start), Err: err})
+	return err
+}
@@ -0,0 +1,129 @@
+package worktree
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxNameLength is the default cap on the length of each directory
+// name of a worktree path, well below the 255 bytes file systems allow, so
+// that paths below the worktree stay short enough for Windows.
+const DefaultMaxNameLength = 80
+
+// nameHashLength is the length of the hash suffix of colliding sanitized names.
+const nameHashLength = 8
+
+// PathOptions controls how worktree names, usually branch names, are turned
+// into directory names.
+type PathOptions struct {
+	// MaxLength caps the length in bytes of each directory name. Defaults to
+	// DefaultMaxNameLength.
+	MaxLength int
+
+	// Replacement is the character that replaces those invalid in file names
+	// on some system, such as ':' or '?'. Defaults to "-".
+	Replacement string
+}
+
+// SetPathOptions sets how worktree names are turned into directory names.
+func (m *Manager) SetPathOptions(opts PathOptions) {
+	m.paths = opts
+}
+
+// windowsReserved are the device names Windows reserves, with or without an
+// extension and in any case.
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// DirName returns the path, relative to the worktree directory, of the
+// worktree called name. Slashes separate directories, as in branch names.
+// Names that are valid file names everywhere and short enough are kept as
+// they are. Otherwise invalid characters are replaced, Windows device names
+// and trailing dots and spaces altered, and long names cut. Applied to its own
+// result, DirName returns it unchanged.
+func (o PathOptions) DirName(name string) string {
+	parts, _ := o.sanitize(name)
+	return strings.Join(parts, "/")
+}
+
+// HashedDirName returns DirName with a hash of name appended, which tells
+// apart names altered the same way, such as feature/foo:bar and feature/foo?bar,
+// when the directory of one is taken by the other. Names DirName keeps as they
+// are are returned unchanged, as nothing else maps to them.
+func (o PathOptions) HashedDirName(name string) string {
+	parts, maxLen := o.sanitize(name)
+	if strings.Join(parts, "/") == name {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := "-" + hex.EncodeToString(sum[:])[:nameHashLength]
+	last := len(parts) - 1
+	parts[last] = strings.TrimRight(truncateName(parts[last], maxLen-len(suffix)), o.replacement()) + suffix
+	return strings.Join(parts, "/")
+}
+
+// sanitize returns the directory names of the path of name and the length they are capped at.
+func (o PathOptions) sanitize(name string) ([]string, int) {
+	maxLen := o.MaxLength
+	if maxLen <= 0 {
+		maxLen = DefaultMaxNameLength
+	}
+
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		clean := sanitizeName(part, o.replacement())
+		if len(clean) > maxLen {
+			clean = truncateName(clean, maxLen)
+		}
+		parts[i] = clean
+	}
+	return parts, maxLen
+}
+
+// replacement returns the character replacing those invalid in file names.
+func (o PathOptions) replacement() string {
+	if o.Replacement == "" {
+		return "-"
+	}
+	return o.Replacement
+}
+
+// sanitizeName makes part a valid file name on Linux, macOS and Windows,
+// replacing invalid characters with replacement.
+func sanitizeName(part, replacement string) string {
+	var b strings.Builder
+	for _, r := range part {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"\|?*`, r) {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	clean := strings.TrimRight(b.String(), ". ")
+
+	base, _, _ := strings.Cut(clean, ".")
+	if clean == "" || windowsReserved[strings.ToUpper(base)] {
+		clean = base + replacement + strings.TrimPrefix(clean, base)
+	}
+	return clean
+}
+
+// truncateName cuts s to at most n bytes without splitting a character, and
+// without leaving a trailing dot or space, which Windows drops.
+func truncateName(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return strings.TrimRight(s[:n], ". ")
+}
@@ -0,0 +1,97 @@
+package worktree
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPathOptionsDirName(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", 100)
+	for name, tt := range map[string]struct {
+		opts   PathOptions
+		name   string
+		want   string
+		hashed string
+	}{
+		"plain name":              {name: "feature-auth", want: "feature-auth", hashed: "feature-auth"},
+		"slashes nest":            {name: "feature/auth", want: "feature/auth", hashed: "feature/auth"},
+		"invalid characters":      {name: "feature/foo:bar", want: "feature/foo-bar", hashed: "feature/foo-bar-c4e5b96d"},
+		"other invalid character": {name: "feature/foo?bar", want: "feature/foo-bar", hashed: "feature/foo-bar-a2238cbd"},
+		"custom replacement":      {opts: PathOptions{Replacement: "_"}, name: "fix<1>", want: "fix_1_", hashed: "fix_1-ddfb6599"},
+		"reserved name":           {name: "con", want: "con-", hashed: "con-1143da2b"},
+		"reserved name any case":  {name: "release/Aux", want: "release/Aux-", hashed: "release/Aux-e025079a"},
+		"reserved with extension": {name: "nul.txt", want: "nul-.txt", hashed: "nul-.txt-7f3cbdd5"},
+		"reserved directory":      {name: "lpt1/fix", want: "lpt1-/fix", hashed: "lpt1-/fix-b4c12bae"},
+		"reserved prefix is fine": {name: "console", want: "console", hashed: "console"},
+		"trailing dot":            {name: "v1.", want: "v1", hashed: "v1-d146c29a"},
+		"long name":               {name: long, want: strings.Repeat("a", 80), hashed: strings.Repeat("a", 71) + "-28165978"},
+		"custom max length":       {opts: PathOptions{MaxLength: 20}, name: "feature-with-a-long-name", want: "feature-with-a-long-", hashed: "feature-wit-0618c109"},
+		"multibyte characters":    {opts: PathOptions{MaxLength: 16}, name: "機能-ログイン画面", want: "機能-ログイ", hashed: "機能-3bd079ba"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tt.opts.DirName(tt.name)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("DirName(%q) mismatch (-want +got):\n%s", tt.name, diff)
+			}
+			if again := tt.opts.DirName(got); again != got {
+				t.Errorf("DirName(%q) = %q, want it unchanged", got, again)
+			}
+			if diff := cmp.Diff(tt.hashed, tt.opts.HashedDirName(tt.name)); diff != "" {
+				t.Errorf("HashedDirName(%q) mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+// TestWorktreePathCollision checks that a name whose directory is taken by the
+// worktree of another branch altered the same way gets the hashed directory.
+func TestWorktreePathCollision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	for name, tt := range map[string]struct {
+		existing []string // branches with worktrees, created in order
+		name     string
+		expected string
+	}{
+		"free directory":            {name: "fix|1", expected: "fix-1"},
+		"own directory":             {existing: []string{"fix|1"}, name: "fix|1", expected: "fix-1"},
+		"taken by another branch":   {existing: []string{"fix|1"}, name: "fix<1", expected: "fix-1-863258b0"},
+		"created after a collision": {existing: []string{"fix|1", "fix<1"}, name: "fix<1", expected: "fix-1-863258b0"},
+		"unaltered name":            {existing: []string{"fix|1"}, name: "fix-1", expected: "fix-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo := filepath.Join(root, "repo")
+			gitRepo(t, root, "init", "--quiet", repo)
+			gitRepo(t, repo, "commit", "--quiet", "--allow-empty", "-m", "first")
+
+			m, err := NewAt(repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.SetWorktreeDir(filepath.Join(root, "trees"))
+			for _, branch := range tt.existing {
+				gitRepo(t, repo, "worktree", "add", "--quiet", "-b", branch, m.WorktreePath(branch))
+			}
+
+			if diff := cmp.Diff(filepath.Join(root, "trees", tt.expected), m.WorktreePath(tt.name)); diff != "" {
+				t.Errorf("WorktreePath(%q) mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package worktree
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+)
+
+// snapshotDirName is the directory below the state directory holding saved snapshots.
+const snapshotDirName = "snapshots"
+
+// Snapshot records the set of worktrees of a repository, so that the layout
+// can be recreated by RestoreSnapshot after a cleanup or on another machine.
+type Snapshot struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Remote is the URL of origin, for telling which repository the snapshot is of.
+	Remote string `json:"remote,omitempty"`
+
+	Worktrees []SnapshotWorktree `json:"worktrees"`
+}
+
+// SnapshotWorktree is one worktree of a snapshot. The main worktree is not recorded.
+type SnapshotWorktree struct {
+	// Name is the worktree's directory relative to the worktree directory,
+	// which keeps snapshots independent of where the repository lives.
+	Name string `json:"name"`
+
+	// Branch is empty for a detached HEAD.
+	Branch string `json:"branch,omitempty"`
+	Head   string `json:"head"`
+
+	// Base is the branch the worktree was created from, as recorded by giwo.
+	Base   string   `json:"base,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Outcomes of restoring a worktree of a snapshot.
+const (
+	SnapshotCreated = "created"
+	SnapshotExists  = "exists"
+	SnapshotFailed  = "failed"
+)
+
+// SnapshotResult reports how a worktree of a snapshot was restored.
+type SnapshotResult struct {
+	Worktree SnapshotWorktree
+	Path     string
+
+	// Status is created, exists if a worktree was already there, or failed.
+	Status string
+	Err    error
+}
+
+// TakeSnapshot records the current linked worktrees under name.
+func (m *Manager) TakeSnapshot(ctx context.Context, name string) (*Snapshot, error) {
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.applyLabels(worktrees); err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{Name: name, CreatedAt: time.Now(), Remote: m.RemoteURL(ctx)}
+	for _, wt := range worktrees {
+		if wt.Path == m.mainRoot || wt.Prunable {
+			continue
+		}
+		entry := SnapshotWorktree{Name: m.snapshotName(wt), Head: wt.Head, Labels: wt.Labels}
+		if !wt.Detached {
+			entry.Branch = wt.Branch
+		}
+		if meta, ok := store[wt.Path]; ok {
+			entry.Base = meta.Base
+		}
+		snapshot.Worktrees = append(snapshot.Worktrees, entry)
+	}
+	return snapshot, nil
+}
+
+// snapshotName returns the name of a worktree relative to the worktree
+// directory, or its branch or directory name if it lives elsewhere.
+func (m *Manager) snapshotName(wt *Worktree) string {
+	rel, err := filepath.Rel(m.worktreeDir, wt.Path)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(rel)
+	}
+	if !wt.Detached && wt.Branch != "" {
+		return wt.Branch
+	}
+	return filepath.Base(wt.Path)
+}
+
+// SaveSnapshot writes a snapshot to the state directory under its name,
+// replacing an earlier one of the same name.
+func (m *Manager) SaveSnapshot(snapshot *Snapshot) error {
+	dir := filepath.Join(m.StateDir(), snapshotDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, snapshot.Name+".json"), data, 0o644)
+}
+
+// LoadSnapshot reads the snapshot saved under name.
+func (m *Manager) LoadSnapshot(name string) (*Snapshot, error) {
+	f, err := os.Open(filepath.Join(m.StateDir(), snapshotDirName, name+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", errors.ErrSnapshotNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer f.Close()
+	return ReadSnapshot(f)
+}
+
+// ReadSnapshot reads a snapshot as written by SaveSnapshot, such as one shared by a teammate.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	for _, wt := range snapshot.Worktrees {
+		if wt.Name == "" || filepath.IsAbs(wt.Name) || slices.Contains(strings.Split(wt.Name, "/"), "..") {
+			return nil, fmt.Errorf("invalid snapshot: worktree name %q must be a relative path inside the worktree directory", wt.Name)
+		}
+	}
+	return &snapshot, nil
+}
+
+// Snapshots returns the saved snapshots, most recent first.
+func (m *Manager) Snapshots() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(m.StateDir(), snapshotDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		snapshot, err := m.LoadSnapshot(name)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	slices.SortFunc(snapshots, func(a, b *Snapshot) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// RestoreSnapshot recreates the worktrees of a snapshot that do not exist, and
+// returns how each was restored. Origin is fetched first. A branch is checked
+// out if it exists locally, continued from origin if only it has it, and
+// otherwise recreated at the recorded commit, or started from the base on
+// origin if that commit is not available either, as on another machine.
+func (m *Manager) RestoreSnapshot(ctx context.Context, snapshot *Snapshot) ([]SnapshotResult, error) {
+	registered, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(m.worktreeDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if err := m.fetchForCreate(ctx); err != nil {
+		return nil, err
+	}
+
+	checkedOut := make(map[string]string)
+	for _, wt := range registered {
+		if !wt.Detached && wt.Branch != "" {
+			checkedOut[wt.Branch] = wt.Path
+		}
+	}
+
+	results := make([]SnapshotResult, len(snapshot.Worktrees))
+	for i, wt := range snapshot.Worktrees {
+		path := m.WorktreePath(wt.Name)
+		results[i] = SnapshotResult{Worktree: wt, Path: path}
+		if existing, ok := checkedOut[wt.Branch]; ok {
+			results[i].Path, results[i].Status = existing, SnapshotExists
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			results[i].Status = SnapshotExists
+			continue
+		}
+
+		if err := m.restoreSnapshotWorktree(ctx, path, wt); err != nil {
+			results[i].Status, results[i].Err = SnapshotFailed, err
+			continue
+		}
+		results[i].Status = SnapshotCreated
+		if wt.Branch != "" {
+			checkedOut[wt.Branch] = path
+		}
+	}
+	return results, nil
+}
+
+// restoreSnapshotWorktree creates the worktree of a snapshot at path.
+func (m *Manager) restoreSnapshotWorktree(ctx context.Context, path string, wt SnapshotWorktree) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	args, err := m.snapshotArgs(ctx, path, wt)
+	if err != nil {
+		return err
+	}
+	if err := m.addWorktree(ctx, path, args...); err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	base := wt.Base
+	if base == "" {
+		base = wt.Head
+	}
+	m.finishCreate(ctx, path, wt.Branch, base)
+	if len(wt.Labels) > 0 {
+		if _, err := m.Label(path, wt.Labels, nil); err != nil {
+			return fmt.Errorf("failed to restore labels: %w", err)
+		}
+	}
+	return nil
+}
+
+// snapshotArgs returns the arguments of 'git worktree add' recreating the worktree of a snapshot at path.
+func (m *Manager) snapshotArgs(ctx context.Context, path string, wt SnapshotWorktree) ([]string, error) {
+	headExists := wt.Head != "" && m.refExists(ctx, wt.Head)
+	switch {
+	case wt.Branch == "" && headExists:
+		return []string{"--detach", path, wt.Head}, nil
+	case wt.Branch == "":
+		return nil, fmt.Errorf("commit %s of the detached worktree is not available", shortHash(wt.Head))
+	case m.refExists(ctx, "refs/heads/"+wt.Branch):
+		return []string{path, wt.Branch}, nil
+	case m.refExists(ctx, "refs/remotes/origin/"+wt.Branch):
+		return []string{"-b", wt.Branch, path, "origin/" + wt.Branch}, nil
+	case headExists:
+		return []string{"-b", wt.Branch, path, wt.Head}, nil
+	case wt.Base != "" && m.refExists(ctx, "refs/remotes/origin/"+wt.Base):
+		return createArgs(wt.Branch, path, wt.Base), nil
+	default:
+		return nil, fmt.Errorf("%w: %s is neither local nor on origin, and its commit %s is not available", errors.ErrBranchNotFound, wt.Branch, shortHash(wt.Head))
+	}
+}
@@ -0,0 +1,80 @@
+package worktree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadSnapshot(t *testing.T) {
+	for name, tt := range map[string]struct {
+		input    string
+		expected []SnapshotWorktree
+		wantErr  bool
+	}{
+		"worktrees": {
+			input: `{"name": "release", "worktrees": [
+				{"name": "feature/login", "branch": "feature/login", "head": "abc123", "base": "main", "labels": ["review"]},
+				{"name": "bisect", "head": "def456"}
+			]}`,
+			expected: []SnapshotWorktree{
+				{Name: "feature/login", Branch: "feature/login", Head: "abc123", Base: "main", Labels: []string{"review"}},
+				{Name: "bisect", Head: "def456"},
+			},
+		},
+		"absolute name":  {input: `{"worktrees": [{"name": "/tmp/evil", "head": "abc123"}]}`, wantErr: true},
+		"escaping name":  {input: `{"worktrees": [{"name": "../evil", "head": "abc123"}]}`, wantErr: true},
+		"empty name":     {input: `{"worktrees": [{"branch": "feature", "head": "abc123"}]}`, wantErr: true},
+		"not a snapshot": {input: `feature main`, wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			snapshot, err := ReadSnapshot(strings.NewReader(tt.input))
+			if diff := cmp.Diff(tt.wantErr, err != nil); diff != "" {
+				t.Fatalf("ReadSnapshot() error = %v (-want +got):\n%s", err, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.expected, snapshot.Worktrees); diff != "" {
+				t.Errorf("ReadSnapshot() worktrees mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSnapshotName(t *testing.T) {
+	m := &Manager{worktreeDir: "/repo/.worktree"}
+
+	for name, tt := range map[string]struct {
+		worktree *Worktree
+		expected string
+	}{
+		"in worktree directory": {
+			worktree: &Worktree{Path: "/repo/.worktree/feature/login", Branch: "feature/login"},
+			expected: "feature/login",
+		},
+		"renamed directory": {
+			worktree: &Worktree{Path: "/repo/.worktree/login", Branch: "feature/login"},
+			expected: "login",
+		},
+		"elsewhere": {
+			worktree: &Worktree{Path: "/elsewhere/hotfix", Branch: "hotfix-1"},
+			expected: "hotfix-1",
+		},
+		"elsewhere detached": {
+			worktree: &Worktree{Path: "/elsewhere/bisect", Branch: "HEAD", Detached: true},
+			expected: "bisect",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, m.snapshotName(tt.worktree)); diff != "" {
+				t.Errorf("snapshotName() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
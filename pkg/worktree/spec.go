@@ -0,0 +1,243 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec describes a worktree layout to reconstruct, for example on a CI runner
+// that needs several branches checked out side by side.
+type Spec struct {
+	Worktrees []SpecWorktree `yaml:"worktrees"`
+}
+
+// SpecWorktree is one worktree of a Spec.
+type SpecWorktree struct {
+	// Branch is the branch checked out in the worktree, which also names it.
+	Branch string `yaml:"branch"`
+
+	// Base is the branch a new branch is started from when Branch exists neither
+	// locally nor on origin. Without it, a missing branch is an error.
+	Base string `yaml:"base"`
+
+	// Env is the environment variable 'giwo export-shellenv' sets to the worktree's path.
+	// Defaults to GIWO_WORKTREE_<BRANCH>.
+	Env string `yaml:"env"`
+}
+
+// ApplyOptions controls Apply.
+type ApplyOptions struct {
+	// Prefetch fetches every branch named by the spec from origin in a single fetch
+	// before creating worktrees. Without it, only refs already present are used,
+	// which fails for branches a shallow or single-branch clone did not fetch.
+	Prefetch bool
+}
+
+// ApplyResult reports what Apply did for one worktree of the spec.
+type ApplyResult struct {
+	Branch  string
+	Path    string
+	Created bool
+}
+
+// LoadSpec reads a spec file. Unknown keys are rejected so that typos do not go unnoticed.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+
+	return spec, nil
+}
+
+// Validate checks that every worktree names a branch, and that branches and variables are unique.
+func (s *Spec) Validate() error {
+	branches := make(map[string]bool)
+	envs := make(map[string]bool)
+	for i, wt := range s.Worktrees {
+		if wt.Branch == "" {
+			return fmt.Errorf("worktrees[%d]: branch is required", i)
+		}
+		if branches[wt.Branch] {
+			return fmt.Errorf("worktrees[%d]: duplicate branch %q", i, wt.Branch)
+		}
+		branches[wt.Branch] = true
+
+		env := wt.EnvName()
+		if envs[env] {
+			return fmt.Errorf("worktrees[%d]: duplicate env %q", i, env)
+		}
+		envs[env] = true
+	}
+	return nil
+}
+
+// EnvName returns the environment variable holding the worktree's path.
+func (w SpecWorktree) EnvName() string {
+	if w.Env != "" {
+		return w.Env
+	}
+	return EnvName(w.Branch)
+}
+
+// EnvName returns the default environment variable for the worktree of branch,
+// e.g. GIWO_WORKTREE_FEATURE_AUTH for "feature/auth".
+func EnvName(branch string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, branch)
+	return "GIWO_WORKTREE_" + name
+}
+
+// Apply creates the worktrees of spec that do not exist yet. Branches already
+// checked out in some worktree, including the main one, are left alone, so
+// applying a spec twice is harmless. It never prompts.
+func (m *Manager) Apply(ctx context.Context, spec *Spec, opts ApplyOptions) ([]ApplyResult, error) {
+	if opts.Prefetch {
+		if err := m.prefetch(ctx, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checkedOut := make(map[string]string, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			checkedOut[wt.Branch] = wt.Path
+		}
+	}
+
+	results := make([]ApplyResult, 0, len(spec.Worktrees))
+	for _, wt := range spec.Worktrees {
+		if path, ok := checkedOut[wt.Branch]; ok {
+			results = append(results, ApplyResult{Branch: wt.Branch, Path: path})
+			continue
+		}
+
+		path, err := m.applyWorktree(ctx, wt)
+		if err != nil {
+			return results, fmt.Errorf("failed to create worktree for %s: %w", wt.Branch, err)
+		}
+		results = append(results, ApplyResult{Branch: wt.Branch, Path: path, Created: true})
+	}
+
+	return results, nil
+}
+
+// prefetch fetches the branches and bases named by spec into their remote-tracking refs.
+// Bases are fetched too since they are only needed if their branch does not exist,
+// which cannot be known before fetching. Branches missing on origin are skipped.
+func (m *Manager) prefetch(ctx context.Context, spec *Spec) error {
+	seen := make(map[string]bool)
+	var branches []string
+	for _, wt := range spec.Worktrees {
+		for _, branch := range []string{wt.Branch, wt.Base} {
+			if branch != "" && !seen[branch] {
+				seen[branch] = true
+				branches = append(branches, branch)
+			}
+		}
+	}
+	if len(branches) == 0 {
+		return nil
+	}
+
+	if m.offline {
+		m.skipFetch()
+		return nil
+	}
+	output, err := m.gitOutput(ctx, m.repoRoot, append([]string{"ls-remote", "--heads", "origin"}, branches...)...)
+	if IsNetworkError(err) {
+		m.goOffline(err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list remote branches: %w", err)
+	}
+
+	args := []string{"--prune", "origin"}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		_, ref, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		branch := strings.TrimPrefix(ref, "refs/heads/")
+		if seen[branch] {
+			args = append(args, fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch))
+		}
+	}
+	if len(args) == 2 {
+		return nil
+	}
+
+	if err := m.fetch(ctx, args...); err != nil {
+		return fmt.Errorf("failed to prefetch branches: %w", err)
+	}
+	return nil
+}
+
+// applyWorktree creates the worktree for one spec entry from the local branch,
+// its origin counterpart, or a new branch off the base, and returns its path.
+func (m *Manager) applyWorktree(ctx context.Context, wt SpecWorktree) (string, error) {
+	path := m.WorktreePath(wt.Branch)
+	if err := os.MkdirAll(m.worktreeDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	var (
+		args  []string
+		track bool
+	)
+	switch {
+	case m.refExists(ctx, "refs/heads/"+wt.Branch):
+		args = []string{path, wt.Branch}
+	case m.refExists(ctx, "refs/remotes/origin/"+wt.Branch):
+		// git cannot infer tracking for refs fetched outside the configured refspecs,
+		// as in single-branch clones, so the upstream is set explicitly
+		args = []string{"-b", wt.Branch, path, "origin/" + wt.Branch}
+		track = true
+	case wt.Base != "" && m.refExists(ctx, "refs/remotes/origin/"+wt.Base):
+		args = []string{"-b", wt.Branch, path, "origin/" + wt.Base}
+	case wt.Base != "":
+		return "", fmt.Errorf("%w: neither %s nor its base %s exist; use --prefetch to fetch them", ErrBranchNotFound, wt.Branch, wt.Base)
+	default:
+		return "", fmt.Errorf("%w: %s; use --prefetch to fetch it, or set a base", ErrBranchNotFound, wt.Branch)
+	}
+
+	if err := m.addWorktree(ctx, path, args...); err != nil {
+		return "", err
+	}
+	if track {
+		if err := m.SetUpstream(ctx, wt.Branch); err != nil {
+			return "", err
+		}
+	}
+	m.finishCreate(ctx, path, wt.Branch, wt.Base)
+
+	return path, nil
+}
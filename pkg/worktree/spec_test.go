@@ -0,0 +1,90 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadSpec(t *testing.T) {
+	for name, tt := range map[string]struct {
+		content   string
+		expected  *Spec
+		wantError bool
+	}{
+		"empty file": {
+			content:  "",
+			expected: &Spec{},
+		},
+		"worktrees": {
+			content: "worktrees:\n  - branch: main\n  - branch: compat\n    base: main\n    env: COMPAT_DIR\n",
+			expected: &Spec{Worktrees: []SpecWorktree{
+				{Branch: "main"},
+				{Branch: "compat", Base: "main", Env: "COMPAT_DIR"},
+			}},
+		},
+		"missing branch": {
+			content:   "worktrees:\n  - base: main\n",
+			wantError: true,
+		},
+		"duplicate branch": {
+			content:   "worktrees:\n  - branch: main\n  - branch: main\n",
+			wantError: true,
+		},
+		"duplicate env": {
+			content:   "worktrees:\n  - branch: feature/a\n  - branch: feature-a\n",
+			wantError: true,
+		},
+		"unknown key": {
+			content:   "worktrees:\n  - branch: main\n    path: /tmp\n",
+			wantError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "spec.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			spec, err := LoadSpec(path)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("LoadSpec expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSpec unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, spec); diff != "" {
+				t.Errorf("LoadSpec mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		branch   string
+		expected string
+	}{
+		"simple":      {"main", "GIWO_WORKTREE_MAIN"},
+		"slash":       {"release/1.x", "GIWO_WORKTREE_RELEASE_1_X"},
+		"dash":        {"feature-auth", "GIWO_WORKTREE_FEATURE_AUTH"},
+		"non-ascii":   {"café", "GIWO_WORKTREE_CAF_"},
+		"digits kept": {"v2", "GIWO_WORKTREE_V2"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, EnvName(tt.branch)); diff != "" {
+				t.Errorf("EnvName(%q) mismatch (-want +got):\n%s", tt.branch, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// stashMessagePrefix marks the stash entries giwo creates when switching away
+// from uncommitted changes.
+const stashMessagePrefix = "giwo: "
+
+// StashMessage returns the name of the stash entry for the changes left on
+// branch at now, such as "giwo: feature-auth on 2026-10-16 14:05".
+func StashMessage(branch string, now time.Time) string {
+	return fmt.Sprintf("%s%s on %s", stashMessagePrefix, branch, now.Format("2006-01-02 15:04"))
+}
+
+// Stash stashes the uncommitted changes to tracked files in the worktree at
+// path as message, leaving the worktree clean.
+func (m *Manager) Stash(ctx context.Context, path, message string) error {
+	if err := m.runGit(ctx, GitCommand{Dir: path, Args: []string{"stash", "push", "--quiet", "--message", message}}); err != nil {
+		return fmt.Errorf("failed to stash the changes in %s: %w", path, err)
+	}
+	return nil
+}
+
+// CarryChanges moves the uncommitted changes to tracked files from the
+// worktree at from to the one at to. Worktrees share the stash, so the
+// changes are stashed in one and popped in the other. If they do not apply
+// cleanly, the conflicts are left in to and the changes are kept in the stash
+// as message, which kept reports.
+func (m *Manager) CarryChanges(ctx context.Context, from, to, message string) (kept bool, err error) {
+	if err := m.Stash(ctx, from, message); err != nil {
+		return false, err
+	}
+	if err := m.runGit(ctx, GitCommand{Dir: to, Args: []string{"stash", "pop", "--quiet"}}); err != nil {
+		return true, fmt.Errorf("failed to apply the changes in %s, they are kept in the stash as '%s': %w", to, message, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,29 @@
+package worktree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStashMessage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 14, 5, 30, 0, time.UTC)
+	for name, tt := range map[string]struct {
+		branch string
+		want   string
+	}{
+		"branch":          {branch: "feature-auth", want: "giwo: feature-auth on 2026-10-16 14:05"},
+		"branch with dir": {branch: "fix/login", want: "giwo: fix/login on 2026-10-16 14:05"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, StashMessage(tt.branch, now)); diff != "" {
+				t.Errorf("StashMessage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
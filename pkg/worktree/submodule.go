@@ -0,0 +1,184 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// SubmoduleOptions configures CreateWithSubmodule.
+type SubmoduleOptions struct {
+	// Submodule is the path of the submodule in the superproject, e.g. "lib/core".
+	Submodule string
+
+	// Branch is created in the superproject and, unless it exists there, in the submodule.
+	Branch string
+
+	// Base is the superproject branch to start from, as for Create.
+	Base string
+
+	Force bool
+}
+
+// SubmodulePair is a superproject worktree whose submodule is checked out as a
+// worktree of the submodule's repository, so that both are developed together.
+type SubmodulePair struct {
+	// Name, Branch and Path describe the superproject worktree.
+	Name   string `json:"name"`
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+
+	// Submodule is the path of the submodule in the superproject, and
+	// SubmodulePath that of the submodule worktree within Path.
+	Submodule     string `json:"submodule"`
+	SubmodulePath string `json:"submodule_path"`
+}
+
+// Submodules returns the paths of the submodules declared in .gitmodules.
+func (m *Manager) Submodules(ctx context.Context) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(m.repoRoot, ".gitmodules")); os.IsNotExist(err) {
+		return nil, nil
+	}
+	output, err := m.gitOutput(ctx, m.repoRoot, "config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+	return parseSubmodulePaths(output), nil
+}
+
+// parseSubmodulePaths extracts the paths from 'git config --get-regexp' output
+// of submodule.<name>.path entries.
+func parseSubmodulePaths(output string) []string {
+	var paths []string
+	for _, line := range strings.Split(output, "\n") {
+		if _, path, ok := strings.Cut(strings.TrimSpace(line), " "); ok && path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// CreateWithSubmodule creates a worktree of the superproject as Create does and
+// checks out its submodule as a worktree of the submodule's repository on a
+// branch of the same name, starting at the commit the superproject records.
+// The submodule must be initialized in the main worktree. The pair is recorded,
+// so that Remove takes the submodule worktree down with the superproject's.
+func (m *Manager) CreateWithSubmodule(ctx context.Context, opts SubmoduleOptions) (*SubmodulePair, error) {
+	submodule := filepath.ToSlash(filepath.Clean(opts.Submodule))
+	submodules, err := m.Submodules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(submodules, submodule) {
+		return nil, fmt.Errorf("%s is not a submodule of %s", submodule, m.repoRoot)
+	}
+	subRepo := filepath.Join(m.repoRoot, submodule)
+	if _, err := os.Stat(filepath.Join(subRepo, ".git")); err != nil {
+		return nil, fmt.Errorf("submodule %s is not initialized; run 'git submodule update --init %s' first", submodule, utils.ShellQuote(submodule))
+	}
+
+	if err := m.Create(ctx, CreateOptions{Branch: opts.Branch, Base: opts.Base, Force: opts.Force}); err != nil {
+		return nil, err
+	}
+	path := m.WorktreePath(opts.Branch)
+	subPath := filepath.Join(path, submodule)
+
+	// Continue a branch the submodule already has, e.g. from an earlier pair
+	args := []string{"worktree", "add", subPath, opts.Branch}
+	if _, ok := m.git().ResolveCommit(ctx, subRepo, "refs/heads/"+opts.Branch); !ok {
+		commit, err := m.gitOutput(ctx, path, "rev-parse", "HEAD:"+submodule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the commit of submodule %s: %w", submodule, err)
+		}
+		args = []string{"worktree", "add", "-b", opts.Branch, subPath, strings.TrimSpace(commit)}
+	}
+	done := m.step("Checking out submodule " + submodule)
+	err = m.runGit(ctx, GitCommand{Dir: subRepo, Args: args})
+	done(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create submodule worktree (the superproject worktree %s was kept): %w", path, worktreeAddError(err))
+	}
+
+	if err := m.updateMetadata(path, func(meta *Metadata) {
+		if !slices.Contains(meta.Submodules, submodule) {
+			meta.Submodules = append(meta.Submodules, submodule)
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record submodule worktree: %w", err)
+	}
+
+	return &SubmodulePair{
+		Name:          opts.Branch,
+		Branch:        opts.Branch,
+		Path:          path,
+		Submodule:     submodule,
+		SubmodulePath: subPath,
+	}, nil
+}
+
+// SubmodulePairs returns the superproject worktrees created with their
+// submodule by CreateWithSubmodule, one pair per submodule.
+func (m *Manager) SubmodulePairs(ctx context.Context) ([]SubmodulePair, error) {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []SubmodulePair
+	for _, wt := range worktrees {
+		meta, ok := store[wt.Path]
+		if !ok {
+			continue
+		}
+		name, err := filepath.Rel(m.worktreeDir, wt.Path)
+		if err != nil || strings.HasPrefix(name, "..") {
+			name = wt.Branch
+		}
+		for _, submodule := range meta.Submodules {
+			pairs = append(pairs, SubmodulePair{
+				Name:          filepath.ToSlash(name),
+				Branch:        wt.Branch,
+				Path:          wt.Path,
+				Submodule:     submodule,
+				SubmodulePath: filepath.Join(wt.Path, submodule),
+			})
+		}
+	}
+	return pairs, nil
+}
+
+// removeSubmoduleWorktrees removes the submodule worktrees checked out in the
+// superproject worktree at path, which git refuses to remove while they exist,
+// and deletes their branches unless keepBranch is set.
+func (m *Manager) removeSubmoduleWorktrees(ctx context.Context, path, branch string, submodules []string, force, keepBranch bool) error {
+	for _, submodule := range submodules {
+		subRepo := filepath.Join(m.repoRoot, submodule)
+		args := []string{"worktree", "remove", filepath.Join(path, submodule)}
+		if force {
+			args = []string{"worktree", "remove", "--force", filepath.Join(path, submodule)}
+		}
+
+		done := m.step("Removing submodule " + submodule)
+		_, err := m.gitOutput(ctx, subRepo, args...)
+		done(err)
+		if err != nil {
+			return fmt.Errorf("failed to remove submodule worktree %s: %w", submodule, err)
+		}
+
+		if !keepBranch && branch != "" {
+			if _, err := m.gitOutput(ctx, subRepo, "branch", "-D", branch); err != nil {
+				fmt.Printf("⚠️  Warning: failed to delete branch '%s' of submodule %s: %v\n", branch, submodule, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSubmodulePaths(t *testing.T) {
+	for name, tt := range map[string]struct {
+		output   string
+		expected []string
+	}{
+		"none": {output: ""},
+		"one":  {output: "submodule.lib.path lib\n", expected: []string{"lib"}},
+		"nested path": {
+			output:   "submodule.lib.path lib\nsubmodule.vendor/proto.path vendor/proto\n",
+			expected: []string{"lib", "vendor/proto"},
+		},
+		"blank lines": {output: "\nsubmodule.lib.path lib\n\n", expected: []string{"lib"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, parseSubmodulePaths(tt.output)); diff != "" {
+				t.Errorf("parseSubmodulePaths() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package worktree
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/knwoop/giwo/internal/errors"
+	"github.com/knwoop/giwo/internal/utils"
+)
+
+// KindTask marks worktrees created by StartTask for an automated coding task.
+const KindTask = "task"
+
+// DefaultTaskTTL is how long a task may run before it counts as expired.
+const DefaultTaskTTL = 24 * time.Hour
+
+// TaskInfo describes the automated coding task a worktree was created for.
+type TaskInfo struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	Owner       string    `json:"owner,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Task is a worktree created by StartTask, as handed to the agent running the task.
+type Task struct {
+	TaskInfo
+
+	// Name is the worktree's name, which other giwo commands accept.
+	Name   string `json:"name"`
+	Branch string `json:"branch"`
+	Base   string `json:"base,omitempty"`
+	Path   string `json:"path"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// PortOffset is added to the ports of servers started in the worktree,
+	// so that several tasks run side by side.
+	PortOffset int `json:"port_offset"`
+}
+
+// Expired reports whether the task ran past its expiry at now.
+func (t *Task) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// TaskOptions configures StartTask.
+type TaskOptions struct {
+	Description string
+
+	// Owner is the person or agent running the task.
+	Owner string
+
+	// Base is the branch the task starts from, freshly fetched from origin.
+	// Defaults to main, like Create.
+	Base string
+
+	// BranchPrefix starts the generated branch name. Defaults to "task/".
+	BranchPrefix string
+
+	// TTL is how long the task may run. Defaults to DefaultTaskTTL.
+	TTL time.Duration
+}
+
+// StartTask creates an isolated worktree for an automated coding task, on a
+// branch named after the description, such as task/fix-parser-3fa2c1, and
+// records the task with the worktree: its description, owner and expiry.
+// A port offset is allocated right away, so that the returned handle is all
+// the agent needs.
+func (m *Manager) StartTask(ctx context.Context, opts TaskOptions) (*Task, error) {
+	description := strings.TrimSpace(opts.Description)
+	if description == "" {
+		return nil, fmt.Errorf("a task description is required")
+	}
+	prefix := opts.BranchPrefix
+	if prefix == "" {
+		prefix = "task/"
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultTaskTTL
+	}
+
+	id, err := newTaskID()
+	if err != nil {
+		return nil, err
+	}
+	branch := taskBranchName(prefix, description, id)
+	if err := utils.ValidateBranchName(branch); err != nil {
+		return nil, fmt.Errorf("invalid task branch name %q: %w", branch, err)
+	}
+
+	if err := m.Create(ctx, CreateOptions{Branch: branch, Base: opts.Base}); err != nil {
+		return nil, err
+	}
+
+	path := m.WorktreePath(branch)
+	info := &TaskInfo{ID: id, Description: description, Owner: opts.Owner, ExpiresAt: time.Now().Add(ttl)}
+	if err := m.updateMetadata(path, func(meta *Metadata) {
+		meta.Kind = KindTask
+		meta.Task = info
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record task: %w", err)
+	}
+
+	return m.Task(ctx, id)
+}
+
+// Tasks returns the tasks whose worktrees still exist, oldest first.
+func (m *Manager) Tasks(ctx context.Context) ([]Task, error) {
+	store, err := m.loadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := m.listRegistered(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	for _, wt := range worktrees {
+		meta, ok := store[wt.Path]
+		if !ok || meta.Task == nil {
+			continue
+		}
+		name, err := filepath.Rel(m.worktreeDir, wt.Path)
+		if err != nil || strings.HasPrefix(name, "..") {
+			name = wt.Branch
+		}
+		offset, err := m.PortOffset(ctx, wt)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, Task{
+			TaskInfo:   *meta.Task,
+			Name:       filepath.ToSlash(name),
+			Branch:     wt.Branch,
+			Base:       meta.Base,
+			Path:       wt.Path,
+			CreatedAt:  meta.CreatedAt,
+			PortOffset: offset,
+		})
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+	return tasks, nil
+}
+
+// Task returns the task with the given ID, or whose worktree has the given name.
+func (m *Manager) Task(ctx context.Context, id string) (*Task, error) {
+	tasks, err := m.Tasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].ID == id || tasks[i].Name == id {
+			return &tasks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no task %q", errors.ErrWorktreeNotFound, id)
+}
+
+// FinishTask ends a task and removes its worktree, keeping its branch. With
+// land, the work is published: the worktree must have no uncommitted changes,
+// and the branch is pushed to origin with its upstream set. Otherwise the
+// worktree is archived with all its files, and 'giwo restore' brings it back.
+func (m *Manager) FinishTask(ctx context.Context, task *Task, land bool) error {
+	if !land {
+		if _, err := m.Archive(ctx, task.Name); err != nil {
+			return fmt.Errorf("failed to archive task %s: %w", task.ID, err)
+		}
+		return nil
+	}
+
+	status, err := m.gitOutput(ctx, task.Path, "status", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("failed to get status of task %s: %w", task.ID, err)
+	}
+	if strings.TrimSpace(status) != "" {
+		return fmt.Errorf("%w: %s", errors.ErrWorktreeDirty, task.Path)
+	}
+
+	done := m.step("Pushing " + task.Branch)
+	err = m.Push(ctx, task.Branch, true)
+	done(err)
+	if err != nil {
+		return err
+	}
+	return m.Remove(ctx, RemoveOptions{Name: task.Name, KeepBranch: true})
+}
+
+// newTaskID returns a short random identifier for a task.
+func newTaskID() (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate task ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// taskBranchName names the branch of a task after a few words of its
+// description, made unique by the task's ID.
+func taskBranchName(prefix, description, id string) string {
+	if slug := utils.Slugify(description, 30); slug != "" {
+		return prefix + slug + "-" + id
+	}
+	return prefix + id
+}
@@ -0,0 +1,58 @@
+package worktree
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTaskBranchName(t *testing.T) {
+	for name, tt := range map[string]struct {
+		prefix      string
+		description string
+		expected    string
+	}{
+		"description": {
+			prefix:      "task/",
+			description: "Fix the parser crash on empty input",
+			expected:    "task/fix-the-parser-crash-on-empty-3fa2c1",
+		},
+		"no usable words": {
+			prefix:      "agent/",
+			description: "!!!",
+			expected:    "agent/3fa2c1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, taskBranchName(tt.prefix, tt.description, "3fa2c1")); diff != "" {
+				t.Errorf("taskBranchName mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTaskExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, tt := range map[string]struct {
+		expiresAt time.Time
+		expected  bool
+	}{
+		"running":   {expiresAt: now.Add(time.Hour), expected: false},
+		"expired":   {expiresAt: now.Add(-time.Minute), expected: true},
+		"just now":  {expiresAt: now, expected: true},
+		"no expiry": {expiresAt: time.Time{}, expected: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			task := &Task{TaskInfo: TaskInfo{ExpiresAt: tt.expiresAt}}
+			if diff := cmp.Diff(tt.expected, task.Expired(now)); diff != "" {
+				t.Errorf("Expired mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -31,14 +31,62 @@ type Worktree struct {
 	// Identifying fields
 	Path   string `json:"path"`
 	Branch string `json:"branch"`
+	Head   string `json:"head"`
+
+	// Repo is the name of the repository the worktree belongs to.
+	// It is only set when worktrees of several repositories are combined.
+	Repo string `json:"repo,omitempty"`
+
+	// Bookmark is the jj bookmark checked out in the worktree jj manages in a
+	// repository colocated with jj, where git reports the HEAD as detached.
+	Bookmark string `json:"bookmark,omitempty"`
+
+	// StatusPending is set by List without ListOptions.WithStatus for worktrees
+	// without a valid cached status, whose status fields, from IsClean on, are
+	// zero until LoadStatus computes them.
+	StatusPending bool `json:"-"`
 
 	// Status flags
-	IsMain  bool `json:"is_main"`
-	IsClean bool `json:"is_clean"`
+	IsMain    bool `json:"is_main"`
+	IsClean   bool `json:"is_clean"`
+	Detached  bool `json:"detached"`
+	Ephemeral bool `json:"ephemeral"`
+
+	// Lock state as maintained by 'git worktree lock'
+	Locked     bool   `json:"locked"`
+	LockReason string `json:"lock_reason,omitempty"`
+
+	// Git reports the worktree as prunable, e.g. because its directory is gone
+	Prunable bool `json:"prunable"`
+
+	// Labels are attached by the user with 'giwo tag', sorted.
+	Labels []string `json:"labels,omitempty"`
+
+	// Base the worktree was created from and its kind, as recorded by giwo
+	Base string `json:"base,omitempty"`
+	Kind string `json:"kind,omitempty"`
+
+	// Template is the key of the template override the worktree was set up with.
+	Template string `json:"template,omitempty"`
+
+	// Submodules are the submodules checked out as worktrees of their own
+	// repositories, paired with this worktree by 'giwo sub create'.
+	Submodules []string `json:"submodules,omitempty"`
+
+	// HeadRef is the branch used as the head of pull requests when it was
+	// recorded separately, because the branch was renamed to avoid a collision.
+	HeadRef string `json:"head_ref,omitempty"`
+
+	// BrokenLinks counts giwo-created symlinks needing repair; only set when links are verified
+	BrokenLinks int `json:"broken_links,omitempty"`
 
-	// Sync status with remote
-	Ahead  int `json:"ahead"`
-	Behind int `json:"behind"`
+	// DiskUsage is the size of the worktree's files in bytes; only set when measured
+	DiskUsage int64 `json:"disk_usage,omitempty"`
+
+	// Sync status with the upstream branch, or origin/<branch> if none is configured
+	Upstream string `json:"upstream,omitempty"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
 
 	// Local changes count
 	Added    int `json:"added"`
@@ -49,6 +97,40 @@ type Worktree struct {
 	LastCommit string    `json:"last_commit"`
 	CommitAge  string    `json:"commit_age"`
 	CommitTime time.Time `json:"commit_time"`
+
+	// Last activity: the latest commit, HEAD movement, staging or change of a
+	// modified file in the worktree
+	LastActivity time.Time `json:"last_activity,omitzero"`
+	ActivityAge  string    `json:"activity_age,omitempty"`
+
+	// Remote is pull request and CI information from the forge; only set when requested
+	Remote *RemoteStatus `json:"remote,omitempty"`
+}
+
+// PullRequestHead returns the branch pull requests of the worktree use as their head.
+func (wt *Worktree) PullRequestHead() string {
+	if wt.HeadRef != "" {
+		return wt.HeadRef
+	}
+	return wt.Branch
+}
+
+// RemoteStatus is forge information about a worktree's branch.
+type RemoteStatus struct {
+	// Pull request of the branch, if any
+	PRNumber int    `json:"pr_number,omitempty"`
+	PRState  string `json:"pr_state,omitempty"`
+	PRURL    string `json:"pr_url,omitempty"`
+
+	// Checks summarizes the CI checks of the pull request: success, failure or pending
+	Checks string `json:"checks,omitempty"`
+
+	// Error explains why the information could not be fetched
+	Error string `json:"error,omitempty"`
+
+	// CachedAt is when the information was fetched, set if it comes from the
+	// cache because the forge could not be reached
+	CachedAt time.Time `json:"cached_at,omitzero"`
 }
 
 // Stats represents statistics about all worktrees.
@@ -47,3 +47,23 @@ func TestConfigFiles(t *testing.T) {
 		t.Error("ConfigFiles should not be empty")
 	}
 }
+
+func TestPullRequestHead(t *testing.T) {
+	for name, tt := range map[string]struct {
+		wt       Worktree
+		expected string
+	}{
+		"branch":    {wt: Worktree{Branch: "feature-x"}, expected: "feature-x"},
+		"recorded":  {wt: Worktree{Branch: "knwoop/feature-x", HeadRef: "knwoop/feature-x"}, expected: "knwoop/feature-x"},
+		"differs":   {wt: Worktree{Branch: "feature-x", HeadRef: "knwoop/feature-x"}, expected: "knwoop/feature-x"},
+		"no branch": {wt: Worktree{Detached: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.expected, tt.wt.PullRequestHead()); diff != "" {
+				t.Errorf("PullRequestHead() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MinimumGitVersion is the oldest git release providing every worktree subcommand giwo uses
+// ('git worktree remove' and 'git worktree move' appeared in 2.17).
+var MinimumGitVersion = [2]int{2, 17}
+
+// gitVersionPattern matches the version number in the output of 'git version'.
+var gitVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// GitVersion returns the version of the installed git, e.g. "2.39.5".
+func GitVersion(ctx context.Context) (string, error) {
+	output, err := exec.CommandContext(ctx, "git", "version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git: %w", err)
+	}
+
+	version := gitVersionPattern.FindString(string(output))
+	if version == "" {
+		return "", fmt.Errorf("failed to parse git version from: %s", strings.TrimSpace(string(output)))
+	}
+	return version, nil
+}
+
+// IsSupportedGitVersion reports whether version is at least MinimumGitVersion.
+func IsSupportedGitVersion(version string) bool {
+	matches := gitVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return false
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	if major != MinimumGitVersion[0] {
+		return major > MinimumGitVersion[0]
+	}
+	return minor >= MinimumGitVersion[1]
+}
@@ -0,0 +1,29 @@
+package worktree
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIsSupportedGitVersion(t *testing.T) {
+	for name, tt := range map[string]struct {
+		version  string
+		expected bool
+	}{
+		"current release":   {"2.39.5", true},
+		"minimum version":   {"2.17.0", true},
+		"too old":           {"2.16.6", false},
+		"next major":        {"3.0", true},
+		"old major":         {"1.9.5", false},
+		"vendor suffix":     {"2.39.3 (Apple Git-146)", true},
+		"unparsable output": {"unknown", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.expected, IsSupportedGitVersion(tt.version)); diff != "" {
+				t.Errorf("IsSupportedGitVersion(%q) mismatch (-want +got):\n%s", tt.version, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package worktree
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchInterval is how often Watch checks all worktrees by default,
+// besides whenever their files change.
+const DefaultWatchInterval = 30 * time.Second
+
+// watchSettleTime is how long Watch waits for changes to settle before
+// refreshing, so that a checkout or build touching many files refreshes once.
+const watchSettleTime = 200 * time.Millisecond
+
+// Watch calls fn with the worktrees right away, then again whenever their
+// status changes, until ctx is done. Changes are noticed through file
+// notifications on each worktree's directories tracked at HEAD and its git
+// directory, and on the repository's refs, FETCH_HEAD and worktree list; only
+// the worktrees whose files changed have 'git status' run again, the others
+// keep their cached status. Every interval, all worktrees are checked anyway,
+// for changes notifications miss such as those to files in directories added
+// since HEAD, or if the system is out of notifications. If listing fails, fn
+// gets the error and the next change or check tries again.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration, fn func([]*Worktree, error)) {
+	watcher := m.newFileWatcher()
+	defer watcher.close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		last    []*Worktree
+		lastErr error
+		settle  <-chan time.Time
+		changed = make(map[string]bool)
+	)
+	refresh := func(first, all bool) {
+		worktrees, err := m.List(ctx, ListOptions{})
+		if err == nil {
+			m.loadStatus(ctx, worktrees, 0, func(wt *Worktree) bool { return all || changed[wt.Path] }, func(i int, status *Worktree) {
+				*worktrees[i] = *status
+			})
+			watcher.sync(ctx, worktrees)
+		}
+		clear(changed)
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err != nil:
+			if lastErr == nil || err.Error() != lastErr.Error() {
+				fn(nil, err)
+			}
+		case first, lastErr != nil, statusChanged(last, worktrees):
+			fn(worktrees, nil)
+			last = worktrees
+		}
+		lastErr = err
+	}
+
+	refresh(true, true)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh(false, true)
+			settle = nil
+		case err := <-watcher.errors():
+			// Notifications were dropped, so any worktree may have changed
+			m.log().Debug("file notifications failed", "error", err)
+			refresh(false, true)
+			settle = nil
+		case event := <-watcher.events():
+			if path, ok := watcher.handle(event); ok {
+				if path != "" {
+					changed[path] = true
+				}
+				settle = time.After(watchSettleTime)
+			}
+		case <-settle:
+			refresh(false, false)
+			settle = nil
+		}
+	}
+}
+
+// fileWatcher maps file notifications to the worktrees they concern. A nil
+// fileWatcher, as returned when notifications are unavailable, watches nothing.
+type fileWatcher struct {
+	m      *Manager
+	notify *fsnotify.Watcher
+
+	// dirs maps each watched directory to the path of its worktree, or to the
+	// empty string for the repository's own directories.
+	dirs map[string]string
+
+	// heads holds the HEAD each worktree's directories were listed at.
+	heads map[string]string
+}
+
+// newFileWatcher starts watching the repository's refs, which do not belong to
+// any worktree; sync adds the worktrees.
+func (m *Manager) newFileWatcher() *fileWatcher {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		m.log().Debug("file notifications unavailable", "error", err)
+		return nil
+	}
+
+	w := &fileWatcher{m: m, notify: notify, dirs: make(map[string]string), heads: make(map[string]string)}
+	w.add(m.gitCommonDir, "")
+	for _, refs := range []string{"heads", "remotes"} {
+		w.addTree(filepath.Join(m.gitCommonDir, "refs", refs), "")
+	}
+	return w
+}
+
+// events returns the channel of file notifications, or nil if there are none.
+func (w *fileWatcher) events() chan fsnotify.Event {
+	if w == nil {
+		return nil
+	}
+	return w.notify.Events
+}
+
+// errors returns the channel of notification errors, or nil if there are no notifications.
+func (w *fileWatcher) errors() chan error {
+	if w == nil {
+		return nil
+	}
+	return w.notify.Errors
+}
+
+// close stops watching.
+func (w *fileWatcher) close() {
+	if w != nil {
+		_ = w.notify.Close()
+	}
+}
+
+// handle returns the path of the worktree a file notification concerns, or the
+// empty string if it concerns the repository as a whole. It reports false for
+// notifications to ignore, such as those about git's lock files.
+func (w *fileWatcher) handle(event fsnotify.Event) (string, bool) {
+	if strings.HasSuffix(event.Name, ".lock") || filepath.Base(event.Name) == ".git" {
+		return "", false
+	}
+
+	path, ok := w.dirs[filepath.Dir(event.Name)]
+	if !ok {
+		path, ok = w.dirs[event.Name]
+	}
+	if !ok {
+		return "", false
+	}
+
+	// Branches and remotes are namespaced in directories created as they are needed
+	if path == "" && event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.addTree(event.Name, "")
+		}
+	}
+	return path, true
+}
+
+// sync watches worktrees that are new or have moved to another HEAD, which may
+// track other directories, and stops watching those that are gone.
+func (w *fileWatcher) sync(ctx context.Context, worktrees []*Worktree) {
+	if w == nil {
+		return
+	}
+
+	// The list of worktrees only exists once there is a linked one
+	w.add(filepath.Join(w.m.gitCommonDir, "worktrees"), "")
+
+	live := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		live[wt.Path] = true
+		if head, ok := w.heads[wt.Path]; ok && head == wt.Head {
+			continue
+		}
+		w.heads[wt.Path] = wt.Head
+
+		w.add(wt.Path, wt.Path)
+		w.add(worktreeGitDir(wt.Path), wt.Path)
+		// Unborn and broken worktrees track no directories
+		output, err := w.m.gitOutput(ctx, wt.Path, "ls-tree", "-r", "-d", "-z", "--name-only", "HEAD")
+		if err != nil {
+			continue
+		}
+		for _, dir := range strings.Split(strings.TrimRight(output, "\x00"), "\x00") {
+			if dir != "" {
+				w.add(filepath.Join(wt.Path, dir), wt.Path)
+			}
+		}
+	}
+
+	for dir, path := range w.dirs {
+		if path != "" && !live[path] {
+			_ = w.notify.Remove(dir)
+			delete(w.dirs, dir)
+			delete(w.heads, path)
+		}
+	}
+}
+
+// add watches dir for the worktree at path. Directories that cannot be watched,
+// because they do not exist or the system is out of notifications, are left to
+// the periodic check.
+func (w *fileWatcher) add(dir, path string) {
+	if _, ok := w.dirs[dir]; ok {
+		w.dirs[dir] = path
+		return
+	}
+	if err := w.notify.Add(dir); err != nil {
+		return
+	}
+	w.dirs[dir] = path
+}
+
+// addTree watches dir and all directories below it for the worktree at path.
+func (w *fileWatcher) addTree(dir, path string) {
+	_ = filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() {
+			w.add(name, path)
+		}
+		return nil
+	})
+}
+
+// statusChanged reports whether the worktrees differ in anything watched:
+// which worktrees there are, what they have checked out, their changes and how
+// they compare to their upstream.
+func statusChanged(old, new []*Worktree) bool {
+	return !slices.EqualFunc(old, new, func(a, b *Worktree) bool {
+		return a.Path == b.Path &&
+			a.Branch == b.Branch &&
+			a.Head == b.Head &&
+			a.IsClean == b.IsClean &&
+			a.Added == b.Added &&
+			a.Modified == b.Modified &&
+			a.Deleted == b.Deleted &&
+			a.Upstream == b.Upstream &&
+			a.Ahead == b.Ahead &&
+			a.Behind == b.Behind &&
+			a.Locked == b.Locked &&
+			slices.Equal(a.Labels, b.Labels)
+	})
+}
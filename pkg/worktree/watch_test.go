@@ -0,0 +1,208 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStatusChanged(t *testing.T) {
+	t.Parallel()
+
+	base := func() []*Worktree {
+		return []*Worktree{
+			{Path: "/repo", Branch: "main", Head: "abc", IsMain: true, IsClean: true},
+			{Path: "/repo/.worktree/a", Branch: "a", Head: "def", IsClean: true, Upstream: "origin/a", CommitAge: "1h ago"},
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		change func([]*Worktree) []*Worktree
+		want   bool
+	}{
+		"unchanged": {
+			change: func(wts []*Worktree) []*Worktree { return wts },
+			want:   false,
+		},
+		"commit age only": {
+			change: func(wts []*Worktree) []*Worktree { wts[1].CommitAge = "2h ago"; return wts },
+			want:   false,
+		},
+		"file modified": {
+			change: func(wts []*Worktree) []*Worktree { wts[1].IsClean = false; wts[1].Modified = 1; return wts },
+			want:   true,
+		},
+		"another file modified": {
+			change: func(wts []*Worktree) []*Worktree { wts[1].Modified = 2; return wts },
+			want:   true,
+		},
+		"new commit": {
+			change: func(wts []*Worktree) []*Worktree { wts[1].Head = "fed"; wts[1].Ahead = 1; return wts },
+			want:   true,
+		},
+		"fetched": {
+			change: func(wts []*Worktree) []*Worktree { wts[1].Behind = 3; return wts },
+			want:   true,
+		},
+		"labeled": {
+			change: func(wts []*Worktree) []*Worktree { wts[1].Labels = []string{"review"}; return wts },
+			want:   true,
+		},
+		"worktree added": {
+			change: func(wts []*Worktree) []*Worktree { return append(wts, &Worktree{Path: "/repo/.worktree/b"}) },
+			want:   true,
+		},
+		"worktree removed": {
+			change: func(wts []*Worktree) []*Worktree { return wts[:1] },
+			want:   true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := statusChanged(base(), tt.change(base()))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("statusChanged() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestWatch checks that Watch notices changes through file notifications alone,
+// with an interval too long to check the worktrees in the meantime.
+func TestWatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Parallel()
+
+	type state struct {
+		Name              string
+		Head              string
+		Modified, Deleted int
+	}
+
+	for name, tt := range map[string]struct {
+		change func(t *testing.T, repo, feature string)
+		want   func(t *testing.T, repo, feature string) []state
+	}{
+		"tracked file edited in a subdirectory": {
+			change: func(t *testing.T, repo, feature string) {
+				writeFile(t, filepath.Join(feature, "src", "main.go"), "package main // edited\n", 0o644)
+			},
+			want: func(t *testing.T, repo, feature string) []state {
+				return []state{
+					{Name: filepath.Base(repo), Head: gitRepo(t, repo, "rev-parse", "HEAD")},
+					{Name: "feature", Head: gitRepo(t, feature, "rev-parse", "HEAD"), Modified: 1},
+				}
+			},
+		},
+		"file deleted in the main worktree": {
+			change: func(t *testing.T, repo, feature string) {
+				if err := os.Remove(filepath.Join(repo, "README")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: func(t *testing.T, repo, feature string) []state {
+				return []state{
+					{Name: filepath.Base(repo), Head: gitRepo(t, repo, "rev-parse", "HEAD"), Deleted: 1},
+					{Name: "feature", Head: gitRepo(t, feature, "rev-parse", "HEAD")},
+				}
+			},
+		},
+		"commit": {
+			change: func(t *testing.T, repo, feature string) {
+				gitRepo(t, feature, "commit", "--allow-empty", "-m", "feature")
+			},
+			want: func(t *testing.T, repo, feature string) []state {
+				return []state{
+					{Name: filepath.Base(repo), Head: gitRepo(t, repo, "rev-parse", "HEAD")},
+					{Name: "feature", Head: gitRepo(t, feature, "rev-parse", "HEAD")},
+				}
+			},
+		},
+		"worktree added elsewhere": {
+			change: func(t *testing.T, repo, feature string) {
+				gitRepo(t, repo, "worktree", "add", "-q", "-b", "other", filepath.Join(filepath.Dir(feature), "other"))
+			},
+			want: func(t *testing.T, repo, feature string) []state {
+				return []state{
+					{Name: filepath.Base(repo), Head: gitRepo(t, repo, "rev-parse", "HEAD")},
+					{Name: "feature", Head: gitRepo(t, feature, "rev-parse", "HEAD")},
+					{Name: "other", Head: gitRepo(t, repo, "rev-parse", "HEAD")},
+				}
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo := filepath.Join(dir, "repo")
+			feature := filepath.Join(dir, "worktrees", "feature")
+			if err := os.MkdirAll(repo, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			gitRepo(t, repo, "init", "-q")
+			writeFiles(t, repo, map[string]string{"README": "hello\n", "src/main.go": "package main\n"})
+			gitRepo(t, repo, "add", ".")
+			gitRepo(t, repo, "commit", "-q", "-m", "initial")
+			gitRepo(t, repo, "worktree", "add", "-q", "-b", "feature", feature)
+
+			m, err := NewAt(repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			updates := make(chan []state)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				m.Watch(ctx, time.Hour, func(worktrees []*Worktree, err error) {
+					if err != nil {
+						t.Errorf("Watch() error = %v", err)
+						return
+					}
+					var states []state
+					for _, wt := range worktrees {
+						states = append(states, state{Name: filepath.Base(wt.Path), Head: wt.Head, Modified: wt.Modified, Deleted: wt.Deleted})
+					}
+					select {
+					case updates <- states:
+					case <-ctx.Done():
+					}
+				})
+			}()
+			t.Cleanup(func() {
+				cancel()
+				<-done
+			})
+
+			<-updates
+			tt.change(t, repo, feature)
+			want := tt.want(t, repo, feature)
+
+			var got []state
+			timeout := time.After(10 * time.Second)
+			for {
+				select {
+				case got = <-updates:
+					if cmp.Equal(want, got) {
+						return
+					}
+				case <-timeout:
+					t.Fatalf("Watch() did not notice the change (-want +got):\n%s", cmp.Diff(want, got))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+# giwo shell integration for directory switching in PowerShell
+# Dot-source this file in your profile: . C:\path\to\giwo\scripts\giwo-switch.ps1
+
+# Let 'giwo doctor' know that the integration is loaded
+$env:GIWO_SHELL_INTEGRATION = "1"
+
+# The giwo executable, which the function below shadows
+$script:GiwoExe = Get-Command giwo -CommandType Application -ErrorAction SilentlyContinue | Select-Object -First 1
+
+# Wrap giwo so that commands like 'giwo create --switch' can change the
+# directory of this shell: giwo writes the target path to $env:GIWO_CD_FILE.
+function giwo {
+    $cdFile = New-TemporaryFile
+    $env:GIWO_CD_FILE = $cdFile.FullName
+    try {
+        & $script:GiwoExe @args
+        $status = $LASTEXITCODE
+    } finally {
+        Remove-Item Env:GIWO_CD_FILE
+    }
+
+    $target = Get-Content -Raw -LiteralPath $cdFile.FullName
+    Remove-Item -LiteralPath $cdFile.FullName
+    if ($target) {
+        Set-Location -LiteralPath $target.Trim()
+    }
+    $global:LASTEXITCODE = $status
+}
+
+# Function to switch to a worktree directory
+function giwo-switch {
+    # Use giwo switch with --print flag to get the path
+    $selectedPath = & $script:GiwoExe switch --print @args
+
+    # Check if a path was returned (not cancelled)
+    if ($selectedPath -and $selectedPath -ne "Operation cancelled.") {
+        Write-Host "🔄 Switching to: $selectedPath"
+        Set-Location -LiteralPath $selectedPath
+
+        # Show current branch
+        if (Get-Command git -ErrorAction SilentlyContinue) {
+            Write-Host "📍 Current branch: $(git branch --show-current 2>$null)"
+        }
+    } else {
+        Write-Host "❌ No worktree selected or operation cancelled"
+    }
+}
+
+# Alias for convenience
+Set-Alias -Name gws -Value giwo-switch
+
+# Fuzzy search version
+function giwo-fuzzy {
+    giwo-switch --fuzzy @args
+}
+
+# Alias for fuzzy search
+Set-Alias -Name gwf -Value giwo-fuzzy
+
+Write-Host "🎉 giwo shell integration loaded!"
+Write-Host "   Use 'giwo-switch' or 'gws' to switch directories"
+Write-Host "   Use 'giwo-fuzzy' or 'gwf' for fuzzy search"
@@ -2,12 +2,31 @@
 # giwo shell integration for directory switching
 # Source this file in your shell configuration (.bashrc, .zshrc, etc.)
 
+# Let 'giwo doctor' know that the integration is loaded
+export GIWO_SHELL_INTEGRATION=1
+
+# Wrap giwo so that commands like 'giwo create --switch' can change the
+# directory of this shell: giwo writes the target path to $GIWO_CD_FILE.
+giwo() {
+    local cd_file rc
+    cd_file=$(mktemp "${TMPDIR:-/tmp}/giwo-cd.XXXXXX") || return 1
+
+    GIWO_CD_FILE="$cd_file" command giwo "$@"
+    rc=$?
+
+    if [[ -s "$cd_file" ]]; then
+        cd "$(cat "$cd_file")" || rc=1
+    fi
+    rm -f "$cd_file"
+    return $rc
+}
+
 # Function to switch to a worktree directory
 giwo-switch() {
     local selected_path
     
     # Use giwo switch with --print flag to get the path
-    selected_path=$(giwo switch --print "$@")
+    selected_path=$(command giwo switch --print "$@")
     
     # Check if a path was returned (not cancelled)
     if [[ -n "$selected_path" && "$selected_path" != "Operation cancelled." ]]; then